@@ -6,28 +6,34 @@ package main
 */
 import "C"
 import (
+	"context"
 	"unsafe"
 
 	"go-reaper/src/actions"
 	"go-reaper/src/core"
+	"go-reaper/src/pkg/config"
 	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"go-reaper/src/ui"
 )
 
 //export GoReaperPluginEntry
 func GoReaperPluginEntry(hInstance unsafe.Pointer, rec unsafe.Pointer) C.int {
-	// If rec is null, REAPER is unloading the plugin
-	if rec == nil {
-		// Close any open UI windows
-		actions.CloseNativeWindow()
-		actions.CloseKeyringWindow()
+	defer reaper.Recover("GoReaperPluginEntry")
 
-		// Perform cleanup tasks including logging shutdown
-		logger.Cleanup()
+	// If rec is null, REAPER is unloading the plugin: run the cleanups registered
+	// below and by the subsystems, newest first
+	if rec == nil {
+		core.Shutdown()
 		return 0
 	}
 
-	// Initialize logging system
+	// Initialize logging system; it shuts down last
 	logger.Initialize()
+	core.RegisterShutdown("logging", 0, func(ctx context.Context) error {
+		logger.Cleanup()
+		return nil
+	})
 
 	// Initialize core functionality
 	if err := core.Initialize(hInstance, rec); err != nil {
@@ -35,12 +41,44 @@ func GoReaperPluginEntry(hInstance unsafe.Pointer, rec unsafe.Pointer) C.int {
 		return 0
 	}
 
+	// Read which features are turned off before registering anything
+	if err := config.LoadFeatures(); err != nil {
+		logger.Warning("Feature flags not fully read, the rest are on: %v", err)
+	}
+
 	// Register all actions
 	if err := actions.RegisterAll(); err != nil {
 		logger.Error("Failed to register actions: %v", err)
 		return 0
 	}
 
+	// Let ReaScripts read the version, and tidy up after an update
+	actions.RegisterVersion()
+	actions.FinishUpdate()
+
+	// Close any open UI windows first on unload
+	core.RegisterShutdown("windows", 0, func(ctx context.Context) error {
+		ui.Windows.CloseAll()
+		return nil
+	})
+
+	// Run the bridge self-test if REAPER was started for one
+	actions.StartSelfTestIfRequested()
+
+	// Keep linked FX parameters in step
+	actions.StartParamLinks()
+	core.RegisterShutdown("parameter links", 0, func(ctx context.Context) error {
+		actions.StopParamLinks()
+		return nil
+	})
+
+	// Apply learned MIDI bindings
+	actions.StartMIDILearn()
+	core.RegisterShutdown("MIDI learn", 0, func(ctx context.Context) error {
+		actions.StopMIDILearn()
+		return nil
+	})
+
 	logger.Info("Go plugin loaded successfully!")
 	return 1
 }
@@ -0,0 +1,254 @@
+package actions
+
+import (
+	"fmt"
+	"go-reaper/src/core"
+	"go-reaper/src/llm"
+	"go-reaper/src/pkg/config"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/pkg/update"
+	"go-reaper/src/pkg/version"
+	"go-reaper/src/reaper"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// This file implements the about box, which reports the extension's version and build,
+// and the update check against a release feed: a ReaPack index, so the same index.xml
+// serves ReaPack users.
+
+// maxChangelogLines is how much of a release's changelog the update dialog shows
+const maxChangelogLines = 12
+
+// The about action
+func init() {
+	registerAction(Action{
+		ID:      "GO_ABOUT",
+		Name:    "Go: About / Check for Updates",
+		Handler: handleAbout,
+	})
+}
+
+// RegisterVersion exports the extension's version to ReaScripts. Call it after
+// RegisterAll.
+func RegisterVersion() {
+	info := version.Get()
+	if err := reaper.RegisterVersion(info.String()); err != nil {
+		logger.Warning("Failed to register the version with REAPER: %v", err)
+		return
+	}
+	logger.Info("%s %s", version.Name, info)
+}
+
+// aboutText describes the running build
+func aboutText() string {
+	info := version.Get()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s\n", version.Name, info.Version)
+	if info.IsDevelopment() {
+		b.WriteString("Development build\n")
+	}
+	if info.Commit != "" {
+		commit := info.Commit
+		if info.Modified {
+			commit += " (with uncommitted changes)"
+		}
+		fmt.Fprintf(&b, "Commit: %s\n", commit)
+	}
+	if !info.BuildDate.IsZero() {
+		fmt.Fprintf(&b, "Built: %s\n", info.BuildDate.Format("2 January 2006 15:04 MST"))
+	}
+	fmt.Fprintf(&b, "Go: %s, %s\n", info.GoVersion, info.Platform)
+	if reaperVersion, err := reaper.GetAppVersion(); err == nil {
+		fmt.Fprintf(&b, "REAPER: %s\n", reaperVersion)
+	}
+	return b.String()
+}
+
+// handleAbout shows the version, then offers to check for updates
+func handleAbout() {
+	const title = "About Go REAPER Extension"
+
+	// STEP 1: Show the version
+	updates := config.GetUpdates()
+	question := "Check for updates?"
+	if updates.FeedURL == "" {
+		question = "Check for updates? You'll be asked for the release feed to check."
+	}
+	check, err := reaper.YesNoBox(aboutText()+"\n"+question, title)
+	if err != nil || !check {
+		return
+	}
+
+	// STEP 2: Confirm where releases are published
+	values, err := reaper.GetUserInputs("Check for Updates",
+		[]string{"Release feed (ReaPack index URL)", "Package (optional)"},
+		[]string{updates.FeedURL, updates.Package})
+	if err != nil {
+		logger.Debug("Update check cancelled")
+		return
+	}
+	changed := config.UpdateSettings{FeedURL: strings.TrimSpace(values[0]), Package: strings.TrimSpace(values[1])}
+	if changed.FeedURL == "" {
+		reaper.MessageBox("Enter the URL of the index.xml the releases are published in.", title)
+		return
+	}
+	if changed != updates {
+		if err := config.SetUpdates(changed); err != nil {
+			core.HandleError(title, core.NewError(core.CategoryUser, fmt.Sprintf("The release feed wasn't saved: %v", err), err))
+			return
+		}
+		updates = changed
+	}
+
+	// STEP 3: Fetch the feed off the main thread and report back on it
+	checkForUpdates(updates)
+}
+
+// checkForUpdates fetches the feed and reports whether a newer release is out. The
+// request is cancelled if the plugin unloads.
+func checkForUpdates(updates config.UpdateSettings) {
+	client := updateHTTPClient()
+	logger.Info("Checking %s for updates", updates.FeedURL)
+
+	core.Go("update check", func() {
+		release, err := update.Fetch(core.ShutdownContext(), client, updates.FeedURL, updates.Package)
+		core.MainThread.CallAsync(func() { showUpdateResult(updates, release, err) })
+	})
+}
+
+// updateHTTPClient is a client with the network settings' proxy and certificates
+func updateHTTPClient() *http.Client {
+	client, err := llm.NewHTTPClient(httpOptions(config.GetNetwork()))
+	if err != nil {
+		logger.Warning("Ignoring network settings for the update check: %v", err)
+		return &http.Client{}
+	}
+	return client
+}
+
+// showUpdateResult reports the newest release in the feed
+func showUpdateResult(updates config.UpdateSettings, release update.Release, err error) {
+	const title = "Check for Updates"
+
+	if err != nil {
+		core.HandleError(title, core.NewError(core.CategoryNetwork, fmt.Sprintf("Could not read the release feed.\n\n%v", err), err).
+			WithDetail("feed %s", updates.FeedURL))
+		return
+	}
+
+	current := version.Get()
+	logger.Info("Newest release in the feed is %s, running %s", release.Version, current.Version)
+	if !current.IsDevelopment() && version.Compare(release.Version, current.Version) <= 0 {
+		reaper.MessageBox(fmt.Sprintf("%s %s is the newest version.", version.Name, current.Version), title)
+		return
+	}
+
+	var b strings.Builder
+	if current.IsDevelopment() {
+		fmt.Fprintf(&b, "This is a development build. The newest release is %s.\n", release.Version)
+	} else {
+		fmt.Fprintf(&b, "%s %s is available. You have %s.\n", version.Name, release.Version, current.Version)
+	}
+	if !release.Time.IsZero() {
+		fmt.Fprintf(&b, "Released %s\n", release.Time.Format("2 January 2006"))
+	}
+	if release.Changelog != "" {
+		fmt.Fprintf(&b, "\n%s\n", shortChangelog(release.Changelog))
+	}
+
+	source, ok := release.SourceFor(update.Platform())
+	switch {
+	case !ok:
+		fmt.Fprintf(&b, "\nThe release has no download for %s.\n", update.Platform())
+	case source.Hash == "":
+		fmt.Fprintf(&b, "\nDownload: %s\n\nThe feed gives no hash to check the download with, so it can't be installed from here.\n", source.URL)
+	default:
+		b.WriteString("\nDownload and install it now? It's used from the next time REAPER starts.")
+		install, err := reaper.YesNoBox(b.String(), title)
+		if err == nil && install {
+			installUpdate(release, source)
+		}
+		return
+	}
+	reaper.MessageBox(b.String(), title)
+}
+
+// installUpdate downloads a release's file next to the running library, checks its hash
+// and puts it in the library's place, then offers to quit REAPER so the next start
+// loads it
+func installUpdate(release update.Release, source update.Source) {
+	const title = "Install Update"
+
+	// STEP 1: Find where the running library is
+	var dir string
+	running, err := reaper.ModulePath()
+	if err != nil {
+		logger.Warning("Installing the update in UserPlugins under the release's file name: %v", err)
+		resourcePath, err := reaper.GetResourcePath()
+		if err != nil {
+			core.HandleError(title, core.NewError(core.CategoryReaper, "Could not find REAPER's UserPlugins folder.", err))
+			return
+		}
+		dir = filepath.Join(resourcePath, "UserPlugins")
+	}
+	target := update.InstallPath(source, running, dir)
+	logger.Info("Installing %s %s from %s to %s", version.Name, release.Version, source.URL, target)
+
+	// STEP 2: Download and check it off the main thread, then install it on the main
+	// thread
+	client := updateHTTPClient()
+	core.Go("update download", func() {
+		downloaded, err := update.Download(core.ShutdownContext(), client, source, filepath.Dir(target))
+		if err == nil {
+			err = update.Install(downloaded, target)
+			if err != nil {
+				os.Remove(downloaded)
+			}
+		}
+		core.MainThread.CallAsync(func() { showInstallResult(title, release, target, err) })
+	})
+}
+
+// showInstallResult reports an installed update and offers to quit REAPER
+func showInstallResult(title string, release update.Release, target string, err error) {
+	if err != nil {
+		core.HandleError(title, core.NewError(core.CategoryNetwork, fmt.Sprintf("The update wasn't installed.\n\n%v", err), err).
+			WithDetail("target %s", target))
+		return
+	}
+	logger.Info("Installed %s %s to %s", version.Name, release.Version, target)
+
+	quit, err := reaper.YesNoBox(fmt.Sprintf("Installed %s %s.\n\nREAPER uses it from its next start. Quit REAPER now? You'll be asked to save changed projects.",
+		version.Name, release.Version), title)
+	if err != nil || !quit {
+		return
+	}
+	if err := reaper.RunCommand(reaper.CommandQuit); err != nil {
+		core.HandleError(title, core.NewError(core.CategoryReaper, "Could not quit REAPER. Restart it to use the update.", err))
+	}
+}
+
+// FinishUpdate removes what installing an update left behind, i.e. the library a
+// Windows install moved aside. Call it when the plugin loads.
+func FinishUpdate() {
+	running, err := reaper.ModulePath()
+	if err != nil {
+		return
+	}
+	if err := update.RemoveReplaced(running); err != nil {
+		logger.Warning("Could not remove the library replaced by an update: %v", err)
+	}
+}
+
+// shortChangelog keeps the first lines of a changelog
+func shortChangelog(changelog string) string {
+	lines := strings.Split(changelog, "\n")
+	if len(lines) <= maxChangelogLines {
+		return changelog
+	}
+	return strings.Join(lines[:maxChangelogLines], "\n") + "\n..."
+}
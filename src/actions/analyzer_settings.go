@@ -0,0 +1,65 @@
+package actions
+
+import (
+	"fmt"
+	"go-reaper/src/pkg/config"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"strconv"
+	"strings"
+)
+
+// The action that sets which parameters the assistant's prompt leaves out
+func init() {
+	registerAction(Action{
+		ID:      "GO_ANALYZER_SETTINGS",
+		Feature: config.FeatureAssistant,
+		Name:    "Go: Assistant parameter filter settings...",
+		Handler: handleAnalyzerSettings,
+	})
+}
+
+// yesNo formats a setting for a y/n field
+func yesNo(value bool) string {
+	if value {
+		return "y"
+	}
+	return "n"
+}
+
+// handleAnalyzerSettings asks which kinds of parameters to leave out of the prompt, and
+// whether to describe the track's loudness per section
+func handleAnalyzerSettings() {
+	const title = "Assistant Parameter Filter"
+
+	analyzer := config.GetAnalyzer()
+	values, err := reaper.GetUserInputs(title,
+		[]string{"Leave out bypass switches (y/n)", "Leave out MIDI parameters (y/n)", "Leave out switches that are off (y/n)",
+			"Leave out placeholders too (y/n)", "Max parameters per FX (0: all)", "Loudness per region/marker section (y/n)"},
+		[]string{yesNo(analyzer.SkipBypass), yesNo(analyzer.SkipMIDI), yesNo(analyzer.SkipOffToggles),
+			yesNo(analyzer.FilterBoilerplate), strconv.Itoa(analyzer.MaxParamsPerFX), yesNo(analyzer.SectionLoudness)})
+	if err != nil {
+		logger.Debug("Analyzer settings cancelled")
+		return
+	}
+
+	yes := func(value string) bool {
+		return strings.HasPrefix(strings.ToLower(strings.TrimSpace(value)), "y")
+	}
+	maxParams, err := strconv.Atoi(strings.TrimSpace(values[4]))
+	if err != nil || maxParams < 0 {
+		reaper.MessageBox("Enter a whole number of parameters, or 0 for all.", title)
+		return
+	}
+
+	analyzer.SkipBypass, analyzer.SkipMIDI, analyzer.SkipOffToggles = yes(values[0]), yes(values[1]), yes(values[2])
+	analyzer.FilterBoilerplate = yes(values[3])
+	analyzer.MaxParamsPerFX = maxParams
+	analyzer.SectionLoudness = yes(values[5])
+	if err := config.SetAnalyzer(analyzer); err != nil {
+		reaper.MessageBox(fmt.Sprintf("The settings were not saved: %v", err), title)
+		return
+	}
+	logger.Info("Assistant parameter filter: bypass %v, MIDI %v, off switches %v, boilerplate %v, max %d per FX, section loudness %v",
+		analyzer.SkipBypass, analyzer.SkipMIDI, analyzer.SkipOffToggles, analyzer.FilterBoilerplate, maxParams, analyzer.SectionLoudness)
+}
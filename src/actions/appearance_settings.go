@@ -0,0 +1,49 @@
+package actions
+
+import (
+	"fmt"
+	"go-reaper/src/pkg/config"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"strings"
+)
+
+// The action that sets whether the extension's windows are light or dark
+func init() {
+	registerAction(Action{
+		ID:      "GO_WINDOW_APPEARANCE",
+		Name:    "Go: Window appearance...",
+		Handler: handleWindowAppearance,
+	})
+}
+
+// handleWindowAppearance asks for auto, light or dark
+func handleWindowAppearance() {
+	const title = "Window Appearance"
+
+	current := config.GetAppearance()
+	if current == config.AppearanceAuto {
+		current = "auto"
+	}
+	values, err := reaper.GetUserInputs(title, []string{"Appearance (auto, light or dark)"}, []string{current})
+	if err != nil {
+		logger.Debug("Window appearance cancelled")
+		return
+	}
+
+	appearance := strings.ToLower(strings.TrimSpace(values[0]))
+	switch appearance {
+	case "auto":
+		appearance = config.AppearanceAuto
+	case config.AppearanceLight, config.AppearanceDark:
+	default:
+		reaper.MessageBox("Enter auto to follow REAPER's theme, or light or dark.", title)
+		return
+	}
+	if err := config.SetAppearance(appearance); err != nil {
+		reaper.MessageBox(fmt.Sprintf("The setting was not saved: %v", err), title)
+		return
+	}
+	logger.Info("Window appearance set to %q", appearance)
+	reaper.MessageBox("Windows take the new appearance the next time they're shown.", title)
+}
@@ -0,0 +1,163 @@
+package actions
+
+import (
+	"fmt"
+	"go-reaper/src/core"
+	"go-reaper/src/pkg/config"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"strconv"
+	"strings"
+)
+
+// historyListSize is how many recent applies the history action lists
+const historyListSize = 15
+
+// The actions that browse the assistant journal and revert the latest apply
+func init() {
+	registerAction(Action{
+		ID:      "GO_ASSISTANT_HISTORY",
+		Feature: config.FeatureAssistant,
+		Name:    "Go: Assistant History",
+		Handler: handleAssistantHistory,
+	})
+	registerAction(Action{
+		ID:      "GO_ASSISTANT_REVERT_LAST",
+		Feature: config.FeatureAssistant,
+		Name:    "Go: Revert Last Assistant Change",
+		Handler: handleRevertLastAssistantChange,
+	})
+}
+
+// handleAssistantHistory lists recent assistant applies and reverts or re-applies one
+func handleAssistantHistory() {
+	const title = "Assistant History"
+
+	// STEP 1: List the recent applies
+	entries := getJournal().recent(historyListSize)
+	if len(entries) == 0 {
+		reaper.MessageBox("The FX assistant hasn't applied any changes yet.", title)
+		return
+	}
+
+	var builder strings.Builder
+	for _, entry := range entries {
+		builder.WriteString(entry.summary() + "\n")
+	}
+	msg := fmt.Sprintf("Recent changes applied by the FX assistant, newest first:\n\n%s\nRevert or re-apply one of them?", builder.String())
+
+	proceed, err := reaper.YesNoBox(msg, title)
+	if err != nil || !proceed {
+		return
+	}
+
+	// STEP 2: Pick an entry and what to do with it
+	values, err := reaper.GetUserInputs(title,
+		[]string{"Change number", "Revert or re-apply (r/a)"},
+		[]string{strconv.Itoa(entries[0].ID), "r"})
+	if err != nil {
+		logger.Debug("History selection cancelled")
+		return
+	}
+
+	id, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(values[0]), "#"))
+	if err != nil {
+		reaper.MessageBox(fmt.Sprintf("Invalid change number: %s", values[0]), title)
+		return
+	}
+	entry, ok := getJournal().get(id)
+	if !ok {
+		reaper.MessageBox(fmt.Sprintf("There is no change #%d in the history.", id), title)
+		return
+	}
+	revert := !strings.HasPrefix(strings.ToLower(strings.TrimSpace(values[1])), "a")
+
+	// STEP 3: Confirm with the values that will be written
+	if len(entry.Changes) == 0 {
+		reaper.MessageBox(fmt.Sprintf("Change #%d only changed the FX chain, which can't be reverted or re-applied from the history.", id), title)
+		return
+	}
+	verb := "Re-apply"
+	if revert {
+		verb = "Revert"
+	}
+	confirm := fmt.Sprintf("%s change #%d?\n\n%s", verb, id, formatJournalEntry(entry, revert))
+	proceed, err = reaper.YesNoBox(confirm, title)
+	if err != nil || !proceed {
+		return
+	}
+
+	// STEP 4: Write the values
+	restoreAndReport(title, entry, revert)
+}
+
+// handleRevertLastAssistantChange puts back the parameter values from before the latest
+// assistant apply. It works from the journal, so it doesn't matter what was undone or
+// done since; the revert is a new undo point.
+func handleRevertLastAssistantChange() {
+	const title = "Revert Last Assistant Change"
+
+	entries := getJournal().recent(1)
+	if len(entries) == 0 {
+		reaper.MessageBox("The FX assistant hasn't applied any changes yet.", title)
+		return
+	}
+	entry := entries[0]
+	if len(entry.Changes) == 0 {
+		reaper.MessageBox(fmt.Sprintf("The last assistant apply (#%d) only changed the FX chain, which can't be reverted here. Use Undo instead.", entry.ID), title)
+		return
+	}
+
+	restoreAndReport(title, entry, true)
+}
+
+// restoreAndReport reverts or re-applies a journal entry and tells the user how it went
+func restoreAndReport(title string, entry journalEntry, revert bool) {
+	verb := "Re-applied"
+	if revert {
+		verb = "Reverted"
+	}
+
+	applied, skipped, err := restoreJournalEntry(entry, revert)
+	if err != nil {
+		core.HandleError(title, core.NewError(core.CategoryUser, fmt.Sprintf("Could not restore change #%d.", entry.ID), err))
+		return
+	}
+
+	logger.Info("%s journaled change #%d: %d parameter(s), %d skipped", verb, entry.ID, applied, len(skipped))
+	result := fmt.Sprintf("%s change #%d (%q on %s): %d parameter(s) set.", verb, entry.ID, entry.Prompt, entry.TrackName, applied)
+	if len(skipped) > 0 {
+		result += fmt.Sprintf("\n\nSkipped %d:\n- %s", len(skipped), strings.Join(skipped, "\n- "))
+	}
+	reaper.MessageBox(result, title)
+}
+
+// formatJournalEntry describes an entry's request and the values a revert or re-apply writes
+func formatJournalEntry(entry journalEntry, revert bool) string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("Track: %s\nRequest: %s\n\n", entry.TrackName, entry.Prompt))
+
+	for _, change := range entry.Changes {
+		from, to := journalValueText(change.After, change.AfterText), journalValueText(change.Before, change.BeforeText)
+		if !revert {
+			from, to = to, from
+		}
+		builder.WriteString(fmt.Sprintf("- %s • %s: %s → %s\n", change.FXName, change.ParamName, from, to))
+	}
+
+	if len(entry.ChainChanges) > 0 {
+		builder.WriteString(fmt.Sprintf("\nChain changes, left as they are:\n- %s\n", strings.Join(entry.ChainChanges, "\n- ")))
+	}
+	if len(entry.ItemChanges) > 0 {
+		builder.WriteString(fmt.Sprintf("\nItem changes, left as they are:\n- %s\n", strings.Join(entry.ItemChanges, "\n- ")))
+	}
+	return builder.String()
+}
+
+// journalValueText shows a journaled value as the plugin displayed it, if known
+func journalValueText(value float64, text string) string {
+	if text != "" {
+		return text
+	}
+	return fmt.Sprintf("%.2f", value)
+}
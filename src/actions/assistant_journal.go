@@ -0,0 +1,343 @@
+package actions
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+// This file keeps a journal of the changes the FX assistant applied: the request, the
+// provider and each parameter's value before and after, so an apply can be reverted or
+// applied again later, in this session or another. Like the LLM response cache, it is a
+// JSON file in the extension's data directory rather than a SQLite database: it is read
+// whole to list recent applies, and capped, so a database would only add a driver to
+// build into the plugin on every platform.
+
+// journalFileName is the journal file inside the extension's data directory
+const journalFileName = "assistant_journal.json"
+
+// maxJournalEntries limits the journal; the oldest applies are dropped beyond it
+const maxJournalEntries = 200
+
+// changeOrigin describes where a set of changes came from
+type changeOrigin struct {
+	Prompt   string // The user's request
+	Source   string // Engine that made the suggestions, "LLM" or "offline assistant"
+	Provider string // Provider and model for LLM suggestions, e.g. "OpenAI gpt-4o"
+}
+
+// journalChange is one parameter an apply changed. FX are found by GUID when reverting
+// or re-applying, since chain changes and the user may move them.
+type journalChange struct {
+	FXGUID     string  `json:"fx_guid"`
+	FXIndex    int     `json:"fx_index"` // Index when applied, for display
+	FXName     string  `json:"fx_name"`
+	ParamIndex int     `json:"param_index"`
+	ParamName  string  `json:"param_name"`
+	Before     float64 `json:"before"`
+	After      float64 `json:"after"`
+	BeforeText string  `json:"before_text,omitempty"`
+	AfterText  string  `json:"after_text,omitempty"`
+}
+
+// journalEntry is one apply of assistant changes
+type journalEntry struct {
+	ID           int             `json:"id"`
+	Time         time.Time       `json:"time"`
+	Prompt       string          `json:"prompt"`
+	Source       string          `json:"source"`
+	Provider     string          `json:"provider,omitempty"`
+	TrackGUID    string          `json:"track_guid"`
+	TrackName    string          `json:"track_name"`
+	Changes      []journalChange `json:"changes"`
+	ChainChanges []string        `json:"chain_changes,omitempty"` // Described only; they aren't reverted
+	ItemChanges  []string        `json:"item_changes,omitempty"`  // Described only; they aren't reverted
+}
+
+// summary describes the entry in one line for lists
+func (e journalEntry) summary() string {
+	origin := e.Source
+	if e.Provider != "" {
+		origin = e.Provider
+	}
+	return fmt.Sprintf("#%d %s, %s: %q (%d change(s), %s)",
+		e.ID, e.Time.Local().Format("2006-01-02 15:04"), e.TrackName, e.Prompt, len(e.Changes)+len(e.ChainChanges)+len(e.ItemChanges), origin)
+}
+
+// assistantJournal stores journal entries, persisted as a JSON file
+type assistantJournal struct {
+	path string
+
+	mutex   sync.Mutex
+	entries []journalEntry // Oldest first
+	loaded  bool
+}
+
+var (
+	journal     *assistantJournal
+	journalOnce sync.Once
+)
+
+// getJournal returns the shared assistant journal
+func getJournal() *assistantJournal {
+	journalOnce.Do(func() {
+		journal = &assistantJournal{path: filepath.Join(extensionDataDir(), journalFileName)}
+	})
+	return journal
+}
+
+// add stores an entry, giving it the next ID, and persists the journal
+func (j *assistantJournal) add(entry journalEntry) (int, error) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	j.loadLocked()
+
+	entry.ID = 1
+	if len(j.entries) > 0 {
+		entry.ID = j.entries[len(j.entries)-1].ID + 1
+	}
+	j.entries = append(j.entries, entry)
+	if len(j.entries) > maxJournalEntries {
+		j.entries = j.entries[len(j.entries)-maxJournalEntries:]
+	}
+
+	return entry.ID, j.saveLocked()
+}
+
+// recent returns up to limit entries, newest first
+func (j *assistantJournal) recent(limit int) []journalEntry {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	j.loadLocked()
+
+	entries := make([]journalEntry, len(j.entries))
+	copy(entries, j.entries)
+	sort.SliceStable(entries, func(a, b int) bool { return entries[a].ID > entries[b].ID })
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries
+}
+
+// get returns the entry with an ID
+func (j *assistantJournal) get(id int) (journalEntry, bool) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	j.loadLocked()
+
+	for _, entry := range j.entries {
+		if entry.ID == id {
+			return entry, true
+		}
+	}
+	return journalEntry{}, false
+}
+
+// loadLocked reads the journal file once; a missing or corrupt file yields an empty journal
+func (j *assistantJournal) loadLocked() {
+	if j.loaded {
+		return
+	}
+	j.loaded = true
+	j.entries = nil
+
+	data, err := os.ReadFile(j.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Warning("Failed to read assistant journal %s: %v", j.path, err)
+		}
+		return
+	}
+
+	if err := json.Unmarshal(data, &j.entries); err != nil {
+		logger.Warning("Failed to parse assistant journal, starting empty: %v", err)
+		j.entries = nil
+	}
+}
+
+// saveLocked writes the journal atomically via a temporary file
+func (j *assistantJournal) saveLocked() error {
+	if err := os.MkdirAll(filepath.Dir(j.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create journal directory: %v", err)
+	}
+
+	data, err := json.MarshalIndent(j.entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal journal: %v", err)
+	}
+
+	tmpPath := j.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write journal: %v", err)
+	}
+	return os.Rename(tmpPath, j.path)
+}
+
+// paramSnapshot is a parameter's value before an apply, with the FX it belongs to
+type paramSnapshot struct {
+	fxGUID string
+	fxName string
+	value  float64
+	text   string
+}
+
+// snapshotParameters reads the current values of the parameters the suggestions change.
+// Parameters that can't be read are left out and won't be journaled.
+func snapshotParameters(track unsafe.Pointer, suggestions []ParameterSuggestion) map[paramKey]paramSnapshot {
+	snapshots := make(map[paramKey]paramSnapshot, len(suggestions))
+	for _, s := range suggestions {
+		key := paramKey{FXIndex: s.FXIndex, ParamIndex: s.ParamIndex}
+		if _, ok := snapshots[key]; ok {
+			continue
+		}
+
+		guid, err := reaper.GetTrackFXGUID(track, s.FXIndex)
+		if err != nil {
+			logger.Warning("Not journaling FX %d parameter %d: %v", s.FXIndex, s.ParamIndex, err)
+			continue
+		}
+		value, err := reaper.GetTrackFXParamValue(track, s.FXIndex, s.ParamIndex)
+		if err != nil {
+			logger.Warning("Not journaling FX %d parameter %d: %v", s.FXIndex, s.ParamIndex, err)
+			continue
+		}
+		name, _ := reaper.GetTrackFXName(track, s.FXIndex)
+		text, _ := reaper.GetTrackFXParamFormatted(track, s.FXIndex, s.ParamIndex)
+
+		snapshots[key] = paramSnapshot{fxGUID: guid, fxName: name, value: value, text: text}
+	}
+	return snapshots
+}
+
+// newJournalEntry describes applied parameter changes, reading their new values. Call it
+// before chain changes, while the suggestions' FX indices still hold.
+func newJournalEntry(track unsafe.Pointer, origin changeOrigin, suggestions []ParameterSuggestion, before map[paramKey]paramSnapshot) journalEntry {
+	entry := journalEntry{
+		Time:     time.Now(),
+		Prompt:   origin.Prompt,
+		Source:   origin.Source,
+		Provider: origin.Provider,
+	}
+
+	guid, err := reaper.GetTrackGUID(track)
+	if err != nil {
+		logger.Warning("Not journaling the changes, the track has no GUID: %v", err)
+		return entry
+	}
+	entry.TrackGUID = guid
+	entry.TrackName, _ = reaper.GetTrackName(track)
+
+	done := make(map[paramKey]bool)
+	for _, s := range suggestions {
+		key := paramKey{FXIndex: s.FXIndex, ParamIndex: s.ParamIndex}
+		snapshot, ok := before[key]
+		if !ok || done[key] {
+			continue
+		}
+		done[key] = true
+
+		after, err := reaper.GetTrackFXParamValue(track, s.FXIndex, s.ParamIndex)
+		if err != nil {
+			logger.Warning("Not journaling FX %d parameter %d: %v", s.FXIndex, s.ParamIndex, err)
+			continue
+		}
+		afterText, _ := reaper.GetTrackFXParamFormatted(track, s.FXIndex, s.ParamIndex)
+
+		entry.Changes = append(entry.Changes, journalChange{
+			FXGUID:     snapshot.fxGUID,
+			FXIndex:    s.FXIndex,
+			FXName:     snapshot.fxName,
+			ParamIndex: s.ParamIndex,
+			ParamName:  s.ParamName,
+			Before:     snapshot.value,
+			After:      after,
+			BeforeText: snapshot.text,
+			AfterText:  afterText,
+		})
+	}
+	return entry
+}
+
+// recordJournalEntry adds an entry to the journal, along with the chain and item changes
+// that were made after its parameter changes. It returns the entry's ID, or 0 if there
+// was nothing to record.
+func recordJournalEntry(entry journalEntry, chainChanges []ChainChange, itemChanges []ItemChange) int {
+	for _, c := range chainChanges {
+		entry.ChainChanges = append(entry.ChainChanges, assistantChange{Chain: &c}.label())
+	}
+	for _, c := range itemChanges {
+		entry.ItemChanges = append(entry.ItemChanges, c.label())
+	}
+	if entry.TrackGUID == "" || len(entry.Changes)+len(entry.ChainChanges)+len(entry.ItemChanges) == 0 {
+		return 0
+	}
+
+	// An entry that couldn't be saved is still in the journal for this session
+	id, err := getJournal().add(entry)
+	if err != nil {
+		logger.Warning("Failed to save the assistant journal: %v", err)
+		return id
+	}
+	logger.Debug("Journaled assistant apply #%d with %d parameter change(s)", id, len(entry.Changes))
+	return id
+}
+
+// restoreJournalEntry writes an entry's before values (revert) or after values
+// (re-apply) back as one undo point. Parameters whose FX is gone are skipped and
+// described in skipped; an error means nothing could be written.
+func restoreJournalEntry(entry journalEntry, revert bool) (applied int, skipped []string, err error) {
+	ref := reaper.TrackRef{GUID: entry.TrackGUID}
+	track, err := ref.Resolve()
+	if err != nil {
+		return 0, nil, fmt.Errorf("the track %q is no longer in the project", entry.TrackName)
+	}
+
+	verb := "re-apply"
+	if revert {
+		verb = "revert"
+	}
+
+	if err := reaper.UndoBeginBlock(); err != nil {
+		logger.Warning("Failed to start undo block: %v", err)
+	} else {
+		defer func() {
+			description := fmt.Sprintf("LLM FX Assistant: %s change #%d", verb, entry.ID)
+			if err := reaper.UndoEndBlock(description, reaper.UndoStateFX); err != nil {
+				logger.Warning("Failed to end undo block: %v", err)
+			}
+		}()
+	}
+
+	for _, change := range entry.Changes {
+		fxIndex, err := reaper.FindTrackFXByGUID(track, change.FXGUID)
+		if err != nil {
+			skipped = append(skipped, fmt.Sprintf("%s %s: the FX was removed", change.FXName, change.ParamName))
+			continue
+		}
+
+		value := change.After
+		if revert {
+			value = change.Before
+		}
+		if err := reaper.SetTrackFXParamValue(track, fxIndex, change.ParamIndex, value); err != nil {
+			skipped = append(skipped, fmt.Sprintf("%s %s: %v", change.FXName, change.ParamName, err))
+			continue
+		}
+		applied++
+	}
+
+	for _, reason := range skipped {
+		logger.Warning("Could not %s journaled change, %s", verb, reason)
+	}
+	return applied, skipped, nil
+}
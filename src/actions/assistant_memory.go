@@ -0,0 +1,103 @@
+package actions
+
+import (
+	"encoding/json"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// assistantMemorySection and assistantMemoryKey hold the project's last assistant
+// request as JSON in project ext state
+const (
+	assistantMemorySection = "GoReaperAssistant"
+	assistantMemoryKey     = "last_request"
+)
+
+// assistantMemory is the last assistant request made in a project, to start the next
+// one from. FX are stored by GUID, so the selection survives reordering the chain.
+type assistantMemory struct {
+	FXGUIDs   []string `json:"fx_guids"`
+	Prompt    string   `json:"prompt"`
+	ChainMode bool     `json:"chain_mode"`
+}
+
+// loadAssistantMemory returns the current project's last assistant request, or an empty
+// one if there is none
+func loadAssistantMemory() assistantMemory {
+	var memory assistantMemory
+
+	data, err := reaper.GetProjExtState(assistantMemorySection, assistantMemoryKey)
+	if err != nil || data == "" {
+		return memory
+	}
+	if err := json.Unmarshal([]byte(data), &memory); err != nil {
+		logger.Warning("Ignoring the project's invalid last assistant request: %v", err)
+		return assistantMemory{}
+	}
+	return memory
+}
+
+// rememberAssistantRequest stores a request as the current project's last one
+func rememberAssistantRequest(track unsafe.Pointer, fxIndices []int, prompt string, chainMode bool) {
+	memory := assistantMemory{Prompt: prompt, ChainMode: chainMode}
+	for _, index := range fxIndices {
+		if guid, err := reaper.GetTrackFXGUID(track, index); err == nil {
+			memory.FXGUIDs = append(memory.FXGUIDs, guid)
+		}
+	}
+
+	saveAssistantMemory(memory)
+}
+
+// rememberAssistantPrompt replaces the prompt of the current project's last request,
+// keeping its FX and chain mode
+func rememberAssistantPrompt(prompt string) {
+	memory := loadAssistantMemory()
+	memory.Prompt = prompt
+	saveAssistantMemory(memory)
+}
+
+// saveAssistantMemory stores a request as the current project's last one
+func saveAssistantMemory(memory assistantMemory) {
+	data, err := json.Marshal(memory)
+	if err != nil {
+		logger.Warning("Failed to encode the assistant request: %v", err)
+		return
+	}
+	if err := reaper.SetProjExtState(assistantMemorySection, assistantMemoryKey, string(data)); err != nil {
+		logger.Warning("Failed to remember the assistant request: %v", err)
+	}
+}
+
+// fxIndices returns the indices of the remembered FX on a track, in chain order, or nil
+// if none of them is on it
+func (m assistantMemory) fxIndices(track unsafe.Pointer, fxList []reaper.FXInfo) []int {
+	if len(m.FXGUIDs) == 0 {
+		return nil
+	}
+	remembered := make(map[string]bool, len(m.FXGUIDs))
+	for _, guid := range m.FXGUIDs {
+		remembered[strings.ToUpper(guid)] = true
+	}
+
+	var indices []int
+	for _, fx := range fxList {
+		guid, err := reaper.GetTrackFXGUID(track, fx.Index)
+		if err == nil && remembered[strings.ToUpper(guid)] {
+			indices = append(indices, fx.Index)
+		}
+	}
+	return indices
+}
+
+// fxSelectionText returns FX indices as the dialog's 1-based, comma-separated numbers
+func fxSelectionText(indices []int) string {
+	numbers := make([]string, len(indices))
+	for i, index := range indices {
+		numbers[i] = strconv.Itoa(index + 1)
+	}
+	return strings.Join(numbers, ", ")
+}
@@ -0,0 +1,1051 @@
+package actions
+
+import (
+	"errors"
+	"fmt"
+	"go-reaper/src/core"
+	"go-reaper/src/llm"
+	"go-reaper/src/pkg/config"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"go-reaper/src/ui"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+// This file implements the FX assistant panel: a persistent window that replaces the
+// dialog chain with FX selection, a prompt, a streamed response and a list of changes
+// that can be accepted individually, auditioned and applied.
+//
+// Widget callbacks run on the main thread and are the only place REAPER is called.
+// The LLM request runs on a goroutine that only talks to the network and the widgets.
+
+// assistantWindowID is the ID the panel is registered under with ui.Windows
+const assistantWindowID = "fx-assistant"
+
+// responseRefreshInterval throttles redrawing the response pane while streaming
+const responseRefreshInterval = 100 * time.Millisecond
+
+// previewInterval throttles writes to the plugin while a preview slider is dragged
+const previewInterval = 30 * time.Millisecond
+
+// assistantChange is one entry in the change list: a parameter suggestion, a chain change
+// or an item change
+type assistantChange struct {
+	Suggestion *ParameterSuggestion
+	Chain      *ChainChange
+	Item       *ItemChange
+}
+
+// label describes the change for the change list
+func (c assistantChange) label() string {
+	if c.Item != nil {
+		return c.Item.label()
+	}
+	if c.Chain != nil {
+		switch c.Chain.Action {
+		case chainActionMove:
+			return fmt.Sprintf("Move FX %d to position %d", c.Chain.FXIndex, c.Chain.ToIndex)
+		case chainActionBypass:
+			return fmt.Sprintf("Bypass FX %d", c.Chain.FXIndex)
+		case chainActionEnable:
+			return fmt.Sprintf("Enable FX %d", c.Chain.FXIndex)
+		default:
+			return fmt.Sprintf("%s FX %d (unsupported, will be skipped)", c.Chain.Action, c.Chain.FXIndex)
+		}
+	}
+
+	return fmt.Sprintf("FX %d • %s: %s", c.Suggestion.FXIndex, c.Suggestion.ParamName, suggestionValueText(*c.Suggestion))
+}
+
+// assistantChanges lists a response's parameter changes followed by its chain and item
+// changes
+func assistantChanges(response *AssistantResponse) []assistantChange {
+	var items []assistantChange
+	for i := range response.Suggestions {
+		items = append(items, assistantChange{Suggestion: &response.Suggestions[i]})
+	}
+	for i := range response.ChainChanges {
+		items = append(items, assistantChange{Chain: &response.ChainChanges[i]})
+	}
+	for i := range response.ItemChanges {
+		items = append(items, assistantChange{Item: &response.ItemChanges[i]})
+	}
+	return items
+}
+
+// splitAssistantChanges separates changes into parameter, chain and item changes
+func splitAssistantChanges(items []assistantChange) ([]ParameterSuggestion, []ChainChange, []ItemChange) {
+	var suggestions []ParameterSuggestion
+	var chainChanges []ChainChange
+	var itemChanges []ItemChange
+	for _, item := range items {
+		switch {
+		case item.Suggestion != nil:
+			suggestions = append(suggestions, *item.Suggestion)
+		case item.Chain != nil:
+			chainChanges = append(chainChanges, *item.Chain)
+		default:
+			itemChanges = append(itemChanges, *item.Item)
+		}
+	}
+	return suggestions, chainChanges, itemChanges
+}
+
+// paramKey identifies a parameter of an FX on the panel's target track
+type paramKey struct {
+	FXIndex    int
+	ParamIndex int
+}
+
+// changeTarget is the track a request was made for and its FX chain at the time, which
+// the changes' indices refer to
+type changeTarget struct {
+	track  reaper.TrackRef
+	chain  reaper.ChainCheck
+	takes  []reaper.TakeInfo // Selected items on the track, which item changes refer to
+	origin changeOrigin      // The request, for the journal
+}
+
+// assistantPanel holds the panel's widgets and state. The mutex guards the state only;
+// it is never held while calling into the widgets, which may wait for the main thread.
+type assistantPanel struct {
+	window     *ui.Window
+	trackLabel *ui.Widget
+	fxList     *ui.Widget
+	chainMode  *ui.Widget
+	prompt     *ui.Widget
+	askButton  *ui.Widget
+	status     *ui.Widget
+	response   *ui.Widget
+	changes    *ui.Widget
+	preview    *ui.Widget
+	slider     *ui.Widget
+	valueLabel *ui.Widget
+	audition   *ui.Widget
+	apply      *ui.Widget
+
+	mutex     sync.Mutex
+	track     reaper.TrackRef      // Selected track when the panel was opened or refreshed
+	trackName string               // Name of that track
+	fxInfo    []reaper.FXInfo      // Its FX chain, without parameters
+	busy      bool                 // A request is in flight
+	target    reaper.TrackRef      // Track the current changes apply to
+	chain     reaper.ChainCheck    // Its FX chain when the changes were requested
+	origin    changeOrigin         // The request the changes answer
+	items     []assistantChange    // Current change list
+	previews  []int                // Change list index of each preview entry
+	originals map[paramKey]float64 // Values from before auditioning or previewing, nil otherwise
+	lastWrite time.Time            // Last preview write to the plugin
+	trailing  bool                 // A preview write is scheduled for a throttled value
+}
+
+var (
+	assistantMutex sync.Mutex
+	assistant      *assistantPanel
+)
+
+// isAssistantPanelOpen reports whether the panel is open
+func isAssistantPanelOpen() bool {
+	assistantMutex.Lock()
+	panel := assistant
+	assistantMutex.Unlock()
+
+	return panel != nil && panel.window.IsOpen()
+}
+
+// showAssistantPanel opens the panel for the selected track, or brings it to the front
+func showAssistantPanel() error {
+	return showAssistantPanelFor(nil, -1)
+}
+
+// showAssistantPanelFor opens the panel for track with the FX at fxIndex checked. A nil
+// track uses the selected track and a negative fxIndex checks the first FX.
+func showAssistantPanelFor(track unsafe.Pointer, fxIndex int) error {
+	assistantMutex.Lock()
+	panel := assistant
+	assistantMutex.Unlock()
+
+	if panel == nil || !panel.window.IsOpen() {
+		var err error
+		if panel, err = newAssistantPanel(); err != nil {
+			return err
+		}
+
+		assistantMutex.Lock()
+		assistant = panel
+		assistantMutex.Unlock()
+	}
+
+	if track == nil {
+		panel.refreshTrack()
+	} else {
+		panel.showTrack(track, fxIndex)
+	}
+	return panel.window.Show()
+}
+
+// newAssistantPanel creates the window and lays out its widgets
+func newAssistantPanel() (*assistantPanel, error) {
+	window, err := ui.NewWindow(assistantWindowID, "LLM FX Assistant", 640, 642)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &assistantPanel{window: window}
+	if err := p.build(); err != nil {
+		window.Close()
+		return nil, err
+	}
+
+	// Start from the project's last request
+	memory := loadAssistantMemory()
+	p.prompt.SetText(memory.Prompt)
+	p.chainMode.SetChecked(memory.ChainMode)
+
+	// Closing the window mid-audition puts the original values back
+	if err := ui.Windows.OnClose(assistantWindowID, p.closed); err != nil {
+		logger.Warning("Failed to observe assistant panel closing: %v", err)
+	}
+
+	return p, nil
+}
+
+// build adds the panel's widgets
+func (p *assistantPanel) build() error {
+	w := p.window
+	var err error
+
+	// Widgets are sized to fit their text; the frames only set minimum sizes
+	if p.trackLabel, err = w.AddLabel(ui.Rect{Width: 300, Height: 20}, "Track:"); err != nil {
+		return err
+	}
+	refresh, err := w.AddButton(ui.Rect{Width: 108, Height: 26}, "Refresh", p.refreshTrack)
+	if err != nil {
+		return err
+	}
+
+	fxLabel, err := w.AddLabel(ui.Rect{Height: 18}, "FX to adjust:")
+	if err != nil {
+		return err
+	}
+	if p.fxList, err = w.AddChecklist(ui.Rect{Width: 300, Height: 118}, nil, nil); err != nil {
+		return err
+	}
+	if p.chainMode, err = w.AddCheckbox(ui.Rect{Height: 20}, "Allow bypass/reorder of the chain", false, nil); err != nil {
+		return err
+	}
+	if p.prompt, err = w.AddMultilineText(ui.Rect{Width: 300, Height: 118}, "", true, nil); err != nil {
+		return err
+	}
+
+	if p.askButton, err = w.AddButton(ui.Rect{Width: 100, Height: 28}, "Ask", p.ask); err != nil {
+		return err
+	}
+	if p.status, err = w.AddLabel(ui.Rect{Width: 300, Height: 20}, "Describe what you want, e.g. \"make the vocal clearer\""); err != nil {
+		return err
+	}
+
+	responseLabel, err := w.AddLabel(ui.Rect{Height: 18}, "Response:")
+	if err != nil {
+		return err
+	}
+	if p.response, err = w.AddMultilineText(ui.Rect{Width: 300, Height: 150}, "", false, nil); err != nil {
+		return err
+	}
+
+	changesLabel, err := w.AddLabel(ui.Rect{Height: 18}, "Changes (uncheck to skip):")
+	if err != nil {
+		return err
+	}
+	if p.changes, err = w.AddChecklist(ui.Rect{Width: 300, Height: 130}, nil, p.changeToggled); err != nil {
+		return err
+	}
+
+	previewLabel, err := w.AddLabel(ui.Rect{Height: 18}, "Preview:")
+	if err != nil {
+		return err
+	}
+	if p.preview, err = w.AddDropdown(ui.Rect{Width: 260, Height: 26}, nil, -1, p.previewSelected); err != nil {
+		return err
+	}
+	if p.slider, err = w.AddSlider(ui.Rect{Width: 120, Height: 22}, 0, 1, 0, p.previewChanged); err != nil {
+		return err
+	}
+	if p.valueLabel, err = w.AddLabel(ui.Rect{Width: 100, Height: 18}, ""); err != nil {
+		return err
+	}
+
+	if p.audition, err = w.AddButton(ui.Rect{Width: 130, Height: 28}, "Audition", p.toggleAudition); err != nil {
+		return err
+	}
+	if p.apply, err = w.AddButton(ui.Rect{Width: 100, Height: 28}, "Apply", p.applyChanges); err != nil {
+		return err
+	}
+	copyButton, err := w.AddButton(ui.Rect{Width: 120, Height: 28}, "Copy Response", p.copyResponse)
+	if err != nil {
+		return err
+	}
+	dock, err := w.AddButton(ui.Rect{Width: 100, Height: 28}, "Dock", p.toggleDock)
+	if err != nil {
+		return err
+	}
+
+	// Captions sit closer to their widget than to the widget above
+	row := func() *ui.Stack { return ui.NewHStack(0, ui.LayoutSpacing) }
+	captioned := func(caption, widget ui.Layout) *ui.Stack {
+		return ui.NewVStack(0, 4).Add(caption).Fill(widget)
+	}
+	err = w.SetLayout(ui.NewVStack(ui.LayoutMargin, ui.LayoutSpacing).
+		Add(row().Fill(p.trackLabel).Add(refresh)).
+		Add(row().Fill(captioned(fxLabel, p.fxList)).Fill(captioned(p.chainMode, p.prompt))).
+		Add(row().Add(p.askButton).Fill(p.status)).
+		Fill(captioned(responseLabel, p.response)).
+		Fill(captioned(changesLabel, p.changes)).
+		Add(row().Add(previewLabel, p.preview).Fill(p.slider).Add(p.valueLabel)).
+		Add(row().Add(p.audition, p.apply).Fill(ui.Spacer()).Add(copyButton, dock)))
+	if err != nil {
+		return err
+	}
+
+	// Name the widgets for VoiceOver, which doesn't see their captions. Tab follows the
+	// layout, and Return types a new line in the request, so there's no default button.
+	widgetNames := map[*ui.Widget]string{
+		p.fxList: "FX to adjust", p.prompt: "Request", p.response: "Response",
+		p.changes: "Changes", p.preview: "Preview parameter", p.slider: "Preview value",
+	}
+	for widget, name := range widgetNames {
+		if err := widget.SetAccessibilityLabel(name); err != nil {
+			return err
+		}
+	}
+
+	p.setChanges(changeTarget{}, nil)
+	return nil
+}
+
+// copyResponse copies the response text, e.g. to keep the LLM's reasoning or share it
+func (p *assistantPanel) copyResponse() {
+	text := p.response.Text()
+	if strings.TrimSpace(text) == "" {
+		p.setStatus("There is no response to copy yet")
+		return
+	}
+	if err := ui.SetClipboardText(text); err != nil {
+		logger.Warning("Failed to copy the assistant response: %v", err)
+		p.setStatus("Could not copy the response: %v", err)
+		return
+	}
+	p.setStatus("Copied the response to the clipboard")
+}
+
+// setStatus shows a one-line status message
+func (p *assistantPanel) setStatus(format string, args ...interface{}) {
+	if err := p.status.SetText(fmt.Sprintf(format, args...)); err != nil {
+		logger.Warning("Failed to update assistant status: %v", err)
+	}
+}
+
+// refreshTrack loads the selected track and its FX chain
+func (p *assistantPanel) refreshTrack() {
+	p.stopAudition()
+
+	trackInfo, err := reaper.GetSelectedTrackInfo()
+	if err != nil {
+		p.mutex.Lock()
+		p.track, p.trackName, p.fxInfo = reaper.TrackRef{}, "", nil
+		p.mutex.Unlock()
+
+		p.trackLabel.SetText("Track: (none selected)")
+		p.fxList.SetItems(nil)
+		p.setStatus("Select a track, then press Refresh")
+		return
+	}
+
+	p.loadTrack(trackInfo, -1)
+}
+
+// showTrack loads a given track, e.g. the one a context menu was opened on
+func (p *assistantPanel) showTrack(track unsafe.Pointer, fxIndex int) {
+	trackInfo, err := reaper.GetTrackInfo(track)
+	if err != nil {
+		logger.Error("Error getting track info: %v", err)
+		p.refreshTrack()
+		return
+	}
+
+	p.stopAudition()
+	p.loadTrack(trackInfo, fxIndex)
+}
+
+// loadTrack shows a track's FX chain with the FX at fxIndex checked. When fxIndex is out
+// of range, the FX of the project's last request are checked if they are on the track,
+// and otherwise the first FX.
+func (p *assistantPanel) loadTrack(trackInfo *reaper.TrackInfo, fxIndex int) {
+	fxList, err := reaper.GetTrackFXSummaries(trackInfo.MediaTrack)
+	if err != nil {
+		logger.Error("Error getting FX list: %v", err)
+		fxList = nil
+	}
+
+	ref, err := reaper.NewTrackRef(trackInfo.MediaTrack)
+	if err != nil {
+		logger.Error("Error getting track GUID: %v", err)
+	}
+
+	p.mutex.Lock()
+	p.track, p.trackName, p.fxInfo = ref, trackInfo.Name, fxList
+	p.mutex.Unlock()
+
+	p.trackLabel.SetText("Track: " + trackInfo.Name)
+	frozen := reaper.IsTrackFrozen(trackInfo.MediaTrack)
+
+	items := make([]string, len(fxList))
+	for i, fx := range fxList {
+		state := ""
+		switch {
+		case fx.Offline:
+			state = " (offline)"
+		case !fx.Enabled:
+			state = " (bypassed)"
+		}
+		items[i] = fmt.Sprintf("%d. %s%s", i+1, fx.Name, state)
+	}
+	p.fxList.SetItems(items)
+
+	if frozen {
+		p.setStatus("%s is frozen; unfreeze it to change its FX", trackInfo.Name)
+		return
+	}
+	if len(fxList) == 0 {
+		p.setStatus("%s has no FX", trackInfo.Name)
+		return
+	}
+
+	checked := []int{fxIndex}
+	if fxIndex < 0 || fxIndex >= len(fxList) {
+		checked = loadAssistantMemory().fxIndices(trackInfo.MediaTrack, fxList)
+		if len(checked) == 0 {
+			// Default to the first FX, as the dialog does
+			checked = []int{0}
+		}
+	}
+	for _, index := range checked {
+		p.fxList.SetItemChecked(index, true)
+	}
+	p.setStatus("%d FX on %s", len(fxList), trackInfo.Name)
+}
+
+// selectedFX returns the indices of the checked FX
+func (p *assistantPanel) selectedFX(count int) []int {
+	var indices []int
+	for i := 0; i < count; i++ {
+		if p.fxList.ItemChecked(i) {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+// ask sends the prompt for the checked FX. Parameters are collected here on the main
+// thread; only the request itself runs in the background.
+func (p *assistantPanel) ask() {
+	p.mutex.Lock()
+	busy, ref, trackName, fxList := p.busy, p.track, p.trackName, p.fxInfo
+	p.mutex.Unlock()
+
+	if busy {
+		return
+	}
+	track, err := ref.Resolve()
+	if err != nil {
+		p.setStatus("The track is gone; select a track and press Refresh")
+		return
+	}
+	if message := frozenTrackMessage(track, trackName); message != "" {
+		p.response.SetText(message)
+		p.setStatus("%s is frozen; unfreeze it to change its FX", trackName)
+		return
+	}
+
+	indices := p.selectedFX(len(fxList))
+	if len(indices) == 0 {
+		p.setStatus("Check at least one FX to adjust")
+		return
+	}
+
+	userPrompt := strings.TrimSpace(p.prompt.Text())
+	if userPrompt == "" {
+		p.setStatus("Type a request first")
+		return
+	}
+
+	// A new request replaces the current changes
+	p.stopAudition()
+	p.setChanges(changeTarget{}, nil)
+	p.response.SetText("")
+
+	fxParameters := collectFXParameters(track, indices, fxList)
+	chainMode := p.chainMode.Checked()
+	rememberAssistantRequest(track, indices, userPrompt, chainMode)
+
+	// The changes refer to FX and parameters by index, so remember what they point at
+	chain, err := reaper.CaptureChainCheck(track)
+	if err != nil {
+		p.setStatus("Could not read the FX chain: %v", err)
+		return
+	}
+	target := changeTarget{track: ref, chain: chain, takes: trackTakes(track), origin: changeOrigin{Prompt: userPrompt, Source: "offline assistant"}}
+
+	logger.Info("Assistant panel request for %d FX (chain mode: %v): %s", len(indices), chainMode, userPrompt)
+
+	provider := config.GetActiveProvider()
+	apiKey, err := getProviderAPIKey(provider)
+	if err != nil {
+		logger.Info("No API key available (%v), using offline assistant", err)
+		p.response.SetText("No API key is configured, so the offline assistant answered. It understands simple requests such as \"warmer\", \"brighter\", \"more compression\" or \"more reverb\".")
+		response := suggestHeuristicChanges(fxParameters, userPrompt)
+		suggestHeuristicItemChanges(response, target.takes, userPrompt)
+		p.showResult(target, response, "offline assistant")
+		return
+	}
+	target.origin.Source, target.origin.Provider = "LLM", providerModelName(provider)
+
+	systemPrompt := buildSystemPrompt(trackName, userPrompt)
+	model, maxTokens, _ := config.GetProviderConfig(provider)
+	budget := llm.PromptBudget(model, systemPrompt, maxTokens)
+	input := promptInput{
+		TrackName: trackName,
+		FXList:    fxParameters,
+		Items:     buildItemPrompt(target.takes),
+		Sections:  buildSectionPrompt(track),
+		Request:   userPrompt,
+	}
+	if chainMode {
+		input.Chain = buildChainPrompt(fxList)
+	}
+	userPrompts, promptNotes := buildUserPrompts(input, budget)
+
+	var preamble string
+	if len(promptNotes) > 0 {
+		logger.Warning("Prompt adjusted to fit %d token budget: %v", budget, promptNotes)
+		preamble = fmt.Sprintf("Note: the prompt was adjusted to fit %s.\n- %s\n\n", model, strings.Join(promptNotes, "\n- "))
+	}
+
+	llmClient := newLLMClient(provider, apiKey)
+	deferUsage(llmClient)
+
+	var client llm.Client = newRetryClient(llmClient)
+	if config.GetCacheResponses() {
+		client = llm.NewCachingClient(client, getResponseCache(), cacheNamespace(provider))
+	}
+
+	p.mutex.Lock()
+	p.busy = true
+	p.mutex.Unlock()
+
+	p.askButton.SetEnabled(false)
+	p.response.SetText(preamble)
+	p.setStatus("Asking %s...", providerDisplayName(provider))
+
+	if len(userPrompts) > 1 {
+		core.Go("assistant request", func() { p.requestInParts(client, target, systemPrompt, userPrompts, preamble) })
+		return
+	}
+	core.Go("assistant request", func() { p.request(client, target, systemPrompt, userPrompts[0], preamble) })
+}
+
+// requestInParts runs the requests for a prompt split into parts, one after another, and
+// shows the merged suggestions. Parts aren't streamed, as their responses are merged.
+func (p *assistantPanel) requestInParts(client llm.Client, target changeTarget, systemPrompt string, userPrompts []string, preamble string) {
+	assistantResponse, err := askAssistantInParts(client, systemPrompt, userPrompts, func(part, parts int) {
+		p.setStatus("Asking part %d of %d...", part, parts)
+	})
+
+	p.mutex.Lock()
+	p.busy = false
+	p.mutex.Unlock()
+	p.askButton.SetEnabled(true)
+
+	if err != nil {
+		logger.Error("Error calling LLM API: %v", err)
+		p.setStatus("The request failed: %s", llm.UserMessage(err))
+		return
+	}
+
+	p.response.SetText(preamble + formatAssistantResults(assistantResponse))
+	p.showResult(target, assistantResponse, "LLM")
+}
+
+// request runs the LLM request and streams the response into the response pane
+func (p *assistantPanel) request(client llm.Client, target changeTarget, systemPrompt, userPrompt, preamble string) {
+	var streamed strings.Builder
+	var lastDraw time.Time
+
+	onDelta := func(delta string) {
+		streamed.WriteString(delta)
+		if time.Since(lastDraw) >= responseRefreshInterval {
+			lastDraw = time.Now()
+			p.response.SetText(preamble + streamed.String())
+		}
+	}
+
+	responseText, err := sendAssistantPromptStream(client, systemPrompt, userPrompt, onDelta)
+
+	p.mutex.Lock()
+	p.busy = false
+	p.mutex.Unlock()
+	p.askButton.SetEnabled(true)
+
+	if err != nil {
+		logger.Error("Error calling LLM API: %v", err)
+		p.response.SetText(preamble + streamed.String())
+		p.setStatus("The request failed: %s", llm.UserMessage(err))
+		return
+	}
+
+	logger.Info("LLM Response: %s", responseText)
+
+	assistantResponse, err := parseAssistantResponse(responseText)
+	if err != nil {
+		logger.Error("Error parsing LLM response: %v", err)
+		p.response.SetText(preamble + responseText)
+		p.setStatus("Could not read the response: %v", err)
+		return
+	}
+
+	// Show the reasoning rather than raw JSON once the response is complete
+	p.response.SetText(preamble + formatAssistantResults(assistantResponse))
+	p.showResult(target, assistantResponse, "LLM")
+}
+
+// showResult fills the change list with a response's changes, all accepted
+func (p *assistantPanel) showResult(target changeTarget, response *AssistantResponse, source string) {
+	resolveItemChanges(response, target.takes)
+	items := assistantChanges(response)
+
+	if source != "LLM" {
+		p.response.SetText(p.response.Text() + "\n\n" + formatAssistantResults(response))
+	}
+
+	p.setChanges(target, items)
+
+	if len(items) == 0 {
+		p.setStatus("The %s did not suggest any changes", source)
+		return
+	}
+	p.setStatus("The %s suggests %d change(s)", source, len(items))
+}
+
+// setChanges replaces the change list, checking every entry
+func (p *assistantPanel) setChanges(target changeTarget, items []assistantChange) {
+	p.mutex.Lock()
+	p.target, p.chain, p.origin, p.items = target.track, target.chain, target.origin, items
+	p.mutex.Unlock()
+
+	labels := make([]string, len(items))
+	for i, item := range items {
+		labels[i] = item.label()
+	}
+	p.changes.SetItems(labels)
+	for i := range items {
+		p.changes.SetItemChecked(i, true)
+	}
+
+	// Chain and item changes have nothing to preview
+	var previews []int
+	var previewLabels []string
+	for i, item := range items {
+		if item.Suggestion != nil {
+			previews = append(previews, i)
+			previewLabels = append(previewLabels, fmt.Sprintf("FX %d • %s", item.Suggestion.FXIndex, item.Suggestion.ParamName))
+		}
+	}
+
+	p.mutex.Lock()
+	p.previews = previews
+	p.mutex.Unlock()
+
+	p.preview.SetItems(previewLabels)
+	p.preview.SetEnabled(len(previews) > 0)
+	p.slider.SetEnabled(len(previews) > 0)
+	if len(previews) > 0 {
+		p.preview.SetValue(0)
+		p.previewSelected(0)
+	} else {
+		p.valueLabel.SetText("")
+	}
+
+	p.audition.SetEnabled(len(items) > 0)
+	p.apply.SetEnabled(len(items) > 0)
+}
+
+// previewSuggestion returns the change list index and suggestion of a preview entry
+func (p *assistantPanel) previewSuggestion(index int) (int, *ParameterSuggestion) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if index < 0 || index >= len(p.previews) {
+		return -1, nil
+	}
+	item := p.previews[index]
+	return item, p.items[item].Suggestion
+}
+
+// previewSelected shows the suggested value of the picked parameter on the slider
+func (p *assistantPanel) previewSelected(index int) {
+	_, suggestion := p.previewSuggestion(index)
+	if suggestion == nil {
+		return
+	}
+
+	p.slider.SetValue(suggestion.Value)
+	p.valueLabel.SetText(suggestionValueText(*suggestion))
+}
+
+// previewChanged makes the slider's value the suggestion for its parameter and writes it
+// to the plugin, auditioning the accepted changes if that isn't already happening
+func (p *assistantPanel) previewChanged(value float64) {
+	item, suggestion := p.previewSuggestion(int(p.preview.Value()))
+	if suggestion == nil {
+		return
+	}
+
+	// The slider now decides the value, so any unit or note length value no longer applies
+	p.mutex.Lock()
+	suggestion.Value = value
+	suggestion.UnitValue = nil
+	suggestion.NoteLength = ""
+	target, chain, auditioning := p.target, p.chain, p.originals != nil
+	label := p.items[item].label()
+	p.mutex.Unlock()
+
+	track, err := target.Resolve()
+	if err != nil {
+		track = nil
+	}
+
+	p.changes.SetItemText(item, label)
+	p.changes.SetItemChecked(item, true)
+
+	if !auditioning {
+		// Auditioning writes the new value along with the other accepted changes
+		p.startAudition()
+		p.valueLabel.SetText(p.formattedValue(track, *suggestion))
+		return
+	}
+
+	// Throttle writes while dragging; Audition and Apply always use the latest value.
+	// A skipped write schedules one for when the interval is up, so the value the drag
+	// ends on reaches the plugin.
+	p.mutex.Lock()
+	due := time.Since(p.lastWrite) >= previewInterval
+	schedule := !due && !p.trailing
+	if due {
+		p.lastWrite = time.Now()
+	} else {
+		p.trailing = true
+	}
+	p.mutex.Unlock()
+
+	if schedule {
+		reaper.After(previewInterval, p.writeTrailingPreview)
+	}
+	if !due || track == nil {
+		return
+	}
+	if kept, _, _, err := checkStaleChanges(track, chain, []ParameterSuggestion{*suggestion}, nil); err != nil || len(kept) == 0 {
+		p.setStatus("The FX chain changed since the request; ask again")
+		return
+	}
+
+	p.remember(track, *suggestion)
+	if err := reaper.SetTrackFXParamValue(track, suggestion.FXIndex, suggestion.ParamIndex, value); err != nil {
+		logger.Warning("Failed to preview FX %d parameter %d: %v", suggestion.FXIndex, suggestion.ParamIndex, err)
+		return
+	}
+	p.valueLabel.SetText(p.formattedValue(track, *suggestion))
+}
+
+// writeTrailingPreview writes the slider's value after throttled preview writes, while
+// the panel is still open and auditioning
+func (p *assistantPanel) writeTrailingPreview() {
+	p.mutex.Lock()
+	p.trailing = false
+	auditioning := p.originals != nil
+	p.mutex.Unlock()
+
+	if auditioning && p.window.IsOpen() {
+		p.previewChanged(p.slider.Value())
+	}
+}
+
+// formattedValue returns the plugin's display of a parameter, or the suggestion's value
+func (p *assistantPanel) formattedValue(track unsafe.Pointer, suggestion ParameterSuggestion) string {
+	if track != nil {
+		if formatted, err := reaper.GetTrackFXParamFormatted(track, suggestion.FXIndex, suggestion.ParamIndex); err == nil && formatted != "" {
+			return formatted
+		}
+	}
+	return suggestionValueText(suggestion)
+}
+
+// suggestionValueText describes a suggested value in the units it was given in
+func suggestionValueText(s ParameterSuggestion) string {
+	switch {
+	case s.NoteLength != "":
+		return s.NoteLength + " (tempo-synced)"
+	case s.UnitValue != nil:
+		return fmt.Sprintf("%g", *s.UnitValue)
+	default:
+		return fmt.Sprintf("%.2f", s.Value)
+	}
+}
+
+// accepted returns the checked changes that still match the FX chain and items, the
+// track they apply to and the ones skipped because the chain or items changed. The track
+// is nil if it is no longer in the project; an error means the chain changed too much to
+// apply any change.
+func (p *assistantPanel) accepted() (unsafe.Pointer, []ParameterSuggestion, []ChainChange, []ItemChange, []string, error) {
+	p.mutex.Lock()
+	target, chain, items := p.target, p.chain, p.items
+	p.mutex.Unlock()
+
+	track, err := target.Resolve()
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil
+	}
+
+	var checked []assistantChange
+	for i, item := range items {
+		if p.changes.ItemChecked(i) {
+			checked = append(checked, item)
+		}
+	}
+
+	suggestions, chainChanges, itemChanges := splitAssistantChanges(checked)
+	suggestions, chainChanges, skipped, err := checkStaleChanges(track, chain, suggestions, chainChanges)
+	itemChanges, skippedItems := checkItemChanges(itemChanges)
+	return track, suggestions, chainChanges, itemChanges, append(skipped, skippedItems...), err
+}
+
+// changeToggled re-applies the audition so it always reflects the accepted changes
+func (p *assistantPanel) changeToggled(index int, checked bool) {
+	p.mutex.Lock()
+	auditioning := p.originals != nil
+	p.mutex.Unlock()
+
+	if auditioning {
+		p.stopAudition()
+		p.startAudition()
+	}
+}
+
+// toggleAudition starts or stops auditioning the accepted parameter changes
+func (p *assistantPanel) toggleAudition() {
+	p.mutex.Lock()
+	auditioning := p.originals != nil
+	p.mutex.Unlock()
+
+	if auditioning {
+		p.stopAudition()
+		p.setStatus("Audition stopped, original values restored")
+		return
+	}
+	p.startAudition()
+}
+
+// remember records a parameter's current value the first time it is auditioned or previewed
+func (p *assistantPanel) remember(track unsafe.Pointer, s ParameterSuggestion) {
+	key := paramKey{FXIndex: s.FXIndex, ParamIndex: s.ParamIndex}
+
+	p.mutex.Lock()
+	_, known := p.originals[key]
+	p.mutex.Unlock()
+	if known {
+		return
+	}
+
+	value, err := reaper.GetTrackFXParamValue(track, s.FXIndex, s.ParamIndex)
+	if err != nil {
+		logger.Warning("Cannot audition FX %d parameter %d: %v", s.FXIndex, s.ParamIndex, err)
+		return
+	}
+
+	p.mutex.Lock()
+	if p.originals == nil {
+		p.originals = make(map[paramKey]float64)
+	}
+	p.originals[key] = value
+	p.mutex.Unlock()
+}
+
+// startAudition remembers the current values of the accepted parameters, then applies them.
+// Chain changes are left out; they are only made by Apply.
+func (p *assistantPanel) startAudition() {
+	track, suggestions, _, _, skipped, err := p.accepted()
+	if track == nil {
+		p.setStatus("The track for these changes is gone")
+		return
+	}
+	if err != nil {
+		p.setStatus("The FX chain changed since the request (%v); ask again", err)
+		return
+	}
+
+	p.mutex.Lock()
+	if p.originals == nil {
+		p.originals = make(map[paramKey]float64)
+	}
+	p.mutex.Unlock()
+
+	for _, s := range suggestions {
+		p.remember(track, s)
+	}
+
+	if err := applyParameterChanges(track, suggestions); err != nil {
+		logger.Error("Error auditioning changes: %v", err)
+		p.setStatus("Audition failed: %v", err)
+	} else {
+		p.setStatus("Auditioning %d parameter change(s)%s", len(suggestions), skippedNote(skipped))
+	}
+	p.audition.SetText("Stop Audition")
+}
+
+// stopAudition puts back the values from before auditioning, if auditioning
+func (p *assistantPanel) stopAudition() {
+	p.mutex.Lock()
+	target, chain, originals := p.target, p.chain, p.originals
+	p.originals = nil
+	p.mutex.Unlock()
+
+	if originals == nil {
+		return
+	}
+
+	p.audition.SetText("Audition")
+	restoreParameters(target, chain, originals)
+}
+
+// restoreParameters writes back remembered parameter values, leaving out FX that no
+// longer match the chain the values were remembered from
+func restoreParameters(target reaper.TrackRef, chain reaper.ChainCheck, originals map[paramKey]float64) {
+	track, err := target.Resolve()
+	if err != nil {
+		logger.Warning("Cannot restore auditioned values: %v", err)
+		return
+	}
+
+	stale, err := chain.Verify(track)
+	if err != nil {
+		logger.Warning("Cannot restore auditioned values, the FX chain changed: %v", err)
+		return
+	}
+
+	for key, value := range originals {
+		if reason, ok := stale[key.FXIndex]; ok {
+			logger.Warning("Not restoring FX %d parameter %d: %s", key.FXIndex, key.ParamIndex, reason)
+			continue
+		}
+		if err := reaper.SetTrackFXParamValue(track, key.FXIndex, key.ParamIndex, value); err != nil {
+			logger.Warning("Failed to restore FX %d parameter %d: %v", key.FXIndex, key.ParamIndex, err)
+		}
+	}
+}
+
+// applyChanges applies the accepted changes and clears the list, since chain moves
+// can't be applied twice
+func (p *assistantPanel) applyChanges() {
+	track, suggestions, chainChanges, itemChanges, skipped, err := p.accepted()
+	if track == nil {
+		p.setStatus("The track for these changes is gone")
+		return
+	}
+	if err != nil {
+		p.setStatus("The FX chain changed since the request (%v); ask again", err)
+		return
+	}
+
+	p.mutex.Lock()
+	origin := p.origin
+	p.mutex.Unlock()
+
+	if len(suggestions)+len(chainChanges)+len(itemChanges) == 0 {
+		if len(skipped) > 0 {
+			p.setStatus("No changes still match the FX chain%s", skippedNote(skipped))
+			return
+		}
+		p.setStatus("No changes are checked")
+		return
+	}
+
+	// Put back anything auditioned or previewed so the undo point covers every change,
+	// then apply for real
+	p.stopAudition()
+
+	if _, err := applyAssistantChanges(track, suggestions, chainChanges, itemChanges, origin); err != nil {
+		logger.Error("Error applying changes: %v", err)
+		p.setStatus("Error applying changes: %v", err)
+		p.setChanges(changeTarget{}, nil)
+		return
+	}
+
+	logger.Info("Applied %d parameter, %d chain and %d item change(s)", len(suggestions), len(chainChanges), len(itemChanges))
+	p.setChanges(changeTarget{}, nil)
+	p.setStatus("Applied %d change(s)%s", len(suggestions)+len(chainChanges)+len(itemChanges), skippedNote(skipped))
+
+	if len(chainChanges) > 0 {
+		// Positions and bypass states changed
+		p.refreshTrack()
+	}
+}
+
+// toggleDock moves the panel in or out of REAPER's docker
+func (p *assistantPanel) toggleDock() {
+	if err := p.window.ToggleDock(); err != nil {
+		logger.Warning("Failed to toggle docking: %v", err)
+		p.setStatus("Docking failed: %v", err)
+	}
+}
+
+// closed restores auditioned values and forgets the panel
+func (p *assistantPanel) closed() {
+	p.mutex.Lock()
+	target, chain, originals := p.target, p.chain, p.originals
+	p.originals = nil
+	p.mutex.Unlock()
+
+	// Unapplied previews are reverted; the widgets are gone, so skip stopAudition
+	if originals != nil {
+		restoreParameters(target, chain, originals)
+		logger.Info("Assistant panel closed while auditioning, original values restored")
+	}
+
+	assistantMutex.Lock()
+	if assistant == p {
+		assistant = nil
+	}
+	assistantMutex.Unlock()
+}
+
+// sendAssistantPromptStream is like sendAssistantPrompt, streaming the response when the
+// client supports it
+func sendAssistantPromptStream(client llm.Client, systemPrompt, userPrompt string, onDelta llm.StreamHandler) (string, error) {
+	streaming, ok := client.(llm.StreamingClient)
+	if !ok {
+		return sendAssistantPrompt(client, systemPrompt, userPrompt)
+	}
+
+	schema := assistantResponseSchema
+	response, err := streaming.SendPromptStream(systemPrompt, userPrompt, &schema, onDelta)
+	if errors.Is(err, llm.ErrStructuredOutputUnsupported) {
+		logger.Info("Provider does not support structured output, using plain prompt")
+		response, err = streaming.SendPromptStream(systemPrompt, userPrompt, nil, onDelta)
+	}
+	if errors.Is(err, llm.ErrStreamingUnsupported) {
+		return sendAssistantPrompt(client, systemPrompt, userPrompt)
+	}
+	return response, err
+}
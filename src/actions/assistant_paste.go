@@ -0,0 +1,62 @@
+package actions
+
+import (
+	"errors"
+	"go-reaper/src/core"
+	"go-reaper/src/pkg/config"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"go-reaper/src/ui"
+	"strings"
+)
+
+// maxPastedPromptLength keeps a pasted request well within GetUserInputs' buffer, which
+// holds the other fields too
+const maxPastedPromptLength = 4000
+
+// The action that starts the assistant with the clipboard's text as the request
+func init() {
+	registerAction(Action{
+		ID:      "GO_FX_ASSISTANT_PASTE",
+		Feature: config.FeatureAssistant,
+		Name:    "Go: LLM FX Assistant with request from clipboard",
+		Handler: handlePasteAssistantPrompt,
+	})
+}
+
+// handlePasteAssistantPrompt makes the clipboard's text the project's last request, which
+// the assistant dialog and panel start from, and opens the assistant. Typing or pasting
+// into a dialog field loses line breaks and can run out of room; this doesn't.
+func handlePasteAssistantPrompt() {
+	const title = "LLM FX Assistant"
+
+	// Dialog fields are single lines, so the remembered request is flattened
+	line, err := ui.ClipboardLine(maxPastedPromptLength)
+	if err != nil {
+		message := "Could not read the clipboard."
+		if errors.Is(err, reaper.ErrSWSNotInstalled) {
+			message = "Reading the clipboard needs the SWS extension on this system."
+		}
+		core.HandleError(title, core.NewError(core.CategoryReaper, message, err))
+		return
+	}
+	if line == "" {
+		reaper.MessageBox("The clipboard holds no text. Copy the request you want to send first.", title)
+		return
+	}
+
+	logger.Info("Using a %d-byte request from the clipboard", len(line))
+	rememberAssistantPrompt(line)
+	handleFXAssistant()
+
+	// The panel's prompt is multi-line, so it gets the text as copied; an open panel
+	// doesn't reload the remembered request either
+	assistantMutex.Lock()
+	panel := assistant
+	assistantMutex.Unlock()
+	if panel != nil && panel.window.IsOpen() {
+		if text, err := ui.ClipboardText(); err == nil && strings.TrimSpace(text) != "" {
+			panel.prompt.SetText(strings.TrimSpace(text))
+		}
+	}
+}
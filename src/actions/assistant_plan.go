@@ -0,0 +1,644 @@
+package actions
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-reaper/src/core"
+	"go-reaper/src/llm"
+	"go-reaper/src/pkg/config"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+// This file implements assistant plans: for a goal such as "make the vocal sit in the
+// mix", the LLM suggests a few steps, e.g. EQ first, then compression. Each step is
+// applied only when confirmed. The action then returns so the user can play the track,
+// and the next run asks whether the step made it better or worse. The feedback goes
+// back to the LLM, which revises the remaining steps.
+
+// maxPlanSteps caps the steps of one plan, including those done, so revisions can't go on forever
+const maxPlanSteps = 8
+
+// planSystemPrompt is added to the system prompt to ask for a plan instead of one set of changes
+const planSystemPrompt = `
+
+PLAN MODE:
+Instead of a single set of suggestions, return a plan of two to five steps that reach the goal one stage at a time, for example EQ first, then compression, then reverb. The user applies each step, listens, and says whether it sounded better or worse before the next one. Respond with JSON of this structure:
+{
+  "steps": [
+    {
+      "title": "<short title such as 'Cut low-mid mud on the EQ'>",
+      "listen_for": "<what the user should listen for after this step>",
+      "suggestions": [<parameter suggestions for this step, as described above>]
+    }
+  ],
+  "reasoning": "<your overall approach>"
+}
+A step may have no suggestions when it only asks the user to listen, e.g. a final "listen and refine" step.
+When the prompt lists the steps already done and the user's feedback on them, the parameter values are the current ones. Return only the steps still to do, revised in light of the feedback: undo or soften what made it worse, and build on what made it better. Return no steps when the goal has been reached.`
+
+// AssistantPlan is the LLM's plan for reaching a goal
+type AssistantPlan struct {
+	Steps     []PlanStep `json:"steps"`
+	Reasoning string     `json:"reasoning"`
+}
+
+// PlanStep is one stage of a plan
+type PlanStep struct {
+	Title       string                `json:"title"`
+	ListenFor   string                `json:"listen_for" desc:"What to listen for after this step"`
+	Suggestions []ParameterSuggestion `json:"suggestions"`
+}
+
+// assistantPlanSchema is the JSON schema structured-output providers must follow
+var assistantPlanSchema = llm.SchemaFromType("fx_plan", AssistantPlan{})
+
+// Outcomes of a plan step
+const (
+	planStepApplied  = "applied"
+	planStepSkipped  = "skipped"
+	planStepReverted = "reverted"
+	planStepListened = "listened"
+)
+
+// planStepRecord is a step the user has been through, for the plan's history
+type planStepRecord struct {
+	Number    int
+	Title     string
+	ListenFor string
+	Outcome   string
+	Changes   int
+	JournalID int    // Journal entry of the applied changes, to revert them
+	Feedback  string // "better", "worse" or "same"
+	Comment   string
+}
+
+// activePlan is a plan in progress. The user runs the action between steps, so it is
+// kept until the plan ends.
+type activePlan struct {
+	Track     reaper.TrackRef
+	TrackName string
+	FXGUIDs   []string // FX the plan adjusts, by GUID so moving them doesn't break the plan
+	Goal      string
+	Provider  config.Provider
+	Reasoning string
+	Steps     []PlanStep // Steps still to do, next first
+	History   []planStepRecord
+	Pending   *planStepRecord // Applied step waiting for feedback
+}
+
+var (
+	planMutex   sync.Mutex
+	currentPlan *activePlan
+	planWaiting bool // A plan request is waiting for the LLM
+)
+
+// The assistant plan action; it is on while a plan is in progress
+func init() {
+	registerAction(Action{
+		ID:          "GO_FX_ASSISTANT_PLAN",
+		Feature:     config.FeatureAssistant,
+		Name:        "Go: LLM FX Assistant plan (step by step)",
+		Handler:     handleAssistantPlan,
+		ToggleState: isPlanActive,
+	})
+}
+
+// isPlanActive reports whether a plan is in progress
+func isPlanActive() bool {
+	planMutex.Lock()
+	defer planMutex.Unlock()
+	return currentPlan != nil
+}
+
+// setCurrentPlan sets or, with nil, ends the plan in progress
+func setCurrentPlan(plan *activePlan) {
+	planMutex.Lock()
+	currentPlan = plan
+	planMutex.Unlock()
+}
+
+// setPlanWaiting marks whether a plan request is waiting for the LLM
+func setPlanWaiting(waiting bool) {
+	planMutex.Lock()
+	planWaiting = waiting
+	planMutex.Unlock()
+}
+
+// handleAssistantPlan starts a plan, or continues the one in progress
+func handleAssistantPlan() {
+	planMutex.Lock()
+	plan, waiting := currentPlan, planWaiting
+	planMutex.Unlock()
+
+	if waiting {
+		reaper.MessageBox("The LLM is still working on the plan. It will be shown when it is ready.", "LLM FX Assistant Plan")
+		return
+	}
+	if plan != nil {
+		continuePlan(plan)
+		return
+	}
+	startPlan()
+}
+
+// startPlan asks for a goal and the FX to adjust, and asks the LLM for a plan
+func startPlan() {
+	const title = "LLM FX Assistant Plan"
+
+	// STEP 1: Get the track and its FX
+	trackInfo, err := reaper.GetSelectedTrackInfo()
+	if err != nil {
+		core.HandleError(title, core.NewError(core.CategoryUser, "Please select a track to make a plan for.", err))
+		return
+	}
+	if message := frozenTrackMessage(trackInfo.MediaTrack, trackInfo.Name); message != "" {
+		reaper.MessageBox(message, title)
+		return
+	}
+	if trackInfo.NumFX == 0 {
+		reaper.MessageBox("Selected track has no FX. Please add FX to the track before making a plan.", title)
+		return
+	}
+	fxList, err := reaper.GetTrackFXSummaries(trackInfo.MediaTrack)
+	if err != nil {
+		core.HandleError(title, core.NewError(core.CategoryReaper, "Could not read the FX on the selected track.", err).
+			WithDetail("track %q", trackInfo.Name))
+		return
+	}
+
+	// STEP 2: Ask for the goal
+	fields := []string{"FX to adjust (comma-separated numbers)", "Your goal (e.g. 'make the vocal sit in the mix')"}
+	defaults := []string{fxSelectionText(allFXIndices(len(fxList))), ""}
+	values, err := reaper.GetUserInputs(title, fields, defaults)
+	if err != nil {
+		logger.Debug("Assistant plan cancelled")
+		return
+	}
+	indices, err := parseFXSelection(values[0], len(fxList))
+	if err != nil {
+		reaper.MessageBox(fmt.Sprintf("Invalid FX selection: %v", err), title)
+		return
+	}
+	goal := strings.TrimSpace(values[1])
+	if goal == "" {
+		reaper.MessageBox("Please describe what the plan should achieve.", title)
+		return
+	}
+
+	track, err := reaper.NewTrackRef(trackInfo.MediaTrack)
+	if err != nil {
+		core.HandleError(title, core.NewError(core.CategoryReaper, "Could not identify the selected track.", err))
+		return
+	}
+	plan := &activePlan{Track: track, TrackName: trackInfo.Name, Goal: goal, Provider: config.GetActiveProvider()}
+	for _, index := range indices {
+		if guid, err := reaper.GetTrackFXGUID(trackInfo.MediaTrack, index); err == nil {
+			plan.FXGUIDs = append(plan.FXGUIDs, guid)
+		}
+	}
+
+	// STEP 3: Ask the LLM for the plan in the background, then show it
+	requestPlanSteps(plan, func(err error) {
+		if err != nil {
+			handlePlanError(title, err)
+			return
+		}
+		offerPlan(plan)
+	})
+}
+
+// offerPlan shows a new plan and offers its first step
+func offerPlan(plan *activePlan) {
+	const title = "LLM FX Assistant Plan"
+
+	if len(plan.Steps) == 0 {
+		message := "The LLM did not suggest a plan for this goal."
+		if plan.Reasoning != "" {
+			message += "\n\nReason: " + plan.Reasoning
+		}
+		reaper.MessageBox(message, title)
+		return
+	}
+
+	var overview strings.Builder
+	if plan.Reasoning != "" {
+		overview.WriteString(plan.Reasoning + "\n\n")
+	}
+	for i, step := range plan.Steps {
+		overview.WriteString(fmt.Sprintf("%d. %s\n", i+1, step.Title))
+	}
+	overview.WriteString("\nEach step is shown before it is applied. Start with step 1?")
+	proceed, err := reaper.YesNoBox(overview.String(), title)
+	if err != nil || !proceed {
+		logger.Debug("User chose not to start the plan")
+		return
+	}
+
+	setCurrentPlan(plan)
+	offerNextStep(plan)
+}
+
+// allFXIndices returns the indices of count FX
+func allFXIndices(count int) []int {
+	indices := make([]int, count)
+	for i := range indices {
+		indices[i] = i
+	}
+	return indices
+}
+
+// offerNextStep shows the next step and applies it if confirmed. Skipped steps move on
+// to the one after; an applied step waits for the user's feedback.
+func offerNextStep(plan *activePlan) {
+	const title = "LLM FX Assistant Plan"
+
+	for len(plan.Steps) > 0 {
+		step := plan.Steps[0]
+		number := len(plan.History) + 1
+		heading := fmt.Sprintf("Step %d of %d: %s", number, len(plan.History)+len(plan.Steps), step.Title)
+
+		// A listening step has nothing to apply; it just waits for feedback
+		if len(step.Suggestions) == 0 {
+			plan.Pending = &planStepRecord{Number: number, Title: step.Title, ListenFor: step.ListenFor, Outcome: planStepListened}
+			reaper.MessageBox(fmt.Sprintf("%s\n\n%s\n\n%s", heading, listenInstruction(step), continueInstruction()), title)
+			return
+		}
+
+		items := assistantChanges(&AssistantResponse{Suggestions: step.Suggestions})
+		message := fmt.Sprintf("%s\n\n%s\nApply this step?\n\nChoose No to skip it, or Cancel to pause the plan.",
+			heading, formatNumberedChanges(&AssistantResponse{}, items))
+		choice, err := reaper.YesNoCancelBox(message, title)
+		if err != nil {
+			logger.Error("Dialog error: %v", err)
+			return
+		}
+
+		switch choice {
+		case reaper.IDYES:
+			record, err := applyPlanStep(plan, number, step)
+			if err != nil {
+				core.HandleError(title, core.NewError(core.CategoryReaper, "The step could not be applied. See the log for details.", err))
+				return
+			}
+			plan.Pending = record
+			reaper.MessageBox(fmt.Sprintf("Step %d applied: %d change(s).\n\n%s\n\n%s",
+				number, record.Changes, listenInstruction(step), continueInstruction()), title)
+			return
+		case reaper.IDNO:
+			logger.Info("Plan step %d skipped", number)
+			plan.History = append(plan.History, planStepRecord{Number: number, Title: step.Title, Outcome: planStepSkipped})
+			plan.Steps = plan.Steps[1:]
+		default:
+			logger.Info("Plan paused before step %d", number)
+			return
+		}
+	}
+
+	finishPlan(plan, "The plan is complete.")
+}
+
+// listenInstruction tells the user what to listen for after a step
+func listenInstruction(step PlanStep) string {
+	if listenFor := strings.TrimSpace(step.ListenFor); listenFor != "" {
+		return "Play the track and listen for: " + listenFor
+	}
+	return "Play the track and listen to the result."
+}
+
+// continueInstruction says how to give feedback on a step
+func continueInstruction() string {
+	return "Then run \"Go: LLM FX Assistant plan\" again to say whether it sounds better or worse and continue."
+}
+
+// applyPlanStep applies a step's changes to the plan's track as one undo point
+func applyPlanStep(plan *activePlan, number int, step PlanStep) (*planStepRecord, error) {
+	track, err := plan.Track.Resolve()
+	if err != nil {
+		return nil, fmt.Errorf("the track %q is no longer in the project", plan.TrackName)
+	}
+
+	// The chain may have changed while the user listened to the previous step
+	suggestions := resolvePlanSuggestions(track, plan, step.Suggestions)
+	if len(suggestions) == 0 {
+		return nil, fmt.Errorf("the FX this step adjusts are no longer on the track")
+	}
+
+	origin := changeOrigin{
+		Prompt:   fmt.Sprintf("%s (step %d: %s)", plan.Goal, number, step.Title),
+		Source:   "LLM",
+		Provider: providerModelName(plan.Provider),
+	}
+	journalID, err := applyAssistantChanges(track, suggestions, nil, nil, origin)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &planStepRecord{Number: number, Title: step.Title, ListenFor: step.ListenFor, Outcome: planStepApplied, Changes: len(suggestions), JournalID: journalID}
+	logger.Info("Plan step %d applied: %d change(s)", number, len(suggestions))
+	return record, nil
+}
+
+// resolvePlanSuggestions maps suggestions from the plan's FX numbering, in which FX n is
+// the plan's nth FX, to the FX's current position, dropping those whose FX is gone
+func resolvePlanSuggestions(track unsafe.Pointer, plan *activePlan, suggestions []ParameterSuggestion) []ParameterSuggestion {
+	var resolved []ParameterSuggestion
+	for _, s := range suggestions {
+		if s.FXIndex < 0 || s.FXIndex >= len(plan.FXGUIDs) {
+			logger.Warning("Skipping plan change to unknown FX %d", s.FXIndex)
+			continue
+		}
+		fxIndex, err := reaper.FindTrackFXByGUID(track, plan.FXGUIDs[s.FXIndex])
+		if err != nil {
+			logger.Warning("Skipping plan change to %s: the FX was removed", s.ParamName)
+			continue
+		}
+		if count, err := reaper.GetTrackFXParamCount(track, fxIndex); err == nil && (s.ParamIndex < 0 || s.ParamIndex >= count) {
+			logger.Warning("Skipping plan change to %s: no parameter %d", s.ParamName, s.ParamIndex)
+			continue
+		}
+		s.FXIndex = fxIndex
+		resolved = append(resolved, s)
+	}
+	return resolved
+}
+
+// continuePlan asks for feedback on the step just taken, then revises and offers the rest
+func continuePlan(plan *activePlan) {
+	const title = "LLM FX Assistant Plan"
+
+	if plan.Pending == nil {
+		offerNextStep(plan)
+		return
+	}
+	record := *plan.Pending
+
+	// STEP 1: Ask how the step sounded
+	values, err := reaper.GetUserInputs(title,
+		[]string{fmt.Sprintf("Step %d: better, worse or same? (b/w/s)", record.Number), "What you heard (optional)", "Continue the plan? (y/n)"},
+		[]string{"b", "", "y"})
+	if err != nil {
+		logger.Debug("Plan feedback cancelled")
+		return
+	}
+	answer := strings.ToLower(strings.TrimSpace(values[0]))
+	switch {
+	case strings.HasPrefix(answer, "b"):
+		record.Feedback = "better"
+	case strings.HasPrefix(answer, "w"):
+		record.Feedback = "worse"
+	case strings.HasPrefix(answer, "s"):
+		record.Feedback = "same"
+	default:
+		reaper.MessageBox("Answer b for better, w for worse or s for the same.", title)
+		return
+	}
+	record.Comment = strings.TrimSpace(values[1])
+	keepGoing := strings.HasPrefix(strings.ToLower(strings.TrimSpace(values[2])), "y")
+
+	// STEP 2: Offer to take back a step that made it worse
+	if record.Feedback == "worse" && record.Outcome == planStepApplied && record.JournalID > 0 {
+		revert, err := reaper.YesNoBox(fmt.Sprintf("Revert the changes of step %d?", record.Number), title)
+		if err == nil && revert {
+			if entry, ok := getJournal().get(record.JournalID); ok {
+				if _, skipped, err := restoreJournalEntry(entry, true); err != nil {
+					logger.Warning("Failed to revert plan step %d: %v", record.Number, err)
+				} else {
+					record.Outcome = planStepReverted
+					for _, reason := range skipped {
+						logger.Warning("Plan step %d revert skipped %s", record.Number, reason)
+					}
+				}
+			}
+		}
+	}
+
+	logger.Info("Plan step %d feedback: %s %q", record.Number, record.Feedback, record.Comment)
+	plan.History = append(plan.History, record)
+	plan.Pending = nil
+	plan.Steps = plan.Steps[1:]
+
+	if !keepGoing {
+		finishPlan(plan, "The plan was stopped.")
+		return
+	}
+
+	// STEP 3: Revise the rest of the plan in light of the feedback. Once the steps run
+	// out, a step that didn't help still gets a refinement.
+	if len(plan.History) >= maxPlanSteps {
+		finishPlan(plan, fmt.Sprintf("The plan has reached its limit of %d steps.", maxPlanSteps))
+		return
+	}
+	if len(plan.Steps) > 0 || record.Feedback != "better" {
+		requestPlanSteps(plan, func(err error) {
+			if err != nil {
+				handlePlanError(title, err)
+				return
+			}
+			offerNextStep(plan)
+		})
+		return
+	}
+
+	offerNextStep(plan)
+}
+
+// finishPlan ends the plan in progress and summarizes it
+func finishPlan(plan *activePlan, reason string) {
+	setCurrentPlan(nil)
+
+	var summary strings.Builder
+	summary.WriteString(reason + "\n\n")
+	for _, record := range plan.History {
+		summary.WriteString(fmt.Sprintf("%d. %s: %s", record.Number, record.Title, record.Outcome))
+		if record.Feedback != "" {
+			summary.WriteString(", " + record.Feedback)
+		}
+		summary.WriteString("\n")
+	}
+	logger.Info("Plan for %q finished after %d step(s)", plan.Goal, len(plan.History))
+	reaper.MessageBox(summary.String(), "LLM FX Assistant Plan")
+}
+
+// handlePlanError reports a failed plan request. The plan in progress, if any, stays,
+// so running the action again goes on with its steps as they were.
+func handlePlanError(title string, err error) {
+	var apiErr *llm.APIError
+	if errors.As(err, &apiErr) {
+		core.HandleError(title, core.NewError(core.CategoryLLM, "The LLM request failed.\n\n"+llm.UserMessage(err), err))
+		return
+	}
+	core.HandleError(title, core.NewError(core.CategoryLLM, "The plan could not be made.", err))
+}
+
+// requestPlanSteps asks the LLM for the plan's remaining steps, describing the FX as
+// they are now and the steps done so far with the user's feedback. The request runs in
+// the background; done is called on the main thread once the steps are updated, or with
+// the error.
+func requestPlanSteps(plan *activePlan, done func(err error)) {
+	client, systemPrompt, userPrompt, err := buildPlanRequest(plan)
+	if err != nil {
+		done(err)
+		return
+	}
+
+	setPlanWaiting(true)
+	logger.Info("Asking %s for plan steps towards %q", providerModelName(plan.Provider), plan.Goal)
+	core.Go("assistant plan", func() {
+		responseText, err := sendStructuredPrompt(client, systemPrompt, userPrompt, assistantPlanSchema)
+		core.MainThread.CallAsync(func() {
+			setPlanWaiting(false)
+			if err != nil {
+				done(err)
+				return
+			}
+			done(updatePlanSteps(plan, responseText))
+		})
+	})
+}
+
+// buildPlanRequest reads the plan's FX and builds the client and prompts for its next
+// steps. It reads the project, so it runs on the main thread.
+func buildPlanRequest(plan *activePlan) (llm.Client, string, string, error) {
+	track, err := plan.Track.Resolve()
+	if err != nil {
+		return nil, "", "", fmt.Errorf("the track %q is no longer in the project", plan.TrackName)
+	}
+
+	// The plan numbers its FX from 0 in the order they were chosen, wherever they are now
+	var fxParameters []reaper.FXInfo
+	for i, guid := range plan.FXGUIDs {
+		fxIndex, err := reaper.FindTrackFXByGUID(track, guid)
+		if err != nil {
+			continue
+		}
+		fx, err := reaper.GetFXParameters(track, fxIndex)
+		if err != nil {
+			logger.Warning("Failed to read FX %d parameters: %v", fxIndex, err)
+			continue
+		}
+		fx.Index = i
+		fxParameters = append(fxParameters, fx)
+	}
+	if len(fxParameters) == 0 {
+		return nil, "", "", fmt.Errorf("the plan's FX are no longer on the track")
+	}
+
+	apiKey, err := getProviderAPIKey(plan.Provider)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	systemPrompt := buildSystemPrompt(plan.TrackName, plan.Goal) + planSystemPrompt
+	model, maxTokens, _ := config.GetProviderConfig(plan.Provider)
+	budget := llm.PromptBudget(model, systemPrompt, maxTokens)
+	input := promptInput{
+		TrackName: plan.TrackName,
+		FXList:    fxParameters,
+		History:   formatPlanHistory(plan),
+		Request:   plan.Goal,
+	}
+	input, userPrompt, notes, fits := fitUserPrompt(input, budget)
+	if !fits {
+		userPrompt, notes = capUserPrompt(input, budget, notes)
+	}
+	if len(notes) > 0 {
+		logger.Warning("Plan prompt adjusted to fit %d token budget: %v", budget, notes)
+	}
+	logger.Info("Plan prompt: %s", userPrompt)
+
+	llmClient := newLLMClient(plan.Provider, apiKey)
+	deferUsage(llmClient)
+	var client llm.Client = newRetryClient(llmClient)
+	if config.GetCacheResponses() {
+		client = llm.NewCachingClient(client, getResponseCache(), cacheNamespace(plan.Provider))
+	}
+	return client, systemPrompt, userPrompt, nil
+}
+
+// updatePlanSteps replaces the plan's remaining steps with those in the LLM's response
+func updatePlanSteps(plan *activePlan, responseText string) error {
+	logger.Info("Plan response: %s", responseText)
+
+	response, err := parseAssistantPlan(responseText)
+	if err != nil {
+		return err
+	}
+
+	if room := maxPlanSteps - len(plan.History); len(response.Steps) > room {
+		response.Steps = response.Steps[:room]
+	}
+	plan.Steps = response.Steps
+	if response.Reasoning != "" {
+		plan.Reasoning = response.Reasoning
+	}
+	return nil
+}
+
+// formatPlanHistory describes the steps done so far and the user's feedback, or returns
+// "" before the first step
+func formatPlanHistory(plan *activePlan) string {
+	if len(plan.History) == 0 {
+		return ""
+	}
+
+	var builder strings.Builder
+	builder.WriteString("\nPlan steps so far (the parameter values above are the current ones):\n")
+	for _, record := range plan.History {
+		builder.WriteString(fmt.Sprintf("- Step %d, %s: ", record.Number, record.Title))
+		switch record.Outcome {
+		case planStepApplied:
+			builder.WriteString(fmt.Sprintf("applied %d change(s)", record.Changes))
+		case planStepReverted:
+			builder.WriteString(fmt.Sprintf("applied %d change(s), then reverted", record.Changes))
+		case planStepSkipped:
+			builder.WriteString("skipped by the user")
+		default:
+			builder.WriteString("listened")
+		}
+		if record.Feedback != "" {
+			builder.WriteString(". The user says it sounds " + record.Feedback)
+			if record.Feedback == "same" {
+				builder.WriteString(" as before")
+			}
+		}
+		if record.Comment != "" {
+			builder.WriteString(fmt.Sprintf(": %q", record.Comment))
+		}
+		builder.WriteString("\n")
+	}
+	builder.WriteString("Return only the remaining steps, revised in light of this feedback.\n")
+	return builder.String()
+}
+
+// parseAssistantPlan parses the LLM's plan, which may be JSON embedded in text when the
+// provider doesn't support structured output
+func parseAssistantPlan(responseText string) (*AssistantPlan, error) {
+	var plan AssistantPlan
+	if err := json.Unmarshal([]byte(strings.TrimSpace(responseText)), &plan); err != nil {
+		start, end := strings.Index(responseText, "{"), strings.LastIndex(responseText, "}")
+		if start == -1 || end < start {
+			return nil, fmt.Errorf("could not find valid JSON in response")
+		}
+		plan = AssistantPlan{}
+		if err := json.Unmarshal([]byte(responseText[start:end+1]), &plan); err != nil {
+			return nil, fmt.Errorf("failed to parse LLM response: %v", err)
+		}
+	}
+
+	// Values outside the normalized range are clamped, as for single requests
+	for i := range plan.Steps {
+		for j, suggestion := range plan.Steps[i].Suggestions {
+			if suggestion.Value < 0 {
+				plan.Steps[i].Suggestions[j].Value = 0
+			} else if suggestion.Value > 1 {
+				plan.Steps[i].Suggestions[j].Value = 1
+			}
+		}
+	}
+	return &plan, nil
+}
@@ -0,0 +1,147 @@
+package actions
+
+import (
+	"fmt"
+	"go-reaper/src/core"
+	"go-reaper/src/pkg/config"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/pkg/version"
+	"go-reaper/src/reaper"
+	"runtime"
+	"strings"
+)
+
+// The diagnostics action, which prints what a bug report needs to the REAPER console
+func init() {
+	registerAction(Action{
+		ID:      "GO_DIAGNOSTICS",
+		Name:    "Go: Extension Diagnostics",
+		Handler: handleDiagnostics,
+	})
+}
+
+// handleDiagnostics prints the diagnostics report to the console and the log
+func handleDiagnostics() {
+	report := diagnosticsReport()
+	logger.Info("Extension diagnostics:\n%s", report)
+
+	reaper.Console.Section("Go Extension Diagnostics")
+	if err := reaper.Console.Print(report); err != nil {
+		core.HandleError("Extension Diagnostics", core.NewError(core.CategoryReaper, "Failed to show the diagnostics in the console.", err))
+	}
+}
+
+// diagnosticsReport describes the versions, platform, paths and resolved functions
+func diagnosticsReport() string {
+	var b strings.Builder
+
+	reaperVersion, err := reaper.GetAppVersion()
+	if err != nil {
+		reaperVersion = fmt.Sprintf("unknown (%v)", err)
+	}
+	fmt.Fprintf(&b, "Extension: %s\n", version.Get())
+	fmt.Fprintf(&b, "REAPER: %s\n", reaperVersion)
+	fmt.Fprintf(&b, "Go: %s\n", runtime.Version())
+	fmt.Fprintf(&b, "Platform: %s/%s\n", runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&b, "Settings schema: %d\n", config.VERSION)
+
+	resourcePath, err := reaper.GetResourcePath()
+	if err != nil {
+		resourcePath = fmt.Sprintf("unknown (%v)", err)
+	}
+	fmt.Fprintf(&b, "\nResource path: %s\n", resourcePath)
+	fmt.Fprintf(&b, "Extension data: %s\n", extensionDataDir())
+	if logger.IsLoggingEnabled() {
+		fmt.Fprintf(&b, "Log file: %s (level %d)\n", logger.GetLogPath(), logger.GetLogLevel())
+	} else {
+		fmt.Fprintf(&b, "Log file: disabled (set REAPER_GO_LOG_ENABLED=1)\n")
+	}
+
+	buffers := reaper.GetBufferStats()
+	fmt.Fprintf(&b, "C buffers: %d allocated, %d reused\n", buffers.Allocations, buffers.Reuses)
+	paramCache := reaper.GetParamCacheStats()
+	fmt.Fprintf(&b, "Parameter metadata cache: %d FX, %d value-only reads, %d full reads, %d stale\n",
+		paramCache.FX, paramCache.Hits, paramCache.Misses, paramCache.Refused)
+
+	// Bridge call profile, when one has been collected
+	if reaper.GetProfile().Duration > 0 {
+		b.WriteString("\n")
+		b.WriteString(profileReport())
+	}
+
+	// Actions
+	var registered int
+	var disabled []string
+	for _, action := range declaredActions {
+		if isActionRegistered(action.ID) {
+			registered++
+		} else if !isActionWanted(action) {
+			disabled = append(disabled, action.ID)
+		}
+	}
+	fmt.Fprintf(&b, "\nActions: %d of %d registered\n", registered, len(declaredActions))
+	if len(disabled) > 0 {
+		fmt.Fprintf(&b, "Disabled: %s\n", strings.Join(disabled, ", "))
+	}
+
+	// Feature flags
+	fmt.Fprintf(&b, "\nFeature flags: %s\n", config.FeaturesPath())
+	for _, name := range config.Features {
+		state := "on"
+		if !config.IsFeatureEnabled(name) {
+			state = "off"
+		}
+		if source := config.FeatureSource(name); source != "" {
+			state += " (" + source + ")"
+		}
+		fmt.Fprintf(&b, "  %s: %s\n", name, state)
+	}
+
+	// Functions
+	var resolved int
+	var missing []string
+	var optional []string
+	for _, capability := range core.Capabilities() {
+		switch {
+		case capability.Optional:
+			state := "missing"
+			if capability.Available {
+				state = "available"
+			}
+			optional = append(optional, fmt.Sprintf("  %s (%s): %s", capability.Function, capability.Provider, state))
+		case capability.Available:
+			resolved++
+		default:
+			missing = append(missing, capability.Function)
+		}
+	}
+
+	fmt.Fprintf(&b, "\nREAPER functions: %d of %d resolved\n", resolved, resolved+len(missing))
+	if len(missing) > 0 {
+		fmt.Fprintf(&b, "Missing: %s\n", strings.Join(missing, ", "))
+	}
+
+	swsText := installedText(core.HasProvider(core.ProviderSWS))
+	if version, err := reaper.GetSWSVersion(); err == nil {
+		swsText = "installed (" + version + ")"
+	}
+	fmt.Fprintf(&b, "SWS: %s\n", swsText)
+	jsText := installedText(core.HasProvider(core.ProviderJSReaAPI))
+	if version, err := reaper.GetJSAPIVersion(); err == nil {
+		jsText = fmt.Sprintf("installed (%.3f)", version)
+	}
+	fmt.Fprintf(&b, "js_ReaScriptAPI: %s\n", jsText)
+	b.WriteString("Optional functions:\n")
+	b.WriteString(strings.Join(optional, "\n"))
+	b.WriteString("\n")
+
+	return b.String()
+}
+
+// installedText describes whether an extension is installed
+func installedText(installed bool) string {
+	if installed {
+		return "installed"
+	}
+	return "not installed"
+}
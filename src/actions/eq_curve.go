@@ -0,0 +1,227 @@
+package actions
+
+import (
+	"fmt"
+	"go-reaper/src/core"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"go-reaper/src/ui"
+	"runtime"
+	"strings"
+	"unsafe"
+)
+
+// This file implements the EQ curve viewer: it draws the response of an EQ on the
+// selected track and follows the plugin's controls while the window is open
+
+// The EQ curve viewer action; it is on while the curve window is open
+func init() {
+	registerAction(Action{
+		ID:          "GO_EQ_CURVE",
+		Name:        "Go: Show EQ Curve for Selected Track",
+		Handler:     handleEQCurve,
+		ToggleState: ui.IsEQCurveOpen,
+	})
+}
+
+// handleEQCurve opens the curve window for the first EQ on the selected track
+func handleEQCurve() {
+	if runtime.GOOS != "darwin" {
+		reaper.MessageBox("The EQ curve window is currently only implemented for macOS", "EQ Curve")
+		return
+	}
+
+	trackInfo, err := reaper.GetSelectedTrackInfo()
+	if err != nil {
+		if strings.Contains(err.Error(), "no track selected") {
+			core.HandleError("EQ Curve", core.NewError(core.CategoryUser, "Please select a track with an EQ first.", err))
+		} else {
+			core.HandleError("EQ Curve", core.NewError(core.CategoryReaper, "Could not read the selected track.", err))
+		}
+		return
+	}
+
+	fx, bands, err := findEQ(trackInfo.MediaTrack)
+	if err != nil {
+		core.HandleError("EQ Curve", core.NewError(core.CategoryReaper, "Could not read the FX on the selected track.", err).
+			WithDetail("track %q", trackInfo.Name))
+		return
+	}
+	if fx == nil {
+		reaper.MessageBox("No EQ with frequency and gain controls found on the selected track.", "EQ Curve")
+		return
+	}
+
+	title := fmt.Sprintf("EQ Curve - %s: %s", trackInfo.Name, fx.Name)
+	ref, err := reaper.NewTrackRef(trackInfo.MediaTrack)
+	if err != nil {
+		core.HandleError("EQ Curve", core.NewError(core.CategoryReaper, "Could not identify the selected track.", err).
+			WithDetail("track %q", trackInfo.Name))
+		return
+	}
+	if err := ui.ShowEQCurve(title, eqCurveSource(ref, *fx, bands)); err != nil {
+		core.HandleError("EQ Curve", core.NewError(core.CategoryInternal, "Failed to show the EQ curve window.", err))
+	}
+}
+
+// findEQ returns the first ReaEQ on the track, or else the first FX whose parameters
+// group into frequency/gain bands
+func findEQ(track unsafe.Pointer) (*reaper.FXInfo, []reaper.ReaEQBand, error) {
+	fxList, err := reaper.GetTrackFXSummaries(track)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var fallback *reaper.FXInfo
+	var fallbackBands []reaper.ReaEQBand
+
+	for _, listed := range fxList {
+		fx, err := reaper.GetFXParameters(track, listed.Index)
+		if err != nil {
+			logger.Warning("Skipping FX %d: %v", listed.Index, err)
+			continue
+		}
+
+		bands := groupEQBands(fx.Parameters)
+		if len(bands) == 0 {
+			continue
+		}
+		if reaper.IsCockosPlugin(fx, "ReaEQ") {
+			return &fx, bands, nil
+		}
+		if fallback == nil {
+			fallback, fallbackBands = &fx, bands
+		}
+	}
+
+	return fallback, fallbackBands, nil
+}
+
+// groupEQBands locates bands using ReaEQ's "Freq-X"/"Gain-X" naming, falling back to
+// pairing parameters like "Band 1 Frequency"/"Band 1 Gain" by their shared text
+func groupEQBands(params []reaper.FXParameter) []reaper.ReaEQBand {
+	if bands := reaper.GroupReaEQBands(params); len(bands) > 0 {
+		return bands
+	}
+
+	var bands []reaper.ReaEQBand
+	bandIndex := make(map[string]int)
+
+	for _, param := range params {
+		if param.Ident != "" {
+			continue
+		}
+
+		kind, key := eqControlKind(param.Name)
+		if kind == "" {
+			continue
+		}
+
+		i, exists := bandIndex[key]
+		if !exists {
+			bands = append(bands, reaper.ReaEQBand{Name: key, FrequencyParam: -1, GainParam: -1, BandwidthParam: -1})
+			i = len(bands) - 1
+			bandIndex[key] = i
+		}
+
+		switch kind {
+		case "freq":
+			bands[i].FrequencyParam = param.Index
+		case "gain":
+			bands[i].GainParam = param.Index
+		case "bw":
+			bands[i].BandwidthParam = param.Index
+		}
+	}
+
+	// A band needs at least a frequency and a gain to be drawn meaningfully
+	result := bands[:0]
+	for _, band := range bands {
+		if band.FrequencyParam >= 0 && band.GainParam >= 0 {
+			result = append(result, band)
+		}
+	}
+	return result
+}
+
+// eqControlKind classifies a parameter name as a band's frequency, gain or width control
+// and returns the rest of the name, which identifies the band
+func eqControlKind(name string) (kind string, key string) {
+	lower := strings.ToLower(name)
+
+	tokens := []struct {
+		kind  string
+		words []string
+	}{
+		{"freq", []string{"frequency", "freq"}},
+		{"gain", []string{"gain"}},
+		{"bw", []string{"bandwidth", "width", "bw"}},
+	}
+
+	for _, token := range tokens {
+		for _, word := range token.words {
+			if strings.Contains(lower, word) {
+				key = strings.Trim(strings.Replace(lower, word, "", 1), " -_:")
+				return token.kind, key
+			}
+		}
+	}
+	return "", ""
+}
+
+// eqCurveSource builds the polling function that keeps the curve in sync with the plugin.
+// It stops (closing the window) when the track or FX is removed or replaced.
+func eqCurveSource(ref reaper.TrackRef, fx reaper.FXInfo, bands []reaper.ReaEQBand) ui.EQCurveSource {
+	identity := fx.IdentityKey()
+
+	return func() ([]ui.EQBand, bool) {
+		track, err := ref.Resolve()
+		if err != nil {
+			return nil, false
+		}
+
+		count, err := reaper.GetTrackFXCount(track)
+		if err != nil || fx.Index >= count {
+			return nil, false
+		}
+
+		// The chain may have been reordered; make sure the slot still holds our plugin
+		current := reaper.FXInfo{Index: fx.Index}
+		current.Type, _ = reaper.GetTrackFXNamedConfigParam(track, fx.Index, reaper.FXConfigType)
+		current.PluginID, _ = reaper.GetTrackFXNamedConfigParam(track, fx.Index, reaper.FXConfigIdent)
+		if current.PluginID != "" && current.IdentityKey() != identity {
+			return nil, false
+		}
+
+		// A bypassed EQ doesn't change the sound
+		if enabled, err := reaper.GetTrackFXEnabled(track, fx.Index); err == nil && !enabled {
+			return []ui.EQBand{}, true
+		}
+
+		if err := reaper.RefreshEQBands(track, fx.Index, bands); err != nil {
+			logger.Warning("Failed to refresh EQ bands: %v", err)
+			return nil, false
+		}
+
+		return toCurveBands(bands), true
+	}
+}
+
+// toCurveBands converts bands to the curve view's representation
+func toCurveBands(bands []reaper.ReaEQBand) []ui.EQBand {
+	result := make([]ui.EQBand, 0, len(bands))
+	for _, band := range bands {
+		q := 0.0 // The view's default Q
+		if band.Bandwidth > 0 {
+			q = reaper.BandwidthToQ(band.Bandwidth)
+		}
+
+		result = append(result, ui.EQBand{
+			Type:      ui.EQFilterTypeFromName(band.Name),
+			Frequency: band.Frequency,
+			Gain:      band.Gain,
+			Q:         q,
+		})
+	}
+	return result
+}
@@ -2,10 +2,14 @@ package actions
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"go-reaper/src/core"
 	"go-reaper/src/llm"
+	"go-reaper/src/pkg/config"
 	"go-reaper/src/pkg/logger"
 	"go-reaper/src/reaper"
+	"go-reaper/src/ui"
 	"runtime"
 	"strconv"
 	"strings"
@@ -14,53 +18,64 @@ import (
 
 // ParameterSuggestion contains a suggestion for a single parameter adjustment
 type ParameterSuggestion struct {
-	FXIndex     int     `json:"fx_index"`
-	ParamIndex  int     `json:"param_index"`
-	ParamName   string  `json:"param_name"`
-	Value       float64 `json:"value"`
-	Explanation string  `json:"explanation"`
+	FXIndex     int      `json:"fx_index"`
+	ParamIndex  int      `json:"param_index"`
+	ParamName   string   `json:"param_name"`
+	Value       float64  `json:"value"`
+	NoteLength  string   `json:"note_length,omitempty"` // Optional tempo-synced value, e.g. "1/8 dotted"
+	UnitValue   *float64 `json:"unit_value,omitempty"`  // Optional value in the plugin's display units, e.g. -12 for "-12.0 dB"
+	Explanation string   `json:"explanation"`
 }
 
 // AssistantResponse contains the structured response from the LLM
 type AssistantResponse struct {
-	Suggestions []ParameterSuggestion `json:"suggestions"`
-	Reasoning   string                `json:"reasoning"`
+	Suggestions  []ParameterSuggestion `json:"suggestions"`
+	ChainChanges []ChainChange         `json:"chain_changes,omitempty"` // Only in chain reasoning mode
+	ItemChanges  []ItemChange          `json:"item_changes,omitempty"`  // Only when items on the track are selected
+	Reasoning    string                `json:"reasoning"`
 }
 
-// RegisterFXAssistant registers the LLM FX Assistant action
-func RegisterFXAssistant() error {
-	actionID, err := reaper.RegisterMainAction("GO_FX_ASSISTANT", "Go: LLM FX Assistant")
-	if err != nil {
-		return fmt.Errorf("failed to register LLM FX Assistant: %v", err)
-	}
-
-	logger.Info("LLM FX Assistant registered with ID: %d", actionID)
-	reaper.SetActionHandler("GO_FX_ASSISTANT", handleFXAssistant)
-	return nil
+// The LLM FX Assistant action; it is on while the assistant panel is open
+func init() {
+	registerAction(Action{
+		ID:          "GO_FX_ASSISTANT",
+		Feature:     config.FeatureAssistant,
+		Name:        "Go: LLM FX Assistant",
+		Handler:     handleFXAssistant,
+		ToggleState: isAssistantPanelOpen,
+	})
 }
 
 // handleFXAssistant handles the FX Assistant action
 func handleFXAssistant() {
-	// Lock the current goroutine to the OS thread to ensure thread safety
-	runtime.LockOSThread()
-	defer runtime.UnlockOSThread()
-
 	logger.Debug("----- LLM FX Assistant Activated -----")
 
+	// The panel handles the whole workflow in one window; other platforms use dialogs
+	if runtime.GOOS == "darwin" {
+		err := showAssistantPanel()
+		if err == nil {
+			return
+		}
+		logger.Warning("Failed to open assistant panel, falling back to dialogs: %v", err)
+	}
+
 	// STEP 1: Get track information
 	trackInfo, err := reaper.GetSelectedTrackInfo()
 	if err != nil {
 		if strings.Contains(err.Error(), "no track selected") {
-			logger.Info("No track selected")
-			reaper.MessageBox("Please select a track before using the LLM FX Assistant.", "LLM FX Assistant")
+			core.HandleError("LLM FX Assistant", core.NewError(core.CategoryUser, "Please select a track before using the LLM FX Assistant.", err))
 		} else {
-			logger.Error("Error getting track info: %v", err)
-			reaper.MessageBox(fmt.Sprintf("Error: %v", err), "LLM FX Assistant")
+			core.HandleError("LLM FX Assistant", core.NewError(core.CategoryReaper, "Could not read the selected track.", err))
 		}
 		return
 	}
 
-	// STEP 2: Check if track has FX
+	// STEP 2: Check the track's FX can be changed and it has some. Freezing takes the FX
+	// off the chain, so a frozen track is reported as such rather than as having no FX.
+	if message := frozenTrackMessage(trackInfo.MediaTrack, trackInfo.Name); message != "" {
+		reaper.MessageBox(message, "LLM FX Assistant")
+		return
+	}
 	if trackInfo.NumFX == 0 {
 		logger.Info("Selected track has no FX")
 
@@ -69,10 +84,10 @@ func handleFXAssistant() {
 	}
 
 	// STEP 3: Get FX list
-	fxList, err := reaper.GetTrackFXList(trackInfo.MediaTrack)
+	fxList, err := reaper.GetTrackFXSummaries(trackInfo.MediaTrack)
 	if err != nil {
-		logger.Error("Error getting FX list: %v", err)
-		reaper.MessageBox(fmt.Sprintf("Error: %v", err), "LLM FX Assistant")
+		core.HandleError("LLM FX Assistant", core.NewError(core.CategoryReaper, "Could not read the FX on the selected track.", err).
+			WithDetail("track %q", trackInfo.Name))
 		return
 	}
 
@@ -86,11 +101,17 @@ func handleFXAssistant() {
 	fields := []string{
 		"FX to adjust (comma-separated numbers)",
 		"Your request (e.g., 'make vocals clearer')",
+		"Allow bypass/reorder of the chain? (y/n)",
 	}
 
-	defaults := []string{
-		"1", // Default to first FX
-		"",  // Empty prompt
+	// Start from the project's last request, or the first FX and parameter changes only
+	memory := loadAssistantMemory()
+	defaults := []string{"1", memory.Prompt, "n"}
+	if indices := memory.fxIndices(trackInfo.MediaTrack, fxList); len(indices) > 0 {
+		defaults[0] = fxSelectionText(indices)
+	}
+	if memory.ChainMode {
+		defaults[2] = "y"
 	}
 
 	results, err := reaper.GetUserInputs("LLM FX Assistant", fields, defaults)
@@ -114,8 +135,14 @@ func handleFXAssistant() {
 		return
 	}
 
+	// Chain reasoning mode sends the whole chain and lets the LLM bypass or reorder FX
+	chainMode := len(results) > 2 && strings.HasPrefix(strings.ToLower(strings.TrimSpace(results[2])), "y")
+
+	rememberAssistantRequest(trackInfo.MediaTrack, selectedFXIndices, userPrompt, chainMode)
+
 	logger.Info("Selected FX indices: %v", selectedFXIndices)
 	logger.Info("User prompt: %s", userPrompt)
+	logger.Info("Chain reasoning mode: %v", chainMode)
 
 	// STEP 6: Collect FX parameters
 	fxParameters := collectFXParameters(trackInfo.MediaTrack, selectedFXIndices, fxList)
@@ -124,9 +151,13 @@ func handleFXAssistant() {
 	parametersText := formatFXParametersText(fxParameters)
 	logger.Info("Parameters collected: %s", parametersText)
 
+	// Selected items on the track can have their pitch and speed changed too
+	takes := trackTakes(trackInfo.MediaTrack)
+
 	// STEP 7: Confirm with user
-	confirmMsg := fmt.Sprintf("Track: %s\nFX selected: %d\nRequest: %s\n\nReady to analyze with LLM?\n\nNote: This will require an OpenAI API key.",
-		trackInfo.Name, len(selectedFXIndices), userPrompt)
+	provider := config.GetActiveProvider()
+	confirmMsg := fmt.Sprintf("Track: %s\nFX selected: %d\nRequest: %s\n\nReady to analyze with LLM?\n\nNote: This will use the %s provider.",
+		trackInfo.Name, len(selectedFXIndices), userPrompt, providerDisplayName(provider))
 
 	proceed, err := reaper.YesNoBox(confirmMsg, "LLM FX Assistant")
 	if err != nil || !proceed {
@@ -135,149 +166,633 @@ func handleFXAssistant() {
 	}
 
 	// STEP 8: Get API key
-	fields = []string{"OpenAI API Key"}
-	defaults = []string{""}
-
-	apiKey, err := getOpenAIKey()
+	apiKey, err := getProviderAPIKey(provider)
 	if err != nil {
-		logger.Error("Error calling GetOpenAIKey: %v", err)
-		reaper.MessageBox(fmt.Sprintf("Error calling GetOpenAIKey: %v", err), "LLM FX Assistant")
+		// Without a key, offer the rule-based engine so the assistant still works offline
+		logger.Info("No API key available (%v), offering offline assistant", err)
+
+		useOffline, dialogErr := reaper.YesNoBox("No API key is configured.\n\nUse the offline assistant instead? It understands simple requests such as \"warmer\", \"brighter\", \"more compression\" or \"more reverb\".",
+			"LLM FX Assistant")
+		if dialogErr != nil || !useOffline {
+			logger.Debug("User chose not to use the offline assistant")
+			return
+		}
+
+		response := suggestHeuristicChanges(fxParameters, userPrompt)
+		suggestHeuristicItemChanges(response, takes, userPrompt)
+		presentAndApplySuggestions(trackInfo.MediaTrack, response,
+			changeOrigin{Prompt: userPrompt, Source: "offline assistant"})
 		return
 	}
 
-	// STEP 9: Prepare prompts
-	systemPrompt := buildSystemPrompt()
-	userPromptText := buildUserPrompt(fxParameters, userPrompt)
+	// STEP 9: Prepare prompts, keeping the user prompt within the model's context window
+	systemPrompt := buildSystemPrompt(trackInfo.Name, userPrompt)
+	model, maxTokens, _ := config.GetProviderConfig(provider)
+	budget := llm.PromptBudget(model, systemPrompt, maxTokens)
+	input := promptInput{
+		TrackName: trackInfo.Name,
+		FXList:    fxParameters,
+		Items:     buildItemPrompt(takes),
+		Sections:  buildSectionPrompt(trackInfo.MediaTrack),
+		Request:   userPrompt,
+	}
+	if chainMode {
+		input.Chain = buildChainPrompt(fxList)
+	}
+	userPrompts, promptNotes := buildUserPrompts(input, budget)
+
+	if len(promptNotes) > 0 {
+		logger.Warning("Prompt adjusted to fit %d token budget: %v", budget, promptNotes)
+
+		warnMsg := fmt.Sprintf("The prompt for %s doesn't fit its context window as it is.\n\n- %s\n\nContinue?",
+			model, strings.Join(promptNotes, "\n- "))
+		proceed, err := reaper.YesNoBox(warnMsg, "LLM FX Assistant")
+		if err != nil || !proceed {
+			logger.Debug("User chose not to send the shortened prompt")
+			return
+		}
+	}
 
 	logger.Info("System Prompt: %s", systemPrompt)
-	logger.Info("User Prompt: %s", userPromptText)
+	logger.Info("User Prompt: %s", strings.Join(userPrompts, "\n---\n"))
 
 	// STEP 10: Inform the user
-	logger.Debug("About to call OpenAI API")
-	logger.Debug("Analyzing parameters with OpenAI... This might take a few seconds.")
-
-	// STEP 11: Create client and make API call
-	// Here we'll use the simplest approach - just call directly
-	client := llm.NewOpenAIClient(apiKey)
+	logger.Debug("About to call %s API", providerDisplayName(provider))
+	logger.Debug("Analyzing parameters with %s... This might take a few seconds.", providerDisplayName(provider))
+
+	// STEP 11: Create the client and ask in the background, so waiting on the provider
+	// (and any retries) doesn't freeze REAPER
+	llmClient := newLLMClient(provider, apiKey)
+	deferUsage(llmClient)
+	var client llm.Client = newRetryClient(llmClient)
+	if config.GetCacheResponses() {
+		client = llm.NewCachingClient(client, getResponseCache(), cacheNamespace(provider))
+	}
 
-	// Make API call
-	logger.Debug("Starting OpenAI API call...")
-	responseText, err := client.SendPrompt(systemPrompt, userPromptText)
+	origin := changeOrigin{Prompt: userPrompt, Source: "LLM", Provider: providerModelName(provider)}
+	core.Go("assistant request", func() {
+		// A prompt split into parts is sent part by part, and the suggestions merged
+		var assistantResponse *AssistantResponse
+		var responseText string
+		var err error
+		if len(userPrompts) > 1 {
+			assistantResponse, err = askAssistantInParts(client, systemPrompt, userPrompts, func(part, parts int) {
+				logger.Info("Sending part %d of %d", part, parts)
+			})
+		} else {
+			logger.Debug("Starting LLM API call...")
+			responseText, err = sendAssistantPrompt(client, systemPrompt, userPrompts[0])
+		}
+		core.MainThread.CallAsync(func() {
+			finishAssistantRequest(trackInfo.MediaTrack, takes, origin, provider, assistantResponse, responseText, err)
+		})
+	})
+}
 
+// finishAssistantRequest handles the LLM's response on the main thread: a prompt sent in
+// parts comes back as a merged response, a single prompt as text to parse
+func finishAssistantRequest(track unsafe.Pointer, takes []reaper.TakeInfo, origin changeOrigin, provider config.Provider,
+	assistantResponse *AssistantResponse, responseText string, err error) {
 	// STEP 12: Handle API response
 	if err != nil {
-		logger.Error("Error calling LLM API: %v", err)
-		reaper.MessageBox(fmt.Sprintf("Error calling LLM API: %v", err), "LLM FX Assistant")
+		core.HandleError("LLM FX Assistant", core.NewError(core.CategoryLLM, "The LLM request failed.\n\n"+llm.UserMessage(err), err).
+			WithDetail("provider %s", provider))
 		return
 	}
 
-	logger.Info("LLM Response: %s", responseText)
+	// The track may have been deleted while waiting
+	if !reaper.IsTrackValid(track) {
+		reaper.MessageBox("The track was removed while waiting for the LLM's suggestions.", "LLM FX Assistant")
+		return
+	}
 
 	// STEP 13: Parse the response
-	var assistantResponse *AssistantResponse
-	assistantResponse, err = parseAssistantResponse(responseText)
-	if err != nil {
-		logger.Error("Error parsing LLM response: %v", err)
-		reaper.MessageBox(fmt.Sprintf("Error parsing LLM response: %v", err), "LLM FX Assistant")
-		return
+	if assistantResponse == nil {
+		logger.Info("LLM Response: %s", responseText)
+
+		assistantResponse, err = parseAssistantResponse(responseText)
+		if err != nil {
+			core.HandleError("LLM FX Assistant", core.NewError(core.CategoryLLM, "The LLM's response could not be understood. Please try again.", err).
+				WithDetail("response %q", responseText))
+			return
+		}
 	}
 
-	// STEP 14: Handle empty suggestions case
-	if len(assistantResponse.Suggestions) == 0 {
+	// STEP 14-16: Show suggestions and apply them if confirmed
+	resolveItemChanges(assistantResponse, takes)
+	presentAndApplySuggestions(track, assistantResponse, origin)
+}
+
+// presentAndApplySuggestions shows the suggested changes, asks for confirmation and applies them.
+// origin.Source names the engine that produced the suggestions ("LLM" or "offline assistant").
+func presentAndApplySuggestions(track unsafe.Pointer, assistantResponse *AssistantResponse, origin changeOrigin) {
+	source := origin.Source
+
+	// Handle empty suggestions case
+	if len(assistantResponse.Suggestions) == 0 && len(assistantResponse.ChainChanges) == 0 && len(assistantResponse.ItemChanges) == 0 {
 		if assistantResponse.Reasoning != "" {
-			message := fmt.Sprintf("The LLM did not suggest any parameter changes.\n\nReason: %s",
-				assistantResponse.Reasoning)
-			logger.Info("No suggestions provided by LLM: %s", assistantResponse.Reasoning)
+			message := fmt.Sprintf("The %s did not suggest any parameter changes.\n\nReason: %s",
+				source, assistantResponse.Reasoning)
+			logger.Info("No suggestions provided by %s: %s", source, assistantResponse.Reasoning)
 			reaper.MessageBox(message, "LLM FX Assistant")
 		} else {
-			logger.Info("No suggestions provided by LLM")
-			reaper.MessageBox("The LLM did not suggest any parameter changes for your request. Try being more specific about what you want to achieve.",
+			logger.Info("No suggestions provided by %s", source)
+			reaper.MessageBox(fmt.Sprintf("The %s did not suggest any parameter changes for your request. Try being more specific about what you want to achieve.", source),
 				"LLM FX Assistant")
 		}
 		return
 	}
 
-	// STEP 15: Show suggestions and get user confirmation
-	resultsText := formatAssistantResults(assistantResponse)
+	// Show the numbered changes; Yes applies them all, No lets the user pick a subset
+	items := assistantChanges(assistantResponse)
+	resultsText := formatNumberedChanges(assistantResponse, items)
 
-	applyMsg := fmt.Sprintf("The LLM suggests these parameter changes:\n\n%s\n\nWould you like to apply these changes?", resultsText)
-	apply, err := reaper.YesNoBox(applyMsg, "LLM FX Assistant - Apply Changes")
+	applyMsg := fmt.Sprintf("The %s suggests these changes:\n\n%s\nApply all of these changes?\n\nChoose No to pick which changes to apply.", source, resultsText)
+	choice, err := reaper.YesNoCancelBox(applyMsg, "LLM FX Assistant - Apply Changes")
 	if err != nil {
 		logger.Error("Dialog error: %v", err)
 		return
 	}
 
-	// STEP 16: Apply changes if requested
-	if apply {
-		err = applyParameterChanges(trackInfo.MediaTrack, assistantResponse.Suggestions)
+	switch choice {
+	case reaper.IDYES:
+		// Apply everything
+	case reaper.IDNO:
+		items, err = chooseChanges(items)
 		if err != nil {
-			logger.Error("Error applying changes: %v", err)
-			reaper.MessageBox(fmt.Sprintf("Error applying changes: %v", err), "LLM FX Assistant")
+			logger.Info("User cancelled the change selection")
 			return
 		}
+	default:
+		logger.Info("User chose not to apply changes")
+		return
+	}
+
+	if len(items) == 0 {
+		logger.Info("No changes selected")
+		return
+	}
+
+	suggestions, chainChanges, itemChanges := splitAssistantChanges(items)
+	if _, err := applyAssistantChanges(track, suggestions, chainChanges, itemChanges, origin); err != nil {
+		core.HandleError("LLM FX Assistant", core.NewError(core.CategoryReaper, "Some changes could not be applied. See the log for details.", err))
+		return
+	}
+
+	logger.Info("Changes applied successfully")
+	reaper.MessageBox(fmt.Sprintf("%d change(s) applied successfully!", len(items)), "LLM FX Assistant")
+}
+
+// formatNumberedChanges lists the reasoning and each change with the number used to select it
+func formatNumberedChanges(response *AssistantResponse, items []assistantChange) string {
+	var builder strings.Builder
+
+	if response.Reasoning != "" {
+		builder.WriteString("Analysis: " + response.Reasoning + "\n\n")
+	}
+
+	for i, item := range items {
+		builder.WriteString(fmt.Sprintf("%d. %s\n", i+1, item.label()))
+
+		explanation := ""
+		switch {
+		case item.Suggestion != nil:
+			explanation = item.Suggestion.Explanation
+		case item.Chain != nil:
+			explanation = item.Chain.Explanation
+		default:
+			explanation = item.Item.Explanation
+		}
+		if explanation != "" {
+			builder.WriteString("    " + explanation + "\n")
+		}
+	}
+
+	return builder.String()
+}
+
+// chooseChanges asks which of the numbered changes to apply
+func chooseChanges(items []assistantChange) ([]assistantChange, error) {
+	for {
+		values, err := reaper.GetUserInputs("LLM FX Assistant - Choose Changes",
+			[]string{"Changes to apply (e.g. 1 3-5, or all)"}, []string{"all"})
+		if err != nil {
+			return nil, err
+		}
+
+		indices, err := parseChangeSelection(values[0], len(items))
+		if err != nil {
+			reaper.MessageBox(fmt.Sprintf("Invalid selection: %v", err), "LLM FX Assistant")
+			continue
+		}
+
+		chosen := make([]assistantChange, 0, len(indices))
+		for _, index := range indices {
+			chosen = append(chosen, items[index])
+		}
+		return chosen, nil
+	}
+}
+
+// parseChangeSelection parses change numbers separated by spaces or commas, ranges such as
+// "3-5" and "all", returning sorted 0-based indices. An empty selection selects nothing.
+func parseChangeSelection(input string, count int) ([]int, error) {
+	selected := make([]bool, count)
+
+	fields := strings.FieldsFunc(input, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+	for _, field := range fields {
+		if strings.EqualFold(field, "all") {
+			for i := range selected {
+				selected[i] = true
+			}
+			continue
+		}
+
+		first, last := field, field
+		if dash := strings.Index(field, "-"); dash > 0 {
+			first, last = field[:dash], field[dash+1:]
+		}
+
+		from, err := strconv.Atoi(first)
+		if err != nil {
+			return nil, fmt.Errorf("invalid change number: %s", field)
+		}
+		to, err := strconv.Atoi(last)
+		if err != nil {
+			return nil, fmt.Errorf("invalid change number: %s", field)
+		}
+		if from < 1 || to > count || from > to {
+			return nil, fmt.Errorf("change number out of range: %s", field)
+		}
+
+		for i := from; i <= to; i++ {
+			selected[i-1] = true
+		}
+	}
 
-		logger.Info("Parameter changes applied successfully")
-		reaper.MessageBox("Parameter changes applied successfully!", "LLM FX Assistant")
+	var indices []int
+	for i, ok := range selected {
+		if ok {
+			indices = append(indices, i)
+		}
+	}
+	return indices, nil
+}
+
+// applyAssistantChanges applies parameter changes, then chain changes, then item changes,
+// as a single undo point, and records them in the journal. It returns the journal entry's
+// ID, or 0 if nothing was recorded.
+func applyAssistantChanges(track unsafe.Pointer, suggestions []ParameterSuggestion, chainChanges []ChainChange, itemChanges []ItemChange, origin changeOrigin) (int, error) {
+	// The track may have been frozen since the changes were suggested
+	if reaper.IsTrackFrozen(track) {
+		return 0, fmt.Errorf("the track is frozen, so its FX can't be changed; unfreeze it first")
+	}
+
+	if err := reaper.UndoBeginBlock(); err != nil {
+		logger.Warning("Failed to start undo block: %v", err)
 	} else {
-		logger.Info("User chose not to apply changes")
+		// The block must be closed even when applying fails part way
+		defer func() {
+			description := fmt.Sprintf("LLM FX Assistant: apply %d change(s)", len(suggestions)+len(chainChanges)+len(itemChanges))
+			flags := reaper.UndoStateFX
+			if trackMarkingEnabled() {
+				flags |= reaper.UndoStateTrackCfg
+			}
+			if len(itemChanges) > 0 {
+				flags |= reaper.UndoStateItems
+			}
+			if err := reaper.UndoEndBlock(description, flags); err != nil {
+				logger.Warning("Failed to end undo block: %v", err)
+			}
+		}()
+	}
+
+	before := snapshotParameters(track, suggestions)
+	if err := applyParameterChanges(track, suggestions); err != nil {
+		return 0, err
 	}
+	entry := newJournalEntry(track, origin, suggestions, before)
+
+	// Chain changes go last since moves change FX indices
+	if len(chainChanges) > 0 {
+		if err := applyChainChanges(track, chainChanges); err != nil {
+			return recordJournalEntry(entry, nil, nil), fmt.Errorf("parameter changes were applied, but changing the FX chain failed: %v", err)
+		}
+	}
+
+	if len(itemChanges) > 0 {
+		if err := applyItemChanges(itemChanges); err != nil {
+			return recordJournalEntry(entry, chainChanges, nil), fmt.Errorf("FX changes were applied, but changing the items failed: %v", err)
+		}
+	}
+
+	journalID := recordJournalEntry(entry, chainChanges, itemChanges)
+
+	// Marking is cosmetic, so a failure doesn't fail the changes
+	if err := markChangedTrack(track); err != nil {
+		logger.Warning("Failed to mark the changed track: %v", err)
+	}
+
+	return journalID, nil
 }
 
-// buildSystemPrompt creates a system prompt for the LLM
-func buildSystemPrompt() string {
-	return `You are an audio engineer assistant that helps adjust effects (FX) parameters in a digital audio workstation.
-You will be given information about one or more audio effects including their names and parameters.
-You will also receive a user request about how they want to adjust the sound.
+// checkStaleChanges keeps the changes that still refer to the FX and parameters they were
+// suggested for. Changes to an FX that was replaced or changed its parameters are left
+// out and described in skipped. An error means the number of FX changed, so no index can
+// be trusted.
+func checkStaleChanges(track unsafe.Pointer, chain reaper.ChainCheck, suggestions []ParameterSuggestion, chainChanges []ChainChange) ([]ParameterSuggestion, []ChainChange, []string, error) {
+	stale, err := chain.Verify(track)
+	if err != nil {
+		return nil, nil, nil, err
+	}
 
-Your task is to suggest parameter adjustments that will help achieve the user's request.
+	var skipped []string
+	var keptSuggestions []ParameterSuggestion
+	for _, s := range suggestions {
+		if reason, ok := stale[s.FXIndex]; ok {
+			skipped = append(skipped, fmt.Sprintf("FX %d %s: %s", s.FXIndex, s.ParamName, reason))
+			continue
+		}
+		if !chain.ParamInRange(s.FXIndex, s.ParamIndex) {
+			skipped = append(skipped, fmt.Sprintf("FX %d %s: no parameter %d", s.FXIndex, s.ParamName, s.ParamIndex))
+			continue
+		}
+		keptSuggestions = append(keptSuggestions, s)
+	}
 
-IMPORTANT RULES:
-1. Only suggest adjustments to the parameters provided.
-2. Always return values within the normalized range (0.0 to 1.0).
-3. Always format your response as valid JSON with this structure:
-{
-  "suggestions": [
-    {
-      "fx_index": <integer index of the effect>,
-      "param_index": <integer index of the parameter>,
-      "param_name": "<name of the parameter>",
-      "value": <new value between 0.0 and 1.0>,
-      "explanation": "<brief explanation of this adjustment>"
-    }
-  ],
-  "reasoning": "<your overall explanation of the parameter adjustments>"
+	var keptChainChanges []ChainChange
+	for _, c := range chainChanges {
+		if reason, ok := stale[c.FXIndex]; ok {
+			skipped = append(skipped, fmt.Sprintf("%s FX %d: %s", c.Action, c.FXIndex, reason))
+			continue
+		}
+		keptChainChanges = append(keptChainChanges, c)
+	}
+
+	for _, reason := range skipped {
+		logger.Warning("Skipping stale change, %s", reason)
+	}
+	return keptSuggestions, keptChainChanges, skipped, nil
+}
+
+// skippedNote describes skipped changes for a status line, or returns "" if there are none
+func skippedNote(skipped []string) string {
+	if len(skipped) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(", skipped %d that no longer match the FX chain or items (%s)", len(skipped), strings.Join(skipped, "; "))
+}
+
+// buildSystemPrompt creates the system prompt for the LLM from the configured template
+func buildSystemPrompt(trackName string, userRequest string) string {
+	return renderPromptTemplate(systemPromptTemplate(), map[string]string{
+		"track":   trackName,
+		"request": userRequest,
+	})
 }
 
-4. Keep explanations concise but technically accurate.
-5. Only include parameters you are adjusting in the suggestions array.
-6. Focus on achieving the user's sonic goals with the minimum necessary adjustments.
-7. The JSON must be valid and complete.`
+// Prompt detail levels, from most to least verbose
+const (
+	promptDetailFull     = iota // Every parameter with raw and formatted values
+	promptDetailCompact         // Shorter lines, formatted value only when it adds information
+	promptDetailFiltered        // Compact, without bypass/MIDI/placeholder parameters
+	promptDetailCapped          // Filtered, with a per-FX parameter cap
+)
+
+// promptInput holds everything the user prompt is built from
+type promptInput struct {
+	TrackName string
+	FXList    []reaper.FXInfo // FX whose parameters are listed
+	Chain     string          // Chain overview, set in chain reasoning mode
+	Items     string          // Selected items on the track, set when there are any
+	Sections  string          // Loudness per song section, set when enabled in the analyzer settings
+	History   string          // Steps done so far and the user's feedback, set for assistant plans
+	Part      string          // Says which part this is when the parameters are split over several prompts
+	Request   string
 }
 
 // buildUserPrompt creates a prompt with FX details and the user's request
-func buildUserPrompt(fxList []reaper.FXInfo, userRequest string) string {
-	var builder strings.Builder
+func buildUserPrompt(input promptInput) string {
+	return buildUserPromptDetail(input, promptDetailFull, 0)
+}
+
+// noPromptBudgetNote is the note for when max tokens and the system prompt already fill
+// the model's context window, so there is nothing to fit the user prompt to
+const noPromptBudgetNote = "Max tokens leaves no room for the prompt in the model's context window, so the prompt was sent in full and may be rejected. Lower max tokens in the LLM settings."
+
+// fitUserPrompt applies the analyzer settings and then shortens the parameter listing
+// without leaving out parameters that matter. It returns the input as filtered, the
+// shortest prompt, notes on what was shortened, and whether the prompt fits the budget.
+func fitUserPrompt(input promptInput, budget int) (promptInput, string, []string, bool) {
+	analyzer := config.GetAnalyzer()
+	input, notes := applyAnalyzerSettings(input, analyzer)
+
+	prompt := buildUserPromptDetail(input, promptDetailFull, 0)
+	if budget <= 0 {
+		return input, prompt, append(notes, noPromptBudgetNote), true
+	}
+	if llm.EstimateTokens(prompt) <= budget {
+		return input, prompt, notes, true
+	}
+
+	logger.Info("Prompt (~%d tokens) exceeds budget of %d tokens, compacting", llm.EstimateTokens(prompt), budget)
+
+	prompt = buildUserPromptDetail(input, promptDetailCompact, 0)
+	if llm.EstimateTokens(prompt) <= budget {
+		return input, prompt, append(notes, "Parameter values were listed in a compact form."), true
+	}
+
+	prompt = buildUserPromptDetail(input, promptDetailFiltered, 0)
+	if !analyzer.FilterBoilerplate {
+		notes = append(notes, "Bypass, MIDI and placeholder parameters were left out.")
+	}
+	return input, prompt, notes, llm.EstimateTokens(prompt) <= budget
+}
+
+// capUserPrompt lists as many parameters of each FX as fit the budget, for when even a
+// prompt split into parts can't fit it
+func capUserPrompt(input promptInput, budget int, notes []string) (string, []string) {
+	// Find the largest per-FX parameter cap that fits
+	maxParams := 0
+	for _, fx := range input.FXList {
+		if len(fx.Parameters) > maxParams {
+			maxParams = len(fx.Parameters)
+		}
+	}
+
+	lo, hi := 1, maxParams
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if llm.EstimateTokens(buildUserPromptDetail(input, promptDetailCapped, mid)) <= budget {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+
+	prompt := buildUserPromptDetail(input, promptDetailCapped, lo)
+	notes = append(notes, fmt.Sprintf("Only the first %d parameters of each FX were included.", lo))
+	if llm.EstimateTokens(prompt) > budget {
+		notes = append(notes, "The prompt may still exceed the model's context window.")
+	}
+
+	return prompt, notes
+}
+
+// applyAnalyzerSettings leaves out the parameters the analyzer settings exclude from
+// every prompt, whatever the budget, and returns notes saying so
+func applyAnalyzerSettings(input promptInput, analyzer config.AnalyzerSettings) (promptInput, []string) {
+	fxList := make([]reaper.FXInfo, len(input.FXList))
+	skipped := make(map[paramRelevance]int)
+	capped := false
+	for i, fx := range input.FXList {
+		var parameters []reaper.FXParameter
+		for _, param := range fx.Parameters {
+			if relevance := requestRelevance(param, input.Request); skipsRelevance(analyzer, relevance) {
+				skipped[relevance]++
+				continue
+			}
+			if analyzer.MaxParamsPerFX > 0 && len(parameters) >= analyzer.MaxParamsPerFX {
+				capped = true
+				break
+			}
+			parameters = append(parameters, param)
+		}
+		fx.Parameters = parameters
+		fxList[i] = fx
+	}
+	input.FXList = fxList
+
+	var notes []string
+	if len(skipped) > 0 {
+		notes = append(notes, skippedParametersNote(skipped))
+	}
+	if capped {
+		notes = append(notes, fmt.Sprintf("At most %d parameters of each FX were included.", analyzer.MaxParamsPerFX))
+	}
+	return input, notes
+}
+
+// buildUserPromptDetail creates the user prompt from the configured template at the given detail level.
+// maxParams limits the parameters listed per FX when detail is promptDetailCapped.
+func buildUserPromptDetail(input promptInput, detail int, maxParams int) string {
+	return renderPromptTemplate(userPromptTemplate(), map[string]string{
+		"track":   input.TrackName,
+		"fx_list": formatPromptFXList(input.FXList, detail, maxParams) + input.Chain + input.Items + input.Sections + input.History + input.Part,
+		"request": input.Request,
+	})
+}
 
-	builder.WriteString("Here are the audio effects and their current parameters:\n\n")
+// formatPromptFXList lists the FX and their parameters at the given detail level
+func formatPromptFXList(fxList []reaper.FXInfo, detail int, maxParams int) string {
+	var builder strings.Builder
 
 	for _, fx := range fxList {
-		builder.WriteString(fmt.Sprintf("FX %d: %s\n", fx.Index, fx.Name))
+		builder.WriteString(fmt.Sprintf("FX %d: %s%s\n", fx.Index, fx.Name, describePlugin(fx)))
+		if summary := describeCockosFX(fx); summary != "" {
+			builder.WriteString(summary)
+		}
 		builder.WriteString("Parameters:\n")
 
+		listed, omitted := 0, 0
 		for _, param := range fx.Parameters {
-			builder.WriteString(fmt.Sprintf("  - %s (index: %d): %.4f (formatted: %s)\n",
-				param.Name, param.Index, param.Value, param.FormattedValue))
+			if detail >= promptDetailFiltered && isBoilerplateParameter(param) {
+				omitted++
+				continue
+			}
+			if detail == promptDetailCapped && listed >= maxParams {
+				omitted++
+				continue
+			}
+			listed++
+
+			if detail == promptDetailFull {
+				builder.WriteString(fmt.Sprintf("  - %s (index: %d): %.4f (formatted: %s)\n",
+					param.Name, param.Index, param.Value, param.FormattedValue))
+				continue
+			}
+
+			builder.WriteString(fmt.Sprintf("  - [%d] %s: %.3f", param.Index, param.Name, param.Value))
+			if formatted := strings.TrimSpace(param.FormattedValue); formatted != "" && formatted != fmt.Sprintf("%.3f", param.Value) {
+				builder.WriteString(" (" + formatted + ")")
+			}
+			builder.WriteString("\n")
+		}
+
+		if omitted > 0 {
+			builder.WriteString(fmt.Sprintf("  (%d more parameters omitted for brevity)\n", omitted))
 		}
 
 		builder.WriteString("\n")
 	}
 
-	builder.WriteString("User request: " + userRequest + "\n\n")
-	builder.WriteString("Please suggest parameter adjustments that will help achieve this request. Remember to format your response as JSON according to the specified structure.")
+	return builder.String()
+}
+
+// describePlugin names the underlying plugin when the instance was renamed, e.g. " (ReaEQ by Cockos)"
+func describePlugin(fx reaper.FXInfo) string {
+	if fx.PluginName == "" || strings.Contains(fx.Name, fx.PluginName) {
+		return ""
+	}
+	if fx.Vendor != "" {
+		return fmt.Sprintf(" (%s by %s)", fx.PluginName, fx.Vendor)
+	}
+	return fmt.Sprintf(" (%s)", fx.PluginName)
+}
 
+// describeCockosFX summarizes stock Cockos plugins in real units so the LLM can reason
+// about them semantically (e.g. "Band 2: 1000 Hz, +3.0 dB, 1.00 oct"), and says which
+// ones process video
+func describeCockosFX(fx reaper.FXInfo) string {
+	if reaper.IsVideoProcessor(fx) {
+		return "Video processor: its parameters change the picture, not the audio. Only change them for requests about the video.\n"
+	}
+	if !reaper.IsCockosPlugin(fx, "ReaEQ") {
+		return ""
+	}
+
+	bands := reaper.GroupReaEQBands(fx.Parameters)
+	if len(bands) == 0 {
+		return ""
+	}
+
+	var builder strings.Builder
+	builder.WriteString("EQ bands (use unit_value with Hz, dB or octaves to set these):\n")
+	for _, band := range bands {
+		builder.WriteString(fmt.Sprintf("  - %s: %.0f Hz, %+.1f dB", band.Name, band.Frequency, band.Gain))
+		if band.BandwidthParam >= 0 {
+			builder.WriteString(fmt.Sprintf(", %.2f oct", band.Bandwidth))
+		}
+		builder.WriteString("\n")
+	}
 	return builder.String()
 }
 
+// assistantResponseSchema is the JSON schema structured-output providers must follow
+var assistantResponseSchema = llm.SchemaFromType("fx_suggestions", AssistantResponse{})
+
+// sendAssistantPrompt requests a schema-constrained response when the provider supports it,
+// falling back to a plain prompt (parsed leniently) otherwise
+func sendAssistantPrompt(client llm.Client, systemPrompt, userPrompt string) (string, error) {
+	return sendStructuredPrompt(client, systemPrompt, userPrompt, assistantResponseSchema)
+}
+
+// sendStructuredPrompt requests a response following schema when the provider supports
+// it, falling back to a plain prompt otherwise
+func sendStructuredPrompt(client llm.Client, systemPrompt, userPrompt string, schema llm.ResponseSchema) (string, error) {
+	if structured, ok := client.(llm.StructuredClient); ok {
+		response, err := structured.SendPromptWithSchema(systemPrompt, userPrompt, schema)
+		if !errors.Is(err, llm.ErrStructuredOutputUnsupported) {
+			return response, err
+		}
+		logger.Info("Provider does not support structured output, using plain prompt")
+	}
+
+	return client.SendPrompt(systemPrompt, userPrompt)
+}
+
 // parseAssistantResponse parses the LLM's text response
 func parseAssistantResponse(responseText string) (*AssistantResponse, error) {
 	// Validate input
@@ -287,23 +802,26 @@ func parseAssistantResponse(responseText string) (*AssistantResponse, error) {
 
 	logger.Info("Parsing response text (%d chars)...", len(responseText))
 
-	// Try to extract JSON from the response (it might contain additional text)
-	jsonStart := strings.Index(responseText, "{")
-	jsonEnd := strings.LastIndex(responseText, "}")
-
-	if jsonStart == -1 || jsonEnd == -1 || jsonEnd < jsonStart {
-		logger.Error("Failed to find valid JSON markers in response: %s", responseText)
-		return nil, fmt.Errorf("could not find valid JSON in response")
-	}
+	// Structured-output responses are plain JSON documents
+	var response AssistantResponse
+	if err := json.Unmarshal([]byte(strings.TrimSpace(responseText)), &response); err != nil {
+		// Fall back to extracting JSON embedded in free text (providers without structured output)
+		jsonStart := strings.Index(responseText, "{")
+		jsonEnd := strings.LastIndex(responseText, "}")
+
+		if jsonStart == -1 || jsonEnd == -1 || jsonEnd < jsonStart {
+			logger.Error("Failed to find valid JSON markers in response: %s", responseText)
+			return nil, fmt.Errorf("could not find valid JSON in response")
+		}
 
-	jsonStr := responseText[jsonStart : jsonEnd+1]
-	logger.Info("Extracted JSON (%d chars)", len(jsonStr))
+		jsonStr := responseText[jsonStart : jsonEnd+1]
+		logger.Info("Extracted JSON (%d chars)", len(jsonStr))
 
-	// Parse the JSON response
-	var response AssistantResponse
-	if err := json.Unmarshal([]byte(jsonStr), &response); err != nil {
-		logger.Error("JSON unmarshal error: %v", err)
-		return nil, fmt.Errorf("failed to parse LLM response: %v", err)
+		response = AssistantResponse{}
+		if err := json.Unmarshal([]byte(jsonStr), &response); err != nil {
+			logger.Error("JSON unmarshal error: %v", err)
+			return nil, fmt.Errorf("failed to parse LLM response: %v", err)
+		}
 	}
 
 	// Initialize empty arrays if needed
@@ -340,7 +858,14 @@ func buildFXSelectionList(fxList []reaper.FXInfo) string {
 	builder.WriteString("\nAvailable FX:\n")
 
 	for i, fx := range fxList {
-		builder.WriteString(fmt.Sprintf("%d. %s\n", i+1, fx.Name))
+		state := ""
+		switch {
+		case fx.Offline:
+			state = " (offline)"
+		case !fx.Enabled:
+			state = " (bypassed)"
+		}
+		builder.WriteString(fmt.Sprintf("%d. %s%s\n", i+1, fx.Name, state))
 	}
 
 	return builder.String()
@@ -445,19 +970,70 @@ func formatAssistantResults(response *AssistantResponse) string {
 		builder.WriteString(fmt.Sprintf("\nFX %d:\n", fxIndex))
 
 		for _, suggestion := range suggestions {
-			builder.WriteString(fmt.Sprintf("  • %s: %.2f\n    %s\n",
+			value := fmt.Sprintf("%.2f", suggestion.Value)
+			if suggestion.NoteLength != "" {
+				value = suggestion.NoteLength + " (tempo-synced)"
+			} else if suggestion.UnitValue != nil {
+				value = fmt.Sprintf("%g", *suggestion.UnitValue)
+			}
+			builder.WriteString(fmt.Sprintf("  • %s: %s\n    %s\n",
 				suggestion.ParamName,
-				suggestion.Value,
+				value,
 				suggestion.Explanation))
 		}
 	}
 
+	if len(response.ChainChanges) > 0 {
+		builder.WriteString(formatChainChanges(response.ChainChanges))
+	}
+	if len(response.ItemChanges) > 0 {
+		builder.WriteString(formatItemChanges(response.ItemChanges))
+	}
+
 	return builder.String()
 }
 
 // applyParameterChanges applies the parameter changes suggested by the LLM
 func applyParameterChanges(track unsafe.Pointer, suggestions []ParameterSuggestion) error {
 	for _, suggestion := range suggestions {
+		// Tempo-synced suggestions are applied in the plugin's own units
+		if suggestion.NoteLength != "" {
+			value, err := reaper.SetTrackFXParamNoteLength(track, suggestion.FXIndex, suggestion.ParamIndex, suggestion.NoteLength)
+			if err == nil {
+				logger.Info("Applied: FX %d, Parameter %d (%s): %s (%.4f) - %s",
+					suggestion.FXIndex,
+					suggestion.ParamIndex,
+					suggestion.ParamName,
+					suggestion.NoteLength,
+					value,
+					suggestion.Explanation,
+				)
+				continue
+			}
+
+			// Fall back to the normalized value the LLM provided
+			logger.Warning("Could not apply note length %s, using normalized value: %v", suggestion.NoteLength, err)
+		}
+
+		// Values in display units (Hz, dB, ms) are mapped through the plugin's own formatting
+		if suggestion.UnitValue != nil {
+			value, err := reaper.SetTrackFXParamFromUnitValue(track, suggestion.FXIndex, suggestion.ParamIndex, *suggestion.UnitValue)
+			if err == nil {
+				logger.Info("Applied: FX %d, Parameter %d (%s): %g units (%.4f) - %s",
+					suggestion.FXIndex,
+					suggestion.ParamIndex,
+					suggestion.ParamName,
+					*suggestion.UnitValue,
+					value,
+					suggestion.Explanation,
+				)
+				continue
+			}
+
+			// Fall back to the normalized value the LLM provided
+			logger.Warning("Could not apply unit value %g, using normalized value: %v", *suggestion.UnitValue, err)
+		}
+
 		// Apply the parameter change
 		err := reaper.SetTrackFXParamValue(track, suggestion.FXIndex, suggestion.ParamIndex, suggestion.Value)
 		if err != nil {
@@ -477,20 +1053,110 @@ func applyParameterChanges(track unsafe.Pointer, suggestions []ParameterSuggesti
 	return nil
 }
 
-// getOpenAIKey asks the user for their OpenAI API key
-func getOpenAIKey() (string, error) {
-	fields := []string{"OpenAI API Key"}
+// newLLMClient creates a client for the given provider using its stored configuration
+func newLLMClient(provider config.Provider, apiKey string) llm.Client {
+	model, maxTokens, temperature := config.GetProviderConfig(provider)
+
+	var client *llm.OpenAIClient
+	switch provider {
+	case config.ProviderGemini, config.ProviderOpenAICompatible:
+		client = llm.NewOpenAICompatibleClient(config.GetProviderBaseURL(provider), apiKey, model)
+	default:
+		client = llm.NewOpenAIClient(apiKey)
+		if model != "" {
+			client.Model = model
+		}
+	}
+
+	if maxTokens > 0 {
+		client.MaxTokens = maxTokens
+	}
+	client.Temp = temperature
+	client.OnUsage = usageRecorder(provider)
+	applyNetworkSettings(client)
+	applyExchangeLogging(client)
+	// Requests still running when REAPER unloads the plugin are cancelled
+	client.HTTPClient = llm.CancelWith(client.HTTPClient, core.ShutdownContext())
+
+	return client
+}
+
+// cacheNamespace identifies a provider configuration so cached responses are only
+// reused for the same endpoint, model and sampling settings
+func cacheNamespace(provider config.Provider) string {
+	model, maxTokens, temperature := config.GetProviderConfig(provider)
+	return fmt.Sprintf("%s|%s|%s|%d|%.2f", provider, config.GetProviderBaseURL(provider), model, maxTokens, temperature)
+}
+
+// providerDisplayName returns a human-readable name for a provider
+func providerDisplayName(provider config.Provider) string {
+	switch provider {
+	case config.ProviderGemini:
+		return "Gemini"
+	case config.ProviderOpenAICompatible:
+		return "OpenAI-compatible"
+	default:
+		return "OpenAI"
+	}
+}
+
+// providerModelName names a provider and its configured model, e.g. "OpenAI gpt-4o"
+func providerModelName(provider config.Provider) string {
+	model, _, _ := config.GetProviderConfig(provider)
+	if model == "" {
+		return providerDisplayName(provider)
+	}
+	return providerDisplayName(provider) + " " + model
+}
+
+// getProviderAPIKey returns the stored API key for a provider, or asks the user for one
+// and stores it. A key file encrypted with a passphrase is unlocked first.
+func getProviderAPIKey(provider config.Provider) (string, error) {
+	apiKey, err := config.GetSecureAPIKey(provider)
+	if errors.Is(err, config.ErrPassphraseRequired) || errors.Is(err, config.ErrWrongPassphrase) {
+		if unlockKeyFile("API Key File") == nil {
+			apiKey, err = config.GetSecureAPIKey(provider)
+		}
+	}
+	if err == nil && apiKey != "" {
+		return apiKey, nil
+	}
+
+	name := providerDisplayName(provider)
+	fields := []string{name + " API Key"}
 	defaults := []string{""}
 
-	values, err := reaper.GetUserInputs("Enter OpenAI API Key", fields, defaults)
+	// The native dialog keeps keys intact even if they contain commas
+	var values []string
+	if runtime.GOOS == "darwin" {
+		values, err = ui.ShowInputDialog("Enter "+name+" API Key", "", fields, defaults)
+	} else {
+		form := reaper.InputForm{
+			Title:      "Enter " + name + " API Key",
+			Fields:     []reaper.InputField{{Label: fields[0], Default: defaults[0], Password: true}},
+			ExtraWidth: 200,
+		}
+		values, err = form.Show()
+	}
 	if err != nil {
 		return "", err
 	}
 
-	apiKey := values[0]
-	if apiKey == "" {
+	// Local OpenAI-compatible servers usually don't require a key
+	apiKey = values[0]
+	if apiKey == "" && provider != config.ProviderOpenAICompatible {
 		return "", fmt.Errorf("API key is required")
 	}
 
+	if apiKey != "" {
+		storeErr := config.StoreSecureAPIKey(provider, apiKey)
+		if errors.Is(storeErr, config.ErrPassphraseRequired) && askKeyFilePassphrase("API Key File", "Passphrase to encrypt API keys") == nil {
+			storeErr = config.StoreSecureAPIKey(provider, apiKey)
+		}
+		if storeErr != nil {
+			logger.Warning("The %s API key was not stored and will be asked for again: %v", name, storeErr)
+		}
+	}
+
 	return apiKey, nil
 }
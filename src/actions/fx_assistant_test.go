@@ -0,0 +1,56 @@
+package actions
+
+import "testing"
+
+func TestParseAssistantResponse(t *testing.T) {
+	response, err := parseAssistantResponse(`{"suggestions":[{"fx_index":1,"param_index":3,"param_name":"Threshold","value":0.4,"explanation":"Catch more peaks"}],"reasoning":"The vocal is too dynamic"}`)
+	if err != nil {
+		t.Fatalf("parseAssistantResponse: %v", err)
+	}
+	if response.Reasoning != "The vocal is too dynamic" || len(response.Suggestions) != 1 {
+		t.Fatalf("response = %+v", response)
+	}
+	if s := response.Suggestions[0]; s.FXIndex != 1 || s.ParamIndex != 3 || s.ParamName != "Threshold" || s.Value != 0.4 {
+		t.Errorf("suggestion = %+v", s)
+	}
+}
+
+func TestParseAssistantResponseEmbeddedJSON(t *testing.T) {
+	text := "Here are my suggestions:\n```json\n{\"suggestions\":[{\"fx_index\":0,\"param_index\":0,\"value\":0.5}],\"reasoning\":\"ok\"}\n```\nHope that helps."
+	response, err := parseAssistantResponse(text)
+	if err != nil {
+		t.Fatalf("parseAssistantResponse: %v", err)
+	}
+	if len(response.Suggestions) != 1 || response.Suggestions[0].Value != 0.5 {
+		t.Errorf("suggestions = %+v", response.Suggestions)
+	}
+}
+
+func TestParseAssistantResponseFixesValues(t *testing.T) {
+	response, err := parseAssistantResponse(`{"suggestions":[{"fx_index":-2,"value":1.5},{"fx_index":0,"value":-0.25}]}`)
+	if err != nil {
+		t.Fatalf("parseAssistantResponse: %v", err)
+	}
+	if s := response.Suggestions[0]; s.FXIndex != 0 || s.Value != 1 {
+		t.Errorf("suggestion 0 = %+v, want FX 0 and value 1", s)
+	}
+	if s := response.Suggestions[1]; s.Value != 0 {
+		t.Errorf("suggestion 1 = %+v, want value 0", s)
+	}
+}
+
+func TestParseAssistantResponseErrors(t *testing.T) {
+	for _, text := range []string{"", "No JSON here", "{not json}"} {
+		if _, err := parseAssistantResponse(text); err == nil {
+			t.Errorf("parseAssistantResponse(%q): err = nil, want an error", text)
+		}
+	}
+
+	response, err := parseAssistantResponse(`{"reasoning":"Nothing to change"}`)
+	if err != nil {
+		t.Fatalf("parseAssistantResponse without suggestions: %v", err)
+	}
+	if response.Suggestions == nil {
+		t.Error("Suggestions = nil, want an empty list")
+	}
+}
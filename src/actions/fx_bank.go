@@ -0,0 +1,206 @@
+package actions
+
+import (
+	"fmt"
+	"go-reaper/src/core"
+	"go-reaper/src/pkg/config"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"strconv"
+	"strings"
+)
+
+// This file implements the FX control bank: a run of CCs on one channel that always
+// controls the focused FX, a page of parameters at a time, so a generic MIDI controller
+// follows whichever plugin is open. It reads the same MIDI input as MIDI learn; CCs with
+// a learned binding go to the binding instead.
+
+// fxBank tracks the page of the focused FX. It is only touched on the main thread.
+type fxBank struct {
+	fxGUID string // FX the page belongs to; focusing another FX starts at page 1
+	page   int
+}
+
+var controlBank = &fxBank{}
+
+// The FX control bank actions
+func init() {
+	registerAction(Action{
+		ID:          "GO_FX_BANK_TOGGLE",
+		Name:        "Go: FX Control Bank (Toggle)",
+		Handler:     handleToggleControlBank,
+		ToggleState: func() bool { return config.GetControlBank().Enabled },
+	})
+	registerAction(Action{
+		ID:      "GO_FX_BANK_NEXT_PAGE",
+		Name:    "Go: FX Control Bank next page",
+		Handler: func() { controlBank.turnPage(1) },
+	})
+	registerAction(Action{
+		ID:      "GO_FX_BANK_PREV_PAGE",
+		Name:    "Go: FX Control Bank previous page",
+		Handler: func() { controlBank.turnPage(-1) },
+	})
+	registerAction(Action{
+		ID:      "GO_FX_BANK_SHOW",
+		Name:    "Go: FX Control Bank page",
+		Handler: handleShowControlBank,
+	})
+	registerAction(Action{
+		ID:      "GO_FX_BANK_SETTINGS",
+		Name:    "Go: FX Control Bank settings...",
+		Handler: handleControlBankSettings,
+	})
+}
+
+// bankSlot returns which of the bank's controls an event comes from
+func bankSlot(settings config.ControlBankSettings, event reaper.MIDIEvent) (int, bool) {
+	if !settings.Enabled || !event.IsCC() || event.Channel() != settings.Channel {
+		return 0, false
+	}
+	slot := int(event.Data1) - settings.FirstCC
+	return slot, slot >= 0 && slot < settings.Size
+}
+
+// focused returns the focused FX and its parameter count, starting at page 1 when the
+// focus moved to another FX
+func (b *fxBank) focused() (fxLocation, int, error) {
+	track, fxIndex, err := reaper.GetFocusedFX()
+	if err != nil {
+		return fxLocation{}, 0, err
+	}
+	guid, err := reaper.GetTrackFXGUID(track, fxIndex)
+	if err != nil {
+		return fxLocation{}, 0, err
+	}
+	count, err := reaper.GetTrackFXParamCount(track, fxIndex)
+	if err != nil {
+		return fxLocation{}, 0, err
+	}
+
+	if guid != b.fxGUID {
+		b.fxGUID = guid
+		b.page = 0
+	}
+	return fxLocation{track: track, fxIndex: fxIndex}, count, nil
+}
+
+// apply sets the focused FX's parameters on the current page from the bank's CC values,
+// scaled over each parameter's range, in one batch call
+func (b *fxBank) apply(settings config.ControlBankSettings, values map[int]byte) {
+	at, count, err := b.focused()
+	if err != nil {
+		logger.Debug("FX control bank: no focused FX: %v", err)
+		return
+	}
+
+	var changes []reaper.ParameterChange
+	for slot, value := range values {
+		param := b.page*settings.Size + slot
+		if param >= count {
+			continue
+		}
+		_, min, max, err := reaper.GetTrackFXParamValueWithRange(at.track, at.fxIndex, param)
+		if err != nil {
+			continue
+		}
+		changes = append(changes, reaper.ParameterChange{
+			Track:      at.track,
+			FXIndex:    at.fxIndex,
+			ParamIndex: param,
+			Value:      min + (max-min)*float64(value)/127,
+		})
+	}
+
+	if _, err := reaper.BatchSetParameters(changes); err != nil {
+		logger.Warning("FX control bank failed to set parameters: %v", err)
+	}
+}
+
+// turnPage moves the bank by delta pages of the focused FX's parameters
+func (b *fxBank) turnPage(delta int) {
+	settings := config.GetControlBank()
+	_, count, err := b.focused()
+	if err != nil {
+		logger.Info("FX control bank: focus an FX to page through its parameters")
+		return
+	}
+
+	pages := (count + settings.Size - 1) / settings.Size
+	page := b.page + delta
+	if page < 0 || page >= pages {
+		return
+	}
+	b.page = page
+	first := page * settings.Size
+	logger.Info("FX control bank: page %d of %d, parameters %d to %d", page+1, pages, first+1, min(first+settings.Size, count))
+}
+
+// handleToggleControlBank switches the bank on or off
+func handleToggleControlBank() {
+	settings := config.GetControlBank()
+	settings.Enabled = !settings.Enabled
+	if err := config.SetControlBank(settings); err != nil {
+		core.HandleError("FX Control Bank", core.NewError(core.CategoryStorage, "Could not save the control bank settings.", err))
+	}
+}
+
+// handleShowControlBank lists which CC controls which parameter on the current page
+func handleShowControlBank() {
+	const title = "FX Control Bank"
+
+	settings := config.GetControlBank()
+	at, count, err := controlBank.focused()
+	if err != nil {
+		reaper.MessageBox("Focus an FX window to see which parameters the bank controls.", title)
+		return
+	}
+
+	fxName, _ := reaper.GetTrackFXName(at.track, at.fxIndex)
+	pages := (count + settings.Size - 1) / settings.Size
+
+	var builder strings.Builder
+	if !settings.Enabled {
+		builder.WriteString("The bank is switched off.\n\n")
+	}
+	builder.WriteString(fmt.Sprintf("%s, page %d of %d (channel %d):\n", fxName, controlBank.page+1, pages, settings.Channel))
+	for slot := 0; slot < settings.Size; slot++ {
+		param := controlBank.page*settings.Size + slot
+		if param >= count {
+			break
+		}
+		name, _ := reaper.GetTrackFXParamName(at.track, at.fxIndex, param)
+		builder.WriteString(fmt.Sprintf("  CC %d → %s\n", settings.FirstCC+slot, name))
+	}
+	reaper.MessageBox(builder.String(), title)
+}
+
+// handleControlBankSettings asks for the bank's channel and CCs
+func handleControlBankSettings() {
+	const title = "FX Control Bank"
+
+	settings := config.GetControlBank()
+	values, err := reaper.GetUserInputs(title,
+		[]string{"Channel (1-16)", "First CC", "Number of CCs"},
+		[]string{strconv.Itoa(settings.Channel), strconv.Itoa(settings.FirstCC), strconv.Itoa(settings.Size)})
+	if err != nil {
+		logger.Debug("Control bank settings cancelled")
+		return
+	}
+
+	channel, channelErr := strconv.Atoi(strings.TrimSpace(values[0]))
+	firstCC, firstErr := strconv.Atoi(strings.TrimSpace(values[1]))
+	size, sizeErr := strconv.Atoi(strings.TrimSpace(values[2]))
+	if channelErr != nil || firstErr != nil || sizeErr != nil {
+		reaper.MessageBox("Enter whole numbers for the channel and CCs.", title)
+		return
+	}
+
+	settings.Channel, settings.FirstCC, settings.Size = channel, firstCC, size
+	settings.Enabled = true
+	if err := config.SetControlBank(settings); err != nil {
+		reaper.MessageBox(fmt.Sprintf("The settings were not saved: %v", err), title)
+		return
+	}
+	logger.Info("FX control bank on channel %d, CCs %d to %d", channel, firstCC, firstCC+size-1)
+}
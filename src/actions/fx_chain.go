@@ -0,0 +1,143 @@
+package actions
+
+import (
+	"fmt"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"strings"
+	"unsafe"
+)
+
+// Chain change actions the assistant can suggest in chain reasoning mode
+const (
+	chainActionBypass = "bypass"
+	chainActionEnable = "enable"
+	chainActionMove   = "move"
+)
+
+// ChainChange is a suggested change to the FX chain itself rather than a parameter
+type ChainChange struct {
+	Action      string `json:"action" desc:"One of: bypass, enable, move"`
+	FXIndex     int    `json:"fx_index" desc:"Index of the FX in the chain as listed in the prompt"`
+	ToIndex     int    `json:"to_index,omitempty" desc:"New position in the chain, only for move"`
+	Explanation string `json:"explanation"`
+}
+
+// buildChainPrompt describes the whole chain in processing order, with instructions for chain changes
+func buildChainPrompt(fxList []reaper.FXInfo) string {
+	var builder strings.Builder
+
+	builder.WriteString("Full FX chain in processing order:\n")
+	for _, fx := range fxList {
+		state := "enabled"
+		switch {
+		case fx.Offline:
+			state = "offline"
+		case !fx.Enabled:
+			state = "bypassed"
+		}
+		builder.WriteString(fmt.Sprintf("  %d: %s (%s)\n", fx.Index, fx.Name, state))
+	}
+
+	builder.WriteString("\nBesides parameter suggestions, you may suggest chain changes in a \"chain_changes\" array. ")
+	builder.WriteString("Each entry has \"action\" (\"bypass\", \"enable\" or \"move\"), \"fx_index\" (the index listed above), ")
+	builder.WriteString("\"to_index\" (the new position, for \"move\" only) and \"explanation\". ")
+	builder.WriteString("Only suggest chain changes that clearly help the request, such as bypassing redundant processors or fixing the processing order.\n\n")
+
+	return builder.String()
+}
+
+// formatChainChanges formats chain changes for the confirmation dialog
+func formatChainChanges(changes []ChainChange) string {
+	var builder strings.Builder
+	builder.WriteString("\nChain Changes:\n")
+
+	for _, change := range changes {
+		switch change.Action {
+		case chainActionMove:
+			builder.WriteString(fmt.Sprintf("  • Move FX %d to position %d\n", change.FXIndex, change.ToIndex))
+		case chainActionBypass:
+			builder.WriteString(fmt.Sprintf("  • Bypass FX %d\n", change.FXIndex))
+		case chainActionEnable:
+			builder.WriteString(fmt.Sprintf("  • Enable FX %d\n", change.FXIndex))
+		default:
+			builder.WriteString(fmt.Sprintf("  • %s FX %d (unsupported, will be skipped)\n", change.Action, change.FXIndex))
+		}
+		if change.Explanation != "" {
+			builder.WriteString("    " + change.Explanation + "\n")
+		}
+	}
+
+	return builder.String()
+}
+
+// applyChainChanges applies bypass/enable changes, then moves. It must run after parameter
+// changes are applied. FX indices always refer to the chain as it was sent to the LLM,
+// so each move is translated to the FX's current position.
+func applyChainChanges(track unsafe.Pointer, changes []ChainChange) error {
+	count, err := reaper.GetTrackFXCount(track)
+	if err != nil {
+		return fmt.Errorf("failed to get FX count: %v", err)
+	}
+
+	// Validate everything first so a bad entry doesn't leave the chain half-changed
+	for _, change := range changes {
+		if change.FXIndex < 0 || change.FXIndex >= count {
+			return fmt.Errorf("FX index %d is out of range", change.FXIndex)
+		}
+		if change.Action == chainActionMove && (change.ToIndex < 0 || change.ToIndex >= count) {
+			return fmt.Errorf("move target %d is out of range", change.ToIndex)
+		}
+	}
+
+	for _, change := range changes {
+		switch change.Action {
+		case chainActionBypass, chainActionEnable:
+			enabled := change.Action == chainActionEnable
+			if err := reaper.SetTrackFXEnabled(track, change.FXIndex, enabled); err != nil {
+				return fmt.Errorf("failed to %s FX %d: %v", change.Action, change.FXIndex, err)
+			}
+			logger.Info("Applied: %s FX %d - %s", change.Action, change.FXIndex, change.Explanation)
+		case chainActionMove:
+			// Handled below, once all index-stable changes are done
+		default:
+			logger.Warning("Skipping unsupported chain change %q for FX %d", change.Action, change.FXIndex)
+		}
+	}
+
+	// order[i] is the original index of the FX currently at position i
+	order := make([]int, count)
+	for i := range order {
+		order[i] = i
+	}
+
+	for _, change := range changes {
+		if change.Action != chainActionMove {
+			continue
+		}
+
+		from := indexOf(order, change.FXIndex)
+		if err := reaper.MoveTrackFX(track, from, change.ToIndex); err != nil {
+			return fmt.Errorf("failed to move FX %d: %v", change.FXIndex, err)
+		}
+
+		// Mirror the move in our view of the chain
+		moved := order[from]
+		order = append(order[:from], order[from+1:]...)
+		order = append(order[:change.ToIndex], append([]int{moved}, order[change.ToIndex:]...)...)
+
+		logger.Info("Applied: move FX %d to position %d - %s", change.FXIndex, change.ToIndex, change.Explanation)
+	}
+
+	return nil
+}
+
+// indexOf returns the position of value in values, or -1
+func indexOf(values []int, value int) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,181 @@
+package actions
+
+import (
+	"fmt"
+	"go-reaper/src/core"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// This file implements exporting a track's FX chain to a .RfxChain file and importing one
+// onto a track. Files are saved to REAPER's FXChains folder, so they also show up in the
+// FX browser.
+
+// fxChainExtension is the extension REAPER uses for FX chain files
+const fxChainExtension = "RfxChain"
+
+// The FX chain file actions
+func init() {
+	registerAction(Action{
+		ID:      "GO_FX_CHAIN_EXPORT",
+		Name:    "Go: Export FX Chain of Selected Track",
+		Handler: handleExportFXChain,
+	})
+	registerAction(Action{
+		ID:      "GO_FX_CHAIN_IMPORT",
+		Name:    "Go: Import FX Chain to Selected Track",
+		Handler: handleImportFXChain,
+	})
+}
+
+// fxChainsDir is REAPER's folder for FX chain files
+func fxChainsDir() (string, error) {
+	resourcePath, err := reaper.GetResourcePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(resourcePath, "FXChains"), nil
+}
+
+// selectedTrackForChain returns the selected track, reporting a missing selection
+func selectedTrackForChain(title string) (*reaper.TrackInfo, bool) {
+	trackInfo, err := reaper.GetSelectedTrackInfo()
+	if err != nil {
+		if strings.Contains(err.Error(), "no track selected") {
+			core.HandleError(title, core.NewError(core.CategoryUser, "Please select a track first.", err))
+		} else {
+			core.HandleError(title, core.NewError(core.CategoryReaper, "Could not read the selected track.", err))
+		}
+		return nil, false
+	}
+	return trackInfo, true
+}
+
+// handleExportFXChain saves the selected track's FX chain under a name the user chooses
+func handleExportFXChain() {
+	const title = "Export FX Chain"
+
+	trackInfo, ok := selectedTrackForChain(title)
+	if !ok {
+		return
+	}
+
+	fxChain, err := reaper.GetTrackFXChain(trackInfo.MediaTrack)
+	if err != nil {
+		core.HandleError(title, core.NewError(core.CategoryReaper, "Could not read the track's FX chain.", err).
+			WithDetail("track %q", trackInfo.Name))
+		return
+	}
+	if fxChain == "" {
+		reaper.MessageBox("The selected track has no FX.", title)
+		return
+	}
+
+	dir, err := fxChainsDir()
+	if err != nil {
+		core.HandleError(title, core.NewError(core.CategoryReaper, "Could not find REAPER's FXChains folder.", err))
+		return
+	}
+
+	values, err := reaper.GetUserInputs(title, []string{"File name"}, []string{sanitizeFileName(trackInfo.Name)})
+	if err != nil {
+		logger.Info("User cancelled the dialog")
+		return
+	}
+	name := sanitizeFileName(values[0])
+	if name == "" {
+		core.HandleError(title, core.NewError(core.CategoryUser, "Please enter a file name.", nil))
+		return
+	}
+
+	path := filepath.Join(dir, name+"."+fxChainExtension)
+	if _, err := os.Stat(path); err == nil {
+		overwrite, _ := reaper.YesNoBox(fmt.Sprintf("%s already exists. Replace it?", filepath.Base(path)), title)
+		if !overwrite {
+			return
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		core.HandleError(title, core.NewError(core.CategoryStorage, "Could not create the FXChains folder.", err))
+		return
+	}
+	if err := os.WriteFile(path, []byte(fxChain), 0o644); err != nil {
+		core.HandleError(title, core.NewError(core.CategoryStorage, "Could not save the FX chain.", err).
+			WithDetail("path %s", path))
+		return
+	}
+
+	logger.Info("Exported the FX chain of track %q to %s", trackInfo.Name, path)
+	reaper.MessageBox(fmt.Sprintf("Saved the FX chain to %s", path), title)
+}
+
+// handleImportFXChain adds the FX from a chain file after the selected track's FX, as
+// one undo point
+func handleImportFXChain() {
+	const title = "Import FX Chain"
+
+	trackInfo, ok := selectedTrackForChain(title)
+	if !ok {
+		return
+	}
+
+	initialPath := ""
+	if dir, err := fxChainsDir(); err == nil {
+		initialPath = dir + string(filepath.Separator)
+	}
+
+	path, ok, err := reaper.BrowseForFile(title, initialPath, fxChainExtension)
+	if err != nil {
+		core.HandleError(title, core.NewError(core.CategoryReaper, "Could not show the file dialog.", err))
+		return
+	}
+	if !ok {
+		return
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		core.HandleError(title, core.NewError(core.CategoryStorage, "Could not read the FX chain file.", err).
+			WithDetail("path %s", path))
+		return
+	}
+	if strings.TrimSpace(string(data)) == "" {
+		core.HandleError(title, core.NewError(core.CategoryUser, "The FX chain file is empty.", nil).
+			WithDetail("path %s", path))
+		return
+	}
+
+	if err := reaper.UndoBeginBlock(); err != nil {
+		logger.Warning("Failed to start undo block: %v", err)
+	} else {
+		defer func() {
+			description := "Import FX chain " + filepath.Base(path)
+			if err := reaper.UndoEndBlock(description, reaper.UndoStateFX); err != nil {
+				logger.Warning("Failed to end undo block: %v", err)
+			}
+		}()
+	}
+
+	if err := reaper.AppendTrackFXChain(trackInfo.MediaTrack, string(data)); err != nil {
+		core.HandleError(title, core.NewError(core.CategoryReaper, "Could not add the FX chain to the track.", err).
+			WithDetail("track %q, file %s", trackInfo.Name, path))
+		return
+	}
+
+	logger.Info("Imported %s onto track %q", path, trackInfo.Name)
+}
+
+// sanitizeFileName replaces the characters file systems reject in a name
+func sanitizeFileName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`<>:"/\|?*`, r) || r < 32 {
+			return '_'
+		}
+		return r
+	}, name)
+	return strings.Trim(strings.TrimSpace(name), ".")
+}
@@ -0,0 +1,131 @@
+package actions
+
+import (
+	"fmt"
+	"go-reaper/src/core"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"strings"
+	"unsafe"
+)
+
+// The action that copies the focused FX's settings to the same plugin on other tracks
+func init() {
+	registerAction(Action{
+		ID:      "GO_FX_COPY_SETTINGS",
+		Name:    "Go: Copy FX settings to selected tracks",
+		Handler: handleCopyFXSettings,
+	})
+}
+
+// handleCopyFXSettings copies every parameter of the focused FX to each instance of the
+// same plugin on the other selected tracks, as one undo point
+func handleCopyFXSettings() {
+	const title = "Copy FX Settings"
+
+	// STEP 1: Read the focused FX
+	source, fxIndex, err := reaper.GetFocusedFX()
+	if err != nil {
+		core.HandleError(title, core.NewError(core.CategoryUser, "Open or focus the FX window to copy settings from.", err))
+		return
+	}
+	summaries, err := reaper.GetTrackFXSummaries(source)
+	if err != nil || fxIndex >= len(summaries) {
+		core.HandleError(title, core.NewError(core.CategoryReaper, "Could not read the focused FX.", err))
+		return
+	}
+	sourceFX := summaries[fxIndex]
+
+	parameters, err := reaper.BatchGetFXParameters(source, fxIndex)
+	if err != nil {
+		core.HandleError(title, core.NewError(core.CategoryReaper, "Could not read the focused FX's parameters.", err).
+			WithDetail("FX %q", sourceFX.Name))
+		return
+	}
+
+	// STEP 2: Find the same plugin on the other selected tracks
+	tracks, err := reaper.GetSelectedTracks()
+	if err != nil {
+		core.HandleError(title, core.NewError(core.CategoryReaper, "Could not read the selected tracks.", err))
+		return
+	}
+
+	var changes []reaper.ParameterChange
+	var targets, skipped []string
+	for _, track := range tracks {
+		if track == source {
+			continue
+		}
+		matches, notes := matchingFX(track, sourceFX, len(parameters))
+		skipped = append(skipped, notes...)
+		for _, index := range matches {
+			for _, param := range parameters {
+				changes = append(changes, reaper.ParameterChange{Track: track, FXIndex: index, ParamIndex: param.Index, Value: param.Value})
+			}
+			name, _ := reaper.GetTrackName(track)
+			targets = append(targets, fmt.Sprintf("%s (FX %d)", name, index+1))
+		}
+	}
+
+	if len(targets) == 0 {
+		msg := fmt.Sprintf("None of the other selected tracks has %s.", sourceFX.Name)
+		if len(skipped) > 0 {
+			msg += "\n\n" + strings.Join(skipped, "\n")
+		}
+		reaper.MessageBox(msg, title)
+		return
+	}
+
+	// STEP 3: Set everything in one call and one undo point
+	if err := reaper.UndoBeginBlock(); err != nil {
+		logger.Warning("Failed to start undo block: %v", err)
+	} else {
+		defer func() {
+			if err := reaper.UndoEndBlock(fmt.Sprintf("Copy %s settings to %d FX", sourceFX.Name, len(targets)), reaper.UndoStateFX); err != nil {
+				logger.Warning("Failed to end undo block: %v", err)
+			}
+		}()
+	}
+
+	failed, err := reaper.BatchSetParameters(changes)
+	if err != nil {
+		core.HandleError(title, core.NewError(core.CategoryReaper, "Could not set the parameters.", err))
+		return
+	}
+
+	logger.Info("Copied %d parameters of %s to %d FX, %d failed", len(parameters), sourceFX.Name, len(targets), len(failed))
+	msg := fmt.Sprintf("Copied the settings of %s to:\n- %s", sourceFX.Name, strings.Join(targets, "\n- "))
+	if len(failed) > 0 {
+		msg += fmt.Sprintf("\n\n%d parameter(s) could not be set.", len(failed))
+	}
+	if len(skipped) > 0 {
+		msg += "\n\nSkipped:\n- " + strings.Join(skipped, "\n- ")
+	}
+	reaper.MessageBox(msg, title)
+}
+
+// matchingFX returns the indices of the FX on a track that are the same plugin as fx,
+// matched by plugin identity so renamed instances count. Instances with a different
+// parameter count, e.g. another version of the plugin, are described in skipped instead.
+func matchingFX(track unsafe.Pointer, fx reaper.FXInfo, paramCount int) (matches []int, skipped []string) {
+	summaries, err := reaper.GetTrackFXSummaries(track)
+	if err != nil {
+		logger.Warning("Could not read FX on a selected track: %v", err)
+		return nil, nil
+	}
+
+	key := fx.IdentityKey()
+	for _, candidate := range summaries {
+		if candidate.IdentityKey() != key {
+			continue
+		}
+		count, err := reaper.GetTrackFXParamCount(track, candidate.Index)
+		if err != nil || count != paramCount {
+			name, _ := reaper.GetTrackName(track)
+			skipped = append(skipped, fmt.Sprintf("%s: %s has %d parameters instead of %d", name, candidate.Name, count, paramCount))
+			continue
+		}
+		matches = append(matches, candidate.Index)
+	}
+	return matches, skipped
+}
@@ -0,0 +1,451 @@
+package actions
+
+import (
+	"fmt"
+	"go-reaper/src/reaper"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// This file implements the offline, rule-based fallback for the FX Assistant.
+// Parameters are classified by name (and by their paired frequency for EQ gains),
+// then nudged according to keywords found in the user's request.
+
+// paramRole classifies what a parameter does
+type paramRole int
+
+const (
+	roleUnknown paramRole = iota
+	roleLowGain
+	roleLowMidGain
+	roleHighMidGain
+	roleHighGain
+	roleThreshold
+	roleRatio
+	roleAttack
+	roleRelease
+	roleWet
+	roleDrive
+	roleOutput
+)
+
+// heuristicRule describes how request keywords adjust parameters of given roles.
+// Keywords are whole words, or stems ending in "*" that match any ending, e.g. "warm*"
+// matches "warmer" and "warmth"; several words must appear in order.
+type heuristicRule struct {
+	keywords    []string              // Qualities the rule adds; "less", "too", "no" or "not" before one negates it
+	problems    []string              // Problems the rule fixes, e.g. "thin"; "too thin" still means fix it
+	adjustments map[paramRole]float64 // Normalized delta per role
+	explanation string
+	opposite    string // Explanation of the reversed adjustments, or "" if negating skips the rule
+}
+
+// heuristicRules are checked in order; every matching rule contributes adjustments
+var heuristicRules = []heuristicRule{
+	{
+		keywords:    []string{"warm*"},
+		adjustments: map[paramRole]float64{roleHighGain: -0.04, roleLowMidGain: 0.03},
+		explanation: "warmer tone: softer highs, a touch more low-mids",
+		opposite:    "less warmth: a touch more highs, fewer low-mids",
+	},
+	{
+		keywords:    []string{"bright*", "air", "airy", "airier", "airiness", "sparkl*", "crisp*"},
+		adjustments: map[paramRole]float64{roleHighGain: 0.04},
+		explanation: "brighter tone: lift the high end",
+		opposite:    "less bright: lower the high end",
+	},
+	{
+		keywords:    []string{"clear*", "clarity", "definition", "defined"},
+		problems:    []string{"mud", "muddy", "muddier", "muddiness"},
+		adjustments: map[paramRole]float64{roleLowMidGain: -0.04, roleHighMidGain: 0.02},
+		explanation: "more clarity: cut low-mid mud, slight presence lift",
+	},
+	{
+		keywords:    []string{"dark*", "smooth*"},
+		problems:    []string{"harsh*", "sibil*"},
+		adjustments: map[paramRole]float64{roleHighMidGain: -0.04, roleHighGain: -0.03},
+		explanation: "smoother tone: tame upper mids and highs",
+		opposite:    "less dark: lift upper mids and highs",
+	},
+	{
+		keywords:    []string{"bass", "bassier", "full", "fuller", "fullness", "thick*", "body", "weight*"},
+		problems:    []string{"thin", "thinner", "thinness"},
+		adjustments: map[paramRole]float64{roleLowGain: 0.04},
+		explanation: "fuller sound: more low end",
+		opposite:    "less low end: reduce the lows",
+	},
+	{
+		problems:    []string{"boom*", "boxy", "boxiness"},
+		adjustments: map[paramRole]float64{roleLowGain: -0.04, roleLowMidGain: -0.03},
+		explanation: "less boom: reduce lows and low-mids",
+	},
+	{
+		keywords:    []string{"dynamic*", "natural*", "open up"},
+		adjustments: map[paramRole]float64{roleThreshold: 0.08, roleRatio: -0.08},
+		explanation: "more dynamics: raise threshold, lower ratio",
+		opposite:    "more control: lower threshold, higher ratio",
+	},
+	{
+		keywords:    []string{"compress*", "tight*", "control*", "even out", "evenly", "evenness", "glue*", "squash*"},
+		adjustments: map[paramRole]float64{roleThreshold: -0.08, roleRatio: 0.06},
+		explanation: "more control: lower threshold, higher ratio",
+		opposite:    "more dynamics: raise threshold, lower ratio",
+	},
+	{
+		keywords:    []string{"punch*"},
+		adjustments: map[paramRole]float64{roleAttack: 0.05, roleRatio: 0.04, roleRelease: -0.03},
+		explanation: "more punch: slower attack lets transients through",
+		opposite:    "less punch: faster attack rounds off transients",
+	},
+	{
+		keywords:    []string{"reverb*", "delay*", "wet*", "space*", "spacious", "ambien*", "big", "bigger"},
+		adjustments: map[paramRole]float64{roleWet: 0.1},
+		explanation: "more space: raise the wet mix",
+		opposite:    "drier sound: lower the wet mix",
+	},
+	{
+		keywords:    []string{"dry", "drier", "close", "closer", "intimate"},
+		adjustments: map[paramRole]float64{roleWet: -0.1},
+		explanation: "drier sound: lower the wet mix",
+		opposite:    "more space: raise the wet mix",
+	},
+	{
+		keywords:    []string{"dirt*", "drive", "driven", "satur*", "grit*", "distort*", "crunch*"},
+		adjustments: map[paramRole]float64{roleDrive: 0.08},
+		explanation: "more character: increase drive",
+		opposite:    "cleaner sound: reduce drive",
+	},
+	{
+		keywords:    []string{"clean*"},
+		adjustments: map[paramRole]float64{roleDrive: -0.08},
+		explanation: "cleaner sound: reduce drive",
+		opposite:    "more character: increase drive",
+	},
+	{
+		keywords:    []string{"loud*", "more level", "volume up"},
+		adjustments: map[paramRole]float64{roleOutput: 0.03},
+		explanation: "louder: raise output level",
+		opposite:    "quieter: lower output level",
+	},
+	{
+		keywords:    []string{"quiet*", "softer", "less level", "volume down"},
+		adjustments: map[paramRole]float64{roleOutput: -0.03},
+		explanation: "quieter: lower output level",
+		opposite:    "louder: raise output level",
+	},
+}
+
+// matchedRule is a rule a request triggers; sign is -1 when a keyword was negated
+type matchedRule struct {
+	rule *heuristicRule
+	sign float64
+}
+
+// explanation describes what the matched rule does
+func (m matchedRule) explanation() string {
+	if m.sign < 0 {
+		return m.rule.opposite
+	}
+	return m.rule.explanation
+}
+
+// matchHeuristicRules finds the rules a request triggers. At each word the longest
+// keyword wins, so "less level" isn't also read as "level". A quality keyword right
+// after "less", "too", "too much" or "no" reverses its rule, e.g. "less bass" cuts the
+// lows, and after "not" or "no more" it is ignored as too vague. A problem keyword is
+// fixed either way, except after "not".
+func matchHeuristicRules(request string) []matchedRule {
+	words := strings.FieldsFunc(strings.ToLower(request), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var matched []matchedRule
+	for i := 0; i < len(words); {
+		var best *heuristicRule
+		bestLength, problem := 0, false
+		for r := range heuristicRules {
+			rule := &heuristicRules[r]
+			for _, keyword := range rule.keywords {
+				if n := matchKeyword(words[i:], keyword); n > bestLength {
+					best, bestLength, problem = rule, n, false
+				}
+			}
+			for _, keyword := range rule.problems {
+				if n := matchKeyword(words[i:], keyword); n > bestLength {
+					best, bestLength, problem = rule, n, true
+				}
+			}
+		}
+		if best == nil {
+			i++
+			continue
+		}
+
+		sign := 1.0
+		switch negation := negationBefore(words, i); {
+		case negation == "not":
+			sign = 0
+		case negation == "no more" && !problem:
+			sign = 0
+		case negation != "" && !problem:
+			sign = -1
+			if best.opposite == "" {
+				sign = 0
+			}
+		}
+		if sign != 0 && !containsMatch(matched, best, sign) {
+			matched = append(matched, matchedRule{rule: best, sign: sign})
+		}
+		i += bestLength
+	}
+	return matched
+}
+
+// matchKeyword returns how many words a keyword matches at the start of words, or 0
+func matchKeyword(words []string, keyword string) int {
+	parts := strings.Fields(keyword)
+	if len(parts) > len(words) {
+		return 0
+	}
+	for i, part := range parts {
+		if stem, ok := strings.CutSuffix(part, "*"); ok {
+			if !strings.HasPrefix(words[i], stem) {
+				return 0
+			}
+		} else if words[i] != part {
+			return 0
+		}
+	}
+	return len(parts)
+}
+
+// negationBefore returns the negating words before words[i], "less", "too" (including
+// "too much"), "no", "no more" or "not", or "" if there is none
+func negationBefore(words []string, i int) string {
+	if i >= 2 && words[i-1] == "much" && words[i-2] == "too" {
+		return "too"
+	}
+	if i >= 2 && words[i-1] == "more" && words[i-2] == "no" {
+		return "no more"
+	}
+	if i >= 1 {
+		switch words[i-1] {
+		case "less", "too", "no", "not":
+			return words[i-1]
+		}
+	}
+	return ""
+}
+
+// containsMatch reports whether a rule was already matched the same way
+func containsMatch(matched []matchedRule, rule *heuristicRule, sign float64) bool {
+	for _, m := range matched {
+		if m.rule == rule && m.sign == sign {
+			return true
+		}
+	}
+	return false
+}
+
+// suggestHeuristicChanges produces suggestions for the request without calling an LLM
+func suggestHeuristicChanges(fxList []reaper.FXInfo, userRequest string) *AssistantResponse {
+	matched := matchHeuristicRules(userRequest)
+
+	response := &AssistantResponse{Suggestions: []ParameterSuggestion{}}
+	if len(matched) == 0 {
+		response.Reasoning = "The offline assistant only understands simple requests such as warmer, brighter, clearer, more/less compression, punchier, more/less reverb or more drive."
+		return response
+	}
+
+	for _, fx := range fxList {
+		// The rules are about sound; a video processor's "brightness" is not
+		if reaper.IsVideoProcessor(fx) {
+			continue
+		}
+		for _, param := range fx.Parameters {
+			role := classifyParameter(fx.Name, param, fx.Parameters)
+			if role == roleUnknown {
+				continue
+			}
+
+			delta := 0.0
+			var reasons []string
+			for _, m := range matched {
+				if d, ok := m.rule.adjustments[role]; ok {
+					delta += m.sign * d
+					reasons = append(reasons, m.explanation())
+				}
+			}
+			if delta == 0 {
+				continue
+			}
+
+			value := clampUnit(param.Value + delta)
+			if value == param.Value {
+				continue
+			}
+
+			response.Suggestions = append(response.Suggestions, ParameterSuggestion{
+				FXIndex:     fx.Index,
+				ParamIndex:  param.Index,
+				ParamName:   param.Name,
+				Value:       value,
+				Explanation: strings.Join(reasons, "; "),
+			})
+		}
+	}
+
+	explanations := make([]string, 0, len(matched))
+	for _, m := range matched {
+		explanations = append(explanations, m.explanation())
+	}
+	response.Reasoning = fmt.Sprintf("Offline rule-based suggestions (%s). These are small, conservative moves; listen and adjust to taste.",
+		strings.Join(explanations, "; "))
+
+	if len(response.Suggestions) == 0 {
+		response.Reasoning = "None of the selected FX have parameters the offline assistant recognizes for this request (EQ gains, compressor threshold/ratio, mix, drive or output)."
+	}
+
+	return response
+}
+
+// classifyParameter works out a parameter's role from its name, using the paired
+// frequency parameter (e.g. "Freq-Band 2" for "Gain-Band 2") to place EQ gains
+func classifyParameter(fxName string, param reaper.FXParameter, siblings []reaper.FXParameter) paramRole {
+	name := strings.ToLower(param.Name)
+
+	// REAPER's built-in FX mix knob only counts as "wet" on time-based effects;
+	// lowering it on an EQ or compressor would just undo that processor
+	if param.Ident != "" {
+		if param.Ident == reaper.FXIdentWet && isTimeBasedFX(fxName) && !hasOwnMixParameter(siblings) {
+			return roleWet
+		}
+		return roleUnknown
+	}
+
+	switch {
+	case strings.Contains(name, "threshold") || strings.Contains(name, "thresh"):
+		return roleThreshold
+	case strings.Contains(name, "ratio"):
+		return roleRatio
+	case strings.Contains(name, "attack"):
+		return roleAttack
+	case strings.Contains(name, "release"):
+		return roleRelease
+	case name == "wet" || strings.Contains(name, "wet") || name == "mix" || strings.Contains(name, "dry/wet"):
+		return roleWet
+	case strings.Contains(name, "drive") || strings.Contains(name, "saturation"):
+		return roleDrive
+	case strings.Contains(name, "output") || strings.Contains(name, "makeup") || strings.Contains(name, "make-up"):
+		return roleOutput
+	case strings.Contains(name, "gain"):
+		return classifyEQGain(name, siblings)
+	}
+
+	return roleUnknown
+}
+
+// isTimeBasedFX reports whether an FX name suggests a reverb, delay or modulation effect
+func isTimeBasedFX(fxName string) bool {
+	name := strings.ToLower(fxName)
+	for _, marker := range []string{"verb", "delay", "echo", "chorus", "flang", "phaser"} {
+		if strings.Contains(name, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasOwnMixParameter reports whether a plugin exposes its own wet/mix parameter
+func hasOwnMixParameter(params []reaper.FXParameter) bool {
+	for _, param := range params {
+		if param.Ident == "" && classifyParameter("", param, nil) == roleWet {
+			return true
+		}
+	}
+	return false
+}
+
+// classifyEQGain places an EQ gain parameter in a frequency region
+func classifyEQGain(name string, siblings []reaper.FXParameter) paramRole {
+	switch {
+	case strings.Contains(name, "high shelf") || strings.Contains(name, "treble") || strings.Contains(name, "air"):
+		return roleHighGain
+	case strings.Contains(name, "low shelf") || strings.Contains(name, "bass"):
+		return roleLowGain
+	}
+
+	// Look for a frequency parameter sharing the band suffix, e.g. "Gain-Band 2" / "Freq-Band 2"
+	suffix := strings.TrimSpace(strings.TrimLeft(strings.Replace(name, "gain", "", 1), "-_: "))
+	if suffix != "" {
+		for _, sibling := range siblings {
+			siblingName := strings.ToLower(sibling.Name)
+			if !strings.Contains(siblingName, "freq") || !strings.HasSuffix(siblingName, suffix) {
+				continue
+			}
+			if hz, ok := parseFrequency(sibling.FormattedValue); ok {
+				return classifyFrequency(hz)
+			}
+		}
+	}
+
+	switch {
+	case strings.Contains(name, "high") || strings.Contains(name, "hi "):
+		return roleHighGain
+	case strings.Contains(name, "low mid") || strings.Contains(name, "lo mid"):
+		return roleLowMidGain
+	case strings.Contains(name, "mid"):
+		return roleHighMidGain
+	case strings.Contains(name, "low"):
+		return roleLowGain
+	}
+
+	return roleUnknown
+}
+
+// classifyFrequency maps a band's centre frequency to an EQ region
+func classifyFrequency(hz float64) paramRole {
+	switch {
+	case hz < 200:
+		return roleLowGain
+	case hz < 800:
+		return roleLowMidGain
+	case hz < 5000:
+		return roleHighMidGain
+	default:
+		return roleHighGain
+	}
+}
+
+// parseFrequency parses formatted frequencies like "120.0 Hz", "2.5 kHz" or "8k"
+func parseFrequency(formatted string) (float64, bool) {
+	s := strings.ToLower(strings.TrimSpace(formatted))
+
+	multiplier := 1.0
+	if strings.Contains(s, "k") {
+		multiplier = 1000.0
+	}
+
+	end := 0
+	for end < len(s) && ((s[end] >= '0' && s[end] <= '9') || s[end] == '.') {
+		end++
+	}
+
+	value, err := strconv.ParseFloat(s[:end], 64)
+	if err != nil {
+		return 0, false
+	}
+	return value * multiplier, true
+}
+
+// clampUnit clamps a value to the normalized 0-1 range
+func clampUnit(value float64) float64 {
+	if value < 0 {
+		return 0
+	}
+	if value > 1 {
+		return 1
+	}
+	return value
+}
@@ -0,0 +1,95 @@
+package actions
+
+import "testing"
+
+func TestMatchHeuristicRules(t *testing.T) {
+	// Each match is described as the explanation it gives
+	tests := []struct {
+		request string
+		want    []string
+	}{
+		{"brighter please", []string{"brighter tone: lift the high end"}},
+		{"more brightness", []string{"brighter tone: lift the high end"}},
+		{"bright", []string{"brighter tone: lift the high end"}},
+		{"not brighter", nil},
+		{"less bright", []string{"less bright: lower the high end"}},
+		{"too much reverb", []string{"drier sound: lower the wet mix"}},
+		{"no reverb", []string{"drier sound: lower the wet mix"}},
+		{"no more compression", nil},
+		{"no more mud", []string{"more clarity: cut low-mid mud, slight presence lift"}},
+		{"too thin", []string{"fuller sound: more low end"}},
+		{"not muddy", nil},
+		{"less level", []string{"quieter: lower output level"}},
+		{"open up the chorus", []string{"more dynamics: raise threshold, lower ratio"}},
+		// Keywords inside other words don't count
+		{"repair the laundry list", nil},
+		{"overdrive", nil},
+		{"warm and punchy", []string{
+			"warmer tone: softer highs, a touch more low-mids",
+			"more punch: slower attack lets transients through",
+		}},
+		{"warmer, warmer", []string{"warmer tone: softer highs, a touch more low-mids"}},
+	}
+
+	for _, test := range tests {
+		var got []string
+		for _, m := range matchHeuristicRules(test.request) {
+			got = append(got, m.explanation())
+		}
+		if len(got) != len(test.want) {
+			t.Errorf("matchHeuristicRules(%q) = %q, want %q", test.request, got, test.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != test.want[i] {
+				t.Errorf("matchHeuristicRules(%q) = %q, want %q", test.request, got, test.want)
+				break
+			}
+		}
+	}
+}
+
+func TestMatchKeyword(t *testing.T) {
+	tests := []struct {
+		words   []string
+		keyword string
+		want    int
+	}{
+		{[]string{"bright"}, "bright*", 1},
+		{[]string{"brightness"}, "bright*", 1},
+		{[]string{"brightness"}, "bright", 0},
+		{[]string{"chair"}, "air", 0},
+		{[]string{"air"}, "air", 1},
+		{[]string{"even", "out", "the", "mix"}, "even out", 2},
+		{[]string{"even"}, "even out", 0},
+		{[]string{"out", "even"}, "even out", 0},
+	}
+
+	for _, test := range tests {
+		if got := matchKeyword(test.words, test.keyword); got != test.want {
+			t.Errorf("matchKeyword(%q, %q) = %d, want %d", test.words, test.keyword, got, test.want)
+		}
+	}
+}
+
+func TestNegationBefore(t *testing.T) {
+	tests := []struct {
+		words []string
+		i     int
+		want  string
+	}{
+		{[]string{"not", "brighter"}, 1, "not"},
+		{[]string{"less", "bass"}, 1, "less"},
+		{[]string{"too", "much", "reverb"}, 2, "too"},
+		{[]string{"no", "more", "compression"}, 2, "no more"},
+		{[]string{"no", "reverb"}, 1, "no"},
+		{[]string{"more", "compression"}, 1, ""},
+		{[]string{"brighter"}, 0, ""},
+	}
+
+	for _, test := range tests {
+		if got := negationBefore(test.words, test.i); got != test.want {
+			t.Errorf("negationBefore(%q, %d) = %q, want %q", test.words, test.i, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package actions
+
+import (
+	"fmt"
+	"go-reaper/src/core"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"runtime"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// maxListedMatches limits how many matching parameters the confirmation lists
+const maxListedMatches = 12
+
+// The action that changes parameters by name across the FX of the selected tracks
+func init() {
+	registerAction(Action{
+		ID:      "GO_FX_PARAM_ADJUST_BY_NAME",
+		Name:    "Go: Adjust parameters by name on selected tracks",
+		Handler: handleAdjustParamsByName,
+	})
+}
+
+// handleAdjustParamsByName sets, offsets, scales or clamps every parameter whose name
+// contains some text, on FX chosen across tracks, as one undo point. The FX are chosen in
+// the FX picker, starting with every FX on the selected tracks, or typed where there is
+// no native window.
+func handleAdjustParamsByName() {
+	const title = "Adjust Parameters by Name"
+
+	// STEP 1: Ask what to change
+	fields := []string{"Parameter name contains", "Operation (set/add/multiply/clamp)", "Value (min for clamp)", "Max (clamp only)"}
+	defaults := []string{"threshold", "multiply", "0.9", ""}
+	usePicker := runtime.GOOS == "darwin"
+	if !usePicker {
+		fields = append(fields, "FX (e.g. 1.1, 2.*; empty for the selected tracks)")
+		defaults = append(defaults, "")
+	}
+	values, err := reaper.GetUserInputs(title, fields, defaults)
+	if err != nil {
+		logger.Debug("Parameter adjustment cancelled")
+		return
+	}
+
+	filter := strings.ToLower(strings.TrimSpace(values[0]))
+	if filter == "" {
+		reaper.MessageBox("Type part of the parameter names to change.", title)
+		return
+	}
+	op, err := reaper.ParseParameterOp(values[1])
+	if err != nil {
+		reaper.MessageBox(err.Error(), title)
+		return
+	}
+	value, err := strconv.ParseFloat(strings.TrimSpace(values[2]), 64)
+	if err != nil {
+		reaper.MessageBox(fmt.Sprintf("Invalid value: %s", values[2]), title)
+		return
+	}
+	change := reaper.ParameterChange{Op: op, Value: value}
+	if op == reaper.ParamClamp {
+		max, err := strconv.ParseFloat(strings.TrimSpace(values[3]), 64)
+		if err != nil || max < value {
+			reaper.MessageBox(fmt.Sprintf("Invalid clamp range: %s to %s", values[2], values[3]), title)
+			return
+		}
+		change.Min, change.Max = value, max
+	}
+	adjust := func(picks []fxPick) { adjustParamsByName(title, values[0], change, picks) }
+
+	// STEP 2: Choose the FX
+	tracks, err := reaper.GetSelectedTracks()
+	if err != nil {
+		core.HandleError(title, core.NewError(core.CategoryReaper, "Could not read the selected tracks.", err))
+		return
+	}
+
+	if usePicker {
+		selected := make(map[unsafe.Pointer]bool)
+		for _, track := range tracks {
+			selected[track] = true
+		}
+		err := showFXPicker(title, func(track unsafe.Pointer) bool { return selected[track] }, adjust)
+		if err == nil {
+			return
+		}
+		logger.Warning("Failed to open FX picker, using the selected tracks: %v", err)
+	} else if typed := strings.TrimSpace(values[4]); typed != "" {
+		roots, err := reaper.GetTrackTree()
+		if err != nil {
+			core.HandleError(title, core.NewError(core.CategoryReaper, "Could not read the tracks.", err))
+			return
+		}
+		picks, err := parseFXPicks(typed, roots)
+		if err != nil {
+			reaper.MessageBox(fmt.Sprintf("Invalid FX selection: %v", err), title)
+			return
+		}
+		adjust(picks)
+		return
+	}
+
+	if len(tracks) == 0 {
+		core.HandleError(title, core.NewError(core.CategoryUser, "Select the tracks whose FX to change.", nil))
+		return
+	}
+	adjust(trackFXPicks(tracks))
+}
+
+// adjustParamsByName applies change to every parameter of the picked FX whose name
+// contains name, after confirming, as one undo point
+func adjustParamsByName(title, name string, change reaper.ParameterChange, picks []fxPick) {
+	filter := strings.ToLower(strings.TrimSpace(name))
+
+	// STEP 3: Find the matching parameters
+	var changes []reaper.ParameterChange
+	var matches []string
+	for _, pick := range picks {
+		parameters, err := reaper.BatchGetFXParameters(pick.Track, pick.FXIndex)
+		if err != nil {
+			logger.Warning("Could not read parameters of %s: %v", pick.label(), err)
+			continue
+		}
+		for _, param := range parameters {
+			if !strings.Contains(strings.ToLower(param.Name), filter) {
+				continue
+			}
+			c := change
+			c.Track, c.FXIndex, c.ParamIndex = pick.Track, pick.FXIndex, param.Index
+			changes = append(changes, c)
+			matches = append(matches, fmt.Sprintf("%s • %s", pick.label(), param.Name))
+		}
+	}
+
+	if len(changes) == 0 {
+		reaper.MessageBox(fmt.Sprintf("No parameter names of the chosen FX contain %q.", name), title)
+		return
+	}
+
+	// STEP 4: Confirm
+	listed := matches
+	if len(listed) > maxListedMatches {
+		listed = append(listed[:maxListedMatches:maxListedMatches], fmt.Sprintf("... and %d more", len(matches)-maxListedMatches))
+	}
+	confirm := fmt.Sprintf("Change %d parameter(s): %s?\n\n- %s", len(changes), describeParameterOp(change), strings.Join(listed, "\n- "))
+	proceed, err := reaper.YesNoBox(confirm, title)
+	if err != nil || !proceed {
+		return
+	}
+
+	// STEP 5: Change them in one call and one undo point
+	if err := reaper.UndoBeginBlock(); err != nil {
+		logger.Warning("Failed to start undo block: %v", err)
+	} else {
+		defer func() {
+			description := fmt.Sprintf("Adjust %d %q parameter(s)", len(changes), name)
+			if err := reaper.UndoEndBlock(description, reaper.UndoStateFX); err != nil {
+				logger.Warning("Failed to end undo block: %v", err)
+			}
+		}()
+	}
+
+	failed, err := reaper.BatchSetParameters(changes)
+	if err != nil {
+		core.HandleError(title, core.NewError(core.CategoryReaper, "Could not change the parameters.", err))
+		return
+	}
+
+	logger.Info("Adjusted %d %q parameters, %d failed", len(changes)-len(failed), filter, len(failed))
+	if len(failed) > 0 {
+		reaper.MessageBox(fmt.Sprintf("Changed %d parameter(s); %d could not be set.", len(changes)-len(failed), len(failed)), title)
+	}
+}
+
+// describeParameterOp describes a change for the confirmation, e.g. "multiply by 0.9"
+func describeParameterOp(change reaper.ParameterChange) string {
+	switch change.Op {
+	case reaper.ParamAdd:
+		return fmt.Sprintf("add %g", change.Value)
+	case reaper.ParamMultiply:
+		return fmt.Sprintf("multiply by %g", change.Value)
+	case reaper.ParamClamp:
+		return fmt.Sprintf("limit to %g-%g", change.Min, change.Max)
+	default:
+		return fmt.Sprintf("set to %g", change.Value)
+	}
+}
@@ -0,0 +1,99 @@
+package actions
+
+import (
+	"fmt"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"unsafe"
+)
+
+// This file implements continuous actions that adjust FX parameters from MIDI CC or OSC.
+// Bound to an endless encoder they step the parameter (REAPER's relative CC modes); bound
+// to a fader or knob they follow its position.
+
+// fxParamControlSlots is how many "parameter N of the focused FX" actions are declared
+const fxParamControlSlots = 8
+
+// relativeParamStep is how far one relative encoder step moves a parameter, as a
+// fraction of its range
+const relativeParamStep = 0.01
+
+// The parameter control actions. REAPER offers MIDI CC/OSC learning for actions whose
+// names say they accept it.
+func init() {
+	registerAction(Action{
+		ID:             "GO_FX_PARAM_LAST_TOUCHED",
+		Name:           "Go: Adjust last touched FX parameter (MIDI CC/OSC only)",
+		ContextHandler: adjustLastTouchedParam,
+	})
+
+	for slot := 1; slot <= fxParamControlSlots; slot++ {
+		paramIndex := slot - 1
+		registerAction(Action{
+			ID:   fmt.Sprintf("GO_FX_PARAM_FOCUSED_%d", slot),
+			Name: fmt.Sprintf("Go: Adjust parameter %d of focused FX (MIDI CC/OSC only)", slot),
+			ContextHandler: func(ctx reaper.ActionContext) {
+				adjustFocusedParam(ctx, paramIndex)
+			},
+		})
+	}
+}
+
+// adjustLastTouchedParam moves the parameter that was last changed
+func adjustLastTouchedParam(ctx reaper.ActionContext) {
+	track, fxIndex, paramIndex, err := reaper.GetLastTouchedFX()
+	if err != nil {
+		logger.Debug("No parameter to adjust: %v", err)
+		return
+	}
+
+	adjustParam(ctx, track, fxIndex, paramIndex)
+}
+
+// adjustFocusedParam moves a parameter of the FX whose window last had focus
+func adjustFocusedParam(ctx reaper.ActionContext, paramIndex int) {
+	track, fxIndex, err := reaper.GetFocusedFX()
+	if err != nil {
+		logger.Debug("No FX to adjust: %v", err)
+		return
+	}
+
+	count, err := reaper.GetTrackFXParamCount(track, fxIndex)
+	if err != nil || paramIndex >= count {
+		logger.Debug("Focused FX has no parameter %d", paramIndex+1)
+		return
+	}
+
+	adjustParam(ctx, track, fxIndex, paramIndex)
+}
+
+// adjustParam applies a control's value to a parameter: relative controls step it from
+// its current value, absolute controls set it. Both work in the parameter's range scaled
+// to 0-1.
+func adjustParam(ctx reaper.ActionContext, track unsafe.Pointer, fxIndex, paramIndex int) {
+	current, min, max, err := reaper.GetTrackFXParamValueWithRange(track, fxIndex, paramIndex)
+	if err != nil {
+		logger.Warning("Failed to read parameter %d of FX %d: %v", paramIndex, fxIndex, err)
+		return
+	}
+	if max <= min {
+		return
+	}
+
+	var position float64
+	if ctx.IsRelative() {
+		position = (current-min)/(max-min) + float64(ctx.RelativeDelta())*relativeParamStep
+	} else {
+		position = ctx.AbsoluteValue()
+	}
+
+	if position < 0 {
+		position = 0
+	} else if position > 1 {
+		position = 1
+	}
+
+	if err := reaper.SetTrackFXParamValue(track, fxIndex, paramIndex, min+position*(max-min)); err != nil {
+		logger.Warning("Failed to set parameter %d of FX %d: %v", paramIndex, fxIndex, err)
+	}
+}
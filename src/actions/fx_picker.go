@@ -0,0 +1,337 @@
+package actions
+
+import (
+	"fmt"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"go-reaper/src/ui"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// This file implements the FX picker: a window showing the project's folders, tracks and
+// FX as a tree of checkboxes, for actions that work on FX across tracks. Checking a track
+// checks all of its FX, and checking a folder checks every FX in it. Where there is no
+// native window, the same choice is typed as "track.fx" numbers, see parseFXPicks.
+
+// fxPickerWindowID is the ID the picker is registered under with ui.Windows
+const fxPickerWindowID = "fx-picker"
+
+// fxPick is an FX chosen in the picker
+type fxPick struct {
+	Track     unsafe.Pointer
+	FXIndex   int
+	TrackName string
+	FXName    string
+}
+
+// label describes the pick for lists, e.g. "Vocals • ReaEQ"
+func (p fxPick) label() string {
+	return fmt.Sprintf("%s • %s", p.TrackName, p.FXName)
+}
+
+// pickerRow is an entry in the picker's list: a track, or one of its FX
+type pickerRow struct {
+	node   *reaper.TrackNode
+	fx     int    // FX index, or -1 for the track's own row
+	fxGUID string // Finds the FX again if the chain changes while the picker is open
+	fxName string
+}
+
+// fxPicker holds an open picker. It is only touched on the main thread.
+type fxPicker struct {
+	window *ui.Window
+	list   *ui.Widget
+	status *ui.Widget
+	rows   []pickerRow
+	done   func([]fxPick)
+	closed bool
+}
+
+// openPicker is the picker on screen, if any
+var openPicker *fxPicker
+
+// showFXPicker opens the picker with every FX of the project's tracks, checking those
+// for which checked returns true, and calls done with the checked FX when the user
+// confirms. Closing the picker cancels without calling done. An open picker is replaced.
+func showFXPicker(title string, checked func(track unsafe.Pointer) bool, done func([]fxPick)) error {
+	roots, err := reaper.GetTrackTree()
+	if err != nil {
+		return err
+	}
+
+	if openPicker != nil {
+		openPicker.close()
+	}
+
+	window, err := ui.NewWindow(fxPickerWindowID, title, 420, 460)
+	if err != nil {
+		return err
+	}
+	p := &fxPicker{window: window, done: done}
+	if err := p.build(); err != nil {
+		window.Close()
+		return err
+	}
+	if err := ui.Windows.OnClose(fxPickerWindowID, func() { p.closed = true }); err != nil {
+		logger.Warning("Failed to observe FX picker closing: %v", err)
+	}
+	openPicker = p
+
+	p.fill(roots, checked)
+	return window.Show()
+}
+
+// build adds the picker's widgets
+func (p *fxPicker) build() error {
+	w := p.window
+	var err error
+
+	if _, err = w.AddLabel(ui.Rect{X: 12, Y: 12, Width: 396, Height: 20}, "Check FX, or a track or folder for all of its FX:"); err != nil {
+		return err
+	}
+	if p.list, err = w.AddChecklist(ui.Rect{X: 12, Y: 38, Width: 396, Height: 340}, nil, p.toggled); err != nil {
+		return err
+	}
+	if p.status, err = w.AddLabel(ui.Rect{X: 12, Y: 386, Width: 396, Height: 20}, ""); err != nil {
+		return err
+	}
+	if _, err = w.AddButton(ui.Rect{X: 12, Y: 416, Width: 70, Height: 28}, "All", func() { p.setAll(true) }); err != nil {
+		return err
+	}
+	if _, err = w.AddButton(ui.Rect{X: 88, Y: 416, Width: 70, Height: 28}, "None", func() { p.setAll(false) }); err != nil {
+		return err
+	}
+	cancel, err := w.AddButton(ui.Rect{X: 236, Y: 416, Width: 80, Height: 28}, "Cancel", p.close)
+	if err != nil {
+		return err
+	}
+	ok, err := w.AddButton(ui.Rect{X: 324, Y: 416, Width: 84, Height: 28}, "OK", p.confirm)
+	if err != nil {
+		return err
+	}
+
+	if err := p.list.SetAccessibilityLabel("FX to include"); err != nil {
+		return err
+	}
+	if err := w.SetDefaultButton(ok); err != nil {
+		return err
+	}
+	return w.SetCancelButton(cancel)
+}
+
+// fill lists every track with FX, and the folders they are in, with their FX below them
+func (p *fxPicker) fill(roots []*reaper.TrackNode, checked func(track unsafe.Pointer) bool) {
+	var items []string
+	reaper.WalkTrackTree(roots, func(node *reaper.TrackNode) {
+		if node.FXCount() == 0 {
+			return
+		}
+		indent := strings.Repeat("    ", node.Depth)
+		items = append(items, fmt.Sprintf("%s%d. %s", indent, node.Index, node.Name))
+		p.rows = append(p.rows, pickerRow{node: node, fx: -1})
+
+		if node.NumFX == 0 {
+			return
+		}
+		summaries, err := reaper.GetTrackFXSummaries(node.MediaTrack)
+		if err != nil {
+			logger.Warning("Could not read FX on %s: %v", node.Name, err)
+			return
+		}
+		for _, fx := range summaries {
+			guid, _ := reaper.GetTrackFXGUID(node.MediaTrack, fx.Index)
+			items = append(items, fmt.Sprintf("%s      %d.%d %s", indent, node.Index, fx.Index+1, fx.Name))
+			p.rows = append(p.rows, pickerRow{node: node, fx: fx.Index, fxGUID: guid, fxName: fx.Name})
+		}
+	})
+
+	p.list.SetItems(items)
+	for i, row := range p.rows {
+		if row.fx >= 0 && checked != nil && checked(row.node.MediaTrack) {
+			p.list.SetItemChecked(i, true)
+		}
+	}
+	p.syncTrackRows()
+}
+
+// toggled checks or unchecks all FX under a track or folder row
+func (p *fxPicker) toggled(index int, checked bool) {
+	if index < 0 || index >= len(p.rows) {
+		return
+	}
+	if row := p.rows[index]; row.fx < 0 {
+		p.setUnder(row.node, checked)
+	}
+	p.syncTrackRows()
+}
+
+// setUnder checks or unchecks every FX of a track and the tracks under it
+func (p *fxPicker) setUnder(node *reaper.TrackNode, checked bool) {
+	tracks := make(map[unsafe.Pointer]bool)
+	for _, track := range node.Tracks() {
+		tracks[track] = true
+	}
+	for i, row := range p.rows {
+		if row.fx >= 0 && tracks[row.node.MediaTrack] {
+			p.list.SetItemChecked(i, checked)
+		}
+	}
+}
+
+// setAll checks or unchecks every FX
+func (p *fxPicker) setAll(checked bool) {
+	for i, row := range p.rows {
+		if row.fx >= 0 {
+			p.list.SetItemChecked(i, checked)
+		}
+	}
+	p.syncTrackRows()
+}
+
+// syncTrackRows checks each track and folder row whose FX are all checked, and counts
+// the checked FX
+func (p *fxPicker) syncTrackRows() {
+	total, count := 0, 0
+	all := make(map[*reaper.TrackNode]bool)
+	for i, row := range p.rows {
+		if row.fx < 0 {
+			continue
+		}
+		total++
+		checked := p.list.ItemChecked(i)
+		if checked {
+			count++
+		}
+		for node := row.node; node != nil; node = node.Parent {
+			if seen, ok := all[node]; !ok || seen {
+				all[node] = checked
+			}
+		}
+	}
+	for i, row := range p.rows {
+		if row.fx < 0 {
+			p.list.SetItemChecked(i, all[row.node])
+		}
+	}
+	p.status.SetText(fmt.Sprintf("%d of %d FX checked", count, total))
+}
+
+// confirm calls done with the checked FX, found again by GUID, and closes the picker
+func (p *fxPicker) confirm() {
+	var picks []fxPick
+	for i, row := range p.rows {
+		if row.fx < 0 || !p.list.ItemChecked(i) {
+			continue
+		}
+		track := row.node.MediaTrack
+		if !reaper.IsTrackValid(track) {
+			continue
+		}
+		index, err := reaper.FindTrackFXByGUID(track, row.fxGUID)
+		if err != nil {
+			logger.Warning("Skipping %s on %s: %v", row.fxName, row.node.Name, err)
+			continue
+		}
+		picks = append(picks, fxPick{Track: track, FXIndex: index, TrackName: row.node.Name, FXName: row.fxName})
+	}
+	if len(picks) == 0 {
+		p.status.SetText("Check at least one FX")
+		return
+	}
+
+	done := p.done
+	p.close()
+	done(picks)
+}
+
+// close closes the picker without choosing
+func (p *fxPicker) close() {
+	if openPicker == p {
+		openPicker = nil
+	}
+	if !p.closed {
+		p.closed = true
+		p.window.Close()
+	}
+}
+
+// parseFXPicks parses a typed FX choice: comma-separated "track.fx" numbers as shown in
+// REAPER, e.g. "1.1, 1.3", where "2.*" is every FX on track 2 and, for a folder, on the
+// tracks in it
+func parseFXPicks(input string, roots []*reaper.TrackNode) ([]fxPick, error) {
+	tracks := make(map[int]*reaper.TrackNode)
+	reaper.WalkTrackTree(roots, func(node *reaper.TrackNode) {
+		tracks[node.Index] = node
+	})
+
+	var picks []fxPick
+	seen := make(map[string]bool)
+	add := func(node *reaper.TrackNode, fxIndex int, fxName string) {
+		key := fmt.Sprintf("%d.%d", node.Index, fxIndex)
+		if !seen[key] {
+			seen[key] = true
+			picks = append(picks, fxPick{Track: node.MediaTrack, FXIndex: fxIndex, TrackName: node.Name, FXName: fxName})
+		}
+	}
+
+	for _, part := range strings.Split(input, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		trackPart, fxPart, ok := strings.Cut(part, ".")
+		trackNumber, err := strconv.Atoi(strings.TrimSpace(trackPart))
+		if !ok || err != nil {
+			return nil, fmt.Errorf("invalid FX %q; use track.fx numbers, e.g. 1.2", part)
+		}
+		node, found := tracks[trackNumber]
+		if !found {
+			return nil, fmt.Errorf("there is no track %d", trackNumber)
+		}
+
+		if strings.TrimSpace(fxPart) == "*" {
+			node.Walk(func(n *reaper.TrackNode) {
+				summaries, err := reaper.GetTrackFXSummaries(n.MediaTrack)
+				if err != nil {
+					return
+				}
+				for _, fx := range summaries {
+					add(n, fx.Index, fx.Name)
+				}
+			})
+			continue
+		}
+
+		fxNumber, err := strconv.Atoi(strings.TrimSpace(fxPart))
+		if err != nil || fxNumber < 1 || fxNumber > node.NumFX {
+			return nil, fmt.Errorf("track %d has no FX %s", trackNumber, strings.TrimSpace(fxPart))
+		}
+		fxName, _ := reaper.GetTrackFXName(node.MediaTrack, fxNumber-1)
+		add(node, fxNumber-1, fxName)
+	}
+
+	if len(picks) == 0 {
+		return nil, fmt.Errorf("no FX selected")
+	}
+	return picks, nil
+}
+
+// trackFXPicks returns every FX on the given tracks
+func trackFXPicks(tracks []unsafe.Pointer) []fxPick {
+	var picks []fxPick
+	for _, track := range tracks {
+		trackName, _ := reaper.GetTrackName(track)
+		summaries, err := reaper.GetTrackFXSummaries(track)
+		if err != nil {
+			logger.Warning("Could not read FX on %s: %v", trackName, err)
+			continue
+		}
+		for _, fx := range summaries {
+			picks = append(picks, fxPick{Track: track, FXIndex: fx.Index, TrackName: trackName, FXName: fx.Name})
+		}
+	}
+	return picks
+}
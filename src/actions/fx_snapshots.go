@@ -0,0 +1,280 @@
+package actions
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-reaper/src/core"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// This file implements named FX snapshots: the parameter values of every FX on some
+// tracks, saved in the project so they travel with it, and the action that manages them.
+
+// snapshotSection is the project ext state section snapshots are stored in, one key each
+const snapshotSection = "GoReaperSnapshots"
+
+// namedSnapshot is a snapshot saved in the project
+type namedSnapshot struct {
+	ID       string            `json:"-"` // Project ext state key
+	Name     string            `json:"name"`
+	Created  time.Time         `json:"created"`
+	Snapshot reaper.FXSnapshot `json:"snapshot"`
+}
+
+// summary describes the snapshot in one line for lists
+func (s namedSnapshot) summary() string {
+	return fmt.Sprintf("%s (%s)", s.Name, s.Created.Local().Format("2006-01-02 15:04"))
+}
+
+// details lists the snapshot's tracks and FX
+func (s namedSnapshot) details() string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("%s\nSaved %s, %d track(s), %d FX\n",
+		s.Name, s.Created.Local().Format("2006-01-02 15:04"), len(s.Snapshot.Tracks), s.Snapshot.FXCount()))
+
+	for _, track := range s.Snapshot.Tracks {
+		names := make([]string, len(track.FX))
+		for i, fx := range track.FX {
+			names[i] = fx.Name
+		}
+		if len(names) == 0 {
+			names = []string{"no FX"}
+		}
+		builder.WriteString(fmt.Sprintf("\n%s: %s", track.Name, strings.Join(names, ", ")))
+	}
+	return builder.String()
+}
+
+// listSnapshots returns the snapshots saved in the current project, oldest first
+func listSnapshots() ([]namedSnapshot, error) {
+	keys, err := reaper.GetProjExtStateKeys(snapshotSection)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := make([]namedSnapshot, 0, len(keys))
+	for _, key := range keys {
+		snapshot, err := loadSnapshot(key)
+		if err != nil {
+			logger.Warning("Skipping FX snapshot %s: %v", key, err)
+			continue
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return snapshots[i].Created.Before(snapshots[j].Created)
+	})
+	return snapshots, nil
+}
+
+// loadSnapshot reads one snapshot from the project
+func loadSnapshot(id string) (namedSnapshot, error) {
+	data, err := reaper.GetProjExtState(snapshotSection, id)
+	if err != nil {
+		return namedSnapshot{}, err
+	}
+	if data == "" {
+		return namedSnapshot{}, fmt.Errorf("the snapshot no longer exists")
+	}
+
+	var snapshot namedSnapshot
+	if err := json.Unmarshal([]byte(data), &snapshot); err != nil {
+		return namedSnapshot{}, fmt.Errorf("invalid snapshot data: %v", err)
+	}
+	snapshot.ID = id
+	return snapshot, nil
+}
+
+// storeSnapshot writes a snapshot to the project under its ID
+func storeSnapshot(snapshot namedSnapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to encode the snapshot: %v", err)
+	}
+	return reaper.SetProjExtState(snapshotSection, snapshot.ID, string(data))
+}
+
+// takeSnapshot captures the FX of the selected tracks and saves them under name
+func takeSnapshot(name string) (namedSnapshot, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return namedSnapshot{}, fmt.Errorf("the snapshot needs a name")
+	}
+
+	tracks, err := reaper.GetSelectedTracks()
+	if err != nil {
+		return namedSnapshot{}, err
+	}
+	if len(tracks) == 0 {
+		return namedSnapshot{}, fmt.Errorf("select the tracks to snapshot first")
+	}
+
+	captured, err := reaper.CaptureFXSnapshot(tracks)
+	if err != nil {
+		return namedSnapshot{}, err
+	}
+
+	now := time.Now()
+	snapshot := namedSnapshot{
+		ID:       strconv.FormatInt(now.UnixNano(), 36),
+		Name:     name,
+		Created:  now,
+		Snapshot: captured,
+	}
+	if err := storeSnapshot(snapshot); err != nil {
+		return namedSnapshot{}, err
+	}
+
+	logger.Info("Saved FX snapshot %q: %d track(s), %d FX", name, len(captured.Tracks), captured.FXCount())
+	return snapshot, nil
+}
+
+// renameSnapshot gives a saved snapshot a new name
+func renameSnapshot(id, name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("the snapshot needs a name")
+	}
+
+	snapshot, err := loadSnapshot(id)
+	if err != nil {
+		return err
+	}
+	snapshot.Name = name
+	return storeSnapshot(snapshot)
+}
+
+// deleteSnapshot removes a snapshot from the project
+func deleteSnapshot(id string) error {
+	return reaper.DeleteProjExtState(snapshotSection, id)
+}
+
+// applySnapshot writes a snapshot's values back as one undo point
+func applySnapshot(snapshot namedSnapshot) (applied int, skipped []string) {
+	if err := reaper.UndoBeginBlock(); err != nil {
+		logger.Warning("Failed to start undo block: %v", err)
+	} else {
+		defer func() {
+			if err := reaper.UndoEndBlock(fmt.Sprintf("Apply FX snapshot %q", snapshot.Name), reaper.UndoStateFX); err != nil {
+				logger.Warning("Failed to end undo block: %v", err)
+			}
+		}()
+	}
+
+	applied, skipped = snapshot.Snapshot.Apply()
+	for _, reason := range skipped {
+		logger.Warning("FX snapshot %q: skipped %s", snapshot.Name, reason)
+	}
+	logger.Info("Applied FX snapshot %q to %d FX", snapshot.Name, applied)
+	return applied, skipped
+}
+
+// appliedNote describes the result of applying a snapshot
+func appliedNote(snapshot namedSnapshot, applied int, skipped []string) string {
+	note := fmt.Sprintf("Applied %q to %d FX", snapshot.Name, applied)
+	if len(skipped) > 0 {
+		note += fmt.Sprintf(", skipped %d (%s)", len(skipped), strings.Join(skipped, "; "))
+	}
+	return note
+}
+
+// The FX snapshot manager action
+func init() {
+	registerAction(Action{
+		ID:      "GO_FX_SNAPSHOTS",
+		Name:    "Go: FX Snapshots",
+		Handler: handleFXSnapshots,
+	})
+}
+
+// handleFXSnapshots opens the snapshot manager window, or manages snapshots with
+// dialogs where there is no native window
+func handleFXSnapshots() {
+	if runtime.GOOS == "darwin" {
+		err := showSnapshotManager()
+		if err == nil {
+			return
+		}
+		logger.Warning("Failed to open snapshot manager, falling back to dialogs: %v", err)
+	}
+
+	manageSnapshotsWithDialogs()
+}
+
+// manageSnapshotsWithDialogs lists the project's snapshots and saves, applies, renames
+// or deletes one
+func manageSnapshotsWithDialogs() {
+	const title = "FX Snapshots"
+
+	// STEP 1: List the snapshots
+	snapshots, err := listSnapshots()
+	if err != nil {
+		core.HandleError(title, core.NewError(core.CategoryReaper, "Could not read the project's FX snapshots.", err))
+		return
+	}
+
+	var builder strings.Builder
+	if len(snapshots) == 0 {
+		builder.WriteString("This project has no FX snapshots yet.\n")
+	}
+	for i, snapshot := range snapshots {
+		builder.WriteString(fmt.Sprintf("%d. %s, %d track(s), %d FX\n", i+1, snapshot.summary(), len(snapshot.Snapshot.Tracks), snapshot.Snapshot.FXCount()))
+	}
+	builder.WriteString("\nActions: save (the selected tracks), apply, rename, delete.")
+	reaper.MessageBox(builder.String(), title)
+
+	// STEP 2: Ask what to do
+	values, err := reaper.GetUserInputs(title,
+		[]string{"Action (save/apply/rename/delete)", "Snapshot number", "Name (save/rename)"},
+		[]string{"save", "", ""})
+	if err != nil {
+		logger.Debug("Snapshot management cancelled")
+		return
+	}
+	action := strings.ToLower(strings.TrimSpace(values[0]))
+
+	if action == "save" {
+		snapshot, err := takeSnapshot(values[2])
+		if err != nil {
+			reaper.MessageBox(fmt.Sprintf("The snapshot was not saved: %v", err), title)
+			return
+		}
+		reaper.MessageBox(fmt.Sprintf("Saved %q with %d FX.", snapshot.Name, snapshot.Snapshot.FXCount()), title)
+		return
+	}
+
+	number, err := strconv.Atoi(strings.TrimSpace(values[1]))
+	if err != nil || number < 1 || number > len(snapshots) {
+		reaper.MessageBox(fmt.Sprintf("Invalid snapshot number: %s", values[1]), title)
+		return
+	}
+	snapshot := snapshots[number-1]
+
+	// STEP 3: Carry it out
+	switch action {
+	case "apply":
+		applied, skipped := applySnapshot(snapshot)
+		reaper.MessageBox(appliedNote(snapshot, applied, skipped)+".", title)
+	case "rename":
+		if err := renameSnapshot(snapshot.ID, values[2]); err != nil {
+			reaper.MessageBox(fmt.Sprintf("The snapshot was not renamed: %v", err), title)
+		}
+	case "delete":
+		proceed, err := reaper.YesNoBox(fmt.Sprintf("Delete the snapshot %q?", snapshot.Name), title)
+		if err != nil || !proceed {
+			return
+		}
+		if err := deleteSnapshot(snapshot.ID); err != nil {
+			core.HandleError(title, core.NewError(core.CategoryReaper, "Could not delete the snapshot.", err))
+		}
+	default:
+		reaper.MessageBox(fmt.Sprintf("Unknown action %q. Use save, apply, rename or delete.", values[0]), title)
+	}
+}
@@ -0,0 +1,235 @@
+package actions
+
+import (
+	"fmt"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// Item changes are quick varispeed and pitch experiments on the selected items of the
+// assistant's track: "pitch the item down 2 semitones" or "slow it down like tape". They
+// are offered when items on the track are selected, listed in the prompt by number.
+
+// ItemChange is a suggested change to how one of the listed items plays back
+type ItemChange struct {
+	Item          int      `json:"item" desc:"Number of the item as listed in the prompt"`
+	PitchChange   *float64 `json:"pitch_change,omitempty" desc:"Semitones to shift the pitch by, relative to its current pitch, e.g. -2"`
+	PlayRate      *float64 `json:"playrate,omitempty" desc:"New play rate, 1 for normal speed"`
+	PreservePitch *bool    `json:"preserve_pitch,omitempty" desc:"Whether a play rate change keeps the pitch"`
+	Explanation   string   `json:"explanation"`
+
+	take reaper.TakeInfo // The listed item's take, set by resolveItemChanges
+}
+
+// playback works out the take's new playback from the change
+func (c ItemChange) playback() reaper.TakePlayback {
+	playback := reaper.TakePlayback{PlayRate: c.PlayRate, PreservePitch: c.PreservePitch}
+	if c.PitchChange != nil {
+		// Relative to the pitch when the request was made, so applying twice doesn't add up
+		pitch := c.take.Pitch + *c.PitchChange
+		playback.Pitch = &pitch
+	}
+	return playback
+}
+
+// label describes the change for the change list, e.g. "Item 1 (Vox) • pitch -2 st"
+func (c ItemChange) label() string {
+	var parts []string
+	if c.PitchChange != nil {
+		parts = append(parts, fmt.Sprintf("pitch %+g st", *c.PitchChange))
+	}
+	if c.PlayRate != nil {
+		parts = append(parts, fmt.Sprintf("rate %.3g", *c.PlayRate))
+	}
+	if c.PreservePitch != nil {
+		if *c.PreservePitch {
+			parts = append(parts, "preserve pitch on")
+		} else {
+			parts = append(parts, "preserve pitch off")
+		}
+	}
+	if len(parts) == 0 {
+		parts = append(parts, "no change")
+	}
+
+	item := fmt.Sprintf("Item %d", c.Item)
+	if c.take.Name != "" {
+		item += " (" + c.take.Name + ")"
+	}
+	return item + " • " + strings.Join(parts, ", ")
+}
+
+// trackTakes returns the active takes of the selected items on a track
+func trackTakes(track unsafe.Pointer) []reaper.TakeInfo {
+	takes, err := reaper.GetSelectedTakes()
+	if err != nil {
+		logger.Warning("Could not read the selected items: %v", err)
+		return nil
+	}
+
+	var onTrack []reaper.TakeInfo
+	for _, take := range takes {
+		if take.Track == track {
+			onTrack = append(onTrack, take)
+		}
+	}
+	return onTrack
+}
+
+// buildItemPrompt lists the selected items with how they play back, with instructions
+// for item changes. It returns "" when no items are selected.
+func buildItemPrompt(takes []reaper.TakeInfo) string {
+	if len(takes) == 0 {
+		return ""
+	}
+
+	var builder strings.Builder
+	builder.WriteString("Selected items on the track:\n")
+	for i, take := range takes {
+		builder.WriteString(fmt.Sprintf("  %d: %s at %.2fs, %.2fs long, play rate %.3g, pitch %+g semitones, preserve pitch %s\n",
+			i+1, take.Name, take.Position, take.Length, take.PlayRate, take.Pitch, onOff(take.PreservePitch)))
+	}
+
+	builder.WriteString("\nIf the request is about the items' pitch or speed, you may suggest item changes in an \"item_changes\" array. ")
+	builder.WriteString("Each entry has \"item\" (the number listed above), and any of \"pitch_change\" (semitones to shift by, e.g. -2 for two semitones down), ")
+	builder.WriteString("\"playrate\" (the new play rate) and \"preserve_pitch\", plus \"explanation\". ")
+	builder.WriteString("For a tape-style varispeed, set \"playrate\" with \"preserve_pitch\" false; each semitone is a factor of 2^(1/12).\n\n")
+
+	return builder.String()
+}
+
+// onOff describes a flag for prompts
+func onOff(on bool) string {
+	if on {
+		return "on"
+	}
+	return "off"
+}
+
+// resolveItemChanges ties a response's item changes to the takes listed in the prompt,
+// dropping changes for items that weren't listed
+func resolveItemChanges(response *AssistantResponse, takes []reaper.TakeInfo) {
+	var resolved []ItemChange
+	for _, change := range response.ItemChanges {
+		if change.Item < 1 || change.Item > len(takes) {
+			logger.Warning("Ignoring a change to item %d; %d item(s) were listed", change.Item, len(takes))
+			continue
+		}
+		change.take = takes[change.Item-1]
+		resolved = append(resolved, change)
+	}
+	response.ItemChanges = resolved
+}
+
+// formatItemChanges formats item changes for the confirmation dialog
+func formatItemChanges(changes []ItemChange) string {
+	var builder strings.Builder
+	builder.WriteString("\nItem Changes:\n")
+
+	for _, change := range changes {
+		builder.WriteString("  • " + change.label() + "\n")
+		if change.Explanation != "" {
+			builder.WriteString("    " + change.Explanation + "\n")
+		}
+	}
+
+	return builder.String()
+}
+
+// checkItemChanges keeps the item changes whose take is still in the project, describing
+// the rest in skipped
+func checkItemChanges(changes []ItemChange) ([]ItemChange, []string) {
+	var kept []ItemChange
+	var skipped []string
+	for _, change := range changes {
+		if !reaper.IsTakeValid(change.take.Take) {
+			skipped = append(skipped, fmt.Sprintf("item %d: no longer in the project", change.Item))
+			continue
+		}
+		kept = append(kept, change)
+	}
+	return kept, skipped
+}
+
+// applyItemChanges sets the items' playback. The items are read again first, since their
+// length may have changed since the request.
+func applyItemChanges(changes []ItemChange) error {
+	for _, change := range changes {
+		if !reaper.IsTakeValid(change.take.Take) {
+			return fmt.Errorf("item %d is no longer in the project", change.Item)
+		}
+		current, err := reaper.GetTakeInfo(change.take.Item)
+		if err != nil {
+			return fmt.Errorf("failed to read item %d: %v", change.Item, err)
+		}
+		if current.Take != change.take.Take {
+			return fmt.Errorf("item %d has a different active take than when it was listed", change.Item)
+		}
+
+		if _, err := reaper.SetTakePlayback(current, change.playback()); err != nil {
+			return fmt.Errorf("failed to change item %d: %v", change.Item, err)
+		}
+		logger.Info("Applied: %s - %s", change.label(), change.Explanation)
+	}
+	return nil
+}
+
+// pitchRequestPattern finds pitch moves such as "down 2 semitones" or "up an octave"
+var pitchRequestPattern = regexp.MustCompile(`\b(up|down)\s+(?:by\s+)?(an?|one|\d+(?:\.\d+)?)\s*(semitones?|st|octaves?)\b`)
+
+// suggestHeuristicItemChanges adds item changes for pitch requests to an offline
+// response: "pitch down 2 semitones" shifts the pitch, and asking for varispeed or tape
+// changes the play rate instead, letting the pitch follow
+func suggestHeuristicItemChanges(response *AssistantResponse, takes []reaper.TakeInfo, userRequest string) {
+	if len(takes) == 0 {
+		return
+	}
+
+	request := strings.ToLower(userRequest)
+	match := pitchRequestPattern.FindStringSubmatch(request)
+	if match == nil {
+		return
+	}
+
+	semitones := 1.0
+	if amount, err := strconv.ParseFloat(match[2], 64); err == nil {
+		semitones = amount
+	}
+	if strings.HasPrefix(match[3], "octave") {
+		semitones *= 12
+	}
+	if match[1] == "down" {
+		semitones = -semitones
+	}
+	if semitones == 0 || math.Abs(semitones) > reaper.MaxPitchShift {
+		return
+	}
+
+	varispeed := strings.Contains(request, "varispeed") || strings.Contains(request, "tape")
+	for i, take := range takes {
+		change := ItemChange{Item: i + 1, take: take}
+		if varispeed {
+			rate := take.PlayRate * reaper.SemitonesToRate(semitones)
+			preserve := false
+			change.PlayRate, change.PreservePitch = &rate, &preserve
+			change.Explanation = fmt.Sprintf("Varispeed %+g semitones: the play rate changes and the pitch follows", semitones)
+		} else {
+			shift := semitones
+			change.PitchChange = &shift
+			change.Explanation = fmt.Sprintf("Shift the pitch %+g semitones, keeping the timing", semitones)
+		}
+		response.ItemChanges = append(response.ItemChanges, change)
+	}
+
+	note := fmt.Sprintf("Pitch change of %+g semitones for %d selected item(s).", semitones, len(takes))
+	if len(response.Suggestions) == 0 {
+		response.Reasoning = note
+	} else {
+		response.Reasoning += " " + note
+	}
+}
@@ -0,0 +1,135 @@
+package actions
+
+import (
+	"errors"
+	"fmt"
+	"go-reaper/src/core"
+	"go-reaper/src/pkg/config"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"strings"
+)
+
+// This file lets users choose where API keys are stored: the system keyring, the
+// encrypted key file, or the keyring with the file as a fallback, and what the file is
+// encrypted with.
+
+// The key storage action
+func init() {
+	registerAction(Action{
+		ID:      "GO_KEY_STORAGE",
+		Feature: config.FeatureAssistant,
+		Name:    "Go: API key storage...",
+		Handler: handleKeyStorage,
+	})
+}
+
+// keyStorageNames are the storage choices as typed in the dialog
+var keyStorageNames = map[string]string{
+	"auto":    config.KeyStorageAuto,
+	"keyring": config.KeyStorageKeyring,
+	"file":    config.KeyStorageFile,
+}
+
+// keyFileSecretNames are the key file secrets as typed in the dialog
+var keyFileSecretNames = map[string]string{
+	"machine":    config.KeyFileSecretMachine,
+	"passphrase": config.KeyFileSecretPassphrase,
+}
+
+// nameOf returns the dialog name of a setting value
+func nameOf(names map[string]string, value string) string {
+	for name, v := range names {
+		if v == value {
+			return name
+		}
+	}
+	return value
+}
+
+// handleKeyStorage asks where API keys are stored and what the key file is encrypted with
+func handleKeyStorage() {
+	const title = "API Key Storage"
+
+	// STEP 1: Ask for the storage and the key file's secret
+	storage, secret := config.GetKeyStorage(), config.GetKeyFileSecret()
+	values, err := reaper.GetUserInputs(title,
+		[]string{"Storage (auto, keyring, file)", "Encrypt file with (machine, passphrase)"},
+		[]string{nameOf(keyStorageNames, storage), nameOf(keyFileSecretNames, secret)})
+	if err != nil {
+		logger.Debug("Key storage settings cancelled")
+		return
+	}
+
+	// STEP 2: Check the choices
+	newStorage, storageOK := keyStorageNames[strings.ToLower(strings.TrimSpace(values[0]))]
+	newSecret, secretOK := keyFileSecretNames[strings.ToLower(strings.TrimSpace(values[1]))]
+	if !storageOK || !secretOK {
+		reaper.MessageBox("Enter auto, keyring or file for the storage, and machine or passphrase for the file's encryption.", title)
+		return
+	}
+
+	// STEP 3: Encrypt the key file again if its secret changed; a new passphrase is
+	// asked for, and the old one too if it wasn't given this session
+	if newSecret != secret {
+		if newSecret == config.KeyFileSecretPassphrase || secret == config.KeyFileSecretPassphrase {
+			if err := unlockKeyFile(title); err != nil && !errors.Is(err, config.ErrNoKeyFile) {
+				return
+			}
+		}
+		if newSecret == config.KeyFileSecretPassphrase {
+			if err := askKeyFilePassphrase(title, "New passphrase"); err != nil {
+				return
+			}
+		}
+		if err := config.SetKeyFileSecret(newSecret); err != nil {
+			core.HandleError(title, core.NewError(core.CategoryStorage, "Could not encrypt the API key file with the new secret.", err))
+			return
+		}
+	}
+
+	// STEP 4: Save the storage
+	if err := config.SetKeyStorage(newStorage); err != nil {
+		core.HandleError(title, core.NewError(core.CategoryStorage, "Could not save the key storage setting.", err))
+		return
+	}
+	logger.Info("API keys stored in %s, key file encrypted with %s", values[0], values[1])
+}
+
+// unlockKeyFile makes sure the key file can be decrypted this session, asking for its
+// passphrase if needed. It returns config.ErrNoKeyFile if there is no file.
+func unlockKeyFile(title string) error {
+	err := config.CheckKeyFile()
+	for errors.Is(err, config.ErrPassphraseRequired) || errors.Is(err, config.ErrWrongPassphrase) {
+		label := "Passphrase"
+		if errors.Is(err, config.ErrWrongPassphrase) {
+			label = "Wrong passphrase; try again"
+		}
+		if err := askKeyFilePassphrase(title, label); err != nil {
+			return err
+		}
+		err = config.CheckKeyFile()
+	}
+	if err != nil && !errors.Is(err, config.ErrNoKeyFile) {
+		core.HandleError(title, core.NewError(core.CategoryStorage, "Could not open the API key file.", err))
+	}
+	return err
+}
+
+// askKeyFilePassphrase asks for the key file's passphrase and uses it this session
+func askKeyFilePassphrase(title string, label string) error {
+	form := reaper.InputForm{
+		Title:      title,
+		Fields:     []reaper.InputField{{Label: label, Password: true}},
+		ExtraWidth: 150,
+	}
+	values, err := form.Show()
+	if err != nil {
+		return err
+	}
+	if values[0] == "" {
+		return fmt.Errorf("no passphrase given")
+	}
+	config.SetKeyFilePassphrase(values[0])
+	return nil
+}
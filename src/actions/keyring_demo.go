@@ -2,6 +2,7 @@ package actions
 
 import (
 	"fmt"
+	"go-reaper/src/pkg/config"
 	"go-reaper/src/pkg/logger"
 	"go-reaper/src/reaper"
 	"runtime"
@@ -26,10 +27,15 @@ const (
 	KeyringKeyName     = "APIKey"
 )
 
+// keyringWindowID is the ID the keyring window is registered under in ui.Windows
+const keyringWindowID = "keyring-test"
+
 // Export the function for C to call directly
 //
 //export go_process_keyring_key
 func go_process_keyring_key(keyValue *C.char) {
+	defer reaper.Recover("go_process_keyring_key")
+
 	// Get key as Go string
 	key := C.GoString(keyValue)
 
@@ -55,20 +61,14 @@ func go_process_keyring_key(keyValue *C.char) {
 	updateMessage(success, message)
 }
 
-// RegisterKeyringTest registers the keyring test action
-func RegisterKeyringTest() error {
-	logger.Info("Registering Keyring Test action")
-
-	actionID, err := reaper.RegisterMainAction("GO_KEYRING_TEST", "Go: Keyring Test")
-	if err != nil {
-		logger.Error("Failed to register keyring test action: %v", err)
-		return fmt.Errorf("failed to register keyring test action: %v", err)
-	}
-
-	logger.Info("Keyring Test action registered with ID: %d", actionID)
-
-	reaper.SetActionHandler("GO_KEYRING_TEST", handleKeyringTest)
-	return nil
+// The keyring test action
+func init() {
+	registerAction(Action{
+		ID:      "GO_KEYRING_TEST",
+		Feature: config.FeatureDemos,
+		Name:    "Go: Keyring Test",
+		Handler: handleKeyringTest,
+	})
 }
 
 // handleKeyringTest executes the keyring test action
@@ -82,10 +82,6 @@ func handleKeyringTest() {
 	// Log action triggered
 	logger.Info("Keyring Test action triggered")
 
-	// Lock the current goroutine to the OS thread
-	runtime.LockOSThread()
-	defer runtime.UnlockOSThread()
-
 	// Check if the key exists in keyring
 	key, err := keyring.Get(KeyringServiceName, KeyringKeyName)
 	keyExists := (err == nil && key != "")
@@ -110,6 +106,7 @@ func handleKeyringTest() {
 
 	if bool(result) {
 		logger.Info("Keyring window created/shown successfully")
+		registerWindow(keyringWindowID, CloseKeyringWindow, IsKeyringWindowOpen)
 	} else {
 		logger.Error("Failed to create/show keyring window")
 		reaper.MessageBox("Failed to create/show keyring window. See log for details.", "Keyring Test")
@@ -0,0 +1,67 @@
+package actions
+
+import (
+	"fmt"
+	"go-reaper/src/llm"
+	"go-reaper/src/pkg/config"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// The LLM response cache is a JSON file rather than a SQLite database, like the assistant
+// journal: it holds at most a few hundred entries, loaded once and rewritten whole.
+
+// llmCacheFileName is the cache file inside the extension's data directory
+const llmCacheFileName = "llm_cache.json"
+
+var (
+	responseCache     *llm.ResponseCache
+	responseCacheOnce sync.Once
+)
+
+// The action that clears cached LLM responses
+func init() {
+	registerAction(Action{
+		ID:      "GO_LLM_CLEAR_CACHE",
+		Feature: config.FeatureAssistant,
+		Name:    "Go: Clear LLM Response Cache",
+		Handler: handleClearLLMCache,
+	})
+}
+
+// handleClearLLMCache removes all cached LLM responses
+func handleClearLLMCache() {
+	cache := getResponseCache()
+	count := cache.Len()
+
+	if err := cache.Clear(); err != nil {
+		logger.Error("Failed to clear LLM cache: %v", err)
+		reaper.MessageBox(fmt.Sprintf("Failed to clear the LLM response cache: %v", err), "LLM Response Cache")
+		return
+	}
+
+	logger.Info("Cleared %d cached LLM responses", count)
+	reaper.MessageBox(fmt.Sprintf("Cleared %d cached LLM responses.", count), "LLM Response Cache")
+}
+
+// getResponseCache returns the shared LLM response cache
+func getResponseCache() *llm.ResponseCache {
+	responseCacheOnce.Do(func() {
+		responseCache = llm.NewResponseCache(filepath.Join(extensionDataDir(), llmCacheFileName))
+	})
+	return responseCache
+}
+
+// extensionDataDir returns the directory where the extension keeps its files,
+// inside REAPER's resource path when available
+func extensionDataDir() string {
+	base, err := reaper.GetResourcePath()
+	if err != nil || base == "" {
+		logger.Warning("Could not get REAPER resource path, using temp directory: %v", err)
+		base = os.TempDir()
+	}
+	return filepath.Join(base, "GoReaperExtension")
+}
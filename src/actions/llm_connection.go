@@ -0,0 +1,69 @@
+package actions
+
+import (
+	"fmt"
+	"go-reaper/src/core"
+	"go-reaper/src/llm"
+	"go-reaper/src/pkg/config"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+)
+
+// This file implements the LLM connection test: one tiny request to the active provider
+// that checks the endpoint, the API key and the model before the assistant needs them.
+
+// The connection test action
+func init() {
+	registerAction(Action{
+		ID:      "GO_LLM_TEST_CONNECTION",
+		Feature: config.FeatureAssistant,
+		Name:    "Go: Test LLM connection",
+		Handler: handleTestLLMConnection,
+	})
+}
+
+// handleTestLLMConnection tests the active provider and reports the result
+func handleTestLLMConnection() {
+	const title = "Test LLM Connection"
+
+	provider := config.GetActiveProvider()
+	apiKey, err := getProviderAPIKey(provider)
+	if err != nil {
+		logger.Debug("Connection test cancelled: %v", err)
+		return
+	}
+
+	client, ok := newLLMClient(provider, apiKey).(*llm.OpenAIClient)
+	if !ok {
+		reaper.MessageBox(fmt.Sprintf("Testing the %s provider isn't supported.", providerDisplayName(provider)), title)
+		return
+	}
+
+	// The request runs off the main thread; its usage and result are handled back on it
+	deferUsage(client)
+
+	logger.Info("Testing connection to %s", providerModelName(provider))
+	core.Go("LLM connection test", func() {
+		report, err := client.TestConnection()
+		core.MainThread.CallAsync(func() { showConnectionResult(title, provider, client.Model, report, err) })
+	})
+}
+
+// showConnectionResult reports a connection test
+func showConnectionResult(title string, provider config.Provider, model string, report llm.ConnectionReport, err error) {
+	name := providerDisplayName(provider)
+
+	switch {
+	case err == nil:
+		logger.Info("Connection test of %s succeeded in %v", name, report.Latency)
+		reaper.MessageBox(fmt.Sprintf("Connected to %s.\n\nEndpoint: %s\nModel: %s\nLatency: %d ms",
+			name, report.Endpoint, report.Model, report.Latency.Milliseconds()), title)
+	case llm.IsModelNotFound(err):
+		logger.Warning("Connection test of %s: model %s unavailable: %v", name, model, err)
+		reaper.MessageBox(fmt.Sprintf("%s answered, but the model %q isn't available with this key.\n\nEndpoint: %s\n\n%s",
+			name, model, report.Endpoint, llm.UserMessage(err)), title)
+	default:
+		core.HandleError(title, core.NewError(core.CategoryLLM, fmt.Sprintf("The connection test of %s failed.\n\n%s", name, llm.UserMessage(err)), err).
+			WithDetail("endpoint %s", report.Endpoint))
+	}
+}
@@ -0,0 +1,126 @@
+package actions
+
+import (
+	"fmt"
+	"go-reaper/src/core"
+	"go-reaper/src/llm"
+	"go-reaper/src/pkg/config"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// This file implements the LLM exchange log: with debugging on, every request to a
+// provider and its raw response are written to a separate log file, with the API key
+// and anything else that looks like a credential redacted.
+
+// llmExchangeLogFileName is the exchange log inside the extension's data directory
+const llmExchangeLogFileName = "llm_exchanges.log"
+
+var (
+	exchangeLog     *llm.ExchangeLog
+	exchangeLogOnce sync.Once
+
+	// exchangeLogging mirrors the setting for the toggle state, which REAPER polls
+	exchangeLogging     bool
+	exchangeLoggingOnce sync.Once
+)
+
+// The exchange log actions
+func init() {
+	registerAction(Action{
+		ID:          "GO_LLM_LOG_EXCHANGES",
+		Feature:     config.FeatureAssistant,
+		Name:        "Go: Toggle LLM exchange logging",
+		Handler:     handleToggleLLMExchangeLog,
+		ToggleState: isLoggingLLMExchanges,
+	})
+	registerAction(Action{
+		ID:      "GO_LLM_LAST_EXCHANGE",
+		Feature: config.FeatureAssistant,
+		Name:    "Go: Open Last LLM Exchange",
+		Handler: handleOpenLastLLMExchange,
+	})
+}
+
+// getExchangeLog returns the shared exchange log
+func getExchangeLog() *llm.ExchangeLog {
+	exchangeLogOnce.Do(func() {
+		exchangeLog = llm.NewExchangeLog(filepath.Join(extensionDataDir(), llmExchangeLogFileName))
+	})
+	return exchangeLog
+}
+
+// isLoggingLLMExchanges reports whether exchange logging is on
+func isLoggingLLMExchanges() bool {
+	exchangeLoggingOnce.Do(func() { exchangeLogging = config.GetLogLLMExchanges() })
+	return exchangeLogging
+}
+
+// applyExchangeLogging makes a client record its exchanges while logging is on, with
+// its API key redacted
+func applyExchangeLogging(client *llm.OpenAIClient) {
+	if !isLoggingLLMExchanges() {
+		return
+	}
+	client.HTTPClient = llm.LogExchanges(client.HTTPClient, getExchangeLog(), client.APIKey)
+}
+
+// handleToggleLLMExchangeLog turns exchange logging on or off
+func handleToggleLLMExchangeLog() {
+	enabled := !isLoggingLLMExchanges()
+	if err := config.SetLogLLMExchanges(enabled); err != nil {
+		core.HandleError("LLM Exchange Logging", core.NewError(core.CategoryStorage, "Could not save the logging setting.", err))
+		return
+	}
+	exchangeLogging = enabled
+
+	if enabled {
+		logger.Info("Logging LLM exchanges to %s", getExchangeLog().Path)
+	} else {
+		logger.Info("Stopped logging LLM exchanges")
+	}
+}
+
+// handleOpenLastLLMExchange prints the last exchange to the console: the one recorded
+// this session, or else the last one in the log file
+func handleOpenLastLLMExchange() {
+	const title = "Last LLM Exchange"
+	log := getExchangeLog()
+
+	var text string
+	if exchange, ok := log.Last(); ok {
+		text = exchange.Format()
+	} else {
+		data, err := os.ReadFile(log.Path)
+		if err != nil || len(data) == 0 {
+			message := "No LLM exchange has been logged yet."
+			if !isLoggingLLMExchanges() {
+				message += "\n\nTurn on \"Go: Toggle LLM exchange logging\" and run the assistant again to record one."
+			}
+			reaper.MessageBox(message, title)
+			return
+		}
+		text = lastExchange(string(data))
+	}
+
+	reaper.Console.Section(title)
+	if err := reaper.Console.Print(fmt.Sprintf("%s\nLog file: %s\n", text, log.Path)); err != nil {
+		core.HandleError(title, core.NewError(core.CategoryReaper, "Failed to show the exchange in the console.", err))
+	}
+}
+
+// lastExchange returns the last exchange in the log file's text
+func lastExchange(text string) string {
+	start := strings.LastIndex(text, "=== ")
+	for start > 0 && text[start-1] != '\n' {
+		start = strings.LastIndex(text[:start], "=== ")
+	}
+	if start < 0 {
+		return text
+	}
+	return text[start:]
+}
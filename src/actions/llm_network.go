@@ -0,0 +1,146 @@
+package actions
+
+import (
+	"fmt"
+	"go-reaper/src/core"
+	"go-reaper/src/llm"
+	"go-reaper/src/pkg/config"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// This file implements the network settings for LLM providers: a proxy, the request
+// timeout and extra trusted certificates, for machines behind corporate networks, and
+// how failed requests are retried.
+
+// The LLM network settings action
+func init() {
+	registerAction(Action{
+		ID:      "GO_LLM_NETWORK",
+		Feature: config.FeatureAssistant,
+		Name:    "Go: LLM network settings...",
+		Handler: handleLLMNetwork,
+	})
+}
+
+// httpOptions converts network settings to the llm package's HTTP options
+func httpOptions(network config.NetworkSettings) llm.HTTPOptions {
+	return llm.HTTPOptions{
+		ProxyURL:   network.ProxyURL,
+		Timeout:    time.Duration(network.TimeoutSec) * time.Second,
+		CACertFile: network.CACertFile,
+	}
+}
+
+// applyNetworkSettings gives a client the configured proxy, timeout and certificates.
+// Settings are checked when saved, so one that no longer works, e.g. a deleted
+// certificate file, is logged and the default transport kept.
+func applyNetworkSettings(client *llm.OpenAIClient) {
+	network := config.GetNetwork()
+	if httpOptions(network) == (llm.HTTPOptions{}) {
+		return
+	}
+
+	httpClient, err := llm.NewHTTPClient(httpOptions(network))
+	if err != nil {
+		logger.Error("Ignoring LLM network settings: %v", err)
+		return
+	}
+	client.HTTPClient = httpClient
+}
+
+// newRetryClient wraps a client to retry transient failures as configured
+func newRetryClient(client llm.Client) *llm.RetryClient {
+	retryClient := llm.NewRetryClient(client)
+	network := config.GetNetwork()
+	switch {
+	case network.Retries < 0:
+		retryClient.MaxRetries = 0
+	case network.Retries > 0:
+		retryClient.MaxRetries = network.Retries
+	}
+	if network.MaxBackoffSec > 0 {
+		retryClient.MaxBackoff = time.Duration(network.MaxBackoffSec) * time.Second
+	}
+	return retryClient
+}
+
+// handleLLMNetwork asks for the proxy, timeout, certificate file and retry settings
+func handleLLMNetwork() {
+	const title = "LLM Network Settings"
+
+	// STEP 1: Ask for the settings
+	network := config.GetNetwork()
+	timeout := ""
+	if network.TimeoutSec > 0 {
+		timeout = strconv.Itoa(network.TimeoutSec)
+	}
+	retries := ""
+	switch {
+	case network.Retries < 0:
+		retries = "0"
+	case network.Retries > 0:
+		retries = strconv.Itoa(network.Retries)
+	}
+	maxBackoff := ""
+	if network.MaxBackoffSec > 0 {
+		maxBackoff = strconv.Itoa(network.MaxBackoffSec)
+	}
+	values, err := reaper.GetUserInputs(title,
+		[]string{"Proxy URL (empty: system)", fmt.Sprintf("Timeout seconds (empty: %d)", llm.DefaultTimeoutSec), "CA certificates (PEM file)",
+			fmt.Sprintf("Retries (empty: %d)", llm.DefaultMaxRetries), fmt.Sprintf("Longest retry wait seconds (empty: %d)", int(llm.DefaultMaxBackoff/time.Second))},
+		[]string{network.ProxyURL, timeout, network.CACertFile, retries, maxBackoff})
+	if err != nil {
+		logger.Debug("LLM network settings cancelled")
+		return
+	}
+
+	// STEP 2: Check them by building the client they describe
+	updated := config.NetworkSettings{
+		ProxyURL:   strings.TrimSpace(values[0]),
+		CACertFile: strings.TrimSpace(values[2]),
+	}
+	if text := strings.TrimSpace(values[1]); text != "" {
+		seconds, err := strconv.Atoi(text)
+		if err != nil || seconds < 1 {
+			reaper.MessageBox(fmt.Sprintf("Invalid timeout: %s", values[1]), title)
+			return
+		}
+		updated.TimeoutSec = seconds
+	}
+	if text := strings.TrimSpace(values[3]); text != "" {
+		count, err := strconv.Atoi(text)
+		if err != nil || count < 0 {
+			reaper.MessageBox(fmt.Sprintf("Invalid number of retries: %s", values[3]), title)
+			return
+		}
+		// 0 means the default in the settings, so no retries is stored as -1
+		updated.Retries = count
+		if count == 0 {
+			updated.Retries = -1
+		}
+	}
+	if text := strings.TrimSpace(values[4]); text != "" {
+		seconds, err := strconv.Atoi(text)
+		if err != nil || seconds < 1 {
+			reaper.MessageBox(fmt.Sprintf("Invalid retry wait: %s", values[4]), title)
+			return
+		}
+		updated.MaxBackoffSec = seconds
+	}
+	if _, err := llm.NewHTTPClient(httpOptions(updated)); err != nil {
+		reaper.MessageBox(fmt.Sprintf("The settings were not saved: %v", err), title)
+		return
+	}
+
+	// STEP 3: Save them
+	if err := config.SetNetwork(updated); err != nil {
+		core.HandleError(title, core.NewError(core.CategoryStorage, "Could not save the network settings.", err))
+		return
+	}
+	logger.Info("LLM network settings saved (proxy %q, timeout %ds, CA file %q, retries %d, retry wait %ds)",
+		updated.ProxyURL, updated.TimeoutSec, updated.CACertFile, updated.Retries, updated.MaxBackoffSec)
+}
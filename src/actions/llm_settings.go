@@ -0,0 +1,484 @@
+package actions
+
+import (
+	"fmt"
+	"go-reaper/src/core"
+	"go-reaper/src/llm"
+	"go-reaper/src/pkg/config"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"go-reaper/src/ui"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// This file implements the LLM provider settings: the provider, its endpoint, model and
+// sampling settings. Models are offered from the provider's own list where it can be
+// fetched, and typed by name where it can't.
+
+// llmSettingsWindowID is the ID the settings window is registered under with ui.Windows
+const llmSettingsWindowID = "llm-settings"
+
+// maxListedModels caps the models shown in the dialog fallback's message box
+const maxListedModels = 40
+
+// settingsProviders are the providers in the order they are offered
+var settingsProviders = []config.Provider{config.ProviderOpenAI, config.ProviderGemini, config.ProviderOpenAICompatible}
+
+// providerNames are the providers as typed in the dialog
+var providerNames = map[string]string{
+	"openai":     string(config.ProviderOpenAI),
+	"gemini":     string(config.ProviderGemini),
+	"compatible": string(config.ProviderOpenAICompatible),
+}
+
+// The LLM settings action
+func init() {
+	registerAction(Action{
+		ID:      "GO_LLM_SETTINGS",
+		Feature: config.FeatureAssistant,
+		Name:    "Go: LLM provider settings...",
+		Handler: handleLLMSettings,
+	})
+}
+
+// handleLLMSettings opens the settings window, or edits the settings with dialogs where
+// there is no native window
+func handleLLMSettings() {
+	if runtime.GOOS == "darwin" {
+		err := showLLMSettings()
+		if err == nil {
+			return
+		}
+		logger.Warning("Failed to open LLM settings window, falling back to dialogs: %v", err)
+	}
+
+	editLLMSettingsWithDialogs()
+}
+
+// modelListClient builds a client for listing a provider's models with its stored key
+// and network settings. It reads settings, so call it on the main thread and hand only
+// the client to the goroutine that lists the models.
+func modelListClient(provider config.Provider, baseURL string) (*llm.OpenAIClient, error) {
+	apiKey, err := config.GetSecureAPIKey(provider)
+	if err != nil && provider != config.ProviderOpenAICompatible {
+		return nil, fmt.Errorf("no usable API key stored: %w", err)
+	}
+
+	client := llm.NewOpenAIClient(apiKey)
+	if provider != config.ProviderOpenAI {
+		client = llm.NewOpenAICompatibleClient(baseURL, apiKey, "")
+	}
+	applyNetworkSettings(client)
+	applyExchangeLogging(client)
+	// Listing still running when REAPER unloads the plugin is cancelled
+	client.HTTPClient = llm.CancelWith(client.HTTPClient, core.ShutdownContext())
+	return client, nil
+}
+
+// modelListError describes why the model list is unavailable
+func modelListError(err error) string {
+	return "Could not list the models. " + llm.UserMessage(err)
+}
+
+// llmSettingsWindow holds the settings window's widgets
+type llmSettingsWindow struct {
+	window *ui.Window
+
+	provider    *ui.Widget
+	baseURL     *ui.Widget
+	model       *ui.Widget
+	maxTokens   *ui.Widget
+	temperature *ui.Widget
+	status      *ui.Widget
+
+	mutex      sync.Mutex
+	generation int // Bumped on every model list request, so stale lists are dropped
+}
+
+var (
+	llmSettingsMutex  sync.Mutex
+	llmSettingsDialog *llmSettingsWindow
+)
+
+// showLLMSettings opens the settings window with the active provider, or brings it to
+// the front
+func showLLMSettings() error {
+	llmSettingsMutex.Lock()
+	s := llmSettingsDialog
+	llmSettingsMutex.Unlock()
+
+	if s == nil || !s.window.IsOpen() {
+		window, err := ui.NewWindow(llmSettingsWindowID, "LLM Provider Settings", 480, 250)
+		if err != nil {
+			return err
+		}
+
+		s = &llmSettingsWindow{window: window}
+		if err := s.build(); err != nil {
+			window.Close()
+			return err
+		}
+		if err := ui.Windows.OnClose(llmSettingsWindowID, s.closed); err != nil {
+			logger.Warning("Failed to observe LLM settings closing: %v", err)
+		}
+
+		llmSettingsMutex.Lock()
+		llmSettingsDialog = s
+		llmSettingsMutex.Unlock()
+
+		s.load(config.GetActiveProvider())
+	}
+
+	return s.window.Show()
+}
+
+// build adds the window's widgets
+func (s *llmSettingsWindow) build() error {
+	w := s.window
+
+	names := make([]string, len(settingsProviders))
+	for i, provider := range settingsProviders {
+		names[i] = providerDisplayName(provider)
+	}
+
+	// Widgets are sized to fit their text; the frames only set minimum sizes
+	label := func(text string) (*ui.Widget, error) {
+		return w.AddLabel(ui.Rect{Width: 100, Height: 20}, text)
+	}
+
+	providerLabel, err := label("Provider:")
+	if err != nil {
+		return err
+	}
+	if s.provider, err = w.AddDropdown(ui.Rect{Width: 240, Height: 26}, names, -1, s.providerSelected); err != nil {
+		return err
+	}
+
+	baseURLLabel, err := label("Base URL:")
+	if err != nil {
+		return err
+	}
+	if s.baseURL, err = w.AddTextField(ui.Rect{Width: 240, Height: 24}, "", nil); err != nil {
+		return err
+	}
+
+	modelLabel, err := label("Model:")
+	if err != nil {
+		return err
+	}
+	if s.model, err = w.AddCombo(ui.Rect{Width: 200, Height: 26}, nil, "", nil); err != nil {
+		return err
+	}
+	refresh, err := w.AddButton(ui.Rect{Width: 92, Height: 26}, "Refresh", s.refreshModels)
+	if err != nil {
+		return err
+	}
+
+	maxTokensLabel, err := label("Max tokens:")
+	if err != nil {
+		return err
+	}
+	if s.maxTokens, err = w.AddTextField(ui.Rect{Width: 100, Height: 24}, "", nil); err != nil {
+		return err
+	}
+	temperatureLabel, err := w.AddLabel(ui.Rect{Height: 20}, "Temperature:")
+	if err != nil {
+		return err
+	}
+	if s.temperature, err = w.AddTextField(ui.Rect{Width: 100, Height: 24}, "", nil); err != nil {
+		return err
+	}
+
+	if s.status, err = w.AddLabel(ui.Rect{Height: 40}, ""); err != nil {
+		return err
+	}
+
+	save, err := w.AddButton(ui.Rect{Width: 96, Height: 28}, "Save", s.save)
+	if err != nil {
+		return err
+	}
+	// Close after the click has been handled, so the window outlives its button's action
+	closeLater := func() { ui.RunOnMainThreadAsync(w.Close) }
+	closeButton, err := w.AddButton(ui.Rect{Width: 100, Height: 28}, "Close", closeLater)
+	if err != nil {
+		return err
+	}
+
+	row := func() *ui.Stack { return ui.NewHStack(0, ui.LayoutSpacing) }
+	err = w.SetLayout(ui.NewVStack(ui.LayoutMargin, ui.LayoutSpacing).
+		Add(row().Add(providerLabel).Fill(s.provider)).
+		Add(row().Add(baseURLLabel).Fill(s.baseURL)).
+		Add(row().Add(modelLabel).Fill(s.model).Add(refresh)).
+		Add(row().Add(maxTokensLabel, s.maxTokens).Fill(ui.Spacer()).Add(temperatureLabel, s.temperature)).
+		Fill(s.status).
+		Add(row().Fill(ui.Spacer()).Add(save, closeButton)))
+	if err != nil {
+		return err
+	}
+
+	// Name the fields for VoiceOver, which doesn't see the labels beside them
+	fieldNames := map[*ui.Widget]string{
+		s.provider: "Provider", s.baseURL: "Base URL", s.model: "Model",
+		s.maxTokens: "Max tokens", s.temperature: "Temperature",
+	}
+	for widget, name := range fieldNames {
+		if err := widget.SetAccessibilityLabel(name); err != nil {
+			return err
+		}
+	}
+	if err := w.SetDefaultButton(save); err != nil {
+		return err
+	}
+	return w.SetCancelButton(closeButton)
+}
+
+// setStatus shows a status message
+func (s *llmSettingsWindow) setStatus(format string, args ...interface{}) {
+	if err := s.status.SetText(fmt.Sprintf(format, args...)); err != nil {
+		logger.Warning("Failed to update LLM settings status: %v", err)
+	}
+}
+
+// selectedProvider returns the provider chosen in the dropdown
+func (s *llmSettingsWindow) selectedProvider() config.Provider {
+	index := int(s.provider.Value())
+	if index < 0 || index >= len(settingsProviders) {
+		return config.GetActiveProvider()
+	}
+	return settingsProviders[index]
+}
+
+// providerSelected shows the settings of the newly chosen provider
+func (s *llmSettingsWindow) providerSelected(index int) {
+	if index >= 0 && index < len(settingsProviders) {
+		s.load(settingsProviders[index])
+	}
+}
+
+// load fills the fields with a provider's stored settings and lists its models
+func (s *llmSettingsWindow) load(provider config.Provider) {
+	for i, p := range settingsProviders {
+		if p == provider {
+			s.provider.SetValue(float64(i))
+		}
+	}
+
+	model, maxTokens, temperature := config.GetProviderConfig(provider)
+	s.baseURL.SetText(config.GetProviderBaseURL(provider))
+	s.baseURL.SetEnabled(provider != config.ProviderOpenAI)
+	s.model.SetText(model)
+	s.model.SetItems(nil)
+	s.maxTokens.SetText(strconv.Itoa(maxTokens))
+	s.temperature.SetText(strconv.FormatFloat(temperature, 'f', -1, 64))
+
+	s.refreshModels()
+}
+
+// refreshModels fetches the chosen provider's models in the background and offers them
+// in the model box. The box keeps taking typed names if the list can't be fetched.
+func (s *llmSettingsWindow) refreshModels() {
+	provider := s.selectedProvider()
+	baseURL := strings.TrimSpace(s.baseURL.Text())
+
+	s.mutex.Lock()
+	s.generation++
+	generation := s.generation
+	s.mutex.Unlock()
+
+	client, err := modelListClient(provider, baseURL)
+	if err != nil {
+		s.showModels(nil, err)
+		return
+	}
+
+	s.setStatus("Listing %s models...", providerDisplayName(provider))
+	core.Go("LLM model list", func() {
+		models, err := client.ListModels()
+		core.MainThread.CallAsync(func() {
+			s.mutex.Lock()
+			stale := generation != s.generation
+			s.mutex.Unlock()
+			if stale || !s.window.IsOpen() {
+				return
+			}
+			s.showModels(models, err)
+		})
+	})
+}
+
+// showModels offers a fetched model list, or explains why there is none
+func (s *llmSettingsWindow) showModels(models []string, err error) {
+	if err != nil {
+		logger.Warning("Failed to list models: %v", err)
+		s.setStatus("%s Type the model name instead.", modelListError(err))
+		return
+	}
+	if len(models) == 0 {
+		s.setStatus("The provider listed no models; type the model name instead.")
+		return
+	}
+
+	if err := s.model.SetItems(models); err != nil {
+		logger.Warning("Failed to show model list: %v", err)
+	}
+	s.setStatus("%d models available.", len(models))
+}
+
+// save stores the fields as the chosen provider's settings and makes it the active one
+func (s *llmSettingsWindow) save() {
+	provider := s.selectedProvider()
+	values := llmSettingsValues{
+		BaseURL:     s.baseURL.Text(),
+		Model:       s.model.Text(),
+		MaxTokens:   s.maxTokens.Text(),
+		Temperature: s.temperature.Text(),
+	}
+
+	if err := saveLLMSettings(provider, values); err != nil {
+		s.setStatus("Not saved: %v", err)
+		return
+	}
+	s.setStatus("Saved; %s is the active provider.", providerModelName(provider))
+}
+
+// closed forgets the window once it closes
+func (s *llmSettingsWindow) closed() {
+	llmSettingsMutex.Lock()
+	if llmSettingsDialog == s {
+		llmSettingsDialog = nil
+	}
+	llmSettingsMutex.Unlock()
+}
+
+// llmSettingsValues are the settings as entered, before they are checked
+type llmSettingsValues struct {
+	BaseURL     string
+	Model       string
+	MaxTokens   string
+	Temperature string
+}
+
+// saveLLMSettings checks entered settings and stores them for a provider, making it the
+// active one
+func saveLLMSettings(provider config.Provider, values llmSettingsValues) error {
+	model := strings.TrimSpace(values.Model)
+	if model == "" {
+		return fmt.Errorf("a model is required")
+	}
+	maxTokens, err := strconv.Atoi(strings.TrimSpace(values.MaxTokens))
+	if err != nil || maxTokens < 1 {
+		return fmt.Errorf("max tokens must be a positive whole number")
+	}
+	temperature, err := strconv.ParseFloat(strings.TrimSpace(values.Temperature), 64)
+	if err != nil || temperature < 0 || temperature > 2 {
+		return fmt.Errorf("temperature must be between 0 and 2")
+	}
+
+	if provider != config.ProviderOpenAI {
+		baseURL := strings.TrimSpace(values.BaseURL)
+		if baseURL == "" {
+			return fmt.Errorf("a base URL is required")
+		}
+		if err := config.SetProviderBaseURL(provider, baseURL); err != nil {
+			return err
+		}
+	}
+	if err := config.SetProviderConfig(provider, model, maxTokens, temperature); err != nil {
+		return err
+	}
+	if err := config.SetActiveProvider(provider); err != nil {
+		return err
+	}
+
+	logger.Info("LLM provider set to %s", providerModelName(provider))
+	return nil
+}
+
+// editLLMSettingsWithDialogs asks for the provider and its settings, then offers its
+// models by number
+func editLLMSettingsWithDialogs() {
+	const title = "LLM Provider Settings"
+
+	// STEP 1: Ask for the provider and its endpoint and sampling settings
+	provider := config.GetActiveProvider()
+	model, maxTokens, temperature := config.GetProviderConfig(provider)
+	values, err := reaper.GetUserInputs(title,
+		[]string{"Provider (openai, gemini, compatible)", "Base URL (not for openai)", "Max tokens", "Temperature"},
+		[]string{nameOf(providerNames, string(provider)), config.GetProviderBaseURL(provider),
+			strconv.Itoa(maxTokens), strconv.FormatFloat(temperature, 'f', -1, 64)})
+	if err != nil {
+		logger.Debug("LLM settings cancelled")
+		return
+	}
+
+	name, ok := providerNames[strings.ToLower(strings.TrimSpace(values[0]))]
+	if !ok {
+		reaper.MessageBox("Enter openai, gemini or compatible for the provider.", title)
+		return
+	}
+	chosen := config.Provider(name)
+	if chosen != provider {
+		model, _, _ = config.GetProviderConfig(chosen)
+	}
+	settings := llmSettingsValues{BaseURL: values[1], Model: model, MaxTokens: values[2], Temperature: values[3]}
+	if chosen == config.ProviderOpenAI {
+		settings.BaseURL = ""
+	}
+
+	// STEP 2: List the models in the background, then ask for one on the main thread
+	client, err := modelListClient(chosen, strings.TrimSpace(settings.BaseURL))
+	if err != nil {
+		chooseModelWithDialogs(title, chosen, settings, nil, err)
+		return
+	}
+	logger.Info("Listing %s models", providerDisplayName(chosen))
+	core.Go("LLM model list", func() {
+		models, err := client.ListModels()
+		core.MainThread.CallAsync(func() { chooseModelWithDialogs(title, chosen, settings, models, err) })
+	})
+}
+
+// chooseModelWithDialogs shows the listed models, asks for one by number or name, and
+// saves the settings
+func chooseModelWithDialogs(title string, provider config.Provider, settings llmSettingsValues, models []string, listErr error) {
+	// STEP 3: Show the models, or why there are none
+	var builder strings.Builder
+	switch {
+	case listErr != nil:
+		logger.Warning("Failed to list models: %v", listErr)
+		builder.WriteString(modelListError(listErr) + "\n\nType the model name in the next dialog.")
+	case len(models) == 0:
+		builder.WriteString("The provider listed no models.\n\nType the model name in the next dialog.")
+	default:
+		builder.WriteString(fmt.Sprintf("%s offers %d models:\n\n", providerDisplayName(provider), len(models)))
+		for i, model := range models {
+			if i == maxListedModels {
+				builder.WriteString(fmt.Sprintf("... and %d more; type any name in the next dialog.\n", len(models)-maxListedModels))
+				break
+			}
+			builder.WriteString(fmt.Sprintf("%d. %s\n", i+1, model))
+		}
+	}
+	reaper.MessageBox(builder.String(), title)
+
+	// STEP 4: Ask for the model
+	values, err := reaper.GetUserInputs(title, []string{"Model (number or name)"}, []string{settings.Model})
+	if err != nil {
+		logger.Debug("LLM settings cancelled")
+		return
+	}
+	settings.Model = strings.TrimSpace(values[0])
+	if number, err := strconv.Atoi(settings.Model); err == nil && number >= 1 && number <= len(models) {
+		settings.Model = models[number-1]
+	}
+
+	// STEP 5: Save
+	if err := saveLLMSettings(provider, settings); err != nil {
+		core.HandleError(title, core.NewError(core.CategoryUser, "The LLM settings were not saved.", err))
+		return
+	}
+	reaper.MessageBox(fmt.Sprintf("%s is the active provider.", providerModelName(provider)), title)
+}
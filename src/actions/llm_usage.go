@@ -0,0 +1,208 @@
+package actions
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-reaper/src/core"
+	"go-reaper/src/llm"
+	"go-reaper/src/pkg/config"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ExtState key for the usage ledger (stored in the extension's ExtState section)
+const usageExtStateKey = "LLMUsage"
+
+// usageRetentionDays limits how much history is kept in ExtState
+const usageRetentionDays = 90
+
+// usageTotals accumulates usage for one provider on one day
+type usageTotals struct {
+	Requests         int     `json:"requests"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	Cost             float64 `json:"cost"` // Estimated USD
+}
+
+// usageLedger maps day (YYYY-MM-DD) → provider → totals
+type usageLedger map[string]map[string]*usageTotals
+
+// usageMutex serializes read-modify-write of the ledger
+var usageMutex sync.Mutex
+
+// The LLM usage report action
+func init() {
+	registerAction(Action{
+		ID:      "GO_LLM_USAGE_REPORT",
+		Feature: config.FeatureAssistant,
+		Name:    "Go: LLM Usage Report",
+		Handler: handleLLMUsageReport,
+	})
+}
+
+// usageRecorder returns a usage handler that records requests for a provider
+func usageRecorder(provider config.Provider) llm.UsageHandler {
+	return func(model string, usage llm.Usage) {
+		recordLLMUsage(string(provider), model, usage)
+	}
+}
+
+// deferUsage hands a client's usage recording to the main thread, for clients whose
+// requests run in the background: recording writes ExtState
+func deferUsage(client llm.Client) {
+	openAI, ok := client.(*llm.OpenAIClient)
+	if !ok || openAI.OnUsage == nil {
+		return
+	}
+	record := openAI.OnUsage
+	openAI.OnUsage = func(model string, usage llm.Usage) {
+		core.MainThread.CallAsync(func() { record(model, usage) })
+	}
+}
+
+// recordLLMUsage adds a request's usage to today's totals
+func recordLLMUsage(provider string, model string, usage llm.Usage) {
+	usageMutex.Lock()
+	defer usageMutex.Unlock()
+
+	ledger := loadUsageLedger()
+
+	day := time.Now().Format("2006-01-02")
+	if ledger[day] == nil {
+		ledger[day] = make(map[string]*usageTotals)
+	}
+	totals := ledger[day][provider]
+	if totals == nil {
+		totals = &usageTotals{}
+		ledger[day][provider] = totals
+	}
+
+	cost := llm.EstimateCost(model, usage)
+	totals.Requests++
+	totals.PromptTokens += usage.PromptTokens
+	totals.CompletionTokens += usage.CompletionTokens
+	totals.Cost += cost
+
+	logger.Info("LLM usage: %s/%s prompt=%d completion=%d est. cost=$%.5f",
+		provider, model, usage.PromptTokens, usage.CompletionTokens, cost)
+
+	saveUsageLedger(ledger)
+}
+
+// loadUsageLedger reads the ledger from ExtState
+func loadUsageLedger() usageLedger {
+	ledger := make(usageLedger)
+
+	data, err := reaper.GetExtState(config.ExtStateSection, usageExtStateKey)
+	if err != nil || data == "" {
+		return ledger
+	}
+
+	if err := json.Unmarshal([]byte(data), &ledger); err != nil {
+		logger.Warning("Failed to parse LLM usage data, starting fresh: %v", err)
+		return make(usageLedger)
+	}
+
+	return ledger
+}
+
+// saveUsageLedger prunes old days and writes the ledger to ExtState
+func saveUsageLedger(ledger usageLedger) {
+	cutoff := time.Now().AddDate(0, 0, -usageRetentionDays).Format("2006-01-02")
+	for day := range ledger {
+		if day < cutoff {
+			delete(ledger, day)
+		}
+	}
+
+	data, err := json.Marshal(ledger)
+	if err != nil {
+		logger.Error("Failed to marshal LLM usage data: %v", err)
+		return
+	}
+
+	if err := reaper.SetExtState(config.ExtStateSection, usageExtStateKey, string(data), true); err != nil {
+		logger.Error("Failed to save LLM usage data: %v", err)
+	}
+}
+
+// handleLLMUsageReport shows usage totals per day and provider
+func handleLLMUsageReport() {
+	usageMutex.Lock()
+	ledger := loadUsageLedger()
+	usageMutex.Unlock()
+
+	if len(ledger) == 0 {
+		reaper.MessageBox("No LLM usage has been recorded yet.", "LLM Usage Report")
+		return
+	}
+
+	reaper.MessageBox(formatUsageReport(ledger), "LLM Usage Report")
+}
+
+// formatUsageReport formats the ledger, newest day first, with overall totals per provider
+func formatUsageReport(ledger usageLedger) string {
+	days := make([]string, 0, len(ledger))
+	for day := range ledger {
+		days = append(days, day)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(days)))
+
+	var builder strings.Builder
+	overall := make(map[string]*usageTotals)
+
+	// Keep the dialog readable: show the last two weeks in detail
+	const detailDays = 14
+	for i, day := range days {
+		providers := make([]string, 0, len(ledger[day]))
+		for provider := range ledger[day] {
+			providers = append(providers, provider)
+		}
+		sort.Strings(providers)
+
+		if i < detailDays {
+			builder.WriteString(day + "\n")
+		}
+
+		for _, provider := range providers {
+			totals := ledger[day][provider]
+			if i < detailDays {
+				builder.WriteString(fmt.Sprintf("  %s: %d requests, %d tokens, ~$%.4f\n",
+					provider, totals.Requests, totals.PromptTokens+totals.CompletionTokens, totals.Cost))
+			}
+
+			sum := overall[provider]
+			if sum == nil {
+				sum = &usageTotals{}
+				overall[provider] = sum
+			}
+			sum.Requests += totals.Requests
+			sum.PromptTokens += totals.PromptTokens
+			sum.CompletionTokens += totals.CompletionTokens
+			sum.Cost += totals.Cost
+		}
+	}
+
+	if len(days) > detailDays {
+		builder.WriteString(fmt.Sprintf("(%d earlier days not shown)\n", len(days)-detailDays))
+	}
+
+	builder.WriteString(fmt.Sprintf("\nTotal (last %d days):\n", usageRetentionDays))
+	providers := make([]string, 0, len(overall))
+	for provider := range overall {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+	for _, provider := range providers {
+		sum := overall[provider]
+		builder.WriteString(fmt.Sprintf("  %s: %d requests, %d prompt + %d completion tokens, ~$%.4f\n",
+			provider, sum.Requests, sum.PromptTokens, sum.CompletionTokens, sum.Cost))
+	}
+
+	builder.WriteString("\nCosts are estimates based on published model prices.")
+	return builder.String()
+}
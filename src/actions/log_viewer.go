@@ -0,0 +1,314 @@
+package actions
+
+import (
+	"errors"
+	"fmt"
+	"go-reaper/src/core"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"go-reaper/src/ui"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// This file implements the log viewer, which shows the extension's recent log messages
+// from memory, so diagnostics can be gathered without finding the log file. On macOS
+// it is a window that follows new messages; elsewhere the filtered messages go to the
+// console and, optionally, the clipboard.
+
+// logViewerWindowID is the ID of the log viewer window
+const logViewerWindowID = "log-viewer"
+
+const (
+	logViewerPollInterval = 500 * time.Millisecond // Between checks for new messages
+	logViewerMaxLines     = 500                    // Matching messages shown, newest first
+)
+
+// logViewerLevels name the level filter's choices; the index is the most verbose
+// logger level shown
+var logViewerLevels = []string{"Errors", "Warnings and errors", "Info and above", "Debug and above", "Everything"}
+
+// logViewer is the open log viewer window
+type logViewer struct {
+	window *ui.Window
+	level  *ui.Widget
+	search *ui.Widget
+	follow *ui.Widget
+	text   *ui.Widget
+	status *ui.Widget
+
+	entries []logger.Entry // Everything read so far, oldest first
+	lastSeq uint64
+}
+
+var (
+	logViewerMutex  sync.Mutex
+	logViewerWindow *logViewer
+)
+
+// The log viewer action
+func init() {
+	registerAction(Action{
+		ID:      "GO_LOG_VIEWER",
+		Name:    "Go: Log viewer",
+		Handler: handleLogViewer,
+	})
+}
+
+// handleLogViewer opens the log viewer window, or lists the log in the console where
+// there are no native windows
+func handleLogViewer() {
+	if runtime.GOOS == "darwin" {
+		err := showLogViewer()
+		if err == nil {
+			return
+		}
+		logger.Warning("Failed to open the log viewer window, using the console: %v", err)
+	}
+	showLogInConsole()
+}
+
+// filterLogEntries returns the entries up to level whose text contains search, ignoring
+// case, oldest first
+func filterLogEntries(entries []logger.Entry, level int, search string) []logger.Entry {
+	search = strings.ToLower(strings.TrimSpace(search))
+
+	var matching []logger.Entry
+	for _, entry := range entries {
+		if entry.Level > level {
+			continue
+		}
+		if search != "" && !strings.Contains(strings.ToLower(entry.FuncName+": "+entry.Message), search) {
+			continue
+		}
+		matching = append(matching, entry)
+	}
+	return matching
+}
+
+// formatLogEntries lists entries one per line, in the order given
+func formatLogEntries(entries []logger.Entry) string {
+	lines := make([]string, len(entries))
+	for i, entry := range entries {
+		lines[i] = entry.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// showLogInConsole asks for a filter and prints the matching messages to the console
+func showLogInConsole() {
+	const title = "Log Viewer"
+
+	values, err := reaper.GetUserInputs(title,
+		[]string{"Level (error, warning, info, debug, trace)", "Search (optional)", "Copy to clipboard (y/n)"},
+		[]string{logger.LevelName(logger.LevelTrace), "", "n"})
+	if err != nil {
+		logger.Debug("Log viewer cancelled")
+		return
+	}
+	level, ok := logger.ParseLevel(strings.TrimSpace(values[0]))
+	if !ok {
+		reaper.MessageBox("Enter error, warning, info, debug or trace.", title)
+		return
+	}
+
+	matching := filterLogEntries(logger.Recent(0), level, values[1])
+	if len(matching) == 0 {
+		reaper.MessageBox("No recent log messages match.", title)
+		return
+	}
+	text := formatLogEntries(matching)
+
+	reaper.Console.Section(fmt.Sprintf("%s: %d message(s)", title, len(matching)))
+	if err := reaper.Console.Print(text); err != nil {
+		core.HandleError(title, core.NewError(core.CategoryReaper, "Failed to show the log in the console.", err))
+		return
+	}
+
+	if strings.HasPrefix(strings.ToLower(strings.TrimSpace(values[2])), "y") {
+		if err := ui.SetClipboardText(text); err != nil {
+			message := "Could not copy the messages to the clipboard."
+			if errors.Is(err, reaper.ErrSWSNotInstalled) {
+				message = "Copying needs the SWS extension on this system. The messages are in the console."
+			}
+			reaper.MessageBox(message, title)
+		}
+	}
+}
+
+// showLogViewer opens the log viewer window, or brings it to the front
+func showLogViewer() error {
+	logViewerMutex.Lock()
+	v := logViewerWindow
+	logViewerMutex.Unlock()
+
+	if v == nil || !v.window.IsOpen() {
+		window, err := ui.NewWindow(logViewerWindowID, "Log Viewer", 720, 480)
+		if err != nil {
+			return err
+		}
+
+		v = &logViewer{window: window}
+		if err := v.build(); err != nil {
+			window.Close()
+			return err
+		}
+		if err := ui.Windows.OnClose(logViewerWindowID, v.closed); err != nil {
+			logger.Warning("Failed to observe the log viewer closing: %v", err)
+		}
+
+		logViewerMutex.Lock()
+		logViewerWindow = v
+		logViewerMutex.Unlock()
+
+		v.readNew()
+		v.render()
+		reaper.After(logViewerPollInterval, v.poll)
+	}
+
+	return v.window.Show()
+}
+
+// build adds the window's widgets
+func (v *logViewer) build() error {
+	w := v.window
+
+	levelLabel, err := w.AddLabel(ui.Rect{Height: 20}, "Show:")
+	if err != nil {
+		return err
+	}
+	if v.level, err = w.AddDropdown(ui.Rect{Width: 180, Height: 26}, logViewerLevels, logger.LevelTrace, func(int) { v.render() }); err != nil {
+		return err
+	}
+	searchLabel, err := w.AddLabel(ui.Rect{Height: 20}, "Search:")
+	if err != nil {
+		return err
+	}
+	if v.search, err = w.AddTextField(ui.Rect{Width: 160, Height: 24}, "", func(string) { v.render() }); err != nil {
+		return err
+	}
+	if v.follow, err = w.AddCheckbox(ui.Rect{Height: 20}, "Follow new messages", true, func(bool) { v.render() }); err != nil {
+		return err
+	}
+
+	if v.text, err = w.AddMultilineText(ui.Rect{Width: 480, Height: 320}, "", false, nil); err != nil {
+		return err
+	}
+
+	if v.status, err = w.AddLabel(ui.Rect{Width: 240, Height: 20}, ""); err != nil {
+		return err
+	}
+	copyButton, err := w.AddButton(ui.Rect{Width: 100, Height: 28}, "Copy", v.copyMatching)
+	if err != nil {
+		return err
+	}
+	// Close after the click has been handled, so the window outlives its button's action
+	closeLater := func() { ui.RunOnMainThreadAsync(w.Close) }
+	closeButton, err := w.AddButton(ui.Rect{Width: 100, Height: 28}, "Close", closeLater)
+	if err != nil {
+		return err
+	}
+
+	row := func() *ui.Stack { return ui.NewHStack(0, ui.LayoutSpacing) }
+	err = w.SetLayout(ui.NewVStack(ui.LayoutMargin, ui.LayoutSpacing).
+		Add(row().Add(levelLabel, v.level, searchLabel).Fill(v.search).Add(v.follow)).
+		Fill(v.text).
+		Add(row().Fill(v.status).Add(copyButton, closeButton)))
+	if err != nil {
+		return err
+	}
+
+	if err := v.level.SetAccessibilityLabel("Levels shown"); err != nil {
+		return err
+	}
+	if err := v.search.SetAccessibilityLabel("Search"); err != nil {
+		return err
+	}
+	if err := v.text.SetAccessibilityLabel("Log messages, newest first"); err != nil {
+		return err
+	}
+	return w.SetCancelButton(closeButton)
+}
+
+// readNew adds the messages logged since the last read, keeping as many as the log's
+// history does, and reports whether there were any
+func (v *logViewer) readNew() bool {
+	entries := logger.Recent(v.lastSeq)
+	if len(entries) == 0 {
+		return false
+	}
+
+	v.entries = append(v.entries, entries...)
+	if excess := len(v.entries) - logger.HistorySize; excess > 0 {
+		v.entries = append([]logger.Entry(nil), v.entries[excess:]...)
+	}
+	v.lastSeq = entries[len(entries)-1].Seq
+	return true
+}
+
+// matching returns the read messages that pass the filters, oldest first
+func (v *logViewer) matching() []logger.Entry {
+	level := int(v.level.Value())
+	if level < logger.LevelError {
+		level = logger.LevelTrace
+	}
+	return filterLogEntries(v.entries, level, v.search.Text())
+}
+
+// render shows the newest matching messages first, so new ones appear at the top
+func (v *logViewer) render() {
+	matching := v.matching()
+
+	shown := matching
+	if len(shown) > logViewerMaxLines {
+		shown = shown[len(shown)-logViewerMaxLines:]
+	}
+	newestFirst := make([]logger.Entry, len(shown))
+	for i, entry := range shown {
+		newestFirst[len(shown)-1-i] = entry
+	}
+
+	// Widget errors aren't logged, since that would add a message on every refresh
+	v.text.SetText(formatLogEntries(newestFirst))
+	status := fmt.Sprintf("%d of %d messages match", len(matching), len(v.entries))
+	if len(matching) > len(shown) {
+		status += fmt.Sprintf(", newest %d shown", len(shown))
+	}
+	v.status.SetText(status)
+}
+
+// poll reads new messages while the window is open, showing them when following
+func (v *logViewer) poll() {
+	if !v.window.IsOpen() {
+		return
+	}
+	if v.readNew() && v.follow.Checked() {
+		v.render()
+	}
+	reaper.After(logViewerPollInterval, v.poll)
+}
+
+// copyMatching copies all matching messages, oldest first
+func (v *logViewer) copyMatching() {
+	matching := v.matching()
+	if len(matching) == 0 {
+		v.status.SetText("No messages to copy")
+		return
+	}
+	if err := ui.SetClipboardText(formatLogEntries(matching)); err != nil {
+		v.status.SetText(fmt.Sprintf("Could not copy the messages: %v", err))
+		return
+	}
+	v.status.SetText(fmt.Sprintf("Copied %d messages to the clipboard", len(matching)))
+}
+
+// closed forgets the window once it closes
+func (v *logViewer) closed() {
+	logViewerMutex.Lock()
+	if logViewerWindow == v {
+		logViewerWindow = nil
+	}
+	logViewerMutex.Unlock()
+}
@@ -0,0 +1,355 @@
+package actions
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-reaper/src/core"
+	"go-reaper/src/pkg/config"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// This file implements macros: named sequences of REAPER actions and our own actions,
+// with optional delays and conditions, stored in the settings. Each macro is registered
+// as an action of its own, so it can be bound to a shortcut or a toolbar button.
+
+// macroActionPrefix starts the action ID of every macro
+const macroActionPrefix = "GO_MACRO_"
+
+// macrosFileName is the file the macro editor exports the macros to
+const macrosFileName = "macros.json"
+
+// maxMacroDelay limits a step's delay, so a typo can't leave a macro waiting for hours
+const maxMacroDelay = 60 * time.Second
+
+// macroConditions are the conditions a step can run under, by name. Prefix a name with
+// "!" to run the step when the condition is false.
+var macroConditions = map[string]func() bool{
+	"track_selected": func() bool {
+		count, err := reaper.CountSelectedTracks()
+		return err == nil && count > 0
+	},
+	"has_fx": func() bool {
+		track, err := reaper.GetSelectedTrack()
+		if err != nil {
+			return false
+		}
+		count, err := reaper.GetTrackFXCount(track)
+		return err == nil && count > 0
+	},
+	"fx_focused": func() bool {
+		_, _, err := reaper.GetFocusedFX()
+		return err == nil
+	},
+	"in_region": func() bool {
+		_, err := reaper.GetCurrentRegion()
+		return err == nil
+	},
+}
+
+// runningMacros holds the macros that have started and not yet finished, so a macro
+// can't be started again while it waits or run itself. Only touched on the main thread.
+var runningMacros = make(map[string]bool)
+
+// The macro editor action
+func init() {
+	registerAction(Action{
+		ID:      "GO_MACROS_EDIT",
+		Name:    "Go: Edit Macros...",
+		Handler: handleEditMacros,
+	})
+}
+
+// macroActionID returns the action ID for a macro name, e.g. GO_MACRO_SOLO_AND_LOOP
+func macroActionID(name string) string {
+	id := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9'):
+			return r
+		default:
+			return '_'
+		}
+	}, strings.TrimSpace(name))
+	return macroActionPrefix + id
+}
+
+// registerMacros registers an action for each macro in the settings
+func registerMacros() {
+	for _, macro := range config.GetMacros() {
+		if err := registerMacroAction(macro.Name); err != nil {
+			logger.Error("Failed to register macro %q: %v", macro.Name, err)
+		}
+	}
+}
+
+// registerMacroAction registers the action that runs a macro, unless it already exists.
+// The steps are read when the action runs, so edits apply without registering again.
+func registerMacroAction(name string) error {
+	id := macroActionID(name)
+	if isActionRegistered(id) {
+		return nil
+	}
+	if !config.IsActionEnabled(id) {
+		logger.Info("Skipping disabled macro %s", id)
+		return nil
+	}
+
+	return registerWithReaper(Action{
+		ID:      id,
+		Name:    "Go Macro: " + name,
+		Handler: func() { runMacro(name) },
+	})
+}
+
+// runMacro starts the macro with the given name
+func runMacro(name string) {
+	var macro config.Macro
+	found := false
+	for _, m := range config.GetMacros() {
+		if m.Name == name {
+			macro, found = m, true
+			break
+		}
+	}
+	if !found {
+		reaper.MessageBox(fmt.Sprintf("The macro %q no longer exists.", name), "Macros")
+		return
+	}
+
+	if runningMacros[name] {
+		logger.Warning("Macro %q is already running", name)
+		return
+	}
+	runningMacros[name] = true
+
+	logger.Info("Running macro %q (%d steps)", name, len(macro.Steps))
+	continueMacro(macro, 0, false)
+}
+
+// continueMacro runs a macro's steps from index. A step with a delay hands the rest of
+// the macro to REAPER's timer, then continues with delayDone set.
+func continueMacro(macro config.Macro, index int, delayDone bool) {
+	// Finished, failed or panicked: the macro can run again
+	handedOff := false
+	defer func() {
+		if !handedOff {
+			delete(runningMacros, macro.Name)
+		}
+	}()
+
+	for ; index < len(macro.Steps); index++ {
+		step := macro.Steps[index]
+
+		if step.DelayMs > 0 && !delayDone {
+			next := index
+			reaper.After(time.Duration(step.DelayMs)*time.Millisecond, func() {
+				defer reaper.Recover("macro " + macro.Name)
+				continueMacro(macro, next, true)
+			})
+			handedOff = true
+			return
+		}
+		delayDone = false
+
+		if err := runMacroStep(step); err != nil {
+			core.HandleError("Macros", core.NewError(core.CategoryUser,
+				fmt.Sprintf("Macro %q stopped at step %d.", macro.Name, index+1), err).
+				WithDetail("command %q", step.Command))
+			return
+		}
+	}
+
+	logger.Info("Macro %q finished", macro.Name)
+}
+
+// runMacroStep runs one step if its condition holds
+func runMacroStep(step config.MacroStep) error {
+	run, err := evaluateMacroCondition(step.If)
+	if err != nil {
+		return err
+	}
+	if !run {
+		logger.Debug("Skipping macro step %q: condition %q is false", step.Command, step.If)
+		return nil
+	}
+
+	commandID, err := resolveMacroCommand(step.Command)
+	if err != nil {
+		return err
+	}
+
+	logger.Debug("Running macro step %q (command ID %d)", step.Command, commandID)
+	return reaper.RunCommand(commandID)
+}
+
+// resolveMacroCommand returns the command ID for a step's command, explaining what to
+// check when it can't be found
+func resolveMacroCommand(command string) (int, error) {
+	commandID, err := reaper.LookupCommand(command)
+	if err == nil {
+		return commandID, nil
+	}
+
+	command = strings.TrimSpace(command)
+	switch {
+	case strings.HasPrefix(command, "GO_"):
+		return 0, fmt.Errorf("action %s is not registered; it may be disabled", command)
+	case strings.HasPrefix(command, "_"):
+		return 0, fmt.Errorf("%v; is the extension that provides it installed?", err)
+	default:
+		return 0, err
+	}
+}
+
+// evaluateMacroCondition reports whether a step with the given condition should run.
+// An empty condition always holds.
+func evaluateMacroCondition(condition string) (bool, error) {
+	condition = strings.TrimSpace(condition)
+	if condition == "" {
+		return true, nil
+	}
+
+	check, negate, ok := parseMacroCondition(condition)
+	if !ok {
+		return false, fmt.Errorf("unknown condition %q", condition)
+	}
+	return check() != negate, nil
+}
+
+// parseMacroCondition looks up a condition like "track_selected" or "!in_region"
+func parseMacroCondition(condition string) (check func() bool, negate bool, ok bool) {
+	condition = strings.TrimSpace(condition)
+	negate = strings.HasPrefix(condition, "!")
+	check, ok = macroConditions[strings.TrimSpace(strings.TrimPrefix(condition, "!"))]
+	return check, negate, ok
+}
+
+// validateMacros checks edited macros before they are saved
+func validateMacros(macros []config.Macro) error {
+	ids := make(map[string]string)
+	for _, macro := range macros {
+		name := strings.TrimSpace(macro.Name)
+		if name == "" {
+			return fmt.Errorf("every macro needs a name")
+		}
+		id := macroActionID(name)
+		if other, ok := ids[id]; ok {
+			return fmt.Errorf("macros %q and %q have the same action ID %s", other, name, id)
+		}
+		ids[id] = name
+
+		if len(macro.Steps) == 0 {
+			return fmt.Errorf("macro %q has no steps", name)
+		}
+		for i, step := range macro.Steps {
+			command := strings.TrimSpace(step.Command)
+			if command == "" {
+				return fmt.Errorf("macro %q step %d has no command", name, i+1)
+			}
+			if macroActionID(name) == command {
+				return fmt.Errorf("macro %q runs itself at step %d", name, i+1)
+			}
+			if _, err := strconv.Atoi(command); err != nil && !strings.HasPrefix(command, "_") && !strings.HasPrefix(command, "GO_") {
+				return fmt.Errorf("macro %q step %d: %q is not a command ID, a named command or a GO_ action", name, i+1, command)
+			}
+			if step.DelayMs < 0 || time.Duration(step.DelayMs)*time.Millisecond > maxMacroDelay {
+				return fmt.Errorf("macro %q step %d: delay must be between 0 and %d ms", name, i+1, maxMacroDelay.Milliseconds())
+			}
+			if _, _, ok := parseMacroCondition(step.If); strings.TrimSpace(step.If) != "" && !ok {
+				return fmt.Errorf("macro %q step %d: unknown condition %q", name, i+1, step.If)
+			}
+		}
+	}
+	return nil
+}
+
+// macroConditionNames lists the condition names in alphabetical order
+func macroConditionNames() []string {
+	names := make([]string, 0, len(macroConditions))
+	for name := range macroConditions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// handleEditMacros writes the macros to a JSON file, lets the user edit it in any text
+// editor, then loads the edited macros into the settings and registers new ones
+func handleEditMacros() {
+	const title = "Macros"
+	path := filepath.Join(extensionDataDir(), macrosFileName)
+
+	// STEP 1: Export the current macros
+	macros := config.GetMacros()
+	if macros == nil {
+		macros = []config.Macro{}
+	}
+	data, err := json.MarshalIndent(macros, "", "  ")
+	if err != nil {
+		core.HandleError(title, core.NewError(core.CategoryInternal, "Failed to encode the macros.", err))
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		core.HandleError(title, core.NewError(core.CategoryStorage, "Failed to create the extension folder.", err))
+		return
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		core.HandleError(title, core.NewError(core.CategoryStorage, "Failed to write "+macrosFileName+".", err))
+		return
+	}
+
+	// STEP 2: Let the user edit the file
+	msg := fmt.Sprintf("The macros have been saved to:\n\n%s\n\n"+
+		"Edit the file in a text editor and save it, then click Yes to load it. For example:\n\n"+
+		`[{"name": "Play Two Seconds", "steps": [{"command": "40044", "if": "track_selected"}, {"command": "40044", "delay_ms": 2000}]}]`+"\n\n"+
+		"A command is a REAPER command ID, a named command starting with _ or one of our GO_ action IDs.\n"+
+		"Conditions: %s (prefix ! to negate).\n\n"+
+		"Each macro appears in the action list as \"Go Macro: <name>\". Click No to keep the current macros.",
+		path, strings.Join(macroConditionNames(), ", "))
+
+	load, err := reaper.YesNoBox(msg, title)
+	if err != nil || !load {
+		logger.Debug("Macro editing cancelled")
+		return
+	}
+
+	// STEP 3: Read and validate the edited macros
+	data, err = os.ReadFile(path)
+	if err != nil {
+		core.HandleError(title, core.NewError(core.CategoryStorage, "Failed to read "+macrosFileName+".", err))
+		return
+	}
+	var edited []config.Macro
+	if strings.TrimSpace(string(data)) != "" {
+		if err := json.Unmarshal(data, &edited); err != nil {
+			reaper.MessageBox(fmt.Sprintf("The macros were not saved.\n\n%s is not valid JSON: %v", macrosFileName, err), title)
+			return
+		}
+	}
+	if err := validateMacros(edited); err != nil {
+		reaper.MessageBox(fmt.Sprintf("The macros were not saved.\n\n%v", err), title)
+		return
+	}
+
+	// STEP 4: Save to settings and register new macros
+	if err := config.SetMacros(edited); err != nil {
+		core.HandleError(title, core.NewError(core.CategoryStorage, "Failed to save the macros to the settings.", err))
+		return
+	}
+	for _, macro := range edited {
+		if err := registerMacroAction(macro.Name); err != nil {
+			logger.Error("Failed to register macro %q: %v", macro.Name, err)
+		}
+	}
+
+	logger.Info("Saved %d macros", len(edited))
+	reaper.MessageBox(fmt.Sprintf("Saved %d macros. Removed macros leave the action list when REAPER restarts.", len(edited)), title)
+}
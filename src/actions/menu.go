@@ -0,0 +1,177 @@
+package actions
+
+import (
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"go-reaper/src/ui"
+	"runtime"
+	"unsafe"
+)
+
+// extensionMenuTitle is the title of our submenu in REAPER's Extensions menu
+const extensionMenuTitle = "Go Extension"
+
+// contextTrack is the track the track context menu was last opened on, or zero when it
+// was opened on empty space. It is only touched on the main thread.
+var contextTrack reaper.TrackRef
+
+// extensionMenuItems lists the actions in our Extensions submenu
+var extensionMenuItems = []ui.MenuItem{
+	{Title: "LLM FX Assistant...", ActionID: "GO_FX_ASSISTANT"},
+	{Title: "LLM FX Assistant Plan...", ActionID: "GO_FX_ASSISTANT_PLAN"},
+	{Title: "LLM FX Assistant with Request from Clipboard", ActionID: "GO_FX_ASSISTANT_PASTE"},
+	{Title: "Assistant History...", ActionID: "GO_ASSISTANT_HISTORY"},
+	{Title: "Revert Last Assistant Change", ActionID: "GO_ASSISTANT_REVERT_LAST"},
+	{Title: "Mix Feedback on Selected Tracks...", ActionID: "GO_MIX_FEEDBACK"},
+	{Title: "Parameter State Report of Selected Tracks...", ActionID: "GO_PARAM_REPORT"},
+	{Title: "Section Loudness of Selected Track...", ActionID: "GO_SECTION_LOUDNESS"},
+	{Title: "Show EQ Curve for Selected Track", ActionID: "GO_EQ_CURVE"},
+	{},
+	{Title: "Export FX Chain of Selected Track...", ActionID: "GO_FX_CHAIN_EXPORT"},
+	{Title: "Import FX Chain to Selected Track...", ActionID: "GO_FX_CHAIN_IMPORT"},
+	{Title: "Insert Track Template with Parameter Overrides...", ActionID: "GO_TRACK_TEMPLATE_INSERT"},
+	{Title: "Copy Focused FX Settings to Selected Tracks", ActionID: "GO_FX_COPY_SETTINGS"},
+	{Title: "Adjust Parameters by Name on Selected Tracks...", ActionID: "GO_FX_PARAM_ADJUST_BY_NAME"},
+	{Title: "Link Last Touched FX Parameter...", ActionID: "GO_PARAM_LINK_ADD"},
+	{Title: "Parameter Links...", ActionID: "GO_PARAM_LINKS"},
+	{Title: "Parameter Linking", ActionID: "GO_PARAM_LINKS_TOGGLE"},
+	{Title: "MIDI Learn Last Touched FX Parameter", ActionID: "GO_MIDI_LEARN_PARAM"},
+	{Title: "MIDI Learn Action...", ActionID: "GO_MIDI_LEARN_ACTION"},
+	{Title: "MIDI Learn Manager...", ActionID: "GO_MIDI_LEARN_MANAGER"},
+	{Title: "FX Control Bank", ActionID: "GO_FX_BANK_TOGGLE"},
+	{Title: "FX Control Bank Page...", ActionID: "GO_FX_BANK_SHOW"},
+	{Title: "FX Control Bank Settings...", ActionID: "GO_FX_BANK_SETTINGS"},
+	{Title: "Stem Render Selected Tracks for Current Region...", ActionID: "GO_REGION_STEM_RENDER"},
+	{Title: "FX Snapshots...", ActionID: "GO_FX_SNAPSHOTS"},
+	{Title: "Freeze Selected Tracks (Remember FX)...", ActionID: "GO_FREEZE_TRACKS"},
+	{Title: "Unfreeze Selected Tracks", ActionID: "GO_UNFREEZE_TRACKS"},
+	{},
+	{Title: "Edit Prompt Templates...", ActionID: "GO_FX_ASSISTANT_TEMPLATES"},
+	{Title: "Assistant Parameter Filter...", ActionID: "GO_ANALYZER_SETTINGS"},
+	{Title: "Window Appearance...", ActionID: "GO_WINDOW_APPEARANCE"},
+	{Title: "LLM Provider Settings...", ActionID: "GO_LLM_SETTINGS"},
+	{Title: "LLM Network Settings...", ActionID: "GO_LLM_NETWORK"},
+	{Title: "API Key Storage...", ActionID: "GO_KEY_STORAGE"},
+	{Title: "Test LLM Connection", ActionID: "GO_LLM_TEST_CONNECTION"},
+	{Title: "Log LLM Exchanges", ActionID: "GO_LLM_LOG_EXCHANGES"},
+	{Title: "Open Last LLM Exchange", ActionID: "GO_LLM_LAST_EXCHANGE"},
+	{Title: "Edit Macros...", ActionID: "GO_MACROS_EDIT"},
+	{Title: "LLM Usage Report", ActionID: "GO_LLM_USAGE_REPORT"},
+	{Title: "Clear LLM Response Cache", ActionID: "GO_LLM_CLEAR_CACHE"},
+	{},
+	{Title: "About / Check for Updates...", ActionID: "GO_ABOUT"},
+	{Title: "Extension Diagnostics", ActionID: "GO_DIAGNOSTICS"},
+	{Title: "Log Viewer", ActionID: "GO_LOG_VIEWER"},
+	{Title: "Profile Bridge Calls", ActionID: "GO_PROFILE_BRIDGE"},
+	{Title: "Reload Extension State", ActionID: "GO_RELOAD_STATE"},
+}
+
+// RegisterExtensionsMenu adds our submenu to REAPER's Extensions menu and the track
+// context menu
+func RegisterExtensionsMenu() error {
+	logger.Info("Registering Extensions menu")
+
+	if runtime.GOOS != "darwin" {
+		logger.Info("Extensions menu is currently only implemented for macOS")
+		return nil
+	}
+
+	reaper.AddMenuHook(reaper.MenuExtensions, buildExtensionsMenu)
+	reaper.AddMenuHook(reaper.MenuTrackContext, buildTrackContextMenu)
+
+	// The Extensions menu stays hidden until an extension asks for it
+	if err := reaper.AddExtensionsMainMenu(); err != nil {
+		logger.Warning("Failed to show the Extensions menu: %v", err)
+	}
+	return nil
+}
+
+// buildExtensionsMenu adds our submenu when REAPER creates the Extensions menu
+func buildExtensionsMenu(menu unsafe.Pointer, flag int) {
+	if flag != reaper.MenuFlagInit {
+		return
+	}
+
+	// Leave out disabled actions, and separators left at either end or doubled up
+	var items []ui.MenuItem
+	for _, item := range extensionMenuItems {
+		if item.Title == "" {
+			if len(items) > 0 && items[len(items)-1].Title != "" {
+				items = append(items, item)
+			}
+			continue
+		}
+		if isActionRegistered(item.ActionID) {
+			items = append(items, item)
+		}
+	}
+	if len(items) > 0 && items[len(items)-1].Title == "" {
+		items = items[:len(items)-1]
+	}
+	if len(items) == 0 {
+		return
+	}
+
+	if err := ui.AddSubmenu(menu, extensionMenuTitle, items); err != nil {
+		logger.Error("Failed to add %s menu: %v", extensionMenuTitle, err)
+	}
+}
+
+// buildTrackContextMenu adds our items when REAPER creates the track context menu and
+// notes which track it is opened on
+func buildTrackContextMenu(menu unsafe.Pointer, flag int) {
+	switch flag {
+	case reaper.MenuFlagInit:
+		// Both items open the assistant, so leave them out when it is disabled
+		if !isActionRegistered("GO_FX_ASSISTANT") {
+			return
+		}
+		items := []ui.MenuItem{
+			{Title: "Ask FX Assistant about this track...", OnSelect: askAssistantAboutContextTrack},
+			{Title: "Ask FX Assistant about the focused FX...", OnSelect: askAssistantAboutFocusedFX},
+		}
+		if err := ui.AddSubmenu(menu, extensionMenuTitle, items); err != nil {
+			logger.Error("Failed to add %s track context menu: %v", extensionMenuTitle, err)
+		}
+	case reaper.MenuFlagShow:
+		// The menu opens where the user clicked, so the track under the mouse is the one
+		contextTrack = reaper.TrackRef{}
+		track, err := reaper.GetTrackUnderMouse()
+		if err != nil {
+			logger.Debug("Track context menu opened without a track: %v", err)
+			return
+		}
+		if contextTrack, err = reaper.NewTrackRef(track); err != nil {
+			logger.Warning("Failed to identify the context menu track: %v", err)
+		}
+	}
+}
+
+// askAssistantAboutContextTrack opens the assistant for the track the menu was opened
+// on, falling back to the selected track
+func askAssistantAboutContextTrack() {
+	track, err := contextTrack.Resolve()
+	if err != nil {
+		track = nil
+	}
+
+	if err := showAssistantPanelFor(track, -1); err != nil {
+		logger.Error("Failed to open assistant panel: %v", err)
+		reaper.MessageBox("Failed to open the assistant. See log for details.", "LLM FX Assistant")
+	}
+}
+
+// askAssistantAboutFocusedFX opens the assistant for the FX whose window last had focus
+func askAssistantAboutFocusedFX() {
+	track, fxIndex, err := reaper.GetFocusedFX()
+	if err != nil {
+		logger.Info("No focused FX for the assistant: %v", err)
+		reaper.MessageBox("Open an FX window first, then choose this item again.", "LLM FX Assistant")
+		return
+	}
+
+	if err := showAssistantPanelFor(track, fxIndex); err != nil {
+		logger.Error("Failed to open assistant panel: %v", err)
+		reaper.MessageBox("Failed to open the assistant. See log for details.", "LLM FX Assistant")
+	}
+}
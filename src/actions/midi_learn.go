@@ -0,0 +1,528 @@
+package actions
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-reaper/src/core"
+	"go-reaper/src/pkg/config"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// This file implements MIDI learn: binding a controller's CC to an FX parameter or an
+// action. REAPER's MIDI input history is polled on the timer, so a binding works on any
+// input enabled in REAPER's preferences, for input or for control only. Bindings are kept
+// in the global ext state, so they work in every project.
+
+// midiLearnSection and midiLearnKey hold the bindings as JSON in the ext state
+const (
+	midiLearnSection = "GoReaperMIDILearn"
+	midiLearnKey     = "bindings"
+)
+
+// midiPollInterval is how often MIDI input is read
+const midiPollInterval = 20 * time.Millisecond
+
+// midiLearnTimeout is how long learning waits for a control to move
+const midiLearnTimeout = 15 * time.Second
+
+// midiBinding maps a CC to an FX parameter, scaled over the parameter's range, or to an
+// action, run when the CC's value is above 0 so buttons run it once per press
+type midiBinding struct {
+	ID         string `json:"id"`
+	Device     int    `json:"device"` // Input device index the control was learned from
+	DeviceName string `json:"device_name,omitempty"`
+	AnyInput   bool   `json:"any_input,omitempty"` // Match the CC on every input
+	Channel    int    `json:"channel"`             // 1-16
+	CC         int    `json:"cc"`
+	paramTarget
+	Action string `json:"action,omitempty"` // Command for action bindings, as LookupCommand takes it
+}
+
+// isAction reports whether the binding runs an action instead of setting a parameter
+func (b midiBinding) isAction() bool {
+	return b.Action != ""
+}
+
+// matches reports whether an event comes from the binding's control
+func (b midiBinding) matches(event reaper.MIDIEvent) bool {
+	return event.IsCC() && event.Channel() == b.Channel && int(event.Data1) == b.CC &&
+		(b.AnyInput || b.Device == event.Device)
+}
+
+// sameSource reports whether two bindings listen to the same control
+func (b midiBinding) sameSource(other midiBinding) bool {
+	return b.Channel == other.Channel && b.CC == other.CC &&
+		(b.AnyInput || other.AnyInput || b.Device == other.Device)
+}
+
+// source describes the binding's control, e.g. "CC 7, channel 1 (nanoKONTROL2)"
+func (b midiBinding) source() string {
+	device := b.DeviceName
+	if b.AnyInput {
+		device = "any input"
+	} else if device == "" {
+		device = fmt.Sprintf("input %d", b.Device)
+	}
+	return fmt.Sprintf("CC %d, channel %d (%s)", b.CC, b.Channel, device)
+}
+
+// target describes what the binding controls
+func (b midiBinding) target() string {
+	if b.isAction() {
+		return "action " + b.Action
+	}
+	return b.Label
+}
+
+// midiLearner applies the bindings and learns new ones. It is only touched on the main
+// thread.
+type midiLearner struct {
+	started  bool
+	primed   bool // Whether the input history before loading has been skipped
+	seq      int  // Newest MIDI event read
+	loaded   bool
+	bindings []midiBinding
+	fx       fxLocator
+
+	learning     *midiBinding // Target waiting for a control to move
+	learnStarted time.Time
+	learned      func(midiBinding, error)
+}
+
+var midiLearn = &midiLearner{}
+
+// The MIDI learn actions
+func init() {
+	registerAction(Action{
+		ID:      "GO_MIDI_LEARN_PARAM",
+		Name:    "Go: MIDI Learn last touched FX parameter",
+		Handler: handleMIDILearnParam,
+	})
+	registerAction(Action{
+		ID:      "GO_MIDI_LEARN_ACTION",
+		Name:    "Go: MIDI Learn action...",
+		Handler: handleMIDILearnAction,
+	})
+	registerAction(Action{
+		ID:      "GO_MIDI_LEARN_MANAGER",
+		Name:    "Go: MIDI Learn Manager",
+		Handler: handleMIDILearnManager,
+	})
+}
+
+// StartMIDILearn starts reading MIDI input for the learned bindings on REAPER's timer.
+// Call it after RegisterAll.
+func StartMIDILearn() {
+	if midiLearn.started {
+		return
+	}
+	midiLearn.started = true
+	reaper.Defer(midiLearn.tick)
+}
+
+// StopMIDILearn stops reading MIDI input and forgets the bindings, e.g. when the plugin
+// unloads
+func StopMIDILearn() {
+	midiLearn.started = false
+	midiLearn.reset()
+}
+
+// reset forgets the bindings, so they are read again when needed, and drops learning
+// without reporting it, since a dialog could block unloading
+func (l *midiLearner) reset() {
+	if l.learning != nil {
+		logger.Info("Stopped waiting for a MIDI control for %s", l.learning.target())
+	}
+	l.learning = nil
+	l.learned = nil
+	l.primed = false
+	l.loaded = false
+	l.bindings = nil
+	l.fx.reset()
+}
+
+// tick handles the MIDI events received since the last tick, then schedules the next
+// until stopped
+func (l *midiLearner) tick() {
+	if !l.started {
+		return
+	}
+	defer reaper.After(midiPollInterval, l.tick)
+
+	events, seq, err := reaper.ReadMIDIInput(l.seq)
+	if err != nil {
+		logger.Debug("MIDI input not read: %v", err)
+		return
+	}
+	l.seq = seq
+	if !l.primed {
+		// Events from before loading weren't meant for us
+		l.primed = true
+		return
+	}
+
+	if l.learning != nil && time.Since(l.learnStarted) > midiLearnTimeout {
+		l.finishLearning(midiBinding{}, fmt.Errorf("no control moved within %d seconds", int(midiLearnTimeout.Seconds())))
+	}
+	if len(events) == 0 {
+		return
+	}
+
+	if err := l.load(); err != nil {
+		logger.Warning("MIDI bindings not loaded: %v", err)
+		return
+	}
+
+	// Only the last value of each parameter binding and bank control matters
+	var order []int
+	values := make(map[int]byte)
+	bankValues := make(map[int]byte)
+	bankSettings := config.GetControlBank()
+	for _, event := range events {
+		if !event.IsCC() {
+			continue
+		}
+		if l.learning != nil {
+			l.learn(event)
+			continue
+		}
+		bound := false
+		for i, binding := range l.bindings {
+			if !binding.matches(event) {
+				continue
+			}
+			bound = true
+			if binding.isAction() {
+				l.runAction(binding, event.Data2)
+				continue
+			}
+			if _, ok := values[i]; !ok {
+				order = append(order, i)
+			}
+			values[i] = event.Data2
+		}
+		if slot, ok := bankSlot(bankSettings, event); ok && !bound {
+			bankValues[slot] = event.Data2
+		}
+	}
+	if len(order) > 0 {
+		l.setParameters(order, values)
+	}
+	if len(bankValues) > 0 {
+		controlBank.apply(bankSettings, bankValues)
+	}
+}
+
+// runAction runs an action binding's action for a CC value above 0
+func (l *midiLearner) runAction(binding midiBinding, value byte) {
+	if value == 0 {
+		return
+	}
+	commandID, err := reaper.LookupCommand(binding.Action)
+	if err != nil {
+		logger.Warning("MIDI binding %s: %v", binding.source(), err)
+		return
+	}
+	if err := reaper.RunCommand(commandID); err != nil {
+		logger.Warning("MIDI binding %s failed to run %s: %v", binding.source(), binding.Action, err)
+	}
+}
+
+// setParameters sets each parameter binding to its CC value, scaled over the
+// parameter's range, in one batch call
+func (l *midiLearner) setParameters(order []int, values map[int]byte) {
+	var changes []reaper.ParameterChange
+	for _, i := range order {
+		binding := l.bindings[i]
+		at, ok := l.fx.locate(binding.paramTarget)
+		if !ok {
+			continue
+		}
+		_, min, max, err := reaper.GetTrackFXParamValueWithRange(at.track, at.fxIndex, binding.ParamIndex)
+		if err != nil {
+			continue
+		}
+		changes = append(changes, reaper.ParameterChange{
+			Track:      at.track,
+			FXIndex:    at.fxIndex,
+			ParamIndex: binding.ParamIndex,
+			Value:      min + (max-min)*float64(values[i])/127,
+		})
+	}
+
+	failed, err := reaper.BatchSetParameters(changes)
+	if err != nil {
+		logger.Warning("Failed to set MIDI-bound parameters: %v", err)
+	} else if len(failed) > 0 {
+		logger.Debug("%d MIDI-bound parameter(s) could not be set", len(failed))
+	}
+}
+
+// startLearning binds the next CC received to target, calling done with the new
+// binding or an error. It replaces any learning still waiting.
+func (l *midiLearner) startLearning(target midiBinding, done func(midiBinding, error)) {
+	if l.learning != nil {
+		l.finishLearning(midiBinding{}, fmt.Errorf("learning was restarted"))
+	}
+	l.learning = &target
+	l.learnStarted = time.Now()
+	l.learned = done
+	logger.Info("Waiting for a MIDI control for %s", target.target())
+}
+
+// learn binds the learning target to the event's control, replacing any binding of the
+// same control. The new binding is stored first.
+func (l *midiLearner) learn(event reaper.MIDIEvent) {
+	binding := *l.learning
+	binding.Device = event.Device
+	binding.DeviceName, _ = reaper.GetMIDIInputName(event.Device)
+	binding.Channel = event.Channel()
+	binding.CC = int(event.Data1)
+	if binding.ID == "" {
+		binding.ID = strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+
+	kept := []midiBinding{binding}
+	for _, existing := range l.bindings {
+		if existing.ID != binding.ID && !existing.sameSource(binding) {
+			kept = append(kept, existing)
+		}
+	}
+	if err := saveMIDIBindings(kept); err != nil {
+		l.finishLearning(midiBinding{}, err)
+		return
+	}
+
+	logger.Info("Bound %s to %s", binding.source(), binding.target())
+	l.finishLearning(binding, nil)
+}
+
+// finishLearning stops learning and reports the result
+func (l *midiLearner) finishLearning(binding midiBinding, err error) {
+	done := l.learned
+	l.learning = nil
+	l.learned = nil
+	if done != nil {
+		done(binding, err)
+	}
+}
+
+// load reads the bindings once; saveMIDIBindings keeps them current after that
+func (l *midiLearner) load() error {
+	if l.loaded {
+		return nil
+	}
+	bindings, err := loadMIDIBindings()
+	if err != nil {
+		return err
+	}
+	l.bindings = bindings
+	l.loaded = true
+	return nil
+}
+
+// loadMIDIBindings reads the stored bindings
+func loadMIDIBindings() ([]midiBinding, error) {
+	data, err := reaper.GetExtState(midiLearnSection, midiLearnKey)
+	if err != nil || data == "" {
+		return nil, err
+	}
+
+	var bindings []midiBinding
+	if err := json.Unmarshal([]byte(data), &bindings); err != nil {
+		return nil, fmt.Errorf("the stored MIDI bindings are invalid: %v", err)
+	}
+	return bindings, nil
+}
+
+// saveMIDIBindings stores the bindings and makes them the ones in use
+func saveMIDIBindings(bindings []midiBinding) error {
+	data, err := json.Marshal(bindings)
+	if err != nil {
+		return fmt.Errorf("failed to encode the MIDI bindings: %v", err)
+	}
+	if err := reaper.SetExtState(midiLearnSection, midiLearnKey, string(data), true); err != nil {
+		return err
+	}
+
+	midiLearn.bindings = bindings
+	midiLearn.loaded = true
+	return nil
+}
+
+// deleteMIDIBinding removes a binding by ID
+func deleteMIDIBinding(id string) error {
+	bindings, err := loadMIDIBindings()
+	if err != nil {
+		return err
+	}
+
+	var kept []midiBinding
+	for _, binding := range bindings {
+		if binding.ID != id {
+			kept = append(kept, binding)
+		}
+	}
+	return saveMIDIBindings(kept)
+}
+
+// updateMIDIBinding replaces the stored binding with the same ID
+func updateMIDIBinding(updated midiBinding) error {
+	bindings, err := loadMIDIBindings()
+	if err != nil {
+		return err
+	}
+
+	for i, binding := range bindings {
+		if binding.ID == updated.ID {
+			bindings[i] = updated
+			return saveMIDIBindings(bindings)
+		}
+	}
+	return fmt.Errorf("the binding no longer exists")
+}
+
+// learnWithMessages learns a binding for target, telling the user to move a control
+// and then what was bound
+func learnWithMessages(title string, target midiBinding) {
+	reaper.MessageBox(fmt.Sprintf("Press OK, then within %d seconds move the control to use for %s.",
+		int(midiLearnTimeout.Seconds()), target.target()), title)
+
+	midiLearn.startLearning(target, func(binding midiBinding, err error) {
+		if err != nil {
+			reaper.MessageBox(fmt.Sprintf("Nothing was bound: %v.", err), title)
+			return
+		}
+		reaper.MessageBox(fmt.Sprintf("Bound %s to %s.", binding.source(), binding.target()), title)
+	})
+}
+
+// handleMIDILearnParam binds the next CC received to the last touched parameter
+func handleMIDILearnParam() {
+	const title = "MIDI Learn"
+
+	target, ok := lastTouchedParam(title)
+	if !ok {
+		return
+	}
+	learnWithMessages(title, midiBinding{paramTarget: target})
+}
+
+// handleMIDILearnAction binds the next CC received to an action
+func handleMIDILearnAction() {
+	const title = "MIDI Learn"
+
+	values, err := reaper.GetUserInputs(title,
+		[]string{"Action (command ID or name)"},
+		[]string{""})
+	if err != nil {
+		logger.Debug("MIDI learn cancelled")
+		return
+	}
+
+	command := strings.TrimSpace(values[0])
+	if _, err := reaper.LookupCommand(command); err != nil {
+		reaper.MessageBox(fmt.Sprintf("Unknown action %q: %v", command, err), title)
+		return
+	}
+	learnWithMessages(title, midiBinding{Action: command})
+}
+
+// handleMIDILearnManager opens the MIDI learn manager window, or manages bindings with
+// dialogs where there is no native window
+func handleMIDILearnManager() {
+	if runtime.GOOS == "darwin" {
+		err := showMIDILearnManager()
+		if err == nil {
+			return
+		}
+		logger.Warning("Failed to open MIDI learn manager, falling back to dialogs: %v", err)
+	}
+
+	manageMIDIBindingsWithDialogs()
+}
+
+// manageMIDIBindingsWithDialogs lists the bindings and edits or deletes one
+func manageMIDIBindingsWithDialogs() {
+	const title = "MIDI Learn"
+
+	// STEP 1: List the bindings
+	bindings, err := loadMIDIBindings()
+	if err != nil {
+		core.HandleError(title, core.NewError(core.CategoryStorage, "Could not read the MIDI bindings.", err))
+		return
+	}
+	if len(bindings) == 0 {
+		reaper.MessageBox("There are no MIDI bindings yet. Use \"Go: MIDI Learn last touched FX parameter\" or \"Go: MIDI Learn action\" to add one.", title)
+		return
+	}
+
+	var builder strings.Builder
+	for i, binding := range bindings {
+		builder.WriteString(fmt.Sprintf("%d. %s → %s\n", i+1, binding.source(), binding.target()))
+	}
+	builder.WriteString("\nActions: edit (channel, CC, any input), delete.")
+	reaper.MessageBox(builder.String(), title)
+
+	// STEP 2: Ask what to do
+	values, err := reaper.GetUserInputs(title,
+		[]string{"Action (edit/delete)", "Binding number", "Channel (1-16)", "CC (0-127)", "Any input (yes/no)"},
+		[]string{"edit", "", "", "", ""})
+	if err != nil {
+		logger.Debug("MIDI binding management cancelled")
+		return
+	}
+
+	number, err := strconv.Atoi(strings.TrimSpace(values[1]))
+	if err != nil || number < 1 || number > len(bindings) {
+		reaper.MessageBox(fmt.Sprintf("Invalid binding number: %s", values[1]), title)
+		return
+	}
+	binding := bindings[number-1]
+
+	// STEP 3: Carry it out
+	switch strings.ToLower(strings.TrimSpace(values[0])) {
+	case "edit":
+		edited, err := editMIDIBinding(binding, values[2], values[3], values[4])
+		if err != nil {
+			reaper.MessageBox(err.Error(), title)
+			return
+		}
+		if err := updateMIDIBinding(edited); err != nil {
+			core.HandleError(title, core.NewError(core.CategoryStorage, "Could not save the binding.", err))
+		}
+	case "delete":
+		if err := deleteMIDIBinding(binding.ID); err != nil {
+			core.HandleError(title, core.NewError(core.CategoryStorage, "Could not delete the binding.", err))
+		}
+	default:
+		reaper.MessageBox(fmt.Sprintf("Unknown action %q. Use edit or delete.", values[0]), title)
+	}
+}
+
+// editMIDIBinding applies typed changes to a binding. Empty fields keep their value.
+func editMIDIBinding(binding midiBinding, channel, cc, anyInput string) (midiBinding, error) {
+	if channel = strings.TrimSpace(channel); channel != "" {
+		value, err := strconv.Atoi(channel)
+		if err != nil || value < 1 || value > 16 {
+			return binding, fmt.Errorf("invalid channel: %s", channel)
+		}
+		binding.Channel = value
+	}
+	if cc = strings.TrimSpace(cc); cc != "" {
+		value, err := strconv.Atoi(cc)
+		if err != nil || value < 0 || value > 127 {
+			return binding, fmt.Errorf("invalid CC: %s", cc)
+		}
+		binding.CC = value
+	}
+	if anyInput = strings.TrimSpace(anyInput); anyInput != "" {
+		binding.AnyInput = isTruthy(anyInput)
+	}
+	return binding, nil
+}
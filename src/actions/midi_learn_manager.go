@@ -0,0 +1,298 @@
+package actions
+
+import (
+	"fmt"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"go-reaper/src/ui"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// This file implements the MIDI learn manager: a window listing the MIDI bindings, to
+// learn new ones, change a binding's channel, CC and input, and delete them. Widget
+// callbacks run on the main thread, so they call REAPER directly.
+
+// midiLearnWindowID is the ID the manager is registered under with ui.Windows
+const midiLearnWindowID = "midi-learn"
+
+// midiLearnManager holds the window's widgets and the listed bindings
+type midiLearnManager struct {
+	window *ui.Window
+
+	list     *ui.Widget
+	details  *ui.Widget
+	channel  *ui.Widget
+	cc       *ui.Widget
+	anyInput *ui.Widget
+	action   *ui.Widget
+	status   *ui.Widget
+	save     *ui.Widget
+	relearn  *ui.Widget
+	remove   *ui.Widget
+
+	mutex    sync.Mutex
+	bindings []midiBinding // Listed bindings, in list order
+}
+
+var (
+	midiLearnManagerMutex sync.Mutex
+	midiLearnWindow       *midiLearnManager
+)
+
+// showMIDILearnManager opens the manager with the current bindings, or brings it to the
+// front
+func showMIDILearnManager() error {
+	midiLearnManagerMutex.Lock()
+	m := midiLearnWindow
+	midiLearnManagerMutex.Unlock()
+
+	if m == nil || !m.window.IsOpen() {
+		window, err := ui.NewWindow(midiLearnWindowID, "MIDI Learn", 480, 380)
+		if err != nil {
+			return err
+		}
+
+		m = &midiLearnManager{window: window}
+		if err := m.build(); err != nil {
+			window.Close()
+			return err
+		}
+		if err := ui.Windows.OnClose(midiLearnWindowID, m.closed); err != nil {
+			logger.Warning("Failed to observe MIDI learn manager closing: %v", err)
+		}
+
+		midiLearnManagerMutex.Lock()
+		midiLearnWindow = m
+		midiLearnManagerMutex.Unlock()
+	}
+
+	m.reload(-1)
+	return m.window.Show()
+}
+
+// build adds the manager's widgets
+func (m *midiLearnManager) build() error {
+	w := m.window
+	var err error
+
+	if _, err = w.AddLabel(ui.Rect{X: 12, Y: 14, Width: 80, Height: 20}, "Binding:"); err != nil {
+		return err
+	}
+	if m.list, err = w.AddDropdown(ui.Rect{X: 92, Y: 10, Width: 268, Height: 26}, nil, -1, m.selected); err != nil {
+		return err
+	}
+	if _, err = w.AddButton(ui.Rect{X: 368, Y: 10, Width: 100, Height: 26}, "Refresh", func() { m.reload(-1) }); err != nil {
+		return err
+	}
+
+	if m.details, err = w.AddMultilineText(ui.Rect{X: 12, Y: 46, Width: 456, Height: 90}, "", false, nil); err != nil {
+		return err
+	}
+
+	if _, err = w.AddLabel(ui.Rect{X: 12, Y: 150, Width: 60, Height: 20}, "Channel:"); err != nil {
+		return err
+	}
+	if m.channel, err = w.AddTextField(ui.Rect{X: 72, Y: 146, Width: 50, Height: 24}, "", nil); err != nil {
+		return err
+	}
+	if _, err = w.AddLabel(ui.Rect{X: 134, Y: 150, Width: 30, Height: 20}, "CC:"); err != nil {
+		return err
+	}
+	if m.cc, err = w.AddTextField(ui.Rect{X: 164, Y: 146, Width: 50, Height: 24}, "", nil); err != nil {
+		return err
+	}
+	if m.anyInput, err = w.AddCheckbox(ui.Rect{X: 226, Y: 148, Width: 110, Height: 20}, "Any input", false, nil); err != nil {
+		return err
+	}
+	if m.save, err = w.AddButton(ui.Rect{X: 368, Y: 144, Width: 100, Height: 28}, "Save", m.saveSelected); err != nil {
+		return err
+	}
+
+	if m.relearn, err = w.AddButton(ui.Rect{X: 12, Y: 184, Width: 140, Height: 28}, "Learn Again", m.relearnSelected); err != nil {
+		return err
+	}
+	if m.remove, err = w.AddButton(ui.Rect{X: 160, Y: 184, Width: 96, Height: 28}, "Delete", m.deleteSelected); err != nil {
+		return err
+	}
+
+	if _, err = w.AddLabel(ui.Rect{X: 12, Y: 232, Width: 456, Height: 20}, "New binding:"); err != nil {
+		return err
+	}
+	if _, err = w.AddButton(ui.Rect{X: 12, Y: 256, Width: 220, Height: 28}, "Learn Last Touched Parameter", m.learnParam); err != nil {
+		return err
+	}
+	if _, err = w.AddLabel(ui.Rect{X: 12, Y: 300, Width: 60, Height: 20}, "Action:"); err != nil {
+		return err
+	}
+	if m.action, err = w.AddTextField(ui.Rect{X: 72, Y: 296, Width: 288, Height: 24}, "", nil); err != nil {
+		return err
+	}
+	if _, err = w.AddButton(ui.Rect{X: 368, Y: 294, Width: 100, Height: 28}, "Learn Action", m.learnAction); err != nil {
+		return err
+	}
+
+	if m.status, err = w.AddLabel(ui.Rect{X: 12, Y: 340, Width: 456, Height: 20}, ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+// setStatus shows a one-line status message
+func (m *midiLearnManager) setStatus(format string, args ...interface{}) {
+	if err := m.status.SetText(fmt.Sprintf(format, args...)); err != nil {
+		logger.Warning("Failed to update MIDI learn manager status: %v", err)
+	}
+}
+
+// reload lists the bindings and selects the one at index, or the first if index is out
+// of range
+func (m *midiLearnManager) reload(index int) {
+	bindings, err := loadMIDIBindings()
+	if err != nil {
+		m.setStatus("Could not read the MIDI bindings: %v", err)
+		bindings = nil
+	}
+
+	m.mutex.Lock()
+	m.bindings = bindings
+	m.mutex.Unlock()
+
+	labels := make([]string, len(bindings))
+	for i, binding := range bindings {
+		labels[i] = fmt.Sprintf("CC %d/%d → %s", binding.CC, binding.Channel, binding.target())
+	}
+	m.list.SetItems(labels)
+
+	has := len(bindings) > 0
+	m.save.SetEnabled(has)
+	m.relearn.SetEnabled(has)
+	m.remove.SetEnabled(has)
+	if !has {
+		m.details.SetText("There are no MIDI bindings yet. Touch an FX parameter, or type an action, and press the Learn button below.")
+		return
+	}
+
+	if index < 0 || index >= len(bindings) {
+		index = 0
+	}
+	m.list.SetValue(float64(index))
+	m.selected(index)
+}
+
+// current returns the binding selected in the list
+func (m *midiLearnManager) current() (int, midiBinding, bool) {
+	index := int(m.list.Value())
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if index < 0 || index >= len(m.bindings) {
+		return -1, midiBinding{}, false
+	}
+	return index, m.bindings[index], true
+}
+
+// selected shows the picked binding's details for editing
+func (m *midiLearnManager) selected(index int) {
+	_, binding, ok := m.current()
+	if !ok {
+		return
+	}
+	m.details.SetText(fmt.Sprintf("Control: %s\nControls: %s", binding.source(), binding.target()))
+	m.channel.SetText(strconv.Itoa(binding.Channel))
+	m.cc.SetText(strconv.Itoa(binding.CC))
+	m.anyInput.SetChecked(binding.AnyInput)
+}
+
+// saveSelected stores the edited channel, CC and input of the selected binding
+func (m *midiLearnManager) saveSelected() {
+	index, binding, ok := m.current()
+	if !ok {
+		return
+	}
+	anyInput := "no"
+	if m.anyInput.Checked() {
+		anyInput = "yes"
+	}
+	edited, err := editMIDIBinding(binding, m.channel.Text(), m.cc.Text(), anyInput)
+	if err != nil {
+		m.setStatus("Not saved: %v", err)
+		return
+	}
+	if err := updateMIDIBinding(edited); err != nil {
+		m.setStatus("Not saved: %v", err)
+		return
+	}
+	m.reload(index)
+	m.setStatus("Saved %s", edited.source())
+}
+
+// deleteSelected removes the selected binding
+func (m *midiLearnManager) deleteSelected() {
+	index, binding, ok := m.current()
+	if !ok {
+		return
+	}
+	if err := deleteMIDIBinding(binding.ID); err != nil {
+		m.setStatus("Not deleted: %v", err)
+		return
+	}
+	m.reload(index)
+	m.setStatus("Deleted the binding of %s", binding.source())
+}
+
+// relearnSelected binds the selected binding's target to the next control moved
+func (m *midiLearnManager) relearnSelected() {
+	_, binding, ok := m.current()
+	if !ok {
+		return
+	}
+	m.learn(binding)
+}
+
+// learnParam binds the last touched parameter to the next control moved
+func (m *midiLearnManager) learnParam() {
+	target, ok := lastTouchedParam("MIDI Learn")
+	if !ok {
+		return
+	}
+	m.learn(midiBinding{paramTarget: target})
+}
+
+// learnAction binds the typed action to the next control moved
+func (m *midiLearnManager) learnAction() {
+	command := strings.TrimSpace(m.action.Text())
+	if _, err := reaper.LookupCommand(command); err != nil {
+		m.setStatus("Unknown action %q: %v", command, err)
+		return
+	}
+	m.learn(midiBinding{Action: command})
+}
+
+// learn waits for a control for target, showing progress in the status line
+func (m *midiLearnManager) learn(target midiBinding) {
+	m.setStatus("Move a control for %s...", target.target())
+	midiLearn.startLearning(target, func(binding midiBinding, err error) {
+		if !m.window.IsOpen() {
+			return
+		}
+		if err != nil {
+			m.setStatus("Nothing bound: %v", err)
+			return
+		}
+		// Learned bindings are stored first
+		m.reload(0)
+		m.setStatus("Bound %s", binding.source())
+	})
+}
+
+// closed forgets the manager
+func (m *midiLearnManager) closed() {
+	midiLearnManagerMutex.Lock()
+	if midiLearnWindow == m {
+		midiLearnWindow = nil
+	}
+	midiLearnManagerMutex.Unlock()
+}
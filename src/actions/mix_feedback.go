@@ -0,0 +1,323 @@
+package actions
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-reaper/src/core"
+	"go-reaper/src/llm"
+	"go-reaper/src/pkg/config"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"sort"
+	"strings"
+	"unsafe"
+)
+
+// This file implements mix feedback: the FX chains of all selected tracks are described
+// to the LLM, which critiques them as a whole and suggests what to change first. The
+// feedback is only shown; nothing is applied.
+
+// mixFeedbackWindowID is the ID of the report window
+const mixFeedbackWindowID = "mix-feedback"
+
+// mixFeedbackParamLimits are the key parameters listed per FX, tried in turn until the
+// prompt fits the model
+var mixFeedbackParamLimits = []int{12, 6, 3, 0}
+
+// mixFeedbackSystemPrompt asks for a critique rather than parameter changes
+const mixFeedbackSystemPrompt = `You are an experienced mix engineer reviewing the FX chains of several tracks in a digital audio workstation.
+
+You are given each track's FX in processing order, whether each FX is bypassed, and a few key parameters. You can't hear the audio, so judge the processing choices: gain staging, order of processors, EQ and compression working against each other, duplicated or missing processing, tracks competing for the same range, and bypassed FX that may have been forgotten.
+
+Respond with a JSON document containing:
+- summary: your overall impression in two or three sentences
+- issues: problems you see, each with the track name as listed (or "overall"), a severity of high, medium or low, and the problem
+- suggestions: what to change, ordered by priority starting at 1, each with the track, the FX it concerns if any, the suggestion and the reason
+
+Refer to tracks and FX by the names given. Be specific and practical, and don't suggest changes you can't justify from the chains.`
+
+// MixFeedback is the LLM's critique of the selected tracks
+type MixFeedback struct {
+	Summary     string          `json:"summary"`
+	Issues      []MixIssue      `json:"issues"`
+	Suggestions []MixSuggestion `json:"suggestions"`
+}
+
+// MixIssue is a problem the LLM sees in the chains
+type MixIssue struct {
+	Track    string `json:"track" desc:"Track name as listed, or overall"`
+	Severity string `json:"severity" desc:"One of: high, medium, low"`
+	Problem  string `json:"problem"`
+}
+
+// MixSuggestion is a change the LLM recommends
+type MixSuggestion struct {
+	Priority   int    `json:"priority" desc:"1 for the most important"`
+	Track      string `json:"track"`
+	FX         string `json:"fx" desc:"FX the suggestion concerns, or empty"`
+	Suggestion string `json:"suggestion"`
+	Reason     string `json:"reason"`
+}
+
+// mixFeedbackSchema is the JSON schema structured-output providers must follow
+var mixFeedbackSchema = llm.SchemaFromType("mix_feedback", MixFeedback{})
+
+// trackChain is a track's name and FX chain, with the parameters chosen for a prompt or report
+type trackChain struct {
+	Name string
+	FX   []reaper.FXInfo // In chain order
+}
+
+// The mix feedback action
+func init() {
+	registerAction(Action{
+		ID:      "GO_MIX_FEEDBACK",
+		Feature: config.FeatureAssistant,
+		Name:    "Go: LLM mix feedback on selected tracks",
+		Handler: handleMixFeedback,
+	})
+}
+
+// handleMixFeedback gathers the selected tracks' chains and asks the LLM to critique them
+func handleMixFeedback() {
+	const title = "Mix Feedback"
+
+	// STEP 1: Gather the selected tracks' chains
+	tracks, err := reaper.GetSelectedTracks()
+	if err != nil {
+		core.HandleError(title, core.NewError(core.CategoryReaper, "Could not read the selected tracks.", err))
+		return
+	}
+	if len(tracks) == 0 {
+		reaper.MessageBox("Select the tracks to get feedback on first.", title)
+		return
+	}
+	chains := collectTrackChains(tracks, func(param reaper.FXParameter) bool {
+		return classifyRelevance(param) == relevant
+	})
+	if countChainFX(chains) == 0 {
+		reaper.MessageBox("None of the selected tracks has any FX.", title)
+		return
+	}
+
+	// STEP 2: Ask what to focus on
+	values, err := reaper.GetUserInputs(title, []string{"Focus (optional, e.g. 'vocals sit too low')"}, []string{""})
+	if err != nil {
+		logger.Debug("Mix feedback cancelled")
+		return
+	}
+	focus := strings.TrimSpace(values[0])
+
+	// STEP 3: Build the prompt, listing fewer parameters until it fits
+	provider := config.GetActiveProvider()
+	model, maxTokens, _ := config.GetProviderConfig(provider)
+	budget := llm.PromptBudget(model, mixFeedbackSystemPrompt, maxTokens)
+	var userPrompt string
+	for _, limit := range mixFeedbackParamLimits {
+		userPrompt = buildMixFeedbackPrompt(chains, focus, limit)
+		if budget <= 0 || llm.EstimateTokens(userPrompt) <= budget {
+			break
+		}
+	}
+	if budget <= 0 {
+		logger.Warning("No prompt budget left for %s with max tokens %d", model, maxTokens)
+		proceed, err := reaper.YesNoBox(noPromptBudgetNote+"\n\nContinue?", title)
+		if err != nil || !proceed {
+			logger.Debug("Mix feedback cancelled: no prompt budget")
+			return
+		}
+	}
+	logger.Info("Mix feedback prompt for %d tracks: %s", len(chains), userPrompt)
+
+	// STEP 4: Get a client
+	apiKey, err := getProviderAPIKey(provider)
+	if err != nil {
+		logger.Debug("Mix feedback cancelled: %v", err)
+		return
+	}
+	llmClient := newLLMClient(provider, apiKey)
+	deferUsage(llmClient)
+	var client llm.Client = newRetryClient(llmClient)
+	if config.GetCacheResponses() {
+		client = llm.NewCachingClient(client, getResponseCache(), cacheNamespace(provider))
+	}
+
+	// STEP 5: Ask in the background and show the report back on the main thread
+	logger.Info("Asking %s for mix feedback on %d tracks", providerModelName(provider), len(chains))
+	core.Go("mix feedback", func() {
+		responseText, err := sendStructuredPrompt(client, mixFeedbackSystemPrompt, userPrompt, mixFeedbackSchema)
+		core.MainThread.CallAsync(func() { showMixFeedback(title, provider, len(chains), responseText, err) })
+	})
+}
+
+// collectTrackChains reads the name and FX of each track, keeping the parameters keep
+// accepts
+func collectTrackChains(tracks []unsafe.Pointer, keep func(param reaper.FXParameter) bool) []trackChain {
+	chains := make([]trackChain, 0, len(tracks))
+	for i, track := range tracks {
+		name, err := reaper.GetTrackName(track)
+		if err != nil || strings.TrimSpace(name) == "" {
+			name = fmt.Sprintf("Track %d", i+1)
+		}
+
+		summaries, err := reaper.GetTrackFXSummaries(track)
+		if err != nil {
+			logger.Warning("Failed to read the FX of %s: %v", name, err)
+		}
+
+		chain := trackChain{Name: name}
+		for _, summary := range summaries {
+			fx, err := reaper.GetFXParameters(track, summary.Index)
+			if err != nil {
+				logger.Warning("Failed to read the parameters of %s on %s: %v", summary.Name, name, err)
+				fx = summary
+			}
+			fx.Enabled, fx.Offline = summary.Enabled, summary.Offline
+
+			var kept []reaper.FXParameter
+			for _, param := range fx.Parameters {
+				if keep(param) {
+					kept = append(kept, param)
+				}
+			}
+			fx.Parameters = kept
+			chain.FX = append(chain.FX, fx)
+		}
+		chains = append(chains, chain)
+	}
+	return chains
+}
+
+// countChainFX counts the FX on all tracks
+func countChainFX(chains []trackChain) int {
+	count := 0
+	for _, chain := range chains {
+		count += len(chain.FX)
+	}
+	return count
+}
+
+// buildMixFeedbackPrompt describes the chains with at most paramLimit parameters per FX
+func buildMixFeedbackPrompt(chains []trackChain, focus string, paramLimit int) string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("Here are the FX chains of %d tracks.\n\n", len(chains)))
+
+	for _, chain := range chains {
+		builder.WriteString(fmt.Sprintf("Track %q", chain.Name))
+		if len(chain.FX) == 0 {
+			builder.WriteString(": no FX\n\n")
+			continue
+		}
+		builder.WriteString(":\n")
+
+		for position, fx := range chain.FX {
+			state := ""
+			if fx.Offline {
+				state = " [offline]"
+			} else if !fx.Enabled {
+				state = " [bypassed]"
+			}
+			builder.WriteString(fmt.Sprintf("  %d. %s%s%s\n", position+1, fx.Name, describePlugin(fx), state))
+
+			for i, param := range fx.Parameters {
+				if i == paramLimit {
+					builder.WriteString(fmt.Sprintf("     (%d more parameters)\n", len(fx.Parameters)-paramLimit))
+					break
+				}
+				value := strings.TrimSpace(param.FormattedValue)
+				if value == "" {
+					value = fmt.Sprintf("%.3f", param.Value)
+				}
+				builder.WriteString(fmt.Sprintf("     - %s: %s\n", param.Name, value))
+			}
+		}
+		builder.WriteString("\n")
+	}
+
+	if focus != "" {
+		builder.WriteString("The user would especially like feedback on: " + focus + "\n")
+	} else {
+		builder.WriteString("Give general feedback on how these tracks are processed.\n")
+	}
+	return builder.String()
+}
+
+// parseMixFeedback parses the LLM's response, which may be JSON embedded in text when
+// the provider doesn't support structured output
+func parseMixFeedback(responseText string) (*MixFeedback, error) {
+	var feedback MixFeedback
+	if err := json.Unmarshal([]byte(strings.TrimSpace(responseText)), &feedback); err != nil {
+		start, end := strings.Index(responseText, "{"), strings.LastIndex(responseText, "}")
+		if start == -1 || end < start {
+			return nil, fmt.Errorf("could not find valid JSON in response")
+		}
+		feedback = MixFeedback{}
+		if err := json.Unmarshal([]byte(responseText[start:end+1]), &feedback); err != nil {
+			return nil, fmt.Errorf("failed to parse LLM response: %v", err)
+		}
+	}
+
+	sort.SliceStable(feedback.Suggestions, func(i, j int) bool {
+		return feedback.Suggestions[i].Priority < feedback.Suggestions[j].Priority
+	})
+	return &feedback, nil
+}
+
+// showMixFeedback reports the LLM's answer, or why there is none
+func showMixFeedback(title string, provider config.Provider, trackCount int, responseText string, err error) {
+	if err != nil {
+		core.HandleError(title, core.NewError(core.CategoryLLM, "The LLM request failed.\n\n"+llm.UserMessage(err), err).
+			WithDetail("provider %s", provider))
+		return
+	}
+	logger.Info("Mix feedback response: %s", responseText)
+
+	feedback, err := parseMixFeedback(responseText)
+	if err != nil {
+		core.HandleError(title, core.NewError(core.CategoryLLM, "The LLM's response could not be understood. Please try again.", err).
+			WithDetail("response %q", responseText))
+		return
+	}
+
+	header := fmt.Sprintf("Feedback from %s on %d track(s). Nothing has been changed.\n\n", providerModelName(provider), trackCount)
+	showReport(mixFeedbackWindowID, title, header+formatMixFeedback(feedback))
+}
+
+// formatMixFeedback lays out the critique for reading
+func formatMixFeedback(feedback *MixFeedback) string {
+	var builder strings.Builder
+	if summary := strings.TrimSpace(feedback.Summary); summary != "" {
+		builder.WriteString(summary + "\n\n")
+	}
+
+	if len(feedback.Issues) > 0 {
+		builder.WriteString("Issues:\n")
+		for _, severity := range []string{"high", "medium", "low", ""} {
+			for _, issue := range feedback.Issues {
+				level := strings.ToLower(strings.TrimSpace(issue.Severity))
+				known := level == "high" || level == "medium" || level == "low"
+				if (severity != "" && level != severity) || (severity == "" && known) {
+					continue
+				}
+				builder.WriteString(fmt.Sprintf("  [%s] %s: %s\n", strings.ToUpper(issue.Severity), issue.Track, issue.Problem))
+			}
+		}
+		builder.WriteString("\n")
+	}
+
+	if len(feedback.Suggestions) > 0 {
+		builder.WriteString("Suggestions, most important first:\n")
+		for i, suggestion := range feedback.Suggestions {
+			target := suggestion.Track
+			if fx := strings.TrimSpace(suggestion.FX); fx != "" {
+				target += " • " + fx
+			}
+			builder.WriteString(fmt.Sprintf("  %d. %s: %s\n     Why: %s\n", i+1, target, suggestion.Suggestion, suggestion.Reason))
+		}
+	}
+
+	if len(feedback.Issues) == 0 && len(feedback.Suggestions) == 0 {
+		builder.WriteString("The LLM found nothing to change.\n")
+	}
+	return builder.String()
+}
@@ -0,0 +1,363 @@
+package actions
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-reaper/src/core"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// This file implements parameter links: named groups of FX parameters, on any FX and
+// tracks, that follow each other. Each member maps a shared base value to its own value
+// as base*scale+offset; when the user moves one member, the others are set to match in
+// one batch call. Groups are stored in the project, and the current project's groups are
+// polled on REAPER's timer.
+
+// linkSection and linkKey hold the project's link groups as JSON in project ext state
+const (
+	linkSection = "GoReaperLinks"
+	linkKey     = "groups"
+)
+
+// linkPollInterval is how often linked parameters are checked for changes
+const linkPollInterval = 50 * time.Millisecond
+
+// linkTolerance is how far a value must move to count as a change, so rounding in the
+// plugin doesn't bounce values around the group
+const linkTolerance = 1e-6
+
+// linkMember is a parameter in a link group
+type linkMember struct {
+	paramTarget
+	Scale  float64 `json:"scale"`  // Value = base*Scale + Offset; never 0
+	Offset float64 `json:"offset"` // In the parameter's own values, 0-1 for most plugins
+}
+
+// linkGroup is a named set of parameters that follow each other
+type linkGroup struct {
+	Name    string       `json:"name"`
+	Members []linkMember `json:"members"`
+}
+
+// linkEngine keeps linked parameters in step. It is only touched on the main thread.
+type linkEngine struct {
+	enabled bool
+	started bool
+	data    string // Project ext state the groups were parsed from
+	groups  []linkGroup
+	fx      fxLocator
+	last    map[string]float64 // Value of each member at the last check
+}
+
+var links = &linkEngine{
+	enabled: true,
+	last:    make(map[string]float64),
+}
+
+// The parameter link actions
+func init() {
+	registerAction(Action{
+		ID:      "GO_PARAM_LINK_ADD",
+		Name:    "Go: Link last touched FX parameter...",
+		Handler: handleLinkLastTouched,
+	})
+	registerAction(Action{
+		ID:      "GO_PARAM_LINKS",
+		Name:    "Go: Parameter Links",
+		Handler: handleParamLinks,
+	})
+	registerAction(Action{
+		ID:          "GO_PARAM_LINKS_TOGGLE",
+		Name:        "Go: Parameter Linking (Toggle)",
+		Handler:     func() { links.enabled = !links.enabled },
+		ToggleState: func() bool { return links.enabled },
+	})
+}
+
+// StartParamLinks starts checking the current project's parameter links on REAPER's
+// timer. Call it after RegisterAll.
+func StartParamLinks() {
+	if links.started {
+		return
+	}
+	links.started = true
+	reaper.Defer(links.tick)
+}
+
+// StopParamLinks stops the checks and forgets what was read, e.g. when the plugin unloads
+func StopParamLinks() {
+	links.started = false
+	links.reset()
+}
+
+// reset forgets the groups and values read, so the next check reads them again
+func (e *linkEngine) reset() {
+	e.data = ""
+	e.groups = nil
+	e.fx.reset()
+	e.last = make(map[string]float64)
+}
+
+// tick syncs every group, then schedules the next check until stopped
+func (e *linkEngine) tick() {
+	if !e.started {
+		return
+	}
+	defer reaper.After(linkPollInterval, e.tick)
+
+	if !e.enabled {
+		return
+	}
+	if err := e.reload(); err != nil {
+		logger.Debug("Parameter links not checked: %v", err)
+		return
+	}
+	for _, group := range e.groups {
+		e.sync(group)
+	}
+}
+
+// reload reads the current project's groups, parsing them again only when they changed,
+// e.g. after editing them or switching projects
+func (e *linkEngine) reload() error {
+	data, err := reaper.GetProjExtState(linkSection, linkKey)
+	if err != nil {
+		return err
+	}
+	if data == e.data {
+		return nil
+	}
+
+	e.reset()
+	e.data = data
+
+	if data == "" {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(data), &e.groups); err != nil {
+		logger.Warning("Ignoring invalid parameter links in the project: %v", err)
+		e.groups = nil
+	}
+	return nil
+}
+
+// sync looks for a member the user moved since the last check and sets the others to
+// match it. Members whose track or FX is gone are left out until they come back.
+func (e *linkEngine) sync(group linkGroup) {
+	type current struct {
+		member linkMember
+		where  fxLocation
+		value  float64
+	}
+
+	var members []current
+	moved := -1
+	for _, member := range group.Members {
+		where, ok := e.fx.locate(member.paramTarget)
+		if !ok {
+			continue
+		}
+		value, err := reaper.GetTrackFXParamValue(where.track, where.fxIndex, member.ParamIndex)
+		if err != nil {
+			continue
+		}
+
+		last, seen := e.last[member.key()]
+		if seen && moved < 0 && math.Abs(value-last) > linkTolerance {
+			moved = len(members)
+		}
+		e.last[member.key()] = value
+		members = append(members, current{member: member, where: where, value: value})
+	}
+	if moved < 0 || len(members) < 2 {
+		return
+	}
+
+	leader := members[moved]
+	base := (leader.value - leader.member.Offset) / leader.member.Scale
+
+	var changes []reaper.ParameterChange
+	for i, m := range members {
+		if i == moved {
+			continue
+		}
+		changes = append(changes, reaper.ParameterChange{
+			Track:      m.where.track,
+			FXIndex:    m.where.fxIndex,
+			ParamIndex: m.member.ParamIndex,
+			Value:      base*m.member.Scale + m.member.Offset,
+		})
+	}
+	if _, err := reaper.BatchSetParameters(changes); err != nil {
+		logger.Warning("Failed to update linked parameters of %q: %v", group.Name, err)
+	}
+
+	// Remember what the plugins made of the new values, so they don't count as moves
+	for i, m := range members {
+		if i == moved {
+			continue
+		}
+		if value, err := reaper.GetTrackFXParamValue(m.where.track, m.where.fxIndex, m.member.ParamIndex); err == nil {
+			e.last[m.member.key()] = value
+		}
+	}
+}
+
+// loadLinkGroups reads the current project's link groups
+func loadLinkGroups() ([]linkGroup, error) {
+	data, err := reaper.GetProjExtState(linkSection, linkKey)
+	if err != nil || data == "" {
+		return nil, err
+	}
+
+	var groups []linkGroup
+	if err := json.Unmarshal([]byte(data), &groups); err != nil {
+		return nil, fmt.Errorf("the project's parameter links are invalid: %v", err)
+	}
+	return groups, nil
+}
+
+// saveLinkGroups stores the current project's link groups, dropping empty ones
+func saveLinkGroups(groups []linkGroup) error {
+	var kept []linkGroup
+	for _, group := range groups {
+		if len(group.Members) > 0 {
+			kept = append(kept, group)
+		}
+	}
+	if len(kept) == 0 {
+		return reaper.DeleteProjExtState(linkSection, linkKey)
+	}
+
+	data, err := json.Marshal(kept)
+	if err != nil {
+		return fmt.Errorf("failed to encode the parameter links: %v", err)
+	}
+	return reaper.SetProjExtState(linkSection, linkKey, string(data))
+}
+
+// handleLinkLastTouched adds the last touched parameter to a link group
+func handleLinkLastTouched() {
+	const title = "Link Parameter"
+
+	// STEP 1: Identify the parameter
+	target, ok := lastTouchedParam(title)
+	if !ok {
+		return
+	}
+	member := linkMember{paramTarget: target}
+
+	groups, err := loadLinkGroups()
+	if err != nil {
+		core.HandleError(title, core.NewError(core.CategoryStorage, "Could not read the project's parameter links.", err))
+		return
+	}
+
+	// STEP 2: Ask for the group and the mapping
+	defaultGroup := "Link 1"
+	if len(groups) > 0 {
+		defaultGroup = groups[len(groups)-1].Name
+	}
+	values, err := reaper.GetUserInputs(title,
+		[]string{"Group name", "Scale", "Offset"},
+		[]string{defaultGroup, "1", "0"})
+	if err != nil {
+		logger.Debug("Linking cancelled")
+		return
+	}
+
+	name := strings.TrimSpace(values[0])
+	scale, scaleErr := strconv.ParseFloat(strings.TrimSpace(values[1]), 64)
+	offset, offsetErr := strconv.ParseFloat(strings.TrimSpace(values[2]), 64)
+	if name == "" || scaleErr != nil || offsetErr != nil || scale == 0 {
+		reaper.MessageBox("Enter a group name, a non-zero scale and an offset.", title)
+		return
+	}
+	member.Scale, member.Offset = scale, offset
+
+	// STEP 3: Add it, replacing the parameter's membership of any group
+	found := false
+	for i := range groups {
+		kept := groups[i].Members[:0]
+		for _, m := range groups[i].Members {
+			if m.key() != member.key() {
+				kept = append(kept, m)
+			}
+		}
+		groups[i].Members = kept
+
+		if strings.EqualFold(groups[i].Name, name) {
+			groups[i].Members = append(groups[i].Members, member)
+			found = true
+		}
+	}
+	if !found {
+		groups = append(groups, linkGroup{Name: name, Members: []linkMember{member}})
+	}
+
+	if err := saveLinkGroups(groups); err != nil {
+		core.HandleError(title, core.NewError(core.CategoryStorage, "Could not save the parameter links.", err))
+		return
+	}
+	logger.Info("Linked %s in group %q (scale %g, offset %g)", member.Label, name, scale, offset)
+}
+
+// handleParamLinks lists the project's link groups and removes one
+func handleParamLinks() {
+	const title = "Parameter Links"
+
+	groups, err := loadLinkGroups()
+	if err != nil {
+		core.HandleError(title, core.NewError(core.CategoryStorage, "Could not read the project's parameter links.", err))
+		return
+	}
+	if len(groups) == 0 {
+		reaper.MessageBox("This project has no parameter links. Move a parameter, then run \"Go: Link last touched FX parameter\" to link it.", title)
+		return
+	}
+
+	var builder strings.Builder
+	if !links.enabled {
+		builder.WriteString("Linking is switched off.\n\n")
+	}
+	for _, group := range groups {
+		builder.WriteString(group.Name + ":\n")
+		for _, m := range group.Members {
+			builder.WriteString(fmt.Sprintf("  %s (x%g %+g)\n", m.Label, m.Scale, m.Offset))
+		}
+	}
+	builder.WriteString("\nRemove a group?")
+
+	remove, err := reaper.YesNoBox(builder.String(), title)
+	if err != nil || !remove {
+		return
+	}
+
+	values, err := reaper.GetUserInputs(title, []string{"Group to remove"}, []string{groups[0].Name})
+	if err != nil {
+		return
+	}
+	name := strings.TrimSpace(values[0])
+
+	var kept []linkGroup
+	for _, group := range groups {
+		if !strings.EqualFold(group.Name, name) {
+			kept = append(kept, group)
+		}
+	}
+	if len(kept) == len(groups) {
+		reaper.MessageBox(fmt.Sprintf("There is no group named %q.", name), title)
+		return
+	}
+
+	if err := saveLinkGroups(kept); err != nil {
+		core.HandleError(title, core.NewError(core.CategoryStorage, "Could not save the parameter links.", err))
+		return
+	}
+	logger.Info("Removed parameter link group %q", name)
+}
@@ -0,0 +1,154 @@
+package actions
+
+import (
+	"fmt"
+	"go-reaper/src/pkg/config"
+	"go-reaper/src/reaper"
+	"strings"
+	"unicode"
+)
+
+// This file classifies FX parameters by how likely they are to matter for a
+// sound-shaping request, so the assistant's prompt can leave out those that don't.
+
+// paramRelevance says why a parameter is unlikely to matter, if it is
+type paramRelevance int
+
+const (
+	relevant              paramRelevance = iota
+	irrelevantPlaceholder                // Unnamed, unused or reserved slots
+	irrelevantBypass                     // Bypass and delta solo switches
+	irrelevantMIDI                       // MIDI CC, channel and program change parameters
+	irrelevantOffToggle                  // On/off switches that are off, unless the request names their stage
+)
+
+// relevanceNames describe each kind of left-out parameter in prompt notes
+var relevanceNames = map[paramRelevance]string{
+	irrelevantPlaceholder: "placeholder parameters",
+	irrelevantBypass:      "bypass switches",
+	irrelevantMIDI:        "MIDI parameters",
+	irrelevantOffToggle:   "switches that are off",
+}
+
+// offToggleValues are the formatted values of a switch that is off. Plugins ship with
+// optional stages switched off, so an off switch is usually at its default.
+var offToggleValues = map[string]bool{"off": true, "disabled": true, "no": true, "false": true, "inactive": true}
+
+// switchWords are the words of a switch's name that say it is a switch rather than
+// which stage it controls, e.g. "On" in "De-esser On"
+var switchWords = map[string]bool{"on": true, "off": true, "enable": true, "enabled": true, "active": true, "power": true, "switch": true, "in": true}
+
+// stageStemLength is how many letters of a stage name a request word must share to name
+// it, so "saturation" is named by "saturate" and "de-esser" by "de-essing"
+const stageStemLength = 5
+
+// classifyRelevance works out whether a parameter is likely to matter, from its name,
+// built-in identifier and formatted value. Parameters the heuristics give a role to, like
+// a drive or mix control, are always relevant.
+func classifyRelevance(param reaper.FXParameter) paramRelevance {
+	switch param.Ident {
+	case reaper.FXIdentBypass, reaper.FXIdentDelta:
+		return irrelevantBypass
+	case "":
+	default:
+		return relevant
+	}
+
+	name := strings.ToLower(strings.TrimSpace(param.Name))
+	if name == "" || name == "-" || name == "unused" || strings.HasPrefix(name, "reserved") {
+		return irrelevantPlaceholder
+	}
+	if strings.Contains(name, "bypass") || strings.Contains(name, "delta") {
+		return irrelevantBypass
+	}
+	for _, marker := range []string{"midi cc", "midi ch", "program change"} {
+		if strings.Contains(name, marker) {
+			return irrelevantMIDI
+		}
+	}
+	if offToggleValues[strings.ToLower(strings.TrimSpace(param.FormattedValue))] && classifyParameter("", param, nil) == roleUnknown {
+		return irrelevantOffToggle
+	}
+
+	return relevant
+}
+
+// requestRelevance is classifyRelevance for a request: a switch that is off stays in
+// when the request names the stage it controls, so the LLM can turn it on
+func requestRelevance(param reaper.FXParameter, request string) paramRelevance {
+	relevance := classifyRelevance(param)
+	if relevance == irrelevantOffToggle && namesStage(request, param.Name) {
+		return relevant
+	}
+	return relevance
+}
+
+// namesStage reports whether a request names the stage a switch controls, e.g.
+// "tame the sibilance with the de-esser" for "De-Esser On"
+func namesStage(request string, switchName string) bool {
+	requestStems := make(map[string]bool)
+	for _, word := range stageWords(request) {
+		requestStems[stageStem(word)] = true
+	}
+	for _, word := range stageWords(switchName) {
+		if !switchWords[word] && len(word) >= 3 && requestStems[stageStem(word)] {
+			return true
+		}
+	}
+	return false
+}
+
+// stageWords splits text into lower case words, joining hyphenated words ("de-esser"
+// becomes "deesser")
+func stageWords(text string) []string {
+	text = strings.ReplaceAll(strings.ToLower(text), "-", "")
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// stageStem shortens a word to its first stageStemLength letters
+func stageStem(word string) string {
+	if len(word) > stageStemLength {
+		return word[:stageStemLength]
+	}
+	return word
+}
+
+// isBoilerplateParameter reports whether a parameter is unlikely to matter for sound-shaping requests
+func isBoilerplateParameter(param reaper.FXParameter) bool {
+	switch classifyRelevance(param) {
+	case irrelevantPlaceholder, irrelevantBypass, irrelevantMIDI:
+		return true
+	default:
+		return false
+	}
+}
+
+// skipsRelevance reports whether the analyzer settings leave out parameters of a kind
+func skipsRelevance(analyzer config.AnalyzerSettings, relevance paramRelevance) bool {
+	switch relevance {
+	case irrelevantPlaceholder:
+		return analyzer.FilterBoilerplate
+	case irrelevantBypass:
+		return analyzer.FilterBoilerplate || analyzer.SkipBypass
+	case irrelevantMIDI:
+		return analyzer.FilterBoilerplate || analyzer.SkipMIDI
+	case irrelevantOffToggle:
+		return analyzer.SkipOffToggles
+	default:
+		return false
+	}
+}
+
+// skippedParametersNote says how many parameters of each kind were left out, e.g.
+// "Left out as unlikely to matter: bypass switches (2), MIDI parameters (16)."
+func skippedParametersNote(skipped map[paramRelevance]int) string {
+	var parts []string
+	for _, relevance := range []paramRelevance{irrelevantBypass, irrelevantMIDI, irrelevantOffToggle, irrelevantPlaceholder} {
+		if count := skipped[relevance]; count > 0 {
+			parts = append(parts, fmt.Sprintf("%s (%d)", relevanceNames[relevance], count))
+		}
+	}
+	return "Left out as unlikely to matter: " + strings.Join(parts, ", ") + "."
+}
@@ -0,0 +1,239 @@
+package actions
+
+import (
+	"errors"
+	"fmt"
+	"go-reaper/src/core"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"go-reaper/src/ui"
+	"html"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// This file renders a report of the selected tracks' FX and their parameters as shown
+// in the plugins, in Markdown or HTML, to share with collaborators or paste into an LLM
+// chat outside REAPER.
+
+// reportsDirName is the folder in the extension's data folder that reports are saved in
+const reportsDirName = "Reports"
+
+// Report formats
+const (
+	reportFormatMarkdown = "md"
+	reportFormatHTML     = "html"
+)
+
+// The parameter state report action
+func init() {
+	registerAction(Action{
+		ID:      "GO_PARAM_REPORT",
+		Name:    "Go: Parameter state report of selected tracks",
+		Handler: handleParameterReport,
+	})
+}
+
+// handleParameterReport asks how to render the report, then saves or copies it
+func handleParameterReport() {
+	const title = "Parameter State Report"
+
+	// STEP 1: Get the selected tracks
+	tracks, err := reaper.GetSelectedTracks()
+	if err != nil {
+		core.HandleError(title, core.NewError(core.CategoryReaper, "Could not read the selected tracks.", err))
+		return
+	}
+	if len(tracks) == 0 {
+		reaper.MessageBox("Select the tracks to report on first.", title)
+		return
+	}
+
+	// STEP 2: Ask for the format and where the report goes
+	values, err := reaper.GetUserInputs(title,
+		[]string{"Format (md or html)", "Key parameters only (y/n)", "Copy to clipboard instead of saving (y/n)"},
+		[]string{reportFormatMarkdown, "y", "n"})
+	if err != nil {
+		logger.Debug("Parameter report cancelled")
+		return
+	}
+	format := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(values[0])), ".")
+	if format == "markdown" {
+		format = reportFormatMarkdown
+	}
+	if format != reportFormatMarkdown && format != reportFormatHTML {
+		reaper.MessageBox("Enter md for Markdown or html for HTML.", title)
+		return
+	}
+	keyOnly := strings.HasPrefix(strings.ToLower(strings.TrimSpace(values[1])), "y")
+	toClipboard := strings.HasPrefix(strings.ToLower(strings.TrimSpace(values[2])), "y")
+
+	// STEP 3: Read the chains; placeholders are never worth listing
+	chains := collectTrackChains(tracks, func(param reaper.FXParameter) bool {
+		relevance := classifyRelevance(param)
+		if keyOnly {
+			return relevance == relevant
+		}
+		return relevance != irrelevantPlaceholder
+	})
+
+	now := time.Now()
+	var report string
+	if format == reportFormatHTML {
+		report = formatHTMLReport(chains, now)
+	} else {
+		report = formatMarkdownReport(chains, now)
+	}
+
+	// STEP 4: Copy or save it
+	if toClipboard {
+		err := ui.SetClipboardText(report)
+		if err == nil {
+			logger.Info("Copied a %s parameter report of %d track(s) to the clipboard", format, len(chains))
+			reaper.MessageBox(fmt.Sprintf("Copied the report of %d track(s) to the clipboard.", len(chains)), title)
+			return
+		}
+		if !errors.Is(err, reaper.ErrSWSNotInstalled) {
+			core.HandleError(title, core.NewError(core.CategoryReaper, "Could not copy the report to the clipboard.", err))
+			return
+		}
+		logger.Info("Clipboard unavailable without SWS, saving the report instead")
+	}
+
+	path, err := saveReport(report, format, now)
+	if err != nil {
+		core.HandleError(title, core.NewError(core.CategoryStorage, "Could not save the report.", err).
+			WithDetail("path %s", path))
+		return
+	}
+	logger.Info("Saved a %s parameter report of %d track(s) to %s", format, len(chains), path)
+
+	message := fmt.Sprintf("Saved the report of %d track(s) to %s", len(chains), path)
+	if toClipboard {
+		message = "Copying needs the SWS extension on this system, so the report was saved instead.\n\n" + message
+	}
+	reaper.MessageBox(message, title)
+}
+
+// saveReport writes a report to the reports folder under a name from its time, and
+// returns its path
+func saveReport(report, format string, now time.Time) (string, error) {
+	dir := filepath.Join(extensionDataDir(), reportsDirName)
+	path := filepath.Join(dir, fmt.Sprintf("FX report %s.%s", now.Format("2006-01-02 150405"), format))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return path, err
+	}
+	return path, os.WriteFile(path, []byte(report), 0o644)
+}
+
+// fxReportState describes an FX's bypass or offline state, or returns "" if it is active
+func fxReportState(fx reaper.FXInfo) string {
+	switch {
+	case fx.Offline:
+		return "offline"
+	case !fx.Enabled:
+		return "bypassed"
+	default:
+		return ""
+	}
+}
+
+// reportValue is a parameter's value as the plugin shows it, or its normalized value
+// if the plugin shows none
+func reportValue(param reaper.FXParameter) string {
+	if value := strings.TrimSpace(param.FormattedValue); value != "" {
+		return value
+	}
+	return fmt.Sprintf("%.3f", param.Value)
+}
+
+// formatMarkdownReport renders the chains as Markdown with a table per FX
+func formatMarkdownReport(chains []trackChain, now time.Time) string {
+	// Pipes would end a table cell early
+	cell := strings.NewReplacer("|", `\|`, "\n", " ").Replace
+
+	var builder strings.Builder
+	builder.WriteString("# FX Parameter Report\n\n")
+	builder.WriteString(fmt.Sprintf("%d track(s), %s\n", len(chains), now.Format("2 January 2006 15:04")))
+
+	for _, chain := range chains {
+		builder.WriteString(fmt.Sprintf("\n## %s\n", chain.Name))
+		if len(chain.FX) == 0 {
+			builder.WriteString("\nNo FX.\n")
+			continue
+		}
+
+		for position, fx := range chain.FX {
+			heading := fmt.Sprintf("%d. %s%s", position+1, fx.Name, describePlugin(fx))
+			if state := fxReportState(fx); state != "" {
+				heading += " (" + state + ")"
+			}
+			builder.WriteString(fmt.Sprintf("\n### %s\n\n", heading))
+
+			if len(fx.Parameters) == 0 {
+				builder.WriteString("No parameters listed.\n")
+				continue
+			}
+			builder.WriteString("| Parameter | Value |\n|---|---|\n")
+			for _, param := range fx.Parameters {
+				builder.WriteString(fmt.Sprintf("| %s | %s |\n", cell(param.Name), cell(reportValue(param))))
+			}
+		}
+	}
+	return builder.String()
+}
+
+// formatHTMLReport renders the chains as a standalone HTML page with a table per FX
+func formatHTMLReport(chains []trackChain, now time.Time) string {
+	escape := html.EscapeString
+
+	var builder strings.Builder
+	builder.WriteString(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>FX Parameter Report</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { border: 1px solid #ccc; padding: 4px 10px; text-align: left; }
+th { background: #f2f2f2; }
+.state { color: #a33; font-weight: normal; }
+</style>
+</head>
+<body>
+<h1>FX Parameter Report</h1>
+`)
+	builder.WriteString(fmt.Sprintf("<p>%d track(s), %s</p>\n", len(chains), escape(now.Format("2 January 2006 15:04"))))
+
+	for _, chain := range chains {
+		builder.WriteString(fmt.Sprintf("<h2>%s</h2>\n", escape(chain.Name)))
+		if len(chain.FX) == 0 {
+			builder.WriteString("<p>No FX.</p>\n")
+			continue
+		}
+
+		for position, fx := range chain.FX {
+			builder.WriteString(fmt.Sprintf("<h3>%d. %s", position+1, escape(fx.Name+describePlugin(fx))))
+			if state := fxReportState(fx); state != "" {
+				builder.WriteString(fmt.Sprintf(` <span class="state">(%s)</span>`, state))
+			}
+			builder.WriteString("</h3>\n")
+
+			if len(fx.Parameters) == 0 {
+				builder.WriteString("<p>No parameters listed.</p>\n")
+				continue
+			}
+			builder.WriteString("<table>\n<tr><th>Parameter</th><th>Value</th></tr>\n")
+			for _, param := range fx.Parameters {
+				builder.WriteString(fmt.Sprintf("<tr><td>%s</td><td>%s</td></tr>\n", escape(param.Name), escape(reportValue(param))))
+			}
+			builder.WriteString("</table>\n")
+		}
+	}
+
+	builder.WriteString("</body>\n</html>\n")
+	return builder.String()
+}
@@ -0,0 +1,102 @@
+package actions
+
+import (
+	"fmt"
+	"go-reaper/src/core"
+	"go-reaper/src/reaper"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// paramTarget is an FX parameter stored by track and FX GUID, so features that keep
+// hold of parameters, like links and MIDI bindings, survive moving tracks and FX
+type paramTarget struct {
+	TrackGUID  string `json:"track_guid"`
+	FXGUID     string `json:"fx_guid"`
+	ParamIndex int    `json:"param_index"`
+	Label      string `json:"label"` // "Track • FX • Parameter" when stored, for display
+}
+
+// key identifies the target's parameter
+func (t paramTarget) key() string {
+	return t.TrackGUID + "|" + t.FXGUID + "|" + strconv.Itoa(t.ParamIndex)
+}
+
+// lastTouchedParam returns the parameter the user moved last as a target, reporting
+// errors under title
+func lastTouchedParam(title string) (paramTarget, bool) {
+	track, fxIndex, paramIndex, err := reaper.GetLastTouchedFX()
+	if err != nil {
+		core.HandleError(title, core.NewError(core.CategoryUser, "Move the FX parameter first, then run this action.", err))
+		return paramTarget{}, false
+	}
+	trackGUID, err := reaper.GetTrackGUID(track)
+	if err != nil {
+		core.HandleError(title, core.NewError(core.CategoryReaper, "Could not identify the track.", err))
+		return paramTarget{}, false
+	}
+	fxGUID, err := reaper.GetTrackFXGUID(track, fxIndex)
+	if err != nil {
+		core.HandleError(title, core.NewError(core.CategoryReaper, "Could not identify the FX.", err))
+		return paramTarget{}, false
+	}
+
+	trackName, _ := reaper.GetTrackName(track)
+	fxName, _ := reaper.GetTrackFXName(track, fxIndex)
+	paramName, _ := reaper.GetTrackFXParamName(track, fxIndex, paramIndex)
+	return paramTarget{
+		TrackGUID:  trackGUID,
+		FXGUID:     fxGUID,
+		ParamIndex: paramIndex,
+		Label:      fmt.Sprintf("%s • %s • %s", trackName, fxName, paramName),
+	}, true
+}
+
+// fxLocation is where a target's FX was last found
+type fxLocation struct {
+	track   unsafe.Pointer
+	fxIndex int
+}
+
+// fxLocator finds targets' FX, remembering where each was found so polling doesn't
+// search the project every time. It is only used on the main thread.
+type fxLocator struct {
+	found map[string]fxLocation // By track and FX GUID
+}
+
+// locate finds a target's track and FX, checking the last place it was found first.
+// It fails while the track or FX is gone.
+func (l *fxLocator) locate(target paramTarget) (fxLocation, bool) {
+	if l.found == nil {
+		l.found = make(map[string]fxLocation)
+	}
+
+	key := target.TrackGUID + "|" + target.FXGUID
+	if at, ok := l.found[key]; ok && reaper.IsTrackValid(at.track) {
+		if guid, err := reaper.GetTrackFXGUID(at.track, at.fxIndex); err == nil && strings.EqualFold(guid, target.FXGUID) {
+			return at, true
+		}
+	}
+
+	ref := reaper.TrackRef{GUID: target.TrackGUID}
+	track, err := ref.Resolve()
+	if err != nil {
+		delete(l.found, key)
+		return fxLocation{}, false
+	}
+	fxIndex, err := reaper.FindTrackFXByGUID(track, target.FXGUID)
+	if err != nil {
+		delete(l.found, key)
+		return fxLocation{}, false
+	}
+
+	at := fxLocation{track: track, fxIndex: fxIndex}
+	l.found[key] = at
+	return at, true
+}
+
+// reset forgets where FX were found, e.g. after switching projects
+func (l *fxLocator) reset() {
+	l.found = nil
+}
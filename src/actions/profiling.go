@@ -0,0 +1,79 @@
+package actions
+
+import (
+	"fmt"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"strings"
+	"time"
+)
+
+// profileReportFunctions is how many functions the diagnostics list, by total time
+const profileReportFunctions = 15
+
+// The action that turns bridge call profiling on and off
+func init() {
+	registerAction(Action{
+		ID:          "GO_PROFILE_BRIDGE",
+		Name:        "Go: Profile Bridge Calls (Toggle)",
+		Handler:     handleToggleProfiling,
+		ToggleState: reaper.IsProfiling,
+	})
+}
+
+// handleToggleProfiling starts profiling, or stops it and prints what was collected
+func handleToggleProfiling() {
+	if !reaper.IsProfiling() {
+		reaper.SetProfiling(true)
+		logger.Info("Bridge call profiling started")
+		reaper.Console.Print("Bridge call profiling started. Run the toggle again, or Extension Diagnostics, to see the counts.")
+		return
+	}
+
+	reaper.SetProfiling(false)
+	report := profileReport()
+	logger.Info("Bridge call profiling stopped:\n%s", report)
+
+	reaper.Console.Section("Go Extension Bridge Profile")
+	reaper.Console.Print(report)
+}
+
+// profileReport describes the bridge calls counted since profiling was last started
+func profileReport() string {
+	profile := reaper.GetProfile()
+	if profile.Duration == 0 {
+		return "Profiling has not been run (Go: Profile Bridge Calls)\n"
+	}
+
+	var b strings.Builder
+
+	state := "stopped"
+	if profile.Enabled {
+		state = "running"
+	}
+	fmt.Fprintf(&b, "Profiling: %s, %s\n", state, profile.Duration.Round(time.Second))
+	fmt.Fprintf(&b, "GetFunc lookups: %d\n", profile.Lookups)
+
+	calls := profile.BatchCalls + profile.SingleCalls
+	fmt.Fprintf(&b, "FX calls: %d batch, %d single", profile.BatchCalls, profile.SingleCalls)
+	if profile.BatchCalls > 0 {
+		fmt.Fprintf(&b, " (%.1f single per batch)", float64(profile.SingleCalls)/float64(profile.BatchCalls))
+	}
+	b.WriteString("\n")
+	if calls == 0 {
+		return b.String()
+	}
+
+	functions := profile.Functions
+	if len(functions) > profileReportFunctions {
+		functions = functions[:profileReportFunctions]
+	}
+	for _, f := range functions {
+		fmt.Fprintf(&b, "  %-34s %8d calls %10s total %9s avg\n",
+			f.Function, f.Calls, f.Total.Round(time.Microsecond), f.Average().Round(100*time.Nanosecond))
+	}
+	if len(profile.Functions) > len(functions) {
+		fmt.Fprintf(&b, "  ... and %d more\n", len(profile.Functions)-len(functions))
+	}
+	return b.String()
+}
@@ -0,0 +1,198 @@
+package actions
+
+import (
+	"fmt"
+	"go-reaper/src/llm"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"strings"
+	"unicode"
+)
+
+// This file splits the assistant's prompt over several requests when the selected FX
+// have more parameters than fit in one, and merges the suggestions from each part.
+
+// maxPromptParts caps the requests one assistant run is split into
+const maxPromptParts = 8
+
+// partNoteFormat tells the LLM that it sees only some of the parameters
+const partNoteFormat = "\n(Part %d of %d: the other parameters of these FX are sent separately. Only suggest changes to the parameters listed here.)\n"
+
+// buildUserPrompts builds the user prompt, progressively shortening the parameter listing
+// until its estimated size fits the token budget. If it doesn't fit with every parameter
+// that matters, the parameters are split over several prompts, keeping groups such as
+// an EQ band's together. It returns the prompts and human-readable notes describing
+// what was changed.
+func buildUserPrompts(input promptInput, budget int) ([]string, []string) {
+	input, prompt, notes, fits := fitUserPrompt(input, budget)
+	if fits {
+		return []string{prompt}, notes
+	}
+
+	parts := splitPromptInput(input, budget)
+	if len(parts) < 2 {
+		// Some part can't fit on its own, so splitting doesn't help
+		prompt, notes := capUserPrompt(input, budget, notes)
+		return []string{prompt}, notes
+	}
+	if len(parts) > maxPromptParts {
+		notes = append(notes, fmt.Sprintf("Only the first %d of %d parts were sent.", maxPromptParts, len(parts)))
+		parts = parts[:maxPromptParts]
+	}
+
+	prompts := make([]string, len(parts))
+	for i, part := range parts {
+		part.Part = fmt.Sprintf(partNoteFormat, i+1, len(parts))
+		prompts[i] = buildUserPromptDetail(part, promptDetailFiltered, 0)
+	}
+	logger.Info("Prompt split into %d parts to fit %d token budget", len(parts), budget)
+	return prompts, append(notes, fmt.Sprintf("The parameters were sent in %d requests and the suggestions merged.", len(parts)))
+}
+
+// splitPromptInput packs the parameters into as few parts as fit the budget, in order.
+// The chain overview goes with the first part only. It returns nil if a single
+// parameter doesn't fit on its own.
+func splitPromptInput(input promptInput, budget int) []promptInput {
+	// Measure with the longest part note, so numbering the parts can't push one over
+	longestNote := fmt.Sprintf(partNoteFormat, maxPromptParts, maxPromptParts)
+
+	var parts []promptInput
+	current := promptInput{TrackName: input.TrackName, Chain: input.Chain, Items: input.Items, Sections: input.Sections, Part: longestNote, Request: input.Request}
+
+	fits := func(part promptInput) bool {
+		return llm.EstimateTokens(buildUserPromptDetail(part, promptDetailFiltered, 0)) <= budget
+	}
+
+	// place adds parameters to the current part, or to a new one if they don't fit
+	place := func(fx reaper.FXInfo, params []reaper.FXParameter) bool {
+		if candidate := withParameters(current, fx, params); fits(candidate) {
+			current = candidate
+			return true
+		}
+		if len(current.FXList) == 0 {
+			return false
+		}
+
+		parts = append(parts, current)
+		current = promptInput{TrackName: input.TrackName, Part: longestNote, Request: input.Request}
+		if candidate := withParameters(current, fx, params); fits(candidate) {
+			current = candidate
+			return true
+		}
+		return false
+	}
+
+	for _, fx := range input.FXList {
+		for _, group := range parameterGroups(fx.Parameters) {
+			if place(fx, group) {
+				continue
+			}
+			// The group is too big for any part, so its parameters go one by one
+			for _, param := range group {
+				if !place(fx, []reaper.FXParameter{param}) {
+					return nil
+				}
+			}
+		}
+	}
+	if len(current.FXList) > 0 {
+		parts = append(parts, current)
+	}
+	return parts
+}
+
+// withParameters returns a copy of a part with parameters of fx added
+func withParameters(part promptInput, fx reaper.FXInfo, params []reaper.FXParameter) promptInput {
+	fxList := append([]reaper.FXInfo(nil), part.FXList...)
+	if last := len(fxList) - 1; last >= 0 && fxList[last].Index == fx.Index {
+		fxList[last].Parameters = append(append([]reaper.FXParameter(nil), fxList[last].Parameters...), params...)
+	} else {
+		fx.Parameters = append([]reaper.FXParameter(nil), params...)
+		fxList = append(fxList, fx)
+	}
+	part.FXList = fxList
+	return part
+}
+
+// parameterGroups splits parameters into runs that belong together, e.g. "Band 2 Freq",
+// "Band 2 Gain" and "Band 2 Q", so a group isn't split over two prompts
+func parameterGroups(params []reaper.FXParameter) [][]reaper.FXParameter {
+	var groups [][]reaper.FXParameter
+	lastKey := ""
+	for _, param := range params {
+		key := parameterGroupKey(param.Name)
+		if len(groups) == 0 || key == "" || key != lastKey {
+			groups = append(groups, nil)
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], param)
+		lastKey = key
+	}
+	return groups
+}
+
+// parameterGroupKey is the part of a parameter's name it shares with its group: the
+// words up to the first number ("band 2" for "Band 2 Gain"), or else the first of
+// several words ("comp" for "Comp Ratio"). Single words stand alone.
+func parameterGroupKey(name string) string {
+	words := strings.Fields(strings.ToLower(name))
+	for i, word := range words {
+		if strings.IndexFunc(word, unicode.IsDigit) >= 0 {
+			return strings.Join(words[:i+1], " ")
+		}
+	}
+	if len(words) > 1 {
+		return words[0]
+	}
+	return ""
+}
+
+// askAssistantInParts sends each part of a split prompt in turn and merges the
+// suggestions. onPart, if set, is called before each part is sent.
+func askAssistantInParts(client llm.Client, systemPrompt string, prompts []string, onPart func(part, parts int)) (*AssistantResponse, error) {
+	responses := make([]*AssistantResponse, 0, len(prompts))
+	for i, prompt := range prompts {
+		if onPart != nil {
+			onPart(i+1, len(prompts))
+		}
+
+		responseText, err := sendAssistantPrompt(client, systemPrompt, prompt)
+		if err != nil {
+			return nil, fmt.Errorf("part %d of %d: %w", i+1, len(prompts), err)
+		}
+		logger.Info("LLM Response (part %d of %d): %s", i+1, len(prompts), responseText)
+
+		response, err := parseAssistantResponse(responseText)
+		if err != nil {
+			return nil, fmt.Errorf("part %d of %d: %w", i+1, len(prompts), err)
+		}
+		responses = append(responses, response)
+	}
+	return mergeAssistantResponses(responses), nil
+}
+
+// mergeAssistantResponses combines the responses to the parts of a split prompt. A
+// parameter suggested in more than one part keeps its first suggestion.
+func mergeAssistantResponses(responses []*AssistantResponse) *AssistantResponse {
+	merged := &AssistantResponse{}
+	seen := make(map[[2]int]bool)
+	var reasoning []string
+
+	for i, response := range responses {
+		for _, suggestion := range response.Suggestions {
+			key := [2]int{suggestion.FXIndex, suggestion.ParamIndex}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			merged.Suggestions = append(merged.Suggestions, suggestion)
+		}
+		merged.ChainChanges = append(merged.ChainChanges, response.ChainChanges...)
+		merged.ItemChanges = append(merged.ItemChanges, response.ItemChanges...)
+		if text := strings.TrimSpace(response.Reasoning); text != "" {
+			reasoning = append(reasoning, fmt.Sprintf("Part %d: %s", i+1, text))
+		}
+	}
+
+	merged.Reasoning = strings.Join(reasoning, "\n\n")
+	return merged
+}
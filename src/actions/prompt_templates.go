@@ -0,0 +1,238 @@
+package actions
+
+import (
+	"fmt"
+	"go-reaper/src/core"
+	"go-reaper/src/pkg/config"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Template file names used by the template editor
+const (
+	systemTemplateFileName = "system_prompt.txt"
+	userTemplateFileName   = "user_prompt.txt"
+)
+
+// defaultSystemTemplate is the built-in system prompt
+const defaultSystemTemplate = `You are an audio engineer assistant that helps adjust effects (FX) parameters in a digital audio workstation.
+You will be given information about one or more audio effects including their names and parameters.
+You will also receive a user request about how they want to adjust the sound.
+
+Your task is to suggest parameter adjustments that will help achieve the user's request.
+
+IMPORTANT RULES:
+1. Only suggest adjustments to the parameters provided.
+2. Always return values within the normalized range (0.0 to 1.0).
+3. Always format your response as valid JSON with this structure:
+{
+  "suggestions": [
+    {
+      "fx_index": <integer index of the effect>,
+      "param_index": <integer index of the parameter>,
+      "param_name": "<name of the parameter>",
+      "value": <new value between 0.0 and 1.0>,
+      "note_length": "<optional tempo-synced note length such as 1/4, 1/8 dotted or 1/16 triplet>",
+      "unit_value": <optional value in the parameter's displayed units, e.g. -12 for "-12.0 dB" or 2500 for "2500 Hz">,
+      "explanation": "<brief explanation of this adjustment>"
+    }
+  ],
+  "reasoning": "<your overall explanation of the parameter adjustments>"
+}
+
+4. Keep explanations concise but technically accurate.
+5. Only include parameters you are adjusting in the suggestions array.
+6. Focus on achieving the user's sonic goals with the minimum necessary adjustments.
+7. The JSON must be valid and complete.
+8. For time-based parameters (delay times, LFO rates) that should follow the project tempo, set "note_length" instead of estimating the normalized value; omit it otherwise.
+9. When you know the target in real units (Hz, dB, ms, octaves), set "unit_value" as well as an estimated "value"; omit it otherwise.
+10. "Wet (FX mix)" blends the whole effect with the dry signal (1.0 = fully wet); use it for blend changes such as parallel processing.`
+
+// defaultUserTemplate is the built-in user prompt
+const defaultUserTemplate = `Here are the audio effects on track "{{track}}" and their current parameters:
+
+{{fx_list}}User request: {{request}}
+
+Please suggest parameter adjustments that will help achieve this request. Remember to format your response as JSON according to the specified structure.`
+
+// Placeholders available in each template. {{fx_list}} is only offered in the user
+// template because its size is managed against the model's context window.
+var (
+	systemTemplateVariables = []string{"track", "request"}
+	userTemplateVariables   = []string{"track", "fx_list", "request"}
+)
+
+// placeholderPattern matches {{name}} placeholders, allowing inner whitespace
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z_]+)\s*\}\}`)
+
+// The prompt template editor action
+func init() {
+	registerAction(Action{
+		ID:      "GO_FX_ASSISTANT_TEMPLATES",
+		Feature: config.FeatureAssistant,
+		Name:    "Go: Edit LLM FX Assistant Prompt Templates",
+		Handler: handleEditPromptTemplates,
+	})
+}
+
+// systemPromptTemplate returns the configured system template or the built-in one
+func systemPromptTemplate() string {
+	systemTemplate, _ := config.GetPromptTemplates()
+	if strings.TrimSpace(systemTemplate) == "" {
+		return defaultSystemTemplate
+	}
+	return systemTemplate
+}
+
+// userPromptTemplate returns the configured user template or the built-in one
+func userPromptTemplate() string {
+	_, userTemplate := config.GetPromptTemplates()
+	if strings.TrimSpace(userTemplate) == "" {
+		return defaultUserTemplate
+	}
+	return userTemplate
+}
+
+// renderPromptTemplate substitutes {{name}} placeholders with their values.
+// Unknown placeholders are left as-is so mistakes are visible in the logged prompt.
+func renderPromptTemplate(template string, variables map[string]string) string {
+	return placeholderPattern.ReplaceAllStringFunc(template, func(match string) string {
+		name := placeholderPattern.FindStringSubmatch(match)[1]
+		if value, ok := variables[name]; ok {
+			return value
+		}
+		return match
+	})
+}
+
+// validatePromptTemplate checks a template only uses allowed placeholders and includes the required ones
+func validatePromptTemplate(template string, allowed []string, required []string) error {
+	allowedSet := make(map[string]bool, len(allowed))
+	for _, name := range allowed {
+		allowedSet[name] = true
+	}
+
+	found := make(map[string]bool)
+	for _, match := range placeholderPattern.FindAllStringSubmatch(template, -1) {
+		name := match[1]
+		if !allowedSet[name] {
+			return fmt.Errorf("unknown placeholder {{%s}} (available: %s)", name, formatPlaceholders(allowed))
+		}
+		found[name] = true
+	}
+
+	for _, name := range required {
+		if !found[name] {
+			return fmt.Errorf("missing required placeholder {{%s}}", name)
+		}
+	}
+
+	return nil
+}
+
+// formatPlaceholders lists placeholder names as {{a}}, {{b}}
+func formatPlaceholders(names []string) string {
+	formatted := make([]string, len(names))
+	for i, name := range names {
+		formatted[i] = "{{" + name + "}}"
+	}
+	return strings.Join(formatted, ", ")
+}
+
+// handleEditPromptTemplates writes the current templates to text files, lets the user
+// edit them in any text editor, then loads the edited versions into the settings
+func handleEditPromptTemplates() {
+	dir := filepath.Join(extensionDataDir(), "templates")
+	systemPath := filepath.Join(dir, systemTemplateFileName)
+	userPath := filepath.Join(dir, userTemplateFileName)
+
+	// STEP 1: Export the current templates
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		core.HandleError("Prompt Templates", core.NewError(core.CategoryStorage, "Failed to create the template folder.", err).
+			WithDetail("directory %s", dir))
+		return
+	}
+
+	if err := os.WriteFile(systemPath, []byte(systemPromptTemplate()), 0o644); err != nil {
+		core.HandleError("Prompt Templates", core.NewError(core.CategoryStorage, "Failed to write "+systemTemplateFileName+".", err))
+		return
+	}
+
+	if err := os.WriteFile(userPath, []byte(userPromptTemplate()), 0o644); err != nil {
+		core.HandleError("Prompt Templates", core.NewError(core.CategoryStorage, "Failed to write "+userTemplateFileName+".", err))
+		return
+	}
+
+	// STEP 2: Let the user edit the files
+	msg := fmt.Sprintf("The current prompt templates have been saved to:\n\n%s\n\n"+
+		"Edit %s and %s in a text editor and save them, then click Yes to load them.\n\n"+
+		"System template placeholders: %s\n"+
+		"User template placeholders: %s (must include {{fx_list}} and {{request}})\n\n"+
+		"Empty a file to restore its built-in template. Click No to keep the current templates.",
+		dir, systemTemplateFileName, userTemplateFileName,
+		formatPlaceholders(systemTemplateVariables), formatPlaceholders(userTemplateVariables))
+
+	load, err := reaper.YesNoBox(msg, "Prompt Templates")
+	if err != nil || !load {
+		logger.Debug("Prompt template editing cancelled")
+		return
+	}
+
+	// STEP 3: Read and validate the edited templates
+	systemTemplate, err := readPromptTemplate(systemPath, defaultSystemTemplate)
+	if err != nil {
+		core.HandleError("Prompt Templates", core.NewError(core.CategoryStorage, "Failed to read "+systemTemplateFileName+".", err))
+		return
+	}
+
+	userTemplate, err := readPromptTemplate(userPath, defaultUserTemplate)
+	if err != nil {
+		core.HandleError("Prompt Templates", core.NewError(core.CategoryStorage, "Failed to read "+userTemplateFileName+".", err))
+		return
+	}
+
+	// Empty templates mean the built-in ones, which are always valid
+	if systemTemplate != "" {
+		if err := validatePromptTemplate(systemTemplate, systemTemplateVariables, nil); err != nil {
+			reaper.MessageBox(fmt.Sprintf("The templates were not saved.\n\nSystem template: %v", err), "Prompt Templates")
+			return
+		}
+	}
+
+	if userTemplate != "" {
+		if err := validatePromptTemplate(userTemplate, userTemplateVariables, []string{"fx_list", "request"}); err != nil {
+			reaper.MessageBox(fmt.Sprintf("The templates were not saved.\n\nUser template: %v", err), "Prompt Templates")
+			return
+		}
+	}
+
+	// STEP 4: Save to settings
+	if err := config.SetPromptTemplates(systemTemplate, userTemplate); err != nil {
+		core.HandleError("Prompt Templates", core.NewError(core.CategoryStorage, "Failed to save the prompt templates to the settings.", err))
+		return
+	}
+
+	logger.Info("Prompt templates updated (custom system: %v, custom user: %v)", systemTemplate != "", userTemplate != "")
+	reaper.MessageBox("The prompt templates have been saved.", "Prompt Templates")
+}
+
+// readPromptTemplate reads an edited template file. Returns an empty string when the
+// file is empty or matches the built-in template, so the built-in one stays in use.
+func readPromptTemplate(path string, builtIn string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	// Normalize Windows line endings from external editors
+	template := strings.ReplaceAll(string(data), "\r\n", "\n")
+	if strings.TrimSpace(template) == "" || strings.TrimSpace(template) == strings.TrimSpace(builtIn) {
+		return "", nil
+	}
+
+	return template, nil
+}
@@ -1,30 +1,73 @@
 package actions
 
 import (
+	"fmt"
+	"go-reaper/src/core"
+	"go-reaper/src/pkg/config"
 	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
 )
 
-// RegisterAll registers all actions
+// Action describes one of the extension's actions. Each action file declares its actions
+// with registerAction from init, and RegisterAll registers them with REAPER.
+type Action struct {
+	ID      string // Unique command ID, e.g. GO_FX_ASSISTANT
+	Name    string // Name shown in the action list
+	Section int    // Action list section; the zero value is reaper.SectionMain
+	Feature string // Feature flag that turns it off, e.g. config.FeatureAssistant; "" for none
+	Handler reaper.ActionHandler
+
+	// ContextHandler is used instead of Handler when set, for actions that need to know
+	// how they were triggered, e.g. MIDI CC values
+	ContextHandler reaper.ContextActionHandler
+
+	// ToggleState, if set, makes this a toggle action and reports whether it is on, for
+	// toolbar buttons and menu check marks
+	ToggleState func() bool
+}
+
+var (
+	// declaredActions holds the actions declared by init, in declaration order
+	declaredActions []Action
+	// registeredActions records which declared actions were registered with REAPER
+	registeredActions = make(map[string]bool)
+)
+
+// registerAction declares an action to be registered by RegisterAll. Call it from init.
+func registerAction(action Action) {
+	declaredActions = append(declaredActions, action)
+}
+
+// RegisterAll registers all declared actions, except those disabled in the settings or
+// belonging to a feature that is turned off
 func RegisterAll() error {
 	logger.Debug("----------------------------------------------------------")
 	logger.Debug("Registering Go REAPER extension actions...")
 
-	// Register FX Assistant (LLM FX Assistant)
-	if err := RegisterFXAssistant(); err != nil {
-		return err
-	}
+	applyReportingSettings()
 
-	// Register Native UI action
-	if err := RegisterNativeWindow(); err != nil {
-		return err
+	for _, action := range declaredActions {
+		if !config.IsActionEnabled(action.ID) {
+			logger.Info("Skipping disabled action %s", action.ID)
+			continue
+		}
+		if !config.IsFeatureEnabled(action.Feature) {
+			logger.Info("Skipping action %s, the %s feature is turned off", action.ID, action.Feature)
+			continue
+		}
+		if err := registerWithReaper(action); err != nil {
+			return err
+		}
 	}
 
-	if err := RegisterKeyringTest(); err != nil {
+	// Each macro from the settings is an action too
+	registerMacros()
+
+	// Add the registered actions to the Extensions menu
+	if err := RegisterExtensionsMenu(); err != nil {
 		return err
 	}
 
-	// Register other actions here as they are implemented
-
 	logger.Debug("----------------------------------------------------------")
 	logger.Debug("Go plugin actions registered successfully!")
 	logger.Debug("- Main section: Look for actions starting with 'Go:'")
@@ -32,3 +75,63 @@ func RegisterAll() error {
 
 	return nil
 }
+
+// applyReportingSettings sets up crash reports and console logging as the settings ask
+func applyReportingSettings() {
+	// Recovered panics are always logged; the dialog is optional
+	if config.GetCrashReports() {
+		reaper.SetCrashReporter(showCrashReport)
+	} else {
+		reaper.SetCrashReporter(nil)
+	}
+
+	// Show log output in the console if asked to
+	level, ok := logger.ParseLevel(config.GetConsoleLogLevel())
+	if !ok {
+		level = -1
+	}
+	reaper.MirrorLogToConsole(level)
+}
+
+// registerWithReaper registers one action, its handler and its toggle state
+func registerWithReaper(action Action) error {
+	commandID, err := reaper.RegisterCustomAction(action.ID, action.Name, action.Section)
+	if err != nil {
+		logger.Error("Failed to register %s: %v", action.Name, err)
+		return fmt.Errorf("failed to register %s: %v", action.Name, err)
+	}
+
+	logger.Info("%s registered with ID: %d", action.Name, commandID)
+	if action.ContextHandler != nil {
+		reaper.SetContextActionHandler(action.ID, action.ContextHandler)
+	} else {
+		reaper.SetActionHandler(action.ID, action.Handler)
+	}
+	if action.ToggleState != nil {
+		reaper.SetToggleStateProvider(action.ID, action.ToggleState)
+	}
+
+	registeredActions[action.ID] = true
+	return nil
+}
+
+// showCrashReport tells the user that an error was recovered. Panics can be recovered on
+// any thread, so the dialog is shown from the main thread.
+func showCrashReport(where string, panicValue interface{}) {
+	message := reaper.PanicMessage(where, panicValue)
+	core.MainThread.CallAsync(func() {
+		reaper.MessageBox(message, "Go Extension Error")
+	})
+}
+
+// isActionWanted reports whether RegisterAll should register an action: its settings
+// don't disable it and its feature is on
+func isActionWanted(action Action) bool {
+	return config.IsActionEnabled(action.ID) && config.IsFeatureEnabled(action.Feature)
+}
+
+// isActionRegistered reports whether an action was registered, i.e. it exists and is
+// enabled
+func isActionRegistered(actionID string) bool {
+	return registeredActions[actionID]
+}
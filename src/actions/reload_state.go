@@ -0,0 +1,59 @@
+package actions
+
+import (
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"go-reaper/src/ui"
+)
+
+// This file implements reloading the extension's state in place: windows close, caches
+// are emptied and the subsystems that poll on REAPER's timer read their settings and
+// project data again, as they would after restarting REAPER, without restarting it.
+
+// The reload extension state action
+func init() {
+	registerAction(Action{
+		ID:      "GO_RELOAD_STATE",
+		Name:    "Go: Reload Extension State",
+		Handler: handleReloadState,
+	})
+}
+
+// handleReloadState asks first, since open windows lose what hasn't been saved
+func handleReloadState() {
+	const title = "Reload Extension State"
+
+	reload, err := reaper.YesNoBox("Close the extension's windows and reload its settings, bindings and caches?\n\n"+
+		"Anything unsaved in the extension's windows is lost.", title)
+	if err != nil || !reload {
+		logger.Debug("Reload of extension state cancelled")
+		return
+	}
+
+	reloadState()
+	reaper.MessageBox("The extension's state was reloaded.", title)
+}
+
+// reloadState reinitializes the subsystems in place. It runs on the main thread, where
+// the polling subsystems run, so they are never caught halfway through a check.
+func reloadState() {
+	logger.Info("Reloading extension state")
+
+	// STEP 1: Close the windows, which read their settings when opened
+	ui.Windows.CloseAll()
+
+	// STEP 2: Forget cached plugin metadata
+	reaper.ClearParamCache()
+
+	// STEP 3: Apply the reporting settings again
+	applyReportingSettings()
+
+	// STEP 4: Have the polling subsystems read their data again, starting any that had
+	// stopped
+	links.reset()
+	StartParamLinks()
+	midiLearn.reset()
+	StartMIDILearn()
+
+	logger.Info("Extension state reloaded")
+}
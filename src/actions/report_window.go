@@ -0,0 +1,104 @@
+package actions
+
+import (
+	"go-reaper/src/core"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"go-reaper/src/ui"
+	"runtime"
+	"sync"
+)
+
+// This file shows read-only text reports: in a native window where there is one, and in
+// the REAPER console otherwise.
+
+// reportWindow is an open report and its text area
+type reportWindow struct {
+	window *ui.Window
+	text   *ui.Widget
+}
+
+var (
+	reportWindowsMutex sync.Mutex
+	reportWindows      = make(map[string]*reportWindow) // By window ID
+)
+
+// showReport shows a report titled title. windowID identifies the report's window, so
+// showing the same kind of report again replaces the text of the open one.
+func showReport(windowID, title, text string) {
+	if runtime.GOOS == "darwin" {
+		err := showReportWindow(windowID, title, text)
+		if err == nil {
+			return
+		}
+		logger.Warning("Failed to open report window, using the console: %v", err)
+	}
+
+	reaper.Console.Section(title)
+	if err := reaper.Console.Print(text); err != nil {
+		core.HandleError(title, core.NewError(core.CategoryReaper, "Failed to show the report in the console.", err))
+	}
+}
+
+// showReportWindow shows a report in its native window, opening it if needed
+func showReportWindow(windowID, title, text string) error {
+	reportWindowsMutex.Lock()
+	r := reportWindows[windowID]
+	reportWindowsMutex.Unlock()
+
+	if r == nil || !r.window.IsOpen() {
+		window, err := ui.NewWindow(windowID, title, 560, 480)
+		if err != nil {
+			return err
+		}
+
+		r = &reportWindow{window: window}
+		if r.text, err = window.AddMultilineText(ui.Rect{X: 12, Y: 12, Width: 536, Height: 418}, "", false, nil); err != nil {
+			window.Close()
+			return err
+		}
+		copyText := func() {
+			if err := ui.SetClipboardText(r.text.Text()); err != nil {
+				logger.Warning("Failed to copy the report: %v", err)
+			}
+		}
+		if _, err := window.AddButton(ui.Rect{X: 340, Y: 440, Width: 100, Height: 28}, "Copy", copyText); err != nil {
+			window.Close()
+			return err
+		}
+		// Close after the click has been handled, so the window outlives its button's action
+		closeLater := func() { ui.RunOnMainThreadAsync(window.Close) }
+		closeButton, err := window.AddButton(ui.Rect{X: 448, Y: 440, Width: 100, Height: 28}, "Close", closeLater)
+		if err != nil {
+			window.Close()
+			return err
+		}
+		if err := r.text.SetAccessibilityLabel(title); err != nil {
+			logger.Warning("Failed to name the report text: %v", err)
+		}
+		if err := window.SetCancelButton(closeButton); err != nil {
+			logger.Warning("Failed to set the report window's cancel button: %v", err)
+		}
+		if err := ui.Windows.OnClose(windowID, func() { forgetReportWindow(windowID, r) }); err != nil {
+			logger.Warning("Failed to observe report window closing: %v", err)
+		}
+
+		reportWindowsMutex.Lock()
+		reportWindows[windowID] = r
+		reportWindowsMutex.Unlock()
+	}
+
+	if err := r.text.SetText(text); err != nil {
+		return err
+	}
+	return r.window.Show()
+}
+
+// forgetReportWindow drops a report window once it closes
+func forgetReportWindow(windowID string, r *reportWindow) {
+	reportWindowsMutex.Lock()
+	if reportWindows[windowID] == r {
+		delete(reportWindows, windowID)
+	}
+	reportWindowsMutex.Unlock()
+}
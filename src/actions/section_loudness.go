@@ -0,0 +1,209 @@
+package actions
+
+import (
+	"fmt"
+	"go-reaper/src/core"
+	"go-reaper/src/pkg/analysis"
+	"go-reaper/src/pkg/config"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"math"
+	"strings"
+	"unsafe"
+)
+
+// This file implements measuring a track's loudness per section of the song. Sections
+// are the project's regions, or the stretches between markers when there are no regions.
+// The measurements are shown in a report, and when enabled in the analyzer settings they
+// are summarized in the assistant's prompt, e.g. "Chorus is 4.1 LU louder than Verse".
+
+// sectionLoudnessWindowID is the ID of the report window
+const sectionLoudnessWindowID = "section-loudness"
+
+// Audio is read at a fixed rate and in stereo, whatever the project uses
+const (
+	sectionSampleRate = 48000
+	sectionChannels   = 2
+)
+
+// sectionContrastLU is the smallest loudness difference between sections worth mentioning
+const sectionContrastLU = 2.0
+
+// maxPromptSections caps the sections listed in a prompt; contrasts cover the rest
+const maxPromptSections = 24
+
+// The section loudness action
+func init() {
+	registerAction(Action{
+		ID:      "GO_SECTION_LOUDNESS",
+		Name:    "Go: Section loudness of selected track",
+		Handler: handleSectionLoudness,
+	})
+}
+
+// handleSectionLoudness measures the selected track per section and shows the report
+func handleSectionLoudness() {
+	const title = "Section Loudness"
+
+	trackInfo, err := reaper.GetSelectedTrackInfo()
+	if err != nil {
+		core.HandleError(title, core.NewError(core.CategoryUser, "Please select a track first.", err))
+		return
+	}
+
+	levels, err := measureSections(trackInfo.MediaTrack)
+	if err != nil {
+		core.HandleError(title, core.NewError(core.CategoryReaper, "Could not measure the track.", err).
+			WithDetail("track %q", trackInfo.Name))
+		return
+	}
+	if len(levels) == 0 {
+		reaper.MessageBox("The project has no regions or markers, or the track has no audio in them. Add regions or markers for the song's sections first.", title)
+		return
+	}
+
+	header := fmt.Sprintf("Loudness of %s per section, from its items before the track FX.\n\n", trackInfo.Name)
+	showReport(sectionLoudnessWindowID, title, header+formatSectionLevels(levels, len(levels)))
+}
+
+// projectSections returns the project's sections that overlap from start to end: its
+// regions, or if it has none, the stretches between markers, the last running to end
+func projectSections(start, end float64) ([]analysis.Section, error) {
+	regions, err := reaper.GetRegions()
+	if err != nil {
+		return nil, err
+	}
+
+	var sections []analysis.Section
+	if len(regions) > 0 {
+		for _, region := range regions {
+			sections = append(sections, analysis.Section{Name: sectionName(region.Name, region.Number, "Region"), Start: region.Start, End: region.End})
+		}
+	} else {
+		markers, err := reaper.GetMarkers()
+		if err != nil {
+			return nil, err
+		}
+		if len(markers) > 0 && markers[0].Position > start {
+			sections = append(sections, analysis.Section{Name: "Before " + sectionName(markers[0].Name, markers[0].Number, "Marker"), Start: start, End: markers[0].Position})
+		}
+		for i, marker := range markers {
+			sectionEnd := end
+			if i+1 < len(markers) {
+				sectionEnd = markers[i+1].Position
+			}
+			sections = append(sections, analysis.Section{Name: sectionName(marker.Name, marker.Number, "Marker"), Start: marker.Position, End: sectionEnd})
+		}
+	}
+
+	// Keep the parts that have audio
+	var overlapping []analysis.Section
+	for _, section := range sections {
+		section.Start, section.End = max(section.Start, start), min(section.End, end)
+		if section.End > section.Start {
+			overlapping = append(overlapping, section)
+		}
+	}
+	return overlapping, nil
+}
+
+// sectionName names a section after its region or marker, or its number if unnamed
+func sectionName(name string, number int, kind string) string {
+	if name = strings.TrimSpace(name); name != "" {
+		return name
+	}
+	return fmt.Sprintf("%s %d", kind, number)
+}
+
+// measureSections reads a track's audio and measures each section. It runs on the main
+// thread, so it blocks REAPER while it reads; about a second per few minutes of audio.
+func measureSections(track unsafe.Pointer) ([]analysis.SectionLevels, error) {
+	accessor, err := reaper.NewTrackAudioAccessor(track)
+	if err != nil {
+		return nil, err
+	}
+	defer accessor.Close()
+
+	sections, err := projectSections(accessor.Start, accessor.End)
+	if err != nil {
+		return nil, err
+	}
+
+	levels := make([]analysis.SectionLevels, 0, len(sections))
+	for _, section := range sections {
+		meter := analysis.NewMeter(sectionSampleRate, sectionChannels)
+		err := accessor.ReadAudio(sectionSampleRate, sectionChannels, section.Start, section.End, func(block []float64) error {
+			meter.Write(block)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", section.Name, err)
+		}
+		levels = append(levels, analysis.SectionLevels{Section: section, Levels: meter.Levels()})
+	}
+	return levels, nil
+}
+
+// buildSectionPrompt summarizes a track's loudness per section for the assistant, when
+// enabled in the analyzer settings. It returns "" when disabled or there is nothing to say.
+func buildSectionPrompt(track unsafe.Pointer) string {
+	if !config.GetAnalyzer().SectionLoudness {
+		return ""
+	}
+
+	levels, err := measureSections(track)
+	if err != nil {
+		logger.Warning("Could not measure the track's sections: %v", err)
+		return ""
+	}
+	if len(levels) == 0 {
+		return ""
+	}
+
+	var builder strings.Builder
+	builder.WriteString("Loudness of the track's audio per song section, measured before its FX:\n")
+	builder.WriteString(formatSectionLevels(levels, maxPromptSections))
+	builder.WriteString("Take these differences into account, e.g. compression or automation that evens out the sections.\n\n")
+	return builder.String()
+}
+
+// formatSectionLevels lists up to limit sections' levels, then the notable differences
+// between sections
+func formatSectionLevels(levels []analysis.SectionLevels, limit int) string {
+	var builder strings.Builder
+	for i, section := range levels {
+		if i == limit {
+			builder.WriteString(fmt.Sprintf("  ... and %d more section(s)\n", len(levels)-limit))
+			break
+		}
+		builder.WriteString(fmt.Sprintf("  %s (%s-%s): ", section.Name, clockTime(section.Start), clockTime(section.End)))
+		if section.Silent() {
+			builder.WriteString("silent\n")
+			continue
+		}
+		builder.WriteString(fmt.Sprintf("%.1f LUFS, peak %s, RMS %s\n", section.Integrated, dbText(section.Peak), dbText(section.RMS)))
+	}
+
+	contrasts := analysis.Contrasts(levels, sectionContrastLU)
+	if len(contrasts) > 0 {
+		builder.WriteString("Differences:\n")
+		for _, contrast := range contrasts {
+			builder.WriteString(fmt.Sprintf("  %s is %.1f LU louder than %s\n", contrast.Louder, contrast.Difference, contrast.Quieter))
+		}
+	}
+	return builder.String()
+}
+
+// clockTime formats seconds as minutes and seconds, e.g. "1:05"
+func clockTime(seconds float64) string {
+	total := int(math.Round(seconds))
+	return fmt.Sprintf("%d:%02d", total/60, total%60)
+}
+
+// dbText formats a level in dBFS, or "-inf" for silence
+func dbText(db float64) string {
+	if math.IsInf(db, -1) {
+		return "-inf dBFS"
+	}
+	return fmt.Sprintf("%.1f dBFS", db)
+}
@@ -0,0 +1,400 @@
+package actions
+
+import (
+	"encoding/xml"
+	"fmt"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+	"unsafe"
+)
+
+// This file implements a self-test that exercises the bridge against the running REAPER:
+// it registers the actions, creates a track, adds ReaEQ, sets parameters and reads them
+// back, then writes a JUnit report. Run it in CI against each supported REAPER version
+// to catch bridge regressions.
+
+// Environment variables that control the self-test
+const (
+	selfTestReportEnv = "REAPER_GO_SELFTEST_REPORT" // Path of the JUnit report; enables the self-test
+	selfTestQuitEnv   = "REAPER_GO_SELFTEST_QUIT"   // Set to 1, true or yes to quit REAPER afterwards
+)
+
+// selfTestTolerance is how close a read-back normalized value must be to the value set
+const selfTestTolerance = 0.001
+
+// errSkipped marks a step that could not run because an earlier one failed
+var errSkipped = fmt.Errorf("skipped")
+
+// selfTestState is shared between the steps
+type selfTestState struct {
+	track   unsafe.Pointer
+	fxIndex int
+}
+
+// selfTestStep is one test case. Steps that need an earlier step's result return
+// errSkipped when it is missing.
+type selfTestStep struct {
+	name string
+	run  func(state *selfTestState) error
+}
+
+// JUnit report types, in the subset of the format CI servers read
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Time       string          `xml:"time,attr"`
+	Timestamp  string          `xml:"timestamp,attr"`
+	Properties []junitProperty `xml:"properties>property"`
+	Cases      []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+}
+
+// StartSelfTestIfRequested schedules the self-test when REAPER was started with
+// REAPER_GO_SELFTEST_REPORT set. It runs at the first timer tick, once REAPER has
+// finished loading. Call it after RegisterAll.
+func StartSelfTestIfRequested() {
+	reportPath := os.Getenv(selfTestReportEnv)
+	if reportPath == "" {
+		return
+	}
+
+	logger.Info("Self-test requested, report will be written to %s", reportPath)
+	reaper.Defer(func() {
+		runSelfTest(reportPath)
+
+		if isTruthy(os.Getenv(selfTestQuitEnv)) {
+			if err := reaper.RunCommand(reaper.CommandQuit); err != nil {
+				logger.Error("Failed to quit REAPER after the self-test: %v", err)
+			}
+		}
+	})
+}
+
+// isTruthy reports whether an environment variable value means "on", the way the
+// logger reads REAPER_GO_LOG_ENABLED
+func isTruthy(value string) bool {
+	switch strings.ToLower(value) {
+	case "1", "true", "yes":
+		return true
+	}
+	return false
+}
+
+// selfTestSteps returns the scripted sequence, in order
+func selfTestSteps() []selfTestStep {
+	return []selfTestStep{
+		{"register_actions", selfTestRegisterActions},
+		{"struct_layout", selfTestStructLayout},
+		{"create_track", selfTestCreateTrack},
+		{"add_reaeq", selfTestAddReaEQ},
+		{"batch_set_parameters", selfTestBatchSetParameters},
+		{"batch_set_fx_states", selfTestBatchSetFXStates},
+		{"set_unit_value", selfTestSetUnitValue},
+		{"delete_track", selfTestDeleteTrack},
+	}
+}
+
+// runSelfTest runs every step and writes the report
+func runSelfTest(reportPath string) {
+	suite := junitTestSuite{
+		Name:      "go-reaper.bridge",
+		Timestamp: time.Now().Format("2006-01-02T15:04:05"),
+		Properties: []junitProperty{
+			{Name: "os", Value: runtime.GOOS},
+			{Name: "arch", Value: runtime.GOARCH},
+			{Name: "go", Value: runtime.Version()},
+		},
+	}
+
+	state := &selfTestState{fxIndex: -1}
+	started := time.Now()
+
+	for _, step := range selfTestSteps() {
+		testCase, err := runSelfTestStep(step, state)
+		suite.Tests++
+		switch {
+		case err == errSkipped:
+			suite.Skipped++
+			logger.Info("Self-test %s: skipped", step.name)
+		case err != nil:
+			suite.Failures++
+			logger.Error("Self-test %s: FAILED: %v", step.name, err)
+		default:
+			logger.Info("Self-test %s: passed", step.name)
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	suite.Time = formatSeconds(time.Since(started))
+	logger.Info("Self-test finished: %d tests, %d failures, %d skipped",
+		suite.Tests, suite.Failures, suite.Skipped)
+
+	if err := writeJUnitReport(reportPath, suite); err != nil {
+		logger.Error("Failed to write the self-test report: %v", err)
+	}
+}
+
+// runSelfTestStep runs one step, turning a panic into a failure so the remaining steps
+// still run and the report is still written
+func runSelfTestStep(step selfTestStep, state *selfTestState) (testCase junitTestCase, err error) {
+	started := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+
+		testCase = junitTestCase{
+			Name:      step.name,
+			ClassName: "go-reaper.bridge",
+			Time:      formatSeconds(time.Since(started)),
+		}
+		switch {
+		case err == errSkipped:
+			testCase.Skipped = &junitMessage{Message: "an earlier step failed"}
+		case err != nil:
+			testCase.Failure = &junitMessage{Message: err.Error()}
+		}
+	}()
+
+	return testCase, step.run(state)
+}
+
+// formatSeconds formats a duration the way JUnit reports do
+func formatSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// writeJUnitReport writes the report, creating its directory if needed
+func writeJUnitReport(path string, suite junitTestSuite) error {
+	data, err := xml.MarshalIndent(junitTestSuites{Suites: []junitTestSuite{suite}}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append([]byte(xml.Header), data...), 0o644)
+}
+
+// selfTestRegisterActions checks every enabled action was registered with REAPER
+func selfTestRegisterActions(state *selfTestState) error {
+	var missing []string
+	for _, action := range declaredActions {
+		if isActionWanted(action) && !isActionRegistered(action.ID) {
+			missing = append(missing, action.ID)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("actions not registered: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// selfTestStructLayout checks Go and the bridge agree on the layout of the structs batch
+// calls read in place
+func selfTestStructLayout(state *selfTestState) error {
+	return reaper.CheckStructLayout()
+}
+
+// selfTestCreateTrack appends a track to the project
+func selfTestCreateTrack(state *selfTestState) error {
+	before, err := reaper.CountTracks()
+	if err != nil {
+		return err
+	}
+
+	track, err := reaper.InsertTrack(before)
+	if err != nil {
+		return err
+	}
+	state.track = track
+
+	after, err := reaper.CountTracks()
+	if err != nil {
+		return err
+	}
+	if after != before+1 {
+		return fmt.Errorf("track count is %d after inserting, expected %d", after, before+1)
+	}
+	if !reaper.IsTrackValid(track) {
+		return fmt.Errorf("inserted track is not valid")
+	}
+	return nil
+}
+
+// selfTestAddReaEQ adds ReaEQ to the new track and reads its bands
+func selfTestAddReaEQ(state *selfTestState) error {
+	if state.track == nil {
+		return errSkipped
+	}
+
+	fxIndex, err := reaper.AddTrackFX(state.track, "ReaEQ")
+	if err != nil {
+		return err
+	}
+	state.fxIndex = fxIndex
+
+	count, err := reaper.GetTrackFXCount(state.track)
+	if err != nil {
+		return err
+	}
+	if count != 1 {
+		return fmt.Errorf("track has %d FX after adding ReaEQ, expected 1", count)
+	}
+
+	eq, err := reaper.NewReaEQ(state.track, fxIndex)
+	if err != nil {
+		return err
+	}
+	if len(eq.Bands) == 0 {
+		return fmt.Errorf("no ReaEQ bands found")
+	}
+	return nil
+}
+
+// selfTestBatchSetParameters sets several parameters and reads them back in one batch
+func selfTestBatchSetParameters(state *selfTestState) error {
+	if state.fxIndex < 0 {
+		return errSkipped
+	}
+
+	params, err := reaper.BatchGetFXParameters(state.track, state.fxIndex)
+	if err != nil {
+		return err
+	}
+	if len(params) == 0 {
+		return fmt.Errorf("ReaEQ reported no parameters")
+	}
+
+	// Move each of the first few parameters to a value it doesn't already have
+	targets := make(map[int]float64)
+	for _, param := range params[:min(len(params), 6)] {
+		target := 0.25
+		if math.Abs(param.Value-target) < 0.1 {
+			target = 0.75
+		}
+		if err := reaper.SetTrackFXParamValue(state.track, state.fxIndex, param.Index, target); err != nil {
+			return fmt.Errorf("setting %s: %v", param.Name, err)
+		}
+		targets[param.Index] = target
+	}
+
+	params, err = reaper.BatchGetFXParameters(state.track, state.fxIndex)
+	if err != nil {
+		return err
+	}
+	for _, param := range params {
+		target, ok := targets[param.Index]
+		if ok && math.Abs(param.Value-target) > selfTestTolerance {
+			return fmt.Errorf("%s reads back %.4f after setting %.4f", param.Name, param.Value, target)
+		}
+	}
+	return nil
+}
+
+// selfTestBatchSetFXStates bypasses the FX and takes it offline, then restores it
+func selfTestBatchSetFXStates(state *selfTestState) error {
+	if state.fxIndex < 0 {
+		return errSkipped
+	}
+
+	for _, want := range []reaper.FXState{
+		{Index: state.fxIndex, Enabled: false, Offline: true},
+		{Index: state.fxIndex, Enabled: true, Offline: false},
+	} {
+		if err := reaper.BatchSetTrackFXStates(state.track, []reaper.FXState{want}); err != nil {
+			return err
+		}
+
+		states, err := reaper.BatchGetTrackFXStates(state.track)
+		if err != nil {
+			return err
+		}
+		if len(states) <= state.fxIndex {
+			return fmt.Errorf("got %d FX states, expected at least %d", len(states), state.fxIndex+1)
+		}
+		if got := states[state.fxIndex]; got != want {
+			return fmt.Errorf("FX state is %+v after setting %+v", got, want)
+		}
+	}
+	return nil
+}
+
+// selfTestSetUnitValue sets a band gain in dB and checks the displayed value follows
+func selfTestSetUnitValue(state *selfTestState) error {
+	if state.fxIndex < 0 {
+		return errSkipped
+	}
+
+	eq, err := reaper.NewReaEQ(state.track, state.fxIndex)
+	if err != nil {
+		return err
+	}
+
+	for i, band := range eq.Bands {
+		if band.GainParam < 0 {
+			continue
+		}
+
+		const targetDB = -6.0
+		if err := eq.SetBandGain(i, targetDB); err != nil {
+			return err
+		}
+		if err := eq.Refresh(); err != nil {
+			return err
+		}
+		if got := eq.Bands[i].Gain; math.Abs(got-targetDB) > 0.5 {
+			return fmt.Errorf("band %s gain reads %.2f dB after setting %.2f dB", band.Name, got, targetDB)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("no ReaEQ band has a gain control")
+}
+
+// selfTestDeleteTrack removes the track the test created
+func selfTestDeleteTrack(state *selfTestState) error {
+	if state.track == nil {
+		return errSkipped
+	}
+
+	if err := reaper.DeleteTrack(state.track); err != nil {
+		return err
+	}
+	if reaper.IsTrackValid(state.track) {
+		return fmt.Errorf("track is still valid after deleting it")
+	}
+	state.track = nil
+	return nil
+}
@@ -0,0 +1,236 @@
+package actions
+
+import (
+	"fmt"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/ui"
+	"strings"
+	"sync"
+)
+
+// This file implements the FX snapshot manager: a window listing the project's snapshots
+// with their tracks and FX, to save, apply, rename and delete them. Widget callbacks run
+// on the main thread, so they call REAPER directly.
+
+// snapshotWindowID is the ID the manager is registered under with ui.Windows
+const snapshotWindowID = "fx-snapshots"
+
+// snapshotManager holds the window's widgets and the listed snapshots
+type snapshotManager struct {
+	window *ui.Window
+
+	list    *ui.Widget
+	details *ui.Widget
+	name    *ui.Widget
+	status  *ui.Widget
+	apply   *ui.Widget
+	rename  *ui.Widget
+	remove  *ui.Widget
+
+	mutex     sync.Mutex
+	snapshots []namedSnapshot // Listed snapshots, in list order
+}
+
+var (
+	snapshotManagerMutex sync.Mutex
+	snapshotWindow       *snapshotManager
+)
+
+// showSnapshotManager opens the manager with the current project's snapshots, or brings
+// it to the front
+func showSnapshotManager() error {
+	snapshotManagerMutex.Lock()
+	m := snapshotWindow
+	snapshotManagerMutex.Unlock()
+
+	if m == nil || !m.window.IsOpen() {
+		window, err := ui.NewWindow(snapshotWindowID, "FX Snapshots", 480, 360)
+		if err != nil {
+			return err
+		}
+
+		m = &snapshotManager{window: window}
+		if err := m.build(); err != nil {
+			window.Close()
+			return err
+		}
+		if err := ui.Windows.OnClose(snapshotWindowID, m.closed); err != nil {
+			logger.Warning("Failed to observe snapshot manager closing: %v", err)
+		}
+
+		snapshotManagerMutex.Lock()
+		snapshotWindow = m
+		snapshotManagerMutex.Unlock()
+	}
+
+	m.reload(-1)
+	return m.window.Show()
+}
+
+// build adds the manager's widgets
+func (m *snapshotManager) build() error {
+	w := m.window
+	var err error
+
+	if _, err = w.AddLabel(ui.Rect{X: 12, Y: 14, Width: 80, Height: 20}, "Snapshot:"); err != nil {
+		return err
+	}
+	if m.list, err = w.AddDropdown(ui.Rect{X: 92, Y: 10, Width: 268, Height: 26}, nil, -1, m.selected); err != nil {
+		return err
+	}
+	if _, err = w.AddButton(ui.Rect{X: 368, Y: 10, Width: 100, Height: 26}, "Refresh", func() { m.reload(-1) }); err != nil {
+		return err
+	}
+
+	if m.details, err = w.AddMultilineText(ui.Rect{X: 12, Y: 46, Width: 456, Height: 186}, "", false, nil); err != nil {
+		return err
+	}
+
+	if _, err = w.AddLabel(ui.Rect{X: 12, Y: 246, Width: 80, Height: 20}, "Name:"); err != nil {
+		return err
+	}
+	if m.name, err = w.AddTextField(ui.Rect{X: 92, Y: 242, Width: 376, Height: 24}, "", nil); err != nil {
+		return err
+	}
+
+	if _, err = w.AddButton(ui.Rect{X: 12, Y: 278, Width: 140, Height: 28}, "Save Selected Tracks", m.save); err != nil {
+		return err
+	}
+	if m.apply, err = w.AddButton(ui.Rect{X: 160, Y: 278, Width: 96, Height: 28}, "Apply", m.applySelected); err != nil {
+		return err
+	}
+	if m.rename, err = w.AddButton(ui.Rect{X: 264, Y: 278, Width: 96, Height: 28}, "Rename", m.renameSelected); err != nil {
+		return err
+	}
+	if m.remove, err = w.AddButton(ui.Rect{X: 368, Y: 278, Width: 100, Height: 28}, "Delete", m.deleteSelected); err != nil {
+		return err
+	}
+
+	if m.status, err = w.AddLabel(ui.Rect{X: 12, Y: 320, Width: 456, Height: 20}, ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+// setStatus shows a one-line status message
+func (m *snapshotManager) setStatus(format string, args ...interface{}) {
+	if err := m.status.SetText(fmt.Sprintf(format, args...)); err != nil {
+		logger.Warning("Failed to update snapshot manager status: %v", err)
+	}
+}
+
+// reload lists the current project's snapshots and selects the one at index, or the
+// newest if index is out of range
+func (m *snapshotManager) reload(index int) {
+	snapshots, err := listSnapshots()
+	if err != nil {
+		m.setStatus("Could not read the project's snapshots: %v", err)
+		snapshots = nil
+	}
+
+	m.mutex.Lock()
+	m.snapshots = snapshots
+	m.mutex.Unlock()
+
+	labels := make([]string, len(snapshots))
+	for i, snapshot := range snapshots {
+		labels[i] = snapshot.summary()
+	}
+	m.list.SetItems(labels)
+
+	has := len(snapshots) > 0
+	m.apply.SetEnabled(has)
+	m.rename.SetEnabled(has)
+	m.remove.SetEnabled(has)
+	if !has {
+		m.details.SetText("This project has no snapshots yet. Select tracks, type a name and press Save Selected Tracks.")
+		return
+	}
+
+	if index < 0 || index >= len(snapshots) {
+		index = len(snapshots) - 1
+	}
+	m.list.SetValue(float64(index))
+	m.selected(index)
+}
+
+// current returns the snapshot selected in the list
+func (m *snapshotManager) current() (int, namedSnapshot, bool) {
+	index := int(m.list.Value())
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if index < 0 || index >= len(m.snapshots) {
+		return -1, namedSnapshot{}, false
+	}
+	return index, m.snapshots[index], true
+}
+
+// selected shows the picked snapshot's details and name
+func (m *snapshotManager) selected(index int) {
+	_, snapshot, ok := m.current()
+	if !ok {
+		return
+	}
+	m.details.SetText(snapshot.details())
+	m.name.SetText(snapshot.Name)
+}
+
+// save snapshots the selected tracks under the typed name
+func (m *snapshotManager) save() {
+	snapshot, err := takeSnapshot(m.name.Text())
+	if err != nil {
+		m.setStatus("Not saved: %v", err)
+		return
+	}
+	m.reload(-1)
+	m.setStatus("Saved %q with %d FX", snapshot.Name, snapshot.Snapshot.FXCount())
+}
+
+// applySelected writes the selected snapshot's values back
+func (m *snapshotManager) applySelected() {
+	_, snapshot, ok := m.current()
+	if !ok {
+		return
+	}
+	applied, skipped := applySnapshot(snapshot)
+	m.setStatus("%s", appliedNote(snapshot, applied, skipped))
+}
+
+// renameSelected gives the selected snapshot the typed name
+func (m *snapshotManager) renameSelected() {
+	index, snapshot, ok := m.current()
+	if !ok {
+		return
+	}
+	if err := renameSnapshot(snapshot.ID, m.name.Text()); err != nil {
+		m.setStatus("Not renamed: %v", err)
+		return
+	}
+	m.reload(index)
+	m.setStatus("Renamed %q to %q", snapshot.Name, strings.TrimSpace(m.name.Text()))
+}
+
+// deleteSelected removes the selected snapshot from the project
+func (m *snapshotManager) deleteSelected() {
+	index, snapshot, ok := m.current()
+	if !ok {
+		return
+	}
+	if err := deleteSnapshot(snapshot.ID); err != nil {
+		m.setStatus("Not deleted: %v", err)
+		return
+	}
+	m.reload(index)
+	m.setStatus("Deleted %q", snapshot.Name)
+}
+
+// closed forgets the manager
+func (m *snapshotManager) closed() {
+	snapshotManagerMutex.Lock()
+	if snapshotWindow == m {
+		snapshotWindow = nil
+	}
+	snapshotManagerMutex.Unlock()
+}
@@ -0,0 +1,69 @@
+package actions
+
+import (
+	"fmt"
+	"go-reaper/src/core"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+)
+
+// This file implements setting up a stem render of the selected tracks for the region
+// under the edit cursor, using REAPER's region render matrix. The render dialog is opened
+// afterwards so the user can check the format and output path before rendering.
+
+// The stem render action
+func init() {
+	registerAction(Action{
+		ID:      "GO_REGION_STEM_RENDER",
+		Name:    "Go: Set Up Stem Render of Selected Tracks for Current Region",
+		Handler: handleRegionStemRender,
+	})
+}
+
+// handleRegionStemRender points the current region's render matrix entries at the
+// selected tracks, as one undo point, and opens the render dialog
+func handleRegionStemRender() {
+	const title = "Stem Render"
+
+	region, err := reaper.GetCurrentRegion()
+	if err != nil {
+		core.HandleError(title, core.NewError(core.CategoryUser, "Move the edit cursor into a region first.", err))
+		return
+	}
+
+	tracks, err := reaper.GetSelectedTracks()
+	if err != nil {
+		core.HandleError(title, core.NewError(core.CategoryReaper, "Could not read the selected tracks.", err))
+		return
+	}
+	if len(tracks) == 0 {
+		core.HandleError(title, core.NewError(core.CategoryUser, "Please select the tracks to render first.", nil))
+		return
+	}
+
+	// The undo block ends before the render dialog opens, so it covers only the matrix
+	undoStarted := true
+	if err := reaper.UndoBeginBlock(); err != nil {
+		logger.Warning("Failed to start undo block: %v", err)
+		undoStarted = false
+	}
+	setupErr := reaper.SetupRegionStemRender(region, tracks)
+	if undoStarted {
+		description := fmt.Sprintf("Set up stem render for region %d", region.Number)
+		if err := reaper.UndoEndBlock(description, reaper.UndoStateMiscCfg); err != nil {
+			logger.Warning("Failed to end undo block: %v", err)
+		}
+	}
+
+	if setupErr != nil {
+		core.HandleError(title, core.NewError(core.CategoryReaper, "Could not set up the region render matrix.", setupErr).
+			WithDetail("region %d %q", region.Number, region.Name))
+		return
+	}
+
+	logger.Info("Set region %d %q to render %d track(s)", region.Number, region.Name, len(tracks))
+
+	if err := reaper.RunCommand(reaper.CommandRenderToDisk); err != nil {
+		logger.Warning("Failed to open the render dialog: %v", err)
+	}
+}
@@ -0,0 +1,329 @@
+package actions
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-reaper/src/core"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"strings"
+	"time"
+	"unsafe"
+)
+
+// This file implements freezing and unfreezing tracks. Freezing moves a track's online FX
+// out of its chain, so before a freeze the FX are recorded in the project, marking the
+// ones Go features refer to (snapshots, parameter links, MIDI learn and the assistant's
+// last request). The assistant refuses frozen tracks and lists what was frozen.
+
+// freezeSection is the project ext state section freeze records are stored in, keyed by
+// track GUID
+const freezeSection = "GoReaperFreeze"
+
+// frozenFX is an FX that was on a track when it was frozen
+type frozenFX struct {
+	GUID    string   `json:"guid"`
+	Name    string   `json:"name"`
+	Managed []string `json:"managed,omitempty"` // Go features that refer to it
+}
+
+// freezeLevel is one freeze of a track. Each freeze stores the FX added since the last.
+type freezeLevel struct {
+	Time time.Time  `json:"time"`
+	FX   []frozenFX `json:"fx"`
+}
+
+// freezeRecord is what was frozen on a track, oldest freeze first
+type freezeRecord struct {
+	TrackName string        `json:"track_name"`
+	Levels    []freezeLevel `json:"levels"`
+}
+
+// The freeze and unfreeze actions
+func init() {
+	registerAction(Action{
+		ID:      "GO_FREEZE_TRACKS",
+		Name:    "Go: Freeze selected tracks (remember FX)",
+		Handler: handleFreezeTracks,
+	})
+	registerAction(Action{
+		ID:      "GO_UNFREEZE_TRACKS",
+		Name:    "Go: Unfreeze selected tracks",
+		Handler: handleUnfreezeTracks,
+	})
+}
+
+// handleFreezeTracks records the FX of the selected tracks, then freezes them
+func handleFreezeTracks() {
+	const title = "Freeze Tracks"
+
+	// STEP 1: Get the selected tracks
+	tracks, err := reaper.GetSelectedTracks()
+	if err != nil {
+		core.HandleError(title, core.NewError(core.CategoryReaper, "Could not read the selected tracks.", err))
+		return
+	}
+	if len(tracks) == 0 {
+		reaper.MessageBox("Select the tracks to freeze first.", title)
+		return
+	}
+
+	// STEP 2: Ask for mono or stereo
+	values, err := reaper.GetUserInputs(title, []string{"Freeze to mono instead of stereo (y/n)"}, []string{"n"})
+	if err != nil {
+		logger.Debug("Freeze cancelled")
+		return
+	}
+	mono := strings.HasPrefix(strings.ToLower(strings.TrimSpace(values[0])), "y")
+
+	// STEP 3: Record the FX the freeze takes off the tracks
+	managed := managedFXFeatures()
+	records := make(map[unsafe.Pointer]freezeLevel, len(tracks))
+	for _, track := range tracks {
+		level, err := captureFreezeLevel(track, managed)
+		if err != nil {
+			core.HandleError(title, core.NewError(core.CategoryReaper, "Could not read the FX of the tracks to freeze.", err))
+			return
+		}
+		records[track] = level
+	}
+
+	// STEP 4: Freeze, and keep the records of the tracks that were frozen
+	before := freezeCounts(tracks)
+	if err := reaper.FreezeTracks(tracks, mono); err != nil {
+		core.HandleError(title, core.NewError(core.CategoryReaper, "Could not freeze the tracks.", err))
+		return
+	}
+	frozen := 0
+	for _, track := range tracks {
+		count, err := reaper.GetTrackFreezeCount(track)
+		if err != nil || count <= before[track] {
+			continue
+		}
+		frozen++
+		if err := addFreezeLevel(track, count, records[track]); err != nil {
+			logger.Warning("Failed to record the frozen FX: %v", err)
+		}
+	}
+
+	logger.Info("Froze %d of %d track(s)", frozen, len(tracks))
+	if frozen < len(tracks) {
+		reaper.MessageBox(fmt.Sprintf("%d of %d track(s) were frozen. Tracks without items or FX can't be frozen.", frozen, len(tracks)), title)
+	}
+}
+
+// handleUnfreezeTracks unfreezes the selected tracks and drops what was recorded for the
+// freezes they come out of
+func handleUnfreezeTracks() {
+	const title = "Unfreeze Tracks"
+
+	tracks, err := reaper.GetSelectedTracks()
+	if err != nil {
+		core.HandleError(title, core.NewError(core.CategoryReaper, "Could not read the selected tracks.", err))
+		return
+	}
+
+	var frozen []unsafe.Pointer
+	for _, track := range tracks {
+		if reaper.IsTrackFrozen(track) {
+			frozen = append(frozen, track)
+		}
+	}
+	if len(frozen) == 0 {
+		reaper.MessageBox("None of the selected tracks are frozen.", title)
+		return
+	}
+
+	if err := reaper.UnfreezeTracks(frozen); err != nil {
+		core.HandleError(title, core.NewError(core.CategoryReaper, "Could not unfreeze the tracks.", err))
+		return
+	}
+	for _, track := range frozen {
+		count, err := reaper.GetTrackFreezeCount(track)
+		if err != nil {
+			continue
+		}
+		if err := trimFreezeRecord(track, count); err != nil {
+			logger.Warning("Failed to update the freeze record: %v", err)
+		}
+	}
+	logger.Info("Unfroze %d track(s)", len(frozen))
+}
+
+// managedFXFeatures returns the Go features referring to each FX GUID in the project
+func managedFXFeatures() map[string][]string {
+	managed := make(map[string][]string)
+	add := func(guid, feature string) {
+		if guid == "" {
+			return
+		}
+		for _, existing := range managed[guid] {
+			if existing == feature {
+				return
+			}
+		}
+		managed[guid] = append(managed[guid], feature)
+	}
+
+	if snapshots, err := listSnapshots(); err == nil {
+		for _, snapshot := range snapshots {
+			for _, track := range snapshot.Snapshot.Tracks {
+				for _, fx := range track.FX {
+					add(fx.GUID, "FX snapshot")
+				}
+			}
+		}
+	}
+	if groups, err := loadLinkGroups(); err == nil {
+		for _, group := range groups {
+			for _, member := range group.Members {
+				add(member.FXGUID, "parameter link")
+			}
+		}
+	}
+	if bindings, err := loadMIDIBindings(); err == nil {
+		for _, binding := range bindings {
+			if !binding.isAction() {
+				add(binding.FXGUID, "MIDI learn")
+			}
+		}
+	}
+	for _, guid := range loadAssistantMemory().FXGUIDs {
+		add(guid, "assistant")
+	}
+	return managed
+}
+
+// captureFreezeLevel lists the online FX of a track, which a freeze takes off it
+func captureFreezeLevel(track unsafe.Pointer, managed map[string][]string) (freezeLevel, error) {
+	level := freezeLevel{Time: time.Now()}
+
+	fxList, err := reaper.GetTrackFXSummaries(track)
+	if err != nil {
+		return level, err
+	}
+	for i, fx := range fxList {
+		if fx.Offline {
+			continue
+		}
+		guid, err := reaper.GetTrackFXGUID(track, i)
+		if err != nil {
+			return level, err
+		}
+		level.FX = append(level.FX, frozenFX{GUID: guid, Name: fx.Name, Managed: managed[guid]})
+	}
+	return level, nil
+}
+
+// freezeCounts returns how many times each track is frozen
+func freezeCounts(tracks []unsafe.Pointer) map[unsafe.Pointer]int {
+	counts := make(map[unsafe.Pointer]int, len(tracks))
+	for _, track := range tracks {
+		counts[track], _ = reaper.GetTrackFreezeCount(track)
+	}
+	return counts
+}
+
+// loadFreezeRecord reads what was recorded for a track's freezes. ok is false when there
+// is no record.
+func loadFreezeRecord(track unsafe.Pointer) (record freezeRecord, ok bool, err error) {
+	guid, err := reaper.GetTrackGUID(track)
+	if err != nil {
+		return record, false, err
+	}
+	data, err := reaper.GetProjExtState(freezeSection, guid)
+	if err != nil || data == "" {
+		return record, false, err
+	}
+	if err := json.Unmarshal([]byte(data), &record); err != nil {
+		return record, false, fmt.Errorf("the freeze record is invalid: %v", err)
+	}
+	return record, true, nil
+}
+
+// saveFreezeRecord stores a track's freeze record, or deletes it when it has no levels
+func saveFreezeRecord(track unsafe.Pointer, record freezeRecord) error {
+	guid, err := reaper.GetTrackGUID(track)
+	if err != nil {
+		return err
+	}
+	if len(record.Levels) == 0 {
+		return reaper.DeleteProjExtState(freezeSection, guid)
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode the freeze record: %v", err)
+	}
+	return reaper.SetProjExtState(freezeSection, guid, string(data))
+}
+
+// addFreezeLevel records a track's latest freeze, count being its freeze count after it.
+// Levels from freezes undone outside the extension are dropped first, and freezes not
+// made by the extension get an empty level so later levels line up.
+func addFreezeLevel(track unsafe.Pointer, count int, level freezeLevel) error {
+	record, _, err := loadFreezeRecord(track)
+	if err != nil {
+		logger.Warning("Replacing the track's freeze record: %v", err)
+		record = freezeRecord{}
+	}
+
+	levels := record.Levels
+	if len(levels) > count-1 {
+		levels = levels[:count-1]
+	}
+	for len(levels) < count-1 {
+		levels = append(levels, freezeLevel{})
+	}
+	record.Levels = append(levels, level)
+	record.TrackName, _ = reaper.GetTrackName(track)
+	return saveFreezeRecord(track, record)
+}
+
+// trimFreezeRecord drops the levels of freezes a track came out of, count being its
+// freeze count now
+func trimFreezeRecord(track unsafe.Pointer, count int) error {
+	record, ok, err := loadFreezeRecord(track)
+	if err != nil || !ok || len(record.Levels) <= count {
+		return err
+	}
+	record.Levels = record.Levels[:count]
+	return saveFreezeRecord(track, record)
+}
+
+// frozenTrackMessage explains that a track's FX can't be changed because it is frozen,
+// listing the FX that were recorded when it was frozen. It returns "" when the track
+// isn't frozen.
+func frozenTrackMessage(track unsafe.Pointer, trackName string) string {
+	count, err := reaper.GetTrackFreezeCount(track)
+	if err != nil || count == 0 {
+		return ""
+	}
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("%s is frozen, so its FX can't be changed. Unfreeze it first with \"Go: Unfreeze selected tracks\" or REAPER's \"Track: Unfreeze tracks\".", trackName))
+
+	record, ok, err := loadFreezeRecord(track)
+	if err != nil {
+		logger.Warning("Could not read the freeze record: %v", err)
+	}
+	if !ok {
+		return builder.String()
+	}
+
+	var lines []string
+	for _, level := range record.Levels[:min(count, len(record.Levels))] {
+		for _, fx := range level.FX {
+			line := "  " + fx.Name
+			if len(fx.Managed) > 0 {
+				line += fmt.Sprintf(" (%s)", strings.Join(fx.Managed, ", "))
+			}
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) > 0 {
+		builder.WriteString("\n\nFX frozen on it:\n")
+		builder.WriteString(strings.Join(lines, "\n"))
+	}
+	return builder.String()
+}
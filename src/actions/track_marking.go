@@ -0,0 +1,62 @@
+package actions
+
+import (
+	"fmt"
+	"go-reaper/src/pkg/config"
+	"go-reaper/src/reaper"
+	"strings"
+	"unsafe"
+)
+
+// This file marks tracks whose FX the assistant changed, with a color and/or a name
+// prefix from the settings, so the tracks touched in a session stand out.
+
+// trackMarkingEnabled reports whether changed tracks are marked at all
+func trackMarkingEnabled() bool {
+	color, prefix := config.GetTrackMarking()
+	return color != "" || prefix != ""
+}
+
+// markChangedTrack tints and/or renames a track the assistant changed, as configured.
+// Call it inside the undo block of the change. The master track is left alone.
+func markChangedTrack(track unsafe.Pointer) error {
+	colorText, prefix := config.GetTrackMarking()
+	if colorText == "" && prefix == "" {
+		return nil
+	}
+
+	if master, err := reaper.GetMasterTrack(); err == nil && master == track {
+		return nil
+	}
+
+	if colorText != "" {
+		color, err := reaper.ParseColor(colorText)
+		if err != nil {
+			return fmt.Errorf("track marking color: %v", err)
+		}
+		if err := reaper.SetTrackColor(track, color); err != nil {
+			return err
+		}
+	}
+
+	if prefix != "" {
+		name, err := reaper.GetTrackCustomName(track)
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(name, prefix) {
+			return nil
+		}
+		if name == "" {
+			// Unnamed tracks show "Track N"; keep that visible after the prefix
+			if name, err = reaper.GetTrackName(track); err != nil {
+				return err
+			}
+		}
+		if err := reaper.SetTrackName(track, prefix+name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
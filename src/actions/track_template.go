@@ -0,0 +1,223 @@
+package actions
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-reaper/src/core"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"os"
+	"path/filepath"
+	"strings"
+	"unsafe"
+)
+
+// This file implements inserting a track template with parameter overrides, so one
+// template (a vocal chain, say) can be spawned already set up for a singer. Overrides
+// come from an FX snapshot saved in the project, or from a JSON file listing parameters
+// by FX and parameter name:
+//
+//	[
+//	  {"fx": "ReaEQ", "param": "Gain-Band 2", "value": 0.55},
+//	  {"fx": "ReaComp", "param": "Thresh", "value": 0.3},
+//	  {"track": 2, "fx": "ReaVerb", "occurrence": 1, "param": "Wet", "value": 0.2}
+//	]
+//
+// Files in the TemplateOverrides folder of the extension's data folder can be given by
+// name alone.
+
+// templateOverridesFolder holds named override files in the extension's data folder
+const templateOverridesFolder = "TemplateOverrides"
+
+// The track template action
+func init() {
+	registerAction(Action{
+		ID:      "GO_TRACK_TEMPLATE_INSERT",
+		Name:    "Go: Insert track template with parameter overrides...",
+		Handler: handleInsertTrackTemplate,
+	})
+}
+
+// templateOverrides is what a track template's new tracks are set to after inserting
+type templateOverrides struct {
+	Source   string                 // Shown in messages and the undo point
+	Params   []reaper.ParamOverride // From a JSON file
+	Snapshot *reaper.FXSnapshot     // From a project snapshot; its tracks apply in order
+}
+
+// trackTemplatesDir is REAPER's folder for track template files
+func trackTemplatesDir() (string, error) {
+	resourcePath, err := reaper.GetResourcePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(resourcePath, "TrackTemplates"), nil
+}
+
+// handleInsertTrackTemplate inserts a track template after the selected track, or at
+// the end, and applies overrides to it, as one undo point
+func handleInsertTrackTemplate() {
+	const title = "Insert Track Template"
+
+	// STEP 1: Choose the template
+	initialPath := ""
+	if dir, err := trackTemplatesDir(); err == nil {
+		initialPath = dir + string(filepath.Separator)
+	}
+	path, ok, err := reaper.BrowseForFile(title, initialPath, reaper.TrackTemplateExtension)
+	if err != nil {
+		core.HandleError(title, core.NewError(core.CategoryReaper, "Could not show the file dialog.", err))
+		return
+	}
+	if !ok {
+		return
+	}
+
+	chunks, err := reaper.ReadTrackTemplate(path)
+	if err != nil {
+		core.HandleError(title, core.NewError(core.CategoryStorage, fmt.Sprintf("Could not read the track template: %v", err), err).
+			WithDetail("path %s", path))
+		return
+	}
+
+	// STEP 2: Choose the overrides and a name for the new track
+	values, err := reaper.GetUserInputs(title,
+		[]string{"Overrides (snapshot, JSON file or name)", "Track name (optional)"},
+		[]string{"", ""})
+	if err != nil {
+		logger.Info("User cancelled the dialog")
+		return
+	}
+	overrides, err := loadTemplateOverrides(strings.TrimSpace(values[0]))
+	if err != nil {
+		core.HandleError(title, core.NewError(core.CategoryUser, err.Error(), err).
+			WithDetail("overrides %q", values[0]))
+		return
+	}
+	trackName := strings.TrimSpace(values[1])
+
+	// STEP 3: Insert the tracks and apply the overrides as one undo point
+	index := -1
+	if trackInfo, err := reaper.GetSelectedTrackInfo(); err == nil && trackInfo.Index > 0 {
+		index = trackInfo.Index
+	}
+
+	template := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if err := reaper.UndoBeginBlock(); err != nil {
+		logger.Warning("Failed to start undo block: %v", err)
+	} else {
+		defer func() {
+			description := "Insert track template " + template
+			if overrides.Source != "" {
+				description += " with " + overrides.Source
+			}
+			if err := reaper.UndoEndBlock(description, reaper.UndoStateAll); err != nil {
+				logger.Warning("Failed to end undo block: %v", err)
+			}
+		}()
+	}
+
+	tracks, err := reaper.InsertTrackTemplate(index, chunks)
+	if err != nil {
+		core.HandleError(title, core.NewError(core.CategoryReaper, "Could not insert the track template.", err).
+			WithDetail("path %s, %d of %d tracks inserted", path, len(tracks), len(chunks)))
+		return
+	}
+	if trackName != "" {
+		if err := reaper.SetTrackName(tracks[0], trackName); err != nil {
+			logger.Warning("Could not name the template track: %v", err)
+		}
+	}
+
+	applied, skipped, err := applyTemplateOverrides(tracks, overrides)
+	if err != nil {
+		core.HandleError(title, core.NewError(core.CategoryReaper, "The template was inserted, but the overrides couldn't be applied.", err).
+			WithDetail("overrides %s", overrides.Source))
+		return
+	}
+	logger.Info("Inserted track template %s with %d tracks, %d overrides applied and %d skipped", path, len(tracks), applied, len(skipped))
+
+	// STEP 4: Report overrides that didn't apply
+	if len(skipped) > 0 {
+		reaper.MessageBox(fmt.Sprintf("Inserted %s and applied %d overrides from %s. These were skipped:\n\n%s",
+			template, applied, overrides.Source, strings.Join(skipped, "\n")), title)
+	}
+}
+
+// loadTemplateOverrides finds overrides by what the user typed: the name of a snapshot
+// in the project, a JSON file's path, or the name of a file in the TemplateOverrides
+// folder. Empty means no overrides.
+func loadTemplateOverrides(name string) (templateOverrides, error) {
+	if name == "" {
+		return templateOverrides{}, nil
+	}
+
+	if snapshots, err := listSnapshots(); err == nil {
+		for _, snapshot := range snapshots {
+			if strings.EqualFold(snapshot.Name, name) {
+				return templateOverrides{Source: "snapshot " + snapshot.Name, Snapshot: &snapshot.Snapshot}, nil
+			}
+		}
+	} else {
+		logger.Warning("Could not list the project's FX snapshots: %v", err)
+	}
+
+	path := name
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(extensionDataDir(), templateOverridesFolder, sanitizeFileName(name))
+		if !strings.EqualFold(filepath.Ext(path), ".json") {
+			path += ".json"
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return templateOverrides{}, fmt.Errorf("%q is neither an FX snapshot in this project nor an overrides file (looked for %s)", name, path)
+		}
+		return templateOverrides{}, fmt.Errorf("could not read %s: %v", path, err)
+	}
+
+	var params []reaper.ParamOverride
+	if err := json.Unmarshal(data, &params); err != nil {
+		return templateOverrides{}, fmt.Errorf("%s is not a list of overrides: %v", filepath.Base(path), err)
+	}
+	for i, param := range params {
+		if param.FX == "" || param.Param == "" {
+			return templateOverrides{}, fmt.Errorf("override %d in %s needs an fx and a param", i+1, filepath.Base(path))
+		}
+		if param.Value < 0 || param.Value > 1 {
+			return templateOverrides{}, fmt.Errorf("override %d in %s has value %g; values are normalized, 0 to 1", i+1, filepath.Base(path), param.Value)
+		}
+	}
+	return templateOverrides{Source: filepath.Base(path), Params: params}, nil
+}
+
+// applyTemplateOverrides sets the new tracks' parameters from overrides, returning how
+// many were applied (FX for a snapshot, parameters for a file) and what was skipped
+func applyTemplateOverrides(tracks []unsafe.Pointer, overrides templateOverrides) (int, []string, error) {
+	if overrides.Snapshot == nil {
+		if len(overrides.Params) == 0 {
+			return 0, nil, nil
+		}
+		return reaper.ApplyParamOverrides(tracks, overrides.Params)
+	}
+
+	// Snapshot tracks apply to template tracks in order, since the template's tracks
+	// are new and share no GUIDs with the snapshot's
+	total := 0
+	var skipped []string
+	for i, snapshotTrack := range overrides.Snapshot.Tracks {
+		if i >= len(tracks) {
+			skipped = append(skipped, fmt.Sprintf("%s: the template has no track %d", snapshotTrack.Name, i+1))
+			continue
+		}
+		applied, trackSkipped, err := reaper.ApplyFXValues(tracks[i], snapshotTrack.FX)
+		if err != nil {
+			return total, skipped, err
+		}
+		total += applied
+		skipped = append(skipped, trackSkipped...)
+	}
+	return total, skipped, nil
+}
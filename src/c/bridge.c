@@ -5,11 +5,25 @@
 // The bridge pattern allows Go code to call REAPER API functions and
 // allows REAPER to call back into Go code through registered callbacks.
 
+// dladdr is a GNU extension on Linux
+#if defined(__linux__) && !defined(_GNU_SOURCE)
+#define _GNU_SOURCE
+#endif
+
 #include "bridge.h"
 #include "logging.h"
 
+#ifndef _WIN32
+#include <pthread.h>
+#include <dlfcn.h>
+#include <stddef.h>
+#endif
+
 // Implementation of the bridge functions
 
+// Number of GetFunc lookups, for profiling. Only changed on the main thread.
+static long long s_GetFuncCalls = 0;
+
 /**
  * REAPER's GetFunc to retrieve an API function pointer by name
  * This is the fundamental bootstrap mechanism for accessing REAPER's API
@@ -23,6 +37,7 @@ void* plugin_bridge_call_get_func(void* get_func_ptr, const char* name) {
         return NULL;
     }
     
+    s_GetFuncCalls++;
     void* (*get_func)(const char*) = (void* (*)(const char*))get_func_ptr;
     void* result = get_func(name);
     
@@ -89,6 +104,144 @@ void* plugin_bridge_call_get_selected_track(void* func_ptr, int proj, int seltra
     return result;
 }
 
+/**
+ * REAPER's GetTrack function
+ * A NULL proj refers to the active project
+ */
+void* plugin_bridge_call_get_track(void* func_ptr, void* proj, int trackidx) {
+    LOG_DEBUG("Called with func_ptr=%p, proj=%p, trackidx=%d", func_ptr, proj, trackidx);
+    
+    if (!func_ptr) {
+        LOG_ERROR("Invalid parameter: func_ptr is NULL");
+        return NULL;
+    }
+    
+    void* (*get_track)(void*, int) = (void* (*)(void*, int))func_ptr;
+    LOG_DEBUG("Calling GetTrack with proj=%p, trackidx=%d", proj, trackidx);
+    void* result = get_track(proj, trackidx);
+    LOG_DEBUG("GetTrack call completed with result: %p", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's GetMasterTrack function
+ * A NULL proj refers to the active project
+ */
+void* plugin_bridge_call_get_master_track(void* func_ptr, void* proj) {
+    LOG_DEBUG("Called with func_ptr=%p, proj=%p", func_ptr, proj);
+    
+    if (!func_ptr) {
+        LOG_ERROR("Invalid parameter: func_ptr is NULL");
+        return NULL;
+    }
+    
+    void* (*get_master_track)(void*) = (void* (*)(void*))func_ptr;
+    LOG_DEBUG("Calling GetMasterTrack with proj=%p", proj);
+    void* result = get_master_track(proj);
+    LOG_DEBUG("GetMasterTrack call completed with result: %p", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's GetMousePosition function
+ */
+void plugin_bridge_call_get_mouse_position(void* func_ptr, int* x, int* y) {
+    LOG_DEBUG("Called with func_ptr=%p, x=%p, y=%p", func_ptr, x, y);
+    
+    if (!func_ptr || !x || !y) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, x=%p, y=%p", func_ptr, x, y);
+        return;
+    }
+    
+    void (*get_mouse_position)(int*, int*) = (void (*)(int*, int*))func_ptr;
+    LOG_DEBUG("Calling GetMousePosition");
+    get_mouse_position(x, y);
+    LOG_DEBUG("GetMousePosition call completed: x=%d, y=%d", *x, *y);
+}
+
+/**
+ * REAPER's GetTrackFromPoint function
+ * info may be NULL; otherwise it receives 1 for an envelope, 2 for the FX chain
+ */
+void* plugin_bridge_call_get_track_from_point(void* func_ptr, int screen_x, int screen_y, int* info) {
+    LOG_DEBUG("Called with func_ptr=%p, screen_x=%d, screen_y=%d, info=%p", func_ptr, screen_x, screen_y, info);
+    
+    if (!func_ptr) {
+        LOG_ERROR("Invalid parameter: func_ptr is NULL");
+        return NULL;
+    }
+    
+    void* (*get_track_from_point)(int, int, int*) = (void* (*)(int, int, int*))func_ptr;
+    LOG_DEBUG("Calling GetTrackFromPoint with screen_x=%d, screen_y=%d", screen_x, screen_y);
+    void* result = get_track_from_point(screen_x, screen_y, info);
+    LOG_DEBUG("GetTrackFromPoint call completed with result: %p", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's GetFocusedFX2 function
+ */
+int plugin_bridge_call_get_focused_fx2(void* func_ptr, int* tracknumber, int* itemnumber, int* fxnumber) {
+    LOG_DEBUG("Called with func_ptr=%p, tracknumber=%p, itemnumber=%p, fxnumber=%p", func_ptr, tracknumber, itemnumber, fxnumber);
+    
+    if (!func_ptr || !tracknumber || !itemnumber || !fxnumber) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, tracknumber=%p, itemnumber=%p, fxnumber=%p",
+                  func_ptr, tracknumber, itemnumber, fxnumber);
+        return 0;
+    }
+    
+    int (*get_focused_fx2)(int*, int*, int*) = (int (*)(int*, int*, int*))func_ptr;
+    LOG_DEBUG("Calling GetFocusedFX2");
+    int result = get_focused_fx2(tracknumber, itemnumber, fxnumber);
+    LOG_DEBUG("GetFocusedFX2 call completed with result: %d", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's GetLastTouchedFX function
+ */
+bool plugin_bridge_call_get_last_touched_fx(void* func_ptr, int* tracknumber, int* fxnumber, int* paramnumber) {
+    LOG_DEBUG("Called with func_ptr=%p, tracknumber=%p, fxnumber=%p, paramnumber=%p", func_ptr, tracknumber, fxnumber, paramnumber);
+    
+    if (!func_ptr || !tracknumber || !fxnumber || !paramnumber) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, tracknumber=%p, fxnumber=%p, paramnumber=%p",
+                  func_ptr, tracknumber, fxnumber, paramnumber);
+        return false;
+    }
+    
+    bool (*get_last_touched_fx)(int*, int*, int*) = (bool (*)(int*, int*, int*))func_ptr;
+    LOG_DEBUG("Calling GetLastTouchedFX");
+    bool result = get_last_touched_fx(tracknumber, fxnumber, paramnumber);
+    LOG_DEBUG("GetLastTouchedFX call completed with result: %d", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's ValidatePtr2 function
+ * A NULL proj refers to the active project
+ */
+bool plugin_bridge_call_validate_ptr2(void* func_ptr, void* proj, void* pointer, const char* ctypename) {
+    LOG_DEBUG("Called with func_ptr=%p, proj=%p, pointer=%p, ctypename=%s", func_ptr, proj, pointer, ctypename ? ctypename : "NULL");
+    
+    // Verify input pointers aren't NULL
+    if (!func_ptr || !ctypename) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, ctypename=%p", func_ptr, ctypename);
+        return false;
+    }
+    
+    bool (*validate_ptr2)(void*, void*, const char*) = (bool (*)(void*, void*, const char*))func_ptr;
+    LOG_DEBUG("Calling ValidatePtr2 with pointer=%p, ctypename=%s", pointer, ctypename);
+    bool result = validate_ptr2(proj, pointer, ctypename);
+    LOG_DEBUG("ValidatePtr2 call completed with result: %d", result);
+    
+    return result;
+}
+
 /**
  * REAPER's TrackFX_GetCount function
  */
@@ -262,30 +415,15 @@ bool plugin_bridge_call_track_fx_set_param(void* func_ptr, void* track, int fx_i
     return result;
 }
 
-// Get track information value
-double plugin_bridge_call_track_get_info_value(void* func_ptr, void* track, const char* param) {
-    LOG_DEBUG("Called with func_ptr=%p, track=%p, param=%s", 
-              func_ptr, track, param ? param : "NULL");
-    
-    if (!func_ptr || !track || !param) {
-        LOG_ERROR("Invalid parameters: func_ptr=%p, track=%p, param=%p", 
-                  func_ptr, track, param);
-        return 0.0;
-    }
-    
-    double (*get_track_info)(void*, const char*) = (double (*)(void*, const char*))func_ptr;
-    LOG_DEBUG("Calling GetMediaTrackInfo_Value with track=%p, param=%s", track, param);
-    double result = get_track_info(track, param);
-    LOG_DEBUG("GetMediaTrackInfo_Value call completed with result: %f", result);
-    
-    return result;
-}
-
-// Get track name
-bool plugin_bridge_call_get_track_name(void* func_ptr, void* track, char* buf, int buf_size, int* flags) {
-    LOG_DEBUG("Called with func_ptr=%p, track=%p, buf=%p, buf_size=%d, flags=%p", 
-              func_ptr, track, buf, buf_size, flags);
+/**
+ * REAPER's TrackFX_FormatParamValue function
+ * Formats an arbitrary normalized value without applying it to the FX
+ */
+bool plugin_bridge_call_track_fx_format_param_value(void* func_ptr, void* track, int fx_idx, int param_idx, double val, char* buf, int buf_size) {
+    LOG_DEBUG("Called with func_ptr=%p, track=%p, fx_idx=%d, param_idx=%d, val=%f, buf=%p, buf_size=%d", 
+              func_ptr, track, fx_idx, param_idx, val, buf, buf_size);
     
+    // Verify input pointers aren't NULL
     if (!func_ptr || !track || !buf || buf_size <= 0) {
         LOG_ERROR("Invalid parameters: func_ptr=%p, track=%p, buf=%p, buf_size=%d", 
                   func_ptr, track, buf, buf_size);
@@ -297,192 +435,3017 @@ bool plugin_bridge_call_get_track_name(void* func_ptr, void* track, char* buf, i
         return false;
     }
     
-    bool (*get_track_name)(void*, char*, int, int*) = 
-        (bool (*)(void*, char*, int, int*))func_ptr;
+    bool (*track_fx_format_param_value)(void*, int, int, double, char*, int) = 
+        (bool (*)(void*, int, int, double, char*, int))func_ptr;
+    LOG_DEBUG("Calling TrackFX_FormatParamValue with track=%p, fx_idx=%d, param_idx=%d, val=%f", 
+              track, fx_idx, param_idx, val);
+    bool result = track_fx_format_param_value(track, fx_idx, param_idx, val, buf, buf_size);
+    LOG_DEBUG("TrackFX_FormatParamValue call completed with result: %d, formatted=%s", result, buf);
     
-    LOG_DEBUG("Calling GetTrackName with track=%p", track);
-    bool result = get_track_name(track, buf, buf_size, flags);
+    return result;
+}
+
+/**
+ * REAPER's TrackFX_GetEnabled function
+ * Returns false when the FX is bypassed
+ */
+bool plugin_bridge_call_track_fx_get_enabled(void* func_ptr, void* track, int fx_idx) {
+    LOG_DEBUG("Called with func_ptr=%p, track=%p, fx_idx=%d", func_ptr, track, fx_idx);
     
-    // Log flags if provided
-    if (flags) {
-        LOG_DEBUG("GetTrackName call completed with result: %d, name=%s, flags=%d", 
-                  result, buf, *flags);
-    } else {
-        LOG_DEBUG("GetTrackName call completed with result: %d, name=%s", 
-                  result, buf);
+    // Verify input pointers aren't NULL
+    if (!func_ptr || !track) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, track=%p", func_ptr, track);
+        return false;
     }
     
+    bool (*track_fx_get_enabled)(void*, int) = (bool (*)(void*, int))func_ptr;
+    LOG_DEBUG("Calling TrackFX_GetEnabled with track=%p, fx_idx=%d", track, fx_idx);
+    bool result = track_fx_get_enabled(track, fx_idx);
+    LOG_DEBUG("TrackFX_GetEnabled call completed with result: %d", result);
+    
     return result;
 }
 
 /**
- * REAPER's GetUserInputs function for creating simple form dialogs
+ * REAPER's TrackFX_SetEnabled function
+ * Enables or bypasses an FX
  */
-bool plugin_bridge_call_get_user_inputs(void* func_ptr, const char* title, int num_inputs, 
-    const char* captions, char* values, int values_sz) {
-    LOG_DEBUG("Called with func_ptr=%p, title=%s, num_inputs=%d, captions=%s, values_sz=%d", 
-              func_ptr, title ? title : "NULL", num_inputs, 
-              captions ? captions : "NULL", values_sz);
+void plugin_bridge_call_track_fx_set_enabled(void* func_ptr, void* track, int fx_idx, bool enabled) {
+    LOG_DEBUG("Called with func_ptr=%p, track=%p, fx_idx=%d, enabled=%d", func_ptr, track, fx_idx, enabled);
     
     // Verify input pointers aren't NULL
-    if (!func_ptr || !title || !captions || !values || values_sz <= 0) {
-        LOG_ERROR("Invalid parameters: func_ptr=%p, title=%p, captions=%p, values=%p, values_sz=%d", 
-                  func_ptr, title, captions, values, values_sz);
-        return false;
+    if (!func_ptr || !track) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, track=%p", func_ptr, track);
+        return;
     }
+    
+    void (*track_fx_set_enabled)(void*, int, bool) = (void (*)(void*, int, bool))func_ptr;
+    LOG_DEBUG("Calling TrackFX_SetEnabled with track=%p, fx_idx=%d, enabled=%d", track, fx_idx, enabled);
+    track_fx_set_enabled(track, fx_idx, enabled);
+    LOG_DEBUG("TrackFX_SetEnabled call completed");
+}
 
-    bool (*get_user_inputs)(const char*, int, const char*, char*, int) =
-        (bool (*)(const char*, int, const char*, char*, int))func_ptr;
-
-    LOG_DEBUG("Calling GetUserInputs with title=%s, num_inputs=%d", title, num_inputs);
-    bool result = get_user_inputs(title, num_inputs, captions, values, values_sz);
-    LOG_DEBUG("GetUserInputs call completed with result: %d, values=%s", result, values);
+/**
+ * REAPER's TrackFX_GetNamedConfigParm function
+ * Reads named FX properties such as "fx_type", "fx_ident" or "fx_name"
+ */
+bool plugin_bridge_call_track_fx_get_named_config_parm(void* func_ptr, void* track, int fx_idx, const char* parm_name, char* buf, int buf_size) {
+    LOG_DEBUG("Called with func_ptr=%p, track=%p, fx_idx=%d, parm_name=%s, buf=%p, buf_size=%d", 
+              func_ptr, track, fx_idx, parm_name ? parm_name : "NULL", buf, buf_size);
+    
+    // Verify input pointers aren't NULL
+    if (!func_ptr || !track || !parm_name || !buf || buf_size <= 0) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, track=%p, parm_name=%p, buf=%p, buf_size=%d", 
+                  func_ptr, track, parm_name, buf, buf_size);
+        // If buffer is valid, make it an empty string for safety
+        if (buf && buf_size > 0) {
+            buf[0] = '\0';
+        }
+        return false;
+    }
+    
+    buf[0] = '\0';
+    
+    bool (*track_fx_get_named_config_parm)(void*, int, const char*, char*, int) = 
+        (bool (*)(void*, int, const char*, char*, int))func_ptr;
+    LOG_DEBUG("Calling TrackFX_GetNamedConfigParm with track=%p, fx_idx=%d, parm_name=%s", track, fx_idx, parm_name);
+    bool result = track_fx_get_named_config_parm(track, fx_idx, parm_name, buf, buf_size);
+    LOG_DEBUG("TrackFX_GetNamedConfigParm call completed with result: %d, value=%s", result, buf);
     
     return result;
 }
 
 /**
-* REAPER's ShowMessageBox function for standard message boxes
-*/
-int plugin_bridge_call_show_message_box(void* func_ptr, const char* text, const char* title, int type) {
-    LOG_DEBUG("Called with func_ptr=%p, text=%s, title=%s, type=%d", 
-              func_ptr, text ? text : "NULL", title ? title : "NULL", type);
+ * REAPER's TrackFX_GetParamFromIdent function
+ * Resolves special identifiers like ":wet" or ":delta" to a parameter index, -1 if unknown
+ */
+int plugin_bridge_call_track_fx_get_param_from_ident(void* func_ptr, void* track, int fx_idx, const char* ident) {
+    LOG_DEBUG("Called with func_ptr=%p, track=%p, fx_idx=%d, ident=%s", 
+              func_ptr, track, fx_idx, ident ? ident : "NULL");
     
     // Verify input pointers aren't NULL
-    if (!func_ptr || !text || !title) {
-        LOG_ERROR("Invalid parameters: func_ptr=%p, text=%p, title=%p", 
-                  func_ptr, text, title);
-        return 0; // Return IDOK (1) by default on error
+    if (!func_ptr || !track || !ident) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, track=%p, ident=%p", func_ptr, track, ident);
+        return -1;
     }
-
-    int (*show_message_box)(const char*, const char*, int) =
-        (int (*)(const char*, const char*, int))func_ptr;
-
-    LOG_DEBUG("Calling ShowMessageBox with text='%s', title='%s', type=%d", text, title, type);
-    int result = show_message_box(text, title, type);
-    LOG_DEBUG("ShowMessageBox call completed with result: %d", result);
+    
+    int (*track_fx_get_param_from_ident)(void*, int, const char*) = 
+        (int (*)(void*, int, const char*))func_ptr;
+    LOG_DEBUG("Calling TrackFX_GetParamFromIdent with track=%p, fx_idx=%d, ident=%s", track, fx_idx, ident);
+    int result = track_fx_get_param_from_ident(track, fx_idx, ident);
+    LOG_DEBUG("TrackFX_GetParamFromIdent call completed with result: %d", result);
     
     return result;
 }
 
 /**
- * Function to batch retrieve all FX parameters in a single call
- * This reduces the number of C-Go crossings dramatically
+ * REAPER's TrackFX_GetOffline function
  */
-bool plugin_bridge_batch_get_fx_parameters(void* track, int fx_idx, fx_param_t* params, 
-    int max_params, int* out_param_count) {
-    LOG_DEBUG("Called with track=%p, fx_idx=%d, params=%p, max_params=%d", 
-    track, fx_idx, params, max_params);
-
-    // Verify input pointers
-    if (!track || !params || !out_param_count || max_params <= 0) {
-        LOG_ERROR("Invalid parameters: track=%p, params=%p, out_param_count=%p, max_params=%d",
-        track, params, out_param_count, max_params);
+bool plugin_bridge_call_track_fx_get_offline(void* func_ptr, void* track, int fx_idx) {
+    LOG_DEBUG("Called with func_ptr=%p, track=%p, fx_idx=%d", func_ptr, track, fx_idx);
+    
+    // Verify input pointers aren't NULL
+    if (!func_ptr || !track) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, track=%p", func_ptr, track);
         return false;
     }
+    
+    bool (*track_fx_get_offline)(void*, int) = (bool (*)(void*, int))func_ptr;
+    LOG_DEBUG("Calling TrackFX_GetOffline with track=%p, fx_idx=%d", track, fx_idx);
+    bool result = track_fx_get_offline(track, fx_idx);
+    LOG_DEBUG("TrackFX_GetOffline call completed with result: %d", result);
+    
+    return result;
+}
 
-    // Get the GetFunc function using our bridge
-    void* getFuncPtr = plugin_bridge_get_get_func();
-    if (!getFuncPtr) {
-        LOG_ERROR("Failed to get GetFunc pointer");
-        return false;
+/**
+ * REAPER's TrackFX_SetOffline function
+ * Offline FX are unloaded and use no CPU
+ */
+void plugin_bridge_call_track_fx_set_offline(void* func_ptr, void* track, int fx_idx, bool offline) {
+    LOG_DEBUG("Called with func_ptr=%p, track=%p, fx_idx=%d, offline=%d", func_ptr, track, fx_idx, offline);
+    
+    // Verify input pointers aren't NULL
+    if (!func_ptr || !track) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, track=%p", func_ptr, track);
+        return;
     }
+    
+    void (*track_fx_set_offline)(void*, int, bool) = (void (*)(void*, int, bool))func_ptr;
+    LOG_DEBUG("Calling TrackFX_SetOffline with track=%p, fx_idx=%d, offline=%d", track, fx_idx, offline);
+    track_fx_set_offline(track, fx_idx, offline);
+    LOG_DEBUG("TrackFX_SetOffline call completed");
+}
 
-    // Get the number of parameters function
-    void* getParamCountFunc = NULL;
-    {
-        char funcName[64] = "TrackFX_GetNumParams";
-        getParamCountFunc = plugin_bridge_call_get_func(getFuncPtr, funcName);
-        if (!getParamCountFunc) {
-            LOG_ERROR("Failed to get TrackFX_GetNumParams function pointer");
-            return false;
-        }
+/**
+ * REAPER's TrackFX_CopyToTrack function
+ * Copies or moves an FX, also used to reorder FX within a chain
+ */
+void plugin_bridge_call_track_fx_copy_to_track(void* func_ptr, void* src_track, int src_fx, void* dest_track, int dest_fx, bool is_move) {
+    LOG_DEBUG("Called with func_ptr=%p, src_track=%p, src_fx=%d, dest_track=%p, dest_fx=%d, is_move=%d", 
+              func_ptr, src_track, src_fx, dest_track, dest_fx, is_move);
+    
+    // Verify input pointers aren't NULL
+    if (!func_ptr || !src_track || !dest_track) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, src_track=%p, dest_track=%p", func_ptr, src_track, dest_track);
+        return;
     }
+    
+    void (*track_fx_copy_to_track)(void*, int, void*, int, bool) = 
+        (void (*)(void*, int, void*, int, bool))func_ptr;
+    LOG_DEBUG("Calling TrackFX_CopyToTrack");
+    track_fx_copy_to_track(src_track, src_fx, dest_track, dest_fx, is_move);
+    LOG_DEBUG("TrackFX_CopyToTrack call completed");
+}
 
-    // Get the parameter functions we need
-    void* getParamNameFunc = NULL;
-    void* getParamFunc = NULL;
-    void* getFormattedFunc = NULL;
-
-    {
-        char funcName[64] = "TrackFX_GetParamName";
-        getParamNameFunc = plugin_bridge_call_get_func(getFuncPtr, funcName);
-        if (!getParamNameFunc) {
-            LOG_ERROR("Failed to get TrackFX_GetParamName function pointer");
-            return false;
-        }
+/**
+ * REAPER's DockWindowAddEx function
+ * identstr lets REAPER remember which docker the window was in
+ */
+void plugin_bridge_call_dock_window_add_ex(void* func_ptr, void* hwnd, const char* name, const char* identstr, bool allow_show) {
+    LOG_DEBUG("Called with func_ptr=%p, hwnd=%p, name=%s, identstr=%s, allow_show=%d", 
+              func_ptr, hwnd, name ? name : "NULL", identstr ? identstr : "NULL", allow_show);
+    
+    // Verify input pointers aren't NULL
+    if (!func_ptr || !hwnd || !name || !identstr) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, hwnd=%p, name=%p, identstr=%p", func_ptr, hwnd, name, identstr);
+        return;
     }
+    
+    void (*dock_window_add_ex)(void*, const char*, const char*, bool) = 
+        (void (*)(void*, const char*, const char*, bool))func_ptr;
+    LOG_DEBUG("Calling DockWindowAddEx with hwnd=%p, name=%s", hwnd, name);
+    dock_window_add_ex(hwnd, name, identstr, allow_show);
+    LOG_DEBUG("DockWindowAddEx call completed");
+}
 
-    {
-        char funcName[64] = "TrackFX_GetParam";
-        getParamFunc = plugin_bridge_call_get_func(getFuncPtr, funcName);
-        if (!getParamFunc) {
-            LOG_ERROR("Failed to get TrackFX_GetParam function pointer");
-            return false;
-        }
+/**
+ * REAPER's DockWindowRemove function
+ */
+void plugin_bridge_call_dock_window_remove(void* func_ptr, void* hwnd) {
+    LOG_DEBUG("Called with func_ptr=%p, hwnd=%p", func_ptr, hwnd);
+    
+    // Verify input pointers aren't NULL
+    if (!func_ptr || !hwnd) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, hwnd=%p", func_ptr, hwnd);
+        return;
     }
+    
+    void (*dock_window_remove)(void*) = (void (*)(void*))func_ptr;
+    LOG_DEBUG("Calling DockWindowRemove with hwnd=%p", hwnd);
+    dock_window_remove(hwnd);
+    LOG_DEBUG("DockWindowRemove call completed");
+}
 
-    {
-        char funcName[64] = "TrackFX_GetFormattedParamValue";
-        getFormattedFunc = plugin_bridge_call_get_func(getFuncPtr, funcName);
+/**
+ * REAPER's DockWindowActivate function
+ */
+void plugin_bridge_call_dock_window_activate(void* func_ptr, void* hwnd) {
+    LOG_DEBUG("Called with func_ptr=%p, hwnd=%p", func_ptr, hwnd);
+    
+    // Verify input pointers aren't NULL
+    if (!func_ptr || !hwnd) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, hwnd=%p", func_ptr, hwnd);
+        return;
+    }
+    
+    void (*dock_window_activate)(void*) = (void (*)(void*))func_ptr;
+    LOG_DEBUG("Calling DockWindowActivate with hwnd=%p", hwnd);
+    dock_window_activate(hwnd);
+    LOG_DEBUG("DockWindowActivate call completed");
+}
+
+/**
+ * REAPER's DockIsChildOfDock function
+ * Returns the docker index, or -1 if the window isn't docked
+ */
+int plugin_bridge_call_dock_is_child_of_dock(void* func_ptr, void* hwnd, bool* is_floating) {
+    LOG_DEBUG("Called with func_ptr=%p, hwnd=%p, is_floating=%p", func_ptr, hwnd, is_floating);
+    
+    // Verify input pointers aren't NULL
+    if (!func_ptr || !hwnd) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, hwnd=%p", func_ptr, hwnd);
+        return -1;
+    }
+    
+    int (*dock_is_child_of_dock)(void*, bool*) = (int (*)(void*, bool*))func_ptr;
+    LOG_DEBUG("Calling DockIsChildOfDock with hwnd=%p", hwnd);
+    int result = dock_is_child_of_dock(hwnd, is_floating);
+    LOG_DEBUG("DockIsChildOfDock call completed with result: %d", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's Undo_BeginBlock2 function
+ */
+void plugin_bridge_call_undo_begin_block2(void* func_ptr, void* proj) {
+    LOG_DEBUG("Called with func_ptr=%p, proj=%p", func_ptr, proj);
+    
+    // proj may be NULL for the active project
+    if (!func_ptr) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p", func_ptr);
+        return;
+    }
+    
+    void (*undo_begin_block2)(void*) = (void (*)(void*))func_ptr;
+    LOG_DEBUG("Calling Undo_BeginBlock2 with proj=%p", proj);
+    undo_begin_block2(proj);
+    LOG_DEBUG("Undo_BeginBlock2 call completed");
+}
+
+/**
+ * REAPER's Undo_EndBlock2 function
+ */
+void plugin_bridge_call_undo_end_block2(void* func_ptr, void* proj, const char* desc, int extraflags) {
+    LOG_DEBUG("Called with func_ptr=%p, proj=%p, desc=%s, extraflags=%d", func_ptr, proj, desc ? desc : "NULL", extraflags);
+    
+    // proj may be NULL for the active project
+    if (!func_ptr || !desc) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, desc=%p", func_ptr, desc);
+        return;
+    }
+    
+    void (*undo_end_block2)(void*, const char*, int) = (void (*)(void*, const char*, int))func_ptr;
+    LOG_DEBUG("Calling Undo_EndBlock2 with proj=%p, desc=%s, extraflags=%d", proj, desc, extraflags);
+    undo_end_block2(proj, desc, extraflags);
+    LOG_DEBUG("Undo_EndBlock2 call completed");
+}
+
+/**
+ * REAPER's CountTracks function
+ */
+int plugin_bridge_call_count_tracks(void* func_ptr, void* proj) {
+    LOG_DEBUG("Called with func_ptr=%p, proj=%p", func_ptr, proj);
+    
+    // proj may be NULL for the active project
+    if (!func_ptr) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p", func_ptr);
+        return 0;
+    }
+    
+    int (*count_tracks)(void*) = (int (*)(void*))func_ptr;
+    LOG_DEBUG("Calling CountTracks with proj=%p", proj);
+    int result = count_tracks(proj);
+    LOG_DEBUG("CountTracks call completed with result: %d", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's InsertTrackAtIndex function
+ */
+void plugin_bridge_call_insert_track_at_index(void* func_ptr, int idx, bool want_defaults) {
+    LOG_DEBUG("Called with func_ptr=%p, idx=%d, want_defaults=%d", func_ptr, idx, want_defaults);
+    
+    if (!func_ptr) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p", func_ptr);
+        return;
+    }
+    
+    void (*insert_track_at_index)(int, bool) = (void (*)(int, bool))func_ptr;
+    LOG_DEBUG("Calling InsertTrackAtIndex with idx=%d, want_defaults=%d", idx, want_defaults);
+    insert_track_at_index(idx, want_defaults);
+    LOG_DEBUG("InsertTrackAtIndex call completed");
+}
+
+/**
+ * REAPER's DeleteTrack function
+ */
+void plugin_bridge_call_delete_track(void* func_ptr, void* track) {
+    LOG_DEBUG("Called with func_ptr=%p, track=%p", func_ptr, track);
+    
+    if (!func_ptr || !track) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, track=%p", func_ptr, track);
+        return;
+    }
+    
+    void (*delete_track)(void*) = (void (*)(void*))func_ptr;
+    LOG_DEBUG("Calling DeleteTrack with track=%p", track);
+    delete_track(track);
+    LOG_DEBUG("DeleteTrack call completed");
+}
+
+/**
+ * REAPER's TrackFX_AddByName function
+ * instantiate: 0 to only look the FX up, -1 to always add it, or -1000-n to add it at position n
+ */
+int plugin_bridge_call_track_fx_add_by_name(void* func_ptr, void* track, const char* fxname, bool rec_fx, int instantiate) {
+    LOG_DEBUG("Called with func_ptr=%p, track=%p, fxname=%s, rec_fx=%d, instantiate=%d",
+              func_ptr, track, fxname ? fxname : "NULL", rec_fx, instantiate);
+    
+    if (!func_ptr || !track || !fxname) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, track=%p, fxname=%p", func_ptr, track, fxname);
+        return -1;
+    }
+    
+    int (*track_fx_add_by_name)(void*, const char*, bool, int) = (int (*)(void*, const char*, bool, int))func_ptr;
+    LOG_DEBUG("Calling TrackFX_AddByName with track=%p, fxname=%s", track, fxname);
+    int result = track_fx_add_by_name(track, fxname, rec_fx, instantiate);
+    LOG_DEBUG("TrackFX_AddByName call completed with result: %d", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's Main_OnCommandEx function
+ */
+void plugin_bridge_call_main_on_command_ex(void* func_ptr, int command, int flag, void* proj) {
+    LOG_DEBUG("Called with func_ptr=%p, command=%d, flag=%d, proj=%p", func_ptr, command, flag, proj);
+    
+    // proj may be NULL for the active project
+    if (!func_ptr) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p", func_ptr);
+        return;
+    }
+    
+    void (*main_on_command_ex)(int, int, void*) = (void (*)(int, int, void*))func_ptr;
+    LOG_DEBUG("Calling Main_OnCommandEx with command=%d", command);
+    main_on_command_ex(command, flag, proj);
+    LOG_DEBUG("Main_OnCommandEx call completed");
+}
+
+/**
+ * REAPER's NamedCommandLookup function
+ */
+int plugin_bridge_call_named_command_lookup(void* func_ptr, const char* command_name) {
+    LOG_DEBUG("Called with func_ptr=%p, command_name=%s", func_ptr, command_name ? command_name : "NULL");
+    
+    if (!func_ptr || !command_name) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, command_name=%p", func_ptr, command_name);
+        return 0;
+    }
+    
+    int (*named_command_lookup)(const char*) = (int (*)(const char*))func_ptr;
+    LOG_DEBUG("Calling NamedCommandLookup with command_name=%s", command_name);
+    int result = named_command_lookup(command_name);
+    LOG_DEBUG("NamedCommandLookup call completed with result: %d", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's ReverseNamedCommandLookup function
+ * Returns the command's name without the leading underscore, or NULL for built-in actions
+ */
+const char* plugin_bridge_call_reverse_named_command_lookup(void* func_ptr, int command_id) {
+    LOG_DEBUG("Called with func_ptr=%p, command_id=%d", func_ptr, command_id);
+    
+    if (!func_ptr) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p", func_ptr);
+        return NULL;
+    }
+    
+    const char* (*reverse_named_command_lookup)(int) = (const char* (*)(int))func_ptr;
+    LOG_DEBUG("Calling ReverseNamedCommandLookup with command_id=%d", command_id);
+    const char* result = reverse_named_command_lookup(command_id);
+    LOG_DEBUG("ReverseNamedCommandLookup call completed with result: %s", result ? result : "NULL");
+    
+    return result;
+}
+
+/**
+ * REAPER's GetToggleCommandStateEx function
+ * Returns -1 for actions without a toggle state, otherwise 0 (off) or 1 (on)
+ */
+int plugin_bridge_call_get_toggle_command_state_ex(void* func_ptr, int section_id, int command_id) {
+    LOG_DEBUG("Called with func_ptr=%p, section_id=%d, command_id=%d", func_ptr, section_id, command_id);
+    
+    if (!func_ptr) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p", func_ptr);
+        return -1;
+    }
+    
+    int (*get_toggle_command_state_ex)(int, int) = (int (*)(int, int))func_ptr;
+    LOG_DEBUG("Calling GetToggleCommandStateEx with section_id=%d, command_id=%d", section_id, command_id);
+    int result = get_toggle_command_state_ex(section_id, command_id);
+    LOG_DEBUG("GetToggleCommandStateEx call completed with result: %d", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's GetFXEnvelope function
+ */
+void* plugin_bridge_call_get_fx_envelope(void* func_ptr, void* track, int fx_idx, int param_idx, bool create) {
+    LOG_DEBUG("Called with func_ptr=%p, track=%p, fx_idx=%d, param_idx=%d, create=%d",
+              func_ptr, track, fx_idx, param_idx, create);
+    
+    if (!func_ptr || !track) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, track=%p", func_ptr, track);
+        return NULL;
+    }
+    
+    void* (*get_fx_envelope)(void*, int, int, bool) = (void* (*)(void*, int, int, bool))func_ptr;
+    LOG_DEBUG("Calling GetFXEnvelope with track=%p, fx_idx=%d, param_idx=%d", track, fx_idx, param_idx);
+    void* result = get_fx_envelope(track, fx_idx, param_idx, create);
+    LOG_DEBUG("GetFXEnvelope call completed with result: %p", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's GetTrackStateChunk function
+ * Returns false if the chunk doesn't fit in the buffer
+ */
+bool plugin_bridge_call_get_track_state_chunk(void* func_ptr, void* track, char* buf, int buf_size, bool is_undo) {
+    LOG_DEBUG("Called with func_ptr=%p, track=%p, buf=%p, buf_size=%d, is_undo=%d",
+              func_ptr, track, buf, buf_size, is_undo);
+    
+    if (!func_ptr || !track || !buf || buf_size <= 0) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, track=%p, buf=%p, buf_size=%d", func_ptr, track, buf, buf_size);
+        if (buf && buf_size > 0) {
+            buf[0] = '\0';
+        }
+        return false;
+    }
+    
+    buf[0] = '\0';
+    
+    bool (*get_track_state_chunk)(void*, char*, int, bool) = (bool (*)(void*, char*, int, bool))func_ptr;
+    LOG_DEBUG("Calling GetTrackStateChunk with track=%p, buf_size=%d", track, buf_size);
+    bool result = get_track_state_chunk(track, buf, buf_size, is_undo);
+    LOG_DEBUG("GetTrackStateChunk call completed with result: %d", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's SetTrackStateChunk function
+ */
+bool plugin_bridge_call_set_track_state_chunk(void* func_ptr, void* track, const char* chunk, bool is_undo) {
+    LOG_DEBUG("Called with func_ptr=%p, track=%p, chunk=%p, is_undo=%d", func_ptr, track, chunk, is_undo);
+    
+    if (!func_ptr || !track || !chunk) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, track=%p, chunk=%p", func_ptr, track, chunk);
+        return false;
+    }
+    
+    bool (*set_track_state_chunk)(void*, const char*, bool) = (bool (*)(void*, const char*, bool))func_ptr;
+    LOG_DEBUG("Calling SetTrackStateChunk with track=%p", track);
+    bool result = set_track_state_chunk(track, chunk, is_undo);
+    LOG_DEBUG("SetTrackStateChunk call completed with result: %d", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's GetUserFileNameForRead function
+ * filename must hold 4096 bytes; it is the initial path on entry and the chosen file on return
+ */
+bool plugin_bridge_call_get_user_file_name_for_read(void* func_ptr, char* filename, const char* title, const char* default_ext) {
+    LOG_DEBUG("Called with func_ptr=%p, filename=%p, title=%s, default_ext=%s",
+              func_ptr, filename, title ? title : "NULL", default_ext ? default_ext : "NULL");
+    
+    if (!func_ptr || !filename || !title || !default_ext) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, filename=%p, title=%p, default_ext=%p",
+                  func_ptr, filename, title, default_ext);
+        return false;
+    }
+    
+    bool (*get_user_file_name_for_read)(char*, const char*, const char*) =
+        (bool (*)(char*, const char*, const char*))func_ptr;
+    LOG_DEBUG("Calling GetUserFileNameForRead with title=%s", title);
+    bool result = get_user_file_name_for_read(filename, title, default_ext);
+    LOG_DEBUG("GetUserFileNameForRead call completed with result: %d", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's CountSelectedTracks2 function
+ */
+int plugin_bridge_call_count_selected_tracks2(void* func_ptr, void* proj, bool want_master) {
+    LOG_DEBUG("Called with func_ptr=%p, proj=%p, want_master=%d", func_ptr, proj, want_master);
+    
+    if (!func_ptr) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p", func_ptr);
+        return 0;
+    }
+    
+    int (*count_selected_tracks2)(void*, bool) = (int (*)(void*, bool))func_ptr;
+    LOG_DEBUG("Calling CountSelectedTracks2 with proj=%p", proj);
+    int result = count_selected_tracks2(proj, want_master);
+    LOG_DEBUG("CountSelectedTracks2 call completed with result: %d", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's GetCursorPositionEx function
+ */
+double plugin_bridge_call_get_cursor_position_ex(void* func_ptr, void* proj) {
+    LOG_DEBUG("Called with func_ptr=%p, proj=%p", func_ptr, proj);
+    
+    if (!func_ptr) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p", func_ptr);
+        return 0.0;
+    }
+    
+    double (*get_cursor_position_ex)(void*) = (double (*)(void*))func_ptr;
+    LOG_DEBUG("Calling GetCursorPositionEx with proj=%p", proj);
+    double result = get_cursor_position_ex(proj);
+    LOG_DEBUG("GetCursorPositionEx call completed with result: %f", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's EnumProjectMarkers3 function
+ * Returns the index to pass for the next marker or region, or 0 when there are no more.
+ * name points into REAPER's memory and must be copied before the project changes.
+ */
+int plugin_bridge_call_enum_project_markers3(void* func_ptr, void* proj, int idx, bool* isrgn, double* pos,
+    double* rgnend, const char** name, int* markrgnindexnumber, int* color) {
+    LOG_DEBUG("Called with func_ptr=%p, proj=%p, idx=%d", func_ptr, proj, idx);
+    
+    if (!func_ptr || !isrgn || !pos || !rgnend || !name || !markrgnindexnumber || !color) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, idx=%d", func_ptr, idx);
+        return 0;
+    }
+    
+    *name = NULL;
+    
+    int (*enum_project_markers3)(void*, int, bool*, double*, double*, const char**, int*, int*) =
+        (int (*)(void*, int, bool*, double*, double*, const char**, int*, int*))func_ptr;
+    LOG_DEBUG("Calling EnumProjectMarkers3 with proj=%p, idx=%d", proj, idx);
+    int result = enum_project_markers3(proj, idx, isrgn, pos, rgnend, name, markrgnindexnumber, color);
+    LOG_DEBUG("EnumProjectMarkers3 call completed with result: %d", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's GetLastMarkerAndCurRegion function
+ * markeridx and regionidx are enumeration indexes, -1 when there is none
+ */
+void plugin_bridge_call_get_last_marker_and_cur_region(void* func_ptr, void* proj, double time,
+    int* markeridx, int* regionidx) {
+    LOG_DEBUG("Called with func_ptr=%p, proj=%p, time=%f", func_ptr, proj, time);
+    
+    if (!func_ptr || !markeridx || !regionidx) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, markeridx=%p, regionidx=%p", func_ptr, markeridx, regionidx);
+        return;
+    }
+    
+    *markeridx = -1;
+    *regionidx = -1;
+    
+    void (*get_last_marker_and_cur_region)(void*, double, int*, int*) =
+        (void (*)(void*, double, int*, int*))func_ptr;
+    LOG_DEBUG("Calling GetLastMarkerAndCurRegion with proj=%p, time=%f", proj, time);
+    get_last_marker_and_cur_region(proj, time, markeridx, regionidx);
+    LOG_DEBUG("GetLastMarkerAndCurRegion call completed with markeridx=%d, regionidx=%d", *markeridx, *regionidx);
+}
+
+/**
+ * REAPER's EnumRegionRenderMatrix function
+ * regionindex is the region's number as shown in REAPER, not its enumeration index
+ */
+void* plugin_bridge_call_enum_region_render_matrix(void* func_ptr, void* proj, int regionindex, int rendertrack) {
+    LOG_DEBUG("Called with func_ptr=%p, proj=%p, regionindex=%d, rendertrack=%d",
+              func_ptr, proj, regionindex, rendertrack);
+    
+    if (!func_ptr) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p", func_ptr);
+        return NULL;
+    }
+    
+    void* (*enum_region_render_matrix)(void*, int, int) = (void* (*)(void*, int, int))func_ptr;
+    LOG_DEBUG("Calling EnumRegionRenderMatrix with proj=%p, regionindex=%d, rendertrack=%d",
+              proj, regionindex, rendertrack);
+    void* result = enum_region_render_matrix(proj, regionindex, rendertrack);
+    LOG_DEBUG("EnumRegionRenderMatrix call completed with result: %p", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's SetRegionRenderMatrix function
+ * flag 1 adds the track to the region's renders, -1 removes it
+ */
+void plugin_bridge_call_set_region_render_matrix(void* func_ptr, void* proj, int regionindex, void* track, int flag) {
+    LOG_DEBUG("Called with func_ptr=%p, proj=%p, regionindex=%d, track=%p, flag=%d",
+              func_ptr, proj, regionindex, track, flag);
+    
+    if (!func_ptr || !track) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, track=%p", func_ptr, track);
+        return;
+    }
+    
+    void (*set_region_render_matrix)(void*, int, void*, int) = (void (*)(void*, int, void*, int))func_ptr;
+    LOG_DEBUG("Calling SetRegionRenderMatrix with proj=%p, regionindex=%d, track=%p, flag=%d",
+              proj, regionindex, track, flag);
+    set_region_render_matrix(proj, regionindex, track, flag);
+    LOG_DEBUG("SetRegionRenderMatrix call completed");
+}
+
+/**
+ * REAPER's CountSelectedMediaItems function
+ * A NULL proj refers to the active project
+ */
+int plugin_bridge_call_count_selected_media_items(void* func_ptr, void* proj) {
+    LOG_DEBUG("Called with func_ptr=%p, proj=%p", func_ptr, proj);
+    
+    if (!func_ptr) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p", func_ptr);
+        return 0;
+    }
+    
+    int (*count_selected_media_items)(void*) = (int (*)(void*))func_ptr;
+    LOG_DEBUG("Calling CountSelectedMediaItems with proj=%p", proj);
+    int result = count_selected_media_items(proj);
+    LOG_DEBUG("CountSelectedMediaItems call completed with result: %d", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's GetSelectedMediaItem function
+ * Returns NULL if selitem is out of range
+ */
+void* plugin_bridge_call_get_selected_media_item(void* func_ptr, void* proj, int selitem) {
+    LOG_DEBUG("Called with func_ptr=%p, proj=%p, selitem=%d", func_ptr, proj, selitem);
+    
+    if (!func_ptr) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p", func_ptr);
+        return NULL;
+    }
+    
+    void* (*get_selected_media_item)(void*, int) = (void* (*)(void*, int))func_ptr;
+    LOG_DEBUG("Calling GetSelectedMediaItem with proj=%p, selitem=%d", proj, selitem);
+    void* result = get_selected_media_item(proj, selitem);
+    LOG_DEBUG("GetSelectedMediaItem call completed with result: %p", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's GetActiveTake function
+ * Returns NULL for an empty item
+ */
+void* plugin_bridge_call_get_active_take(void* func_ptr, void* item) {
+    LOG_DEBUG("Called with func_ptr=%p, item=%p", func_ptr, item);
+    
+    if (!func_ptr || !item) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, item=%p", func_ptr, item);
+        return NULL;
+    }
+    
+    void* (*get_active_take)(void*) = (void* (*)(void*))func_ptr;
+    LOG_DEBUG("Calling GetActiveTake with item=%p", item);
+    void* result = get_active_take(item);
+    LOG_DEBUG("GetActiveTake call completed with result: %p", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's GetMediaItem_Track function
+ */
+void* plugin_bridge_call_get_media_item_track(void* func_ptr, void* item) {
+    LOG_DEBUG("Called with func_ptr=%p, item=%p", func_ptr, item);
+    
+    if (!func_ptr || !item) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, item=%p", func_ptr, item);
+        return NULL;
+    }
+    
+    void* (*get_media_item_track)(void*) = (void* (*)(void*))func_ptr;
+    LOG_DEBUG("Calling GetMediaItem_Track with item=%p", item);
+    void* result = get_media_item_track(item);
+    LOG_DEBUG("GetMediaItem_Track call completed with result: %p", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's GetMediaItemInfo_Value function
+ * Reads a numeric item property such as D_POSITION or D_LENGTH
+ */
+double plugin_bridge_call_get_media_item_info_value(void* func_ptr, void* item, const char* parmname) {
+    LOG_DEBUG("Called with func_ptr=%p, item=%p, parmname=%s", func_ptr, item, parmname ? parmname : "NULL");
+    
+    if (!func_ptr || !item || !parmname) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, item=%p, parmname=%p", func_ptr, item, parmname);
+        return 0.0;
+    }
+    
+    double (*get_media_item_info_value)(void*, const char*) = (double (*)(void*, const char*))func_ptr;
+    LOG_DEBUG("Calling GetMediaItemInfo_Value with item=%p, parmname=%s", item, parmname);
+    double result = get_media_item_info_value(item, parmname);
+    LOG_DEBUG("GetMediaItemInfo_Value call completed with result: %f", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's SetMediaItemInfo_Value function
+ * Returns false if REAPER doesn't know the property
+ */
+bool plugin_bridge_call_set_media_item_info_value(void* func_ptr, void* item, const char* parmname, double value) {
+    LOG_DEBUG("Called with func_ptr=%p, item=%p, parmname=%s, value=%f",
+              func_ptr, item, parmname ? parmname : "NULL", value);
+    
+    if (!func_ptr || !item || !parmname) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, item=%p, parmname=%p", func_ptr, item, parmname);
+        return false;
+    }
+    
+    bool (*set_media_item_info_value)(void*, const char*, double) =
+        (bool (*)(void*, const char*, double))func_ptr;
+    LOG_DEBUG("Calling SetMediaItemInfo_Value with item=%p, parmname=%s, value=%f", item, parmname, value);
+    bool result = set_media_item_info_value(item, parmname, value);
+    LOG_DEBUG("SetMediaItemInfo_Value call completed with result: %d", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's GetMediaItemTakeInfo_Value function
+ * Reads a numeric take property such as D_STARTOFFS or D_PLAYRATE
+ */
+double plugin_bridge_call_get_media_item_take_info_value(void* func_ptr, void* take, const char* parmname) {
+    LOG_DEBUG("Called with func_ptr=%p, take=%p, parmname=%s", func_ptr, take, parmname ? parmname : "NULL");
+    
+    if (!func_ptr || !take || !parmname) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, take=%p, parmname=%p", func_ptr, take, parmname);
+        return 0.0;
+    }
+    
+    double (*get_media_item_take_info_value)(void*, const char*) = (double (*)(void*, const char*))func_ptr;
+    LOG_DEBUG("Calling GetMediaItemTakeInfo_Value with take=%p, parmname=%s", take, parmname);
+    double result = get_media_item_take_info_value(take, parmname);
+    LOG_DEBUG("GetMediaItemTakeInfo_Value call completed with result: %f", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's SetMediaItemTakeInfo_Value function
+ * Returns false if REAPER doesn't know the property
+ */
+bool plugin_bridge_call_set_media_item_take_info_value(void* func_ptr, void* take, const char* parmname, double value) {
+    LOG_DEBUG("Called with func_ptr=%p, take=%p, parmname=%s, value=%f",
+              func_ptr, take, parmname ? parmname : "NULL", value);
+    
+    if (!func_ptr || !take || !parmname) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, take=%p, parmname=%p", func_ptr, take, parmname);
+        return false;
+    }
+    
+    bool (*set_media_item_take_info_value)(void*, const char*, double) =
+        (bool (*)(void*, const char*, double))func_ptr;
+    LOG_DEBUG("Calling SetMediaItemTakeInfo_Value with take=%p, parmname=%s, value=%f", take, parmname, value);
+    bool result = set_media_item_take_info_value(take, parmname, value);
+    LOG_DEBUG("SetMediaItemTakeInfo_Value call completed with result: %d", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's GetTakeName function
+ * The name points into REAPER's memory and must be copied before the take changes
+ */
+const char* plugin_bridge_call_get_take_name(void* func_ptr, void* take) {
+    LOG_DEBUG("Called with func_ptr=%p, take=%p", func_ptr, take);
+    
+    if (!func_ptr || !take) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, take=%p", func_ptr, take);
+        return NULL;
+    }
+    
+    const char* (*get_take_name)(void*) = (const char* (*)(void*))func_ptr;
+    LOG_DEBUG("Calling GetTakeName with take=%p", take);
+    const char* result = get_take_name(take);
+    LOG_DEBUG("GetTakeName call completed with result: %s", result ? result : "NULL");
+    
+    return result;
+}
+
+/**
+ * REAPER's UpdateItemInProject function
+ * Redraws an item after its takes or markers changed
+ */
+void plugin_bridge_call_update_item_in_project(void* func_ptr, void* item) {
+    LOG_DEBUG("Called with func_ptr=%p, item=%p", func_ptr, item);
+    
+    if (!func_ptr || !item) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, item=%p", func_ptr, item);
+        return;
+    }
+    
+    void (*update_item_in_project)(void*) = (void (*)(void*))func_ptr;
+    LOG_DEBUG("Calling UpdateItemInProject with item=%p", item);
+    update_item_in_project(item);
+    LOG_DEBUG("UpdateItemInProject call completed");
+}
+
+/**
+ * REAPER's GetNumTakeMarkers function
+ */
+int plugin_bridge_call_get_num_take_markers(void* func_ptr, void* take) {
+    LOG_DEBUG("Called with func_ptr=%p, take=%p", func_ptr, take);
+    
+    if (!func_ptr || !take) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, take=%p", func_ptr, take);
+        return 0;
+    }
+    
+    int (*get_num_take_markers)(void*) = (int (*)(void*))func_ptr;
+    LOG_DEBUG("Calling GetNumTakeMarkers with take=%p", take);
+    int result = get_num_take_markers(take);
+    LOG_DEBUG("GetNumTakeMarkers call completed with result: %d", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's GetTakeMarker function
+ * Returns the marker's position in the source media, or -1 if there is no marker idx
+ */
+double plugin_bridge_call_get_take_marker(void* func_ptr, void* take, int idx, char* name, int name_size, int* color) {
+    LOG_DEBUG("Called with func_ptr=%p, take=%p, idx=%d", func_ptr, take, idx);
+    
+    if (!func_ptr || !take || !name || name_size <= 0 || !color) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, take=%p, name=%p, name_size=%d", func_ptr, take, name, name_size);
+        return -1.0;
+    }
+    
+    name[0] = '\0';
+    *color = 0;
+    
+    double (*get_take_marker)(void*, int, char*, int, int*) = (double (*)(void*, int, char*, int, int*))func_ptr;
+    LOG_DEBUG("Calling GetTakeMarker with take=%p, idx=%d", take, idx);
+    double result = get_take_marker(take, idx, name, name_size, color);
+    name[name_size - 1] = '\0';
+    LOG_DEBUG("GetTakeMarker call completed with result: %f, name=%s", result, name);
+    
+    return result;
+}
+
+/**
+ * REAPER's SetTakeMarker function
+ * idx -1 adds a marker, which needs srcpos. NULL srcpos or color leaves them unchanged.
+ * Returns the marker's index, or -1 on failure.
+ */
+int plugin_bridge_call_set_take_marker(void* func_ptr, void* take, int idx, const char* name, double* srcpos, int* color) {
+    LOG_DEBUG("Called with func_ptr=%p, take=%p, idx=%d, name=%s", func_ptr, take, idx, name ? name : "NULL");
+    
+    if (!func_ptr || !take || !name) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, take=%p, name=%p", func_ptr, take, name);
+        return -1;
+    }
+    
+    int (*set_take_marker)(void*, int, const char*, double*, int*) = (int (*)(void*, int, const char*, double*, int*))func_ptr;
+    LOG_DEBUG("Calling SetTakeMarker with take=%p, idx=%d", take, idx);
+    int result = set_take_marker(take, idx, name, srcpos, color);
+    LOG_DEBUG("SetTakeMarker call completed with result: %d", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's DeleteTakeMarker function
+ */
+bool plugin_bridge_call_delete_take_marker(void* func_ptr, void* take, int idx) {
+    LOG_DEBUG("Called with func_ptr=%p, take=%p, idx=%d", func_ptr, take, idx);
+    
+    if (!func_ptr || !take) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, take=%p", func_ptr, take);
+        return false;
+    }
+    
+    bool (*delete_take_marker)(void*, int) = (bool (*)(void*, int))func_ptr;
+    LOG_DEBUG("Calling DeleteTakeMarker with take=%p, idx=%d", take, idx);
+    bool result = delete_take_marker(take, idx);
+    LOG_DEBUG("DeleteTakeMarker call completed with result: %d", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's GetTakeNumStretchMarkers function
+ */
+int plugin_bridge_call_get_take_num_stretch_markers(void* func_ptr, void* take) {
+    LOG_DEBUG("Called with func_ptr=%p, take=%p", func_ptr, take);
+    
+    if (!func_ptr || !take) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, take=%p", func_ptr, take);
+        return 0;
+    }
+    
+    int (*get_take_num_stretch_markers)(void*) = (int (*)(void*))func_ptr;
+    LOG_DEBUG("Calling GetTakeNumStretchMarkers with take=%p", take);
+    int result = get_take_num_stretch_markers(take);
+    LOG_DEBUG("GetTakeNumStretchMarkers call completed with result: %d", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's GetTakeStretchMarker function
+ * Returns idx, or -1 if there is no stretch marker idx
+ */
+int plugin_bridge_call_get_take_stretch_marker(void* func_ptr, void* take, int idx, double* pos, double* srcpos) {
+    LOG_DEBUG("Called with func_ptr=%p, take=%p, idx=%d", func_ptr, take, idx);
+    
+    if (!func_ptr || !take || !pos || !srcpos) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, take=%p, pos=%p, srcpos=%p", func_ptr, take, pos, srcpos);
+        return -1;
+    }
+    
+    int (*get_take_stretch_marker)(void*, int, double*, double*) = (int (*)(void*, int, double*, double*))func_ptr;
+    LOG_DEBUG("Calling GetTakeStretchMarker with take=%p, idx=%d", take, idx);
+    int result = get_take_stretch_marker(take, idx, pos, srcpos);
+    LOG_DEBUG("GetTakeStretchMarker call completed with result: %d, pos=%f, srcpos=%f", result, *pos, *srcpos);
+    
+    return result;
+}
+
+/**
+ * REAPER's SetTakeStretchMarker function
+ * idx -1 adds a marker. A NULL srcpos is worked out by REAPER when adding and left
+ * unchanged when updating. Returns the marker's index, or -1 if none was set.
+ */
+int plugin_bridge_call_set_take_stretch_marker(void* func_ptr, void* take, int idx, double pos, const double* srcpos) {
+    LOG_DEBUG("Called with func_ptr=%p, take=%p, idx=%d, pos=%f", func_ptr, take, idx, pos);
+    
+    if (!func_ptr || !take) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, take=%p", func_ptr, take);
+        return -1;
+    }
+    
+    int (*set_take_stretch_marker)(void*, int, double, const double*) =
+        (int (*)(void*, int, double, const double*))func_ptr;
+    LOG_DEBUG("Calling SetTakeStretchMarker with take=%p, idx=%d, pos=%f", take, idx, pos);
+    int result = set_take_stretch_marker(take, idx, pos, srcpos);
+    LOG_DEBUG("SetTakeStretchMarker call completed with result: %d", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's DeleteTakeStretchMarkers function
+ * Deletes count markers from idx, or one if count is NULL. Returns how many were deleted.
+ */
+int plugin_bridge_call_delete_take_stretch_markers(void* func_ptr, void* take, int idx, const int* count) {
+    LOG_DEBUG("Called with func_ptr=%p, take=%p, idx=%d, count=%d", func_ptr, take, idx, count ? *count : 1);
+    
+    if (!func_ptr || !take) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, take=%p", func_ptr, take);
+        return 0;
+    }
+    
+    int (*delete_take_stretch_markers)(void*, int, const int*) = (int (*)(void*, int, const int*))func_ptr;
+    LOG_DEBUG("Calling DeleteTakeStretchMarkers with take=%p, idx=%d", take, idx);
+    int result = delete_take_stretch_markers(take, idx, count);
+    LOG_DEBUG("DeleteTakeStretchMarkers call completed with result: %d", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's GetTakeStretchMarkerSlope function
+ */
+double plugin_bridge_call_get_take_stretch_marker_slope(void* func_ptr, void* take, int idx) {
+    LOG_DEBUG("Called with func_ptr=%p, take=%p, idx=%d", func_ptr, take, idx);
+    
+    if (!func_ptr || !take) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, take=%p", func_ptr, take);
+        return 0.0;
+    }
+    
+    double (*get_take_stretch_marker_slope)(void*, int) = (double (*)(void*, int))func_ptr;
+    LOG_DEBUG("Calling GetTakeStretchMarkerSlope with take=%p, idx=%d", take, idx);
+    double result = get_take_stretch_marker_slope(take, idx);
+    LOG_DEBUG("GetTakeStretchMarkerSlope call completed with result: %f", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's SetTakeStretchMarkerSlope function
+ * slope runs from -1 to 1 and changes the rate across the marker's segment
+ */
+bool plugin_bridge_call_set_take_stretch_marker_slope(void* func_ptr, void* take, int idx, double slope) {
+    LOG_DEBUG("Called with func_ptr=%p, take=%p, idx=%d, slope=%f", func_ptr, take, idx, slope);
+    
+    if (!func_ptr || !take) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, take=%p", func_ptr, take);
+        return false;
+    }
+    
+    bool (*set_take_stretch_marker_slope)(void*, int, double) = (bool (*)(void*, int, double))func_ptr;
+    LOG_DEBUG("Calling SetTakeStretchMarkerSlope with take=%p, idx=%d, slope=%f", take, idx, slope);
+    bool result = set_take_stretch_marker_slope(take, idx, slope);
+    LOG_DEBUG("SetTakeStretchMarkerSlope call completed with result: %d", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's CountAutomationItems function
+ */
+int plugin_bridge_call_count_automation_items(void* func_ptr, void* env) {
+    LOG_DEBUG("Called with func_ptr=%p, env=%p", func_ptr, env);
+    
+    if (!func_ptr || !env) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, env=%p", func_ptr, env);
+        return 0;
+    }
+    
+    int (*count_automation_items)(void*) = (int (*)(void*))func_ptr;
+    LOG_DEBUG("Calling CountAutomationItems with env=%p", env);
+    int result = count_automation_items(env);
+    LOG_DEBUG("CountAutomationItems call completed with result: %d", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's InsertAutomationItem function
+ * pool_id < 0 collects the envelope's points in the range into a new pool; otherwise the
+ * item is an instance of that pool. Returns the item's index.
+ */
+int plugin_bridge_call_insert_automation_item(void* func_ptr, void* env, int pool_id, double position, double length) {
+    LOG_DEBUG("Called with func_ptr=%p, env=%p, pool_id=%d, position=%f, length=%f",
+              func_ptr, env, pool_id, position, length);
+    
+    if (!func_ptr || !env) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, env=%p", func_ptr, env);
+        return -1;
+    }
+    
+    int (*insert_automation_item)(void*, int, double, double) = (int (*)(void*, int, double, double))func_ptr;
+    LOG_DEBUG("Calling InsertAutomationItem with env=%p, pool_id=%d", env, pool_id);
+    int result = insert_automation_item(env, pool_id, position, length);
+    LOG_DEBUG("InsertAutomationItem call completed with result: %d", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's GetSetAutomationItemInfo function
+ * Reads the numeric property desc, or sets it to value when is_set is true
+ */
+double plugin_bridge_call_get_set_automation_item_info(void* func_ptr, void* env, int autoitem_idx, const char* desc,
+    double value, bool is_set) {
+    LOG_DEBUG("Called with func_ptr=%p, env=%p, autoitem_idx=%d, desc=%s, value=%f, is_set=%d",
+              func_ptr, env, autoitem_idx, desc ? desc : "NULL", value, is_set);
+    
+    if (!func_ptr || !env || !desc) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, env=%p, desc=%p", func_ptr, env, desc);
+        return 0.0;
+    }
+    
+    double (*get_set_automation_item_info)(void*, int, const char*, double, bool) =
+        (double (*)(void*, int, const char*, double, bool))func_ptr;
+    LOG_DEBUG("Calling GetSetAutomationItemInfo with env=%p, autoitem_idx=%d, desc=%s", env, autoitem_idx, desc);
+    double result = get_set_automation_item_info(env, autoitem_idx, desc, value, is_set);
+    LOG_DEBUG("GetSetAutomationItemInfo call completed with result: %f", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's GetSetAutomationItemInfo_String function
+ * REAPER wants a big buffer for reads; buf_size is only used to terminate it
+ */
+bool plugin_bridge_call_get_set_automation_item_info_string(void* func_ptr, void* env, int autoitem_idx, const char* desc,
+    char* buf, int buf_size, bool is_set) {
+    LOG_DEBUG("Called with func_ptr=%p, env=%p, autoitem_idx=%d, desc=%s, buf_size=%d, is_set=%d",
+              func_ptr, env, autoitem_idx, desc ? desc : "NULL", buf_size, is_set);
+    
+    if (!func_ptr || !env || !desc || !buf || buf_size <= 0) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, env=%p, desc=%p, buf=%p, buf_size=%d",
+                  func_ptr, env, desc, buf, buf_size);
+        return false;
+    }
+    
+    if (!is_set) {
+        buf[0] = '\0';
+    }
+    
+    bool (*get_set_automation_item_info_string)(void*, int, const char*, char*, bool) =
+        (bool (*)(void*, int, const char*, char*, bool))func_ptr;
+    LOG_DEBUG("Calling GetSetAutomationItemInfo_String with env=%p, autoitem_idx=%d, desc=%s", env, autoitem_idx, desc);
+    bool result = get_set_automation_item_info_string(env, autoitem_idx, desc, buf, is_set);
+    buf[buf_size - 1] = '\0';
+    LOG_DEBUG("GetSetAutomationItemInfo_String call completed with result: %d", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's CountEnvelopePointsEx function
+ */
+int plugin_bridge_call_count_envelope_points_ex(void* func_ptr, void* env, int autoitem_idx) {
+    LOG_DEBUG("Called with func_ptr=%p, env=%p, autoitem_idx=%d", func_ptr, env, autoitem_idx);
+    
+    if (!func_ptr || !env) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, env=%p", func_ptr, env);
+        return 0;
+    }
+    
+    int (*count_envelope_points_ex)(void*, int) = (int (*)(void*, int))func_ptr;
+    LOG_DEBUG("Calling CountEnvelopePointsEx with env=%p, autoitem_idx=%d", env, autoitem_idx);
+    int result = count_envelope_points_ex(env, autoitem_idx);
+    LOG_DEBUG("CountEnvelopePointsEx call completed with result: %d", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's GetEnvelopePointEx function
+ */
+bool plugin_bridge_call_get_envelope_point_ex(void* func_ptr, void* env, int autoitem_idx, int ptidx, double* time,
+    double* value, int* shape, double* tension, bool* selected) {
+    LOG_DEBUG("Called with func_ptr=%p, env=%p, autoitem_idx=%d, ptidx=%d", func_ptr, env, autoitem_idx, ptidx);
+    
+    if (!func_ptr || !env || !time || !value || !shape || !tension || !selected) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, env=%p, ptidx=%d", func_ptr, env, ptidx);
+        return false;
+    }
+    
+    bool (*get_envelope_point_ex)(void*, int, int, double*, double*, int*, double*, bool*) =
+        (bool (*)(void*, int, int, double*, double*, int*, double*, bool*))func_ptr;
+    LOG_DEBUG("Calling GetEnvelopePointEx with env=%p, autoitem_idx=%d, ptidx=%d", env, autoitem_idx, ptidx);
+    bool result = get_envelope_point_ex(env, autoitem_idx, ptidx, time, value, shape, tension, selected);
+    LOG_DEBUG("GetEnvelopePointEx call completed with result: %d, time=%f, value=%f", result, *time, *value);
+    
+    return result;
+}
+
+/**
+ * REAPER's InsertEnvelopePointEx function
+ * With no_sort set, call Envelope_SortPointsEx after inserting several points
+ */
+bool plugin_bridge_call_insert_envelope_point_ex(void* func_ptr, void* env, int autoitem_idx, double time, double value,
+    int shape, double tension, bool selected, bool no_sort) {
+    LOG_DEBUG("Called with func_ptr=%p, env=%p, autoitem_idx=%d, time=%f, value=%f",
+              func_ptr, env, autoitem_idx, time, value);
+    
+    if (!func_ptr || !env) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, env=%p", func_ptr, env);
+        return false;
+    }
+    
+    bool (*insert_envelope_point_ex)(void*, int, double, double, int, double, bool, bool*) =
+        (bool (*)(void*, int, double, double, int, double, bool, bool*))func_ptr;
+    LOG_DEBUG("Calling InsertEnvelopePointEx with env=%p, autoitem_idx=%d, time=%f", env, autoitem_idx, time);
+    bool result = insert_envelope_point_ex(env, autoitem_idx, time, value, shape, tension, selected, &no_sort);
+    LOG_DEBUG("InsertEnvelopePointEx call completed with result: %d", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's DeleteEnvelopePointRangeEx function
+ */
+bool plugin_bridge_call_delete_envelope_point_range_ex(void* func_ptr, void* env, int autoitem_idx,
+    double time_start, double time_end) {
+    LOG_DEBUG("Called with func_ptr=%p, env=%p, autoitem_idx=%d, time_start=%f, time_end=%f",
+              func_ptr, env, autoitem_idx, time_start, time_end);
+    
+    if (!func_ptr || !env) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, env=%p", func_ptr, env);
+        return false;
+    }
+    
+    bool (*delete_envelope_point_range_ex)(void*, int, double, double) =
+        (bool (*)(void*, int, double, double))func_ptr;
+    LOG_DEBUG("Calling DeleteEnvelopePointRangeEx with env=%p, autoitem_idx=%d", env, autoitem_idx);
+    bool result = delete_envelope_point_range_ex(env, autoitem_idx, time_start, time_end);
+    LOG_DEBUG("DeleteEnvelopePointRangeEx call completed with result: %d", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's Envelope_SortPointsEx function
+ */
+bool plugin_bridge_call_envelope_sort_points_ex(void* func_ptr, void* env, int autoitem_idx) {
+    LOG_DEBUG("Called with func_ptr=%p, env=%p, autoitem_idx=%d", func_ptr, env, autoitem_idx);
+    
+    if (!func_ptr || !env) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, env=%p", func_ptr, env);
+        return false;
+    }
+    
+    bool (*envelope_sort_points_ex)(void*, int) = (bool (*)(void*, int))func_ptr;
+    LOG_DEBUG("Calling Envelope_SortPointsEx with env=%p, autoitem_idx=%d", env, autoitem_idx);
+    bool result = envelope_sort_points_ex(env, autoitem_idx);
+    LOG_DEBUG("Envelope_SortPointsEx call completed with result: %d", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's CreateTrackAudioAccessor function
+ * Must be destroyed with DestroyAudioAccessor
+ */
+void* plugin_bridge_call_create_track_audio_accessor(void* func_ptr, void* track) {
+    LOG_DEBUG("Called with func_ptr=%p, track=%p", func_ptr, track);
+    
+    if (!func_ptr || !track) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, track=%p", func_ptr, track);
+        return NULL;
+    }
+    
+    void* (*create_track_audio_accessor)(void*) = (void* (*)(void*))func_ptr;
+    LOG_DEBUG("Calling CreateTrackAudioAccessor with track=%p", track);
+    void* result = create_track_audio_accessor(track);
+    LOG_DEBUG("CreateTrackAudioAccessor call completed with result: %p", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's DestroyAudioAccessor function
+ */
+void plugin_bridge_call_destroy_audio_accessor(void* func_ptr, void* accessor) {
+    LOG_DEBUG("Called with func_ptr=%p, accessor=%p", func_ptr, accessor);
+    
+    if (!func_ptr || !accessor) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, accessor=%p", func_ptr, accessor);
+        return;
+    }
+    
+    void (*destroy_audio_accessor)(void*) = (void (*)(void*))func_ptr;
+    LOG_DEBUG("Calling DestroyAudioAccessor with accessor=%p", accessor);
+    destroy_audio_accessor(accessor);
+    LOG_DEBUG("DestroyAudioAccessor call completed");
+}
+
+/**
+ * REAPER's GetAudioAccessorStartTime function
+ */
+double plugin_bridge_call_get_audio_accessor_start_time(void* func_ptr, void* accessor) {
+    LOG_DEBUG("Called with func_ptr=%p, accessor=%p", func_ptr, accessor);
+    
+    if (!func_ptr || !accessor) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, accessor=%p", func_ptr, accessor);
+        return 0.0;
+    }
+    
+    double (*get_audio_accessor_start_time)(void*) = (double (*)(void*))func_ptr;
+    LOG_DEBUG("Calling GetAudioAccessorStartTime with accessor=%p", accessor);
+    double result = get_audio_accessor_start_time(accessor);
+    LOG_DEBUG("GetAudioAccessorStartTime call completed with result: %f", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's GetAudioAccessorEndTime function
+ */
+double plugin_bridge_call_get_audio_accessor_end_time(void* func_ptr, void* accessor) {
+    LOG_DEBUG("Called with func_ptr=%p, accessor=%p", func_ptr, accessor);
+    
+    if (!func_ptr || !accessor) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, accessor=%p", func_ptr, accessor);
+        return 0.0;
+    }
+    
+    double (*get_audio_accessor_end_time)(void*) = (double (*)(void*))func_ptr;
+    LOG_DEBUG("Calling GetAudioAccessorEndTime with accessor=%p", accessor);
+    double result = get_audio_accessor_end_time(accessor);
+    LOG_DEBUG("GetAudioAccessorEndTime call completed with result: %f", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's GetAudioAccessorSamples function
+ * Fills samplebuffer with numsamplesperchannel interleaved frames; returns 1 for audio,
+ * 0 for silence and -1 on error
+ */
+int plugin_bridge_call_get_audio_accessor_samples(void* func_ptr, void* accessor, int samplerate, int numchannels,
+    double starttime_sec, int numsamplesperchannel, double* samplebuffer) {
+    LOG_DEBUG("Called with func_ptr=%p, accessor=%p, samplerate=%d, numchannels=%d, starttime_sec=%f, numsamplesperchannel=%d",
+              func_ptr, accessor, samplerate, numchannels, starttime_sec, numsamplesperchannel);
+    
+    if (!func_ptr || !accessor || !samplebuffer) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, accessor=%p, samplebuffer=%p", func_ptr, accessor, samplebuffer);
+        return -1;
+    }
+    
+    int (*get_audio_accessor_samples)(void*, int, int, double, int, double*) =
+        (int (*)(void*, int, int, double, int, double*))func_ptr;
+    int result = get_audio_accessor_samples(accessor, samplerate, numchannels, starttime_sec, numsamplesperchannel, samplebuffer);
+    LOG_DEBUG("GetAudioAccessorSamples call completed with result: %d", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's GetSetProjectInfo function
+ * Reads the numeric project setting desc, or sets it to value when is_set is true
+ */
+double plugin_bridge_call_get_set_project_info(void* func_ptr, void* proj, const char* desc, double value, bool is_set) {
+    LOG_DEBUG("Called with func_ptr=%p, proj=%p, desc=%s, value=%f, is_set=%d",
+              func_ptr, proj, desc ? desc : "NULL", value, is_set);
+    
+    if (!func_ptr || !desc) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, desc=%p", func_ptr, desc);
+        return 0.0;
+    }
+    
+    double (*get_set_project_info)(void*, const char*, double, bool) =
+        (double (*)(void*, const char*, double, bool))func_ptr;
+    LOG_DEBUG("Calling GetSetProjectInfo with proj=%p, desc=%s", proj, desc);
+    double result = get_set_project_info(proj, desc, value, is_set);
+    LOG_DEBUG("GetSetProjectInfo call completed with result: %f", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's GetProjExtState function. Returns the length of the value, 0 if there is none.
+ */
+int plugin_bridge_call_get_proj_ext_state(void* func_ptr, void* proj, const char* extname, const char* key,
+                                          char* buf, int buf_size) {
+    LOG_DEBUG("Called with func_ptr=%p, proj=%p, extname=%s, key=%s, buf_size=%d",
+              func_ptr, proj, extname ? extname : "NULL", key ? key : "NULL", buf_size);
+    
+    if (!func_ptr || !extname || !key || !buf || buf_size <= 0) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, extname=%p, key=%p, buf=%p, buf_size=%d",
+                  func_ptr, extname, key, buf, buf_size);
+        return 0;
+    }
+    
+    buf[0] = '\0';
+    int (*get_proj_ext_state)(void*, const char*, const char*, char*, int) =
+        (int (*)(void*, const char*, const char*, char*, int))func_ptr;
+    int result = get_proj_ext_state(proj, extname, key, buf, buf_size);
+    LOG_DEBUG("GetProjExtState call completed with result: %d", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's SetProjExtState function. An empty value deletes the key; an empty key
+ * deletes every key of extname.
+ */
+int plugin_bridge_call_set_proj_ext_state(void* func_ptr, void* proj, const char* extname, const char* key,
+                                          const char* value) {
+    LOG_DEBUG("Called with func_ptr=%p, proj=%p, extname=%s, key=%s",
+              func_ptr, proj, extname ? extname : "NULL", key ? key : "NULL");
+    
+    if (!func_ptr || !extname || !key || !value) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, extname=%p, key=%p, value=%p",
+                  func_ptr, extname, key, value);
+        return 0;
+    }
+    
+    int (*set_proj_ext_state)(void*, const char*, const char*, const char*) =
+        (int (*)(void*, const char*, const char*, const char*))func_ptr;
+    int result = set_proj_ext_state(proj, extname, key, value);
+    LOG_DEBUG("SetProjExtState call completed with result: %d", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's EnumProjExtState function. Only the key is read; values are fetched with
+ * GetProjExtState so they can be read into a buffer of the right size.
+ */
+bool plugin_bridge_call_enum_proj_ext_state(void* func_ptr, void* proj, const char* extname, int idx,
+                                            char* key_buf, int key_buf_size) {
+    LOG_DEBUG("Called with func_ptr=%p, proj=%p, extname=%s, idx=%d",
+              func_ptr, proj, extname ? extname : "NULL", idx);
+    
+    if (!func_ptr || !extname || !key_buf || key_buf_size <= 0) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, extname=%p, key_buf=%p, key_buf_size=%d",
+                  func_ptr, extname, key_buf, key_buf_size);
+        return false;
+    }
+    
+    key_buf[0] = '\0';
+    bool (*enum_proj_ext_state)(void*, const char*, int, char*, int, char*, int) =
+        (bool (*)(void*, const char*, int, char*, int, char*, int))func_ptr;
+    bool result = enum_proj_ext_state(proj, extname, idx, key_buf, key_buf_size, NULL, 0);
+    LOG_DEBUG("EnumProjExtState call completed with result: %d", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's MIDI_GetRecentInputEvent function, called until it reaches an event already
+ * seen. Events are newest first; SysEx and other long messages are skipped.
+ */
+int plugin_bridge_call_get_recent_midi_input(void* func_ptr, int after_seq, midi_input_event_t* out, int max_count,
+                                             int* out_latest_seq) {
+    LOG_DEBUG("Called with func_ptr=%p, after_seq=%d, out=%p, max_count=%d", func_ptr, after_seq, out, max_count);
+    
+    if (!func_ptr || !out || max_count <= 0 || !out_latest_seq) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, out=%p, max_count=%d, out_latest_seq=%p",
+                  func_ptr, out, max_count, out_latest_seq);
+        return 0;
+    }
+    
+    int (*get_recent_input_event)(int, char*, int*, int*, int*, double*, int*) =
+        (int (*)(int, char*, int*, int*, int*, double*, int*))func_ptr;
+    
+    *out_latest_seq = 0;
+    int count = 0;
+    char buf[256];
+    for (int idx = 0; count < max_count; idx++) {
+        int size = (int)sizeof(buf);
+        int ts = 0, device = 0, loop_count = 0;
+        double proj_pos = -1.0;
+        
+        // idx 0 latches the history, so later indices read the same list
+        int seq = get_recent_input_event(idx, buf, &size, &ts, &device, &proj_pos, &loop_count);
+        if (idx == 0) {
+            *out_latest_seq = seq;
+        }
+        if (seq == 0 || seq <= after_seq) {
+            break;
+        }
+        if (size < 1 || size > 3) {
+            continue;
+        }
+        
+        midi_input_event_t* event = &out[count++];
+        event->seq = seq;
+        event->size = size;
+        event->device = device & 0xFFFF;
+        memset(event->msg, 0, sizeof(event->msg));
+        memcpy(event->msg, buf, size);
+    }
+    
+    LOG_DEBUG("Read %d MIDI event(s), latest sequence %d", count, *out_latest_seq);
+    return count;
+}
+
+/**
+ * REAPER's GetMIDIInputName function
+ */
+bool plugin_bridge_call_get_midi_input_name(void* func_ptr, int dev, char* buf, int buf_size) {
+    LOG_DEBUG("Called with func_ptr=%p, dev=%d, buf=%p, buf_size=%d", func_ptr, dev, buf, buf_size);
+    
+    if (!func_ptr || !buf || buf_size <= 0) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, buf=%p, buf_size=%d", func_ptr, buf, buf_size);
+        return false;
+    }
+    
+    buf[0] = '\0';
+    bool (*get_midi_input_name)(int, char*, int) = (bool (*)(int, char*, int))func_ptr;
+    return get_midi_input_name(dev, buf, buf_size);
+}
+
+/**
+ * SWS's CF_GetSWSVersion function
+ */
+void plugin_bridge_call_cf_get_sws_version(void* func_ptr, char* buf, int buf_size) {
+    LOG_DEBUG("Called with func_ptr=%p, buf=%p, buf_size=%d", func_ptr, buf, buf_size);
+    
+    if (!func_ptr || !buf || buf_size <= 0) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, buf=%p, buf_size=%d", func_ptr, buf, buf_size);
+        return;
+    }
+    
+    buf[0] = '\0';
+    void (*cf_get_sws_version)(char*, int) = (void (*)(char*, int))func_ptr;
+    LOG_DEBUG("Calling CF_GetSWSVersion");
+    cf_get_sws_version(buf, buf_size);
+    LOG_DEBUG("CF_GetSWSVersion call completed with result: %s", buf);
+}
+
+/**
+ * SWS's NF_GetSWSTrackNotes function
+ */
+const char* plugin_bridge_call_nf_get_sws_track_notes(void* func_ptr, void* track) {
+    LOG_DEBUG("Called with func_ptr=%p, track=%p", func_ptr, track);
+    
+    if (!func_ptr || !track) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, track=%p", func_ptr, track);
+        return NULL;
+    }
+    
+    const char* (*nf_get_sws_track_notes)(void*) = (const char* (*)(void*))func_ptr;
+    LOG_DEBUG("Calling NF_GetSWSTrackNotes with track=%p", track);
+    const char* result = nf_get_sws_track_notes(track);
+    LOG_DEBUG("NF_GetSWSTrackNotes call completed");
+    
+    return result;
+}
+
+/**
+ * SWS's NF_SetSWSTrackNotes function
+ */
+void plugin_bridge_call_nf_set_sws_track_notes(void* func_ptr, void* track, const char* notes) {
+    LOG_DEBUG("Called with func_ptr=%p, track=%p", func_ptr, track);
+    
+    if (!func_ptr || !track || !notes) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, track=%p, notes=%p", func_ptr, track, notes);
+        return;
+    }
+    
+    void (*nf_set_sws_track_notes)(void*, const char*) = (void (*)(void*, const char*))func_ptr;
+    LOG_DEBUG("Calling NF_SetSWSTrackNotes with track=%p", track);
+    nf_set_sws_track_notes(track, notes);
+    LOG_DEBUG("NF_SetSWSTrackNotes call completed");
+}
+
+/**
+ * SWS's CF_SetClipboard function
+ */
+void plugin_bridge_call_cf_set_clipboard(void* func_ptr, const char* text) {
+    LOG_DEBUG("Called with func_ptr=%p", func_ptr);
+    
+    if (!func_ptr || !text) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, text=%p", func_ptr, text);
+        return;
+    }
+    
+    void (*cf_set_clipboard)(const char*) = (void (*)(const char*))func_ptr;
+    LOG_DEBUG("Calling CF_SetClipboard");
+    cf_set_clipboard(text);
+    LOG_DEBUG("CF_SetClipboard call completed");
+}
+
+/**
+ * SWS's CF_GetClipboard function
+ */
+void plugin_bridge_call_cf_get_clipboard(void* func_ptr, char* buf, int buf_size) {
+    LOG_DEBUG("Called with func_ptr=%p, buf=%p, buf_size=%d", func_ptr, buf, buf_size);
+    
+    if (!func_ptr || !buf || buf_size <= 0) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, buf=%p, buf_size=%d", func_ptr, buf, buf_size);
+        return;
+    }
+    
+    void (*cf_get_clipboard)(char*, int) = (void (*)(char*, int))func_ptr;
+    LOG_DEBUG("Calling CF_GetClipboard");
+    cf_get_clipboard(buf, buf_size);
+    LOG_DEBUG("CF_GetClipboard call completed");
+}
+
+/**
+ * SWS's BR_GetMouseCursorContext function
+ */
+void plugin_bridge_call_br_get_mouse_cursor_context(void* func_ptr, char* window, int window_size,
+                                                    char* segment, int segment_size, char* details, int details_size) {
+    LOG_DEBUG("Called with func_ptr=%p", func_ptr);
+    
+    if (!func_ptr || !window || !segment || !details) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, window=%p, segment=%p, details=%p", func_ptr, window, segment, details);
+        return;
+    }
+    
+    window[0] = segment[0] = details[0] = '\0';
+    void (*br_get_mouse_cursor_context)(char*, int, char*, int, char*, int) =
+        (void (*)(char*, int, char*, int, char*, int))func_ptr;
+    LOG_DEBUG("Calling BR_GetMouseCursorContext");
+    br_get_mouse_cursor_context(window, window_size, segment, segment_size, details, details_size);
+    LOG_DEBUG("BR_GetMouseCursorContext call completed with window=%s, segment=%s, details=%s", window, segment, details);
+}
+
+/**
+ * SWS's BR_EnvAlloc function
+ */
+void* plugin_bridge_call_br_env_alloc(void* func_ptr, void* envelope, bool take_envelopes_use_project_time) {
+    LOG_DEBUG("Called with func_ptr=%p, envelope=%p", func_ptr, envelope);
+    
+    if (!func_ptr || !envelope) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, envelope=%p", func_ptr, envelope);
+        return NULL;
+    }
+    
+    void* (*br_env_alloc)(void*, bool) = (void* (*)(void*, bool))func_ptr;
+    LOG_DEBUG("Calling BR_EnvAlloc with envelope=%p", envelope);
+    void* result = br_env_alloc(envelope, take_envelopes_use_project_time);
+    LOG_DEBUG("BR_EnvAlloc call completed with result: %p", result);
+    
+    return result;
+}
+
+/**
+ * SWS's BR_EnvCountPoints function
+ */
+int plugin_bridge_call_br_env_count_points(void* func_ptr, void* br_envelope) {
+    LOG_DEBUG("Called with func_ptr=%p, br_envelope=%p", func_ptr, br_envelope);
+    
+    if (!func_ptr || !br_envelope) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, br_envelope=%p", func_ptr, br_envelope);
+        return 0;
+    }
+    
+    int (*br_env_count_points)(void*) = (int (*)(void*))func_ptr;
+    LOG_DEBUG("Calling BR_EnvCountPoints with br_envelope=%p", br_envelope);
+    int result = br_env_count_points(br_envelope);
+    LOG_DEBUG("BR_EnvCountPoints call completed with result: %d", result);
+    
+    return result;
+}
+
+/**
+ * SWS's BR_EnvGetPoint function
+ */
+bool plugin_bridge_call_br_env_get_point(void* func_ptr, void* br_envelope, int id, double* position,
+                                         double* value, int* shape, bool* selected, double* bezier_tension) {
+    LOG_DEBUG("Called with func_ptr=%p, br_envelope=%p, id=%d", func_ptr, br_envelope, id);
+    
+    if (!func_ptr || !br_envelope || !position || !value || !shape || !selected || !bezier_tension) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, br_envelope=%p", func_ptr, br_envelope);
+        return false;
+    }
+    
+    bool (*br_env_get_point)(void*, int, double*, double*, int*, bool*, double*) =
+        (bool (*)(void*, int, double*, double*, int*, bool*, double*))func_ptr;
+    LOG_DEBUG("Calling BR_EnvGetPoint with br_envelope=%p, id=%d", br_envelope, id);
+    bool result = br_env_get_point(br_envelope, id, position, value, shape, selected, bezier_tension);
+    LOG_DEBUG("BR_EnvGetPoint call completed with result: %d", result);
+    
+    return result;
+}
+
+/**
+ * SWS's BR_EnvSetPoint function
+ * An id of -1 adds a new point
+ */
+bool plugin_bridge_call_br_env_set_point(void* func_ptr, void* br_envelope, int id, double position,
+                                         double value, int shape, bool selected, double bezier_tension) {
+    LOG_DEBUG("Called with func_ptr=%p, br_envelope=%p, id=%d, position=%f, value=%f",
+              func_ptr, br_envelope, id, position, value);
+    
+    if (!func_ptr || !br_envelope) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, br_envelope=%p", func_ptr, br_envelope);
+        return false;
+    }
+    
+    bool (*br_env_set_point)(void*, int, double, double, int, bool, double) =
+        (bool (*)(void*, int, double, double, int, bool, double))func_ptr;
+    LOG_DEBUG("Calling BR_EnvSetPoint with br_envelope=%p, id=%d", br_envelope, id);
+    bool result = br_env_set_point(br_envelope, id, position, value, shape, selected, bezier_tension);
+    LOG_DEBUG("BR_EnvSetPoint call completed with result: %d", result);
+    
+    return result;
+}
+
+/**
+ * SWS's BR_EnvFree function
+ * commit writes the changes back to the envelope
+ */
+bool plugin_bridge_call_br_env_free(void* func_ptr, void* br_envelope, bool commit) {
+    LOG_DEBUG("Called with func_ptr=%p, br_envelope=%p, commit=%d", func_ptr, br_envelope, commit);
+    
+    if (!func_ptr || !br_envelope) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, br_envelope=%p", func_ptr, br_envelope);
+        return false;
+    }
+    
+    bool (*br_env_free)(void*, bool) = (bool (*)(void*, bool))func_ptr;
+    LOG_DEBUG("Calling BR_EnvFree with br_envelope=%p, commit=%d", br_envelope, commit);
+    bool result = br_env_free(br_envelope, commit);
+    LOG_DEBUG("BR_EnvFree call completed with result: %d", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's GetMainHwnd function
+ */
+void* plugin_bridge_call_get_main_hwnd(void* func_ptr) {
+    LOG_DEBUG("Called with func_ptr=%p", func_ptr);
+    
+    if (!func_ptr) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p", func_ptr);
+        return NULL;
+    }
+    
+    void* (*get_main_hwnd)(void) = (void* (*)(void))func_ptr;
+    LOG_DEBUG("Calling GetMainHwnd");
+    void* result = get_main_hwnd();
+    LOG_DEBUG("GetMainHwnd call completed with result: %p", result);
+    
+    return result;
+}
+
+/**
+ * js_ReaScriptAPI's JS_ReaScriptAPI_Version function
+ */
+double plugin_bridge_call_js_version(void* func_ptr) {
+    LOG_DEBUG("Called with func_ptr=%p", func_ptr);
+    
+    if (!func_ptr) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p", func_ptr);
+        return 0;
+    }
+    
+    double version = 0;
+    void (*js_version)(double*) = (void (*)(double*))func_ptr;
+    LOG_DEBUG("Calling JS_ReaScriptAPI_Version");
+    js_version(&version);
+    LOG_DEBUG("JS_ReaScriptAPI_Version call completed with result: %f", version);
+    
+    return version;
+}
+
+/**
+ * js_ReaScriptAPI's JS_Window_Create function
+ * style and owner may be NULL
+ */
+void* plugin_bridge_call_js_window_create(void* func_ptr, const char* title, const char* class_name,
+                                          int x, int y, int w, int h, char* style, void* owner) {
+    LOG_DEBUG("Called with func_ptr=%p, title=%s, class_name=%s, x=%d, y=%d, w=%d, h=%d, owner=%p",
+              func_ptr, title ? title : "NULL", class_name ? class_name : "NULL", x, y, w, h, owner);
+    
+    if (!func_ptr || !title || !class_name) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, title=%p, class_name=%p", func_ptr, title, class_name);
+        return NULL;
+    }
+    
+    void* (*js_window_create)(const char*, const char*, int, int, int, int, char*, void*) =
+        (void* (*)(const char*, const char*, int, int, int, int, char*, void*))func_ptr;
+    LOG_DEBUG("Calling JS_Window_Create with title=%s", title);
+    void* result = js_window_create(title, class_name, x, y, w, h, style, owner);
+    LOG_DEBUG("JS_Window_Create call completed with result: %p", result);
+    
+    return result;
+}
+
+/**
+ * js_ReaScriptAPI's JS_Window_Destroy function
+ */
+void plugin_bridge_call_js_window_destroy(void* func_ptr, void* hwnd) {
+    LOG_DEBUG("Called with func_ptr=%p, hwnd=%p", func_ptr, hwnd);
+    
+    if (!func_ptr || !hwnd) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, hwnd=%p", func_ptr, hwnd);
+        return;
+    }
+    
+    void (*js_window_destroy)(void*) = (void (*)(void*))func_ptr;
+    LOG_DEBUG("Calling JS_Window_Destroy with hwnd=%p", hwnd);
+    js_window_destroy(hwnd);
+    LOG_DEBUG("JS_Window_Destroy call completed");
+}
+
+/**
+ * js_ReaScriptAPI's JS_Window_IsWindow function
+ */
+bool plugin_bridge_call_js_window_is_window(void* func_ptr, void* hwnd) {
+    LOG_DEBUG("Called with func_ptr=%p, hwnd=%p", func_ptr, hwnd);
+    
+    if (!func_ptr || !hwnd) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, hwnd=%p", func_ptr, hwnd);
+        return false;
+    }
+    
+    bool (*js_window_is_window)(void*) = (bool (*)(void*))func_ptr;
+    LOG_DEBUG("Calling JS_Window_IsWindow with hwnd=%p", hwnd);
+    bool result = js_window_is_window(hwnd);
+    LOG_DEBUG("JS_Window_IsWindow call completed with result: %d", result);
+    
+    return result;
+}
+
+/**
+ * js_ReaScriptAPI's JS_Window_Find function
+ */
+void* plugin_bridge_call_js_window_find(void* func_ptr, const char* title, bool exact) {
+    LOG_DEBUG("Called with func_ptr=%p, title=%s, exact=%d", func_ptr, title ? title : "NULL", exact);
+    
+    if (!func_ptr || !title) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, title=%p", func_ptr, title);
+        return NULL;
+    }
+    
+    void* (*js_window_find)(const char*, bool) = (void* (*)(const char*, bool))func_ptr;
+    LOG_DEBUG("Calling JS_Window_Find with title=%s", title);
+    void* result = js_window_find(title, exact);
+    LOG_DEBUG("JS_Window_Find call completed with result: %p", result);
+    
+    return result;
+}
+
+/**
+ * js_ReaScriptAPI's JS_Window_SetParent function
+ */
+void* plugin_bridge_call_js_window_set_parent(void* func_ptr, void* child, void* parent) {
+    LOG_DEBUG("Called with func_ptr=%p, child=%p, parent=%p", func_ptr, child, parent);
+    
+    if (!func_ptr || !child) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, child=%p", func_ptr, child);
+        return NULL;
+    }
+    
+    void* (*js_window_set_parent)(void*, void*) = (void* (*)(void*, void*))func_ptr;
+    LOG_DEBUG("Calling JS_Window_SetParent with child=%p, parent=%p", child, parent);
+    void* result = js_window_set_parent(child, parent);
+    LOG_DEBUG("JS_Window_SetParent call completed with result: %p", result);
+    
+    return result;
+}
+
+/**
+ * js_ReaScriptAPI's JS_Window_Show function
+ * state is e.g. "SHOW", "SHOWNA" or "HIDE"
+ */
+void plugin_bridge_call_js_window_show(void* func_ptr, void* hwnd, const char* state) {
+    LOG_DEBUG("Called with func_ptr=%p, hwnd=%p, state=%s", func_ptr, hwnd, state ? state : "NULL");
+    
+    if (!func_ptr || !hwnd || !state) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, hwnd=%p, state=%p", func_ptr, hwnd, state);
+        return;
+    }
+    
+    void (*js_window_show)(void*, const char*) = (void (*)(void*, const char*))func_ptr;
+    LOG_DEBUG("Calling JS_Window_Show with hwnd=%p, state=%s", hwnd, state);
+    js_window_show(hwnd, state);
+    LOG_DEBUG("JS_Window_Show call completed");
+}
+
+/**
+ * js_ReaScriptAPI's JS_Window_SetTitle function
+ */
+bool plugin_bridge_call_js_window_set_title(void* func_ptr, void* hwnd, const char* title) {
+    LOG_DEBUG("Called with func_ptr=%p, hwnd=%p, title=%s", func_ptr, hwnd, title ? title : "NULL");
+    
+    if (!func_ptr || !hwnd || !title) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, hwnd=%p, title=%p", func_ptr, hwnd, title);
+        return false;
+    }
+    
+    bool (*js_window_set_title)(void*, const char*) = (bool (*)(void*, const char*))func_ptr;
+    LOG_DEBUG("Calling JS_Window_SetTitle with hwnd=%p, title=%s", hwnd, title);
+    bool result = js_window_set_title(hwnd, title);
+    LOG_DEBUG("JS_Window_SetTitle call completed with result: %d", result);
+    
+    return result;
+}
+
+/**
+ * js_ReaScriptAPI's JS_Window_SetPosition function
+ */
+bool plugin_bridge_call_js_window_set_position(void* func_ptr, void* hwnd, int left, int top, int width, int height) {
+    LOG_DEBUG("Called with func_ptr=%p, hwnd=%p, left=%d, top=%d, width=%d, height=%d",
+              func_ptr, hwnd, left, top, width, height);
+    
+    if (!func_ptr || !hwnd) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, hwnd=%p", func_ptr, hwnd);
+        return false;
+    }
+    
+    // The z-order and flags arguments are optional
+    bool (*js_window_set_position)(void*, int, int, int, int, char*, char*) =
+        (bool (*)(void*, int, int, int, int, char*, char*))func_ptr;
+    LOG_DEBUG("Calling JS_Window_SetPosition with hwnd=%p", hwnd);
+    bool result = js_window_set_position(hwnd, left, top, width, height, NULL, NULL);
+    LOG_DEBUG("JS_Window_SetPosition call completed with result: %d", result);
+    
+    return result;
+}
+
+/**
+ * js_ReaScriptAPI's JS_Window_GetRect function
+ */
+bool plugin_bridge_call_js_window_get_rect(void* func_ptr, void* hwnd, int* left, int* top, int* right, int* bottom) {
+    LOG_DEBUG("Called with func_ptr=%p, hwnd=%p", func_ptr, hwnd);
+    
+    if (!func_ptr || !hwnd || !left || !top || !right || !bottom) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, hwnd=%p", func_ptr, hwnd);
+        return false;
+    }
+    
+    bool (*js_window_get_rect)(void*, int*, int*, int*, int*) = (bool (*)(void*, int*, int*, int*, int*))func_ptr;
+    LOG_DEBUG("Calling JS_Window_GetRect with hwnd=%p", hwnd);
+    bool result = js_window_get_rect(hwnd, left, top, right, bottom);
+    LOG_DEBUG("JS_Window_GetRect call completed with result: %d (%d, %d, %d, %d)", result, *left, *top, *right, *bottom);
+    
+    return result;
+}
+
+/**
+ * REAPER's AddExtensionsMainMenu function
+ */
+bool plugin_bridge_call_add_extensions_main_menu(void* func_ptr) {
+    LOG_DEBUG("Called with func_ptr=%p", func_ptr);
+    
+    if (!func_ptr) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p", func_ptr);
+        return false;
+    }
+    
+    bool (*add_extensions_main_menu)(void) = (bool (*)(void))func_ptr;
+    LOG_DEBUG("Calling AddExtensionsMainMenu");
+    bool result = add_extensions_main_menu();
+    LOG_DEBUG("AddExtensionsMainMenu call completed with result: %d", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's ClearConsole function
+ */
+void plugin_bridge_call_clear_console(void* func_ptr) {
+    LOG_DEBUG("Called with func_ptr=%p", func_ptr);
+    
+    if (!func_ptr) {
+        LOG_ERROR("Invalid parameter: func_ptr is NULL");
+        return;
+    }
+    
+    void (*clear_console)(void) = (void (*)(void))func_ptr;
+    LOG_DEBUG("Calling ClearConsole");
+    clear_console();
+    LOG_DEBUG("ClearConsole call completed");
+}
+
+/**
+ * REAPER's GetAppVersion function
+ */
+const char* plugin_bridge_call_get_app_version(void* func_ptr) {
+    LOG_DEBUG("Called with func_ptr=%p", func_ptr);
+    
+    // Verify input pointer isn't NULL
+    if (!func_ptr) {
+        LOG_ERROR("Invalid parameter: func_ptr is NULL");
+        return NULL;
+    }
+    
+    const char* (*get_app_version)(void) = (const char* (*)(void))func_ptr;
+    LOG_DEBUG("Calling GetAppVersion");
+    const char* result = get_app_version();
+    LOG_DEBUG("GetAppVersion call completed with result: %s", result ? result : "NULL");
+    
+    return result;
+}
+
+/**
+ * REAPER's GetResourcePath function
+ */
+const char* plugin_bridge_call_get_resource_path(void* func_ptr) {
+    LOG_DEBUG("Called with func_ptr=%p", func_ptr);
+    
+    // Verify input pointer isn't NULL
+    if (!func_ptr) {
+        LOG_ERROR("Invalid parameter: func_ptr is NULL");
+        return NULL;
+    }
+    
+    const char* (*get_resource_path)(void) = (const char* (*)(void))func_ptr;
+    LOG_DEBUG("Calling GetResourcePath");
+    const char* result = get_resource_path();
+    LOG_DEBUG("GetResourcePath call completed with result: %s", result ? result : "NULL");
+    
+    return result;
+}
+
+/**
+ * REAPER's GetProjectTimeSignature2 function
+ * A NULL proj refers to the active project
+ */
+void plugin_bridge_call_get_project_time_signature2(void* func_ptr, void* proj, double* bpm, double* bpi) {
+    LOG_DEBUG("Called with func_ptr=%p, proj=%p, bpm=%p, bpi=%p", func_ptr, proj, bpm, bpi);
+    
+    // Verify input pointers aren't NULL
+    if (!func_ptr || !bpm || !bpi) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, bpm=%p, bpi=%p", func_ptr, bpm, bpi);
+        return;
+    }
+    
+    void (*get_project_time_signature2)(void*, double*, double*) = 
+        (void (*)(void*, double*, double*))func_ptr;
+    LOG_DEBUG("Calling GetProjectTimeSignature2 with proj=%p", proj);
+    get_project_time_signature2(proj, bpm, bpi);
+    LOG_DEBUG("GetProjectTimeSignature2 call completed with bpm=%f, bpi=%f", *bpm, *bpi);
+}
+
+/**
+ * REAPER's CountTempoTimeSigMarkers function
+ * A NULL proj refers to the active project
+ */
+int plugin_bridge_call_count_tempo_time_sig_markers(void* func_ptr, void* proj) {
+    LOG_DEBUG("Called with func_ptr=%p, proj=%p", func_ptr, proj);
+    
+    if (!func_ptr) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p", func_ptr);
+        return 0;
+    }
+    
+    int (*count_tempo_time_sig_markers)(void*) = (int (*)(void*))func_ptr;
+    LOG_DEBUG("Calling CountTempoTimeSigMarkers with proj=%p", proj);
+    int result = count_tempo_time_sig_markers(proj);
+    LOG_DEBUG("CountTempoTimeSigMarkers call completed with result: %d", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's GetTempoTimeSigMarker function
+ * A NULL proj refers to the active project
+ */
+bool plugin_bridge_call_get_tempo_time_sig_marker(void* func_ptr, void* proj, int ptidx, double* timepos,
+    int* measurepos, double* beatpos, double* bpm, int* timesig_num, int* timesig_denom, bool* lineartempo) {
+    LOG_DEBUG("Called with func_ptr=%p, proj=%p, ptidx=%d", func_ptr, proj, ptidx);
+    
+    if (!func_ptr || !timepos || !measurepos || !beatpos || !bpm || !timesig_num || !timesig_denom || !lineartempo) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, ptidx=%d", func_ptr, ptidx);
+        return false;
+    }
+    
+    bool (*get_tempo_time_sig_marker)(void*, int, double*, int*, double*, double*, int*, int*, bool*) =
+        (bool (*)(void*, int, double*, int*, double*, double*, int*, int*, bool*))func_ptr;
+    LOG_DEBUG("Calling GetTempoTimeSigMarker with proj=%p, ptidx=%d", proj, ptidx);
+    bool result = get_tempo_time_sig_marker(proj, ptidx, timepos, measurepos, beatpos, bpm,
+                                            timesig_num, timesig_denom, lineartempo);
+    LOG_DEBUG("GetTempoTimeSigMarker call completed with result: %d, timepos=%f, bpm=%f",
+              result, *timepos, *bpm);
+    
+    return result;
+}
+
+/**
+ * REAPER's SetTempoTimeSigMarker function
+ * ptidx -1 adds a marker; a negative measurepos places it by timepos instead of by beat
+ */
+bool plugin_bridge_call_set_tempo_time_sig_marker(void* func_ptr, void* proj, int ptidx, double timepos,
+    int measurepos, double beatpos, double bpm, int timesig_num, int timesig_denom, bool lineartempo) {
+    LOG_DEBUG("Called with func_ptr=%p, proj=%p, ptidx=%d, timepos=%f, measurepos=%d, beatpos=%f, bpm=%f, timesig=%d/%d, lineartempo=%d",
+              func_ptr, proj, ptidx, timepos, measurepos, beatpos, bpm, timesig_num, timesig_denom, lineartempo);
+    
+    if (!func_ptr) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p", func_ptr);
+        return false;
+    }
+    
+    bool (*set_tempo_time_sig_marker)(void*, int, double, int, double, double, int, int, bool) =
+        (bool (*)(void*, int, double, int, double, double, int, int, bool))func_ptr;
+    LOG_DEBUG("Calling SetTempoTimeSigMarker with proj=%p, ptidx=%d", proj, ptidx);
+    bool result = set_tempo_time_sig_marker(proj, ptidx, timepos, measurepos, beatpos, bpm,
+                                            timesig_num, timesig_denom, lineartempo);
+    LOG_DEBUG("SetTempoTimeSigMarker call completed with result: %d", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's DeleteTempoTimeSigMarker function
+ */
+bool plugin_bridge_call_delete_tempo_time_sig_marker(void* func_ptr, void* proj, int markerindex) {
+    LOG_DEBUG("Called with func_ptr=%p, proj=%p, markerindex=%d", func_ptr, proj, markerindex);
+    
+    if (!func_ptr) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p", func_ptr);
+        return false;
+    }
+    
+    bool (*delete_tempo_time_sig_marker)(void*, int) = (bool (*)(void*, int))func_ptr;
+    LOG_DEBUG("Calling DeleteTempoTimeSigMarker with proj=%p, markerindex=%d", proj, markerindex);
+    bool result = delete_tempo_time_sig_marker(proj, markerindex);
+    LOG_DEBUG("DeleteTempoTimeSigMarker call completed with result: %d", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's TimeMap_GetTimeSigAtTime function
+ * Gets the time signature and tempo in effect at a project time in seconds
+ */
+void plugin_bridge_call_time_map_get_time_sig_at_time(void* func_ptr, void* proj, double time,
+    int* timesig_num, int* timesig_denom, double* tempo) {
+    LOG_DEBUG("Called with func_ptr=%p, proj=%p, time=%f", func_ptr, proj, time);
+    
+    if (!func_ptr || !timesig_num || !timesig_denom || !tempo) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, timesig_num=%p, timesig_denom=%p, tempo=%p",
+                  func_ptr, timesig_num, timesig_denom, tempo);
+        return;
+    }
+    
+    void (*time_map_get_time_sig_at_time)(void*, double, int*, int*, double*) =
+        (void (*)(void*, double, int*, int*, double*))func_ptr;
+    LOG_DEBUG("Calling TimeMap_GetTimeSigAtTime with proj=%p, time=%f", proj, time);
+    time_map_get_time_sig_at_time(proj, time, timesig_num, timesig_denom, tempo);
+    LOG_DEBUG("TimeMap_GetTimeSigAtTime call completed with timesig=%d/%d, tempo=%f",
+              *timesig_num, *timesig_denom, *tempo);
+}
+
+/**
+ * REAPER's UpdateTimeline function
+ * Redraws the arrange view and ruler after tempo map changes
+ */
+void plugin_bridge_call_update_timeline(void* func_ptr) {
+    LOG_DEBUG("Called with func_ptr=%p", func_ptr);
+    
+    if (!func_ptr) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p", func_ptr);
+        return;
+    }
+    
+    void (*update_timeline)(void) = (void (*)(void))func_ptr;
+    LOG_DEBUG("Calling UpdateTimeline");
+    update_timeline();
+    LOG_DEBUG("UpdateTimeline call completed");
+}
+
+/**
+ * REAPER's TrackList_AdjustWindows function
+ * Redraws the track panels and mixer after track layout changes; is_minor skips the mixer
+ */
+void plugin_bridge_call_track_list_adjust_windows(void* func_ptr, bool is_minor) {
+    LOG_DEBUG("Called with func_ptr=%p, is_minor=%d", func_ptr, is_minor);
+    
+    if (!func_ptr) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p", func_ptr);
+        return;
+    }
+    
+    void (*track_list_adjust_windows)(bool) = (void (*)(bool))func_ptr;
+    LOG_DEBUG("Calling TrackList_AdjustWindows");
+    track_list_adjust_windows(is_minor);
+    LOG_DEBUG("TrackList_AdjustWindows call completed");
+}
+
+// Get track information value
+double plugin_bridge_call_track_get_info_value(void* func_ptr, void* track, const char* param) {
+    LOG_DEBUG("Called with func_ptr=%p, track=%p, param=%s", 
+              func_ptr, track, param ? param : "NULL");
+    
+    if (!func_ptr || !track || !param) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, track=%p, param=%p", 
+                  func_ptr, track, param);
+        return 0.0;
+    }
+    
+    double (*get_track_info)(void*, const char*) = (double (*)(void*, const char*))func_ptr;
+    LOG_DEBUG("Calling GetMediaTrackInfo_Value with track=%p, param=%s", track, param);
+    double result = get_track_info(track, param);
+    LOG_DEBUG("GetMediaTrackInfo_Value call completed with result: %f", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's GetSetMediaTrackInfo_String function
+ * REAPER doesn't take a buffer size; buf must be large (see trackInfoStringSize on the Go side).
+ * When set is true, buf holds the new value.
+ */
+bool plugin_bridge_call_get_set_media_track_info_string(void* func_ptr, void* track, const char* parmname,
+    char* buf, int buf_size, bool set) {
+    LOG_DEBUG("Called with func_ptr=%p, track=%p, parmname=%s, buf=%p, buf_size=%d, set=%d",
+              func_ptr, track, parmname ? parmname : "NULL", buf, buf_size, set);
+    
+    if (!func_ptr || !track || !parmname || !buf || buf_size <= 0) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, track=%p, parmname=%p, buf=%p, buf_size=%d",
+                  func_ptr, track, parmname, buf, buf_size);
+        return false;
+    }
+    
+    if (!set) {
+        buf[0] = '\0';
+    }
+    
+    bool (*get_set_media_track_info_string)(void*, const char*, char*, bool) =
+        (bool (*)(void*, const char*, char*, bool))func_ptr;
+    LOG_DEBUG("Calling GetSetMediaTrackInfo_String with track=%p, parmname=%s", track, parmname);
+    bool result = get_set_media_track_info_string(track, parmname, buf, set);
+    LOG_DEBUG("GetSetMediaTrackInfo_String call completed with result: %d", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's GetTrackColor function
+ * Returns the native color with 0x1000000 set, or 0 if the track has no custom color
+ */
+int plugin_bridge_call_get_track_color(void* func_ptr, void* track) {
+    LOG_DEBUG("Called with func_ptr=%p, track=%p", func_ptr, track);
+    
+    if (!func_ptr || !track) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, track=%p", func_ptr, track);
+        return 0;
+    }
+    
+    int (*get_track_color)(void*) = (int (*)(void*))func_ptr;
+    int result = get_track_color(track);
+    LOG_DEBUG("GetTrackColor call completed with result: 0x%X", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's GetThemeColor function
+ */
+int plugin_bridge_call_get_theme_color(void* func_ptr, const char* ini_key, int flags) {
+    LOG_DEBUG("Called with func_ptr=%p, ini_key=%s, flags=%d", func_ptr, ini_key ? ini_key : "(null)", flags);
+    
+    if (!func_ptr || !ini_key) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, ini_key=%p", func_ptr, ini_key);
+        return -1;
+    }
+    
+    int (*get_theme_color)(const char*, int) = (int (*)(const char*, int))func_ptr;
+    int result = get_theme_color(ini_key, flags);
+    LOG_DEBUG("GetThemeColor call completed with result: 0x%X", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's SetTrackColor function
+ * color is a native color from ColorToNative
+ */
+void plugin_bridge_call_set_track_color(void* func_ptr, void* track, int color) {
+    LOG_DEBUG("Called with func_ptr=%p, track=%p, color=0x%X", func_ptr, track, color);
+    
+    if (!func_ptr || !track) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, track=%p", func_ptr, track);
+        return;
+    }
+    
+    void (*set_track_color)(void*, int) = (void (*)(void*, int))func_ptr;
+    set_track_color(track, color);
+    LOG_DEBUG("SetTrackColor call completed");
+}
+
+/**
+ * REAPER's ColorToNative function
+ */
+int plugin_bridge_call_color_to_native(void* func_ptr, int r, int g, int b) {
+    LOG_DEBUG("Called with func_ptr=%p, r=%d, g=%d, b=%d", func_ptr, r, g, b);
+    
+    if (!func_ptr) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p", func_ptr);
+        return 0;
+    }
+    
+    int (*color_to_native)(int, int, int) = (int (*)(int, int, int))func_ptr;
+    return color_to_native(r, g, b);
+}
+
+/**
+ * REAPER's ColorFromNative function
+ */
+void plugin_bridge_call_color_from_native(void* func_ptr, int color, int* r, int* g, int* b) {
+    LOG_DEBUG("Called with func_ptr=%p, color=0x%X", func_ptr, color);
+    
+    if (!func_ptr || !r || !g || !b) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, r=%p, g=%p, b=%p", func_ptr, r, g, b);
+        return;
+    }
+    
+    void (*color_from_native)(int, int*, int*, int*) = (void (*)(int, int*, int*, int*))func_ptr;
+    color_from_native(color, r, g, b);
+}
+
+/**
+ * REAPER's SetMediaTrackInfo_Value function
+ */
+bool plugin_bridge_call_set_media_track_info_value(void* func_ptr, void* track, const char* parmname, double value) {
+    LOG_DEBUG("Called with func_ptr=%p, track=%p, parmname=%s, value=%f",
+              func_ptr, track, parmname ? parmname : "NULL", value);
+    
+    if (!func_ptr || !track || !parmname) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, track=%p, parmname=%p", func_ptr, track, parmname);
+        return false;
+    }
+    
+    bool (*set_media_track_info_value)(void*, const char*, double) =
+        (bool (*)(void*, const char*, double))func_ptr;
+    bool result = set_media_track_info_value(track, parmname, value);
+    LOG_DEBUG("SetMediaTrackInfo_Value call completed with result: %d", result);
+    
+    return result;
+}
+
+/**
+ * REAPER's TrackFX_GetFXGUID, formatted with guidToString
+ * buf must hold at least 64 bytes. Returns false if the FX doesn't exist.
+ */
+bool plugin_bridge_call_track_fx_get_fx_guid(void* func_ptr, void* guid_to_string_ptr, void* track, int fx_idx,
+    char* buf, int buf_size) {
+    LOG_DEBUG("Called with func_ptr=%p, guid_to_string_ptr=%p, track=%p, fx_idx=%d, buf=%p, buf_size=%d",
+              func_ptr, guid_to_string_ptr, track, fx_idx, buf, buf_size);
+    
+    if (!func_ptr || !guid_to_string_ptr || !track || !buf || buf_size < 64) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, guid_to_string_ptr=%p, track=%p, buf=%p, buf_size=%d",
+                  func_ptr, guid_to_string_ptr, track, buf, buf_size);
+        return false;
+    }
+    
+    buf[0] = '\0';
+    
+    void* (*track_fx_get_fx_guid)(void*, int) = (void* (*)(void*, int))func_ptr;
+    void* guid = track_fx_get_fx_guid(track, fx_idx);
+    if (!guid) {
+        LOG_DEBUG("No GUID for FX %d", fx_idx);
+        return false;
+    }
+    
+    void (*guid_to_string)(const void*, char*) = (void (*)(const void*, char*))guid_to_string_ptr;
+    guid_to_string(guid, buf);
+    LOG_DEBUG("FX %d GUID: %s", fx_idx, buf);
+    
+    return buf[0] != '\0';
+}
+
+// Get track name
+bool plugin_bridge_call_get_track_name(void* func_ptr, void* track, char* buf, int buf_size, int* flags) {
+    LOG_DEBUG("Called with func_ptr=%p, track=%p, buf=%p, buf_size=%d, flags=%p", 
+              func_ptr, track, buf, buf_size, flags);
+    
+    if (!func_ptr || !track || !buf || buf_size <= 0) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, track=%p, buf=%p, buf_size=%d", 
+                  func_ptr, track, buf, buf_size);
+        // If buffer is valid, make it an empty string for safety
+        if (buf && buf_size > 0) {
+            buf[0] = '\0';
+            LOG_DEBUG("Buffer set to empty string for safety");
+        }
+        return false;
+    }
+    
+    bool (*get_track_name)(void*, char*, int, int*) = 
+        (bool (*)(void*, char*, int, int*))func_ptr;
+    
+    LOG_DEBUG("Calling GetTrackName with track=%p", track);
+    bool result = get_track_name(track, buf, buf_size, flags);
+    
+    // Log flags if provided
+    if (flags) {
+        LOG_DEBUG("GetTrackName call completed with result: %d, name=%s, flags=%d", 
+                  result, buf, *flags);
+    } else {
+        LOG_DEBUG("GetTrackName call completed with result: %d, name=%s", 
+                  result, buf);
+    }
+    
+    return result;
+}
+
+/**
+ * REAPER's GetUserInputs function for creating simple form dialogs
+ */
+bool plugin_bridge_call_get_user_inputs(void* func_ptr, const char* title, int num_inputs, 
+    const char* captions, char* values, int values_sz) {
+    LOG_DEBUG("Called with func_ptr=%p, title=%s, num_inputs=%d, captions=%s, values_sz=%d", 
+              func_ptr, title ? title : "NULL", num_inputs, 
+              captions ? captions : "NULL", values_sz);
+    
+    // Verify input pointers aren't NULL
+    if (!func_ptr || !title || !captions || !values || values_sz <= 0) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, title=%p, captions=%p, values=%p, values_sz=%d", 
+                  func_ptr, title, captions, values, values_sz);
+        return false;
+    }
+
+    bool (*get_user_inputs)(const char*, int, const char*, char*, int) =
+        (bool (*)(const char*, int, const char*, char*, int))func_ptr;
+
+    LOG_DEBUG("Calling GetUserInputs with title=%s, num_inputs=%d", title, num_inputs);
+    bool result = get_user_inputs(title, num_inputs, captions, values, values_sz);
+    LOG_DEBUG("GetUserInputs call completed with result: %d, values=%s", result, values);
+    
+    return result;
+}
+
+/**
+* REAPER's ShowMessageBox function for standard message boxes
+*/
+int plugin_bridge_call_show_message_box(void* func_ptr, const char* text, const char* title, int type) {
+    LOG_DEBUG("Called with func_ptr=%p, text=%s, title=%s, type=%d", 
+              func_ptr, text ? text : "NULL", title ? title : "NULL", type);
+    
+    // Verify input pointers aren't NULL
+    if (!func_ptr || !text || !title) {
+        LOG_ERROR("Invalid parameters: func_ptr=%p, text=%p, title=%p", 
+                  func_ptr, text, title);
+        return 0; // Return IDOK (1) by default on error
+    }
+
+    int (*show_message_box)(const char*, const char*, int) =
+        (int (*)(const char*, const char*, int))func_ptr;
+
+    LOG_DEBUG("Calling ShowMessageBox with text='%s', title='%s', type=%d", text, title, type);
+    int result = show_message_box(text, title, type);
+    LOG_DEBUG("ShowMessageBox call completed with result: %d", result);
+    
+    return result;
+}
+
+/**
+ * Function to batch retrieve FX parameters in a single call
+ * This reduces the number of C-Go crossings dramatically. Reads up to max_params
+ * parameters starting at first_param; out_param_count receives the full parameter
+ * count, so plugins with more parameters than one buffer holds are read in chunks.
+ */
+bool plugin_bridge_batch_get_fx_parameters(void* track, int fx_idx, int first_param, fx_param_t* params,
+    int max_params, int* out_param_count) {
+    LOG_DEBUG("Called with track=%p, fx_idx=%d, first_param=%d, params=%p, max_params=%d",
+    track, fx_idx, first_param, params, max_params);
+
+    // Verify input pointers
+    if (!track || !params || !out_param_count || max_params <= 0 || first_param < 0) {
+        LOG_ERROR("Invalid parameters: track=%p, params=%p, out_param_count=%p, first_param=%d, max_params=%d",
+        track, params, out_param_count, first_param, max_params);
+        return false;
+    }
+
+    // Get the GetFunc function using our bridge
+    void* getFuncPtr = plugin_bridge_get_get_func();
+    if (!getFuncPtr) {
+        LOG_ERROR("Failed to get GetFunc pointer");
+        return false;
+    }
+
+    // Get the number of parameters function
+    void* getParamCountFunc = NULL;
+    {
+        char funcName[64] = "TrackFX_GetNumParams";
+        getParamCountFunc = plugin_bridge_call_get_func(getFuncPtr, funcName);
+        if (!getParamCountFunc) {
+            LOG_ERROR("Failed to get TrackFX_GetNumParams function pointer");
+            return false;
+        }
+    }
+
+    // Get the parameter functions we need
+    void* getParamNameFunc = NULL;
+    void* getParamFunc = NULL;
+    void* getFormattedFunc = NULL;
+
+    {
+        char funcName[64] = "TrackFX_GetParamName";
+        getParamNameFunc = plugin_bridge_call_get_func(getFuncPtr, funcName);
+        if (!getParamNameFunc) {
+            LOG_ERROR("Failed to get TrackFX_GetParamName function pointer");
+            return false;
+        }
+    }
+
+    {
+        char funcName[64] = "TrackFX_GetParam";
+        getParamFunc = plugin_bridge_call_get_func(getFuncPtr, funcName);
+        if (!getParamFunc) {
+            LOG_ERROR("Failed to get TrackFX_GetParam function pointer");
+            return false;
+        }
+    }
+
+    {
+        char funcName[64] = "TrackFX_GetFormattedParamValue";
+        getFormattedFunc = plugin_bridge_call_get_func(getFuncPtr, funcName);
         if (!getFormattedFunc) {
             LOG_ERROR("Failed to get TrackFX_GetFormattedParamValue function pointer");
             return false;
         }
     }
 
-    // Cast the function pointers to their proper types
-    int (*track_fx_get_param_count)(void*, int) = 
-    (int (*)(void*, int))getParamCountFunc;
+    // Cast the function pointers to their proper types
+    int (*track_fx_get_param_count)(void*, int) = 
+    (int (*)(void*, int))getParamCountFunc;
+
+    void (*track_fx_get_param_name)(void*, int, int, char*, int) = 
+    (void (*)(void*, int, int, char*, int))getParamNameFunc;
+
+    double (*track_fx_get_param)(void*, int, int, double*, double*) = 
+    (double (*)(void*, int, int, double*, double*))getParamFunc;
+
+    void (*track_fx_get_param_formatted)(void*, int, int, char*, int) = 
+    (void (*)(void*, int, int, char*, int))getFormattedFunc;
+
+    // Get parameter count
+    int param_count = track_fx_get_param_count(track, fx_idx);
+    LOG_DEBUG("FX parameter count: %d", param_count);
+
+    if (param_count <= 0) {
+        LOG_WARNING("FX has no parameters (count=%d)", param_count);
+        *out_param_count = 0;
+        return true; // Not an error, just no parameters
+    }
+
+    // Read the chunk from first_param, up to max_params
+    int end = param_count;
+    if (first_param > end) {
+        first_param = end;
+    }
+    if (end - first_param > max_params) {
+        end = first_param + max_params;
+    }
+
+    // Get all parameter data
+    for (int i = first_param; i < end; i++) {
+        fx_param_t* param = &params[i - first_param];
+
+        // Get parameter name
+        track_fx_get_param_name(track, fx_idx, i, param->name, sizeof(param->name));
+
+        // Get parameter value with min/max
+        double min = 0, max = 0;
+        param->value = track_fx_get_param(track, fx_idx, i, &min, &max);
+        param->min = min;
+        param->max = max;
+
+        // Get formatted value
+        track_fx_get_param_formatted(track, fx_idx, i, param->formatted, sizeof(param->formatted));
+
+        LOG_DEBUG("Parameter %d: name=%s, value=%f, min=%f, max=%f, formatted=%s", 
+        i, param->name, param->value, param->min, param->max, param->formatted);
+    }
+
+    // Return the full parameter count
+    *out_param_count = param_count;
+    LOG_DEBUG("Successfully retrieved parameters %d to %d of %d", first_param, end, param_count);
+
+    return true;
+}
+
+/**
+ * Function to get the value and formatted value of every parameter of an FX in a single call
+ * Used when the names and ranges are already known, so only what changes is read
+ */
+bool plugin_bridge_batch_get_fx_values(void* track, int fx_idx, fx_value_t* values,
+    int max_params, int* out_param_count) {
+    LOG_DEBUG("Called with track=%p, fx_idx=%d, values=%p, max_params=%d",
+    track, fx_idx, values, max_params);
+
+    // Verify input pointers
+    if (!track || !values || !out_param_count || max_params <= 0) {
+        LOG_ERROR("Invalid parameters: track=%p, values=%p, out_param_count=%p, max_params=%d",
+        track, values, out_param_count, max_params);
+        return false;
+    }
+
+    void* getFuncPtr = plugin_bridge_get_get_func();
+    if (!getFuncPtr) {
+        LOG_ERROR("Failed to get GetFunc pointer");
+        return false;
+    }
+
+    void* getParamCountFunc = plugin_bridge_call_get_func(getFuncPtr, "TrackFX_GetNumParams");
+    void* getParamFunc = plugin_bridge_call_get_func(getFuncPtr, "TrackFX_GetParam");
+    void* getFormattedFunc = plugin_bridge_call_get_func(getFuncPtr, "TrackFX_GetFormattedParamValue");
+    if (!getParamCountFunc || !getParamFunc || !getFormattedFunc) {
+        LOG_ERROR("Failed to get TrackFX parameter function pointers");
+        return false;
+    }
 
-    void (*track_fx_get_param_name)(void*, int, int, char*, int) = 
-    (void (*)(void*, int, int, char*, int))getParamNameFunc;
+    int (*track_fx_get_param_count)(void*, int) =
+    (int (*)(void*, int))getParamCountFunc;
 
-    double (*track_fx_get_param)(void*, int, int, double*, double*) = 
+    double (*track_fx_get_param)(void*, int, int, double*, double*) =
     (double (*)(void*, int, int, double*, double*))getParamFunc;
 
-    void (*track_fx_get_param_formatted)(void*, int, int, char*, int) = 
+    void (*track_fx_get_param_formatted)(void*, int, int, char*, int) =
     (void (*)(void*, int, int, char*, int))getFormattedFunc;
 
-    // Get parameter count
+    // The full count is returned even past max_params, so the caller can tell the
+    // FX changed
     int param_count = track_fx_get_param_count(track, fx_idx);
-    LOG_DEBUG("FX parameter count: %d", param_count);
-
-    if (param_count <= 0) {
-        LOG_WARNING("FX has no parameters (count=%d)", param_count);
-        *out_param_count = 0;
-        return true; // Not an error, just no parameters
-    }
-
-    // Limit to max_params
+    *out_param_count = param_count > 0 ? param_count : 0;
     if (param_count > max_params) {
-        LOG_WARNING("Parameter count (%d) exceeds max_params (%d), limiting to max_params", 
-        param_count, max_params);
         param_count = max_params;
     }
 
-    // Get all parameter data
     for (int i = 0; i < param_count; i++) {
-        // Get parameter name
-        track_fx_get_param_name(track, fx_idx, i, params[i].name, sizeof(params[i].name));
+        double min = 0, max = 0;
+        values[i].value = track_fx_get_param(track, fx_idx, i, &min, &max);
+        track_fx_get_param_formatted(track, fx_idx, i, values[i].formatted, sizeof(values[i].formatted));
+        values[i].valid = true;
+    }
+
+    LOG_DEBUG("Successfully retrieved %d parameter values", param_count);
+    return true;
+}
+
+/**
+ * Function to get the value and formatted value of any parameters of a track's FX in a single call
+ * References to FX or parameters that no longer exist are marked invalid. out_fx_count
+ * receives the track's FX count, so callers can tell the chain changed.
+ */
+bool plugin_bridge_batch_get_param_values(void* track, const fx_param_ref_t* refs, int ref_count,
+    fx_value_t* values, int* out_fx_count) {
+    LOG_DEBUG("Called with track=%p, refs=%p, ref_count=%d, values=%p", track, refs, ref_count, values);
+
+    // Verify input pointers
+    if (!track || !refs || !values || !out_fx_count || ref_count < 0) {
+        LOG_ERROR("Invalid parameters: track=%p, refs=%p, ref_count=%d, values=%p, out_fx_count=%p",
+        track, refs, ref_count, values, out_fx_count);
+        return false;
+    }
+
+    void* getFuncPtr = plugin_bridge_get_get_func();
+    if (!getFuncPtr) {
+        LOG_ERROR("Failed to get GetFunc pointer");
+        return false;
+    }
+
+    void* getCountFunc = plugin_bridge_call_get_func(getFuncPtr, "TrackFX_GetCount");
+    void* getParamCountFunc = plugin_bridge_call_get_func(getFuncPtr, "TrackFX_GetNumParams");
+    void* getParamFunc = plugin_bridge_call_get_func(getFuncPtr, "TrackFX_GetParam");
+    void* getFormattedFunc = plugin_bridge_call_get_func(getFuncPtr, "TrackFX_GetFormattedParamValue");
+    if (!getCountFunc || !getParamCountFunc || !getParamFunc || !getFormattedFunc) {
+        LOG_ERROR("Failed to get TrackFX parameter function pointers");
+        return false;
+    }
+
+    int (*track_fx_get_count)(void*) = (int (*)(void*))getCountFunc;
+
+    int (*track_fx_get_param_count)(void*, int) =
+    (int (*)(void*, int))getParamCountFunc;
+
+    double (*track_fx_get_param)(void*, int, int, double*, double*) =
+    (double (*)(void*, int, int, double*, double*))getParamFunc;
+
+    void (*track_fx_get_param_formatted)(void*, int, int, char*, int) =
+    (void (*)(void*, int, int, char*, int))getFormattedFunc;
+
+    int fx_count = track_fx_get_count(track);
+    *out_fx_count = fx_count;
+
+    // References are usually grouped by FX, so the parameter count is read once per FX
+    int counted_fx = -1;
+    int param_count = 0;
+
+    for (int i = 0; i < ref_count; i++) {
+        int fx_idx = refs[i].fx_idx;
+        int param_idx = refs[i].param_idx;
+
+        values[i].valid = false;
+        values[i].value = 0;
+        values[i].formatted[0] = '\0';
+
+        if (fx_idx < 0 || fx_idx >= fx_count) {
+            continue;
+        }
+        if (fx_idx != counted_fx) {
+            param_count = track_fx_get_param_count(track, fx_idx);
+            counted_fx = fx_idx;
+        }
+        if (param_idx < 0 || param_idx >= param_count) {
+            continue;
+        }
 
-        // Get parameter value with min/max
         double min = 0, max = 0;
-        params[i].value = track_fx_get_param(track, fx_idx, i, &min, &max);
-        params[i].min = min;
-        params[i].max = max;
+        values[i].value = track_fx_get_param(track, fx_idx, param_idx, &min, &max);
+        track_fx_get_param_formatted(track, fx_idx, param_idx, values[i].formatted, sizeof(values[i].formatted));
+        values[i].valid = true;
+    }
 
-        // Get formatted value
-        track_fx_get_param_formatted(track, fx_idx, i, params[i].formatted, sizeof(params[i].formatted));
+    LOG_DEBUG("Successfully retrieved %d parameter values from %d FX", ref_count, fx_count);
+    return true;
+}
 
-        LOG_DEBUG("Parameter %d: name=%s, value=%f, min=%f, max=%f, formatted=%s", 
-        i, params[i].name, params[i].value, params[i].min, params[i].max, params[i].formatted);
+/**
+ * Function to get the bypass/offline state of every FX on a track in a single call
+ */
+bool plugin_bridge_batch_get_fx_states(void* track, fx_state_t* states, int max_fx, int* out_fx_count) {
+    LOG_DEBUG("Called with track=%p, states=%p, max_fx=%d", track, states, max_fx);
+
+    // Verify input pointers
+    if (!track || !states || !out_fx_count || max_fx <= 0) {
+        LOG_ERROR("Invalid parameters: track=%p, states=%p, out_fx_count=%p, max_fx=%d",
+        track, states, out_fx_count, max_fx);
+        return false;
     }
 
-    // Return parameter count
-    *out_param_count = param_count;
-    LOG_DEBUG("Successfully retrieved %d parameters", param_count);
+    void* getFuncPtr = plugin_bridge_get_get_func();
+    if (!getFuncPtr) {
+        LOG_ERROR("Failed to get GetFunc pointer");
+        return false;
+    }
+
+    void* getCountFunc = plugin_bridge_call_get_func(getFuncPtr, "TrackFX_GetCount");
+    void* getEnabledFunc = plugin_bridge_call_get_func(getFuncPtr, "TrackFX_GetEnabled");
+    void* getOfflineFunc = plugin_bridge_call_get_func(getFuncPtr, "TrackFX_GetOffline");
+    if (!getCountFunc || !getEnabledFunc || !getOfflineFunc) {
+        LOG_ERROR("Failed to get FX state function pointers: count=%p, enabled=%p, offline=%p",
+        getCountFunc, getEnabledFunc, getOfflineFunc);
+        return false;
+    }
+
+    int (*track_fx_get_count)(void*) = (int (*)(void*))getCountFunc;
+    bool (*track_fx_get_enabled)(void*, int) = (bool (*)(void*, int))getEnabledFunc;
+    bool (*track_fx_get_offline)(void*, int) = (bool (*)(void*, int))getOfflineFunc;
+
+    int fx_count = track_fx_get_count(track);
+    if (fx_count > max_fx) {
+        LOG_WARNING("FX count (%d) exceeds max_fx (%d), limiting to max_fx", fx_count, max_fx);
+        fx_count = max_fx;
+    }
+
+    for (int i = 0; i < fx_count; i++) {
+        states[i].index = i;
+        states[i].enabled = track_fx_get_enabled(track, i);
+        states[i].offline = track_fx_get_offline(track, i);
+    }
+
+    *out_fx_count = fx_count < 0 ? 0 : fx_count;
+    LOG_DEBUG("Successfully retrieved state for %d FX", *out_fx_count);
+
+    return true;
+}
+
+/**
+ * Function to get the name, state and identity of every FX on a track in a single call.
+ * Returns an array of *out_fx_count entries allocated with malloc, which the caller
+ * frees, or NULL on failure or when the track has no FX.
+ */
+fx_summary_t* plugin_bridge_batch_get_fx_summaries(void* track, int* out_fx_count) {
+    LOG_DEBUG("Called with track=%p", track);
+
+    // Verify input pointers
+    if (!track || !out_fx_count) {
+        LOG_ERROR("Invalid parameters: track=%p, out_fx_count=%p", track, out_fx_count);
+        return NULL;
+    }
+    *out_fx_count = 0;
+
+    void* getFuncPtr = plugin_bridge_get_get_func();
+    if (!getFuncPtr) {
+        LOG_ERROR("Failed to get GetFunc pointer");
+        return NULL;
+    }
+
+    void* getCountFunc = plugin_bridge_call_get_func(getFuncPtr, "TrackFX_GetCount");
+    void* getNameFunc = plugin_bridge_call_get_func(getFuncPtr, "TrackFX_GetFXName");
+    void* getEnabledFunc = plugin_bridge_call_get_func(getFuncPtr, "TrackFX_GetEnabled");
+    void* getOfflineFunc = plugin_bridge_call_get_func(getFuncPtr, "TrackFX_GetOffline");
+    if (!getCountFunc || !getNameFunc || !getEnabledFunc || !getOfflineFunc) {
+        LOG_ERROR("Failed to get FX summary function pointers: count=%p, name=%p, enabled=%p, offline=%p",
+        getCountFunc, getNameFunc, getEnabledFunc, getOfflineFunc);
+        return NULL;
+    }
+
+    // Identity is optional: older REAPER versions lack named config parameters
+    void* getNamedConfigFunc = plugin_bridge_call_get_func(getFuncPtr, "TrackFX_GetNamedConfigParm");
+
+    int (*track_fx_get_count)(void*) = (int (*)(void*))getCountFunc;
+    bool (*track_fx_get_name)(void*, int, char*, int) = (bool (*)(void*, int, char*, int))getNameFunc;
+    bool (*track_fx_get_enabled)(void*, int) = (bool (*)(void*, int))getEnabledFunc;
+    bool (*track_fx_get_offline)(void*, int) = (bool (*)(void*, int))getOfflineFunc;
+    bool (*track_fx_get_named_config_parm)(void*, int, const char*, char*, int) =
+        (bool (*)(void*, int, const char*, char*, int))getNamedConfigFunc;
+
+    int fx_count = track_fx_get_count(track);
+    if (fx_count <= 0) {
+        LOG_DEBUG("Track has no FX");
+        return NULL;
+    }
+
+    fx_summary_t* summaries = (fx_summary_t*)calloc(fx_count, sizeof(fx_summary_t));
+    if (!summaries) {
+        LOG_ERROR("Failed to allocate %d FX summaries", fx_count);
+        return NULL;
+    }
+
+    for (int i = 0; i < fx_count; i++) {
+        fx_summary_t* summary = &summaries[i];
+        summary->index = i;
+        track_fx_get_name(track, i, summary->name, sizeof(summary->name));
+        summary->enabled = track_fx_get_enabled(track, i);
+        summary->offline = track_fx_get_offline(track, i);
+
+        if (track_fx_get_named_config_parm) {
+            if (!track_fx_get_named_config_parm(track, i, "fx_type", summary->type, sizeof(summary->type))) {
+                summary->type[0] = '\0';
+            }
+            if (!track_fx_get_named_config_parm(track, i, "fx_ident", summary->ident, sizeof(summary->ident))) {
+                summary->ident[0] = '\0';
+            }
+            if (!track_fx_get_named_config_parm(track, i, "fx_name", summary->original_name, sizeof(summary->original_name))) {
+                summary->original_name[0] = '\0';
+            }
+        }
+    }
+
+    *out_fx_count = fx_count;
+    LOG_DEBUG("Successfully retrieved summaries for %d FX", fx_count);
+
+    return summaries;
+}
+
+/**
+ * Function to get a track's number, name, GUID, FX count, mixer state, color and folder
+ * position in a single call
+ */
+bool plugin_bridge_batch_get_track_info(void* track, track_info_t* out) {
+    LOG_DEBUG("Called with track=%p", track);
+
+    // Verify input pointers
+    if (!track || !out) {
+        LOG_ERROR("Invalid parameters: track=%p, out=%p", track, out);
+        return false;
+    }
+    memset(out, 0, sizeof(*out));
+
+    void* getFuncPtr = plugin_bridge_get_get_func();
+    if (!getFuncPtr) {
+        LOG_ERROR("Failed to get GetFunc pointer");
+        return false;
+    }
+
+    void* getValueFunc = plugin_bridge_call_get_func(getFuncPtr, "GetMediaTrackInfo_Value");
+    void* getStringFunc = plugin_bridge_call_get_func(getFuncPtr, "GetSetMediaTrackInfo_String");
+    void* getNameFunc = plugin_bridge_call_get_func(getFuncPtr, "GetTrackName");
+    void* getFXCountFunc = plugin_bridge_call_get_func(getFuncPtr, "TrackFX_GetCount");
+    void* getDepthFunc = plugin_bridge_call_get_func(getFuncPtr, "GetTrackDepth");
+    void* getParentFunc = plugin_bridge_call_get_func(getFuncPtr, "GetParentTrack");
+    void* fromNativeFunc = plugin_bridge_call_get_func(getFuncPtr, "ColorFromNative");
+    if (!getValueFunc || !getStringFunc || !getNameFunc || !getFXCountFunc || !getDepthFunc || !getParentFunc ||
+        !fromNativeFunc) {
+        LOG_ERROR("Failed to get track info function pointers: value=%p, string=%p, name=%p, fx_count=%p, depth=%p, parent=%p, "
+        "from_native=%p", getValueFunc, getStringFunc, getNameFunc, getFXCountFunc, getDepthFunc, getParentFunc, fromNativeFunc);
+        return false;
+    }
+
+    double (*get_value)(void*, const char*) = (double (*)(void*, const char*))getValueFunc;
+    bool (*get_set_string)(void*, const char*, char*, bool) = (bool (*)(void*, const char*, char*, bool))getStringFunc;
+    bool (*get_track_name)(void*, char*, int) = (bool (*)(void*, char*, int))getNameFunc;
+    int (*track_fx_get_count)(void*) = (int (*)(void*))getFXCountFunc;
+    int (*get_track_depth)(void*) = (int (*)(void*))getDepthFunc;
+    void* (*get_parent_track)(void*) = (void* (*)(void*))getParentFunc;
+    void (*color_from_native)(int, int*, int*, int*) = (void (*)(int, int*, int*, int*))fromNativeFunc;
+
+    out->number = (int)get_value(track, "IP_TRACKNUMBER");
+    if (!get_track_name(track, out->name, sizeof(out->name))) {
+        out->name[0] = '\0';
+    }
+
+    // GetSetMediaTrackInfo_String doesn't take a size; a GUID string is 38 characters
+    if (!get_set_string(track, "GUID", out->guid, false)) {
+        out->guid[0] = '\0';
+    }
+
+    out->fx_count = track_fx_get_count(track);
+    out->mute = get_value(track, "B_MUTE") != 0.0;
+    out->solo = (int)get_value(track, "I_SOLO");
+    out->rec_arm = get_value(track, "I_RECARM") != 0.0;
+    out->volume = get_value(track, "D_VOL");
+    out->pan = get_value(track, "D_PAN");
+    out->color = (int)get_value(track, "I_CUSTOMCOLOR");
+    if (out->color & 0x1000000) {
+        color_from_native(out->color & ~0x1000000, &out->r, &out->g, &out->b);
+    }
+    out->folder_depth = (int)get_value(track, "I_FOLDERDEPTH");
+    out->depth = get_track_depth(track);
+    out->parent = get_parent_track(track);
+
+    LOG_DEBUG("Track %d: %d FX, depth %d, parent %p", out->number, out->fx_count, out->depth, out->parent);
+    return true;
+}
+
+/**
+ * Function to set the bypass/offline state of several FX in a single call
+ */
+bool plugin_bridge_batch_set_fx_states(void* track, const fx_state_t* states, int count) {
+    LOG_DEBUG("Called with track=%p, states=%p, count=%d", track, states, count);
+
+    // Verify input pointers
+    if (!track || !states || count < 0) {
+        LOG_ERROR("Invalid parameters: track=%p, states=%p, count=%d", track, states, count);
+        return false;
+    }
+
+    void* getFuncPtr = plugin_bridge_get_get_func();
+    if (!getFuncPtr) {
+        LOG_ERROR("Failed to get GetFunc pointer");
+        return false;
+    }
+
+    void* setEnabledFunc = plugin_bridge_call_get_func(getFuncPtr, "TrackFX_SetEnabled");
+    void* setOfflineFunc = plugin_bridge_call_get_func(getFuncPtr, "TrackFX_SetOffline");
+    if (!setEnabledFunc || !setOfflineFunc) {
+        LOG_ERROR("Failed to get FX state function pointers: enabled=%p, offline=%p",
+        setEnabledFunc, setOfflineFunc);
+        return false;
+    }
+
+    void (*track_fx_set_enabled)(void*, int, bool) = (void (*)(void*, int, bool))setEnabledFunc;
+    void (*track_fx_set_offline)(void*, int, bool) = (void (*)(void*, int, bool))setOfflineFunc;
+
+    for (int i = 0; i < count; i++) {
+        track_fx_set_offline(track, states[i].index, states[i].offline);
+        track_fx_set_enabled(track, states[i].index, states[i].enabled);
+        LOG_DEBUG("FX %d: enabled=%d, offline=%d", states[i].index, states[i].enabled, states[i].offline);
+    }
+
+    return true;
+}
+
+/**
+ * Compute the new value of a parameter change from the current value, within the
+ * parameter's range
+ */
+static double fx_param_change_value(const fx_param_change_t* change, double current, double range_min, double range_max) {
+    double value;
+    switch (change->op) {
+        case FX_PARAM_OP_ADD:
+            value = current + change->value;
+            break;
+        case FX_PARAM_OP_MULTIPLY:
+            value = current * change->value;
+            break;
+        case FX_PARAM_OP_CLAMP:
+            value = current < change->min ? change->min : (current > change->max ? change->max : current);
+            break;
+        default:
+            value = change->value;
+            break;
+    }
+    return value < range_min ? range_min : (value > range_max ? range_max : value);
+}
+
+/**
+ * Function to change parameters of FX on any number of tracks in a single call. Each
+ * change's ok field reports whether REAPER accepted it and result holds the value set.
+ */
+bool plugin_bridge_batch_set_param_values(fx_param_change_t* changes, int count, int* out_applied) {
+    LOG_DEBUG("Called with changes=%p, count=%d", changes, count);
+
+    if (!changes || count < 0 || !out_applied) {
+        LOG_ERROR("Invalid parameters: changes=%p, count=%d, out_applied=%p", changes, count, out_applied);
+        return false;
+    }
+    *out_applied = 0;
+
+    void* getFuncPtr = plugin_bridge_get_get_func();
+    if (!getFuncPtr) {
+        LOG_ERROR("Failed to get GetFunc pointer");
+        return false;
+    }
+
+    void* setParamFunc = plugin_bridge_call_get_func(getFuncPtr, "TrackFX_SetParam");
+    void* getParamFunc = plugin_bridge_call_get_func(getFuncPtr, "TrackFX_GetParam");
+    if (!setParamFunc || !getParamFunc) {
+        LOG_ERROR("Failed to get parameter function pointers: set=%p, get=%p", setParamFunc, getParamFunc);
+        return false;
+    }
+
+    bool (*track_fx_set_param)(void*, int, int, double) = (bool (*)(void*, int, int, double))setParamFunc;
+    double (*track_fx_get_param)(void*, int, int, double*, double*) =
+        (double (*)(void*, int, int, double*, double*))getParamFunc;
+
+    for (int i = 0; i < count; i++) {
+        fx_param_change_t* change = &changes[i];
+        change->ok = false;
+        change->result = 0.0;
+        if (!change->track) {
+            continue;
+        }
+
+        double range_min = 0.0, range_max = 1.0;
+        double current = track_fx_get_param(change->track, change->fx_idx, change->param_idx, &range_min, &range_max);
+        if (range_max <= range_min) {
+            // Not a valid parameter; let TrackFX_SetParam refuse it
+            range_min = -1e300;
+            range_max = 1e300;
+        }
+        change->result = fx_param_change_value(change, current, range_min, range_max);
+        change->ok = track_fx_set_param(change->track, change->fx_idx, change->param_idx, change->result);
+        if (change->ok) {
+            (*out_applied)++;
+        } else {
+            LOG_DEBUG("Failed to set track %p FX %d parameter %d", changes[i].track, changes[i].fx_idx, changes[i].param_idx);
+        }
+    }
 
+    LOG_DEBUG("Set %d of %d parameters", *out_applied, count);
     return true;
 }
 
@@ -589,11 +3552,127 @@ void plugin_bridge_call_delete_ext_state(void* func_ptr, const char* section, co
     LOG_DEBUG("DeleteExtState call completed");
 }
 
+// Scratch buffer for string results, so short reads don't allocate.
+// Not thread-safe: the Go buffer pool locks it around each use.
+// Aligned like malloc's memory, as Go also reads arrays of bridge structs in it.
+static _Alignas(max_align_t) char s_ScratchBuffer[PLUGIN_BRIDGE_SCRATCH_SIZE];
+
+/**
+ * Reports the size and alignment of a struct passed between C and Go
+ * Go reads arrays of these structs in place, so its view of them must match the C
+ * compiler's exactly, padding included
+ */
+bool plugin_bridge_struct_layout(int which, size_t* out_size, size_t* out_align) {
+    if (!out_size || !out_align) {
+        LOG_ERROR("Invalid parameters: out_size=%p, out_align=%p", out_size, out_align);
+        return false;
+    }
+
+    switch (which) {
+    case BRIDGE_STRUCT_FX_PARAM:
+        *out_size = sizeof(fx_param_t);
+        *out_align = _Alignof(fx_param_t);
+        return true;
+    case BRIDGE_STRUCT_FX_VALUE:
+        *out_size = sizeof(fx_value_t);
+        *out_align = _Alignof(fx_value_t);
+        return true;
+    case BRIDGE_STRUCT_FX_PARAM_REF:
+        *out_size = sizeof(fx_param_ref_t);
+        *out_align = _Alignof(fx_param_ref_t);
+        return true;
+    case BRIDGE_STRUCT_FX_STATE:
+        *out_size = sizeof(fx_state_t);
+        *out_align = _Alignof(fx_state_t);
+        return true;
+    case BRIDGE_STRUCT_FX_PARAM_CHANGE:
+        *out_size = sizeof(fx_param_change_t);
+        *out_align = _Alignof(fx_param_change_t);
+        return true;
+    case BRIDGE_STRUCT_FX_SUMMARY:
+        *out_size = sizeof(fx_summary_t);
+        *out_align = _Alignof(fx_summary_t);
+        return true;
+    case BRIDGE_STRUCT_TRACK_INFO:
+        *out_size = sizeof(track_info_t);
+        *out_align = _Alignof(track_info_t);
+        return true;
+    case BRIDGE_STRUCT_MIDI_INPUT_EVENT:
+        *out_size = sizeof(midi_input_event_t);
+        *out_align = _Alignof(midi_input_event_t);
+        return true;
+    default:
+        LOG_ERROR("Unknown bridge struct: %d", which);
+        return false;
+    }
+}
+
+/**
+ * Returns the static scratch buffer
+ */
+char* plugin_bridge_get_scratch_buffer(void) {
+    return s_ScratchBuffer;
+}
+
+// The version reported to ReaScripts; set once while loading
+static char s_Version[256] = "";
+
+// APIdef_ entry: return type, parameter types, parameter names and help, separated by NULs
+static const char s_VersionDef[] = "const char*\0\0\0"
+    "Returns the version of the Go REAPER extension, e.g. \"1.2.0 (abc1234, built 2026-01-31)\"";
+
+// The exported function, for C++ extensions
+static const char* GoReaperExtension_GetVersion(void) {
+    return s_Version;
+}
+
+// The same for Lua, EEL and Python, which pass their arguments as a list
+static void* GoReaperExtension_GetVersion_vararg(void** arglist, int numparms) {
+    (void)arglist;
+    (void)numparms;
+    return (void*)s_Version;
+}
+
+/**
+ * Stores the version string returned by GoReaperExtension_GetVersion
+ */
+void plugin_bridge_set_version(const char* version) {
+    if (!version) {
+        version = "";
+    }
+    strncpy(s_Version, version, sizeof(s_Version) - 1);
+    s_Version[sizeof(s_Version) - 1] = '\0';
+}
+
+/**
+ * Returns the pointers registered with REAPER for GoReaperExtension_GetVersion
+ */
+void* plugin_bridge_version_func(void) {
+    return (void*)GoReaperExtension_GetVersion;
+}
+
+void* plugin_bridge_version_vararg(void) {
+    return (void*)GoReaperExtension_GetVersion_vararg;
+}
+
+const char* plugin_bridge_version_def(void) {
+    return s_VersionDef;
+}
+
 // Global storage for REAPER's GetFunc pointer
 // This is a central lookup mechanism for all REAPER API functions
 // It's accessed from multiple functions but is set only once during initialization
 static void* s_GetFunc = NULL;
 
+// The thread REAPER loaded the plugin on, which is the thread REAPER's API must be called
+// from. Recorded with the GetFunc pointer, since both are set during initialization.
+#ifdef _WIN32
+static DWORD s_MainThread = 0;
+#else
+static pthread_t s_MainThread;
+#endif
+static bool s_MainThreadSet = false;
+
 /**
  * Sets the global GetFunc pointer that's used to lookup REAPER functions
  * Called once during plugin initialization
@@ -604,12 +3683,57 @@ void plugin_bridge_set_get_func(void* get_func_ptr) {
     // Only store if it's a valid pointer
     if (get_func_ptr) {
         s_GetFunc = get_func_ptr;
+#ifdef _WIN32
+        s_MainThread = GetCurrentThreadId();
+#else
+        s_MainThread = pthread_self();
+#endif
+        s_MainThreadSet = true;
         LOG_INFO("Global GetFunc pointer set successfully");
     } else {
         LOG_ERROR("Attempted to set NULL GetFunc pointer");
     }
 }
 
+/**
+ * Forgets the GetFunc pointer and the main thread when the plugin unloads, so nothing
+ * calls into a REAPER that has let go of the plugin. A reload sets them again.
+ */
+void plugin_bridge_reset(void) {
+    LOG_INFO("Clearing global GetFunc pointer");
+    s_GetFunc = NULL;
+    s_MainThreadSet = false;
+}
+
+/**
+ * Writes the path of the library this bridge is linked into, e.g. the plugin's dylib in
+ * UserPlugins, to out. Returns false if the platform can't tell.
+ */
+bool plugin_bridge_module_path(char* out, int out_size) {
+    if (!out || out_size <= 0) {
+        return false;
+    }
+    out[0] = '\0';
+
+#ifdef _WIN32
+    HMODULE module = NULL;
+    if (!GetModuleHandleExA(GET_MODULE_HANDLE_EX_FLAG_FROM_ADDRESS | GET_MODULE_HANDLE_EX_FLAG_UNCHANGED_REFCOUNT,
+            (LPCSTR)(void*)plugin_bridge_module_path, &module)) {
+        return false;
+    }
+    DWORD length = GetModuleFileNameA(module, out, (DWORD)out_size);
+    return length > 0 && length < (DWORD)out_size;
+#else
+    Dl_info info;
+    if (!dladdr((void*)plugin_bridge_module_path, &info) || !info.dli_fname) {
+        return false;
+    }
+    strncpy(out, info.dli_fname, out_size - 1);
+    out[out_size - 1] = '\0';
+    return true;
+#endif
+}
+
 /**
  * Returns the stored GetFunc pointer
  * This is the bootstrap function used to access all other REAPER functions
@@ -619,6 +3743,28 @@ void* plugin_bridge_get_get_func() {
     return s_GetFunc;
 }
 
+/**
+ * Returns the number of GetFunc lookups since the plugin loaded
+ */
+long long plugin_bridge_get_func_call_count(void) {
+    return s_GetFuncCalls;
+}
+
+/**
+ * Reports whether the caller is on the thread that initialized the plugin
+ * Before initialization every thread counts as the main thread
+ */
+bool plugin_bridge_is_main_thread(void) {
+    if (!s_MainThreadSet) {
+        return true;
+    }
+#ifdef _WIN32
+    return GetCurrentThreadId() == s_MainThread;
+#else
+    return pthread_equal(pthread_self(), s_MainThread) != 0;
+#endif
+}
+
 /**
  * Main entry point called by REAPER when loading the plugin
  * This function forwards the call to the Go entry point via CGo
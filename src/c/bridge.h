@@ -20,8 +20,19 @@ extern "C" {
 
 void* plugin_bridge_call_get_func(void* get_func_ptr, const char* name);
 void plugin_bridge_call_show_console_msg(void* func_ptr, const char* message);
+void plugin_bridge_call_clear_console(void* func_ptr);
 int plugin_bridge_call_register(void* register_func_ptr, const char* name, void* info);
 void* plugin_bridge_call_get_selected_track(void* func_ptr, int proj, int seltrackidx);
+void* plugin_bridge_call_get_track(void* func_ptr, void* proj, int trackidx);
+void* plugin_bridge_call_get_master_track(void* func_ptr, void* proj);
+void plugin_bridge_call_get_mouse_position(void* func_ptr, int* x, int* y);
+void* plugin_bridge_call_get_track_from_point(void* func_ptr, int screen_x, int screen_y, int* info);
+int plugin_bridge_call_get_focused_fx2(void* func_ptr, int* tracknumber, int* itemnumber, int* fxnumber);
+bool plugin_bridge_call_get_last_touched_fx(void* func_ptr, int* tracknumber, int* fxnumber, int* paramnumber);
+bool plugin_bridge_call_validate_ptr2(void* func_ptr, void* proj, void* pointer, const char* ctypename);
+
+// The TakeFX_ functions have the same signatures with a MediaItem_Take* in place of the
+// track, so these calls serve both, given the TakeFX_ function pointer
 int plugin_bridge_call_track_fx_get_count(void* func_ptr, void* track);
 void plugin_bridge_call_track_fx_get_name(void* func_ptr, void* track, int fx_idx, char* buf, int buf_size);
 int plugin_bridge_call_track_fx_get_param_count(void* func_ptr, void* track, int fx_idx);
@@ -29,10 +40,185 @@ void plugin_bridge_call_track_fx_get_param_name(void* func_ptr, void* track, int
 double plugin_bridge_call_track_fx_get_param(void* func_ptr, void* track, int fx_idx, int param_idx, double* minval, double* maxval);
 void plugin_bridge_call_track_fx_get_param_formatted(void* func_ptr, void* track, int fx_idx, int param_idx, char* buf, int buf_size);
 bool plugin_bridge_call_track_fx_set_param(void* func_ptr, void* track, int fx_idx, int param_idx, double val);
+bool plugin_bridge_call_track_fx_format_param_value(void* func_ptr, void* track, int fx_idx, int param_idx, double val, char* buf, int buf_size);
+bool plugin_bridge_call_track_fx_get_enabled(void* func_ptr, void* track, int fx_idx);
+void plugin_bridge_call_track_fx_set_enabled(void* func_ptr, void* track, int fx_idx, bool enabled);
+bool plugin_bridge_call_track_fx_get_named_config_parm(void* func_ptr, void* track, int fx_idx, const char* parm_name, char* buf, int buf_size);
+int plugin_bridge_call_track_fx_get_param_from_ident(void* func_ptr, void* track, int fx_idx, const char* ident);
+bool plugin_bridge_call_track_fx_get_offline(void* func_ptr, void* track, int fx_idx);
+void plugin_bridge_call_track_fx_set_offline(void* func_ptr, void* track, int fx_idx, bool offline);
+void plugin_bridge_call_track_fx_copy_to_track(void* func_ptr, void* src_track, int src_fx, void* dest_track, int dest_fx, bool is_move);
+
+// GetResourcePath - REAPER's configuration/resource directory
+const char* plugin_bridge_call_get_resource_path(void* func_ptr);
+
+// GetAppVersion - REAPER's version and platform, e.g. "7.22/macOS-arm64"
+const char* plugin_bridge_call_get_app_version(void* func_ptr);
+
+// Project tempo functions - proj is a ReaProject* (NULL for the active project)
+void plugin_bridge_call_get_project_time_signature2(void* func_ptr, void* proj, double* bpm, double* bpi);
+int plugin_bridge_call_count_tempo_time_sig_markers(void* func_ptr, void* proj);
+bool plugin_bridge_call_get_tempo_time_sig_marker(void* func_ptr, void* proj, int ptidx, double* timepos,
+    int* measurepos, double* beatpos, double* bpm, int* timesig_num, int* timesig_denom, bool* lineartempo);
+bool plugin_bridge_call_set_tempo_time_sig_marker(void* func_ptr, void* proj, int ptidx, double timepos,
+    int measurepos, double beatpos, double bpm, int timesig_num, int timesig_denom, bool lineartempo);
+bool plugin_bridge_call_delete_tempo_time_sig_marker(void* func_ptr, void* proj, int markerindex);
+void plugin_bridge_call_time_map_get_time_sig_at_time(void* func_ptr, void* proj, double time,
+    int* timesig_num, int* timesig_denom, double* tempo);
+void plugin_bridge_call_update_timeline(void* func_ptr);
+void plugin_bridge_call_track_list_adjust_windows(void* func_ptr, bool is_minor);
 
 // Track information functions
 double plugin_bridge_call_track_get_info_value(void* func_ptr, void* track, const char* param);
 bool plugin_bridge_call_get_track_name(void* func_ptr, void* track, char* buf, int buf_size, int* flags);
+bool plugin_bridge_call_get_set_media_track_info_string(void* func_ptr, void* track, const char* parmname,
+    char* buf, int buf_size, bool set);
+int plugin_bridge_call_get_track_color(void* func_ptr, void* track);
+int plugin_bridge_call_get_theme_color(void* func_ptr, const char* ini_key, int flags);
+void plugin_bridge_call_set_track_color(void* func_ptr, void* track, int color);
+int plugin_bridge_call_color_to_native(void* func_ptr, int r, int g, int b);
+void plugin_bridge_call_color_from_native(void* func_ptr, int color, int* r, int* g, int* b);
+bool plugin_bridge_call_set_media_track_info_value(void* func_ptr, void* track, const char* parmname, double value);
+bool plugin_bridge_call_track_fx_get_fx_guid(void* func_ptr, void* guid_to_string_ptr, void* track, int fx_idx,
+    char* buf, int buf_size);
+
+// Docker functions - hwnd is an HWND (an NSView on macOS)
+void plugin_bridge_call_dock_window_add_ex(void* func_ptr, void* hwnd, const char* name, const char* identstr, bool allow_show);
+void plugin_bridge_call_dock_window_remove(void* func_ptr, void* hwnd);
+void plugin_bridge_call_dock_window_activate(void* func_ptr, void* hwnd);
+int plugin_bridge_call_dock_is_child_of_dock(void* func_ptr, void* hwnd, bool* is_floating);
+
+// Undo functions - proj is a ReaProject* (NULL for the active project)
+void plugin_bridge_call_undo_begin_block2(void* func_ptr, void* proj);
+void plugin_bridge_call_undo_end_block2(void* func_ptr, void* proj, const char* desc, int extraflags);
+
+// Project editing - proj is a ReaProject* (NULL for the active project)
+int plugin_bridge_call_count_tracks(void* func_ptr, void* proj);
+void plugin_bridge_call_insert_track_at_index(void* func_ptr, int idx, bool want_defaults);
+void plugin_bridge_call_delete_track(void* func_ptr, void* track);
+int plugin_bridge_call_track_fx_add_by_name(void* func_ptr, void* track, const char* fxname, bool rec_fx, int instantiate);
+void plugin_bridge_call_main_on_command_ex(void* func_ptr, int command, int flag, void* proj);
+int plugin_bridge_call_named_command_lookup(void* func_ptr, const char* command_name);
+const char* plugin_bridge_call_reverse_named_command_lookup(void* func_ptr, int command_id);
+int plugin_bridge_call_get_toggle_command_state_ex(void* func_ptr, int section_id, int command_id);
+void* plugin_bridge_call_get_fx_envelope(void* func_ptr, void* track, int fx_idx, int param_idx, bool create);
+bool plugin_bridge_call_get_track_state_chunk(void* func_ptr, void* track, char* buf, int buf_size, bool is_undo);
+bool plugin_bridge_call_set_track_state_chunk(void* func_ptr, void* track, const char* chunk, bool is_undo);
+bool plugin_bridge_call_get_user_file_name_for_read(void* func_ptr, char* filename, const char* title, const char* default_ext);
+int plugin_bridge_call_count_selected_tracks2(void* func_ptr, void* proj, bool want_master);
+double plugin_bridge_call_get_cursor_position_ex(void* func_ptr, void* proj);
+double plugin_bridge_call_get_set_project_info(void* func_ptr, void* proj, const char* desc, double value, bool is_set);
+int plugin_bridge_call_get_proj_ext_state(void* func_ptr, void* proj, const char* extname, const char* key,
+                                          char* buf, int buf_size);
+int plugin_bridge_call_set_proj_ext_state(void* func_ptr, void* proj, const char* extname, const char* key,
+                                          const char* value);
+bool plugin_bridge_call_enum_proj_ext_state(void* func_ptr, void* proj, const char* extname, int idx,
+                                            char* key_buf, int key_buf_size);
+
+// Structure to hold a short MIDI message from REAPER's input history
+typedef struct {
+    int seq;                  // Sequence number, increasing with each event
+    unsigned char msg[3];
+    int size;                 // Bytes used in msg
+    int device;               // Input device index
+} midi_input_event_t;
+
+// Recent MIDI input - reads the short messages newer than after_seq, newest first, and the newest sequence number
+int plugin_bridge_call_get_recent_midi_input(void* func_ptr, int after_seq, midi_input_event_t* out, int max_count,
+                                             int* out_latest_seq);
+bool plugin_bridge_call_get_midi_input_name(void* func_ptr, int dev, char* buf, int buf_size);
+
+// Markers, regions and the region render matrix - proj is a ReaProject* (NULL for the active project)
+int plugin_bridge_call_enum_project_markers3(void* func_ptr, void* proj, int idx, bool* isrgn, double* pos,
+    double* rgnend, const char** name, int* markrgnindexnumber, int* color);
+void plugin_bridge_call_get_last_marker_and_cur_region(void* func_ptr, void* proj, double time,
+    int* markeridx, int* regionidx);
+void* plugin_bridge_call_enum_region_render_matrix(void* func_ptr, void* proj, int regionindex, int rendertrack);
+void plugin_bridge_call_set_region_render_matrix(void* func_ptr, void* proj, int regionindex, void* track, int flag);
+
+// Media items and takes - proj is a ReaProject* (NULL for the active project)
+int plugin_bridge_call_count_selected_media_items(void* func_ptr, void* proj);
+void* plugin_bridge_call_get_selected_media_item(void* func_ptr, void* proj, int selitem);
+void* plugin_bridge_call_get_active_take(void* func_ptr, void* item);
+void* plugin_bridge_call_get_media_item_track(void* func_ptr, void* item);
+double plugin_bridge_call_get_media_item_info_value(void* func_ptr, void* item, const char* parmname);
+bool plugin_bridge_call_set_media_item_info_value(void* func_ptr, void* item, const char* parmname, double value);
+double plugin_bridge_call_get_media_item_take_info_value(void* func_ptr, void* take, const char* parmname);
+bool plugin_bridge_call_set_media_item_take_info_value(void* func_ptr, void* take, const char* parmname, double value);
+const char* plugin_bridge_call_get_take_name(void* func_ptr, void* take);
+void plugin_bridge_call_update_item_in_project(void* func_ptr, void* item);
+
+// Take markers and stretch markers - positions are in the take's source media (take markers)
+// or in the item (stretch markers)
+int plugin_bridge_call_get_num_take_markers(void* func_ptr, void* take);
+double plugin_bridge_call_get_take_marker(void* func_ptr, void* take, int idx, char* name, int name_size, int* color);
+int plugin_bridge_call_set_take_marker(void* func_ptr, void* take, int idx, const char* name, double* srcpos, int* color);
+bool plugin_bridge_call_delete_take_marker(void* func_ptr, void* take, int idx);
+int plugin_bridge_call_get_take_num_stretch_markers(void* func_ptr, void* take);
+int plugin_bridge_call_get_take_stretch_marker(void* func_ptr, void* take, int idx, double* pos, double* srcpos);
+int plugin_bridge_call_set_take_stretch_marker(void* func_ptr, void* take, int idx, double pos, const double* srcpos);
+int plugin_bridge_call_delete_take_stretch_markers(void* func_ptr, void* take, int idx, const int* count);
+double plugin_bridge_call_get_take_stretch_marker_slope(void* func_ptr, void* take, int idx);
+bool plugin_bridge_call_set_take_stretch_marker_slope(void* func_ptr, void* take, int idx, double slope);
+
+// Automation items and envelope points - autoitem_idx is -1 for the underlying envelope
+int plugin_bridge_call_count_automation_items(void* func_ptr, void* env);
+int plugin_bridge_call_insert_automation_item(void* func_ptr, void* env, int pool_id, double position, double length);
+double plugin_bridge_call_get_set_automation_item_info(void* func_ptr, void* env, int autoitem_idx, const char* desc,
+    double value, bool is_set);
+bool plugin_bridge_call_get_set_automation_item_info_string(void* func_ptr, void* env, int autoitem_idx, const char* desc,
+    char* buf, int buf_size, bool is_set);
+int plugin_bridge_call_count_envelope_points_ex(void* func_ptr, void* env, int autoitem_idx);
+bool plugin_bridge_call_get_envelope_point_ex(void* func_ptr, void* env, int autoitem_idx, int ptidx, double* time,
+    double* value, int* shape, double* tension, bool* selected);
+bool plugin_bridge_call_insert_envelope_point_ex(void* func_ptr, void* env, int autoitem_idx, double time, double value,
+    int shape, double tension, bool selected, bool no_sort);
+bool plugin_bridge_call_delete_envelope_point_range_ex(void* func_ptr, void* env, int autoitem_idx,
+    double time_start, double time_end);
+bool plugin_bridge_call_envelope_sort_points_ex(void* func_ptr, void* env, int autoitem_idx);
+
+// Audio accessors - samples are interleaved doubles, numchannels per frame
+void* plugin_bridge_call_create_track_audio_accessor(void* func_ptr, void* track);
+void plugin_bridge_call_destroy_audio_accessor(void* func_ptr, void* accessor);
+double plugin_bridge_call_get_audio_accessor_start_time(void* func_ptr, void* accessor);
+double plugin_bridge_call_get_audio_accessor_end_time(void* func_ptr, void* accessor);
+int plugin_bridge_call_get_audio_accessor_samples(void* func_ptr, void* accessor, int samplerate, int numchannels,
+    double starttime_sec, int numsamplesperchannel, double* samplebuffer);
+
+// SWS extension functions - only resolvable when SWS is installed
+void plugin_bridge_call_cf_get_sws_version(void* func_ptr, char* buf, int buf_size);
+const char* plugin_bridge_call_nf_get_sws_track_notes(void* func_ptr, void* track);
+void plugin_bridge_call_nf_set_sws_track_notes(void* func_ptr, void* track, const char* notes);
+void plugin_bridge_call_cf_set_clipboard(void* func_ptr, const char* text);
+void plugin_bridge_call_cf_get_clipboard(void* func_ptr, char* buf, int buf_size);
+void plugin_bridge_call_br_get_mouse_cursor_context(void* func_ptr, char* window, int window_size,
+    char* segment, int segment_size, char* details, int details_size);
+void* plugin_bridge_call_br_env_alloc(void* func_ptr, void* envelope, bool take_envelopes_use_project_time);
+int plugin_bridge_call_br_env_count_points(void* func_ptr, void* br_envelope);
+bool plugin_bridge_call_br_env_get_point(void* func_ptr, void* br_envelope, int id, double* position,
+    double* value, int* shape, bool* selected, double* bezier_tension);
+bool plugin_bridge_call_br_env_set_point(void* func_ptr, void* br_envelope, int id, double position,
+    double value, int shape, bool selected, double bezier_tension);
+bool plugin_bridge_call_br_env_free(void* func_ptr, void* br_envelope, bool commit);
+
+// Windows - hwnd is an HWND (an NSView on macOS)
+void* plugin_bridge_call_get_main_hwnd(void* func_ptr);
+
+// js_ReaScriptAPI extension functions - only resolvable when js_ReaScriptAPI is installed
+double plugin_bridge_call_js_version(void* func_ptr);
+void* plugin_bridge_call_js_window_create(void* func_ptr, const char* title, const char* class_name,
+    int x, int y, int w, int h, char* style, void* owner);
+void plugin_bridge_call_js_window_destroy(void* func_ptr, void* hwnd);
+bool plugin_bridge_call_js_window_is_window(void* func_ptr, void* hwnd);
+void* plugin_bridge_call_js_window_find(void* func_ptr, const char* title, bool exact);
+void* plugin_bridge_call_js_window_set_parent(void* func_ptr, void* child, void* parent);
+void plugin_bridge_call_js_window_show(void* func_ptr, void* hwnd, const char* state);
+bool plugin_bridge_call_js_window_set_title(void* func_ptr, void* hwnd, const char* title);
+bool plugin_bridge_call_js_window_set_position(void* func_ptr, void* hwnd, int left, int top, int width, int height);
+bool plugin_bridge_call_js_window_get_rect(void* func_ptr, void* hwnd, int* left, int* top, int* right, int* bottom);
+
+// Menus
+bool plugin_bridge_call_add_extensions_main_menu(void* func_ptr);
 
 // GetUserInputs - Simple form dialog
 bool plugin_bridge_call_get_user_inputs(void* func_ptr, const char* title, int num_inputs, 
@@ -42,7 +228,11 @@ bool plugin_bridge_call_get_user_inputs(void* func_ptr, const char* title, int n
 int plugin_bridge_call_show_message_box(void* func_ptr, const char* text, const char* title, int type);
 
 void plugin_bridge_set_get_func(void* get_func_ptr);
+void plugin_bridge_reset(void);
 void* plugin_bridge_get_get_func();
+bool plugin_bridge_is_main_thread(void);
+bool plugin_bridge_module_path(char* out, int out_size);
+long long plugin_bridge_get_func_call_count(void);
 
 // Structure to hold parameter data
 typedef struct {
@@ -53,10 +243,130 @@ typedef struct {
     char formatted[256];
 } fx_param_t;
 
-// Function to get all FX parameters in a single call
-bool plugin_bridge_batch_get_fx_parameters(void* track, int fx_idx, fx_param_t* params, 
+// Function to get up to max_params FX parameters from first_param on in a single call
+// out_param_count receives the FX's full parameter count, so callers can read the rest
+bool plugin_bridge_batch_get_fx_parameters(void* track, int fx_idx, int first_param, fx_param_t* params,
                                         int max_params, int* out_param_count);
 
+// Structure to hold the parts of a parameter that change: its value
+typedef struct {
+    double value;
+    char formatted[256];
+    bool valid; // False if the parameter no longer exists
+} fx_value_t;
+
+// Structure to name a parameter of one of a track's FX
+typedef struct {
+    int fx_idx;
+    int param_idx;
+} fx_param_ref_t;
+
+// Function to get the values of all FX parameters in a single call, without names or ranges
+bool plugin_bridge_batch_get_fx_values(void* track, int fx_idx, fx_value_t* values,
+                                    int max_params, int* out_param_count);
+
+// Function to get the values of any parameters of a track's FX in a single call
+bool plugin_bridge_batch_get_param_values(void* track, const fx_param_ref_t* refs, int ref_count,
+                                       fx_value_t* values, int* out_fx_count);
+
+// Structure to hold FX bypass/offline state
+typedef struct {
+    int index;
+    bool enabled;
+    bool offline;
+} fx_state_t;
+
+// Functions to get/set the bypass and offline state of several FX in a single call
+bool plugin_bridge_batch_get_fx_states(void* track, fx_state_t* states, int max_fx, int* out_fx_count);
+bool plugin_bridge_batch_set_fx_states(void* track, const fx_state_t* states, int count);
+
+// Operations a parameter change applies to the current value
+enum {
+    FX_PARAM_OP_SET = 0,      // value
+    FX_PARAM_OP_ADD = 1,      // current + value
+    FX_PARAM_OP_MULTIPLY = 2, // current * value
+    FX_PARAM_OP_CLAMP = 3     // current limited to [min, max]
+};
+
+// Structure to hold a change to a parameter of any track's FX. The result is kept within
+// the parameter's range; ok and result are set by the call.
+typedef struct {
+    void* track;
+    int fx_idx;
+    int param_idx;
+    int op;
+    double value;
+    double min;
+    double max;
+    bool ok;
+    double result;
+} fx_param_change_t;
+
+// Function to set parameters of FX on any number of tracks in a single call
+bool plugin_bridge_batch_set_param_values(fx_param_change_t* changes, int count, int* out_applied);
+
+// Structure to hold what FX lists show about an FX
+typedef struct {
+    int index;
+    char name[256];
+    char type[32];            // fx_type, e.g. "VST3"
+    char ident[1024];         // fx_ident, the plugin file and/or unique ID
+    char original_name[256];  // fx_name, the name before any renaming
+    bool enabled;
+    bool offline;
+} fx_summary_t;
+
+// Function to get the summary of every FX on a track in a single call; the caller frees the result
+fx_summary_t* plugin_bridge_batch_get_fx_summaries(void* track, int* out_fx_count);
+
+// Structure to hold what track lists show about a track
+typedef struct {
+    int number;               // IP_TRACKNUMBER: 1-based, -1 for the master track
+    char name[256];
+    char guid[64];
+    int fx_count;
+    bool mute;
+    int solo;                 // I_SOLO: 0 not soloed, 1 soloed, 2 soloed in place, and more
+    bool rec_arm;
+    double volume;            // D_VOL, as a gain (1 is 0dB)
+    double pan;               // D_PAN, -1 (left) to 1 (right)
+    int color;                // I_CUSTOMCOLOR, native, with 0x1000000 set for custom colors
+    int r, g, b;              // The custom color's components, 0 for the default color
+    int folder_depth;         // I_FOLDERDEPTH: 1 starts a folder, negative values close folders
+    int depth;                // Nesting level, 0 for top-level tracks
+    void* parent;             // Folder track the track is in, or NULL
+} track_info_t;
+
+// Function to get what track lists show about a track in a single call
+bool plugin_bridge_batch_get_track_info(void* track, track_info_t* out);
+
+// Structs passed between C and Go, to check that both agree on their layout
+enum {
+    BRIDGE_STRUCT_FX_PARAM = 0,
+    BRIDGE_STRUCT_FX_VALUE,
+    BRIDGE_STRUCT_FX_PARAM_REF,
+    BRIDGE_STRUCT_FX_STATE,
+    BRIDGE_STRUCT_FX_PARAM_CHANGE,
+    BRIDGE_STRUCT_FX_SUMMARY,
+    BRIDGE_STRUCT_TRACK_INFO,
+    BRIDGE_STRUCT_MIDI_INPUT_EVENT,
+    BRIDGE_STRUCT_COUNT
+};
+
+// Size and alignment of a bridge struct as the C compiler lays it out; false for an unknown struct
+bool plugin_bridge_struct_layout(int which, size_t* out_size, size_t* out_align);
+
+// Static scratch buffer for string results; the Go side serializes access to it
+#define PLUGIN_BRIDGE_SCRATCH_SIZE 4096
+char* plugin_bridge_get_scratch_buffer(void);
+
+// The extension's version, exported to ReaScripts as GoReaperExtension_GetVersion. The
+// pointers are registered with REAPER as API_, APIvararg_ and APIdef_ entries.
+void plugin_bridge_set_version(const char* version);
+void* plugin_bridge_version_func(void);
+void* plugin_bridge_version_vararg(void);
+const char* plugin_bridge_version_def(void);
+
 
 // GetExtState
 const char* plugin_bridge_call_get_ext_state(void* func_ptr, const char* section, const char* key);
@@ -81,6 +391,15 @@ extern int GoReaperPluginEntry(void* hInstance, void* rec);
 extern int goHookCommandProc(int commandId, int flag);
 extern int goHookCommandProc2(void* section, int commandId, int val, int valhw, int relmode, void* hwnd, void* proj);
 
+// Toggle state callback
+extern int goToggleActionProc(int commandId);
+
+// Custom menu hook callback
+extern void goHookCustomMenu(char* menuidstr, void* menu, int flag);
+
+// Timer callback, called on the main thread about 30 times a second
+extern void goTimerProc(void);
+
 #ifdef __cplusplus
 }
 #endif
@@ -148,6 +148,11 @@ void log_set_path(const char* path) {
     }
 }
 
+// Get the path of the log file
+const char* log_get_path() {
+    return get_log_file_path();
+}
+
 // Enable or disable logging at runtime
 void log_set_enabled(bool enabled) {
     if (enabled != logging_enabled) {
@@ -25,6 +25,7 @@ void log_message_v(LogLevel level, const char* func, const char* format, ...);
 
 // Configuration functions
 void log_set_path(const char* path);
+const char* log_get_path(void);
 void log_set_enabled(bool enabled);
 void log_set_level(LogLevel level);
 LogLevel log_get_level(void);
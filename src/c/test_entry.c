@@ -0,0 +1,8 @@
+// test_entry.c stands in for the plugin entry point that cmd/reaper-ext exports, so the
+// bridge can be linked into go test binaries. It is only built by "make test".
+
+int GoReaperPluginEntry(void* hInstance, void* rec) {
+    (void)hInstance;
+    (void)rec;
+    return 0;
+}
@@ -6,7 +6,9 @@ package core
 */
 import "C"
 import (
+	"context"
 	"fmt"
+	"time"
 	"unsafe"
 
 	"go-reaper/src/pkg/logger"
@@ -20,6 +22,22 @@ func Initialize(hInstance unsafe.Pointer, rec unsafe.Pointer) error {
 		return fmt.Errorf("failed to initialize REAPER API: %v", err)
 	}
 
+	// A reload runs in the same process, where the queue was closed at the last unload
+	MainThread.reopen()
+
+	// On unload, unregister from REAPER last, once nothing else can call it
+	RegisterShutdown("REAPER API", 0, func(ctx context.Context) error {
+		reaper.Teardown()
+		return nil
+	})
+
+	// Before that, fail calls waiting for the main thread, so background work stops
+	RegisterShutdown("background work", 3*time.Second, waitForBackground)
+	RegisterShutdown("REAPER call queue", 0, func(ctx context.Context) error {
+		MainThread.Close()
+		return nil
+	})
+
 	// Log to the REAPER console
 	logger.Debug("----------------------------------------------------------")
 	logger.Debug("Hello from Go REAPER extension!")
@@ -0,0 +1,133 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"go-reaper/src/reaper"
+	"sync"
+)
+
+// ErrCallQueueClosed is returned for calls made after the queue was closed, e.g. while
+// the plugin unloads and the timer no longer runs
+var ErrCallQueueClosed = errors.New("the REAPER call queue is closed")
+
+// CallQueue runs functions that use REAPER's API on the main thread, one at a time and in
+// the order they were queued, at REAPER's next timer tick. REAPER's API may only be called
+// from the main thread, so goroutines (network requests, servers, watchers) queue their
+// REAPER work here rather than calling the wrappers themselves.
+type CallQueue struct {
+	mutex     sync.Mutex
+	pending   []func()
+	scheduled bool // A drain is waiting for the timer
+	closed    bool
+}
+
+// MainThread is the queue for REAPER calls from background goroutines
+var MainThread = NewCallQueue()
+
+// NewCallQueue returns an empty queue
+func NewCallQueue() *CallQueue {
+	return &CallQueue{}
+}
+
+// Call runs fn on the main thread and waits for it to finish, returning its error. A
+// panic in fn is recovered and returned as an error. On the main thread fn runs at once,
+// since waiting for the timer there would never return.
+func (q *CallQueue) Call(fn func() error) error {
+	if reaper.IsMainThread() {
+		return fn()
+	}
+
+	done := make(chan error, 1)
+	err := q.enqueue(func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("panic in queued REAPER call: %v", r)
+			}
+		}()
+		done <- fn()
+	}, done)
+	if err != nil {
+		return err
+	}
+	return <-done
+}
+
+// CallAsync queues fn to run on the main thread and returns at once. Panics in fn are
+// recovered and logged.
+func (q *CallQueue) CallAsync(fn func()) error {
+	return q.enqueue(func() {
+		defer reaper.Recover("queued REAPER call")
+		fn()
+	}, nil)
+}
+
+// Close stops the queue. Queued calls that haven't run fail with ErrCallQueueClosed, and
+// so does every later call.
+func (q *CallQueue) Close() {
+	q.mutex.Lock()
+	q.closed = true
+	pending := q.pending
+	q.pending = nil
+	q.mutex.Unlock()
+
+	// The queue is closed, so each call only releases its waiter instead of running
+	for _, call := range pending {
+		call()
+	}
+}
+
+// reopen accepts calls again after Close, for when the plugin is loaded again
+func (q *CallQueue) reopen() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.closed = false
+	q.scheduled = false
+}
+
+// enqueue adds run to the queue and schedules a drain if none is waiting. done, if not
+// nil, receives ErrCallQueueClosed should the queue close before run is reached.
+func (q *CallQueue) enqueue(run func(), done chan error) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.closed {
+		return ErrCallQueueClosed
+	}
+
+	call := func() {
+		q.mutex.Lock()
+		closed := q.closed
+		q.mutex.Unlock()
+
+		if closed {
+			if done != nil {
+				done <- ErrCallQueueClosed
+			}
+			return
+		}
+		run()
+	}
+	q.pending = append(q.pending, call)
+
+	if !q.scheduled {
+		q.scheduled = true
+		reaper.Defer(q.drain)
+	}
+	return nil
+}
+
+// drain runs on the timer tick. Calls queued while it runs wait for the next tick, so a
+// busy queue can't stall REAPER's main thread.
+func (q *CallQueue) drain() {
+	q.mutex.Lock()
+	pending := q.pending
+	q.pending = nil
+	q.scheduled = false
+	q.mutex.Unlock()
+
+	for _, call := range pending {
+		call()
+	}
+}
@@ -0,0 +1,170 @@
+package core
+
+import (
+	"go-reaper/src/reaper"
+)
+
+// Providers of the functions the extension looks up
+const (
+	ProviderReaper   = "REAPER"
+	ProviderSWS      = "SWS"
+	ProviderJSReaAPI = "js_ReaScriptAPI"
+)
+
+// Capability reports whether a function the extension can use was resolved
+type Capability struct {
+	Function  string
+	Provider  string // ProviderReaper or the extension that adds the function
+	Optional  bool   // Features degrade without it instead of failing
+	Available bool
+}
+
+// requiredFunctions are the REAPER functions the bridge calls
+var requiredFunctions = []string{
+	"ShowConsoleMsg",
+	"ShowMessageBox",
+	"GetUserInputs",
+	"GetResourcePath",
+	"GetExtState",
+	"SetExtState",
+	"HasExtState",
+	"DeleteExtState",
+	"ValidatePtr2",
+	"CountTracks",
+	"GetTrack",
+	"GetMasterTrack",
+	"GetSelectedTrack",
+	"GetTrackName",
+	"GetMediaTrackInfo_Value",
+	"GetSetMediaTrackInfo_String",
+	"GetTrackDepth",
+	"GetParentTrack",
+	"InsertTrackAtIndex",
+	"DeleteTrack",
+	"GetMousePosition",
+	"GetTrackFromPoint",
+	"GetFocusedFX2",
+	"GetLastTouchedFX",
+	"GetProjectTimeSignature2",
+	"CountTempoTimeSigMarkers",
+	"GetTempoTimeSigMarker",
+	"SetTempoTimeSigMarker",
+	"DeleteTempoTimeSigMarker",
+	"TimeMap_GetTimeSigAtTime",
+	"UpdateTimeline",
+	"TrackList_AdjustWindows",
+	"GetTrackColor",
+	"SetTrackColor",
+	"ColorToNative",
+	"ColorFromNative",
+	"SetMediaTrackInfo_Value",
+	"Main_OnCommandEx",
+	"NamedCommandLookup",
+	"ReverseNamedCommandLookup",
+	"GetToggleCommandStateEx",
+	"GetFXEnvelope",
+	"GetTrackStateChunk",
+	"SetTrackStateChunk",
+	"GetUserFileNameForRead",
+	"CountSelectedTracks2",
+	"GetCursorPositionEx",
+	"GetSetProjectInfo",
+	"GetProjExtState",
+	"SetProjExtState",
+	"EnumProjExtState",
+	"MIDI_GetRecentInputEvent",
+	"GetMIDIInputName",
+	"EnumProjectMarkers3",
+	"GetLastMarkerAndCurRegion",
+	"EnumRegionRenderMatrix",
+	"SetRegionRenderMatrix",
+	"Undo_BeginBlock2",
+	"Undo_EndBlock2",
+	"AddExtensionsMainMenu",
+	"TrackFX_GetCount",
+	"TrackFX_GetFXName",
+	"TrackFX_AddByName",
+	"TrackFX_CopyToTrack",
+	"TrackFX_GetEnabled",
+	"TrackFX_SetEnabled",
+	"TrackFX_GetOffline",
+	"TrackFX_SetOffline",
+	"TrackFX_GetNumParams",
+	"TrackFX_GetFXGUID",
+	"guidToString",
+	"TrackFX_GetParamName",
+	"TrackFX_GetParam",
+	"TrackFX_SetParam",
+	"TrackFX_GetFormattedParamValue",
+	"TrackFX_FormatParamValue",
+	"TrackFX_GetNamedConfigParm",
+	"TrackFX_GetParamFromIdent",
+	"DockWindowAddEx",
+	"DockWindowRemove",
+	"DockWindowActivate",
+	"DockIsChildOfDock",
+	"GetMainHwnd",
+}
+
+// optionalFunctions are functions from other extensions, by provider, used when installed
+var optionalFunctions = []struct {
+	provider string
+	function string
+}{
+	{ProviderReaper, "GetAppVersion"},
+	{ProviderSWS, "CF_GetSWSVersion"},
+	{ProviderSWS, "BR_GetMouseCursorContext"},
+	{ProviderSWS, "SNM_GetIntConfigVar"},
+	{ProviderSWS, "NF_GetSWSTrackNotes"},
+	{ProviderSWS, "NF_SetSWSTrackNotes"},
+	{ProviderSWS, "BR_EnvAlloc"},
+	{ProviderSWS, "BR_EnvCountPoints"},
+	{ProviderSWS, "BR_EnvGetPoint"},
+	{ProviderSWS, "BR_EnvSetPoint"},
+	{ProviderSWS, "BR_EnvFree"},
+	{ProviderJSReaAPI, "JS_ReaScriptAPI_Version"},
+	{ProviderJSReaAPI, "JS_Window_Create"},
+	{ProviderJSReaAPI, "JS_Window_Destroy"},
+	{ProviderJSReaAPI, "JS_Window_IsWindow"},
+	{ProviderJSReaAPI, "JS_Window_Find"},
+	{ProviderJSReaAPI, "JS_Window_SetParent"},
+	{ProviderJSReaAPI, "JS_Window_Show"},
+	{ProviderJSReaAPI, "JS_Window_SetTitle"},
+	{ProviderJSReaAPI, "JS_Window_SetPosition"},
+	{ProviderJSReaAPI, "JS_Window_GetRect"},
+}
+
+// Capabilities lists the functions the extension uses and whether REAPER resolved each
+// one, required functions first
+func Capabilities() []Capability {
+	capabilities := make([]Capability, 0, len(requiredFunctions)+len(optionalFunctions))
+
+	for _, name := range requiredFunctions {
+		capabilities = append(capabilities, Capability{
+			Function:  name,
+			Provider:  ProviderReaper,
+			Available: reaper.IsFunctionAvailable(name),
+		})
+	}
+
+	for _, optional := range optionalFunctions {
+		capabilities = append(capabilities, Capability{
+			Function:  optional.function,
+			Provider:  optional.provider,
+			Optional:  true,
+			Available: reaper.IsFunctionAvailable(optional.function),
+		})
+	}
+
+	return capabilities
+}
+
+// HasProvider reports whether any function from an extension such as SWS was resolved
+func HasProvider(provider string) bool {
+	for _, optional := range optionalFunctions {
+		if optional.provider == provider && reaper.IsFunctionAvailable(optional.function) {
+			return true
+		}
+	}
+	return false
+}
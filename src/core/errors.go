@@ -0,0 +1,100 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"go-reaper/src/llm"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"path/filepath"
+	"runtime"
+)
+
+// ErrorCategory classifies errors so they can be logged and reported consistently
+type ErrorCategory string
+
+// Error categories
+const (
+	CategoryUser     ErrorCategory = "user"     // Something the user can fix, e.g. no track selected
+	CategoryReaper   ErrorCategory = "reaper"   // A REAPER API call failed
+	CategoryLLM      ErrorCategory = "llm"      // The LLM provider failed or returned something unusable
+	CategoryStorage  ErrorCategory = "storage"  // Reading or writing files or settings failed
+	CategoryNetwork  ErrorCategory = "network"  // A server other than an LLM provider couldn't be reached
+	CategoryInternal ErrorCategory = "internal" // A bug in the extension
+)
+
+// Error carries a friendly message for dialogs alongside the detail developers need
+// from the log
+type Error struct {
+	Category    ErrorCategory
+	UserMessage string // Shown to the user
+	Detail      string // Extra context for the log, if any
+	Location    string // file:line where the error was created
+	Err         error  // Underlying error, if any
+}
+
+// NewError creates an Error, recording where it was created
+func NewError(category ErrorCategory, userMessage string, err error) *Error {
+	e := &Error{Category: category, UserMessage: userMessage, Err: err}
+	if _, file, line, ok := runtime.Caller(1); ok {
+		e.Location = fmt.Sprintf("%s:%d", filepath.Base(file), line)
+	}
+	return e
+}
+
+// WithDetail adds developer detail to the error
+func (e *Error) WithDetail(format string, args ...interface{}) *Error {
+	e.Detail = fmt.Sprintf(format, args...)
+	return e
+}
+
+// Error implements the error interface with the developer-facing description
+func (e *Error) Error() string {
+	message := fmt.Sprintf("%s error: %s", e.Category, e.UserMessage)
+	if e.Detail != "" {
+		message += " (" + e.Detail + ")"
+	}
+	if e.Err != nil {
+		message += ": " + e.Err.Error()
+	}
+	return message
+}
+
+// Unwrap returns the underlying error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// UserMessage returns the text to show the user for any error: the message of an Error,
+// the friendly explanation of an LLM error, or the error text otherwise
+func UserMessage(err error) string {
+	var coreErr *Error
+	if errors.As(err, &coreErr) {
+		return coreErr.UserMessage
+	}
+	var apiErr *llm.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.UserMessage()
+	}
+	return err.Error()
+}
+
+// HandleError logs an error with its full detail and shows the user-facing message in a
+// dialog titled title. User errors are logged at Info level since nothing went wrong.
+func HandleError(title string, err error) {
+	if err == nil {
+		return
+	}
+
+	var coreErr *Error
+	switch {
+	case errors.As(err, &coreErr) && coreErr.Category == CategoryUser:
+		logger.Info("%s: %v", title, err)
+	case errors.As(err, &coreErr) && coreErr.Location != "":
+		logger.Error("%s: %v [at %s]", title, err, coreErr.Location)
+	default:
+		logger.Error("%s: %v", title, err)
+	}
+
+	reaper.MessageBox(UserMessage(err), title)
+}
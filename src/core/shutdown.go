@@ -0,0 +1,132 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"sync"
+	"time"
+)
+
+// DefaultShutdownTimeout is how long a shutdown hook gets when registered without a timeout
+const DefaultShutdownTimeout = 2 * time.Second
+
+// ShutdownFunc cleans up a subsystem when the plugin unloads. ctx is done when its
+// timeout has passed; a hook that waits for something must give up then.
+type ShutdownFunc func(ctx context.Context) error
+
+// shutdownHook is a registered cleanup
+type shutdownHook struct {
+	name    string
+	timeout time.Duration
+	fn      ShutdownFunc
+}
+
+// Hooks in the order they were registered. shutdownContext is cancelled when shutdown
+// starts, so background work can stop early.
+var (
+	shutdownMutex   sync.Mutex
+	shutdownHooks   []shutdownHook
+	shutdownContext context.Context
+	shutdownCancel  context.CancelFunc
+
+	background sync.WaitGroup
+)
+
+func init() {
+	shutdownContext, shutdownCancel = context.WithCancel(context.Background())
+}
+
+// RegisterShutdown adds a cleanup to run when the plugin unloads. Hooks run on the main
+// thread in reverse order of registration, so a subsystem registered after the ones it
+// uses is cleaned up before them. A timeout of 0 uses DefaultShutdownTimeout.
+func RegisterShutdown(name string, timeout time.Duration, fn ShutdownFunc) {
+	if timeout <= 0 {
+		timeout = DefaultShutdownTimeout
+	}
+
+	shutdownMutex.Lock()
+	defer shutdownMutex.Unlock()
+
+	shutdownHooks = append(shutdownHooks, shutdownHook{name: name, timeout: timeout, fn: fn})
+}
+
+// ShutdownContext returns a context that is cancelled when the plugin starts unloading,
+// e.g. for network requests that shouldn't outlive it
+func ShutdownContext() context.Context {
+	shutdownMutex.Lock()
+	defer shutdownMutex.Unlock()
+
+	return shutdownContext
+}
+
+// Go runs fn in a goroutine that shutdown waits for. A panic in fn is recovered and
+// logged. fn should watch ShutdownContext if it can run for long.
+func Go(name string, fn func()) {
+	background.Add(1)
+	go func() {
+		defer background.Done()
+		defer reaper.Recover(name)
+		fn()
+	}()
+}
+
+// Shutdown cancels ShutdownContext and runs the registered hooks, newest first, then
+// forgets them. A hook that fails, panics or overruns its timeout is logged and the
+// rest still run. Hooks registered afterwards run at the next Shutdown.
+func Shutdown() {
+	shutdownMutex.Lock()
+	hooks := shutdownHooks
+	shutdownHooks = nil
+	cancel := shutdownCancel
+	shutdownContext, shutdownCancel = context.WithCancel(context.Background())
+	shutdownMutex.Unlock()
+
+	cancel()
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hook := hooks[i]
+		start := time.Now()
+		err := runShutdownHook(hook)
+		elapsed := time.Since(start)
+
+		switch {
+		case err != nil:
+			logger.Warning("Shutdown of %s failed after %v: %v", hook.name, elapsed.Round(time.Millisecond), err)
+		case elapsed > hook.timeout:
+			logger.Warning("Shutdown of %s took %v, longer than its %v timeout", hook.name, elapsed.Round(time.Millisecond), hook.timeout)
+		default:
+			logger.Debug("Shut down %s in %v", hook.name, elapsed.Round(time.Millisecond))
+		}
+	}
+}
+
+// runShutdownHook runs a hook with its timeout, turning a panic into an error
+func runShutdownHook(hook shutdownHook) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), hook.timeout)
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return hook.fn(ctx)
+}
+
+// waitForBackground waits for the goroutines started with Go, or until ctx is done
+func waitForBackground(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		background.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("background work still running: %w", ctx.Err())
+	}
+}
@@ -0,0 +1,236 @@
+package llm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"go-reaper/src/pkg/logger"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Default cache limits
+const (
+	DefaultCacheMaxEntries = 200
+	DefaultCacheTTL        = 7 * 24 * time.Hour
+)
+
+// cacheEntry is a single cached response
+type cacheEntry struct {
+	Response string    `json:"response"`
+	Created  time.Time `json:"created"`
+}
+
+// ResponseCache stores LLM responses keyed by a hash of the request, persisted as a JSON file.
+// The whole file is rewritten on each change rather than kept in a SQLite database: it
+// is capped at MaxEntries and only looked up by key, and SQLite would add a cgo or pure-Go
+// driver to the plugin on every platform.
+type ResponseCache struct {
+	Path       string        // File the cache is persisted to
+	MaxEntries int           // Oldest entries are evicted beyond this count
+	TTL        time.Duration // Entries older than this are ignored
+
+	mutex   sync.Mutex
+	entries map[string]cacheEntry
+	loaded  bool
+}
+
+// NewResponseCache creates a cache persisted at path with default limits
+func NewResponseCache(path string) *ResponseCache {
+	return &ResponseCache{
+		Path:       path,
+		MaxEntries: DefaultCacheMaxEntries,
+		TTL:        DefaultCacheTTL,
+	}
+}
+
+// CacheKey hashes everything that influences a response into a cache key
+func CacheKey(parts ...string) string {
+	hash := sha256.New()
+	for _, part := range parts {
+		// Length-prefix each part so ("ab","c") and ("a","bc") differ
+		fmt.Fprintf(hash, "%d:%s|", len(part), part)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Get returns the cached response for a key
+func (c *ResponseCache) Get(key string) (string, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.loadLocked()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+	if c.TTL > 0 && time.Since(entry.Created) > c.TTL {
+		delete(c.entries, key)
+		return "", false
+	}
+
+	return entry.Response, true
+}
+
+// Put stores a response and persists the cache
+func (c *ResponseCache) Put(key string, response string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.loadLocked()
+	c.entries[key] = cacheEntry{Response: response, Created: time.Now()}
+	c.evictLocked()
+
+	return c.saveLocked()
+}
+
+// Clear removes all cached responses, including the file on disk
+func (c *ResponseCache) Clear() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.entries = make(map[string]cacheEntry)
+	c.loaded = true
+
+	if err := os.Remove(c.Path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete cache file: %v", err)
+	}
+	return nil
+}
+
+// Len returns the number of cached responses
+func (c *ResponseCache) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.loadLocked()
+	return len(c.entries)
+}
+
+// loadLocked reads the cache file once; a missing or corrupt file yields an empty cache
+func (c *ResponseCache) loadLocked() {
+	if c.loaded {
+		return
+	}
+	c.loaded = true
+	c.entries = make(map[string]cacheEntry)
+
+	data, err := os.ReadFile(c.Path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Warning("Failed to read LLM cache %s: %v", c.Path, err)
+		}
+		return
+	}
+
+	if err := json.Unmarshal(data, &c.entries); err != nil {
+		logger.Warning("Failed to parse LLM cache, starting empty: %v", err)
+		c.entries = make(map[string]cacheEntry)
+	}
+}
+
+// evictLocked drops expired entries and the oldest ones beyond MaxEntries
+func (c *ResponseCache) evictLocked() {
+	if c.TTL > 0 {
+		for key, entry := range c.entries {
+			if time.Since(entry.Created) > c.TTL {
+				delete(c.entries, key)
+			}
+		}
+	}
+
+	if c.MaxEntries <= 0 || len(c.entries) <= c.MaxEntries {
+		return
+	}
+
+	keys := make([]string, 0, len(c.entries))
+	for key := range c.entries {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return c.entries[keys[i]].Created.Before(c.entries[keys[j]].Created)
+	})
+
+	for _, key := range keys[:len(keys)-c.MaxEntries] {
+		delete(c.entries, key)
+	}
+}
+
+// saveLocked writes the cache atomically via a temporary file
+func (c *ResponseCache) saveLocked() error {
+	if err := os.MkdirAll(filepath.Dir(c.Path), 0o755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %v", err)
+	}
+
+	data, err := json.Marshal(c.entries)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache: %v", err)
+	}
+
+	tmpPath := c.Path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write cache: %v", err)
+	}
+	return os.Rename(tmpPath, c.Path)
+}
+
+// CachingClient serves repeated identical requests from a ResponseCache
+type CachingClient struct {
+	Client    Client
+	Cache     *ResponseCache
+	Namespace string // Identifies the provider/model so different models don't share entries
+}
+
+// NewCachingClient wraps a client with a response cache
+func NewCachingClient(client Client, cache *ResponseCache, namespace string) *CachingClient {
+	return &CachingClient{
+		Client:    client,
+		Cache:     cache,
+		Namespace: namespace,
+	}
+}
+
+// SendPrompt implements the Client interface
+func (c *CachingClient) SendPrompt(systemPrompt, userPrompt string) (string, error) {
+	key := CacheKey(c.Namespace, "text", systemPrompt, userPrompt)
+	return c.cached(key, func() (string, error) {
+		return c.Client.SendPrompt(systemPrompt, userPrompt)
+	})
+}
+
+// SendPromptWithSchema implements the StructuredClient interface when the wrapped client does
+func (c *CachingClient) SendPromptWithSchema(systemPrompt, userPrompt string, schema ResponseSchema) (string, error) {
+	structured, ok := c.Client.(StructuredClient)
+	if !ok {
+		return "", ErrStructuredOutputUnsupported
+	}
+
+	key := CacheKey(c.Namespace, "schema:"+schema.Name, systemPrompt, userPrompt)
+	return c.cached(key, func() (string, error) {
+		return structured.SendPromptWithSchema(systemPrompt, userPrompt, schema)
+	})
+}
+
+// cached returns the cached response for key or performs and caches the request
+func (c *CachingClient) cached(key string, request func() (string, error)) (string, error) {
+	if response, ok := c.Cache.Get(key); ok {
+		logger.Info("Using cached LLM response")
+		return response, nil
+	}
+
+	response, err := request()
+	if err != nil {
+		return "", err
+	}
+
+	if err := c.Cache.Put(key, response); err != nil {
+		logger.Warning("Failed to store LLM response in cache: %v", err)
+	}
+
+	return response, nil
+}
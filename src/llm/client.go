@@ -3,10 +3,12 @@ package llm
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"go-reaper/src/pkg/logger"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -38,7 +40,15 @@ type OpenAIClient struct {
 	Model      string
 	MaxTokens  int
 	Temp       float64
+	Endpoint   string // Chat completions URL, defaults to OpenAICompletionURL
 	HTTPClient *http.Client
+
+	// StructuredOutput enables response_format json_schema requests; disable it
+	// for compatible endpoints that reject the field
+	StructuredOutput bool
+
+	// OnUsage, if set, receives the token usage reported for each successful request
+	OnUsage UsageHandler
 }
 
 // NewOpenAIClient creates a new OpenAI client with default settings
@@ -46,60 +56,76 @@ func NewOpenAIClient(apiKey string) *OpenAIClient {
 	return &OpenAIClient{
 		APIKey:    apiKey,
 		Model:     DefaultModel,
+		Endpoint:  OpenAICompletionURL,
 		MaxTokens: DefaultMaxTokens,
 		Temp:      DefaultTemp,
 		HTTPClient: &http.Client{
 			Timeout: time.Duration(DefaultTimeoutSec) * time.Second,
 		},
+		StructuredOutput: true,
 	}
 }
 
 // SendPrompt implements the Client interface
 func (c *OpenAIClient) SendPrompt(systemPrompt, userPrompt string) (string, error) {
-	// Log the start of the API call
-	logger.Debug("Starting OpenAI API call...")
+	return c.send(systemPrompt, userPrompt, nil)
+}
 
-	// Set up the request body
-	type RequestBody struct {
-		Model       string    `json:"model"`
-		Messages    []Message `json:"messages"`
-		MaxTokens   int       `json:"max_tokens"`
-		Temperature float64   `json:"temperature"`
+// SendPromptWithSchema implements the StructuredClient interface
+func (c *OpenAIClient) SendPromptWithSchema(systemPrompt, userPrompt string, schema ResponseSchema) (string, error) {
+	if !c.StructuredOutput {
+		return "", ErrStructuredOutputUnsupported
 	}
 
-	reqBody := RequestBody{
-		Model: c.Model,
-		Messages: []Message{
-			{Role: "system", Content: systemPrompt},
-			{Role: "user", Content: userPrompt},
+	response, err := c.send(systemPrompt, userPrompt, schemaResponseFormat(schema))
+	if c.rejectedStructuredOutput(err) {
+		return "", ErrStructuredOutputUnsupported
+	}
+
+	return response, err
+}
+
+// schemaResponseFormat builds the response_format field for a JSON schema
+func schemaResponseFormat(schema ResponseSchema) interface{} {
+	return map[string]interface{}{
+		"type": "json_schema",
+		"json_schema": map[string]interface{}{
+			"name":   schema.Name,
+			"schema": schema.Schema,
+			"strict": true,
 		},
-		MaxTokens:   c.MaxTokens,
-		Temperature: c.Temp,
 	}
+}
 
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		return "", fmt.Errorf("error marshaling request body: %v", err)
+// rejectedStructuredOutput reports whether the endpoint rejected response_format, and if so
+// disables structured output for later requests
+func (c *OpenAIClient) rejectedStructuredOutput(err error) bool {
+	// Endpoints that don't understand response_format reject the request outright
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.Category == ErrorRequest && strings.Contains(strings.ToLower(apiErr.Message), "response_format") {
+		logger.Warning("Endpoint rejected structured output request: %s", apiErr.Message)
+		c.StructuredOutput = false
+		return true
 	}
+	return false
+}
 
-	logger.Debug("Request body prepared, creating HTTP request...")
+// send performs a chat completion request; responseFormat is omitted when nil
+func (c *OpenAIClient) send(systemPrompt, userPrompt string, responseFormat interface{}) (string, error) {
+	// Log the start of the API call
+	logger.Debug("Starting OpenAI API call...")
 
-	// Create the request
-	req, err := http.NewRequest("POST", OpenAICompletionURL, bytes.NewBuffer(jsonData))
+	req, endpoint, err := c.newChatRequest(systemPrompt, userPrompt, responseFormat, false)
 	if err != nil {
-		return "", fmt.Errorf("error creating request: %v", err)
+		return "", err
 	}
 
-	// Set headers
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.APIKey)
-
-	logger.Debug("Sending HTTP request to OpenAI API...")
+	logger.Debug("Sending HTTP request to %s...", endpoint)
 
 	// Send the request
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("error sending request: %v", err)
+		return "", newTransportError(err)
 	}
 	// Guard against nil response
 	if resp == nil {
@@ -112,13 +138,13 @@ func (c *OpenAIClient) SendPrompt(systemPrompt, userPrompt string) (string, erro
 	// Read the response
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("error reading response: %v", err)
+		return "", newTransportError(err)
 	}
 
 	// Check for HTTP errors
 	if resp.StatusCode != http.StatusOK {
 		logger.Error("API error response: %s", string(body))
-		return "", fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+		return "", newHTTPError(resp, body)
 	}
 
 	logger.Debug("Successfully read response body (%d bytes)", len(body))
@@ -133,30 +159,93 @@ func (c *OpenAIClient) SendPrompt(systemPrompt, userPrompt string) (string, erro
 		Error *struct {
 			Message string `json:"message"`
 		} `json:"error"`
+		Usage *Usage `json:"usage"`
 	}
 
 	if err := json.Unmarshal(body, &openAIResp); err != nil {
 		logger.Error("Error parsing response: %v", err)
 		logger.Error("Response content: %s", string(body))
-		return "", fmt.Errorf("error parsing API response: %v", err)
+		return "", &APIError{Category: ErrorResponse, StatusCode: resp.StatusCode, Message: fmt.Sprintf("error parsing API response: %v", err), Err: err}
 	}
 
 	// Check for API error
 	if openAIResp.Error != nil && openAIResp.Error.Message != "" {
-		return "", fmt.Errorf("API error: %s", openAIResp.Error.Message)
+		return "", &APIError{Category: ErrorRequest, StatusCode: resp.StatusCode, Message: openAIResp.Error.Message}
 	}
 
 	// Check for valid choices
 	if len(openAIResp.Choices) == 0 {
-		return "", fmt.Errorf("no response choices returned from API")
+		return "", &APIError{Category: ErrorResponse, StatusCode: resp.StatusCode, Message: "no response choices returned from API"}
 	}
 
 	// Check for nil or empty content
 	content := openAIResp.Choices[0].Message.Content
 	if content == "" {
-		return "", fmt.Errorf("empty content in API response")
+		return "", &APIError{Category: ErrorResponse, StatusCode: resp.StatusCode, Message: "empty content in API response"}
+	}
+
+	// Report token usage for cost tracking
+	if openAIResp.Usage != nil && c.OnUsage != nil {
+		c.OnUsage(c.Model, *openAIResp.Usage)
 	}
 
 	logger.Debug("Successfully parsed OpenAI response")
 	return content, nil
 }
+
+// newChatRequest builds a chat completion HTTP request, returning it with the endpoint used
+func (c *OpenAIClient) newChatRequest(systemPrompt, userPrompt string, responseFormat interface{}, stream bool) (*http.Request, string, error) {
+	// Set up the request body
+	type RequestBody struct {
+		Model          string      `json:"model"`
+		Messages       []Message   `json:"messages"`
+		MaxTokens      int         `json:"max_tokens"`
+		Temperature    float64     `json:"temperature"`
+		ResponseFormat interface{} `json:"response_format,omitempty"`
+		Stream         bool        `json:"stream,omitempty"`
+		StreamOptions  interface{} `json:"stream_options,omitempty"`
+	}
+
+	endpoint := c.Endpoint
+	if endpoint == "" {
+		endpoint = OpenAICompletionURL
+	}
+
+	reqBody := RequestBody{
+		Model: c.Model,
+		Messages: []Message{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: userPrompt},
+		},
+		MaxTokens:      c.MaxTokens,
+		Temperature:    c.Temp,
+		ResponseFormat: responseFormat,
+		Stream:         stream,
+	}
+
+	// Only OpenAI is known to accept stream_options; it adds token usage to the last chunk
+	if stream && endpoint == OpenAICompletionURL {
+		reqBody.StreamOptions = map[string]bool{"include_usage": true}
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, "", fmt.Errorf("error marshaling request body: %v", err)
+	}
+
+	logger.Debug("Request body prepared, creating HTTP request...")
+
+	// Create the request
+	req, err := http.NewRequest("POST", endpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, "", fmt.Errorf("error creating request: %v", err)
+	}
+
+	// Set headers (local servers such as LM Studio don't require a key)
+	req.Header.Set("Content-Type", "application/json")
+	if c.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	}
+
+	return req, endpoint, nil
+}
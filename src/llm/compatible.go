@@ -0,0 +1,57 @@
+package llm
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Base URLs for services exposing an OpenAI-compatible chat completions API
+const (
+	GeminiBaseURL   = "https://generativelanguage.googleapis.com/v1beta/openai"
+	LMStudioBaseURL = "http://localhost:1234/v1"
+	VLLMBaseURL     = "http://localhost:8000/v1"
+
+	DefaultGeminiModel = "gemini-2.0-flash"
+)
+
+// NewOpenAICompatibleClient creates a client for any endpoint implementing the OpenAI
+// chat completions API (Gemini's compatibility layer, LM Studio, vLLM, corporate proxies).
+// baseURL is the API root, e.g. "http://localhost:1234/v1"; "/chat/completions" is appended.
+// apiKey may be empty for local servers that don't require authentication.
+func NewOpenAICompatibleClient(baseURL, apiKey, model string) *OpenAIClient {
+	if model == "" {
+		model = DefaultModel
+	}
+
+	return &OpenAIClient{
+		APIKey:    apiKey,
+		Model:     model,
+		MaxTokens: DefaultMaxTokens,
+		Temp:      DefaultTemp,
+		Endpoint:  CompletionsEndpoint(baseURL),
+		HTTPClient: &http.Client{
+			Timeout: time.Duration(DefaultTimeoutSec) * time.Second,
+		},
+		// Most compatible servers accept json_schema; those that don't are detected on first use
+		StructuredOutput: true,
+	}
+}
+
+// NewGeminiClient creates a client for Google Gemini via its OpenAI compatibility layer
+func NewGeminiClient(apiKey, model string) *OpenAIClient {
+	if model == "" {
+		model = DefaultGeminiModel
+	}
+	return NewOpenAICompatibleClient(GeminiBaseURL, apiKey, model)
+}
+
+// CompletionsEndpoint builds the chat completions URL for an OpenAI-compatible base URL.
+// A base URL that already points at the completions route is returned unchanged.
+func CompletionsEndpoint(baseURL string) string {
+	base := strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	if strings.HasSuffix(base, "/chat/completions") {
+		return base
+	}
+	return base + "/chat/completions"
+}
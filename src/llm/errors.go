@@ -0,0 +1,193 @@
+package llm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrorCategory classifies LLM failures so callers can react and show friendly messages
+type ErrorCategory string
+
+// Error categories
+const (
+	ErrorAuth      ErrorCategory = "auth"       // Invalid or missing API key
+	ErrorQuota     ErrorCategory = "quota"      // Account out of credits or quota
+	ErrorRateLimit ErrorCategory = "rate_limit" // Too many requests, retry later
+	ErrorTimeout   ErrorCategory = "timeout"    // Request timed out
+	ErrorNetwork   ErrorCategory = "network"    // Connection failures
+	ErrorServer    ErrorCategory = "server"     // 5xx responses from the provider
+	ErrorRequest   ErrorCategory = "request"    // Invalid request (bad model name, too long prompt, ...)
+	ErrorResponse  ErrorCategory = "response"   // Unusable response content
+)
+
+// APIError describes a failed LLM request
+type APIError struct {
+	Category   ErrorCategory
+	StatusCode int           // HTTP status code, 0 if no response was received
+	Message    string        // Provider error message or transport error text
+	RetryAfter time.Duration // Delay requested by the provider, 0 if not specified
+	Err        error         // Underlying error, if any
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("API error (%s, status %d): %s", e.Category, e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("API error (%s): %s", e.Category, e.Message)
+}
+
+// Unwrap returns the underlying error
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// Retryable reports whether repeating the request may succeed
+func (e *APIError) Retryable() bool {
+	switch e.Category {
+	case ErrorRateLimit, ErrorTimeout, ErrorNetwork, ErrorServer:
+		return true
+	default:
+		return false
+	}
+}
+
+// UserMessage returns a short explanation suitable for a message box
+func (e *APIError) UserMessage() string {
+	switch e.Category {
+	case ErrorAuth:
+		return "The API key was rejected. Please check that it is correct and still active."
+	case ErrorQuota:
+		return "Your account has run out of credits or quota. Please check your plan and billing details with the provider."
+	case ErrorRateLimit:
+		return "The provider is rate limiting requests. Please wait a moment and try again."
+	case ErrorTimeout:
+		return "The request timed out. The provider may be busy; please try again."
+	case ErrorNetwork:
+		return "Could not reach the provider. Please check your internet connection and endpoint settings."
+	case ErrorServer:
+		return "The provider had an internal error. Please try again later."
+	case ErrorRequest:
+		return fmt.Sprintf("The provider rejected the request: %s", e.Message)
+	default:
+		return fmt.Sprintf("The provider returned an unexpected response: %s", e.Message)
+	}
+}
+
+// UserMessage returns a friendly message for any error returned by a Client
+func UserMessage(err error) string {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.UserMessage()
+	}
+	return err.Error()
+}
+
+// newHTTPError builds an APIError from a non-200 HTTP response
+func newHTTPError(resp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Message:    providerErrorMessage(body),
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+	}
+
+	code := providerErrorCode(body)
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		apiErr.Category = ErrorAuth
+	case resp.StatusCode == http.StatusTooManyRequests && code == "insufficient_quota":
+		apiErr.Category = ErrorQuota
+	case resp.StatusCode == http.StatusTooManyRequests:
+		apiErr.Category = ErrorRateLimit
+	case resp.StatusCode == http.StatusPaymentRequired:
+		apiErr.Category = ErrorQuota
+	case resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusGatewayTimeout:
+		apiErr.Category = ErrorTimeout
+	case resp.StatusCode >= 500:
+		apiErr.Category = ErrorServer
+	default:
+		apiErr.Category = ErrorRequest
+	}
+
+	return apiErr
+}
+
+// newTransportError builds an APIError from an error returned by the HTTP client
+func newTransportError(err error) *APIError {
+	apiErr := &APIError{
+		Category: ErrorNetwork,
+		Message:  err.Error(),
+		Err:      err,
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		apiErr.Category = ErrorTimeout
+	}
+
+	return apiErr
+}
+
+// providerErrorMessage extracts error.message from an OpenAI-style error body
+func providerErrorMessage(body []byte) string {
+	var parsed struct {
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &parsed); err == nil && parsed.Error != nil && parsed.Error.Message != "" {
+		return parsed.Error.Message
+	}
+
+	// Fall back to the raw body, trimmed so it stays readable in logs
+	text := strings.TrimSpace(string(body))
+	if len(text) > 300 {
+		text = text[:300] + "..."
+	}
+	return text
+}
+
+// providerErrorCode extracts error.code (or error.type) from an OpenAI-style error body
+func providerErrorCode(body []byte) string {
+	var parsed struct {
+		Error *struct {
+			Code interface{} `json:"code"`
+			Type string      `json:"type"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &parsed); err != nil || parsed.Error == nil {
+		return ""
+	}
+
+	if code, ok := parsed.Error.Code.(string); ok && code != "" {
+		return code
+	}
+	return parsed.Error.Type
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+		return time.Duration(seconds * float64(time.Second))
+	}
+
+	if when, err := http.ParseTime(value); err == nil {
+		if delay := time.Until(when); delay > 0 {
+			return delay
+		}
+	}
+
+	return 0
+}
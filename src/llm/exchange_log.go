@@ -0,0 +1,219 @@
+package llm
+
+import (
+	"bytes"
+	"fmt"
+	"go-reaper/src/pkg/logger"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultExchangeLogMaxBytes is the size at which the exchange log is rotated
+const DefaultExchangeLogMaxBytes = 5 * 1024 * 1024
+
+// Exchange is one HTTP request to a provider and its response, as sent and received
+type Exchange struct {
+	Time       time.Time
+	Method     string
+	URL        string
+	Headers    http.Header // Request headers
+	Request    string      // Request body
+	StatusCode int         // 0 if no response was received
+	Response   string      // Response body; for a stream, the raw events
+	Duration   time.Duration
+	Err        string // Transport error, if any
+}
+
+// Format renders the exchange for the log
+func (e Exchange) Format() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "=== %s %s %s\n", e.Time.Format(time.RFC3339), e.Method, e.URL)
+
+	names := make([]string, 0, len(e.Headers))
+	for name := range e.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s: %s\n", name, strings.Join(e.Headers[name], ", "))
+	}
+
+	fmt.Fprintf(&b, "\n--- Request\n%s\n", e.Request)
+	if e.Err != "" {
+		fmt.Fprintf(&b, "\n--- Failed after %v\n%s\n", e.Duration.Round(time.Millisecond), e.Err)
+	} else {
+		fmt.Fprintf(&b, "\n--- Response %d after %v\n%s\n", e.StatusCode, e.Duration.Round(time.Millisecond), e.Response)
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// ExchangeLog appends redacted exchanges to a file, and keeps the last one in memory
+type ExchangeLog struct {
+	Path     string // File exchanges are appended to
+	MaxBytes int64  // The file is moved to Path+".1" beyond this size
+
+	mutex sync.Mutex
+	last  *Exchange
+}
+
+// NewExchangeLog creates an exchange log written to path
+func NewExchangeLog(path string) *ExchangeLog {
+	return &ExchangeLog{Path: path, MaxBytes: DefaultExchangeLogMaxBytes}
+}
+
+// Record redacts an exchange, appends it to the file and remembers it as the last one
+func (l *ExchangeLog) Record(exchange Exchange, secrets ...string) {
+	exchange.Headers = redactHeaders(exchange.Headers, secrets)
+	exchange.URL = Redact(exchange.URL, secrets...)
+	exchange.Request = Redact(exchange.Request, secrets...)
+	exchange.Response = Redact(exchange.Response, secrets...)
+	exchange.Err = Redact(exchange.Err, secrets...)
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.last = &exchange
+	if err := l.appendLocked(exchange.Format()); err != nil {
+		logger.Warning("Failed to write LLM exchange log: %v", err)
+	}
+}
+
+// Last returns the most recent exchange recorded this session
+func (l *ExchangeLog) Last() (Exchange, bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.last == nil {
+		return Exchange{}, false
+	}
+	return *l.last, true
+}
+
+// appendLocked writes text to the end of the file, rotating it first if it is full;
+// the caller must hold the mutex
+func (l *ExchangeLog) appendLocked(text string) error {
+	if err := os.MkdirAll(filepath.Dir(l.Path), 0700); err != nil {
+		return err
+	}
+	if info, err := os.Stat(l.Path); err == nil && l.MaxBytes > 0 && info.Size()+int64(len(text)) > l.MaxBytes {
+		if err := os.Rename(l.Path, l.Path+".1"); err != nil {
+			return err
+		}
+	}
+
+	// Prompts can hold project details, so the file is private to the user
+	file, err := os.OpenFile(l.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.WriteString(text); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// redactHeaders copies headers with their values redacted
+func redactHeaders(headers http.Header, secrets []string) http.Header {
+	copied := make(http.Header, len(headers))
+	for name, values := range headers {
+		for _, value := range values {
+			if strings.EqualFold(name, "Authorization") || strings.EqualFold(name, "X-Api-Key") {
+				value = redacted
+			}
+			copied[name] = append(copied[name], Redact(value, secrets...))
+		}
+	}
+	return copied
+}
+
+// LogExchanges returns a copy of an HTTP client that records every exchange in log,
+// with secrets such as the API key redacted. Response bodies are recorded once read to
+// the end or closed, so streamed responses are logged as they were received.
+func LogExchanges(client *http.Client, log *ExchangeLog, secrets ...string) *http.Client {
+	logged := *client
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	logged.Transport = &loggingTransport{base: base, log: log, secrets: secrets}
+	return &logged
+}
+
+// loggingTransport records the exchanges made through its base transport
+type loggingTransport struct {
+	base    http.RoundTripper
+	log     *ExchangeLog
+	secrets []string
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	exchange := Exchange{
+		Time:    time.Now(),
+		Method:  req.Method,
+		URL:     req.URL.String(),
+		Headers: req.Header.Clone(),
+	}
+
+	if req.Body != nil && req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			data, _ := io.ReadAll(body)
+			body.Close()
+			exchange.Request = string(data)
+		}
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		exchange.Duration = time.Since(exchange.Time)
+		exchange.Err = err.Error()
+		t.log.Record(exchange, t.secrets...)
+		return nil, err
+	}
+
+	exchange.StatusCode = resp.StatusCode
+	resp.Body = &recordingBody{body: resp.Body, done: func(received []byte) {
+		exchange.Duration = time.Since(exchange.Time)
+		exchange.Response = string(received)
+		t.log.Record(exchange, t.secrets...)
+	}}
+	return resp, nil
+}
+
+// recordingBody keeps a copy of a response body and hands it over once, at its end or
+// when it is closed
+type recordingBody struct {
+	body     io.ReadCloser
+	received bytes.Buffer
+	done     func([]byte)
+	once     sync.Once
+}
+
+// Read implements io.Reader
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	b.received.Write(p[:n])
+	if err == io.EOF {
+		b.finish()
+	}
+	return n, err
+}
+
+// Close implements io.Closer
+func (b *recordingBody) Close() error {
+	b.finish()
+	return b.body.Close()
+}
+
+// finish hands over what was received
+func (b *recordingBody) finish() {
+	b.once.Do(func() { b.done(b.received.Bytes()) })
+}
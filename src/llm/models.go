@@ -0,0 +1,150 @@
+package llm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-reaper/src/pkg/logger"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// OllamaBaseURL is the API root of Ollama's OpenAI-compatible endpoint
+const OllamaBaseURL = "http://localhost:11434/v1"
+
+// nonChatModels mark model IDs on OpenAI's list that can't answer chat completions
+var nonChatModels = []string{"embedding", "whisper", "tts", "dall-e", "moderation", "davinci", "babbage", "transcribe", "image", "audio", "realtime", "search"}
+
+// ListModels returns the IDs of the models the endpoint offers, sorted. It asks the
+// OpenAI-style /models route first; servers without one, such as older Ollama versions,
+// are asked for Ollama's /api/tags instead.
+func (c *OpenAIClient) ListModels() ([]string, error) {
+	endpoint := c.Endpoint
+	if endpoint == "" {
+		endpoint = OpenAICompletionURL
+	}
+	base := strings.TrimSuffix(endpoint, "/chat/completions")
+
+	models, err := c.getModels(base+"/models", parseOpenAIModels)
+	if err != nil {
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || (apiErr.StatusCode != http.StatusNotFound && apiErr.Category != ErrorResponse) {
+			return nil, err
+		}
+
+		// Ollama serves its own list at the server root, outside /v1
+		root := strings.TrimSuffix(base, "/v1")
+		tags, tagsErr := c.getModels(root+"/api/tags", parseOllamaModels)
+		if tagsErr != nil {
+			logger.Debug("Ollama model list unavailable too: %v", tagsErr)
+			return nil, err
+		}
+		models = tags
+	}
+
+	// OpenAI's own list includes embedding, audio and image models
+	if endpoint == OpenAICompletionURL {
+		models = chatModels(models)
+	}
+	sort.Strings(models)
+	return models, nil
+}
+
+// getModels fetches a model list and parses it with parse
+func (c *OpenAIClient) getModels(url string, parse func([]byte) ([]string, error)) ([]string, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %v", err)
+	}
+	if c.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	}
+
+	logger.Debug("Listing models from %s", url)
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, newTransportError(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, newTransportError(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, newHTTPError(resp, body)
+	}
+
+	models, err := parse(body)
+	if err != nil {
+		return nil, &APIError{Category: ErrorResponse, StatusCode: resp.StatusCode, Message: fmt.Sprintf("error parsing model list: %v", err), Err: err}
+	}
+	return models, nil
+}
+
+// parseOpenAIModels reads an OpenAI-style model list. Gemini prefixes its IDs with
+// "models/", which its chat endpoint doesn't expect.
+func parseOpenAIModels(body []byte) ([]string, error) {
+	var list struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, err
+	}
+	if list.Data == nil {
+		return nil, fmt.Errorf("no data field")
+	}
+
+	models := make([]string, 0, len(list.Data))
+	for _, model := range list.Data {
+		if model.ID != "" {
+			models = append(models, strings.TrimPrefix(model.ID, "models/"))
+		}
+	}
+	return models, nil
+}
+
+// parseOllamaModels reads Ollama's /api/tags list
+func parseOllamaModels(body []byte) ([]string, error) {
+	var list struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, err
+	}
+	if list.Models == nil {
+		return nil, fmt.Errorf("no models field")
+	}
+
+	models := make([]string, 0, len(list.Models))
+	for _, model := range list.Models {
+		if model.Name != "" {
+			models = append(models, model.Name)
+		}
+	}
+	return models, nil
+}
+
+// chatModels drops the models that can't be used for chat completions
+func chatModels(models []string) []string {
+	var chat []string
+	for _, model := range models {
+		usable := true
+		for _, marker := range nonChatModels {
+			if strings.Contains(model, marker) {
+				usable = false
+				break
+			}
+		}
+		if usable {
+			chat = append(chat, model)
+		}
+	}
+	return chat
+}
@@ -0,0 +1,95 @@
+package llm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-reaper/src/pkg/logger"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// probeMaxTokens is the completion length of a connection test; enough for a short
+// reply on every provider, few enough to cost next to nothing
+const probeMaxTokens = 8
+
+// ConnectionReport describes a successful connection test
+type ConnectionReport struct {
+	Endpoint string
+	Model    string        // Model that answered, as reported by the provider
+	Latency  time.Duration // Time until the whole response arrived
+	Reply    string
+}
+
+// TestConnection sends the smallest request that exercises the endpoint, API key and
+// model, and reports how long the answer took. Failures are APIErrors; use
+// IsModelNotFound to tell a missing model apart from other rejected requests.
+func (c *OpenAIClient) TestConnection() (ConnectionReport, error) {
+	probe := *c
+	probe.MaxTokens = probeMaxTokens
+
+	req, endpoint, err := probe.newChatRequest("Reply with the single word OK.", "OK?", nil, false)
+	if err != nil {
+		return ConnectionReport{}, err
+	}
+	report := ConnectionReport{Endpoint: endpoint}
+
+	logger.Debug("Testing connection to %s with model %s", endpoint, c.Model)
+	start := time.Now()
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return report, newTransportError(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	report.Latency = time.Since(start)
+	if err != nil {
+		return report, newTransportError(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return report, newHTTPError(resp, body)
+	}
+
+	// Only the envelope matters: a reasoning model may spend the few tokens before
+	// replying, which still shows the model is there
+	var parsed struct {
+		Model   string `json:"model"`
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+		Usage *Usage `json:"usage"`
+	}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return report, &APIError{Category: ErrorResponse, StatusCode: resp.StatusCode, Message: fmt.Sprintf("error parsing API response: %v", err), Err: err}
+	}
+
+	report.Model = parsed.Model
+	if report.Model == "" {
+		report.Model = c.Model
+	}
+	if len(parsed.Choices) > 0 {
+		report.Reply = strings.TrimSpace(parsed.Choices[0].Message.Content)
+	}
+	if parsed.Usage != nil && c.OnUsage != nil {
+		c.OnUsage(c.Model, *parsed.Usage)
+	}
+	return report, nil
+}
+
+// IsModelNotFound reports whether a request failed because the provider doesn't offer
+// the requested model, or the key has no access to it
+func IsModelNotFound(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Category != ErrorRequest {
+		return false
+	}
+	message := strings.ToLower(apiErr.Message)
+	return apiErr.StatusCode == http.StatusNotFound ||
+		strings.Contains(message, "model_not_found") ||
+		(strings.Contains(message, "model") && (strings.Contains(message, "not found") || strings.Contains(message, "does not exist")))
+}
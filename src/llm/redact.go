@@ -0,0 +1,39 @@
+package llm
+
+import (
+	"regexp"
+	"strings"
+)
+
+// redacted replaces secrets in logged text
+const redacted = "[REDACTED]"
+
+// secretPatterns match credentials that may appear in prompts, headers or responses
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9._~+/=-]{16,}`),         // Authorization headers
+	regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{16,}`),                        // OpenAI and Anthropic keys
+	regexp.MustCompile(`\bAIza[A-Za-z0-9_-]{30,}`),                       // Google API keys
+	regexp.MustCompile(`\b(?:ghp|gho|ghs|github_pat)_[A-Za-z0-9_]{20,}`), // GitHub tokens
+	regexp.MustCompile(`\bAKIA[A-Z0-9]{16}\b`),                           // AWS access key IDs
+	regexp.MustCompile(`(?i)("?(?:api[_-]?key|access[_-]?token|secret|password)"?\s*[:=]\s*"?)[^\s",}]+`),
+}
+
+// Redact replaces the given secrets, and anything that looks like a credential, with
+// [REDACTED]
+func Redact(text string, secrets ...string) string {
+	for _, secret := range secrets {
+		if len(secret) >= 8 {
+			text = strings.ReplaceAll(text, secret, redacted)
+		}
+	}
+
+	for _, pattern := range secretPatterns {
+		if pattern.NumSubexp() > 0 {
+			// Keep the field name, e.g. "api_key": "[REDACTED]"
+			text = pattern.ReplaceAllString(text, "${1}"+redacted)
+		} else {
+			text = pattern.ReplaceAllString(text, redacted)
+		}
+	}
+	return text
+}
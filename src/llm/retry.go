@@ -0,0 +1,106 @@
+package llm
+
+import (
+	"errors"
+	"go-reaper/src/pkg/logger"
+	"math/rand"
+	"time"
+)
+
+// Default retry settings
+const (
+	DefaultMaxRetries     = 3
+	DefaultInitialBackoff = 1 * time.Second
+	DefaultMaxBackoff     = 20 * time.Second
+)
+
+// RetryClient wraps a Client and retries transient failures with exponential backoff
+type RetryClient struct {
+	Client         Client
+	MaxRetries     int           // Number of retries after the first attempt
+	InitialBackoff time.Duration // Delay before the first retry
+	MaxBackoff     time.Duration // Upper bound for any single delay, including Retry-After
+}
+
+// NewRetryClient wraps a client with the default retry settings
+func NewRetryClient(client Client) *RetryClient {
+	return &RetryClient{
+		Client:         client,
+		MaxRetries:     DefaultMaxRetries,
+		InitialBackoff: DefaultInitialBackoff,
+		MaxBackoff:     DefaultMaxBackoff,
+	}
+}
+
+// SendPrompt implements the Client interface
+func (c *RetryClient) SendPrompt(systemPrompt, userPrompt string) (string, error) {
+	return c.retry(func() (string, error) {
+		return c.Client.SendPrompt(systemPrompt, userPrompt)
+	})
+}
+
+// SendPromptWithSchema implements the StructuredClient interface when the wrapped client does
+func (c *RetryClient) SendPromptWithSchema(systemPrompt, userPrompt string, schema ResponseSchema) (string, error) {
+	structured, ok := c.Client.(StructuredClient)
+	if !ok {
+		return "", ErrStructuredOutputUnsupported
+	}
+
+	return c.retry(func() (string, error) {
+		return structured.SendPromptWithSchema(systemPrompt, userPrompt, schema)
+	})
+}
+
+// retry runs a request, repeating it on transient failures
+func (c *RetryClient) retry(request func() (string, error)) (string, error) {
+	return c.retryWhile(nil, request)
+}
+
+// retryWhile is like retry, but stops retrying once canRetry (if set) returns false
+func (c *RetryClient) retryWhile(canRetry func() bool, request func() (string, error)) (string, error) {
+	var lastErr error
+
+	for attempt := 0; attempt <= c.MaxRetries; attempt++ {
+		response, err := request()
+		if err == nil {
+			return response, nil
+		}
+		lastErr = err
+
+		// Only transient errors are worth repeating
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || !apiErr.Retryable() {
+			return "", err
+		}
+
+		if attempt == c.MaxRetries || (canRetry != nil && !canRetry()) {
+			break
+		}
+
+		delay := c.backoff(attempt, apiErr.RetryAfter)
+		logger.Warning("LLM request failed (%s), retrying in %v (attempt %d of %d)",
+			apiErr.Category, delay, attempt+1, c.MaxRetries)
+		time.Sleep(delay)
+	}
+
+	return "", lastErr
+}
+
+// backoff returns the delay before the next attempt, honoring the provider's Retry-After
+func (c *RetryClient) backoff(attempt int, retryAfter time.Duration) time.Duration {
+	delay := c.InitialBackoff << uint(attempt)
+
+	// Add up to 25% jitter so parallel clients don't retry in lockstep
+	if delay > 0 {
+		delay += time.Duration(rand.Int63n(int64(delay)/4 + 1))
+	}
+
+	if retryAfter > delay {
+		delay = retryAfter
+	}
+	if c.MaxBackoff > 0 && delay > c.MaxBackoff {
+		delay = c.MaxBackoff
+	}
+
+	return delay
+}
@@ -0,0 +1,132 @@
+package llm
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// ResponseSchema describes the JSON document a structured-output request must return
+type ResponseSchema struct {
+	Name   string                 // Identifier sent to the provider, e.g. "fx_suggestions"
+	Schema map[string]interface{} // JSON schema of the response
+}
+
+// StructuredClient is implemented by clients that can constrain responses to a JSON schema
+type StructuredClient interface {
+	Client
+	// SendPromptWithSchema sends the prompts and asks the provider to return JSON
+	// matching the schema. Returns ErrStructuredOutputUnsupported if the endpoint
+	// doesn't support structured output, so callers can fall back to SendPrompt.
+	SendPromptWithSchema(systemPrompt, userPrompt string, schema ResponseSchema) (string, error)
+}
+
+// ErrStructuredOutputUnsupported is returned when a provider can't honor a response schema
+var ErrStructuredOutputUnsupported = errors.New("structured output not supported by provider")
+
+// ErrStreamingUnsupported is returned when a wrapped client can't stream responses
+var ErrStreamingUnsupported = errors.New("streaming not supported by provider")
+
+// SchemaFromType generates a strict JSON schema from a Go struct using its json tags.
+// Fields tagged omitempty are made nullable, since strict mode requires every property.
+func SchemaFromType(name string, value interface{}) ResponseSchema {
+	return ResponseSchema{
+		Name:   name,
+		Schema: schemaForType(reflect.TypeOf(value)),
+	}
+}
+
+// schemaForType builds the schema for a single Go type
+func schemaForType(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		properties := map[string]interface{}{}
+		required := []string{}
+
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			name, omitEmpty, skip := parseJSONTag(field)
+			if skip {
+				continue
+			}
+
+			fieldSchema := schemaForType(field.Type)
+			if omitEmpty {
+				fieldSchema = nullable(fieldSchema)
+			}
+			if desc := field.Tag.Get("desc"); desc != "" {
+				fieldSchema["description"] = desc
+			}
+
+			properties[name] = fieldSchema
+			required = append(required, name)
+		}
+
+		return map[string]interface{}{
+			"type":                 "object",
+			"properties":           properties,
+			"required":             required,
+			"additionalProperties": false,
+		}
+
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{
+			"type":  "array",
+			"items": schemaForType(t.Elem()),
+		}
+
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// parseJSONTag returns the JSON property name for a field and whether it is optional
+func parseJSONTag(field reflect.StructField) (name string, omitEmpty bool, skip bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+
+	parts := strings.Split(tag, ",")
+	name = parts[0]
+	if name == "" {
+		name = field.Name
+	}
+
+	for _, option := range parts[1:] {
+		if option == "omitempty" {
+			omitEmpty = true
+		}
+	}
+
+	return name, omitEmpty, false
+}
+
+// nullable allows null in addition to the schema's type
+func nullable(schema map[string]interface{}) map[string]interface{} {
+	if typeName, ok := schema["type"].(string); ok {
+		schema["type"] = []string{typeName, "null"}
+	}
+	return schema
+}
@@ -0,0 +1,194 @@
+package llm
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"go-reaper/src/pkg/logger"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// StreamHandler receives response text as it arrives
+type StreamHandler func(delta string)
+
+// StreamingClient is implemented by clients that can deliver a response incrementally
+type StreamingClient interface {
+	// SendPromptStream sends the prompts and calls onDelta with each piece of the response
+	// as it arrives, returning the complete response. A nil schema requests plain text;
+	// otherwise it returns ErrStructuredOutputUnsupported if the provider can't honor it.
+	SendPromptStream(systemPrompt, userPrompt string, schema *ResponseSchema, onDelta StreamHandler) (string, error)
+}
+
+// SendPromptStream implements the StreamingClient interface using server-sent events
+func (c *OpenAIClient) SendPromptStream(systemPrompt, userPrompt string, schema *ResponseSchema, onDelta StreamHandler) (string, error) {
+	var responseFormat interface{}
+	if schema != nil {
+		if !c.StructuredOutput {
+			return "", ErrStructuredOutputUnsupported
+		}
+		responseFormat = schemaResponseFormat(*schema)
+	}
+
+	logger.Debug("Starting streaming OpenAI API call...")
+
+	req, endpoint, err := c.newChatRequest(systemPrompt, userPrompt, responseFormat, true)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	logger.Debug("Sending streaming HTTP request to %s...", endpoint)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return "", newTransportError(err)
+	}
+	if resp == nil {
+		return "", fmt.Errorf("nil response received from HTTP client")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		logger.Error("API error response: %s", string(body))
+		apiErr := newHTTPError(resp, body)
+		if schema != nil && c.rejectedStructuredOutput(apiErr) {
+			return "", ErrStructuredOutputUnsupported
+		}
+		return "", apiErr
+	}
+
+	content, usage, err := readEventStream(resp.Body, onDelta)
+	if err != nil {
+		return "", &APIError{Category: ErrorResponse, StatusCode: resp.StatusCode, Message: err.Error(), Err: err}
+	}
+	if content == "" {
+		return "", &APIError{Category: ErrorResponse, StatusCode: resp.StatusCode, Message: "empty content in API response"}
+	}
+
+	// Report token usage for cost tracking
+	if usage != nil && c.OnUsage != nil {
+		c.OnUsage(c.Model, *usage)
+	}
+
+	logger.Debug("Streaming response complete (%d chars)", len(content))
+	return content, nil
+}
+
+// readEventStream reads chat completion chunks ("data: {...}" lines ending with
+// "data: [DONE]"), passing content deltas to onDelta and returning the full content
+func readEventStream(body io.Reader, onDelta StreamHandler) (string, *Usage, error) {
+	var content strings.Builder
+	var usage *Usage
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			continue // Blank separators, comments and event names
+		}
+
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			break
+		}
+
+		var chunk struct {
+			Choices []struct {
+				Delta struct {
+					Content string `json:"content"`
+				} `json:"delta"`
+			} `json:"choices"`
+			Error *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+			Usage *Usage `json:"usage"`
+		}
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			logger.Warning("Skipping malformed stream chunk: %s", data)
+			continue
+		}
+
+		if chunk.Error != nil && chunk.Error.Message != "" {
+			return "", nil, fmt.Errorf("stream error: %s", chunk.Error.Message)
+		}
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		}
+
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content == "" {
+				continue
+			}
+			content.WriteString(choice.Delta.Content)
+			if onDelta != nil {
+				onDelta(choice.Delta.Content)
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", nil, fmt.Errorf("error reading stream: %v", err)
+	}
+
+	return content.String(), usage, nil
+}
+
+// SendPromptStream implements the StreamingClient interface when the wrapped client does.
+// Requests are only retried if nothing has been streamed yet.
+func (c *RetryClient) SendPromptStream(systemPrompt, userPrompt string, schema *ResponseSchema, onDelta StreamHandler) (string, error) {
+	streaming, ok := c.Client.(StreamingClient)
+	if !ok {
+		return "", ErrStreamingUnsupported
+	}
+
+	streamed := false
+	tracked := func(delta string) {
+		streamed = true
+		if onDelta != nil {
+			onDelta(delta)
+		}
+	}
+
+	return c.retryWhile(func() bool { return !streamed }, func() (string, error) {
+		return streaming.SendPromptStream(systemPrompt, userPrompt, schema, tracked)
+	})
+}
+
+// SendPromptStream implements the StreamingClient interface when the wrapped client does.
+// Cached responses are delivered as a single delta.
+func (c *CachingClient) SendPromptStream(systemPrompt, userPrompt string, schema *ResponseSchema, onDelta StreamHandler) (string, error) {
+	streaming, ok := c.Client.(StreamingClient)
+	if !ok {
+		return "", ErrStreamingUnsupported
+	}
+
+	kind := "text"
+	if schema != nil {
+		kind = "schema:" + schema.Name
+	}
+	key := CacheKey(c.Namespace, kind, systemPrompt, userPrompt)
+
+	if response, ok := c.Cache.Get(key); ok {
+		logger.Info("Using cached LLM response")
+		if onDelta != nil {
+			onDelta(response)
+		}
+		return response, nil
+	}
+
+	response, err := streaming.SendPromptStream(systemPrompt, userPrompt, schema, onDelta)
+	if err != nil {
+		return "", err
+	}
+
+	if err := c.Cache.Put(key, response); err != nil {
+		logger.Warning("Failed to store LLM response in cache: %v", err)
+	}
+
+	return response, nil
+}
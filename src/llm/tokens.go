@@ -0,0 +1,96 @@
+package llm
+
+import (
+	"strings"
+	"unicode"
+)
+
+// DefaultContextWindow is used for models we don't know the context size of
+const DefaultContextWindow = 8192
+
+// contextWindows maps model name prefixes to their context window in tokens.
+// Longer prefixes are checked first, so "gpt-4o" wins over "gpt-4".
+var contextWindows = []struct {
+	prefix string
+	tokens int
+}{
+	{"gpt-4o", 128000},
+	{"gpt-4.1", 1047576},
+	{"gpt-4-turbo", 128000},
+	{"gpt-4-32k", 32768},
+	{"gpt-4", 8192},
+	{"gpt-3.5-turbo", 16385},
+	{"o1", 128000},
+	{"o3", 200000},
+	{"o4", 200000},
+	{"gemini-1.5", 1000000},
+	{"gemini-2", 1000000},
+	{"claude", 200000},
+	{"llama-3", 8192},
+	{"mistral", 32768},
+}
+
+// EstimateTokens roughly estimates the number of tokens in a text.
+// It takes the larger of a character-based (~4 chars per token) and a word-based
+// (~0.75 words per token) estimate, which errs on the side of overestimating for
+// the number-heavy parameter listings we send.
+func EstimateTokens(text string) int {
+	if text == "" {
+		return 0
+	}
+
+	byChars := (len(text) + 3) / 4
+
+	words := 0
+	inWord := false
+	for _, r := range text {
+		if unicode.IsSpace(r) {
+			inWord = false
+			continue
+		}
+		// Punctuation usually becomes its own token
+		if unicode.IsPunct(r) {
+			words++
+			inWord = false
+			continue
+		}
+		if !inWord {
+			words++
+			inWord = true
+		}
+	}
+	byWords := words * 4 / 3
+
+	if byWords > byChars {
+		return byWords
+	}
+	return byChars
+}
+
+// ContextWindow returns the context window size in tokens for a model
+func ContextWindow(model string) int {
+	name := strings.ToLower(model)
+	// Strip vendor prefixes such as "openai/" or "models/"
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+
+	best, bestLen := DefaultContextWindow, 0
+	for _, entry := range contextWindows {
+		if strings.HasPrefix(name, entry.prefix) && len(entry.prefix) > bestLen {
+			best, bestLen = entry.tokens, len(entry.prefix)
+		}
+	}
+	return best
+}
+
+// PromptBudget returns how many tokens are available for the user prompt, after
+// reserving room for the system prompt and the completion. It is 0 when those alone
+// fill the context window, which callers should report rather than treat as no limit
+func PromptBudget(model string, systemPrompt string, maxCompletionTokens int) int {
+	budget := ContextWindow(model) - EstimateTokens(systemPrompt) - maxCompletionTokens
+	if budget < 0 {
+		return 0
+	}
+	return budget
+}
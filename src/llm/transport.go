@@ -0,0 +1,116 @@
+package llm
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// HTTPOptions configure the HTTP client used to reach a provider
+type HTTPOptions struct {
+	ProxyURL   string        // Proxy for all requests; empty uses HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+	Timeout    time.Duration // Whole-request timeout; 0 for DefaultTimeoutSec
+	CACertFile string        // PEM file of certificates to trust besides the system's, e.g. a proxy's root
+}
+
+// NewHTTPClient builds an HTTP client with a proxy, timeout and extra trusted
+// certificates. Assign it to a client's HTTPClient field.
+func NewHTTPClient(options HTTPOptions) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if options.ProxyURL != "" {
+		proxy, err := url.Parse(options.ProxyURL)
+		if err != nil || proxy.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q", options.ProxyURL)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	if options.CACertFile != "" {
+		pool, err := certPool(options.CACertFile)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+
+	timeout := options.Timeout
+	if timeout <= 0 {
+		timeout = time.Duration(DefaultTimeoutSec) * time.Second
+	}
+	return &http.Client{Transport: transport, Timeout: timeout}, nil
+}
+
+// certPool returns the system's trusted certificates with those in a PEM file added
+func certPool(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading CA certificates: %w", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no PEM certificates found in %s", path)
+	}
+	return pool, nil
+}
+
+// CancelWith returns a copy of an HTTP client whose requests, including reading their
+// responses, are cancelled when ctx is done, e.g. so they don't outlive the plugin
+func CancelWith(client *http.Client, ctx context.Context) *http.Client {
+	cancelled := *client
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	cancelled.Transport = &cancelTransport{base: base, ctx: ctx}
+	return &cancelled
+}
+
+// cancelTransport cancels the requests made through its base transport when ctx is done
+type cancelTransport struct {
+	base http.RoundTripper
+	ctx  context.Context
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *cancelTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqCtx, cancel := context.WithCancel(req.Context())
+	stop := context.AfterFunc(t.ctx, cancel)
+
+	resp, err := t.base.RoundTrip(req.WithContext(reqCtx))
+	if err != nil {
+		stop()
+		cancel()
+		return nil, err
+	}
+
+	// The request stays cancellable until its body is closed
+	resp.Body = &cancelBody{ReadCloser: resp.Body, release: func() {
+		stop()
+		cancel()
+	}}
+	return resp, nil
+}
+
+// cancelBody releases its request's cancellation once closed
+type cancelBody struct {
+	io.ReadCloser
+	release func()
+}
+
+// Close implements io.Closer
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
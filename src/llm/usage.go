@@ -0,0 +1,61 @@
+package llm
+
+import "strings"
+
+// Usage holds the token counts reported by the provider for a single request
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// UsageHandler is called after every successful API request with the reported usage
+type UsageHandler func(model string, usage Usage)
+
+// modelPrice holds USD prices per million tokens
+type modelPrice struct {
+	prefix string
+	input  float64
+	output float64
+}
+
+// modelPrices lists published prices for common models; longer prefixes win.
+// Prices change over time, so costs are estimates only.
+var modelPrices = []modelPrice{
+	{"gpt-4o-mini", 0.15, 0.60},
+	{"gpt-4o", 2.50, 10.00},
+	{"gpt-4.1-nano", 0.10, 0.40},
+	{"gpt-4.1-mini", 0.40, 1.60},
+	{"gpt-4.1", 2.00, 8.00},
+	{"gpt-4-turbo", 10.00, 30.00},
+	{"gpt-4", 30.00, 60.00},
+	{"gpt-3.5-turbo", 0.50, 1.50},
+	{"o4-mini", 1.10, 4.40},
+	{"o3-mini", 1.10, 4.40},
+	{"gemini-2.0-flash-lite", 0.075, 0.30},
+	{"gemini-2.0-flash", 0.10, 0.40},
+	{"gemini-1.5-flash", 0.075, 0.30},
+	{"gemini-1.5-pro", 1.25, 5.00},
+}
+
+// EstimateCost returns the estimated cost in USD of a request, or 0 for unknown
+// (e.g. locally hosted) models
+func EstimateCost(model string, usage Usage) float64 {
+	name := strings.ToLower(model)
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+
+	var best *modelPrice
+	for i := range modelPrices {
+		price := &modelPrices[i]
+		if strings.HasPrefix(name, price.prefix) && (best == nil || len(price.prefix) > len(best.prefix)) {
+			best = price
+		}
+	}
+	if best == nil {
+		return 0
+	}
+
+	return (float64(usage.PromptTokens)*best.input + float64(usage.CompletionTokens)*best.output) / 1000000.0
+}
@@ -0,0 +1,210 @@
+// Package analysis measures audio read from REAPER: loudness as ITU-R BS.1770 defines it,
+// with peak and RMS levels, for whole tracks or sections of them.
+package analysis
+
+import (
+	"math"
+)
+
+// Silence is the level reported for audio with no signal, in dB
+var Silence = math.Inf(-1)
+
+// Gating from BS.1770: 400 ms blocks every 100 ms, an absolute gate at -70 LUFS and a
+// relative gate 10 LU below the loudness of the blocks that pass it
+const (
+	blockSteps     = 4 // 100 ms steps per 400 ms block
+	absoluteGate   = -70.0
+	relativeGate   = -10.0
+	loudnessOffset = -0.691
+	stepsPerSecond = 10
+	silenceFloor   = -120.0 // Peaks and RMS below this count as silence, dBFS
+	maxChannels    = 2      // Channels beyond the first two are not measured
+)
+
+// Levels are the measurements of a stretch of audio. Silent audio measures Silence.
+type Levels struct {
+	Integrated float64 // Gated loudness, LUFS
+	Peak       float64 // Highest sample, dBFS
+	RMS        float64 // Unweighted RMS over all channels, dBFS
+	Duration   float64 // Seconds measured
+}
+
+// Silent reports whether the audio had no measurable loudness
+func (l Levels) Silent() bool {
+	return math.IsInf(l.Integrated, -1)
+}
+
+// biquad is a second order filter in direct form I
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+	x1, x2, y1, y2     float64
+}
+
+// process filters one sample
+func (f *biquad) process(x float64) float64 {
+	y := f.b0*x + f.b1*f.x1 + f.b2*f.x2 - f.a1*f.y1 - f.a2*f.y2
+	f.x2, f.x1 = f.x1, x
+	f.y2, f.y1 = f.y1, y
+	return y
+}
+
+// kWeighting returns the two filter stages of BS.1770's K-weighting for a sample rate:
+// a high shelf modelling the head, then a high pass. The coefficients are derived for
+// any rate, as libebur128 does, rather than only the 48 kHz values in the standard.
+func kWeighting(sampleRate float64) [2]biquad {
+	// High shelf, +4 dB above about 1.7 kHz
+	f0, gain, q := 1681.974450955533, 3.999843853973347, 0.7071752369554196
+	k := math.Tan(math.Pi * f0 / sampleRate)
+	vh := math.Pow(10, gain/20)
+	vb := math.Pow(vh, 0.4996667741545416)
+	a0 := 1 + k/q + k*k
+	shelf := biquad{
+		b0: (vh + vb*k/q + k*k) / a0,
+		b1: 2 * (k*k - vh) / a0,
+		b2: (vh - vb*k/q + k*k) / a0,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/q + k*k) / a0,
+	}
+
+	// High pass at about 38 Hz
+	f0, q = 38.13547087602444, 0.5003270373238773
+	k = math.Tan(math.Pi * f0 / sampleRate)
+	a0 = 1 + k/q + k*k
+	highPass := biquad{
+		b0: 1,
+		b1: -2,
+		b2: 1,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/q + k*k) / a0,
+	}
+
+	return [2]biquad{shelf, highPass}
+}
+
+// Meter measures interleaved audio written to it in blocks of any size
+type Meter struct {
+	sampleRate int
+	channels   int
+	filters    [][2]biquad
+
+	stepFrames int       // Frames in a 100 ms step
+	stepFrame  int       // Frames so far in the current step
+	stepPower  float64   // Sum of weighted squares in the current step, over channels
+	steps      []float64 // Mean weighted power of each finished step
+
+	frames  int
+	peak    float64
+	squares float64
+}
+
+// NewMeter returns a meter for audio at sampleRate with channels samples per frame. Only
+// the first two channels are measured, each weighted 1 as for stereo.
+func NewMeter(sampleRate, channels int) *Meter {
+	measured := min(channels, maxChannels)
+	filters := make([][2]biquad, measured)
+	for i := range filters {
+		filters[i] = kWeighting(float64(sampleRate))
+	}
+	return &Meter{
+		sampleRate: sampleRate,
+		channels:   channels,
+		filters:    filters,
+		stepFrames: max(sampleRate/stepsPerSecond, 1),
+	}
+}
+
+// Write adds interleaved samples to the measurement
+func (m *Meter) Write(samples []float64) {
+	for frame := 0; frame+m.channels <= len(samples); frame += m.channels {
+		for ch := range m.filters {
+			x := samples[frame+ch]
+			if a := math.Abs(x); a > m.peak {
+				m.peak = a
+			}
+			m.squares += x * x
+
+			y := m.filters[ch][0].process(x)
+			y = m.filters[ch][1].process(y)
+			m.stepPower += y * y
+		}
+		m.frames++
+
+		m.stepFrame++
+		if m.stepFrame == m.stepFrames {
+			m.steps = append(m.steps, m.stepPower/float64(m.stepFrames))
+			m.stepFrame, m.stepPower = 0, 0
+		}
+	}
+}
+
+// Levels returns the measurements of everything written so far. Audio shorter than one
+// 400 ms block has no integrated loudness.
+func (m *Meter) Levels() Levels {
+	levels := Levels{
+		Integrated: Silence,
+		Peak:       toDB(m.peak),
+		RMS:        Silence,
+		Duration:   float64(m.frames) / float64(m.sampleRate),
+	}
+	if m.frames > 0 && len(m.filters) > 0 {
+		levels.RMS = toDB(math.Sqrt(m.squares / float64(m.frames*len(m.filters))))
+	}
+
+	// Each block's power is the mean over its four steps
+	var blocks []float64
+	for i := 0; i+blockSteps <= len(m.steps); i++ {
+		power := 0.0
+		for _, step := range m.steps[i : i+blockSteps] {
+			power += step
+		}
+		blocks = append(blocks, power/blockSteps)
+	}
+
+	mean := gatedMean(blocks, absoluteGate)
+	if mean == 0 {
+		return levels
+	}
+	// The relative gate is applied on top of the absolute one, so blocks below -70 LUFS
+	// stay out even when the relative gate is lower
+	mean = gatedMean(blocks, max(absoluteGate, powerLoudness(mean)+relativeGate))
+	if mean > 0 {
+		levels.Integrated = powerLoudness(mean)
+	}
+	return levels
+}
+
+// gatedMean averages the powers of the blocks louder than gate LUFS, or returns 0 if
+// none are
+func gatedMean(blocks []float64, gate float64) float64 {
+	sum, count := 0.0, 0
+	for _, power := range blocks {
+		if powerLoudness(power) > gate {
+			sum += power
+			count++
+		}
+	}
+	if count == 0 {
+		return 0
+	}
+	return sum / float64(count)
+}
+
+// powerLoudness converts a mean weighted power to LUFS
+func powerLoudness(power float64) float64 {
+	if power <= 0 {
+		return Silence
+	}
+	return loudnessOffset + 10*math.Log10(power)
+}
+
+// toDB converts a linear amplitude to dBFS, treating very low levels as silence
+func toDB(amplitude float64) float64 {
+	if amplitude <= 0 {
+		return Silence
+	}
+	db := 20 * math.Log10(amplitude)
+	if db < silenceFloor {
+		return Silence
+	}
+	return db
+}
@@ -0,0 +1,112 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+)
+
+const testSampleRate = 48000
+
+// sine returns seconds of interleaved audio with a 997 Hz sine of amplitude in every
+// channel
+func sine(amplitude, seconds float64, channels int) []float64 {
+	frames := int(seconds * testSampleRate)
+	samples := make([]float64, 0, frames*channels)
+	for i := 0; i < frames; i++ {
+		x := amplitude * math.Sin(2*math.Pi*997*float64(i)/testSampleRate)
+		for ch := 0; ch < channels; ch++ {
+			samples = append(samples, x)
+		}
+	}
+	return samples
+}
+
+// dbAmplitude converts dBFS to a linear amplitude
+func dbAmplitude(db float64) float64 {
+	return math.Pow(10, db/20)
+}
+
+func TestMeterSine(t *testing.T) {
+	tests := []struct {
+		name       string
+		level      float64 // Sine amplitude, dBFS
+		channels   int
+		integrated float64 // Expected loudness, LUFS
+	}{
+		// A full scale 997 Hz sine in both channels measures 0 LUFS, and in one channel -3.01
+		{"stereo full scale", 0, 2, 0},
+		{"stereo -20 dBFS", -20, 2, -20},
+		{"mono -20 dBFS", -20, 1, -23.01},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			meter := NewMeter(testSampleRate, test.channels)
+			meter.Write(sine(dbAmplitude(test.level), 5, test.channels))
+			levels := meter.Levels()
+
+			if math.Abs(levels.Integrated-test.integrated) > 0.1 {
+				t.Errorf("integrated = %.2f LUFS, want %.2f", levels.Integrated, test.integrated)
+			}
+			if math.Abs(levels.Peak-test.level) > 0.01 {
+				t.Errorf("peak = %.2f dBFS, want %.2f", levels.Peak, test.level)
+			}
+			if rms := test.level - 3.0103; math.Abs(levels.RMS-rms) > 0.01 {
+				t.Errorf("RMS = %.2f dBFS, want %.2f", levels.RMS, rms)
+			}
+			if math.Abs(levels.Duration-5) > 1e-9 {
+				t.Errorf("duration = %.3f s, want 5", levels.Duration)
+			}
+		})
+	}
+}
+
+func TestMeterSilence(t *testing.T) {
+	meter := NewMeter(testSampleRate, 2)
+	meter.Write(make([]float64, 2*testSampleRate*3))
+	levels := meter.Levels()
+
+	if !levels.Silent() {
+		t.Errorf("integrated = %.2f LUFS, want silence", levels.Integrated)
+	}
+	if !math.IsInf(levels.Peak, -1) || !math.IsInf(levels.RMS, -1) {
+		t.Errorf("peak %.2f, RMS %.2f, want silence", levels.Peak, levels.RMS)
+	}
+}
+
+func TestMeterAbsoluteGate(t *testing.T) {
+	// Audio quieter than -70 LUFS throughout has no integrated loudness
+	meter := NewMeter(testSampleRate, 2)
+	meter.Write(sine(dbAmplitude(-75), 3, 2))
+	if levels := meter.Levels(); !levels.Silent() {
+		t.Errorf("integrated = %.2f LUFS, want silence below the absolute gate", levels.Integrated)
+	}
+
+	// Quiet audio after a -65 LUFS passage is within 10 LU of it, but still below the
+	// absolute gate, so it must not lower the result
+	meter = NewMeter(testSampleRate, 2)
+	meter.Write(sine(dbAmplitude(-65), 3, 2))
+	meter.Write(sine(dbAmplitude(-72), 3, 2))
+	if levels := meter.Levels(); math.Abs(levels.Integrated+65) > 0.3 {
+		t.Errorf("integrated = %.2f LUFS, want about -65", levels.Integrated)
+	}
+}
+
+func TestMeterRelativeGate(t *testing.T) {
+	// A passage more than 10 LU below the rest is gated out
+	meter := NewMeter(testSampleRate, 2)
+	meter.Write(sine(dbAmplitude(-20), 3, 2))
+	meter.Write(sine(dbAmplitude(-40), 3, 2))
+	if levels := meter.Levels(); math.Abs(levels.Integrated+20) > 0.3 {
+		t.Errorf("integrated = %.2f LUFS, want about -20", levels.Integrated)
+	}
+}
+
+func TestMeterShortAudio(t *testing.T) {
+	// Less than one 400 ms block has no integrated loudness
+	meter := NewMeter(testSampleRate, 2)
+	meter.Write(sine(1, 0.3, 2))
+	if levels := meter.Levels(); !levels.Silent() {
+		t.Errorf("integrated = %.2f LUFS, want none for 300 ms", levels.Integrated)
+	}
+}
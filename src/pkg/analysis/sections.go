@@ -0,0 +1,68 @@
+package analysis
+
+import (
+	"math"
+	"sort"
+)
+
+// Section is a named stretch of the project, from a region or between two markers
+type Section struct {
+	Name  string
+	Start float64 // Project time, seconds
+	End   float64 // Project time, seconds
+}
+
+// SectionLevels are the measurements of one section
+type SectionLevels struct {
+	Section
+	Levels
+}
+
+// Contrast is a loudness difference between two sections worth mentioning
+type Contrast struct {
+	Louder     string  // Name of the louder section
+	Quieter    string  // Name of the quieter section
+	Difference float64 // LU, always positive
+}
+
+// Contrasts finds the loudness changes between consecutive sections of at least
+// threshold LU, e.g. a chorus 4 LU louder than the verse before it. Silent sections are
+// skipped. When no consecutive sections differ enough but the loudest and quietest do,
+// that difference is reported instead.
+func Contrasts(sections []SectionLevels, threshold float64) []Contrast {
+	var audible []SectionLevels
+	for _, section := range sections {
+		if !section.Silent() {
+			audible = append(audible, section)
+		}
+	}
+
+	var contrasts []Contrast
+	for i := 1; i < len(audible); i++ {
+		if contrast, ok := contrastOf(audible[i-1], audible[i], threshold); ok {
+			contrasts = append(contrasts, contrast)
+		}
+	}
+	if len(contrasts) > 0 || len(audible) < 2 {
+		return contrasts
+	}
+
+	sorted := append([]SectionLevels(nil), audible...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Integrated > sorted[j].Integrated })
+	if contrast, ok := contrastOf(sorted[0], sorted[len(sorted)-1], threshold); ok {
+		contrasts = append(contrasts, contrast)
+	}
+	return contrasts
+}
+
+// contrastOf compares two sections' loudness
+func contrastOf(a, b SectionLevels, threshold float64) (Contrast, bool) {
+	difference := a.Integrated - b.Integrated
+	if math.Abs(difference) < threshold {
+		return Contrast{}, false
+	}
+	if difference < 0 {
+		a, b = b, a
+	}
+	return Contrast{Louder: a.Name, Quieter: b.Name, Difference: math.Abs(difference)}, true
+}
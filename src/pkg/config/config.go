@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"go-reaper/src/pkg/logger"
 	"go-reaper/src/reaper"
+	"strings"
 	"sync"
 
 	"github.com/zalando/go-keyring"
@@ -14,7 +15,7 @@ import (
 
 // VERSION indicates the settings schema version
 // Increment this when making incompatible changes to settings structure
-const VERSION = 1
+const VERSION = 2
 
 // Constants for keyring access
 const (
@@ -23,7 +24,9 @@ const (
 
 // KeyringKeys represents different API keys we might store
 const (
-	KeyringOpenAI = "OpenAIAPIKey"
+	KeyringOpenAI           = "OpenAIAPIKey"
+	KeyringGemini           = "GeminiAPIKey"
+	KeyringOpenAICompatible = "OpenAICompatibleAPIKey"
 	// KeyringClaude = "ClaudeAPIKey"
 )
 
@@ -32,13 +35,99 @@ type Provider string
 
 // Provider constants
 const (
-	ProviderOpenAI Provider = "openai"
+	ProviderOpenAI           Provider = "openai"
+	ProviderGemini           Provider = "gemini"
+	ProviderOpenAICompatible Provider = "openai_compatible" // Any OpenAI-compatible endpoint (LM Studio, vLLM, proxies)
 	// ProviderClaude   Provider = "claude"
 	// ProviderOllama   Provider = "ollama"
 	// ProviderLMStudio Provider = "lmstudio"
 	// Add more providers as needed
 )
 
+// CompatibleProviderSettings holds the configuration for providers reached through an
+// OpenAI-compatible chat completions endpoint
+type CompatibleProviderSettings struct {
+	BaseURL     string  `json:"base_url"`
+	Model       string  `json:"model"`
+	MaxTokens   int     `json:"max_tokens"`
+	Temperature float64 `json:"temperature"`
+}
+
+// MacroStep is one step of a macro
+type MacroStep struct {
+	// Command is a REAPER command ID ("40001"), a named command ("_SWS_ABOUT") or one
+	// of our action IDs ("GO_FX_ASSISTANT")
+	Command string `json:"command"`
+	DelayMs int    `json:"delay_ms,omitempty"` // Wait before running the step
+	If      string `json:"if,omitempty"`       // Condition the step runs under, e.g. "track_selected" or "!in_region"
+}
+
+// Macro is a named sequence of actions run as one action
+type Macro struct {
+	Name  string      `json:"name"`
+	Steps []MacroStep `json:"steps"`
+}
+
+// ControlBankSettings configures the bank of CCs that controls the focused FX's
+// parameters, a page at a time
+type ControlBankSettings struct {
+	Enabled bool `json:"enabled"`
+	Channel int  `json:"channel"`  // MIDI channel of the bank's CCs, 1-16
+	FirstCC int  `json:"first_cc"` // CCs FirstCC to FirstCC+Size-1 control a page's parameters
+	Size    int  `json:"size"`     // Parameters per page
+}
+
+// Appearances of the extension's windows
+const (
+	AppearanceAuto  = ""      // Follow REAPER's theme, or the system's appearance if it can't be read
+	AppearanceLight = "light" // Always light
+	AppearanceDark  = "dark"  // Always dark
+)
+
+// WindowPreferences is how one of the extension's windows opens
+type WindowPreferences struct {
+	Width  int  `json:"width,omitempty"`  // Default width; 0 for the window's own size
+	Height int  `json:"height,omitempty"` // Default height; 0 for the window's own size
+	Docked bool `json:"docked,omitempty"` // Open in REAPER's docker rather than floating
+}
+
+// ActionSettings holds the settings of one action
+type ActionSettings struct {
+	Disabled bool `json:"disabled,omitempty"` // Not registered from the next REAPER start
+}
+
+// AnalyzerSettings controls how FX parameters are described to the assistant
+type AnalyzerSettings struct {
+	FilterBoilerplate bool `json:"filter_boilerplate"` // Always leave out bypass, MIDI and placeholder parameters
+	MaxParamsPerFX    int  `json:"max_params_per_fx"`  // List at most this many parameters of each FX; 0 for all that fit
+
+	// Parameters unlikely to matter for a request, left out of every prompt
+	SkipBypass     bool `json:"skip_bypass"`      // Bypass and delta solo switches
+	SkipMIDI       bool `json:"skip_midi"`        // MIDI CC, channel and program change parameters
+	SkipOffToggles bool `json:"skip_off_toggles"` // On/off switches that are off, unless the request names their stage
+
+	// Describe the track's loudness per region, or between markers, read from its audio
+	SectionLoudness bool `json:"section_loudness"`
+}
+
+// NetworkSettings controls how LLM providers are reached, e.g. from behind a corporate
+// proxy that inspects TLS
+type NetworkSettings struct {
+	ProxyURL   string `json:"proxy_url,omitempty"`    // e.g. "http://proxy.example.com:8080"; empty uses HTTPS_PROXY and friends
+	TimeoutSec int    `json:"timeout_sec,omitempty"`  // Request timeout; 0 for the default
+	CACertFile string `json:"ca_cert_file,omitempty"` // PEM file of certificates trusted besides the system's
+
+	// Requests that fail for a transient reason, e.g. rate limiting, are repeated
+	Retries       int `json:"retries,omitempty"`         // Retries after the first attempt; 0 for the default, -1 for none
+	MaxBackoffSec int `json:"max_backoff_sec,omitempty"` // Longest wait before a retry; 0 for the default
+}
+
+// UpdateSettings say where to look for new versions of the extension
+type UpdateSettings struct {
+	FeedURL string `json:"feed_url,omitempty"` // ReaPack index listing the releases; empty to never check
+	Package string `json:"package,omitempty"`  // Package in the index; empty for its first extension
+}
+
 // Settings defines the structure of our application settings
 type Settings struct {
 	// Schema version for migration support
@@ -56,52 +145,113 @@ type Settings struct {
 			Temperature float64 `json:"temperature"`
 		} `json:"openai"`
 
-		// TODO: add other providers
+		// Google Gemini via its OpenAI compatibility layer
+		Gemini CompatibleProviderSettings `json:"gemini"`
+
+		// Generic OpenAI-compatible endpoint
+		OpenAICompatible CompatibleProviderSettings `json:"openai_compatible"`
 	} `json:"providers"`
 
 	// Prompt settings
 	Prompt struct {
-		DefaultPrompt string `json:"default_prompt"`
+		DefaultPrompt  string `json:"default_prompt"`
+		SystemTemplate string `json:"system_template"` // Empty uses the built-in system prompt
+		UserTemplate   string `json:"user_template"`   // Empty uses the built-in user prompt
 	} `json:"prompt"`
 
 	// General plugin settings
 	General struct {
-		AutoApplyChanges bool `json:"auto_apply_changes"`
+		AutoApplyChanges bool   `json:"auto_apply_changes"`
+		CacheResponses   bool   `json:"cache_responses"`             // Reuse LLM responses for identical requests
+		CrashReports     bool   `json:"crash_reports"`               // Show a dialog when an error is recovered
+		ConsoleLogLevel  string `json:"console_log_level,omitempty"` // Mirror log messages up to this level to the console; empty for none
+		KeyStorage       string `json:"key_storage,omitempty"`       // Where API keys are stored; see KeyStorageAuto
+		KeyFileSecret    string `json:"key_file_secret,omitempty"`   // What the API key file is encrypted with; see KeyFileSecretMachine
+		LogLLMExchanges  bool   `json:"log_llm_exchanges,omitempty"` // Write redacted LLM requests and responses to llm_exchanges.log
+
+		// DisabledActions is only read from version 1 settings; migrateV1toV2 moves it
+		// to Actions
+		DisabledActions []string `json:"disabled_actions,omitempty"`
 		// Add more general settings as needed
 	} `json:"general"`
+
+	// How tracks are marked when the assistant changes their FX; empty fields leave
+	// tracks as they are
+	TrackMarking struct {
+		Color      string `json:"color,omitempty"`       // Tint changed tracks, as "#RRGGBB"
+		NamePrefix string `json:"name_prefix,omitempty"` // Put before changed tracks' names, e.g. "[AI] "
+	} `json:"track_marking"`
+
+	// User-defined macros, each registered as an action
+	Macros []Macro `json:"macros,omitempty"`
+
+	// CCs that control the focused FX, for generic MIDI controllers
+	ControlBank ControlBankSettings `json:"control_bank"`
+
+	// User interface preferences
+	UI struct {
+		Windows    map[string]WindowPreferences `json:"windows,omitempty"`    // By window ID, e.g. "assistant-panel"
+		Appearance string                       `json:"appearance,omitempty"` // See AppearanceAuto
+	} `json:"ui"`
+
+	// Per-action settings, by action ID; actions without an entry use the defaults
+	Actions map[string]ActionSettings `json:"actions,omitempty"`
+
+	// How the assistant describes FX parameters
+	Analyzer AnalyzerSettings `json:"analyzer"`
+
+	// How LLM providers are reached
+	Network NetworkSettings `json:"network"`
+
+	// Where releases are published
+	Updates UpdateSettings `json:"updates"`
 }
 
 // DefaultSettings provides the default configuration
-var DefaultSettings = Settings{
-	Version:        VERSION,
-	ActiveProvider: ProviderOpenAI,
-	Providers: struct {
-		OpenAI struct {
-			Model       string  `json:"model"`
-			MaxTokens   int     `json:"max_tokens"`
-			Temperature float64 `json:"temperature"`
-		} `json:"openai"`
-	}{
-		OpenAI: struct {
-			Model       string  `json:"model"`
-			MaxTokens   int     `json:"max_tokens"`
-			Temperature float64 `json:"temperature"`
-		}{
-			Model:       "gpt-3.5-turbo",
-			MaxTokens:   1024,
-			Temperature: 0.7,
-		},
-	},
-	Prompt: struct {
-		DefaultPrompt string `json:"default_prompt"`
-	}{
-		DefaultPrompt: "", // TODO: centralize this
-	},
-	General: struct {
-		AutoApplyChanges bool `json:"auto_apply_changes"`
-	}{
-		AutoApplyChanges: false,
-	},
+var DefaultSettings = defaultSettings()
+
+// defaultSettings builds the default configuration
+func defaultSettings() Settings {
+	var settings Settings
+	settings.Version = VERSION
+	settings.ActiveProvider = ProviderOpenAI
+
+	settings.Providers.OpenAI.Model = "gpt-3.5-turbo"
+	settings.Providers.OpenAI.MaxTokens = 1024
+	settings.Providers.OpenAI.Temperature = 0.7
+
+	settings.Providers.Gemini = CompatibleProviderSettings{
+		BaseURL:     "https://generativelanguage.googleapis.com/v1beta/openai",
+		Model:       "gemini-2.0-flash",
+		MaxTokens:   1024,
+		Temperature: 0.7,
+	}
+
+	settings.Providers.OpenAICompatible = CompatibleProviderSettings{
+		BaseURL:     "http://localhost:1234/v1",
+		Model:       "",
+		MaxTokens:   1024,
+		Temperature: 0.7,
+	}
+
+	settings.Prompt.DefaultPrompt = "" // TODO: centralize this
+	settings.General.AutoApplyChanges = false
+	settings.General.CacheResponses = true
+	settings.General.CrashReports = true
+
+	settings.Analyzer = AnalyzerSettings{
+		SkipBypass: true,
+		SkipMIDI:   true,
+	}
+
+	settings.ControlBank = ControlBankSettings{
+		Enabled: false,
+		Channel: 1,
+		FirstCC: 20,
+		Size:    8,
+	}
+
+	return settings
 }
 
 // ExtState keys - note we use a consistent key, versioning is handled within the JSON
@@ -113,16 +263,102 @@ const (
 // configMutex protects access to the settings
 var configMutex sync.RWMutex
 
-// GetSecureAPIKey retrieves an API key from the system keyring
+// GetSecureAPIKey retrieves an API key from the system keyring or the encrypted key
+// file, as set by the key storage setting
 func GetSecureAPIKey(provider Provider) (string, error) {
 	keyName := providerToKeyringKey(provider)
-	return keyring.Get(KeyringServiceName, keyName)
+
+	switch GetKeyStorage() {
+	case KeyStorageKeyring:
+		return keyring.Get(KeyringServiceName, keyName)
+	case KeyStorageFile:
+		return getFileKey(keyName)
+	}
+
+	key, err := keyring.Get(KeyringServiceName, keyName)
+	if err == nil {
+		return key, nil
+	}
+	inFile, fileErr := hasKeyFile()
+	if fileErr != nil {
+		return "", fileErr
+	}
+	if !inFile {
+		return key, err
+	}
+	return getFileKey(keyName)
 }
 
-// StoreSecureAPIKey stores an API key in the system keyring
+// StoreSecureAPIKey stores an API key in the system keyring or the encrypted key file,
+// as set by the key storage setting. Automatic storage uses the file when the keyring
+// is unavailable.
 func StoreSecureAPIKey(provider Provider, apiKey string) error {
 	keyName := providerToKeyringKey(provider)
-	return keyring.Set(KeyringServiceName, keyName, apiKey)
+
+	switch GetKeyStorage() {
+	case KeyStorageKeyring:
+		return keyring.Set(KeyringServiceName, keyName, apiKey)
+	case KeyStorageFile:
+		return setFileKey(keyName, apiKey)
+	}
+
+	err := keyring.Set(KeyringServiceName, keyName, apiKey)
+	if err == nil {
+		return nil
+	}
+	logger.Warning("System keyring unavailable (%v), storing the API key in the encrypted key file", err)
+	return setFileKey(keyName, apiKey)
+}
+
+// GetKeyStorage returns where API keys are stored, one of the KeyStorage constants
+func GetKeyStorage() string {
+	return GetSettings().General.KeyStorage
+}
+
+// SetKeyStorage sets where API keys are stored from now on. Keys already stored stay
+// where they are.
+func SetKeyStorage(storage string) error {
+	switch storage {
+	case KeyStorageAuto, KeyStorageKeyring, KeyStorageFile:
+	default:
+		return fmt.Errorf("unknown key storage %q", storage)
+	}
+
+	configMutex.Lock()
+	defer configMutex.Unlock()
+
+	settings := loadSettings()
+	settings.General.KeyStorage = storage
+
+	return saveSettingsLocked(settings)
+}
+
+// GetKeyFileSecret returns what the API key file is encrypted with, one of the
+// KeyFileSecret constants
+func GetKeyFileSecret() string {
+	return GetSettings().General.KeyFileSecret
+}
+
+// SetKeyFileSecret sets what the API key file is encrypted with and encrypts the keys
+// already in it again. Switching to a passphrase needs SetKeyFilePassphrase first.
+func SetKeyFileSecret(secret string) error {
+	switch secret {
+	case KeyFileSecretMachine, KeyFileSecretPassphrase:
+	default:
+		return fmt.Errorf("unknown key file secret %q", secret)
+	}
+
+	if err := reencryptKeyFile(secret); err != nil {
+		return err
+	}
+
+	configMutex.Lock()
+	defer configMutex.Unlock()
+
+	settings := loadSettings()
+	settings.General.KeyFileSecret = secret
+
+	return saveSettingsLocked(settings)
 }
 
 // HasSecureAPIKey checks if an API key exists in the keyring
@@ -136,6 +372,10 @@ func providerToKeyringKey(provider Provider) string {
 	switch provider {
 	case ProviderOpenAI:
 		return KeyringOpenAI
+	case ProviderGemini:
+		return KeyringGemini
+	case ProviderOpenAICompatible:
+		return KeyringOpenAICompatible
 	default:
 		return KeyringOpenAI
 	}
@@ -155,6 +395,11 @@ func SaveSettings(settings Settings) error {
 	configMutex.Lock()
 	defer configMutex.Unlock()
 
+	return saveSettingsLocked(settings)
+}
+
+// saveSettingsLocked saves the settings; the caller must hold configMutex
+func saveSettingsLocked(settings Settings) error {
 	// Ensure version is current before saving
 	settings.Version = VERSION
 
@@ -234,8 +479,7 @@ func migrateSettingsStepByStep(oldSettings Settings) (Settings, error) {
 
 		switch v {
 		case 1:
-			// v1 to v2 migration (when we add v2)
-			// settings = migrateV1toV2(settings)
+			settings = migrateV1toV2(settings)
 
 		case 2:
 			// v2 to v3 migration (when we add v3)
@@ -253,18 +497,30 @@ func migrateSettingsStepByStep(oldSettings Settings) (Settings, error) {
 	return settings, nil
 }
 
-// Future migration helpers would be defined below:
+// migrateV1toV2 handles migration from v1 to v2, which added the UI, per-action and
+// analyzer sections. The list of disabled actions becomes per-action settings; the new
+// sections start from their defaults.
+func migrateV1toV2(settings Settings) Settings {
+	newSettings := settings
+	defaults := defaultSettings()
 
-// migrateV1toV2 handles migration from v1 to v2
-// func migrateV1toV2(settings Settings) Settings {
-//     // Example migration logic
-//     newSettings := settings
-//
-//     // Add new field with default value
-//     // newSettings.NewFieldInV2 = defaultValue
-//
-//     return newSettings
-// }
+	newSettings.Actions = make(map[string]ActionSettings, len(settings.General.DisabledActions))
+	for id, action := range settings.Actions {
+		newSettings.Actions[id] = action
+	}
+	for _, id := range settings.General.DisabledActions {
+		newSettings.Actions[id] = ActionSettings{Disabled: true}
+	}
+	newSettings.General.DisabledActions = nil
+
+	newSettings.UI = defaults.UI
+	newSettings.Analyzer = defaults.Analyzer
+
+	logger.Debug("Migrated %d disabled actions to per-action settings", len(settings.General.DisabledActions))
+	return newSettings
+}
+
+// Future migration helpers would be defined below:
 
 // GetActiveProvider returns the currently active LLM provider
 func GetActiveProvider() Provider {
@@ -278,7 +534,7 @@ func SetActiveProvider(provider Provider) error {
 
 	settings := loadSettings()
 	settings.ActiveProvider = provider
-	return SaveSettings(settings)
+	return saveSettingsLocked(settings)
 }
 
 // GetProviderConfig returns the configuration for the specified provider
@@ -290,6 +546,14 @@ func GetProviderConfig(provider Provider) (model string, maxTokens int, temperat
 		return settings.Providers.OpenAI.Model,
 			settings.Providers.OpenAI.MaxTokens,
 			settings.Providers.OpenAI.Temperature
+	case ProviderGemini:
+		return settings.Providers.Gemini.Model,
+			settings.Providers.Gemini.MaxTokens,
+			settings.Providers.Gemini.Temperature
+	case ProviderOpenAICompatible:
+		return settings.Providers.OpenAICompatible.Model,
+			settings.Providers.OpenAICompatible.MaxTokens,
+			settings.Providers.OpenAICompatible.Temperature
 	default:
 		// Fallback to OpenAI config
 		logger.Warning("Unknown provider %s, using OpenAI configuration", provider)
@@ -317,11 +581,53 @@ func SetProviderConfig(provider Provider, model string, maxTokens int, temperatu
 		settings.Providers.OpenAI.Model = model
 		settings.Providers.OpenAI.MaxTokens = maxTokens
 		settings.Providers.OpenAI.Temperature = temperature
+	case ProviderGemini:
+		settings.Providers.Gemini.Model = model
+		settings.Providers.Gemini.MaxTokens = maxTokens
+		settings.Providers.Gemini.Temperature = temperature
+	case ProviderOpenAICompatible:
+		settings.Providers.OpenAICompatible.Model = model
+		settings.Providers.OpenAICompatible.MaxTokens = maxTokens
+		settings.Providers.OpenAICompatible.Temperature = temperature
 	default:
 		return fmt.Errorf("unsupported provider: %s", provider)
 	}
 
-	return SaveSettings(settings)
+	return saveSettingsLocked(settings)
+}
+
+// GetProviderBaseURL returns the API base URL for providers using an OpenAI-compatible endpoint.
+// Returns an empty string for providers with a fixed endpoint.
+func GetProviderBaseURL(provider Provider) string {
+	settings := GetSettings()
+
+	switch provider {
+	case ProviderGemini:
+		return settings.Providers.Gemini.BaseURL
+	case ProviderOpenAICompatible:
+		return settings.Providers.OpenAICompatible.BaseURL
+	default:
+		return ""
+	}
+}
+
+// SetProviderBaseURL sets the API base URL for an OpenAI-compatible provider
+func SetProviderBaseURL(provider Provider, baseURL string) error {
+	configMutex.Lock()
+	defer configMutex.Unlock()
+
+	settings := loadSettings()
+
+	switch provider {
+	case ProviderGemini:
+		settings.Providers.Gemini.BaseURL = baseURL
+	case ProviderOpenAICompatible:
+		settings.Providers.OpenAICompatible.BaseURL = baseURL
+	default:
+		return fmt.Errorf("provider %s does not support a custom base URL", provider)
+	}
+
+	return saveSettingsLocked(settings)
 }
 
 // GetPromptConfig returns the prompt configuration
@@ -337,7 +643,26 @@ func SetPromptConfig(defaultPrompt string) error {
 	settings := loadSettings()
 	settings.Prompt.DefaultPrompt = defaultPrompt
 
-	return SaveSettings(settings)
+	return saveSettingsLocked(settings)
+}
+
+// GetPromptTemplates returns the custom system and user prompt templates.
+// Empty strings mean the built-in templates should be used.
+func GetPromptTemplates() (systemTemplate string, userTemplate string) {
+	settings := GetSettings()
+	return settings.Prompt.SystemTemplate, settings.Prompt.UserTemplate
+}
+
+// SetPromptTemplates sets the custom system and user prompt templates
+func SetPromptTemplates(systemTemplate string, userTemplate string) error {
+	configMutex.Lock()
+	defer configMutex.Unlock()
+
+	settings := loadSettings()
+	settings.Prompt.SystemTemplate = systemTemplate
+	settings.Prompt.UserTemplate = userTemplate
+
+	return saveSettingsLocked(settings)
 }
 
 // GetGeneralConfig returns the general configuration
@@ -353,7 +678,291 @@ func SetGeneralConfig(autoApplyChanges bool) error {
 	settings := loadSettings()
 	settings.General.AutoApplyChanges = autoApplyChanges
 
-	return SaveSettings(settings)
+	return saveSettingsLocked(settings)
+}
+
+// GetCacheResponses returns whether LLM responses should be cached
+func GetCacheResponses() bool {
+	return GetSettings().General.CacheResponses
+}
+
+// SetCacheResponses enables or disables LLM response caching
+func SetCacheResponses(enabled bool) error {
+	configMutex.Lock()
+	defer configMutex.Unlock()
+
+	settings := loadSettings()
+	settings.General.CacheResponses = enabled
+
+	return saveSettingsLocked(settings)
+}
+
+// GetLogLLMExchanges returns whether LLM requests and responses are logged for debugging
+func GetLogLLMExchanges() bool {
+	return GetSettings().General.LogLLMExchanges
+}
+
+// SetLogLLMExchanges enables or disables logging LLM requests and responses
+func SetLogLLMExchanges(enabled bool) error {
+	configMutex.Lock()
+	defer configMutex.Unlock()
+
+	settings := loadSettings()
+	settings.General.LogLLMExchanges = enabled
+
+	return saveSettingsLocked(settings)
+}
+
+// GetCrashReports returns whether recovered errors are reported in a dialog
+func GetCrashReports() bool {
+	return GetSettings().General.CrashReports
+}
+
+// SetCrashReports enables or disables crash report dialogs from the next REAPER start
+func SetCrashReports(enabled bool) error {
+	configMutex.Lock()
+	defer configMutex.Unlock()
+
+	settings := loadSettings()
+	settings.General.CrashReports = enabled
+
+	return saveSettingsLocked(settings)
+}
+
+// GetConsoleLogLevel returns the level up to which log messages are mirrored to the
+// REAPER console, e.g. "warning", or "" when they aren't
+func GetConsoleLogLevel() string {
+	return GetSettings().General.ConsoleLogLevel
+}
+
+// SetConsoleLogLevel sets the level up to which log messages are mirrored to the REAPER
+// console; "" turns mirroring off
+func SetConsoleLogLevel(level string) error {
+	if _, ok := logger.ParseLevel(level); level != "" && !ok {
+		return fmt.Errorf("unknown log level %q", level)
+	}
+
+	configMutex.Lock()
+	defer configMutex.Unlock()
+
+	settings := loadSettings()
+	settings.General.ConsoleLogLevel = level
+
+	return saveSettingsLocked(settings)
+}
+
+// GetTrackMarking returns how tracks the assistant changed are marked: a "#RRGGBB" tint
+// and a name prefix, each empty when not used
+func GetTrackMarking() (color string, namePrefix string) {
+	settings := GetSettings()
+	return settings.TrackMarking.Color, settings.TrackMarking.NamePrefix
+}
+
+// SetTrackMarking sets how tracks the assistant changed are marked; pass empty strings
+// to leave them as they are
+func SetTrackMarking(color string, namePrefix string) error {
+	if color != "" {
+		if _, err := reaper.ParseColor(color); err != nil {
+			return err
+		}
+	}
+
+	configMutex.Lock()
+	defer configMutex.Unlock()
+
+	settings := loadSettings()
+	settings.TrackMarking.Color = color
+	settings.TrackMarking.NamePrefix = namePrefix
+
+	return saveSettingsLocked(settings)
+}
+
+// GetMacros returns the user-defined macros
+func GetMacros() []Macro {
+	return GetSettings().Macros
+}
+
+// SetMacros replaces the user-defined macros
+func SetMacros(macros []Macro) error {
+	configMutex.Lock()
+	defer configMutex.Unlock()
+
+	settings := loadSettings()
+	settings.Macros = macros
+
+	return saveSettingsLocked(settings)
+}
+
+// GetControlBank returns the settings of the CC bank that controls the focused FX
+func GetControlBank() ControlBankSettings {
+	return GetSettings().ControlBank
+}
+
+// SetControlBank sets the CC bank that controls the focused FX
+func SetControlBank(bank ControlBankSettings) error {
+	if bank.Channel < 1 || bank.Channel > 16 {
+		return fmt.Errorf("invalid MIDI channel %d", bank.Channel)
+	}
+	if bank.Size < 1 || bank.FirstCC < 0 || bank.FirstCC+bank.Size > 128 {
+		return fmt.Errorf("CCs %d to %d are out of range", bank.FirstCC, bank.FirstCC+bank.Size-1)
+	}
+
+	configMutex.Lock()
+	defer configMutex.Unlock()
+
+	settings := loadSettings()
+	settings.ControlBank = bank
+
+	return saveSettingsLocked(settings)
+}
+
+// IsActionEnabled reports whether an action should be registered. Actions are enabled
+// unless their settings disable them; changes apply from the next REAPER start.
+func IsActionEnabled(actionID string) bool {
+	return !GetActionSettings(actionID).Disabled
+}
+
+// SetActionEnabled enables or disables an action from the next REAPER start
+func SetActionEnabled(actionID string, enabled bool) error {
+	action := GetActionSettings(actionID)
+	action.Disabled = !enabled
+	return SetActionSettings(actionID, action)
+}
+
+// GetActionSettings returns an action's settings, the defaults if it has none
+func GetActionSettings(actionID string) ActionSettings {
+	return GetSettings().Actions[actionID]
+}
+
+// SetActionSettings sets an action's settings; default settings remove its entry
+func SetActionSettings(actionID string, action ActionSettings) error {
+	configMutex.Lock()
+	defer configMutex.Unlock()
+
+	settings := loadSettings()
+	actions := make(map[string]ActionSettings, len(settings.Actions)+1)
+	for id, existing := range settings.Actions {
+		if id != actionID {
+			actions[id] = existing
+		}
+	}
+	if action != (ActionSettings{}) {
+		actions[actionID] = action
+	}
+	settings.Actions = actions
+
+	return saveSettingsLocked(settings)
+}
+
+// GetWindowPreferences returns how a window opens, by its ID
+func GetWindowPreferences(windowID string) WindowPreferences {
+	return GetSettings().UI.Windows[windowID]
+}
+
+// SetWindowPreferences sets how a window opens; default preferences remove its entry
+func SetWindowPreferences(windowID string, preferences WindowPreferences) error {
+	if preferences.Width < 0 || preferences.Height < 0 {
+		return fmt.Errorf("invalid window size %dx%d", preferences.Width, preferences.Height)
+	}
+
+	configMutex.Lock()
+	defer configMutex.Unlock()
+
+	settings := loadSettings()
+	windows := make(map[string]WindowPreferences, len(settings.UI.Windows)+1)
+	for id, existing := range settings.UI.Windows {
+		if id != windowID {
+			windows[id] = existing
+		}
+	}
+	if preferences != (WindowPreferences{}) {
+		windows[windowID] = preferences
+	}
+	settings.UI.Windows = windows
+
+	return saveSettingsLocked(settings)
+}
+
+// GetAppearance returns whether windows are light, dark or follow the theme
+func GetAppearance() string {
+	return GetSettings().UI.Appearance
+}
+
+// SetAppearance sets whether windows are light, dark or follow the theme
+func SetAppearance(appearance string) error {
+	switch appearance {
+	case AppearanceAuto, AppearanceLight, AppearanceDark:
+	default:
+		return fmt.Errorf("unknown appearance %q", appearance)
+	}
+
+	configMutex.Lock()
+	defer configMutex.Unlock()
+
+	settings := loadSettings()
+	settings.UI.Appearance = appearance
+
+	return saveSettingsLocked(settings)
+}
+
+// GetAnalyzer returns how the assistant describes FX parameters
+func GetAnalyzer() AnalyzerSettings {
+	return GetSettings().Analyzer
+}
+
+// SetAnalyzer sets how the assistant describes FX parameters
+func SetAnalyzer(analyzer AnalyzerSettings) error {
+	if analyzer.MaxParamsPerFX < 0 {
+		return fmt.Errorf("invalid parameter limit %d", analyzer.MaxParamsPerFX)
+	}
+
+	configMutex.Lock()
+	defer configMutex.Unlock()
+
+	settings := loadSettings()
+	settings.Analyzer = analyzer
+
+	return saveSettingsLocked(settings)
+}
+
+// GetNetwork returns how LLM providers are reached
+func GetNetwork() NetworkSettings {
+	return GetSettings().Network
+}
+
+// SetNetwork sets how LLM providers are reached
+func SetNetwork(network NetworkSettings) error {
+	if network.TimeoutSec < 0 {
+		return fmt.Errorf("invalid timeout %d", network.TimeoutSec)
+	}
+
+	configMutex.Lock()
+	defer configMutex.Unlock()
+
+	settings := loadSettings()
+	settings.Network = network
+
+	return saveSettingsLocked(settings)
+}
+
+// GetUpdates returns where new versions are looked for
+func GetUpdates() UpdateSettings {
+	return GetSettings().Updates
+}
+
+// SetUpdates sets where new versions are looked for
+func SetUpdates(updates UpdateSettings) error {
+	if updates.FeedURL != "" && !strings.HasPrefix(updates.FeedURL, "https://") && !strings.HasPrefix(updates.FeedURL, "http://") {
+		return fmt.Errorf("invalid release feed URL %q", updates.FeedURL)
+	}
+
+	configMutex.Lock()
+	defer configMutex.Unlock()
+
+	settings := loadSettings()
+	settings.Updates = updates
+
+	return saveSettingsLocked(settings)
 }
 
 // ResetToDefaults resets all settings to defaults
@@ -362,5 +971,5 @@ func ResetToDefaults() error {
 	defer configMutex.Unlock()
 
 	// Save default settings
-	return SaveSettings(DefaultSettings)
+	return saveSettingsLocked(DefaultSettings)
 }
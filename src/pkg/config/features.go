@@ -0,0 +1,195 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Feature flags turn the extension's major subsystems on or off when the plugin loads,
+// without rebuilding it. They are read from an INI file in REAPER's resource path, so
+// a subsystem that misbehaves can be turned off even when REAPER can't start with it:
+//
+//	[features]
+//	assistant = false
+//	demos = off
+//
+// An environment variable such as REAPER_GO_FEATURE_ASSISTANT=0 overrides the file.
+// Everything is on unless turned off.
+
+// FeaturesFileName is the feature flag file in REAPER's resource path
+const FeaturesFileName = "GoReaperExtension.ini"
+
+// featuresSection is the INI section the flags are read from
+const featuresSection = "features"
+
+// FeatureEnvPrefix starts the environment variables that override a feature flag; the
+// rest is the feature's name in upper case
+const FeatureEnvPrefix = "REAPER_GO_FEATURE_"
+
+// Features that can be turned off
+const (
+	FeatureHTTPServer = "http_server" // The HTTP control server
+	FeatureOSC        = "osc"         // OSC control
+	FeatureDemos      = "demos"       // Demo and test actions
+	FeatureAssistant  = "assistant"   // The LLM assistant and its settings
+)
+
+// Features lists the feature flags in the order they are shown
+var Features = []string{FeatureHTTPServer, FeatureOSC, FeatureDemos, FeatureAssistant}
+
+// Flags read by LoadFeatures; features not listed are on
+var (
+	featuresMutex sync.RWMutex
+	featureFlags  = make(map[string]bool)
+	featureSource = make(map[string]string)
+)
+
+// LoadFeatures reads the feature flags from the file in REAPER's resource path and the
+// environment. Call it once when the plugin loads, before registering subsystems. A
+// missing file leaves everything on; problems in the file are logged and skipped.
+func LoadFeatures() error {
+	flags := make(map[string]bool)
+	source := make(map[string]string)
+
+	path := FeaturesPath()
+	file, err := os.Open(path)
+	switch {
+	case err == nil:
+		fileFlags, parseErr := parseFeatures(file)
+		file.Close()
+		if parseErr != nil {
+			err = fmt.Errorf("failed to read %s: %w", path, parseErr)
+		}
+		for name, on := range fileFlags {
+			flags[name] = on
+			source[name] = FeaturesFileName
+		}
+	case os.IsNotExist(err):
+		err = nil
+	default:
+		err = fmt.Errorf("failed to open %s: %w", path, err)
+	}
+
+	for _, name := range Features {
+		env := FeatureEnvPrefix + strings.ToUpper(name)
+		value, set := os.LookupEnv(env)
+		if !set {
+			continue
+		}
+		on, ok := parseFlag(value)
+		if !ok {
+			logger.Warning("Ignoring %s=%q: use on or off", env, value)
+			continue
+		}
+		flags[name] = on
+		source[name] = env
+	}
+
+	featuresMutex.Lock()
+	featureFlags = flags
+	featureSource = source
+	featuresMutex.Unlock()
+
+	for _, name := range Features {
+		if !IsFeatureEnabled(name) {
+			logger.Info("Feature %s is turned off by %s", name, source[name])
+		}
+	}
+	return err
+}
+
+// IsFeatureEnabled reports whether a feature is on. The empty name, for things that
+// aren't part of a feature, is always on.
+func IsFeatureEnabled(name string) bool {
+	if name == "" {
+		return true
+	}
+
+	featuresMutex.RLock()
+	defer featuresMutex.RUnlock()
+
+	on, set := featureFlags[name]
+	return !set || on
+}
+
+// FeatureSource returns where a feature's flag was set: the file name, an environment
+// variable, or "" if it wasn't set
+func FeatureSource(name string) string {
+	featuresMutex.RLock()
+	defer featuresMutex.RUnlock()
+
+	return featureSource[name]
+}
+
+// FeaturesPath returns the path of the feature flag file
+func FeaturesPath() string {
+	base, err := reaper.GetResourcePath()
+	if err != nil || base == "" {
+		logger.Warning("Could not get REAPER resource path, looking for %s in the temp directory: %v", FeaturesFileName, err)
+		base = os.TempDir()
+	}
+	return filepath.Join(base, FeaturesFileName)
+}
+
+// parseFeatures reads the flags in the [features] section of an INI file. Keys are
+// matched ignoring case; comments start with ; or #. Unknown features and values are
+// logged and skipped.
+func parseFeatures(r io.Reader) (map[string]bool, error) {
+	known := make(map[string]bool, len(Features))
+	for _, name := range Features {
+		known[name] = true
+	}
+
+	flags := make(map[string]bool)
+	section := ""
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, ";") || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.ToLower(strings.TrimSpace(line[1 : len(line)-1]))
+			continue
+		}
+		if section != featuresSection {
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		name := strings.ToLower(strings.TrimSpace(key))
+		if !found {
+			logger.Warning("%s line %d: expected name = on or off", FeaturesFileName, lineNumber)
+			continue
+		}
+		if !known[name] {
+			logger.Warning("%s line %d: unknown feature %q", FeaturesFileName, lineNumber, name)
+			continue
+		}
+		on, ok := parseFlag(value)
+		if !ok {
+			logger.Warning("%s line %d: %s should be on or off, not %q", FeaturesFileName, lineNumber, name, strings.TrimSpace(value))
+			continue
+		}
+		flags[name] = on
+	}
+	return flags, scanner.Err()
+}
+
+// parseFlag reads an on or off value, accepting the usual spellings
+func parseFlag(value string) (on bool, ok bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "1", "true", "yes", "on", "enabled":
+		return true, true
+	case "0", "false", "no", "off", "disabled":
+		return false, true
+	}
+	return false, false
+}
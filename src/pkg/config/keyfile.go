@@ -0,0 +1,414 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// The encrypted key file stores API keys where the system keyring isn't available, e.g.
+// on Linux without a Secret Service. Each key is sealed with AES-256-GCM under a key
+// derived with PBKDF2 from a secret: this machine's ID, or a passphrase the user gives
+// each session. The machine ID keeps keys out of backups and synced folders copied to
+// other machines; a passphrase also protects them from other users of this machine.
+
+// Key storage choices for Settings.General.KeyStorage
+const (
+	KeyStorageAuto    = ""        // The keyring, falling back to the file when it is unavailable
+	KeyStorageKeyring = "keyring" // Only the system keyring
+	KeyStorageFile    = "file"    // Only the encrypted file
+)
+
+// Secrets the key file can be encrypted with, for Settings.General.KeyFileSecret
+const (
+	KeyFileSecretMachine    = ""           // This machine's ID
+	KeyFileSecretPassphrase = "passphrase" // A passphrase asked for each session
+)
+
+// KeyFilePassphraseEnv supplies the key file passphrase without asking, e.g. for
+// unattended setups
+const KeyFilePassphraseEnv = "REAPER_GO_KEY_PASSPHRASE"
+
+// keyFileName is the key file in the extension's data folder
+const keyFileName = "api_keys.enc"
+
+// keyFileIterations is the PBKDF2 work factor
+const keyFileIterations = 600000
+
+// ErrPassphraseRequired is returned by key file operations when the file is encrypted
+// with a passphrase that wasn't given this session; see SetKeyFilePassphrase
+var ErrPassphraseRequired = errors.New("the API key file passphrase is required")
+
+// ErrWrongPassphrase is returned when the key file can't be decrypted with the secret
+var ErrWrongPassphrase = errors.New("the API key file could not be decrypted; the passphrase or machine changed")
+
+// ErrNoKeyFile is returned by CheckKeyFile when no key has been stored in the file
+var ErrNoKeyFile = errors.New("there is no API key file")
+
+// keyFileData is the key file's JSON
+type keyFileData struct {
+	Version int               `json:"version"`
+	Salt    string            `json:"salt"`
+	Secret  string            `json:"secret"` // KeyFileSecretMachine or KeyFileSecretPassphrase
+	Check   string            `json:"check"`  // A sealed known value, to tell a wrong secret apart from a damaged key
+	Keys    map[string]string `json:"keys"`   // Sealed keys by keyring key name
+}
+
+// keyFileCheck is sealed into every key file to verify the secret
+const keyFileCheck = "go-reaper-key-file"
+
+// keyFileState holds the session's passphrase and the last derived key
+var keyFileState struct {
+	mutex      sync.Mutex
+	passphrase string
+	salt       string
+	secret     string
+	derived    []byte
+}
+
+// SetKeyFilePassphrase sets the passphrase the key file is encrypted with for this
+// session. It isn't stored anywhere.
+func SetKeyFilePassphrase(passphrase string) {
+	keyFileState.mutex.Lock()
+	defer keyFileState.mutex.Unlock()
+	keyFileState.passphrase = passphrase
+	keyFileState.derived = nil
+}
+
+// keyFilePath returns where the key file is kept. There is no fallback location: a
+// shared folder such as the temp directory would let others plant or read the file.
+func keyFilePath() (string, error) {
+	base, err := reaper.GetResourcePath()
+	if err != nil {
+		return "", fmt.Errorf("cannot locate the API key file: %v", err)
+	}
+	if base == "" {
+		return "", fmt.Errorf("cannot locate the API key file: REAPER's resource path is unknown")
+	}
+	return filepath.Join(base, "GoReaperExtension", keyFileName), nil
+}
+
+// getFileKey returns a key from the key file
+func getFileKey(name string) (string, error) {
+	data, err := readKeyFile()
+	if err != nil {
+		return "", err
+	}
+	sealed, ok := data.Keys[name]
+	if !ok {
+		return "", fmt.Errorf("no %s in the API key file", name)
+	}
+
+	aead, err := keyFileCipher(data)
+	if err != nil {
+		return "", err
+	}
+	value, err := openSealed(aead, sealed, name)
+	if err != nil {
+		return "", fmt.Errorf("could not decrypt %s: %v", name, err)
+	}
+	return value, nil
+}
+
+// setFileKey stores a key in the key file, creating the file with the configured
+// secret if there is none
+func setFileKey(name string, value string) error {
+	data, err := readKeyFile()
+	if errors.Is(err, os.ErrNotExist) {
+		data, err = newKeyFile(GetSettings().General.KeyFileSecret)
+	}
+	if err != nil {
+		return err
+	}
+
+	aead, err := keyFileCipher(data)
+	if err != nil {
+		return err
+	}
+	sealed, err := seal(aead, value, name)
+	if err != nil {
+		return err
+	}
+	data.Keys[name] = sealed
+	return writeKeyFile(data)
+}
+
+// CheckKeyFile checks the key file can be decrypted this session. It returns
+// ErrPassphraseRequired or ErrWrongPassphrase when a passphrase is needed.
+func CheckKeyFile() error {
+	data, err := readKeyFile()
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrNoKeyFile
+	}
+	if err != nil {
+		return err
+	}
+	_, err = keyFileCipher(data)
+	return err
+}
+
+// hasKeyFile reports whether there is a key file
+func hasKeyFile() (bool, error) {
+	path, err := keyFilePath()
+	if err != nil {
+		return false, err
+	}
+	_, err = os.Stat(path)
+	return err == nil, nil
+}
+
+// newKeyFile starts a key file encrypted with secret
+func newKeyFile(secret string) (*keyFileData, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	data := &keyFileData{
+		Version: 1,
+		Salt:    base64.StdEncoding.EncodeToString(salt),
+		Secret:  secret,
+		Keys:    make(map[string]string),
+	}
+
+	aead, err := keyFileCipher(data)
+	if err != nil {
+		return nil, err
+	}
+	if data.Check, err = seal(aead, keyFileCheck, "check"); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// readKeyFile reads the key file. The error wraps os.ErrNotExist if there is none.
+func readKeyFile() (*keyFileData, error) {
+	path, err := keyFilePath()
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var data keyFileData
+	if err := json.Unmarshal(content, &data); err != nil {
+		return nil, fmt.Errorf("the API key file is damaged: %v", err)
+	}
+	if data.Keys == nil {
+		data.Keys = make(map[string]string)
+	}
+	return &data, nil
+}
+
+// writeKeyFile saves the key file, readable by the user only
+func writeKeyFile(data *keyFileData) error {
+	content, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	path, err := keyFilePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// keyFileCipher returns the cipher for a key file, deriving its key from the file's
+// secret and salt, and checks the secret is right
+func keyFileCipher(data *keyFileData) (cipher.AEAD, error) {
+	key, err := deriveKeyFileKey(data.Secret, data.Salt)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	if data.Check != "" {
+		if check, err := openSealed(aead, data.Check, "check"); err != nil || check != keyFileCheck {
+			forgetDerivedKey()
+			return nil, ErrWrongPassphrase
+		}
+	}
+	return aead, nil
+}
+
+// deriveKeyFileKey derives the encryption key, reusing the last one for the same secret
+// and salt, since deriving is deliberately slow
+func deriveKeyFileKey(secret string, salt string) ([]byte, error) {
+	keyFileState.mutex.Lock()
+	defer keyFileState.mutex.Unlock()
+
+	if keyFileState.derived != nil && keyFileState.secret == secret && keyFileState.salt == salt {
+		return keyFileState.derived, nil
+	}
+
+	var password string
+	switch secret {
+	case KeyFileSecretPassphrase:
+		password = keyFileState.passphrase
+		if password == "" {
+			password = os.Getenv(KeyFilePassphraseEnv)
+		}
+		if password == "" {
+			return nil, ErrPassphraseRequired
+		}
+	default:
+		id, err := machineID()
+		if err != nil {
+			return nil, fmt.Errorf("could not identify this machine to encrypt API keys: %v", err)
+		}
+		password = id
+	}
+
+	saltBytes, err := base64.StdEncoding.DecodeString(salt)
+	if err != nil {
+		return nil, fmt.Errorf("the API key file is damaged: %v", err)
+	}
+	key, err := pbkdf2.Key(sha256.New, password, saltBytes, keyFileIterations, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	keyFileState.secret, keyFileState.salt, keyFileState.derived = secret, salt, key
+	return key, nil
+}
+
+// forgetDerivedKey drops the derived key, e.g. after a wrong passphrase
+func forgetDerivedKey() {
+	keyFileState.mutex.Lock()
+	defer keyFileState.mutex.Unlock()
+	keyFileState.derived = nil
+	if keyFileState.secret == KeyFileSecretPassphrase {
+		keyFileState.passphrase = ""
+	}
+}
+
+// seal encrypts value, bound to name so sealed values can't be swapped between keys
+func seal(aead cipher.AEAD, value string, name string) (string, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(value), []byte(name))
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// openSealed decrypts a value sealed under name
+func openSealed(aead cipher.AEAD, sealed string, name string) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return "", err
+	}
+	if len(raw) < aead.NonceSize() {
+		return "", fmt.Errorf("sealed value too short")
+	}
+	value, err := aead.Open(nil, raw[:aead.NonceSize()], raw[aead.NonceSize():], []byte(name))
+	if err != nil {
+		return "", err
+	}
+	return string(value), nil
+}
+
+// machineID returns an ID that stays the same for this machine
+func machineID() (string, error) {
+	switch runtime.GOOS {
+	case "linux":
+		for _, path := range []string{"/etc/machine-id", "/var/lib/dbus/machine-id"} {
+			if content, err := os.ReadFile(path); err == nil && strings.TrimSpace(string(content)) != "" {
+				return strings.TrimSpace(string(content)), nil
+			}
+		}
+	case "darwin":
+		out, err := exec.Command("ioreg", "-rd1", "-c", "IOPlatformExpertDevice").Output()
+		if err == nil {
+			if match := regexp.MustCompile(`"IOPlatformUUID" = "([^"]+)"`).FindSubmatch(out); match != nil {
+				return string(match[1]), nil
+			}
+		}
+	case "windows":
+		out, err := exec.Command("reg", "query", `HKLM\SOFTWARE\Microsoft\Cryptography`, "/v", "MachineGuid").Output()
+		if err == nil {
+			if match := regexp.MustCompile(`MachineGuid\s+REG_SZ\s+(\S+)`).FindSubmatch(out); match != nil {
+				return string(match[1]), nil
+			}
+		}
+	}
+
+	logger.Warning("No machine ID found, encrypting API keys with the host name")
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		return "", fmt.Errorf("no machine ID or host name")
+	}
+	return host, nil
+}
+
+// reencryptKeyFile encrypts the keys in the key file, if there is one, with a new salt
+// and secret
+func reencryptKeyFile(secret string) error {
+	data, err := readKeyFile()
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if data.Secret == secret {
+		return nil
+	}
+
+	oldCipher, err := keyFileCipher(data)
+	if err != nil {
+		return err
+	}
+	keys := make(map[string]string, len(data.Keys))
+	for name, sealed := range data.Keys {
+		value, err := openSealed(oldCipher, sealed, name)
+		if err != nil {
+			return fmt.Errorf("could not decrypt %s: %v", name, err)
+		}
+		keys[name] = value
+	}
+
+	newData, err := newKeyFile(secret)
+	if err != nil {
+		return err
+	}
+	newCipher, err := keyFileCipher(newData)
+	if err != nil {
+		return err
+	}
+	for name, value := range keys {
+		if newData.Keys[name], err = seal(newCipher, value, name); err != nil {
+			return err
+		}
+	}
+	return writeKeyFile(newData)
+}
@@ -62,3 +62,8 @@ func setPath(path string) {
 	defer C.free(unsafe.Pointer(cPath))
 	C.log_set_path(cPath)
 }
+
+// getPath returns the log file path
+func getPath() string {
+	return C.GoString(C.log_get_path())
+}
@@ -0,0 +1,66 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HistorySize is how many recent messages are kept in memory
+const HistorySize = 2000
+
+// Entry is a logged message kept in memory, e.g. for the log viewer
+type Entry struct {
+	Seq      uint64 // Numbers messages from 1 in the order they were logged
+	Time     time.Time
+	Level    int
+	FuncName string
+	Message  string
+}
+
+// String formats the entry like a line of the log file
+func (e Entry) String() string {
+	return fmt.Sprintf("%s [%s] %s: %s", e.Time.Format("2006-01-02 15:04:05.000"),
+		strings.ToUpper(LevelName(e.Level)), e.FuncName, e.Message)
+}
+
+// The last HistorySize messages, in a ring starting at historyStart once full
+var (
+	historyMutex sync.Mutex
+	history      = make([]Entry, 0, HistorySize)
+	historyStart int
+	historySeq   uint64
+)
+
+// remember adds a message to the history, dropping the oldest when it is full
+func remember(level int, funcName, message string) {
+	historyMutex.Lock()
+	defer historyMutex.Unlock()
+
+	historySeq++
+	entry := Entry{Seq: historySeq, Time: time.Now(), Level: level, FuncName: funcName, Message: message}
+	if len(history) < HistorySize {
+		history = append(history, entry)
+		return
+	}
+	history[historyStart] = entry
+	historyStart = (historyStart + 1) % HistorySize
+}
+
+// Recent returns the kept messages logged after the one numbered after, oldest first;
+// pass 0 for all of them. Info and more serious messages are always kept, as are
+// messages at the log file's level while file logging is enabled.
+func Recent(after uint64) []Entry {
+	historyMutex.Lock()
+	defer historyMutex.Unlock()
+
+	var entries []Entry
+	for i := range history {
+		entry := history[(historyStart+i)%len(history)]
+		if entry.Seq > after {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
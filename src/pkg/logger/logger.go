@@ -4,6 +4,8 @@ package logger
 import (
 	"fmt"
 	"runtime"
+	"strings"
+	"sync"
 )
 
 // Log level constants
@@ -15,6 +17,46 @@ const (
 	LevelTrace
 )
 
+// levelNames are the level names, as used by REAPER_GO_LOG_LEVEL
+var levelNames = []string{"error", "warning", "info", "debug", "trace"}
+
+// LevelName returns the name of a log level, e.g. "debug"
+func LevelName(level int) string {
+	if level < LevelError || level > LevelTrace {
+		return "unknown"
+	}
+	return levelNames[level]
+}
+
+// ParseLevel parses a level name such as "warning", ignoring case
+func ParseLevel(name string) (int, bool) {
+	for level, levelName := range levelNames {
+		if strings.EqualFold(name, levelName) {
+			return level, true
+		}
+	}
+	return 0, false
+}
+
+// The mirror receives every message up to mirrorLevel, whether or not file logging is
+// enabled
+var (
+	mirrorMutex sync.RWMutex
+	mirror      func(level int, funcName, message string)
+	mirrorLevel int
+)
+
+// SetMirror sends a copy of every message up to level to fn, e.g. to show log output
+// in REAPER's console. fn may be called from any goroutine and must not log. A nil fn
+// stops mirroring.
+func SetMirror(level int, fn func(level int, funcName, message string)) {
+	mirrorMutex.Lock()
+	defer mirrorMutex.Unlock()
+
+	mirror = fn
+	mirrorLevel = level
+}
+
 // Error logs an error message
 func Error(format string, args ...interface{}) {
 	logMessage(LevelError, format, args...)
@@ -67,6 +109,11 @@ func SetLogPath(path string) {
 	setPath(path)
 }
 
+// GetLogPath returns the path of the log file
+func GetLogPath() string {
+	return getPath()
+}
+
 // Initialize initializes the logging system
 func Initialize() {
 	initLogging()
@@ -79,8 +126,18 @@ func Cleanup() {
 
 // logMessage is the internal function for all logging levels
 func logMessage(level int, format string, args ...interface{}) {
-	// Skip logging if disabled or level is too verbose
-	if !IsLoggingEnabled() || GetLogLevel() < level {
+	mirrorMutex.RLock()
+	mirrorFunc := mirror
+	if level > mirrorLevel {
+		mirrorFunc = nil
+	}
+	mirrorMutex.RUnlock()
+
+	// Skip logging if disabled or level is too verbose, unless the message is mirrored.
+	// The history keeps what the file does, and always info and more serious messages.
+	toFile := IsLoggingEnabled() && GetLogLevel() >= level
+	toHistory := toFile || level <= LevelInfo
+	if !toHistory && mirrorFunc == nil {
 		return
 	}
 
@@ -108,7 +165,15 @@ func logMessage(level int, format string, args ...interface{}) {
 	}
 
 	// Send to the C logging system
-	cLogMessage(level, funcName, message)
+	if toFile {
+		cLogMessage(level, funcName, message)
+	}
+	if toHistory {
+		remember(level, funcName, message)
+	}
+	if mirrorFunc != nil {
+		mirrorFunc(level, funcName, message)
+	}
 }
 
 // last finds the last occurrence of a character in a string
@@ -0,0 +1,185 @@
+package update
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"go-reaper/src/pkg/version"
+	"io"
+	"net/http"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// The release feed is a ReaPack repository index, so one index.xml serves both ReaPack
+// users and the extension's own update check. The index lists packages in categories;
+// the extension is a package of type "extension" with a version element per release,
+// each with a source per platform:
+//
+//	<index version="1" name="Go REAPER Extension">
+//	  <category name="Extensions">
+//	    <reapack name="reaper_go_extension.ext" type="extension" desc="Go REAPER Extension">
+//	      <version name="1.2.0" time="2026-01-31T12:00:00Z">
+//	        <changelog><![CDATA[Fixes ...]]></changelog>
+//	        <source platform="darwin-arm64" file="reaper_go_extension.dylib">https://...</source>
+//	      </version>
+//	    </reapack>
+//	  </category>
+//	</index>
+
+// maxFeedSize limits how much of a feed is read
+const maxFeedSize = 4 << 20
+
+// Release is one version of the extension listed in a feed
+type Release struct {
+	Version   string
+	Time      time.Time // Zero if the feed doesn't say
+	Changelog string
+	Sources   []Source
+}
+
+// Source is a file a release ships for a platform
+type Source struct {
+	Platform string // ReaPack platform name, e.g. "darwin-arm64", "win64" or "all"
+	File     string // File name to install it as; empty to use the URL's
+	URL      string
+	Hash     string // ReaPack multihash in hex, e.g. "1220" and a SHA-256; empty if none
+}
+
+// index is the part of a ReaPack index the update check reads
+type index struct {
+	Categories []struct {
+		Packages []feedPackage `xml:"reapack"`
+	} `xml:"category"`
+}
+
+type feedPackage struct {
+	Name     string `xml:"name,attr"`
+	Type     string `xml:"type,attr"`
+	Versions []struct {
+		Name      string `xml:"name,attr"`
+		Time      string `xml:"time,attr"`
+		Changelog string `xml:"changelog"`
+		Sources   []struct {
+			Platform string `xml:"platform,attr"`
+			File     string `xml:"file,attr"`
+			Hash     string `xml:"hash,attr"`
+			URL      string `xml:",chardata"`
+		} `xml:"source"`
+	} `xml:"version"`
+}
+
+// ParseFeed reads a ReaPack index and returns the newest release of the package named
+// packageName, or of its first extension package if packageName is empty
+func ParseFeed(r io.Reader, packageName string) (Release, error) {
+	var feed index
+	if err := xml.NewDecoder(io.LimitReader(r, maxFeedSize)).Decode(&feed); err != nil {
+		return Release{}, fmt.Errorf("not a ReaPack index: %w", err)
+	}
+
+	for _, category := range feed.Categories {
+		for _, pkg := range category.Packages {
+			if packageName != "" && pkg.Name != packageName {
+				continue
+			}
+			if packageName == "" && pkg.Type != "extension" {
+				continue
+			}
+			return newestRelease(pkg)
+		}
+	}
+
+	if packageName != "" {
+		return Release{}, fmt.Errorf("the feed has no package named %q", packageName)
+	}
+	return Release{}, fmt.Errorf("the feed has no extension package")
+}
+
+// newestRelease picks the highest version of a package
+func newestRelease(pkg feedPackage) (Release, error) {
+	if len(pkg.Versions) == 0 {
+		return Release{}, fmt.Errorf("the feed lists no versions of %s", pkg.Name)
+	}
+
+	newest := pkg.Versions[0]
+	for _, v := range pkg.Versions[1:] {
+		if version.Compare(v.Name, newest.Name) > 0 {
+			newest = v
+		}
+	}
+
+	release := Release{Version: newest.Name, Changelog: strings.TrimSpace(newest.Changelog)}
+	if t, err := time.Parse(time.RFC3339, newest.Time); err == nil {
+		release.Time = t
+	}
+	for _, source := range newest.Sources {
+		release.Sources = append(release.Sources, Source{
+			Platform: source.Platform,
+			File:     source.File,
+			URL:      strings.TrimSpace(source.URL),
+			Hash:     strings.ToLower(strings.TrimSpace(source.Hash)),
+		})
+	}
+	return release, nil
+}
+
+// Platform returns ReaPack's name for the platform the extension runs on
+func Platform() string {
+	switch runtime.GOOS + "/" + runtime.GOARCH {
+	case "darwin/arm64":
+		return "darwin-arm64"
+	case "darwin/amd64":
+		return "darwin64"
+	case "windows/amd64":
+		return "win64"
+	case "windows/386":
+		return "win32"
+	case "linux/amd64":
+		return "linux64"
+	case "linux/arm64":
+		return "linux-aarch64"
+	}
+	return runtime.GOOS
+}
+
+// platformFamily is the ReaPack name covering all architectures of an OS
+func platformFamily() string {
+	if runtime.GOOS == "windows" {
+		return "windows"
+	}
+	return runtime.GOOS
+}
+
+// SourceFor returns the release's file for this platform, preferring one built for its
+// architecture over one for the whole OS or for all platforms
+func (r Release) SourceFor(platform string) (Source, bool) {
+	for _, wanted := range []string{platform, platformFamily(), "all"} {
+		for _, source := range r.Sources {
+			if source.Platform == wanted {
+				return source, true
+			}
+		}
+	}
+	return Source{}, false
+}
+
+// Fetch downloads a feed and returns its newest release of packageName
+func Fetch(ctx context.Context, client *http.Client, url, packageName string) (Release, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return Release{}, fmt.Errorf("invalid feed URL %q: %w", url, err)
+	}
+	request.Header.Set("User-Agent", strings.ReplaceAll(version.Name, " ", "-")+"/"+version.Version)
+
+	response, err := client.Do(request)
+	if err != nil {
+		return Release{}, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return Release{}, fmt.Errorf("the feed returned %s", response.Status)
+	}
+	return ParseFeed(response.Body, packageName)
+}
@@ -0,0 +1,158 @@
+package update
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go-reaper/src/pkg/version"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Installing an update downloads the release's file next to the running library, checks
+// it against the hash in the feed, then puts it in the library's place. REAPER keeps
+// using the library it loaded until it restarts. On macOS and Linux the new file simply
+// replaces the old one, which stays mapped while in use; Windows won't replace a loaded
+// DLL, so it is moved aside first and deleted at the next load.
+
+// maxDownloadSize limits how large a release file may be
+const maxDownloadSize = 256 << 20
+
+// sha256Multihash starts a ReaPack hash: the multihash code for SHA-256 and its length
+const sha256Multihash = "1220"
+
+// replacedSuffix is added to a library moved aside on Windows
+const replacedSuffix = ".old"
+
+// ErrNoHash is returned for sources the feed gives no hash for, which aren't installed
+// since the download couldn't be checked
+var ErrNoHash = errors.New("the release gives no hash to check the download against")
+
+// Download fetches source into a temporary file in dir, checking its size and hash, and
+// returns the file's path. The file is removed if anything fails.
+func Download(ctx context.Context, client *http.Client, source Source, dir string) (_ string, err error) {
+	want, err := parseHash(source.Hash)
+	if err != nil {
+		return "", err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, source.URL, nil)
+	if err != nil {
+		return "", fmt.Errorf("invalid download URL %q: %w", source.URL, err)
+	}
+	request.Header.Set("User-Agent", strings.ReplaceAll(version.Name, " ", "-")+"/"+version.Version)
+
+	response, err := client.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("the download returned %s", response.Status)
+	}
+	if response.ContentLength > maxDownloadSize {
+		return "", fmt.Errorf("the download is %d MB, more than the %d MB allowed", response.ContentLength>>20, maxDownloadSize>>20)
+	}
+
+	file, err := os.CreateTemp(dir, ".update-*.download")
+	if err != nil {
+		return "", err
+	}
+	path := file.Name()
+	defer func() {
+		if err != nil {
+			file.Close()
+			os.Remove(path)
+		}
+	}()
+
+	hash := sha256.New()
+	written, err := io.Copy(io.MultiWriter(file, hash), io.LimitReader(response.Body, maxDownloadSize+1))
+	if err != nil {
+		return "", fmt.Errorf("download interrupted: %w", err)
+	}
+	if written > maxDownloadSize {
+		return "", fmt.Errorf("the download is more than the %d MB allowed", maxDownloadSize>>20)
+	}
+	if got := hash.Sum(nil); !bytes.Equal(got, want) {
+		return "", fmt.Errorf("the download's SHA-256 is %x, but the feed gives %x; it was not installed", got, want)
+	}
+
+	if err = file.Close(); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// parseHash reads a ReaPack multihash, or a bare SHA-256, as hex
+func parseHash(hash string) ([]byte, error) {
+	hash = strings.ToLower(strings.TrimSpace(hash))
+	if hash == "" {
+		return nil, ErrNoHash
+	}
+	if len(hash) == len(sha256Multihash)+2*sha256.Size && strings.HasPrefix(hash, sha256Multihash) {
+		hash = hash[len(sha256Multihash):]
+	}
+
+	digest, err := hex.DecodeString(hash)
+	if err != nil || len(digest) != sha256.Size {
+		return nil, fmt.Errorf("unsupported hash %q: only SHA-256 is checked", hash)
+	}
+	return digest, nil
+}
+
+// Install puts a downloaded file in target's place
+func Install(downloaded, target string) error {
+	if err := os.Chmod(downloaded, 0o755); err != nil {
+		return err
+	}
+
+	if runtime.GOOS != "windows" {
+		return os.Rename(downloaded, target)
+	}
+
+	// A loaded DLL can be renamed but not replaced
+	replaced := target + replacedSuffix
+	os.Remove(replaced)
+	if err := os.Rename(target, replaced); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("could not move the running library aside: %w", err)
+	}
+	if err := os.Rename(downloaded, target); err != nil {
+		os.Rename(replaced, target)
+		return err
+	}
+	return nil
+}
+
+// RemoveReplaced deletes the library Install moved aside for target, if there is one
+func RemoveReplaced(target string) error {
+	err := os.Remove(target + replacedSuffix)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// InstallPath returns where a source is installed: in place of the running library
+// when its path is known, otherwise in dir under the source's file name
+func InstallPath(source Source, running, dir string) string {
+	if running != "" {
+		return running
+	}
+
+	name := source.File
+	if name == "" {
+		name = source.URL[strings.LastIndex(source.URL, "/")+1:]
+		if i := strings.IndexAny(name, "?#"); i >= 0 {
+			name = name[:i]
+		}
+	}
+	return filepath.Join(dir, filepath.Base(name))
+}
@@ -0,0 +1,153 @@
+package version
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The version and build details are set when building, e.g.
+//
+//	go build -ldflags "-X go-reaper/src/pkg/version.Version=1.2.0"
+//
+// which the Makefile does from git. Builds without them, e.g. go build on its own, are
+// "dev" builds and take the commit from Go's VCS stamp where there is one.
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildDate = "" // RFC 3339
+)
+
+// Name is the extension's name as it is listed to users
+const Name = "Go REAPER Extension"
+
+// Info describes the running build
+type Info struct {
+	Version   string
+	Commit    string // Abbreviated; empty if unknown
+	Modified  bool   // Built from a working tree with uncommitted changes
+	BuildDate time.Time
+	GoVersion string
+	Platform  string // GOOS/GOARCH
+}
+
+// commitLength is how many characters of a commit hash are shown
+const commitLength = 7
+
+// Get returns the running build's details
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	if date, err := time.Parse(time.RFC3339, BuildDate); err == nil {
+		info.BuildDate = date
+	}
+
+	// Fall back to the VCS stamp go build adds in a git checkout
+	if build, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range build.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = setting.Value
+				}
+			case "vcs.time":
+				if date, err := time.Parse(time.RFC3339, setting.Value); err == nil && info.BuildDate.IsZero() {
+					info.BuildDate = date
+				}
+			case "vcs.modified":
+				info.Modified = setting.Value == "true"
+			}
+		}
+	}
+	if len(info.Commit) > commitLength {
+		info.Commit = info.Commit[:commitLength]
+	}
+	return info
+}
+
+// IsDevelopment reports whether this is a build without a release version
+func (i Info) IsDevelopment() bool {
+	return i.Version == "" || i.Version == "dev"
+}
+
+// String describes the build in one line, e.g. "1.2.0 (abc1234, built 2026-01-31)"
+func (i Info) String() string {
+	var details []string
+	if i.Commit != "" {
+		commit := i.Commit
+		if i.Modified {
+			commit += "-modified"
+		}
+		details = append(details, commit)
+	}
+	if !i.BuildDate.IsZero() {
+		details = append(details, "built "+i.BuildDate.Format("2006-01-02"))
+	}
+
+	if len(details) == 0 {
+		return i.Version
+	}
+	return fmt.Sprintf("%s (%s)", i.Version, strings.Join(details, ", "))
+}
+
+// Compare orders two version names the way ReaPack does: numbers in each dot-separated
+// segment compare as numbers, so 1.10 is newer than 1.9, and a pre-release such as
+// 1.2.0-beta or 1.2.0rc1 is older than 1.2.0. A leading "v" is ignored. It returns -1
+// if a is older than b, 1 if newer and 0 if they are the same.
+func Compare(a, b string) int {
+	aNumbers, aSuffix := splitVersion(a)
+	bNumbers, bSuffix := splitVersion(b)
+
+	for i := 0; i < max(len(aNumbers), len(bNumbers)); i++ {
+		var x, y int
+		if i < len(aNumbers) {
+			x = aNumbers[i]
+		}
+		if i < len(bNumbers) {
+			y = bNumbers[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	switch {
+	case aSuffix == bSuffix:
+		return 0
+	case aSuffix == "":
+		return 1
+	case bSuffix == "":
+		return -1
+	}
+	return strings.Compare(aSuffix, bSuffix)
+}
+
+// splitVersion returns a version's leading numbers and the pre-release text after them
+func splitVersion(name string) (numbers []int, suffix string) {
+	name = strings.TrimPrefix(strings.TrimSpace(name), "v")
+
+	for _, segment := range strings.Split(name, ".") {
+		digits := 0
+		for digits < len(segment) && segment[digits] >= '0' && segment[digits] <= '9' {
+			digits++
+		}
+		number, _ := strconv.Atoi(segment[:digits])
+		numbers = append(numbers, number)
+
+		if digits < len(segment) {
+			suffix = strings.TrimLeft(segment[digits:], "-+_")
+			break
+		}
+	}
+	return numbers, suffix
+}
@@ -16,6 +16,7 @@ typedef struct {
 // Forward declaration of the Go callback function
 extern int goHookCommandProc(int commandId, int flag);
 extern int goHookCommandProc2(void* section, int commandId, int val, int valhw, int relmode, void* hwnd, void* proj);
+extern int goToggleActionProc(int commandId);
 */
 import "C"
 import (
@@ -27,82 +28,151 @@ import (
 var (
 	// Track registered command IDs
 	registeredCommands map[string]int
+	// Map command IDs back to action IDs for the command hooks
+	commandActions map[int]string
 	// Store a map of action handlers
-	actionHandlers map[string]ActionHandler
+	actionHandlers map[string]ContextActionHandler
+	// Store a map of toggle state providers for toggle actions
+	toggleStates map[string]func() bool
 )
 
 func init() {
 	registeredCommands = make(map[string]int)
+	commandActions = make(map[int]string)
 }
 
 // Initialize action handlers map
 func initActionHandlers() {
-	actionHandlers = make(map[string]ActionHandler)
+	actionHandlers = make(map[string]ContextActionHandler)
+	toggleStates = make(map[string]func() bool)
 }
 
 // SetActionHandler associates a function with an action ID
 func SetActionHandler(actionID string, handler ActionHandler) {
+	SetContextActionHandler(actionID, func(ActionContext) { handler() })
+}
+
+// SetContextActionHandler associates a function that receives the trigger context with
+// an action ID
+func SetContextActionHandler(actionID string, handler ContextActionHandler) {
 	mutex.Lock()
 	defer mutex.Unlock()
 
 	actionHandlers[actionID] = handler
 }
 
+// SetToggleStateProvider makes an action a toggle action whose on/off state is reported
+// by state, e.g. for toolbar buttons
+func SetToggleStateProvider(actionID string, state func() bool) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	toggleStates[actionID] = state
+}
+
+// GetCommandID returns the command ID REAPER assigned to one of our actions, for running
+// it with RunCommand
+func GetCommandID(actionID string) (int, bool) {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	commandID, ok := registeredCommands[actionID]
+	return commandID, ok
+}
+
+// actionForCommand returns the action registered under a command ID. It is called for
+// every action REAPER runs, so it must stay a map lookup.
+func actionForCommand(commandID int) (string, bool) {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	actionID, ok := commandActions[commandID]
+	return actionID, ok
+}
+
+// runCommand runs the handler for one of our commands. It returns 1 if the command is
+// ours, so REAPER stops looking for a handler, or 0 otherwise.
+func runCommand(ctx ActionContext, hook string) C.int {
+	actionID, ok := actionForCommand(ctx.CommandID)
+	if !ok {
+		return 0 // Not our command, let REAPER handle it
+	}
+
+	logger.Info("GoReaper action triggered! Command ID: %d (%s) (via %s)", ctx.CommandID, actionID, hook)
+
+	mutex.RLock()
+	handler, exists := actionHandlers[actionID]
+	mutex.RUnlock()
+
+	if exists {
+		callHandler(actionID, handler, ctx)
+	}
+	return 1
+}
+
+// callHandler runs an action handler, recovering a panic so the command still counts as
+// handled
+func callHandler(actionID string, handler ContextActionHandler, ctx ActionContext) {
+	defer Recover("action " + actionID)
+	handler(ctx)
+}
+
 // Command callback handlers
 //
 //export goHookCommandProc
 func goHookCommandProc(commandId C.int, flag C.int) C.int {
-	// Check if this is one of our registered commands
-	for actionID, cmdID := range registeredCommands {
-		if int(commandId) == cmdID {
-			// Log that the command was triggered
-			logger.Info("GoReaper action triggered! Command ID: %d (%s)", int(commandId), actionID)
-
-			// Check if we have a handler for this action
-			mutex.RLock()
-			handler, exists := actionHandlers[actionID]
-			mutex.RUnlock()
-
-			if exists {
-				// Execute the handler
-				handler()
-			}
-
-			return 1 // Return 1 to indicate we handled it
-		}
-	}
-	return 0 // Not our command, let REAPER handle it
+	defer Recover("goHookCommandProc")
+	return runCommand(ActionContext{SectionID: SectionMain, CommandID: int(commandId), ValueHW: -1}, "hookcommand")
 }
 
 //export goHookCommandProc2
 func goHookCommandProc2(section unsafe.Pointer, commandId C.int, val C.int, valhw C.int, relmode C.int, hwnd unsafe.Pointer, proj unsafe.Pointer) C.int {
+	defer Recover("goHookCommandProc2")
+
 	// Called by REAPER when an action is triggered. Return 1 if handled, 0 to pass to other plugins.
 	// commandId: unique identifier for the action
 	// val, valhw: action parameters that may contain state information
 	// relmode: relative mouse mode (0=absolute, 1/2=relative from last value)
 	// hwnd: window handle
-	// proj: project context"
-
-	// Similar to hookCommandProc, check if this is one of our commands
-	for actionID, cmdID := range registeredCommands {
-		if int(commandId) == cmdID {
-			// Command was triggered - log it to console
-			logger.Info("GoReaper action triggered! Command ID: %d (%s) (via hookcommand2)", int(commandId), actionID)
-
-			// Check if we have a handler for this action
-			mutex.RLock()
-			handler, exists := actionHandlers[actionID]
-			mutex.RUnlock()
-
-			if exists {
-				// Execute the handler
-				handler()
-			}
-
-			return 1 // Return 1 to indicate we handled it
-		}
+	// proj: project context
+	ctx := ActionContext{
+		CommandID: int(commandId),
+		Value:     int(val),
+		ValueHW:   int(valhw),
+		RelMode:   int(relmode),
+		HWND:      hwnd,
+		Project:   proj,
+	}
+	// section is a KbdSectionInfo, which starts with the section's unique ID
+	if section != nil {
+		ctx.SectionID = int(*(*C.int)(section))
+	}
+	return runCommand(ctx, "hookcommand2")
+}
+
+// Toggle state callback handler
+//
+//export goToggleActionProc
+func goToggleActionProc(commandId C.int) C.int {
+	defer Recover("goToggleActionProc")
+
+	// Called by REAPER to show toggle state. Return -1 if not a toggle action, 0 for off, 1 for on.
+	actionID, ok := actionForCommand(int(commandId))
+	if !ok {
+		return -1 // Not our command
+	}
+
+	mutex.RLock()
+	state, exists := toggleStates[actionID]
+	mutex.RUnlock()
+
+	if !exists {
+		return -1
+	}
+	if state() {
+		return 1
 	}
-	return 0 // Not our command, let REAPER handle it
+	return 0
 }
 
 // RegisterCustomAction uses a two-step registration process: first register a command ID, then register the custom
@@ -130,6 +200,7 @@ func RegisterCustomAction(actionID string, description string, sectionID int) (i
 
 	// Store command ID for lookup in hook handlers
 	registeredCommands[actionID] = cmdID
+	commandActions[cmdID] = actionID
 
 	// 2. Now register the custom action with more details
 	cDesc := C.CString(description)
@@ -63,19 +63,120 @@ func Initialize(info unsafe.Pointer) error {
 
 	// Clear registered commands map
 	registeredCommands = make(map[string]int)
+	commandActions = make(map[int]string)
 
 	// Initialize action handlers map
 	initActionHandlers()
 
-	// Register command hooks
-	cHookCmd2 := C.CString("hookcommand2")
-	defer C.free(unsafe.Pointer(cHookCmd2))
-	C.plugin_bridge_call_register(registerFuncPtr, cHookCmd2, unsafe.Pointer(C.goHookCommandProc2))
+	// Clear menu hooks
+	menuHooks = make(map[string][]MenuHook)
 
-	cHookCmd := C.CString("hookcommand")
-	defer C.free(unsafe.Pointer(cHookCmd))
-	C.plugin_bridge_call_register(registerFuncPtr, cHookCmd, unsafe.Pointer(C.goHookCommandProc))
+	// Drop work deferred before a reload
+	deferredMutex.Lock()
+	deferred = nil
+	deferredMutex.Unlock()
+
+	// Register the hooks REAPER calls back into
+	for _, hook := range pluginHooks() {
+		cName := C.CString(hook.name)
+		C.plugin_bridge_call_register(registerFuncPtr, cName, hook.fn)
+		C.free(unsafe.Pointer(cName))
+	}
 
 	initialized = true
 	return nil
 }
+
+// pluginHook is a callback registered with REAPER under a name
+type pluginHook struct {
+	name string
+	fn   unsafe.Pointer
+}
+
+// pluginHooks lists the callbacks Initialize registers and Teardown unregisters
+func pluginHooks() []pluginHook {
+	return []pluginHook{
+		{"hookcommand2", unsafe.Pointer(C.goHookCommandProc2)}, // Command hooks
+		{"hookcommand", unsafe.Pointer(C.goHookCommandProc)},
+		{"toggleaction", unsafe.Pointer(C.goToggleActionProc)}, // Toggle states
+		{"hookcustommenu", unsafe.Pointer(C.goHookCustomMenu)}, // Custom menus
+		{"timer", unsafe.Pointer(C.goTimerProc)},               // Deferred work
+	}
+}
+
+// VersionFunction is the name ReaScripts call to read the extension's version
+const VersionFunction = "GoReaperExtension_GetVersion"
+
+// apiFunctions are the functions exported to ReaScripts, unregistered by Teardown
+var apiFunctions []pluginHook
+
+// RegisterVersion exports version to ReaScripts and other extensions as
+// GoReaperExtension_GetVersion, which also lists it in REAPER's ReaScript documentation
+// among the functions extensions provide
+func RegisterVersion(version string) error {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if !initialized {
+		return fmt.Errorf("REAPER functions not initialized")
+	}
+
+	cVersion := C.CString(version)
+	C.plugin_bridge_set_version(cVersion)
+	C.free(unsafe.Pointer(cVersion))
+
+	if len(apiFunctions) > 0 {
+		return nil
+	}
+	entries := []pluginHook{
+		{"API_" + VersionFunction, C.plugin_bridge_version_func()},
+		{"APIvararg_" + VersionFunction, C.plugin_bridge_version_vararg()},
+		{"APIdef_" + VersionFunction, unsafe.Pointer(C.plugin_bridge_version_def())},
+	}
+	for _, entry := range entries {
+		cName := C.CString(entry.name)
+		result := C.plugin_bridge_call_register(registerFuncPtr, cName, entry.fn)
+		C.free(unsafe.Pointer(cName))
+		if result == 0 {
+			return fmt.Errorf("REAPER refused to register %s", entry.name)
+		}
+		apiFunctions = append(apiFunctions, entry)
+	}
+	return nil
+}
+
+// Teardown undoes Initialize when the plugin unloads: it unregisters the hooks and the
+// exported functions, so REAPER doesn't call into a library it has unloaded, drops
+// deferred work and forgets the function pointers and caches. Actions keep their
+// command IDs, which REAPER gives back when they're registered again. Call it on the
+// main thread, after everything that uses REAPER has shut down.
+func Teardown() {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if !initialized {
+		return
+	}
+
+	for _, hook := range append(pluginHooks(), apiFunctions...) {
+		cName := C.CString("-" + hook.name)
+		C.plugin_bridge_call_register(registerFuncPtr, cName, hook.fn)
+		C.free(unsafe.Pointer(cName))
+	}
+	apiFunctions = nil
+
+	deferredMutex.Lock()
+	deferred = nil
+	deferredMutex.Unlock()
+
+	registeredCommands = make(map[string]int)
+	commandActions = make(map[int]string)
+	initActionHandlers()
+	menuHooks = make(map[string][]MenuHook)
+	ClearParamCache()
+
+	showConsoleMsgPtr = nil
+	registerFuncPtr = nil
+	C.plugin_bridge_reset()
+	initialized = false
+}
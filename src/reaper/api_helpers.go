@@ -7,33 +7,23 @@ package reaper
 */
 import "C"
 import (
+	"fmt"
 	"go-reaper/src/pkg/logger"
 	"unsafe"
 )
 
 // ListAvailableFunctions checks if specific REAPER functions exist and logs the results
 func ListAvailableFunctions(functionNames []string) {
-	if !initialized {
-		logger.Error("REAPER functions not initialized")
-		return
-	}
-
 	logger.Debug("Checking for available functions:")
 
-	getFuncPtr := C.plugin_bridge_get_get_func()
-	if getFuncPtr == nil {
-		logger.Error("Error: GetFunc function pointer is nil")
-		return
-	}
-
 	for _, name := range functionNames {
-		cFuncName := C.CString(name)
-		funcPtr := C.plugin_bridge_call_get_func(getFuncPtr, cFuncName)
-		C.free(unsafe.Pointer(cFuncName))
-
-		if funcPtr != nil {
+		funcPtr, err := lookupFunc(name)
+		switch {
+		case err != nil:
+			logger.Error("- %s: %v", name, err)
+		case funcPtr != nil:
 			logger.Debug("- %s: Available", name)
-		} else {
+		default:
 			logger.Warning("- %s: Not found", name)
 		}
 	}
@@ -41,58 +31,57 @@ func ListAvailableFunctions(functionNames []string) {
 
 // IsFunctionAvailable checks if a specific REAPER function exists
 func IsFunctionAvailable(functionName string) bool {
-	if !initialized {
-		return false
-	}
+	funcPtr, err := lookupFunc(functionName)
+	return err == nil && funcPtr != nil
+}
 
-	getFuncPtr := C.plugin_bridge_get_get_func()
-	if getFuncPtr == nil {
-		return false
+// GetFunctionPointer returns a pointer to a REAPER function if available
+func GetFunctionPointer(functionName string) unsafe.Pointer {
+	funcPtr, _ := lookupFunc(functionName)
+	return funcPtr
+}
+
+// GetResourcePath returns REAPER's resource directory (where reaper.ini and UserPlugins live)
+func GetResourcePath() (string, error) {
+	getFuncPtr, err := projectFunc("GetResourcePath")
+	if err != nil {
+		return "", err
 	}
 
-	cFuncName := C.CString(functionName)
-	defer C.free(unsafe.Pointer(cFuncName))
+	result := C.plugin_bridge_call_get_resource_path(getFuncPtr)
+	if result == nil {
+		return "", fmt.Errorf("GetResourcePath returned no path")
+	}
 
-	funcPtr := C.plugin_bridge_call_get_func(getFuncPtr, cFuncName)
-	return funcPtr != nil
+	return C.GoString(result), nil
 }
 
-// GetFunctionPointer returns a pointer to a REAPER function if available
-func GetFunctionPointer(functionName string) unsafe.Pointer {
-	if !initialized {
-		return nil
+// GetAppVersion returns REAPER's version and platform, e.g. "7.22/macOS-arm64"
+func GetAppVersion() (string, error) {
+	funcPtr, err := projectFunc("GetAppVersion")
+	if err != nil {
+		return "", err
 	}
 
-	getFuncPtr := C.plugin_bridge_get_get_func()
-	if getFuncPtr == nil {
-		return nil
+	result := C.plugin_bridge_call_get_app_version(funcPtr)
+	if result == nil {
+		return "", fmt.Errorf("GetAppVersion returned no version")
 	}
 
-	cFuncName := C.CString(functionName)
-	defer C.free(unsafe.Pointer(cFuncName))
-
-	return C.plugin_bridge_call_get_func(getFuncPtr, cFuncName)
+	return C.GoString(result), nil
 }
 
-// ReaperConsoleLog sends a message directly to the REAPER console without our package's initialization check
-// This is useful for debugging when the main initialization may have failed
-func ReaperConsoleLog(message string) {
-	cFuncName := C.CString("ShowConsoleMsg")
-	defer C.free(unsafe.Pointer(cFuncName))
+// modulePathSize is the longest plugin path ModulePath reads
+const modulePathSize = 4096
 
-	getFuncPtr := C.plugin_bridge_get_get_func()
-	if getFuncPtr == nil {
-		// Can't log as we have no way to access REAPER functions
-		return
-	}
+// ModulePath returns the path of the extension's own library, e.g. the dylib REAPER
+// loaded from UserPlugins
+func ModulePath() (string, error) {
+	buf := getBuffer(modulePathSize)
+	defer buf.release()
 
-	showConsoleMsgPtr := C.plugin_bridge_call_get_func(getFuncPtr, cFuncName)
-	if showConsoleMsgPtr == nil {
-		// Can't log as ShowConsoleMsg is not available
-		return
+	if !bool(C.plugin_bridge_module_path(buf.ptr, buf.cSize())) {
+		return "", fmt.Errorf("the extension's library path is unknown")
 	}
-
-	cMessage := C.CString(message + "\n")
-	defer C.free(unsafe.Pointer(cMessage))
-	C.plugin_bridge_call_show_console_msg(showConsoleMsgPtr, cMessage)
+	return buf.String(), nil
 }
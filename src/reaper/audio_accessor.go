@@ -0,0 +1,115 @@
+package reaper
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../c -I${SRCDIR}/../../sdk
+#include "../c/bridge.h"
+*/
+import "C"
+import (
+	"fmt"
+	"unsafe"
+)
+
+// Audio accessors read a track's audio without playing it: the sum of its items, with
+// take FX but before the track's own FX. REAPER resamples to the rate asked for. They
+// must be created, read and destroyed on the main thread.
+
+// audioBlockFrames is how many frames ReadAudio reads per call
+const audioBlockFrames = 4096
+
+// AudioAccessor reads the audio of a track. Close it when done.
+type AudioAccessor struct {
+	ptr   unsafe.Pointer
+	Start float64 // Earliest audio, in project time
+	End   float64 // Latest audio, in project time
+}
+
+// NewTrackAudioAccessor creates an accessor for a track's audio
+func NewTrackAudioAccessor(track unsafe.Pointer) (*AudioAccessor, error) {
+	if track == nil {
+		return nil, fmt.Errorf("invalid track")
+	}
+
+	createFunc, err := projectFunc("CreateTrackAudioAccessor")
+	if err != nil {
+		return nil, err
+	}
+	startFunc, err := projectFunc("GetAudioAccessorStartTime")
+	if err != nil {
+		return nil, err
+	}
+	endFunc, err := projectFunc("GetAudioAccessorEndTime")
+	if err != nil {
+		return nil, err
+	}
+
+	ptr := C.plugin_bridge_call_create_track_audio_accessor(createFunc, track)
+	if ptr == nil {
+		return nil, fmt.Errorf("failed to create an audio accessor")
+	}
+	return &AudioAccessor{
+		ptr:   ptr,
+		Start: float64(C.plugin_bridge_call_get_audio_accessor_start_time(startFunc, ptr)),
+		End:   float64(C.plugin_bridge_call_get_audio_accessor_end_time(endFunc, ptr)),
+	}, nil
+}
+
+// Close destroys the accessor. It is safe to call more than once.
+func (a *AudioAccessor) Close() {
+	if a.ptr == nil {
+		return
+	}
+	funcPtr, err := projectFunc("DestroyAudioAccessor")
+	if err != nil {
+		return
+	}
+	C.plugin_bridge_call_destroy_audio_accessor(funcPtr, a.ptr)
+	a.ptr = nil
+}
+
+// ReadAudio reads the audio from start to end in project time, in blocks of interleaved
+// samples with channels samples per frame, and passes each to fn. Blocks are only valid
+// during the call. Silent blocks are passed as zeros.
+func (a *AudioAccessor) ReadAudio(sampleRate, channels int, start, end float64, fn func(block []float64) error) error {
+	if a.ptr == nil {
+		return fmt.Errorf("the audio accessor is closed")
+	}
+	if sampleRate <= 0 || channels <= 0 {
+		return fmt.Errorf("invalid audio format: %d Hz, %d channels", sampleRate, channels)
+	}
+
+	funcPtr, err := projectFunc("GetAudioAccessorSamples")
+	if err != nil {
+		return err
+	}
+
+	buf, ptr := getArray[C.double](audioBlockFrames * channels)
+	defer buf.release()
+	samples := arraySlice[C.double](buf, audioBlockFrames*channels)
+	block := make([]float64, len(samples))
+
+	total := int((end - start) * float64(sampleRate))
+	for offset := 0; offset < total; offset += audioBlockFrames {
+		frames := min(audioBlockFrames, total-offset)
+		time := start + float64(offset)/float64(sampleRate)
+
+		result := C.plugin_bridge_call_get_audio_accessor_samples(funcPtr, a.ptr, C.int(sampleRate), C.int(channels),
+			C.double(time), C.int(frames), ptr)
+		if result < 0 {
+			return fmt.Errorf("failed to read audio at %.3fs", time)
+		}
+
+		out := block[:frames*channels]
+		if result == 0 {
+			clear(out)
+		} else {
+			for i := range out {
+				out[i] = float64(samples[i])
+			}
+		}
+		if err := fn(out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
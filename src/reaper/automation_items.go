@@ -0,0 +1,360 @@
+package reaper
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../c -I${SRCDIR}/../../sdk
+#include "../c/bridge.h"
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"fmt"
+	"unsafe"
+)
+
+// Automation items are blocks of envelope points on an envelope's lane, like media items
+// for automation. Items sharing a pool ID are copies of one another: editing the points
+// of one changes them all. Envelope-writing features should write items rather than raw
+// points, so users can move, loop and reuse the result the way modern projects organize
+// automation. These wrappers use REAPER's own functions and don't need SWS.
+
+// Numeric automation item properties, from GetSetAutomationItemInfo
+const (
+	autoItemPoolID      = "D_POOL_ID"
+	autoItemPosition    = "D_POSITION"
+	autoItemLength      = "D_LENGTH"
+	autoItemStartOffset = "D_STARTOFFS"
+	autoItemPlayRate    = "D_PLAYRATE"
+	autoItemBaseline    = "D_BASELINE"
+	autoItemAmplitude   = "D_AMPLITUDE"
+	autoItemLoop        = "D_LOOPSRC"
+	autoItemSelected    = "D_UISEL"
+	autoItemPoolName    = "P_POOL_NAME"
+)
+
+// autoItemPoolNameSize is the buffer size for pool names. REAPER asks for a big buffer
+// when reading automation item strings.
+const autoItemPoolNameSize = 4096
+
+// NewAutomationPool asks InsertAutomationItem for a new pool
+const NewAutomationPool = -1
+
+// AutomationItem is an automation item on an envelope
+type AutomationItem struct {
+	Index       int // Position among the envelope's automation items
+	PoolID      int // Items with the same pool ID share their points
+	PoolName    string
+	Position    float64 // Project time, seconds
+	Length      float64 // Seconds
+	StartOffset float64 // Where the item starts in its pooled points, seconds
+	PlayRate    float64 // 1 for normal speed
+	Baseline    float64 // 0 to 1; 0.5 leaves the points as they are
+	Amplitude   float64 // -1 to 1; 1 leaves the points as they are
+	Loop        bool    // The pooled points repeat to fill the item
+	Selected    bool
+}
+
+// End returns the item's end in project time
+func (a AutomationItem) End() float64 {
+	return a.Position + a.Length
+}
+
+// envelopeFunc looks up an envelope function and checks the envelope
+func envelopeFunc(envelope unsafe.Pointer, name string) (unsafe.Pointer, error) {
+	if envelope == nil {
+		return nil, fmt.Errorf("invalid envelope")
+	}
+	return projectFunc(name)
+}
+
+// CountAutomationItems returns the number of automation items on an envelope
+func CountAutomationItems(envelope unsafe.Pointer) (int, error) {
+	funcPtr, err := envelopeFunc(envelope, "CountAutomationItems")
+	if err != nil {
+		return 0, err
+	}
+
+	return int(C.plugin_bridge_call_count_automation_items(funcPtr, envelope)), nil
+}
+
+// GetAutomationItems returns an envelope's automation items, in order
+func GetAutomationItems(envelope unsafe.Pointer) ([]AutomationItem, error) {
+	count, err := CountAutomationItems(envelope)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]AutomationItem, 0, count)
+	for i := 0; i < count; i++ {
+		item, err := GetAutomationItem(envelope, i)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// GetAutomationItem returns the automation item at index (0-based)
+func GetAutomationItem(envelope unsafe.Pointer, index int) (AutomationItem, error) {
+	count, err := CountAutomationItems(envelope)
+	if err != nil {
+		return AutomationItem{}, err
+	}
+	if index < 0 || index >= count {
+		return AutomationItem{}, fmt.Errorf("automation item %d not found", index)
+	}
+
+	funcPtr, err := projectFunc("GetSetAutomationItemInfo")
+	if err != nil {
+		return AutomationItem{}, err
+	}
+	get := func(desc string) float64 {
+		cDesc := C.CString(desc)
+		defer C.free(unsafe.Pointer(cDesc))
+		return float64(C.plugin_bridge_call_get_set_automation_item_info(funcPtr, envelope, C.int(index), cDesc, 0, C.bool(false)))
+	}
+
+	item := AutomationItem{
+		Index:       index,
+		PoolID:      int(get(autoItemPoolID)),
+		Position:    get(autoItemPosition),
+		Length:      get(autoItemLength),
+		StartOffset: get(autoItemStartOffset),
+		PlayRate:    get(autoItemPlayRate),
+		Baseline:    get(autoItemBaseline),
+		Amplitude:   get(autoItemAmplitude),
+		Loop:        get(autoItemLoop) != 0,
+		Selected:    get(autoItemSelected) != 0,
+	}
+	if item.PoolName, err = getAutomationItemString(envelope, index, autoItemPoolName); err != nil {
+		return AutomationItem{}, err
+	}
+	return item, nil
+}
+
+// InsertAutomationItem adds an automation item and returns its index. With
+// NewAutomationPool the item gets a new pool and takes over the envelope's points in its
+// range; with a pool ID it is another copy of that pool, or the first of a new empty
+// pool with that ID. Call it inside an undo block.
+func InsertAutomationItem(envelope unsafe.Pointer, poolID int, position, length float64) (int, error) {
+	if position < 0 || length <= 0 {
+		return -1, fmt.Errorf("invalid automation item range: %.3fs for %.3fs", position, length)
+	}
+
+	funcPtr, err := envelopeFunc(envelope, "InsertAutomationItem")
+	if err != nil {
+		return -1, err
+	}
+	if poolID < 0 {
+		poolID = NewAutomationPool
+	}
+
+	index := int(C.plugin_bridge_call_insert_automation_item(funcPtr, envelope, C.int(poolID), C.double(position), C.double(length)))
+	if index < 0 {
+		return -1, fmt.Errorf("failed to insert an automation item at %.3fs", position)
+	}
+	return index, updateTimeline()
+}
+
+// SetAutomationItem writes an item's position, length, offset, rate, baseline,
+// amplitude, looping, selection and pool name. Setting PoolID moves the item to another
+// pool. Call it inside an undo block.
+func SetAutomationItem(envelope unsafe.Pointer, item AutomationItem) error {
+	if item.Length <= 0 || item.PlayRate <= 0 {
+		return fmt.Errorf("invalid automation item: length %.3fs, play rate %f", item.Length, item.PlayRate)
+	}
+
+	current, err := GetAutomationItem(envelope, item.Index)
+	if err != nil {
+		return err
+	}
+
+	funcPtr, err := projectFunc("GetSetAutomationItemInfo")
+	if err != nil {
+		return err
+	}
+	set := func(desc string, value float64) {
+		cDesc := C.CString(desc)
+		defer C.free(unsafe.Pointer(cDesc))
+		C.plugin_bridge_call_get_set_automation_item_info(funcPtr, envelope, C.int(item.Index), cDesc, C.double(value), C.bool(true))
+	}
+
+	if item.PoolID != current.PoolID {
+		set(autoItemPoolID, float64(item.PoolID))
+	}
+	set(autoItemPosition, item.Position)
+	set(autoItemLength, item.Length)
+	set(autoItemStartOffset, item.StartOffset)
+	set(autoItemPlayRate, item.PlayRate)
+	set(autoItemBaseline, item.Baseline)
+	set(autoItemAmplitude, item.Amplitude)
+	set(autoItemLoop, boolValue(item.Loop))
+	set(autoItemSelected, boolValue(item.Selected))
+
+	if item.PoolName != current.PoolName {
+		if err := setAutomationItemString(envelope, item.Index, autoItemPoolName, item.PoolName); err != nil {
+			return err
+		}
+	}
+	return updateTimeline()
+}
+
+// GetAutomationItemPoints returns the points of an automation item, in project time,
+// over all the loops the item shows
+func GetAutomationItemPoints(envelope unsafe.Pointer, index int) ([]EnvelopePoint, error) {
+	countFunc, err := envelopeFunc(envelope, "CountEnvelopePointsEx")
+	if err != nil {
+		return nil, err
+	}
+	getFunc, err := projectFunc("GetEnvelopePointEx")
+	if err != nil {
+		return nil, err
+	}
+
+	count := int(C.plugin_bridge_call_count_envelope_points_ex(countFunc, envelope, C.int(index)))
+	points := make([]EnvelopePoint, 0, count)
+	for i := 0; i < count; i++ {
+		var cTime, cValue, cTension C.double
+		var cShape C.int
+		var cSelected C.bool
+		if !C.plugin_bridge_call_get_envelope_point_ex(getFunc, envelope, C.int(index), C.int(i),
+			&cTime, &cValue, &cShape, &cTension, &cSelected) {
+			return nil, fmt.Errorf("failed to read point %d of automation item %d", i, index)
+		}
+		points = append(points, EnvelopePoint{
+			Position:      float64(cTime),
+			Value:         float64(cValue),
+			Shape:         int(cShape),
+			Selected:      bool(cSelected),
+			BezierTension: float64(cTension),
+		})
+	}
+	return points, nil
+}
+
+// SetAutomationItemPoints replaces the points of an automation item, and so of every item
+// in its pool. Positions are in project time and should fall within the item. Call it
+// inside an undo block.
+func SetAutomationItemPoints(envelope unsafe.Pointer, index int, points []EnvelopePoint) error {
+	item, err := GetAutomationItem(envelope, index)
+	if err != nil {
+		return err
+	}
+
+	deleteFunc, err := projectFunc("DeleteEnvelopePointRangeEx")
+	if err != nil {
+		return err
+	}
+	insertFunc, err := projectFunc("InsertEnvelopePointEx")
+	if err != nil {
+		return err
+	}
+	sortFunc, err := projectFunc("Envelope_SortPointsEx")
+	if err != nil {
+		return err
+	}
+
+	// The range runs a little past the end so a point exactly at it goes too
+	C.plugin_bridge_call_delete_envelope_point_range_ex(deleteFunc, envelope, C.int(index),
+		C.double(item.Position), C.double(item.End()+1e-6))
+
+	for i, point := range points {
+		if !C.plugin_bridge_call_insert_envelope_point_ex(insertFunc, envelope, C.int(index),
+			C.double(point.Position), C.double(point.Value), C.int(point.Shape),
+			C.double(point.BezierTension), C.bool(point.Selected), C.bool(true)) {
+			return fmt.Errorf("failed to insert point %d of automation item %d", i, index)
+		}
+	}
+
+	C.plugin_bridge_call_envelope_sort_points_ex(sortFunc, envelope, C.int(index))
+	return updateTimeline()
+}
+
+// WriteAutomationItem adds an automation item in a new pool holding points, named name
+// unless it is empty, and returns it. REAPER first fills a new pool from the envelope's
+// points in the range; those are replaced by points. Call it inside an undo block.
+func WriteAutomationItem(envelope unsafe.Pointer, position, length float64, name string, points []EnvelopePoint) (AutomationItem, error) {
+	index, err := InsertAutomationItem(envelope, NewAutomationPool, position, length)
+	if err != nil {
+		return AutomationItem{}, err
+	}
+	if err := SetAutomationItemPoints(envelope, index, points); err != nil {
+		return AutomationItem{}, err
+	}
+
+	item, err := GetAutomationItem(envelope, index)
+	if err != nil {
+		return AutomationItem{}, err
+	}
+	if name != "" {
+		item.PoolName = name
+		if err := SetAutomationItem(envelope, item); err != nil {
+			return item, err
+		}
+	}
+	return item, nil
+}
+
+// DuplicateAutomationItem adds a pooled copy of an item at another position and returns
+// its index. Call it inside an undo block.
+func DuplicateAutomationItem(envelope unsafe.Pointer, index int, position float64) (int, error) {
+	item, err := GetAutomationItem(envelope, index)
+	if err != nil {
+		return -1, err
+	}
+
+	copyIndex, err := InsertAutomationItem(envelope, item.PoolID, position, item.Length)
+	if err != nil {
+		return -1, err
+	}
+
+	duplicate := item
+	duplicate.Index = copyIndex
+	duplicate.Position = position
+	duplicate.Selected = false
+	return copyIndex, SetAutomationItem(envelope, duplicate)
+}
+
+// getAutomationItemString reads a string property of an automation item
+func getAutomationItemString(envelope unsafe.Pointer, index int, desc string) (string, error) {
+	funcPtr, err := envelopeFunc(envelope, "GetSetAutomationItemInfo_String")
+	if err != nil {
+		return "", err
+	}
+
+	cDesc := C.CString(desc)
+	defer C.free(unsafe.Pointer(cDesc))
+	buf := getBuffer(autoItemPoolNameSize)
+	defer buf.release()
+
+	if !C.plugin_bridge_call_get_set_automation_item_info_string(funcPtr, envelope, C.int(index), cDesc, buf.ptr, buf.cSize(), C.bool(false)) {
+		return "", nil
+	}
+	return buf.String(), nil
+}
+
+// setAutomationItemString sets a string property of an automation item
+func setAutomationItemString(envelope unsafe.Pointer, index int, desc, value string) error {
+	funcPtr, err := envelopeFunc(envelope, "GetSetAutomationItemInfo_String")
+	if err != nil {
+		return err
+	}
+
+	cDesc := C.CString(desc)
+	defer C.free(unsafe.Pointer(cDesc))
+	cValue := C.CString(value)
+	defer C.free(unsafe.Pointer(cValue))
+
+	if !C.plugin_bridge_call_get_set_automation_item_info_string(funcPtr, envelope, C.int(index), cDesc, cValue, C.int(len(value)+1), C.bool(true)) {
+		return fmt.Errorf("failed to set automation item %s", desc)
+	}
+	return nil
+}
+
+// boolValue is 1 for true and 0 for false, as REAPER's numeric properties take flags
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
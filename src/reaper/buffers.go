@@ -0,0 +1,207 @@
+package reaper
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../c -I${SRCDIR}/../../sdk
+#include "../c/bridge.h"
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"go-reaper/src/pkg/logger"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// Buffers that REAPER writes results into are C memory, since Go memory can't be handed
+// to C for writing. Rather than malloc and free one per call, wrappers take a buffer from
+// getBuffer and release it when done:
+//
+//   - requests that fit use the bridge's static scratch buffer when it is free, which
+//     covers the main thread's string reads without allocating at all
+//   - other requests are rounded up to a size tier and reused through a sync.Pool
+//   - requests larger than the largest tier are allocated directly and freed on release
+//
+// Pooled buffers free their C memory when the pool drops them and they are collected.
+
+// bufferTiers are the pooled buffer sizes, smallest first
+var bufferTiers = [...]int{256, 1024, 4096, 16384, 65536, 262144, 1048576}
+
+// bufferPools holds the free *cBuffer of each tier
+var bufferPools [len(bufferTiers)]sync.Pool
+
+// Tiers of buffers that aren't pooled
+const (
+	scratchTier   = -1
+	oversizedTier = -2
+)
+
+// The bridge's static scratch buffer; scratchMutex is held while it is in use
+var (
+	scratchMutex  sync.Mutex
+	scratchBuffer = &cBuffer{
+		ptr:  C.plugin_bridge_get_scratch_buffer(),
+		size: C.PLUGIN_BRIDGE_SCRATCH_SIZE,
+		tier: scratchTier,
+	}
+)
+
+// Buffer usage counters, for diagnostics
+var (
+	bufferAllocations atomic.Int64
+	bufferReuses      atomic.Int64
+)
+
+// BufferStats reports how many C buffers were allocated and how many requests were
+// served by reusing one
+type BufferStats struct {
+	Allocations int64
+	Reuses      int64
+}
+
+// GetBufferStats returns the buffer usage since the extension loaded
+func GetBufferStats() BufferStats {
+	return BufferStats{
+		Allocations: bufferAllocations.Load(),
+		Reuses:      bufferReuses.Load(),
+	}
+}
+
+// cBuffer is a block of C memory from getBuffer
+type cBuffer struct {
+	ptr  *C.char
+	size int
+	tier int // Index into bufferTiers, scratchTier or oversizedTier
+}
+
+// getBuffer returns a zero-terminated C buffer of at least size bytes. Call release when
+// done with it.
+func getBuffer(size int) *cBuffer {
+	if size <= C.PLUGIN_BRIDGE_SCRATCH_SIZE && scratchMutex.TryLock() {
+		bufferReuses.Add(1)
+		*scratchBuffer.ptr = 0
+		return scratchBuffer
+	}
+
+	for tier, tierSize := range bufferTiers {
+		if size > tierSize {
+			continue
+		}
+		if pooled, ok := bufferPools[tier].Get().(*cBuffer); ok {
+			bufferReuses.Add(1)
+			*pooled.ptr = 0
+			return pooled
+		}
+		return allocateBuffer(tierSize, tier)
+	}
+
+	return allocateBuffer(size, oversizedTier)
+}
+
+// allocateBuffer mallocs a buffer. Pooled buffers free their memory once collected.
+func allocateBuffer(size int, tier int) *cBuffer {
+	bufferAllocations.Add(1)
+	buffer := &cBuffer{
+		ptr:  (*C.char)(C.malloc(C.size_t(size))),
+		size: size,
+		tier: tier,
+	}
+	*buffer.ptr = 0
+
+	if tier >= 0 {
+		runtime.SetFinalizer(buffer, func(b *cBuffer) {
+			C.free(unsafe.Pointer(b.ptr))
+		})
+	}
+	return buffer
+}
+
+// release returns the buffer for reuse. The buffer must not be used afterwards.
+func (b *cBuffer) release() {
+	switch b.tier {
+	case scratchTier:
+		scratchMutex.Unlock()
+	case oversizedTier:
+		C.free(unsafe.Pointer(b.ptr))
+		b.ptr = nil
+	default:
+		bufferPools[b.tier].Put(b)
+	}
+}
+
+// cSize is the buffer size to pass to REAPER
+func (b *cBuffer) cSize() C.int {
+	return C.int(b.size)
+}
+
+// String copies the zero-terminated string in the buffer
+func (b *cBuffer) String() string {
+	return C.GoString(b.ptr)
+}
+
+// pointer returns the buffer as untyped memory, for arrays of bridge structs
+func (b *cBuffer) pointer() unsafe.Pointer {
+	return unsafe.Pointer(b.ptr)
+}
+
+// Sizes of the buffers string results are read into
+const (
+	// defaultConfigBufferSize is the starting size for named config values, which
+	// include chunks and JSON
+	defaultConfigBufferSize = 16384
+
+	// maxStringBufferSize is the most readString grows a buffer to
+	maxStringBufferSize = 1048576
+)
+
+// stringBufferSize is the starting size for names and formatted values
+var stringBufferSize atomic.Int64
+
+func init() {
+	stringBufferSize.Store(256)
+}
+
+// SetStringBufferSize sets the starting buffer size for names and formatted values.
+// Results that don't fit are read again into a larger buffer, so this only tunes how
+// often that happens.
+func SetStringBufferSize(size int) {
+	if size < 16 {
+		size = 16
+	}
+	stringBufferSize.Store(int64(min(size, maxStringBufferSize)))
+}
+
+// GetStringBufferSize returns the starting buffer size for names and formatted values
+func GetStringBufferSize() int {
+	return int(stringBufferSize.Load())
+}
+
+// readString calls read with a buffer of at least size bytes and returns the string it
+// wrote. REAPER truncates results to the buffer without saying so, so a result that
+// fills the buffer is read again into one twice the size, up to maxStringBufferSize.
+// ok is false if read reports failure.
+func readString(size int, read func(buf *cBuffer) bool) (value string, ok bool) {
+	for {
+		buf := getBuffer(size)
+		ok = read(buf)
+		value = buf.String()
+		bufSize := buf.size
+		buf.release()
+
+		if !ok || !isTruncated(value, bufSize) {
+			return value, ok
+		}
+		if bufSize >= maxStringBufferSize {
+			logger.Warning("String result is still truncated at %d bytes", bufSize)
+			return value, ok
+		}
+		size = bufSize * 2
+	}
+}
+
+// isTruncated reports whether a string read into a buffer of bufSize bytes may have been
+// cut short, because it fills the buffer
+func isTruncated(value string, bufSize int) bool {
+	return len(value) >= bufSize-1
+}
@@ -0,0 +1,166 @@
+package reaper
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../c -I${SRCDIR}/../../sdk
+#include "../c/bridge.h"
+*/
+import "C"
+import (
+	"fmt"
+	"go-reaper/src/pkg/logger"
+	"unsafe"
+)
+
+// Batch calls pass arrays of bridge structs through C memory. Go reads and writes them
+// in place as slices, without copying: getArray allocates one, and arraySlice and
+// cArray view one as a slice no longer than the memory behind it.
+
+// getArray returns a buffer holding count elements of T, and a pointer to the first one
+// to pass to C. Release the buffer when done with it.
+func getArray[T any](count int) (*cBuffer, *T) {
+	var zero T
+	buf := getBuffer(max(count, 1) * int(unsafe.Sizeof(zero)))
+	return buf, (*T)(buf.pointer())
+}
+
+// arraySlice views the first count elements of T in a buffer from getArray. count is
+// usually what C reports it wrote, so it is kept within the buffer rather than trusted.
+func arraySlice[T any](buf *cBuffer, count int) []T {
+	var zero T
+	capacity := buf.size / int(unsafe.Sizeof(zero))
+	if count > capacity {
+		logger.Error("Bridge reported %d elements of %T in a buffer of %d; reading %d", count, zero, capacity, capacity)
+		count = capacity
+	}
+	return cArray((*T)(buf.pointer()), count)
+}
+
+// cArray views count elements of T at ptr, e.g. an array malloced by the bridge. It
+// returns nil for a nil pointer or a count below 1.
+func cArray[T any](ptr *T, count int) []T {
+	if ptr == nil || count <= 0 {
+		return nil
+	}
+	return unsafe.Slice(ptr, count)
+}
+
+// structField is a field of a bridge struct and its offset in Go's view of the struct
+type structField struct {
+	name   string
+	offset uintptr
+}
+
+// bridgeStructs are the structs read and written in place, with Go's view of them. The
+// field offsets are what the Go side reads; the tests check them against the layout the
+// bridge is written for.
+var bridgeStructs = []struct {
+	id     C.int
+	name   string
+	size   uintptr
+	align  uintptr
+	fields []structField
+}{
+	{C.BRIDGE_STRUCT_FX_PARAM, "fx_param_t", unsafe.Sizeof(C.fx_param_t{}), unsafe.Alignof(C.fx_param_t{}), []structField{
+		{"name", unsafe.Offsetof(C.fx_param_t{}.name)},
+		{"value", unsafe.Offsetof(C.fx_param_t{}.value)},
+		{"min", unsafe.Offsetof(C.fx_param_t{}.min)},
+		{"max", unsafe.Offsetof(C.fx_param_t{}.max)},
+		{"formatted", unsafe.Offsetof(C.fx_param_t{}.formatted)},
+	}},
+	{C.BRIDGE_STRUCT_FX_VALUE, "fx_value_t", unsafe.Sizeof(C.fx_value_t{}), unsafe.Alignof(C.fx_value_t{}), []structField{
+		{"value", unsafe.Offsetof(C.fx_value_t{}.value)},
+		{"formatted", unsafe.Offsetof(C.fx_value_t{}.formatted)},
+		{"valid", unsafe.Offsetof(C.fx_value_t{}.valid)},
+	}},
+	{C.BRIDGE_STRUCT_FX_PARAM_REF, "fx_param_ref_t", unsafe.Sizeof(C.fx_param_ref_t{}), unsafe.Alignof(C.fx_param_ref_t{}), []structField{
+		{"fx_idx", unsafe.Offsetof(C.fx_param_ref_t{}.fx_idx)},
+		{"param_idx", unsafe.Offsetof(C.fx_param_ref_t{}.param_idx)},
+	}},
+	{C.BRIDGE_STRUCT_FX_STATE, "fx_state_t", unsafe.Sizeof(C.fx_state_t{}), unsafe.Alignof(C.fx_state_t{}), []structField{
+		{"index", unsafe.Offsetof(C.fx_state_t{}.index)},
+		{"enabled", unsafe.Offsetof(C.fx_state_t{}.enabled)},
+		{"offline", unsafe.Offsetof(C.fx_state_t{}.offline)},
+	}},
+	{C.BRIDGE_STRUCT_FX_PARAM_CHANGE, "fx_param_change_t", unsafe.Sizeof(C.fx_param_change_t{}), unsafe.Alignof(C.fx_param_change_t{}), []structField{
+		{"track", unsafe.Offsetof(C.fx_param_change_t{}.track)},
+		{"fx_idx", unsafe.Offsetof(C.fx_param_change_t{}.fx_idx)},
+		{"param_idx", unsafe.Offsetof(C.fx_param_change_t{}.param_idx)},
+		{"op", unsafe.Offsetof(C.fx_param_change_t{}.op)},
+		{"value", unsafe.Offsetof(C.fx_param_change_t{}.value)},
+		{"min", unsafe.Offsetof(C.fx_param_change_t{}.min)},
+		{"max", unsafe.Offsetof(C.fx_param_change_t{}.max)},
+		{"ok", unsafe.Offsetof(C.fx_param_change_t{}.ok)},
+		{"result", unsafe.Offsetof(C.fx_param_change_t{}.result)},
+	}},
+	{C.BRIDGE_STRUCT_FX_SUMMARY, "fx_summary_t", unsafe.Sizeof(C.fx_summary_t{}), unsafe.Alignof(C.fx_summary_t{}), []structField{
+		{"index", unsafe.Offsetof(C.fx_summary_t{}.index)},
+		{"name", unsafe.Offsetof(C.fx_summary_t{}.name)},
+		{"type", unsafe.Offsetof(C.fx_summary_t{}._type)},
+		{"ident", unsafe.Offsetof(C.fx_summary_t{}.ident)},
+		{"original_name", unsafe.Offsetof(C.fx_summary_t{}.original_name)},
+		{"enabled", unsafe.Offsetof(C.fx_summary_t{}.enabled)},
+		{"offline", unsafe.Offsetof(C.fx_summary_t{}.offline)},
+	}},
+	{C.BRIDGE_STRUCT_TRACK_INFO, "track_info_t", unsafe.Sizeof(C.track_info_t{}), unsafe.Alignof(C.track_info_t{}), []structField{
+		{"number", unsafe.Offsetof(C.track_info_t{}.number)},
+		{"name", unsafe.Offsetof(C.track_info_t{}.name)},
+		{"guid", unsafe.Offsetof(C.track_info_t{}.guid)},
+		{"fx_count", unsafe.Offsetof(C.track_info_t{}.fx_count)},
+		{"mute", unsafe.Offsetof(C.track_info_t{}.mute)},
+		{"solo", unsafe.Offsetof(C.track_info_t{}.solo)},
+		{"rec_arm", unsafe.Offsetof(C.track_info_t{}.rec_arm)},
+		{"volume", unsafe.Offsetof(C.track_info_t{}.volume)},
+		{"pan", unsafe.Offsetof(C.track_info_t{}.pan)},
+		{"color", unsafe.Offsetof(C.track_info_t{}.color)},
+		{"r", unsafe.Offsetof(C.track_info_t{}.r)},
+		{"g", unsafe.Offsetof(C.track_info_t{}.g)},
+		{"b", unsafe.Offsetof(C.track_info_t{}.b)},
+		{"folder_depth", unsafe.Offsetof(C.track_info_t{}.folder_depth)},
+		{"depth", unsafe.Offsetof(C.track_info_t{}.depth)},
+		{"parent", unsafe.Offsetof(C.track_info_t{}.parent)},
+	}},
+	{C.BRIDGE_STRUCT_MIDI_INPUT_EVENT, "midi_input_event_t", unsafe.Sizeof(C.midi_input_event_t{}), unsafe.Alignof(C.midi_input_event_t{}), []structField{
+		{"seq", unsafe.Offsetof(C.midi_input_event_t{}.seq)},
+		{"msg", unsafe.Offsetof(C.midi_input_event_t{}.msg)},
+		{"size", unsafe.Offsetof(C.midi_input_event_t{}.size)},
+		{"device", unsafe.Offsetof(C.midi_input_event_t{}.device)},
+	}},
+}
+
+// bridgeStructCount is the number of structs the bridge reports layouts for
+var bridgeStructCount = int(C.BRIDGE_STRUCT_COUNT)
+
+// cStructLayout returns the C compiler's size and alignment of a bridge struct. ok is
+// false if the bridge doesn't know the struct.
+func cStructLayout(id C.int) (size, align uintptr, ok bool) {
+	var cSize, cAlign C.size_t
+	if !bool(C.plugin_bridge_struct_layout(id, &cSize, &cAlign)) {
+		return 0, 0, false
+	}
+	return uintptr(cSize), uintptr(cAlign), true
+}
+
+// CheckStructLayout compares the size and alignment Go uses for each bridge struct with
+// the C compiler's. A mismatch, e.g. from a bridge built with different packing than the
+// Go side, means batch calls would read fields from the wrong offsets.
+func CheckStructLayout() error {
+	if len(bridgeStructs) != bridgeStructCount {
+		return fmt.Errorf("%d bridge structs are checked but the bridge has %d", len(bridgeStructs), bridgeStructCount)
+	}
+
+	var mismatches []string
+	for _, s := range bridgeStructs {
+		size, align, ok := cStructLayout(s.id)
+		if !ok {
+			return fmt.Errorf("the bridge doesn't know %s", s.name)
+		}
+		if size != s.size || align != s.align {
+			mismatches = append(mismatches, fmt.Sprintf("%s is %d bytes aligned to %d in C but %d aligned to %d in Go",
+				s.name, size, align, s.size, s.align))
+		}
+	}
+	if len(mismatches) > 0 {
+		return fmt.Errorf("bridge struct layout mismatch: %v", mismatches)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package reaper
+
+import (
+	"testing"
+	"unsafe"
+)
+
+// expectedLayout is the layout the bridge and the Go side are written for, on the 64-bit
+// platforms REAPER runs on
+type expectedLayout struct {
+	size, align uintptr
+	fields      map[string]uintptr
+}
+
+var expectedLayouts = map[string]expectedLayout{
+	"fx_param_t": {536, 8, map[string]uintptr{
+		"name": 0, "value": 256, "min": 264, "max": 272, "formatted": 280,
+	}},
+	"fx_value_t": {272, 8, map[string]uintptr{
+		"value": 0, "formatted": 8, "valid": 264,
+	}},
+	"fx_param_ref_t": {8, 4, map[string]uintptr{
+		"fx_idx": 0, "param_idx": 4,
+	}},
+	"fx_state_t": {8, 4, map[string]uintptr{
+		"index": 0, "enabled": 4, "offline": 5,
+	}},
+	"fx_param_change_t": {64, 8, map[string]uintptr{
+		"track": 0, "fx_idx": 8, "param_idx": 12, "op": 16, "value": 24, "min": 32, "max": 40, "ok": 48, "result": 56,
+	}},
+	"fx_summary_t": {1576, 4, map[string]uintptr{
+		"index": 0, "name": 4, "type": 260, "ident": 292, "original_name": 1316, "enabled": 1572, "offline": 1573,
+	}},
+	"track_info_t": {392, 8, map[string]uintptr{
+		"number": 0, "name": 4, "guid": 260, "fx_count": 324, "mute": 328, "solo": 332, "rec_arm": 336,
+		"volume": 344, "pan": 352, "color": 360, "r": 364, "g": 368, "b": 372, "folder_depth": 376, "depth": 380,
+		"parent": 384,
+	}},
+	"midi_input_event_t": {16, 4, map[string]uintptr{
+		"seq": 0, "msg": 4, "size": 8, "device": 12,
+	}},
+}
+
+func TestBridgeStructLayout(t *testing.T) {
+	if unsafe.Sizeof(uintptr(0)) != 8 {
+		t.Skip("the expected layouts are for 64-bit platforms")
+	}
+	if len(bridgeStructs) != bridgeStructCount {
+		t.Fatalf("%d bridge structs are checked but the bridge has %d", len(bridgeStructs), bridgeStructCount)
+	}
+
+	for _, s := range bridgeStructs {
+		want, ok := expectedLayouts[s.name]
+		if !ok {
+			t.Errorf("%s has no expected layout", s.name)
+			continue
+		}
+
+		size, align, ok := cStructLayout(s.id)
+		if !ok {
+			t.Errorf("the bridge doesn't know %s", s.name)
+			continue
+		}
+		if size != want.size || align != want.align {
+			t.Errorf("%s is %d bytes aligned to %d in C, want %d aligned to %d", s.name, size, align, want.size, want.align)
+		}
+		if s.size != want.size || s.align != want.align {
+			t.Errorf("%s is %d bytes aligned to %d in Go, want %d aligned to %d", s.name, s.size, s.align, want.size, want.align)
+		}
+
+		if len(s.fields) != len(want.fields) {
+			t.Errorf("%s has %d fields checked, want %d", s.name, len(s.fields), len(want.fields))
+		}
+		for _, field := range s.fields {
+			offset, ok := want.fields[field.name]
+			if !ok {
+				t.Errorf("%s.%s has no expected offset", s.name, field.name)
+			} else if field.offset != offset {
+				t.Errorf("%s.%s is at offset %d, want %d", s.name, field.name, field.offset, offset)
+			}
+		}
+	}
+
+	if err := CheckStructLayout(); err != nil {
+		t.Errorf("CheckStructLayout: %v", err)
+	}
+}
+
+func TestScratchBufferAlignment(t *testing.T) {
+	address := uintptr(unsafe.Pointer(scratchBuffer.ptr))
+	for _, s := range bridgeStructs {
+		if address%s.align != 0 {
+			t.Errorf("the scratch buffer at %#x isn't aligned for %s (%d)", address, s.name, s.align)
+		}
+	}
+}
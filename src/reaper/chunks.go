@@ -0,0 +1,200 @@
+package reaper
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../c -I${SRCDIR}/../../sdk
+#include "../c/bridge.h"
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"fmt"
+	"strings"
+	"unsafe"
+)
+
+// State chunks are REAPER's text serialization of an object, the same format as in .RPP
+// files. A track chunk holds the track's FX chain in an <FXCHAIN block, whose contents
+// (minus the window settings) are what REAPER saves as a .RfxChain file.
+
+// Sizes of the buffer track chunks are read into. Chunks include plugin state, so they
+// can be large.
+const (
+	initialChunkBufferSize = 65536
+	maxChunkBufferSize     = 64 * 1048576
+)
+
+// fxChainHeaderKeys are the <FXCHAIN lines describing the chain window rather than the FX
+var fxChainHeaderKeys = map[string]bool{
+	"WNDRECT": true,
+	"SHOW":    true,
+	"LASTSEL": true,
+	"DOCKED":  true,
+}
+
+// GetTrackStateChunk returns the state chunk of a track
+func GetTrackStateChunk(track unsafe.Pointer) (string, error) {
+	if track == nil {
+		return "", fmt.Errorf("invalid track")
+	}
+
+	funcPtr, err := projectFunc("GetTrackStateChunk")
+	if err != nil {
+		return "", err
+	}
+
+	// REAPER fails rather than truncating when the chunk doesn't fit, so grow until it does
+	for size := initialChunkBufferSize; size <= maxChunkBufferSize; size *= 4 {
+		buf := getBuffer(size)
+		ok := bool(C.plugin_bridge_call_get_track_state_chunk(funcPtr, track, buf.ptr, buf.cSize(), false))
+		chunk := buf.String()
+		bufSize := buf.size
+		buf.release()
+
+		if ok && !isTruncated(chunk, bufSize) {
+			return chunk, nil
+		}
+	}
+
+	return "", fmt.Errorf("track state chunk is larger than %d bytes", maxChunkBufferSize)
+}
+
+// SetTrackStateChunk replaces a track's state with chunk. Wrap it in an undo block to
+// make it undoable.
+func SetTrackStateChunk(track unsafe.Pointer, chunk string) error {
+	if track == nil {
+		return fmt.Errorf("invalid track")
+	}
+
+	funcPtr, err := projectFunc("SetTrackStateChunk")
+	if err != nil {
+		return err
+	}
+
+	cChunk := C.CString(chunk)
+	defer C.free(unsafe.Pointer(cChunk))
+
+	if !bool(C.plugin_bridge_call_set_track_state_chunk(funcPtr, track, cChunk, false)) {
+		return fmt.Errorf("REAPER rejected the track state chunk")
+	}
+	return nil
+}
+
+// GetTrackFXChain returns a track's FX chain in .RfxChain format, or "" if it has no FX
+func GetTrackFXChain(track unsafe.Pointer) (string, error) {
+	chunk, err := GetTrackStateChunk(track)
+	if err != nil {
+		return "", err
+	}
+	return extractFXChain(chunk), nil
+}
+
+// AppendTrackFXChain adds the FX of a chain in .RfxChain format after the track's
+// existing FX
+func AppendTrackFXChain(track unsafe.Pointer, fxChain string) error {
+	if strings.TrimSpace(fxChain) == "" {
+		return fmt.Errorf("the FX chain is empty")
+	}
+
+	chunk, err := GetTrackStateChunk(track)
+	if err != nil {
+		return err
+	}
+
+	updated, err := appendFXChain(chunk, fxChain)
+	if err != nil {
+		return err
+	}
+	return SetTrackStateChunk(track, updated)
+}
+
+// chunkLines splits a chunk into lines, dropping line ending differences
+func chunkLines(chunk string) []string {
+	return strings.Split(strings.ReplaceAll(chunk, "\r\n", "\n"), "\n")
+}
+
+// findFXChain returns the line indices of the track's <FXCHAIN line and its closing
+// ">", or -1s if the track has no FX chain block
+func findFXChain(lines []string) (start, end int) {
+	start = -1
+	depth := 0
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "<"):
+			depth++
+			if depth == 2 && strings.HasPrefix(trimmed, "<FXCHAIN") {
+				start = i
+			}
+		case trimmed == ">":
+			if depth == 2 && start >= 0 {
+				return start, i
+			}
+			depth--
+		}
+	}
+	return -1, -1
+}
+
+// extractFXChain returns the FX in a track chunk's <FXCHAIN block, without the chain
+// window settings
+func extractFXChain(chunk string) string {
+	lines := chunkLines(chunk)
+	start, end := findFXChain(lines)
+	if start < 0 {
+		return ""
+	}
+
+	var builder strings.Builder
+	depth := 0
+	for _, line := range lines[start+1 : end] {
+		trimmed := strings.TrimSpace(line)
+		if depth == 0 {
+			key, _, _ := strings.Cut(trimmed, " ")
+			if fxChainHeaderKeys[key] {
+				continue
+			}
+		}
+		if strings.HasPrefix(trimmed, "<") {
+			depth++
+		} else if trimmed == ">" {
+			depth--
+		}
+		builder.WriteString(trimmed)
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
+
+// appendFXChain inserts the FX of a .RfxChain file at the end of a track chunk's FX
+// chain, creating the chain block if the track has none. FXID lines are dropped so
+// REAPER assigns new IDs rather than duplicating existing ones.
+func appendFXChain(chunk string, fxChain string) (string, error) {
+	var fxLines []string
+	for _, line := range chunkLines(fxChain) {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "FXID ") {
+			continue
+		}
+		fxLines = append(fxLines, trimmed)
+	}
+
+	lines := chunkLines(strings.TrimRight(chunk, "\r\n"))
+	if len(lines) == 0 || strings.TrimSpace(lines[len(lines)-1]) != ">" {
+		return "", fmt.Errorf("the track state chunk is malformed")
+	}
+
+	var insertAt int
+	if _, end := findFXChain(lines); end >= 0 {
+		insertAt = end
+	} else {
+		// New chain block just before the track's closing ">"
+		insertAt = len(lines) - 1
+		fxLines = append(append([]string{"<FXCHAIN", "SHOW 0", "LASTSEL 0", "DOCKED 0"}, fxLines...), ">")
+	}
+
+	updated := make([]string, 0, len(lines)+len(fxLines))
+	updated = append(updated, lines[:insertAt]...)
+	updated = append(updated, fxLines...)
+	updated = append(updated, lines[insertAt:]...)
+	return strings.Join(updated, "\n") + "\n", nil
+}
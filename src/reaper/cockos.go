@@ -0,0 +1,324 @@
+package reaper
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unsafe"
+)
+
+// Typed adapters for the stock Cockos plugins. Parameters are located by name and
+// read/written in the units the plugin displays (Hz, dB, octaves, ms), so callers
+// don't need to know the plugin's normalized mappings.
+
+// IsCockosPlugin reports whether an FX is the given stock Cockos plugin, even if renamed
+func IsCockosPlugin(fx FXInfo, pluginName string) bool {
+	if strings.EqualFold(fx.PluginName, pluginName) {
+		return fx.Vendor == "" || strings.EqualFold(fx.Vendor, "Cockos")
+	}
+
+	// Fall back to the plugin file, e.g. "reaeq.dll<1919247729" or "reacomp.vst.dylib"
+	return strings.HasPrefix(strings.ToLower(fx.PluginID), strings.ToLower(pluginName)+".")
+}
+
+// ReaEQBand is a single ReaEQ band in real units
+type ReaEQBand struct {
+	Name           string  // Band label, e.g. "Low Shelf" or "Band 2"
+	FrequencyParam int     // Parameter indices, -1 when the band lacks the control
+	GainParam      int     //
+	BandwidthParam int     //
+	Frequency      float64 // Hz
+	Gain           float64 // dB
+	Bandwidth      float64 // Octaves
+}
+
+// ReaEQ adapts a ReaEQ instance
+type ReaEQ struct {
+	Track   unsafe.Pointer
+	FXIndex int
+	Bands   []ReaEQBand
+}
+
+// NewReaEQ reads the bands of a ReaEQ instance
+func NewReaEQ(track unsafe.Pointer, fxIndex int) (*ReaEQ, error) {
+	fx, err := GetFXParameters(track, fxIndex)
+	if err != nil {
+		return nil, err
+	}
+	if !IsCockosPlugin(fx, "ReaEQ") {
+		return nil, fmt.Errorf("FX %d (%s) is not ReaEQ", fxIndex, fx.Name)
+	}
+
+	return &ReaEQ{
+		Track:   track,
+		FXIndex: fxIndex,
+		Bands:   GroupReaEQBands(fx.Parameters),
+	}, nil
+}
+
+// GroupReaEQBands pairs "Freq-X", "Gain-X" and "BW-X"/"Q-X" parameters into bands,
+// using the formatted values for the band settings
+func GroupReaEQBands(params []FXParameter) []ReaEQBand {
+	var bands []ReaEQBand
+	bandIndex := make(map[string]int)
+
+	for _, param := range params {
+		prefix, label, ok := strings.Cut(param.Name, "-")
+		if !ok || label == "" {
+			continue
+		}
+
+		i, exists := bandIndex[label]
+		if !exists {
+			bands = append(bands, ReaEQBand{Name: label, FrequencyParam: -1, GainParam: -1, BandwidthParam: -1})
+			i = len(bands) - 1
+			bandIndex[label] = i
+		}
+
+		value, _ := parseFormattedNumber(param.FormattedValue)
+		switch strings.ToLower(prefix) {
+		case "freq":
+			bands[i].FrequencyParam, bands[i].Frequency = param.Index, value
+		case "gain":
+			bands[i].GainParam, bands[i].Gain = param.Index, value
+		case "bw", "q", "bandwidth":
+			bands[i].BandwidthParam, bands[i].Bandwidth = param.Index, value
+		}
+	}
+
+	// Drop groups that turned out not to be EQ bands
+	result := bands[:0]
+	for _, band := range bands {
+		if band.FrequencyParam >= 0 {
+			result = append(result, band)
+		}
+	}
+	return result
+}
+
+// Refresh re-reads the band settings, e.g. after the user moved a control in the plugin
+func (eq *ReaEQ) Refresh() error {
+	return RefreshEQBands(eq.Track, eq.FXIndex, eq.Bands)
+}
+
+// RefreshEQBands re-reads the frequency, gain and bandwidth of bands from their parameters'
+// formatted values, in one batch call. It works for any plugin whose bands have been
+// located by index, and is cheap enough to poll.
+func RefreshEQBands(track unsafe.Pointer, fxIndex int, bands []ReaEQBand) error {
+	var refs []paramRef
+	for _, band := range bands {
+		for _, param := range []int{band.FrequencyParam, band.GainParam, band.BandwidthParam} {
+			if param >= 0 {
+				refs = append(refs, paramRef{FXIndex: fxIndex, ParamIndex: param})
+			}
+		}
+	}
+
+	values, _, err := readParamValues(track, refs)
+	if err != nil {
+		return err
+	}
+
+	for i := range bands {
+		band := &bands[i]
+		controls := []struct {
+			param int
+			value *float64
+		}{
+			{band.FrequencyParam, &band.Frequency},
+			{band.GainParam, &band.Gain},
+			{band.BandwidthParam, &band.Bandwidth},
+		}
+
+		for _, control := range controls {
+			if control.param < 0 {
+				continue
+			}
+			read, ok := values[paramRef{FXIndex: fxIndex, ParamIndex: control.param}]
+			if !ok {
+				return fmt.Errorf("failed to read %s: parameter %d no longer exists", band.Name, control.param)
+			}
+			if value, ok := parseFormattedNumber(read.Formatted); ok {
+				*control.value = value
+			}
+		}
+	}
+	return nil
+}
+
+// band returns a band by index
+func (eq *ReaEQ) band(index int) (*ReaEQBand, error) {
+	if index < 0 || index >= len(eq.Bands) {
+		return nil, fmt.Errorf("ReaEQ band %d out of range (%d bands)", index, len(eq.Bands))
+	}
+	return &eq.Bands[index], nil
+}
+
+// SetBandFrequency sets a band's frequency in Hz
+func (eq *ReaEQ) SetBandFrequency(index int, hz float64) error {
+	band, err := eq.band(index)
+	if err != nil {
+		return err
+	}
+	if _, err := SetTrackFXParamFromUnitValue(eq.Track, eq.FXIndex, band.FrequencyParam, hz); err != nil {
+		return fmt.Errorf("failed to set %s frequency: %v", band.Name, err)
+	}
+	band.Frequency = hz
+	return nil
+}
+
+// SetBandGain sets a band's gain in dB
+func (eq *ReaEQ) SetBandGain(index int, db float64) error {
+	band, err := eq.band(index)
+	if err != nil {
+		return err
+	}
+	if band.GainParam < 0 {
+		return fmt.Errorf("%s has no gain control", band.Name)
+	}
+	if _, err := SetTrackFXParamFromUnitValue(eq.Track, eq.FXIndex, band.GainParam, db); err != nil {
+		return fmt.Errorf("failed to set %s gain: %v", band.Name, err)
+	}
+	band.Gain = db
+	return nil
+}
+
+// SetBandBandwidth sets a band's bandwidth in octaves
+func (eq *ReaEQ) SetBandBandwidth(index int, octaves float64) error {
+	band, err := eq.band(index)
+	if err != nil {
+		return err
+	}
+	if band.BandwidthParam < 0 {
+		return fmt.Errorf("%s has no bandwidth control", band.Name)
+	}
+	if _, err := SetTrackFXParamFromUnitValue(eq.Track, eq.FXIndex, band.BandwidthParam, octaves); err != nil {
+		return fmt.Errorf("failed to set %s bandwidth: %v", band.Name, err)
+	}
+	band.Bandwidth = octaves
+	return nil
+}
+
+// SetBandQ sets a band's width as a Q factor, converted to ReaEQ's octave bandwidth
+func (eq *ReaEQ) SetBandQ(index int, q float64) error {
+	if q <= 0 {
+		return fmt.Errorf("invalid Q %.3g: must be positive", q)
+	}
+	return eq.SetBandBandwidth(index, QToBandwidth(q))
+}
+
+// QToBandwidth converts a Q factor to bandwidth in octaves
+func QToBandwidth(q float64) float64 {
+	return 2 / math.Ln2 * math.Asinh(1/(2*q))
+}
+
+// BandwidthToQ converts bandwidth in octaves to a Q factor
+func BandwidthToQ(octaves float64) float64 {
+	return math.Sqrt(math.Exp2(octaves)) / (math.Exp2(octaves) - 1)
+}
+
+// ReaComp adapts a ReaComp instance
+type ReaComp struct {
+	Track          unsafe.Pointer
+	FXIndex        int
+	ThresholdParam int // Parameter indices, -1 when not found
+	RatioParam     int
+	AttackParam    int
+	ReleaseParam   int
+}
+
+// NewReaComp locates the main controls of a ReaComp instance
+func NewReaComp(track unsafe.Pointer, fxIndex int) (*ReaComp, error) {
+	fx, err := GetFXParameters(track, fxIndex)
+	if err != nil {
+		return nil, err
+	}
+	if !IsCockosPlugin(fx, "ReaComp") {
+		return nil, fmt.Errorf("FX %d (%s) is not ReaComp", fxIndex, fx.Name)
+	}
+
+	comp := &ReaComp{
+		Track:          track,
+		FXIndex:        fxIndex,
+		ThresholdParam: -1,
+		RatioParam:     -1,
+		AttackParam:    -1,
+		ReleaseParam:   -1,
+	}
+
+	for _, param := range fx.Parameters {
+		switch name := strings.ToLower(param.Name); {
+		case strings.HasPrefix(name, "thresh") && comp.ThresholdParam < 0:
+			comp.ThresholdParam = param.Index
+		case name == "ratio" && comp.RatioParam < 0:
+			comp.RatioParam = param.Index
+		case name == "attack" && comp.AttackParam < 0:
+			comp.AttackParam = param.Index
+		case name == "release" && comp.ReleaseParam < 0:
+			comp.ReleaseParam = param.Index
+		}
+	}
+
+	return comp, nil
+}
+
+// unitValue reads a control in display units
+func (c *ReaComp) unitValue(paramIndex int, control string) (float64, error) {
+	if paramIndex < 0 {
+		return 0, fmt.Errorf("ReaComp %s control not found", control)
+	}
+	formatted, err := GetTrackFXParamFormatted(c.Track, c.FXIndex, paramIndex)
+	if err != nil {
+		return 0, err
+	}
+	value, ok := parseFormattedNumber(formatted)
+	if !ok {
+		return 0, fmt.Errorf("ReaComp %s value %q is not numeric", control, formatted)
+	}
+	return value, nil
+}
+
+// setUnitValue writes a control in display units
+func (c *ReaComp) setUnitValue(paramIndex int, control string, value float64) error {
+	if paramIndex < 0 {
+		return fmt.Errorf("ReaComp %s control not found", control)
+	}
+	if _, err := SetTrackFXParamFromUnitValue(c.Track, c.FXIndex, paramIndex, value); err != nil {
+		return fmt.Errorf("failed to set ReaComp %s: %v", control, err)
+	}
+	return nil
+}
+
+// Threshold returns the threshold in dB
+func (c *ReaComp) Threshold() (float64, error) {
+	return c.unitValue(c.ThresholdParam, "threshold")
+}
+
+// SetThreshold sets the threshold in dB
+func (c *ReaComp) SetThreshold(db float64) error {
+	return c.setUnitValue(c.ThresholdParam, "threshold", db)
+}
+
+// Ratio returns the ratio (4 means 4:1)
+func (c *ReaComp) Ratio() (float64, error) {
+	return c.unitValue(c.RatioParam, "ratio")
+}
+
+// SetRatio sets the ratio (4 means 4:1)
+func (c *ReaComp) SetRatio(ratio float64) error { return c.setUnitValue(c.RatioParam, "ratio", ratio) }
+
+// Attack returns the attack time in ms
+func (c *ReaComp) Attack() (float64, error) {
+	return c.unitValue(c.AttackParam, "attack")
+}
+
+// SetAttack sets the attack time in ms
+func (c *ReaComp) SetAttack(ms float64) error { return c.setUnitValue(c.AttackParam, "attack", ms) }
+
+// Release returns the release time in ms
+func (c *ReaComp) Release() (float64, error) {
+	return c.unitValue(c.ReleaseParam, "release")
+}
+
+// SetRelease sets the release time in ms
+func (c *ReaComp) SetRelease(ms float64) error { return c.setUnitValue(c.ReleaseParam, "release", ms) }
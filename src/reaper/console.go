@@ -8,7 +8,8 @@ package reaper
 import "C"
 import (
 	"fmt"
-	"runtime"
+	"go-reaper/src/pkg/logger"
+	"strings"
 	"sync"
 	"unsafe"
 )
@@ -25,6 +26,9 @@ func ConsoleLog(message string) error {
 	if !initialized {
 		return fmt.Errorf("REAPER functions not initialized")
 	}
+	if err := requireMainThread("ShowConsoleMsg"); err != nil {
+		return err
+	}
 
 	if showConsoleMsgPtr == nil {
 		return fmt.Errorf("ShowConsoleMsg function not available")
@@ -36,34 +40,96 @@ func ConsoleLog(message string) error {
 	return nil
 }
 
-// ShowConsoleMsg is a direct wrapper for REAPER's ShowConsoleMsg function
-// This version is safe to call from any goroutine
+// ShowConsoleMsg writes a message to the REAPER console from any goroutine. Off the
+// main thread the message is written at the next timer tick.
 func ShowConsoleMsg(message string) error {
-	consoleMutex.Lock()
-	defer consoleMutex.Unlock()
-
 	if !initialized {
 		return fmt.Errorf("REAPER functions not initialized")
 	}
 
-	if showConsoleMsgPtr == nil {
-		return fmt.Errorf("ShowConsoleMsg function not available")
+	if IsMainThread() {
+		return ConsoleLog(message)
 	}
 
-	// Run on main thread if not already there
-	done := make(chan struct{})
+	Defer(func() {
+		ConsoleLog(message)
+	})
+	return nil
+}
+
+// ConsoleWriter writes structured output to REAPER's console. Use it from the main
+// thread; the Console variable is the writer to use.
+type ConsoleWriter struct{}
+
+// Console writes to REAPER's console
+var Console ConsoleWriter
+
+// Print writes a line
+func (ConsoleWriter) Print(message string) error {
+	return ConsoleLog(message)
+}
 
-	go func() {
-		runtime.LockOSThread()
-		defer runtime.UnlockOSThread()
+// Printf writes a formatted line
+func (ConsoleWriter) Printf(format string, args ...interface{}) error {
+	return ConsoleLog(fmt.Sprintf(format, args...))
+}
 
-		cMessage := C.CString(message + "\n")
-		defer C.free(unsafe.Pointer(cMessage))
-		C.plugin_bridge_call_show_console_msg(showConsoleMsgPtr, cMessage)
+// Section writes a header that starts a new block of output
+func (ConsoleWriter) Section(title string) error {
+	return ConsoleLog("\n=== " + title + " ===")
+}
 
-		close(done)
-	}()
+// Clear empties the console
+func (ConsoleWriter) Clear() error {
+	funcPtr, err := projectFunc("ClearConsole")
+	if err != nil {
+		return err
+	}
 
-	<-done
+	consoleMutex.Lock()
+	defer consoleMutex.Unlock()
+
+	C.plugin_bridge_call_clear_console(funcPtr)
 	return nil
 }
+
+// Log lines waiting to be mirrored to the console
+var (
+	mirrorMutex   sync.Mutex
+	mirroredLines []string
+)
+
+// MirrorLogToConsole shows log messages up to level (a logger.Level constant) in the
+// console as well as the log file. A negative level turns mirroring off. Messages can
+// come from any goroutine, so they are queued and written at the next timer tick.
+func MirrorLogToConsole(level int) {
+	if level < logger.LevelError {
+		logger.SetMirror(0, nil)
+		return
+	}
+
+	logger.SetMirror(level, func(level int, funcName, message string) {
+		line := fmt.Sprintf("[%s] %s: %s", strings.ToUpper(logger.LevelName(level)), funcName, message)
+
+		mirrorMutex.Lock()
+		flush := len(mirroredLines) == 0
+		mirroredLines = append(mirroredLines, line)
+		mirrorMutex.Unlock()
+
+		if flush {
+			Defer(flushMirroredLines)
+		}
+	})
+}
+
+// flushMirroredLines writes the queued log lines to the console
+func flushMirroredLines() {
+	mirrorMutex.Lock()
+	lines := mirroredLines
+	mirroredLines = nil
+	mirrorMutex.Unlock()
+
+	if len(lines) > 0 {
+		ConsoleLog(strings.Join(lines, "\n"))
+	}
+}
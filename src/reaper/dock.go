@@ -0,0 +1,68 @@
+package reaper
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../c -I${SRCDIR}/../../sdk
+#include "../c/bridge.h"
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"fmt"
+	"unsafe"
+)
+
+// DockWindowAdd adds a window (an HWND; an NSView on macOS) to REAPER's docker.
+// ident is used by REAPER to remember which docker the window was placed in;
+// allowShow lets REAPER show the docker if it is hidden.
+func DockWindowAdd(hwnd unsafe.Pointer, name, ident string, allowShow bool) error {
+	if hwnd == nil {
+		return fmt.Errorf("cannot dock a nil window")
+	}
+
+	funcPtr, err := projectFunc("DockWindowAddEx")
+	if err != nil {
+		return err
+	}
+
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+	cIdent := C.CString(ident)
+	defer C.free(unsafe.Pointer(cIdent))
+
+	C.plugin_bridge_call_dock_window_add_ex(funcPtr, hwnd, cName, cIdent, C.bool(allowShow))
+	return nil
+}
+
+// DockWindowRemove removes a window from REAPER's docker
+func DockWindowRemove(hwnd unsafe.Pointer) error {
+	funcPtr, err := projectFunc("DockWindowRemove")
+	if err != nil {
+		return err
+	}
+
+	C.plugin_bridge_call_dock_window_remove(funcPtr, hwnd)
+	return nil
+}
+
+// DockWindowActivate brings a docked window's tab to the front
+func DockWindowActivate(hwnd unsafe.Pointer) error {
+	funcPtr, err := projectFunc("DockWindowActivate")
+	if err != nil {
+		return err
+	}
+
+	C.plugin_bridge_call_dock_window_activate(funcPtr, hwnd)
+	return nil
+}
+
+// DockIsChildOfDock reports whether a window is docked, and if so whether its docker is floating
+func DockIsChildOfDock(hwnd unsafe.Pointer) (docked bool, floating bool, err error) {
+	funcPtr, err := projectFunc("DockIsChildOfDock")
+	if err != nil {
+		return false, false, err
+	}
+
+	var cFloating C.bool
+	index := int(C.plugin_bridge_call_dock_is_child_of_dock(funcPtr, hwnd, &cFloating))
+	return index >= 0, bool(cFloating), nil
+}
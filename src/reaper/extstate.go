@@ -26,12 +26,9 @@ func GetExtState(section, key string) (string, error) {
 	}
 
 	// Get the function pointer
-	cFuncName := C.CString("GetExtState")
-	defer C.free(unsafe.Pointer(cFuncName))
-
-	getFuncPtr := C.plugin_bridge_call_get_func(C.plugin_bridge_get_get_func(), cFuncName)
-	if getFuncPtr == nil {
-		return "", fmt.Errorf("could not get GetExtState function pointer")
+	getFuncPtr, err := projectFunc("GetExtState")
+	if err != nil {
+		return "", err
 	}
 
 	// Prepare the parameters
@@ -61,12 +58,9 @@ func SetExtState(section, key, value string, persist bool) error {
 	}
 
 	// Get the function pointer
-	cFuncName := C.CString("SetExtState")
-	defer C.free(unsafe.Pointer(cFuncName))
-
-	getFuncPtr := C.plugin_bridge_call_get_func(C.plugin_bridge_get_get_func(), cFuncName)
-	if getFuncPtr == nil {
-		return fmt.Errorf("could not get SetExtState function pointer")
+	getFuncPtr, err := projectFunc("SetExtState")
+	if err != nil {
+		return err
 	}
 
 	// Prepare the parameters
@@ -101,12 +95,9 @@ func HasExtState(section, key string) (bool, error) {
 	}
 
 	// Get the function pointer
-	cFuncName := C.CString("HasExtState")
-	defer C.free(unsafe.Pointer(cFuncName))
-
-	getFuncPtr := C.plugin_bridge_call_get_func(C.plugin_bridge_get_get_func(), cFuncName)
-	if getFuncPtr == nil {
-		return false, fmt.Errorf("could not get HasExtState function pointer")
+	getFuncPtr, err := projectFunc("HasExtState")
+	if err != nil {
+		return false, err
 	}
 
 	// Prepare the parameters
@@ -132,12 +123,9 @@ func DeleteExtState(section, key string) error {
 	}
 
 	// Get the function pointer
-	cFuncName := C.CString("DeleteExtState")
-	defer C.free(unsafe.Pointer(cFuncName))
-
-	getFuncPtr := C.plugin_bridge_call_get_func(C.plugin_bridge_get_get_func(), cFuncName)
-	if getFuncPtr == nil {
-		return fmt.Errorf("could not get DeleteExtState function pointer")
+	getFuncPtr, err := projectFunc("DeleteExtState")
+	if err != nil {
+		return err
 	}
 
 	// Prepare the parameters
@@ -153,3 +141,74 @@ func DeleteExtState(section, key string) error {
 
 	return nil
 }
+
+// projExtStateKeySize is the buffer size for project ext state keys
+const projExtStateKeySize = 256
+
+// GetProjExtState gets a value stored in the current project, or "" if there is none
+func GetProjExtState(section, key string) (string, error) {
+	funcPtr, err := projectFunc("GetProjExtState")
+	if err != nil {
+		return "", err
+	}
+
+	cSection := C.CString(section)
+	defer C.free(unsafe.Pointer(cSection))
+	cKey := C.CString(key)
+	defer C.free(unsafe.Pointer(cKey))
+
+	value, _ := readString(defaultConfigBufferSize, func(buf *cBuffer) bool {
+		C.plugin_bridge_call_get_proj_ext_state(funcPtr, nil, cSection, cKey, buf.ptr, buf.cSize())
+		return true
+	})
+	return value, nil
+}
+
+// SetProjExtState stores a value in the current project. It is saved with the project
+// and marks it as changed; an empty value deletes the key.
+func SetProjExtState(section, key, value string) error {
+	if key == "" {
+		return fmt.Errorf("project ext state key is empty")
+	}
+
+	funcPtr, err := projectFunc("SetProjExtState")
+	if err != nil {
+		return err
+	}
+
+	cSection := C.CString(section)
+	defer C.free(unsafe.Pointer(cSection))
+	cKey := C.CString(key)
+	defer C.free(unsafe.Pointer(cKey))
+	cValue := C.CString(value)
+	defer C.free(unsafe.Pointer(cValue))
+
+	C.plugin_bridge_call_set_proj_ext_state(funcPtr, nil, cSection, cKey, cValue)
+	logger.Debug("Set project ext state: [%s]%s (%d bytes)", section, key, len(value))
+	return nil
+}
+
+// DeleteProjExtState deletes a value stored in the current project
+func DeleteProjExtState(section, key string) error {
+	return SetProjExtState(section, key, "")
+}
+
+// GetProjExtStateKeys lists the keys stored in a section of the current project
+func GetProjExtStateKeys(section string) ([]string, error) {
+	funcPtr, err := projectFunc("EnumProjExtState")
+	if err != nil {
+		return nil, err
+	}
+
+	cSection := C.CString(section)
+	defer C.free(unsafe.Pointer(cSection))
+
+	buf := getBuffer(projExtStateKeySize)
+	defer buf.release()
+
+	var keys []string
+	for i := 0; C.plugin_bridge_call_enum_proj_ext_state(funcPtr, nil, cSection, C.int(i), buf.ptr, buf.cSize()); i++ {
+		keys = append(keys, buf.String())
+	}
+	return keys, nil
+}
@@ -0,0 +1,488 @@
+// Package fake provides an in-memory implementation of reaper.ReaperAPI, so logic written
+// against the interface can run without REAPER: build a project of tracks, FX and
+// parameters, run the code, then inspect what it changed.
+package fake
+
+import (
+	"fmt"
+	"go-reaper/src/reaper"
+	"strconv"
+	"sync"
+	"unsafe"
+)
+
+// Param is a parameter of a fake FX
+type Param struct {
+	Name     string
+	Ident    string // REAPER's identifier for built-in parameters, e.g. ":wet"
+	Value    float64
+	Min, Max float64 // Range of Value; a zero range is treated as 0-1
+
+	// Format renders a value the way the plugin displays it, e.g. "250.0 ms". The
+	// default prints the value with two decimals.
+	Format func(value float64) string
+}
+
+// FX is an effect on a fake track
+type FX struct {
+	Name    string
+	GUID    string // Defaults to one made from the FX's address
+	Enabled bool
+	Offline bool
+	Params  []*Param
+	Config  map[string]string // Named config parameters, e.g. "fx_ident"
+}
+
+// Track is a fake track
+type Track struct {
+	Name string
+	FX   []*FX
+}
+
+// Reaper is an in-memory project. It is safe for concurrent use.
+type Reaper struct {
+	mutex    sync.Mutex
+	Tracks   []*Track
+	Selected *Track // The track GetSelectedTrack returns, or nil for none
+}
+
+var _ reaper.ReaperAPI = (*Reaper)(nil)
+
+// New creates a project with the given tracks and selects the first one
+func New(tracks ...*Track) *Reaper {
+	r := &Reaper{Tracks: tracks}
+	if len(tracks) > 0 {
+		r.Selected = tracks[0]
+	}
+	return r
+}
+
+// Handle returns the handle the API uses for track
+func Handle(track *Track) unsafe.Pointer {
+	return unsafe.Pointer(track)
+}
+
+// NewParam creates a 0-1 parameter
+func NewParam(name string, value float64) *Param {
+	return &Param{Name: name, Value: value, Max: 1}
+}
+
+// track resolves a handle, checking that the track is in the project
+func (r *Reaper) track(handle unsafe.Pointer) (*Track, error) {
+	for _, t := range r.Tracks {
+		if unsafe.Pointer(t) == handle {
+			return t, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid track")
+}
+
+// fx resolves a track handle and FX index
+func (r *Reaper) fx(handle unsafe.Pointer, fxIndex int) (*FX, error) {
+	t, err := r.track(handle)
+	if err != nil {
+		return nil, err
+	}
+	if fxIndex < 0 || fxIndex >= len(t.FX) {
+		return nil, fmt.Errorf("FX index %d is out of range", fxIndex)
+	}
+	return t.FX[fxIndex], nil
+}
+
+// param resolves a track handle, FX index and parameter index
+func (r *Reaper) param(handle unsafe.Pointer, fxIndex, paramIndex int) (*Param, error) {
+	fx, err := r.fx(handle, fxIndex)
+	if err != nil {
+		return nil, err
+	}
+	if paramIndex < 0 || paramIndex >= len(fx.Params) {
+		return nil, fmt.Errorf("parameter index %d is out of range", paramIndex)
+	}
+	return fx.Params[paramIndex], nil
+}
+
+// guid returns the FX's GUID
+func (fx *FX) guid() string {
+	if fx.GUID != "" {
+		return fx.GUID
+	}
+	return fmt.Sprintf("{%p}", fx)
+}
+
+// valueRange returns a parameter's range, defaulting to 0-1
+func (p *Param) valueRange() (float64, float64) {
+	if p.Max <= p.Min {
+		return 0, 1
+	}
+	return p.Min, p.Max
+}
+
+// format renders a value with the parameter's formatter
+func (p *Param) format(value float64) string {
+	if p.Format != nil {
+		return p.Format(value)
+	}
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
+
+// GetSelectedTrack implements reaper.ReaperAPI
+func (r *Reaper) GetSelectedTrack() (unsafe.Pointer, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.Selected == nil {
+		return nil, fmt.Errorf("no track selected")
+	}
+	return Handle(r.Selected), nil
+}
+
+// GetTrackName implements reaper.ReaperAPI
+func (r *Reaper) GetTrackName(track unsafe.Pointer) (string, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	t, err := r.track(track)
+	if err != nil {
+		return "", err
+	}
+	return t.Name, nil
+}
+
+// GetTrackFXCount implements reaper.ReaperAPI
+func (r *Reaper) GetTrackFXCount(track unsafe.Pointer) (int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	t, err := r.track(track)
+	if err != nil {
+		return 0, err
+	}
+	return len(t.FX), nil
+}
+
+// GetTrackFXName implements reaper.ReaperAPI
+func (r *Reaper) GetTrackFXName(track unsafe.Pointer, fxIndex int) (string, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	fx, err := r.fx(track, fxIndex)
+	if err != nil {
+		return "", err
+	}
+	return fx.Name, nil
+}
+
+// GetTrackFXEnabled implements reaper.ReaperAPI
+func (r *Reaper) GetTrackFXEnabled(track unsafe.Pointer, fxIndex int) (bool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	fx, err := r.fx(track, fxIndex)
+	if err != nil {
+		return false, err
+	}
+	return fx.Enabled, nil
+}
+
+// SetTrackFXEnabled implements reaper.ReaperAPI
+func (r *Reaper) SetTrackFXEnabled(track unsafe.Pointer, fxIndex int, enabled bool) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	fx, err := r.fx(track, fxIndex)
+	if err != nil {
+		return err
+	}
+	fx.Enabled = enabled
+	return nil
+}
+
+// GetTrackFXOffline implements reaper.ReaperAPI
+func (r *Reaper) GetTrackFXOffline(track unsafe.Pointer, fxIndex int) (bool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	fx, err := r.fx(track, fxIndex)
+	if err != nil {
+		return false, err
+	}
+	return fx.Offline, nil
+}
+
+// SetTrackFXOffline implements reaper.ReaperAPI
+func (r *Reaper) SetTrackFXOffline(track unsafe.Pointer, fxIndex int, offline bool) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	fx, err := r.fx(track, fxIndex)
+	if err != nil {
+		return err
+	}
+	fx.Offline = offline
+	return nil
+}
+
+// MoveTrackFX implements reaper.ReaperAPI
+func (r *Reaper) MoveTrackFX(track unsafe.Pointer, fromIndex int, toIndex int) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	t, err := r.track(track)
+	if err != nil {
+		return err
+	}
+	count := len(t.FX)
+	if fromIndex < 0 || fromIndex >= count || toIndex < 0 || toIndex >= count {
+		return fmt.Errorf("FX move %d -> %d is out of range (chain has %d FX)", fromIndex, toIndex, count)
+	}
+
+	moved := t.FX[fromIndex]
+	chain := append(t.FX[:fromIndex:fromIndex], t.FX[fromIndex+1:]...)
+	t.FX = append(chain[:toIndex:toIndex], append([]*FX{moved}, chain[toIndex:]...)...)
+	return nil
+}
+
+// GetTrackFXGUID implements reaper.ReaperAPI
+func (r *Reaper) GetTrackFXGUID(track unsafe.Pointer, fxIndex int) (string, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	fx, err := r.fx(track, fxIndex)
+	if err != nil {
+		return "", err
+	}
+	return fx.guid(), nil
+}
+
+// GetTrackFXNamedConfigParam implements reaper.ReaperAPI
+func (r *Reaper) GetTrackFXNamedConfigParam(track unsafe.Pointer, fxIndex int, name string) (string, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	fx, err := r.fx(track, fxIndex)
+	if err != nil {
+		return "", err
+	}
+	value, ok := fx.Config[name]
+	if !ok {
+		return "", fmt.Errorf("FX %d has no config parameter %s", fxIndex, name)
+	}
+	return value, nil
+}
+
+// GetTrackFXParamCount implements reaper.ReaperAPI
+func (r *Reaper) GetTrackFXParamCount(track unsafe.Pointer, fxIndex int) (int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	fx, err := r.fx(track, fxIndex)
+	if err != nil {
+		return 0, err
+	}
+	return len(fx.Params), nil
+}
+
+// GetTrackFXParamName implements reaper.ReaperAPI
+func (r *Reaper) GetTrackFXParamName(track unsafe.Pointer, fxIndex int, paramIndex int) (string, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	p, err := r.param(track, fxIndex, paramIndex)
+	if err != nil {
+		return "", err
+	}
+	return p.Name, nil
+}
+
+// GetTrackFXParamValue implements reaper.ReaperAPI
+func (r *Reaper) GetTrackFXParamValue(track unsafe.Pointer, fxIndex int, paramIndex int) (float64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	p, err := r.param(track, fxIndex, paramIndex)
+	if err != nil {
+		return 0, err
+	}
+	return p.Value, nil
+}
+
+// GetTrackFXParamValueWithRange implements reaper.ReaperAPI
+func (r *Reaper) GetTrackFXParamValueWithRange(track unsafe.Pointer, fxIndex int, paramIndex int) (value, min, max float64, err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	p, err := r.param(track, fxIndex, paramIndex)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	min, max = p.valueRange()
+	return p.Value, min, max, nil
+}
+
+// GetTrackFXParamFormatted implements reaper.ReaperAPI
+func (r *Reaper) GetTrackFXParamFormatted(track unsafe.Pointer, fxIndex int, paramIndex int) (string, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	p, err := r.param(track, fxIndex, paramIndex)
+	if err != nil {
+		return "", err
+	}
+	return p.format(p.Value), nil
+}
+
+// FormatTrackFXParamValue implements reaper.ReaperAPI
+func (r *Reaper) FormatTrackFXParamValue(track unsafe.Pointer, fxIndex int, paramIndex int, value float64) (string, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	p, err := r.param(track, fxIndex, paramIndex)
+	if err != nil {
+		return "", err
+	}
+	return p.format(value), nil
+}
+
+// SetTrackFXParamValue implements reaper.ReaperAPI. Values are clamped to the range, as
+// plugins do.
+func (r *Reaper) SetTrackFXParamValue(track unsafe.Pointer, fxIndex int, paramIndex int, value float64) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	p, err := r.param(track, fxIndex, paramIndex)
+	if err != nil {
+		return err
+	}
+	min, max := p.valueRange()
+	if value < min {
+		value = min
+	} else if value > max {
+		value = max
+	}
+	p.Value = value
+	return nil
+}
+
+// GetTrackFXParamFromIdent implements reaper.ReaperAPI
+func (r *Reaper) GetTrackFXParamFromIdent(track unsafe.Pointer, fxIndex int, ident string) (int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	fx, err := r.fx(track, fxIndex)
+	if err != nil {
+		return -1, err
+	}
+	for i, p := range fx.Params {
+		if p.Ident == ident {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("FX %d has no parameter %s", fxIndex, ident)
+}
+
+// ReadFXParameters implements reaper.ReaperAPI
+func (r *Reaper) ReadFXParameters(track unsafe.Pointer, fxIndex int) ([]reaper.FXParameter, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	fx, err := r.fx(track, fxIndex)
+	if err != nil {
+		return nil, err
+	}
+	parameters := make([]reaper.FXParameter, len(fx.Params))
+	for i, p := range fx.Params {
+		min, max := p.valueRange()
+		parameters[i] = reaper.FXParameter{
+			Index:          i,
+			Name:           p.Name,
+			Value:          p.Value,
+			FormattedValue: p.format(p.Value),
+			Min:            min,
+			Max:            max,
+		}
+	}
+	return parameters, nil
+}
+
+// ReadFXParameterValues implements reaper.ReaperAPI
+func (r *Reaper) ReadFXParameterValues(track unsafe.Pointer, fxIndex int, count int) ([]reaper.FXParameter, int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	fx, err := r.fx(track, fxIndex)
+	if err != nil {
+		return nil, 0, err
+	}
+	if count <= 0 {
+		return nil, 0, fmt.Errorf("no parameters to read")
+	}
+	values := make([]reaper.FXParameter, min(count, len(fx.Params)))
+	for i := range values {
+		p := fx.Params[i]
+		values[i] = reaper.FXParameter{Index: i, Value: p.Value, FormattedValue: p.format(p.Value)}
+	}
+	return values, len(fx.Params), nil
+}
+
+// BatchGetTrackFXStates implements reaper.ReaperAPI
+func (r *Reaper) BatchGetTrackFXStates(track unsafe.Pointer) ([]reaper.FXState, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	t, err := r.track(track)
+	if err != nil {
+		return nil, err
+	}
+	states := make([]reaper.FXState, len(t.FX))
+	for i, fx := range t.FX {
+		states[i] = reaper.FXState{Index: i, Enabled: fx.Enabled, Offline: fx.Offline}
+	}
+	return states, nil
+}
+
+// BatchSetTrackFXStates implements reaper.ReaperAPI
+func (r *Reaper) BatchSetTrackFXStates(track unsafe.Pointer, states []reaper.FXState) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, state := range states {
+		fx, err := r.fx(track, state.Index)
+		if err != nil {
+			return err
+		}
+		fx.Enabled = state.Enabled
+		fx.Offline = state.Offline
+	}
+	return nil
+}
+
+// BatchSetParameters implements reaper.ReaperAPI, computing each change the way the
+// bridge does. Changes to parameters that don't exist are returned as failed.
+func (r *Reaper) BatchSetParameters(changes []reaper.ParameterChange) ([]reaper.ParameterChange, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var failed []reaper.ParameterChange
+	for _, change := range changes {
+		p, err := r.param(change.Track, change.FXIndex, change.ParamIndex)
+		if err != nil {
+			failed = append(failed, change)
+			continue
+		}
+
+		var value float64
+		switch change.Op {
+		case reaper.ParamAdd:
+			value = p.Value + change.Value
+		case reaper.ParamMultiply:
+			value = p.Value * change.Value
+		case reaper.ParamClamp:
+			value = max(change.Min, min(p.Value, change.Max))
+		default:
+			value = change.Value
+		}
+		low, high := p.valueRange()
+		p.Value = max(low, min(value, high))
+	}
+	return failed, nil
+}
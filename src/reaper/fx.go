@@ -9,62 +9,111 @@ import "C"
 import (
 	"encoding/json"
 	"fmt"
+	"go-reaper/src/pkg/logger"
+	"math"
+	"strconv"
+	"strings"
 	"unsafe"
 )
 
 // GetTrackFXCount gets the number of FX on a track
 func GetTrackFXCount(track unsafe.Pointer) (int, error) {
-	if !initialized {
-		return 0, fmt.Errorf("REAPER functions not initialized")
-	}
+	defer profileCall("TrackFX_GetCount", false)()
 
-	cFuncName := C.CString("TrackFX_GetCount")
-	defer C.free(unsafe.Pointer(cFuncName))
-
-	getFuncPtr := C.plugin_bridge_call_get_func(C.plugin_bridge_get_get_func(), cFuncName)
-	if getFuncPtr == nil {
-		return 0, fmt.Errorf("could not get TrackFX_GetCount function pointer")
+	getFuncPtr, err := projectFunc("TrackFX_GetCount")
+	if err != nil {
+		return 0, err
 	}
 
 	count := C.plugin_bridge_call_track_fx_get_count(getFuncPtr, track)
 	return int(count), nil
 }
 
-// GetTrackFXName gets the name of an FX
-func GetTrackFXName(track unsafe.Pointer, fxIndex int) (string, error) {
-	if !initialized {
-		return "", fmt.Errorf("REAPER functions not initialized")
+// GetFocusedFX returns the track FX whose window most recently had focus. Take FX on
+// media items are not supported.
+func GetFocusedFX() (unsafe.Pointer, int, error) {
+	getFuncPtr, err := projectFunc("GetFocusedFX2")
+	if err != nil {
+		return nil, -1, err
+	}
+
+	// Bit 0 is set for track FX; 2 means take FX, and 4 is added once the window loses focus
+	var trackNumber, itemNumber, fxNumber C.int
+	result := int(C.plugin_bridge_call_get_focused_fx2(getFuncPtr, &trackNumber, &itemNumber, &fxNumber))
+	if result&1 == 0 {
+		return nil, -1, fmt.Errorf("no track FX focused")
+	}
+
+	// Track number 0 is the master track
+	var track unsafe.Pointer
+	if trackNumber == 0 {
+		track, err = GetMasterTrack()
+	} else {
+		track, err = GetTrack(int(trackNumber) - 1)
+	}
+	if err != nil {
+		return nil, -1, err
 	}
 
-	cFuncName := C.CString("TrackFX_GetFXName")
-	defer C.free(unsafe.Pointer(cFuncName))
+	return track, int(fxNumber), nil
+}
 
-	getFuncPtr := C.plugin_bridge_call_get_func(C.plugin_bridge_get_get_func(), cFuncName)
-	if getFuncPtr == nil {
-		return "", fmt.Errorf("could not get TrackFX_GetFXName function pointer")
+// GetLastTouchedFX returns the track FX parameter that was last changed. Take FX are not
+// supported.
+func GetLastTouchedFX() (unsafe.Pointer, int, int, error) {
+	getFuncPtr, err := projectFunc("GetLastTouchedFX")
+	if err != nil {
+		return nil, -1, -1, err
 	}
 
-	// Allocate buffer for the name
-	buf := (*C.char)(C.malloc(C.size_t(256)))
-	defer C.free(unsafe.Pointer(buf))
+	var trackNumber, fxNumber, paramNumber C.int
+	if !bool(C.plugin_bridge_call_get_last_touched_fx(getFuncPtr, &trackNumber, &fxNumber, &paramNumber)) {
+		return nil, -1, -1, fmt.Errorf("no FX parameter touched")
+	}
 
-	C.plugin_bridge_call_track_fx_get_name(getFuncPtr, track, C.int(fxIndex), buf, C.int(256))
+	// The high word of the track number is set for take FX
+	if trackNumber>>16 != 0 {
+		return nil, -1, -1, fmt.Errorf("the last touched parameter is on a take FX")
+	}
 
-	return C.GoString(buf), nil
+	// Track number 0 is the master track
+	var track unsafe.Pointer
+	if trackNumber == 0 {
+		track, err = GetMasterTrack()
+	} else {
+		track, err = GetTrack(int(trackNumber) - 1)
+	}
+	if err != nil {
+		return nil, -1, -1, err
+	}
+
+	return track, int(fxNumber), int(paramNumber), nil
 }
 
-// GetTrackFXParamCount gets the number of parameters for an FX
-func GetTrackFXParamCount(track unsafe.Pointer, fxIndex int) (int, error) {
-	if !initialized {
-		return 0, fmt.Errorf("REAPER functions not initialized")
+// GetTrackFXName gets the name of an FX
+func GetTrackFXName(track unsafe.Pointer, fxIndex int) (string, error) {
+	defer profileCall("TrackFX_GetFXName", false)()
+
+	getFuncPtr, err := projectFunc("TrackFX_GetFXName")
+	if err != nil {
+		return "", err
 	}
 
-	cFuncName := C.CString("TrackFX_GetNumParams")
-	defer C.free(unsafe.Pointer(cFuncName))
+	name, _ := readString(GetStringBufferSize(), func(buf *cBuffer) bool {
+		C.plugin_bridge_call_track_fx_get_name(getFuncPtr, track, C.int(fxIndex), buf.ptr, buf.cSize())
+		return true
+	})
 
-	getFuncPtr := C.plugin_bridge_call_get_func(C.plugin_bridge_get_get_func(), cFuncName)
-	if getFuncPtr == nil {
-		return 0, fmt.Errorf("could not get TrackFX_GetNumParams function pointer")
+	return name, nil
+}
+
+// GetTrackFXParamCount gets the number of parameters for an FX
+func GetTrackFXParamCount(track unsafe.Pointer, fxIndex int) (int, error) {
+	defer profileCall("TrackFX_GetNumParams", false)()
+
+	getFuncPtr, err := projectFunc("TrackFX_GetNumParams")
+	if err != nil {
+		return 0, err
 	}
 
 	count := C.plugin_bridge_call_track_fx_get_param_count(getFuncPtr, track, C.int(fxIndex))
@@ -73,39 +122,28 @@ func GetTrackFXParamCount(track unsafe.Pointer, fxIndex int) (int, error) {
 
 // GetTrackFXParamName gets the name of a parameter
 func GetTrackFXParamName(track unsafe.Pointer, fxIndex int, paramIndex int) (string, error) {
-	if !initialized {
-		return "", fmt.Errorf("REAPER functions not initialized")
-	}
-
-	cFuncName := C.CString("TrackFX_GetParamName")
-	defer C.free(unsafe.Pointer(cFuncName))
+	defer profileCall("TrackFX_GetParamName", false)()
 
-	getFuncPtr := C.plugin_bridge_call_get_func(C.plugin_bridge_get_get_func(), cFuncName)
-	if getFuncPtr == nil {
-		return "", fmt.Errorf("could not get TrackFX_GetParamName function pointer")
+	getFuncPtr, err := projectFunc("TrackFX_GetParamName")
+	if err != nil {
+		return "", err
 	}
 
-	// Allocate buffer for the name
-	buf := (*C.char)(C.malloc(C.size_t(256)))
-	defer C.free(unsafe.Pointer(buf))
-
-	C.plugin_bridge_call_track_fx_get_param_name(getFuncPtr, track, C.int(fxIndex), C.int(paramIndex), buf, C.int(256))
+	name, _ := readString(GetStringBufferSize(), func(buf *cBuffer) bool {
+		C.plugin_bridge_call_track_fx_get_param_name(getFuncPtr, track, C.int(fxIndex), C.int(paramIndex), buf.ptr, buf.cSize())
+		return true
+	})
 
-	return C.GoString(buf), nil
+	return name, nil
 }
 
 // GetTrackFXParamValue gets the normalized value (0.0-1.0) of a parameter
 func GetTrackFXParamValue(track unsafe.Pointer, fxIndex int, paramIndex int) (float64, error) {
-	if !initialized {
-		return 0, fmt.Errorf("REAPER functions not initialized")
-	}
-
-	cFuncName := C.CString("TrackFX_GetParam")
-	defer C.free(unsafe.Pointer(cFuncName))
+	defer profileCall("TrackFX_GetParam", false)()
 
-	getFuncPtr := C.plugin_bridge_call_get_func(C.plugin_bridge_get_get_func(), cFuncName)
-	if getFuncPtr == nil {
-		return 0, fmt.Errorf("could not get TrackFX_GetParam function pointer")
+	getFuncPtr, err := projectFunc("TrackFX_GetParam")
+	if err != nil {
+		return 0, err
 	}
 
 	value := C.plugin_bridge_call_track_fx_get_param(getFuncPtr, track, C.int(fxIndex), C.int(paramIndex), nil, nil)
@@ -114,46 +152,233 @@ func GetTrackFXParamValue(track unsafe.Pointer, fxIndex int, paramIndex int) (fl
 
 // GetTrackFXParamFormatted gets the formatted value of a parameter as a string
 func GetTrackFXParamFormatted(track unsafe.Pointer, fxIndex int, paramIndex int) (string, error) {
-	if !initialized {
-		return "", fmt.Errorf("REAPER functions not initialized")
+	defer profileCall("TrackFX_GetFormattedParamValue", false)()
+
+	getFuncPtr, err := projectFunc("TrackFX_GetFormattedParamValue")
+	if err != nil {
+		return "", err
 	}
 
-	cFuncName := C.CString("TrackFX_GetFormattedParamValue")
-	defer C.free(unsafe.Pointer(cFuncName))
+	formatted, _ := readString(GetStringBufferSize(), func(buf *cBuffer) bool {
+		C.plugin_bridge_call_track_fx_get_param_formatted(getFuncPtr, track, C.int(fxIndex), C.int(paramIndex), buf.ptr, buf.cSize())
+		return true
+	})
 
-	getFuncPtr := C.plugin_bridge_call_get_func(C.plugin_bridge_get_get_func(), cFuncName)
-	if getFuncPtr == nil {
-		return "", fmt.Errorf("could not get TrackFX_GetFormattedParamValue function pointer")
+	return formatted, nil
+}
+
+// SetTrackFXParamValue sets the value of a parameter
+func SetTrackFXParamValue(track unsafe.Pointer, fxIndex int, paramIndex int, value float64) error {
+	defer profileCall("TrackFX_SetParam", false)()
+
+	getFuncPtr, err := projectFunc("TrackFX_SetParam")
+	if err != nil {
+		return err
 	}
 
-	// Allocate buffer for the formatted value
-	buf := (*C.char)(C.malloc(C.size_t(256)))
-	defer C.free(unsafe.Pointer(buf))
+	C.plugin_bridge_call_track_fx_set_param(getFuncPtr, track, C.int(fxIndex), C.int(paramIndex), C.double(value))
 
-	C.plugin_bridge_call_track_fx_get_param_formatted(getFuncPtr, track, C.int(fxIndex), C.int(paramIndex), buf, C.int(256))
+	return nil
+}
+
+// FormatTrackFXParamValue formats a normalized value for a parameter without applying it
+func FormatTrackFXParamValue(track unsafe.Pointer, fxIndex int, paramIndex int, value float64) (string, error) {
+	defer profileCall("TrackFX_FormatParamValue", false)()
+
+	getFuncPtr, err := projectFunc("TrackFX_FormatParamValue")
+	if err != nil {
+		return "", err
+	}
 
-	return C.GoString(buf), nil
+	formatted, ok := readString(GetStringBufferSize(), func(buf *cBuffer) bool {
+		return bool(C.plugin_bridge_call_track_fx_format_param_value(getFuncPtr, track, C.int(fxIndex), C.int(paramIndex), C.double(value), buf.ptr, buf.cSize()))
+	})
+	if !ok {
+		return "", fmt.Errorf("plugin does not support formatting parameter %d", paramIndex)
+	}
+
+	return formatted, nil
 }
 
-// SetTrackFXParamValue sets the value of a parameter
-func SetTrackFXParamValue(track unsafe.Pointer, fxIndex int, paramIndex int, value float64) error {
+// GetTrackFXEnabled reports whether an FX is enabled (false means bypassed)
+func GetTrackFXEnabled(track unsafe.Pointer, fxIndex int) (bool, error) {
+	defer profileCall("TrackFX_GetEnabled", false)()
+
+	getFuncPtr, err := projectFunc("TrackFX_GetEnabled")
+	if err != nil {
+		return false, err
+	}
+
+	enabled := C.plugin_bridge_call_track_fx_get_enabled(getFuncPtr, track, C.int(fxIndex))
+	return bool(enabled), nil
+}
+
+// SetTrackFXEnabled enables or bypasses an FX
+func SetTrackFXEnabled(track unsafe.Pointer, fxIndex int, enabled bool) error {
+	defer profileCall("TrackFX_SetEnabled", false)()
+
+	getFuncPtr, err := projectFunc("TrackFX_SetEnabled")
+	if err != nil {
+		return err
+	}
+
+	C.plugin_bridge_call_track_fx_set_enabled(getFuncPtr, track, C.int(fxIndex), C.bool(enabled))
+	return nil
+}
+
+// GetTrackFXOffline reports whether an FX is offline
+func GetTrackFXOffline(track unsafe.Pointer, fxIndex int) (bool, error) {
+	defer profileCall("TrackFX_GetOffline", false)()
+
+	getFuncPtr, err := projectFunc("TrackFX_GetOffline")
+	if err != nil {
+		return false, err
+	}
+
+	offline := C.plugin_bridge_call_track_fx_get_offline(getFuncPtr, track, C.int(fxIndex))
+	return bool(offline), nil
+}
+
+// SetTrackFXOffline sets an FX offline (unloaded) or back online
+func SetTrackFXOffline(track unsafe.Pointer, fxIndex int, offline bool) error {
+	defer profileCall("TrackFX_SetOffline", false)()
+
+	getFuncPtr, err := projectFunc("TrackFX_SetOffline")
+	if err != nil {
+		return err
+	}
+
+	C.plugin_bridge_call_track_fx_set_offline(getFuncPtr, track, C.int(fxIndex), C.bool(offline))
+	return nil
+}
+
+// MoveTrackFX moves an FX within the track's chain so that it ends up at toIndex
+func MoveTrackFX(track unsafe.Pointer, fromIndex int, toIndex int) error {
 	if !initialized {
 		return fmt.Errorf("REAPER functions not initialized")
 	}
 
-	cFuncName := C.CString("TrackFX_SetParam")
-	defer C.free(unsafe.Pointer(cFuncName))
-
-	getFuncPtr := C.plugin_bridge_call_get_func(C.plugin_bridge_get_get_func(), cFuncName)
-	if getFuncPtr == nil {
-		return fmt.Errorf("could not get TrackFX_SetParam function pointer")
+	count, err := GetTrackFXCount(track)
+	if err != nil {
+		return err
+	}
+	if fromIndex < 0 || fromIndex >= count || toIndex < 0 || toIndex >= count {
+		return fmt.Errorf("FX move %d -> %d is out of range (chain has %d FX)", fromIndex, toIndex, count)
+	}
+	if fromIndex == toIndex {
+		return nil
 	}
 
-	C.plugin_bridge_call_track_fx_set_param(getFuncPtr, track, C.int(fxIndex), C.int(paramIndex), C.double(value))
+	getFuncPtr, err := projectFunc("TrackFX_CopyToTrack")
+	if err != nil {
+		return err
+	}
 
+	C.plugin_bridge_call_track_fx_copy_to_track(getFuncPtr, track, C.int(fromIndex), track, C.int(toIndex), C.bool(true))
 	return nil
 }
 
+// SetTrackFXParamFromUnitValue sets a parameter to a value expressed in the plugin's display
+// units (e.g. 250 for "250.0 ms"). The matching normalized value is found by bisection over
+// TrackFX_FormatParamValue, so the parameter's display mapping must be monotonic.
+// Returns the normalized value that was applied.
+func SetTrackFXParamFromUnitValue(track unsafe.Pointer, fxIndex int, paramIndex int, target float64) (float64, error) {
+	return SetParamFromUnitValue(Native{}, track, fxIndex, paramIndex, target)
+}
+
+// SetParamFromUnitValue is SetTrackFXParamFromUnitValue against any ReaperAPI
+func SetParamFromUnitValue(api ReaperAPI, track unsafe.Pointer, fxIndex int, paramIndex int, target float64) (float64, error) {
+	// Read the display value at both ends of the range to find the direction of the mapping
+	lowText, err := api.FormatTrackFXParamValue(track, fxIndex, paramIndex, 0)
+	if err != nil {
+		return 0, err
+	}
+	highText, err := api.FormatTrackFXParamValue(track, fxIndex, paramIndex, 1)
+	if err != nil {
+		return 0, err
+	}
+
+	lowValue, ok := parseFormattedNumber(lowText)
+	if !ok {
+		return 0, fmt.Errorf("parameter value %q is not numeric", lowText)
+	}
+	highValue, ok := parseFormattedNumber(highText)
+	if !ok {
+		return 0, fmt.Errorf("parameter value %q is not numeric", highText)
+	}
+
+	ascending := highValue >= lowValue
+	minValue, maxValue := lowValue, highValue
+	if !ascending {
+		minValue, maxValue = highValue, lowValue
+	}
+	if target < minValue || target > maxValue {
+		return 0, fmt.Errorf("value %.4g is outside the parameter range (%s to %s)", target, lowText, highText)
+	}
+
+	// Bisect the normalized range
+	lo, hi := 0.0, 1.0
+	for i := 0; i < 32; i++ {
+		mid := (lo + hi) / 2
+		text, err := api.FormatTrackFXParamValue(track, fxIndex, paramIndex, mid)
+		if err != nil {
+			return 0, err
+		}
+
+		value, ok := parseFormattedNumber(text)
+		if !ok {
+			return 0, fmt.Errorf("parameter value %q is not numeric", text)
+		}
+
+		if (value < target) == ascending {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+
+	normalized := (lo + hi) / 2
+	if err := api.SetTrackFXParamValue(track, fxIndex, paramIndex, normalized); err != nil {
+		return 0, err
+	}
+
+	return normalized, nil
+}
+
+// parseFormattedNumber extracts the leading number from a formatted value like "-6.0 dB".
+// A "k" suffix ("1.2k", "2.5 kHz") scales by 1000 and "-inf" parses as negative infinity.
+func parseFormattedNumber(formatted string) (float64, bool) {
+	s := strings.TrimSpace(formatted)
+
+	lower := strings.ToLower(s)
+	if strings.HasPrefix(lower, "-inf") {
+		return math.Inf(-1), true
+	}
+	if strings.HasPrefix(lower, "inf") || strings.HasPrefix(lower, "+inf") {
+		return math.Inf(1), true
+	}
+
+	end := 0
+	for end < len(s) {
+		c := s[end]
+		if (c >= '0' && c <= '9') || c == '.' || ((c == '-' || c == '+') && end == 0) {
+			end++
+			continue
+		}
+		break
+	}
+
+	value, err := strconv.ParseFloat(s[:end], 64)
+	if err != nil {
+		return 0, false
+	}
+
+	if suffix := strings.TrimSpace(s[end:]); strings.HasPrefix(suffix, "k") || strings.HasPrefix(suffix, "K") {
+		value *= 1000
+	}
+	return value, true
+}
+
 // LogFXParameters logs all parameters of an FX to the REAPER console
 func LogFXParameters(track unsafe.Pointer, fxIndex int) error {
 	// Get FX name
@@ -162,7 +387,7 @@ func LogFXParameters(track unsafe.Pointer, fxIndex int) error {
 		return fmt.Errorf("failed to get FX name: %v", err)
 	}
 
-	ConsoleLog(fmt.Sprintf("FX: %s", fxName))
+	Console.Section(fxName)
 
 	// Get parameter count
 	paramCount, err := GetTrackFXParamCount(track, fxIndex)
@@ -170,7 +395,7 @@ func LogFXParameters(track unsafe.Pointer, fxIndex int) error {
 		return fmt.Errorf("failed to get parameter count: %v", err)
 	}
 
-	ConsoleLog(fmt.Sprintf("Parameter count: %d", paramCount))
+	Console.Printf("Parameter count: %d", paramCount)
 
 	// Log each parameter
 	for i := 0; i < paramCount; i++ {
@@ -189,7 +414,7 @@ func LogFXParameters(track unsafe.Pointer, fxIndex int) error {
 			return fmt.Errorf("failed to get formatted parameter value: %v", err)
 		}
 
-		ConsoleLog(fmt.Sprintf("  Param #%d: %s = %.4f (%s)", i, paramName, paramValue, paramFormatted))
+		Console.Printf("  Param #%d: %s = %.4f (%s)", i, paramName, paramValue, paramFormatted)
 	}
 
 	return nil
@@ -215,67 +440,140 @@ func LogCurrentFX() error {
 
 // GetTrackFXParamValueWithRange gets the normalized value and range of a parameter
 func GetTrackFXParamValueWithRange(track unsafe.Pointer, fxIndex int, paramIndex int) (value, min, max float64, err error) {
-	if !initialized {
-		return 0, 0, 0, fmt.Errorf("REAPER functions not initialized")
-	}
-
-	cFuncName := C.CString("TrackFX_GetParam")
-	defer C.free(unsafe.Pointer(cFuncName))
+	defer profileCall("TrackFX_GetParam", false)()
 
-	getFuncPtr := C.plugin_bridge_call_get_func(C.plugin_bridge_get_get_func(), cFuncName)
-	if getFuncPtr == nil {
-		return 0, 0, 0, fmt.Errorf("could not get TrackFX_GetParam function pointer")
+	getFuncPtr, err := projectFunc("TrackFX_GetParam")
+	if err != nil {
+		return 0, 0, 0, err
 	}
 
-	// Allocate memory for min and max values
-	minPtr := (*C.double)(C.malloc(C.size_t(unsafe.Sizeof(C.double(0)))))
-	maxPtr := (*C.double)(C.malloc(C.size_t(unsafe.Sizeof(C.double(0)))))
-	defer C.free(unsafe.Pointer(minPtr))
-	defer C.free(unsafe.Pointer(maxPtr))
-
-	value = float64(C.plugin_bridge_call_track_fx_get_param(getFuncPtr, track, C.int(fxIndex), C.int(paramIndex), minPtr, maxPtr))
-	min = float64(*minPtr)
-	max = float64(*maxPtr)
+	var cMin, cMax C.double
+	value = float64(C.plugin_bridge_call_track_fx_get_param(getFuncPtr, track, C.int(fxIndex), C.int(paramIndex), &cMin, &cMax))
+	min = float64(cMin)
+	max = float64(cMax)
 
 	return value, min, max, nil
 }
 
 // GetFXParameters retrieves all parameters for a specific FX
 func GetFXParameters(track unsafe.Pointer, fxIndex int) (FXInfo, error) {
+	return GetFXInfo(Native{}, track, fxIndex)
+}
+
+// GetFXInfo is GetFXParameters against any ReaperAPI
+func GetFXInfo(api ReaperAPI, track unsafe.Pointer, fxIndex int) (FXInfo, error) {
 	result := FXInfo{
 		Index:      fxIndex,
 		Parameters: []FXParameter{},
 	}
 
 	// Get FX name
-	fxName, err := GetTrackFXName(track, fxIndex)
+	fxName, err := api.GetTrackFXName(track, fxIndex)
 	if err != nil {
 		return result, fmt.Errorf("failed to get FX name: %v", err)
 	}
 	result.Name = fxName
 
 	// Use the batch function to get all parameters at once
-	parameters, err := BatchGetFXParameters(track, fxIndex)
+	parameters, err := BatchGetParameters(api, track, fxIndex)
 	if err != nil {
 		return result, fmt.Errorf("failed to batch get FX parameters: %v", err)
 	}
 
-	// Add parameters to result
-	result.Parameters = parameters
+	// Add parameters to result, with the wet and delta controls labelled
+	result.Parameters = addMixPseudoParameters(api, track, fxIndex, parameters)
+
+	// Plugin identification
+	fillFXIdentity(api, track, &result)
+
+	// Bypass/offline state
+	if result.Enabled, err = api.GetTrackFXEnabled(track, fxIndex); err != nil {
+		return result, fmt.Errorf("failed to get FX enabled state: %v", err)
+	}
+	if result.Offline, err = api.GetTrackFXOffline(track, fxIndex); err != nil {
+		return result, fmt.Errorf("failed to get FX offline state: %v", err)
+	}
 
 	return result, nil
 }
 
+// BatchGetTrackFXStates gets the bypass and offline state of every FX on a track in a single call
+func BatchGetTrackFXStates(track unsafe.Pointer) ([]FXState, error) {
+	defer profileCall("batch: FX states", true)()
+
+	if err := batchReady("BatchGetTrackFXStates"); err != nil {
+		return nil, err
+	}
+
+	// Allocate memory for states (we'll allow up to 256 FX)
+	const maxFX = 256
+	stateBuf, stateData := getArray[C.fx_state_t](maxFX)
+	defer stateBuf.release()
+
+	var fxCount C.int
+	if !bool(C.plugin_bridge_batch_get_fx_states(track, stateData, C.int(maxFX), &fxCount)) {
+		return nil, fmt.Errorf("failed to get FX states")
+	}
+
+	stateSlice := arraySlice[C.fx_state_t](stateBuf, int(fxCount))
+
+	states := make([]FXState, len(stateSlice))
+	for i := range stateSlice {
+		states[i] = FXState{
+			Index:   int(stateSlice[i].index),
+			Enabled: bool(stateSlice[i].enabled),
+			Offline: bool(stateSlice[i].offline),
+		}
+	}
+
+	return states, nil
+}
+
+// BatchSetTrackFXStates sets the bypass and offline state of several FX in a single call
+func BatchSetTrackFXStates(track unsafe.Pointer, states []FXState) error {
+	defer profileCall("batch: set FX states", true)()
+
+	if err := batchReady("BatchSetTrackFXStates"); err != nil {
+		return err
+	}
+	if len(states) == 0 {
+		return nil
+	}
+
+	stateBuf, stateData := getArray[C.fx_state_t](len(states))
+	defer stateBuf.release()
+
+	stateSlice := arraySlice[C.fx_state_t](stateBuf, len(states))
+	for i, state := range states {
+		stateSlice[i] = C.fx_state_t{
+			index:   C.int(state.Index),
+			enabled: C.bool(state.Enabled),
+			offline: C.bool(state.Offline),
+		}
+	}
+
+	if !bool(C.plugin_bridge_batch_set_fx_states(track, stateData, C.int(len(states)))) {
+		return fmt.Errorf("failed to set FX states")
+	}
+
+	return nil
+}
+
 // GetCurrentFXInfo gets information about the FX on the currently selected track
 func GetCurrentFXInfo() ([]FXInfo, error) {
+	return GetSelectedTrackFXInfo(Native{})
+}
+
+// GetSelectedTrackFXInfo is GetCurrentFXInfo against any ReaperAPI
+func GetSelectedTrackFXInfo(api ReaperAPI) ([]FXInfo, error) {
 	// Get selected track
-	track, err := GetSelectedTrack()
+	track, err := api.GetSelectedTrack()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get selected track: %v", err)
 	}
 
 	// Get FX count
-	fxCount, err := GetTrackFXCount(track)
+	fxCount, err := api.GetTrackFXCount(track)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get FX count: %v", err)
 	}
@@ -283,7 +581,7 @@ func GetCurrentFXInfo() ([]FXInfo, error) {
 	// Gather info for all FX
 	result := make([]FXInfo, 0, fxCount)
 	for i := 0; i < fxCount; i++ {
-		fxInfo, err := GetFXParameters(track, i)
+		fxInfo, err := GetFXInfo(api, track, i)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get FX parameters: %v", err)
 		}
@@ -308,60 +606,129 @@ func GetCurrentFXInfoJSON() (string, error) {
 	return string(jsonData), nil
 }
 
+// maxBatchParams is the most parameters one batch call reads. Plugins with more are
+// read in several calls.
+const maxBatchParams = 512
+
 // BatchGetFXParameters gets all parameters for an FX in a single call
 // This reduces the number of C-Go crossings dramatically
 func BatchGetFXParameters(track unsafe.Pointer, fxIndex int) ([]FXParameter, error) {
-	if !initialized {
-		return nil, fmt.Errorf("REAPER functions not initialized")
-	}
-
-	// Allocate memory for parameters (we'll allow up to 512 parameters)
-	const maxParams = 512
-	paramData := (*C.fx_param_t)(C.malloc(C.size_t(maxParams) * C.size_t(unsafe.Sizeof(C.fx_param_t{}))))
-	if paramData == nil {
-		return nil, fmt.Errorf("failed to allocate memory for parameter data")
-	}
-	defer C.free(unsafe.Pointer(paramData))
-
-	// Allocate memory for parameter count
-	paramCount := (*C.int)(C.malloc(C.size_t(unsafe.Sizeof(C.int(0)))))
-	if paramCount == nil {
-		return nil, fmt.Errorf("failed to allocate memory for parameter count")
-	}
-	defer C.free(unsafe.Pointer(paramCount))
-
-	// Call the C function to get all parameters
-	result := C.plugin_bridge_batch_get_fx_parameters(
-		track,
-		C.int(fxIndex),
-		paramData,
-		C.int(maxParams),
-		paramCount,
-	)
-
-	if !bool(result) {
-		return nil, fmt.Errorf("failed to get FX parameters")
-	}
-
-	// Convert C parameter data to Go slice
-	count := int(*paramCount)
-	parameters := make([]FXParameter, count)
-
-	// Create a slice of paramData
-	// This creates a Go slice that points to the C array without copying it
-	paramSlice := (*[maxParams]C.fx_param_t)(unsafe.Pointer(paramData))[:count:count]
-
-	// Copy parameter data to Go slice
-	for i := 0; i < count; i++ {
-		parameters[i] = FXParameter{
-			Index:          i,
-			Name:           C.GoString(&paramSlice[i].name[0]),
-			Value:          float64(paramSlice[i].value),
-			FormattedValue: C.GoString(&paramSlice[i].formatted[0]),
-			Min:            float64(paramSlice[i].min),
-			Max:            float64(paramSlice[i].max),
+	return BatchGetParameters(Native{}, track, fxIndex)
+}
+
+// BatchGetParameters is BatchGetFXParameters against any ReaperAPI
+func BatchGetParameters(api ReaperAPI, track unsafe.Pointer, fxIndex int) ([]FXParameter, error) {
+	// Names and ranges don't change while the plugin is loaded, so after the first read
+	// only the values are read again
+	guid, err := api.GetTrackFXGUID(track, fxIndex)
+	if err == nil {
+		if metadata, ok := cachedParamMetadata(guid); ok {
+			if parameters, ok := readCachedParameters(api, track, fxIndex, metadata); ok {
+				return parameters, nil
+			}
 		}
 	}
 
+	parameters, err := api.ReadFXParameters(track, fxIndex)
+	if err != nil {
+		return nil, err
+	}
+	if guid != "" {
+		cacheParamMetadata(guid, parameters)
+	}
+	return parameters, nil
+}
+
+// readFXParameters reads the names, ranges and values of all parameters of an FX, in
+// chunks of maxBatchParams
+func readFXParameters(track unsafe.Pointer, fxIndex int) ([]FXParameter, error) {
+	defer profileCall("batch: FX parameters", true)()
+
+	if err := batchReady("BatchGetFXParameters"); err != nil {
+		return nil, err
+	}
+
+	// Allocate memory for one chunk of parameters
+	paramBuf, paramData := getArray[C.fx_param_t](maxBatchParams)
+	defer paramBuf.release()
+
+	var parameters []FXParameter
+	total := -1
+	for total < 0 || len(parameters) < total {
+		first := len(parameters)
+		var paramCount C.int
+
+		// Call the C function to get the next chunk of parameters
+		result := C.plugin_bridge_batch_get_fx_parameters(
+			track,
+			C.int(fxIndex),
+			C.int(first),
+			paramData,
+			C.int(maxBatchParams),
+			&paramCount,
+		)
+
+		if !bool(result) {
+			return nil, fmt.Errorf("failed to get FX parameters")
+		}
+
+		// The C function returns the full count, which only changes if the plugin
+		// changed its parameters between chunks
+		count := int(paramCount)
+		if total >= 0 && count != total {
+			logger.Warning("FX %d changed from %d to %d parameters while being read", fxIndex, total, count)
+			if count < first {
+				parameters = parameters[:count]
+			}
+			if count <= first {
+				break
+			}
+		}
+		total = count
+		if total > maxBatchParams && first == 0 {
+			logger.Debug("FX %d has %d parameters, reading them in chunks of %d", fxIndex, total, maxBatchParams)
+		}
+
+		read := min(total-first, maxBatchParams)
+		if read <= 0 {
+			break
+		}
+		if parameters == nil {
+			parameters = make([]FXParameter, 0, total)
+		}
+
+		// View the chunk as a Go slice without copying it
+		paramSlice := arraySlice[C.fx_param_t](paramBuf, read)
+
+		// Copy parameter data to Go slice
+		for i := range paramSlice {
+			index := first + i
+			parameter := FXParameter{
+				Index:          index,
+				Name:           C.GoString(&paramSlice[i].name[0]),
+				Value:          float64(paramSlice[i].value),
+				FormattedValue: C.GoString(&paramSlice[i].formatted[0]),
+				Min:            float64(paramSlice[i].min),
+				Max:            float64(paramSlice[i].max),
+			}
+
+			// The batch fields are fixed size; read long values again on their own
+			if isTruncated(parameter.Name, len(paramSlice[i].name)) {
+				if name, err := GetTrackFXParamName(track, fxIndex, index); err == nil {
+					parameter.Name = name
+				}
+			}
+			if isTruncated(parameter.FormattedValue, len(paramSlice[i].formatted)) {
+				if formatted, err := GetTrackFXParamFormatted(track, fxIndex, index); err == nil {
+					parameter.FormattedValue = formatted
+				}
+			}
+			parameters = append(parameters, parameter)
+		}
+	}
+
+	if parameters == nil {
+		parameters = []FXParameter{}
+	}
 	return parameters, nil
 }
@@ -0,0 +1,83 @@
+package reaper
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// FXCheck records an FX slot as it was when indices into the chain were captured
+type FXCheck struct {
+	Index      int
+	Name       string
+	ParamCount int
+}
+
+// ChainCheck records a track's FX chain at the time FX and parameter indices into it were
+// captured, e.g. when the assistant read the parameters it sent to the LLM. Verify it
+// before writing through those indices: once the user reorders, adds or removes FX, the
+// same index can belong to another plugin.
+type ChainCheck struct {
+	FXCount int
+	FX      []FXCheck
+}
+
+// CaptureChainCheck records the FX count, and the name and parameter count of each FX,
+// of a track's chain
+func CaptureChainCheck(track unsafe.Pointer) (ChainCheck, error) {
+	fxList, err := GetTrackFXSummaries(track)
+	if err != nil {
+		return ChainCheck{}, err
+	}
+
+	check := ChainCheck{FXCount: len(fxList), FX: make([]FXCheck, len(fxList))}
+	for i, fx := range fxList {
+		paramCount, err := GetTrackFXParamCount(track, fx.Index)
+		if err != nil {
+			return ChainCheck{}, fmt.Errorf("failed to get parameter count of FX %d: %v", fx.Index, err)
+		}
+		check.FX[i] = FXCheck{Index: fx.Index, Name: fx.Name, ParamCount: paramCount}
+	}
+	return check, nil
+}
+
+// Verify compares the track's chain with the capture. It returns an error when the FX
+// count changed, since any index may then point somewhere else. Otherwise stale holds
+// the FX, by index, whose name or parameter count no longer match, with the reason.
+func (c ChainCheck) Verify(track unsafe.Pointer) (stale map[int]string, err error) {
+	fxList, err := GetTrackFXSummaries(track)
+	if err != nil {
+		return nil, err
+	}
+	if len(fxList) != c.FXCount {
+		return nil, fmt.Errorf("the track had %d FX and now has %d", c.FXCount, len(fxList))
+	}
+
+	stale = make(map[int]string)
+	for _, captured := range c.FX {
+		current := fxList[captured.Index]
+		if current.Name != captured.Name {
+			stale[captured.Index] = fmt.Sprintf("%q is now %q", captured.Name, current.Name)
+			continue
+		}
+
+		paramCount, err := GetTrackFXParamCount(track, captured.Index)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get parameter count of FX %d: %v", captured.Index, err)
+		}
+		if paramCount != captured.ParamCount {
+			stale[captured.Index] = fmt.Sprintf("%q had %d parameters and now has %d", captured.Name, captured.ParamCount, paramCount)
+		}
+	}
+	return stale, nil
+}
+
+// ParamInRange reports whether paramIndex was a parameter of the FX at fxIndex when the
+// chain was captured. FX that weren't captured have no valid parameters.
+func (c ChainCheck) ParamInRange(fxIndex int, paramIndex int) bool {
+	for _, captured := range c.FX {
+		if captured.Index == fxIndex {
+			return paramIndex >= 0 && paramIndex < captured.ParamCount
+		}
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+package reaper
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../c -I${SRCDIR}/../../sdk
+#include "../c/bridge.h"
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"fmt"
+	"path"
+	"strings"
+	"unsafe"
+)
+
+// Named config parameters that identify a plugin
+const (
+	FXConfigType  = "fx_type"  // Plugin format, e.g. "VST", "VST3", "JS", "AU", "CLAP"
+	FXConfigIdent = "fx_ident" // Plugin file and/or unique ID, e.g. "reaeq.dll<1919247729"
+	FXConfigName  = "fx_name"  // Original plugin name, e.g. "VST: ReaEQ (Cockos)"
+
+	FXConfigVideoCode = "VIDEO_CODE" // A video processor's code; other FX don't provide it
+)
+
+// videoProcessorName is the plugin name of REAPER's video processor
+const videoProcessorName = "Video processor"
+
+// IsVideoProcessor reports whether an FX is REAPER's video processor, whose parameters
+// change the picture rather than the audio. Renamed instances are recognized by their
+// original name.
+func IsVideoProcessor(fx FXInfo) bool {
+	return strings.EqualFold(fx.Type, "video") || strings.EqualFold(fx.PluginName, videoProcessorName)
+}
+
+// GetTrackFXVideoCode returns the code of a video processor on a track. It fails for FX
+// that aren't video processors.
+func GetTrackFXVideoCode(track unsafe.Pointer, fxIndex int) (string, error) {
+	return GetTrackFXNamedConfigParam(track, fxIndex, FXConfigVideoCode)
+}
+
+// GetTakeFXVideoCode returns the code of a video processor on a take. It fails for FX
+// that aren't video processors.
+func GetTakeFXVideoCode(take unsafe.Pointer, fxIndex int) (string, error) {
+	return GetTakeFXNamedConfigParam(take, fxIndex, FXConfigVideoCode)
+}
+
+// GetTrackFXNamedConfigParam reads a named FX property via TrackFX_GetNamedConfigParm
+func GetTrackFXNamedConfigParam(track unsafe.Pointer, fxIndex int, name string) (string, error) {
+	return GetTrackFXNamedConfigParamWithSize(track, fxIndex, name, defaultConfigBufferSize)
+}
+
+// GetTrackFXNamedConfigParamWithSize reads a named FX property, starting with a buffer of
+// bufSize bytes. Values that don't fit are read again into a larger buffer.
+func GetTrackFXNamedConfigParamWithSize(track unsafe.Pointer, fxIndex int, name string, bufSize int) (string, error) {
+	defer profileCall("TrackFX_GetNamedConfigParm", false)()
+
+	getFuncPtr, err := projectFunc("TrackFX_GetNamedConfigParm")
+	if err != nil {
+		return "", err
+	}
+
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+
+	value, ok := readString(bufSize, func(buf *cBuffer) bool {
+		return bool(C.plugin_bridge_call_track_fx_get_named_config_parm(getFuncPtr, track, C.int(fxIndex), cName, buf.ptr, buf.cSize()))
+	})
+	if !ok {
+		return "", fmt.Errorf("FX %d does not provide %s", fxIndex, name)
+	}
+
+	return value, nil
+}
+
+// fillFXIdentity reads the identification metadata of an FX into info.
+// Missing properties (e.g. on older REAPER versions) are left empty.
+func fillFXIdentity(api ReaperAPI, track unsafe.Pointer, info *FXInfo) {
+	fxType, _ := api.GetTrackFXNamedConfigParam(track, info.Index, FXConfigType)
+	pluginID, _ := api.GetTrackFXNamedConfigParam(track, info.Index, FXConfigIdent)
+	originalName, _ := api.GetTrackFXNamedConfigParam(track, info.Index, FXConfigName)
+	setFXIdentity(info, fxType, pluginID, originalName)
+}
+
+// setFXIdentity fills info from the fx_type, fx_ident and fx_name properties, any of
+// which may be empty
+func setFXIdentity(info *FXInfo, fxType, pluginID, originalName string) {
+	info.Type = fxType
+	info.PluginID = pluginID
+
+	if originalName == "" {
+		// The instance may have been renamed, but its name is the best we have
+		originalName = info.Name
+	}
+	info.PluginName, info.Vendor = parsePluginName(originalName)
+}
+
+// parsePluginName splits a name like "VST3: Pro-Q 3 (FabFilter)" into plugin name and vendor
+func parsePluginName(fullName string) (name string, vendor string) {
+	name = strings.TrimSpace(fullName)
+
+	// Drop the format prefix ("VST:", "VST3i:", "JS:", ...)
+	if colon := strings.Index(name, ": "); colon > 0 && !strings.Contains(name[:colon], " ") {
+		name = name[colon+2:]
+	}
+
+	// The vendor is the last parenthesized group, unless it describes channels ("2->4ch", "32 out")
+	if strings.HasSuffix(name, ")") {
+		if open := strings.LastIndex(name, "("); open > 0 {
+			candidate := name[open+1 : len(name)-1]
+			if !strings.Contains(candidate, "->") && !strings.HasSuffix(candidate, "ch") && !strings.HasSuffix(candidate, " out") {
+				vendor = strings.TrimSpace(candidate)
+				name = strings.TrimSpace(name[:open])
+			}
+		}
+	}
+
+	return name, vendor
+}
+
+// IdentityKey returns a stable key for the plugin behind an FX instance, so a renamed
+// instance and the original resolve to the same entry in per-plugin maps
+func (fx FXInfo) IdentityKey() string {
+	format := strings.ToLower(strings.TrimSuffix(fx.Type, "i")) // VSTi and VST share IDs
+
+	if fx.PluginID != "" {
+		ident := fx.PluginID
+
+		// VST identifiers look like "file<uniqueID"; the unique ID is the same on every platform
+		if lt := strings.LastIndex(ident, "<"); lt >= 0 && lt < len(ident)-1 {
+			return format + ":" + strings.ToLower(ident[lt+1:])
+		}
+
+		// Otherwise use the file name without its platform-specific directory
+		return format + ":" + strings.ToLower(path.Base(strings.ReplaceAll(ident, "\\", "/")))
+	}
+
+	key := strings.ToLower(fx.PluginName)
+	if fx.Vendor != "" {
+		key += " (" + strings.ToLower(fx.Vendor) + ")"
+	}
+	if format != "" {
+		key = format + ":" + key
+	}
+	return key
+}
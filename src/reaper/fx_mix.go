@@ -0,0 +1,149 @@
+package reaper
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../c -I${SRCDIR}/../../sdk
+#include "../c/bridge.h"
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"fmt"
+	"unsafe"
+)
+
+// Identifiers REAPER uses for the built-in parameters every FX has
+const (
+	FXIdentWet    = ":wet"    // Wet/dry mix knob
+	FXIdentBypass = ":bypass" // Bypass state
+	FXIdentDelta  = ":delta"  // Delta solo (hear only what the FX changes)
+)
+
+// GetTrackFXParamFromIdent resolves a parameter identifier like ":wet" to a parameter index
+func GetTrackFXParamFromIdent(track unsafe.Pointer, fxIndex int, ident string) (int, error) {
+	defer profileCall("TrackFX_GetParamFromIdent", false)()
+
+	getFuncPtr, err := projectFunc("TrackFX_GetParamFromIdent")
+	if err != nil {
+		return -1, err
+	}
+
+	cIdent := C.CString(ident)
+	defer C.free(unsafe.Pointer(cIdent))
+
+	index := int(C.plugin_bridge_call_track_fx_get_param_from_ident(getFuncPtr, track, C.int(fxIndex), cIdent))
+	if index < 0 {
+		return -1, fmt.Errorf("FX %d has no parameter %s", fxIndex, ident)
+	}
+
+	return index, nil
+}
+
+// GetTrackFXWet gets the wet/dry mix of an FX (0.0 = dry, 1.0 = fully wet)
+func GetTrackFXWet(track unsafe.Pointer, fxIndex int) (float64, error) {
+	paramIndex, err := GetTrackFXParamFromIdent(track, fxIndex, FXIdentWet)
+	if err != nil {
+		return 0, err
+	}
+	return GetTrackFXParamValue(track, fxIndex, paramIndex)
+}
+
+// SetTrackFXWet sets the wet/dry mix of an FX (0.0 = dry, 1.0 = fully wet)
+func SetTrackFXWet(track unsafe.Pointer, fxIndex int, wet float64) error {
+	paramIndex, err := GetTrackFXParamFromIdent(track, fxIndex, FXIdentWet)
+	if err != nil {
+		return err
+	}
+	return SetTrackFXParamValue(track, fxIndex, paramIndex, clampNormalized(wet))
+}
+
+// GetTrackFXDeltaSolo reports whether delta solo is active for an FX
+func GetTrackFXDeltaSolo(track unsafe.Pointer, fxIndex int) (bool, error) {
+	paramIndex, err := GetTrackFXParamFromIdent(track, fxIndex, FXIdentDelta)
+	if err != nil {
+		return false, err
+	}
+	value, err := GetTrackFXParamValue(track, fxIndex, paramIndex)
+	if err != nil {
+		return false, err
+	}
+	return value >= 0.5, nil
+}
+
+// SetTrackFXDeltaSolo enables or disables delta solo for an FX
+func SetTrackFXDeltaSolo(track unsafe.Pointer, fxIndex int, enabled bool) error {
+	paramIndex, err := GetTrackFXParamFromIdent(track, fxIndex, FXIdentDelta)
+	if err != nil {
+		return err
+	}
+
+	value := 0.0
+	if enabled {
+		value = 1.0
+	}
+	return SetTrackFXParamValue(track, fxIndex, paramIndex, value)
+}
+
+// addMixPseudoParameters marks the wet and delta parameters of an FX and gives them
+// readable names and values. REAPER usually lists them after the plugin's own parameters;
+// if it doesn't, they are appended so callers always see them.
+func addMixPseudoParameters(api ReaperAPI, track unsafe.Pointer, fxIndex int, parameters []FXParameter) []FXParameter {
+	pseudo := []struct {
+		ident string
+		name  string
+	}{
+		{FXIdentWet, "Wet (FX mix)"},
+		{FXIdentDelta, "Delta solo"},
+	}
+
+	for _, p := range pseudo {
+		paramIndex, err := api.GetTrackFXParamFromIdent(track, fxIndex, p.ident)
+		if err != nil {
+			continue
+		}
+
+		var param *FXParameter
+		for i := range parameters {
+			if parameters[i].Index == paramIndex {
+				param = &parameters[i]
+				break
+			}
+		}
+
+		if param == nil {
+			value, err := api.GetTrackFXParamValue(track, fxIndex, paramIndex)
+			if err != nil {
+				continue
+			}
+			parameters = append(parameters, FXParameter{Index: paramIndex, Value: value, Min: 0, Max: 1})
+			param = &parameters[len(parameters)-1]
+		}
+
+		param.Ident = p.ident
+		param.Name = p.name
+		param.FormattedValue = formatMixValue(p.ident, param.Value)
+	}
+
+	return parameters
+}
+
+// formatMixValue formats a wet or delta value for display
+func formatMixValue(ident string, value float64) string {
+	if ident == FXIdentDelta {
+		if value >= 0.5 {
+			return "On"
+		}
+		return "Off"
+	}
+	return fmt.Sprintf("%.0f%%", value*100)
+}
+
+// clampNormalized clamps a value to the normalized 0-1 range
+func clampNormalized(value float64) float64 {
+	if value < 0 {
+		return 0
+	}
+	if value > 1 {
+		return 1
+	}
+	return value
+}
@@ -0,0 +1,97 @@
+package reaper
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../c -I${SRCDIR}/../../sdk
+#include "../c/bridge.h"
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"fmt"
+	"strings"
+	"unsafe"
+)
+
+// ParameterOp is how a ParameterChange computes the new value from the current one
+type ParameterOp int
+
+// Parameter operations; values match FX_PARAM_OP_* in bridge.h
+const (
+	ParamSet      ParameterOp = iota // Value
+	ParamAdd                         // Current value + Value
+	ParamMultiply                    // Current value * Value
+	ParamClamp                       // Current value limited to [Min, Max]
+)
+
+// ParseParameterOp parses an operation name: "set", "add", "multiply" or "clamp"
+func ParseParameterOp(name string) (ParameterOp, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "set", "=":
+		return ParamSet, nil
+	case "add", "+":
+		return ParamAdd, nil
+	case "multiply", "*", "x":
+		return ParamMultiply, nil
+	case "clamp":
+		return ParamClamp, nil
+	}
+	return ParamSet, fmt.Errorf("unknown parameter operation %q (use set, add, multiply or clamp)", name)
+}
+
+// ParameterChange is a change to a parameter of an FX on any track. The zero Op sets
+// Value; the others work from the current value, so one change list can e.g. lower every
+// threshold by 10% (ParamMultiply by 0.9) whatever each one is set to. The result is
+// kept within the parameter's range.
+type ParameterChange struct {
+	Track      unsafe.Pointer
+	FXIndex    int
+	ParamIndex int
+	Op         ParameterOp
+	Value      float64
+	Min, Max   float64 // Bounds for ParamClamp
+}
+
+// BatchSetParameters changes parameters of FX on any number of tracks in a single call,
+// computing relative changes from the current values on the C side. It returns the
+// changes REAPER refused, e.g. because the FX or parameter doesn't exist; the other
+// changes are still made. Call it inside an undo block.
+func BatchSetParameters(changes []ParameterChange) (failed []ParameterChange, err error) {
+	defer profileCall("batch: set parameters", true)()
+
+	if err := batchReady("BatchSetParameters"); err != nil {
+		return nil, err
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+
+	changeBuf, changeData := getArray[C.fx_param_change_t](len(changes))
+	defer changeBuf.release()
+
+	changeSlice := arraySlice[C.fx_param_change_t](changeBuf, len(changes))
+	for i, change := range changes {
+		changeSlice[i] = C.fx_param_change_t{
+			track:     change.Track,
+			fx_idx:    C.int(change.FXIndex),
+			param_idx: C.int(change.ParamIndex),
+			op:        C.int(change.Op),
+			value:     C.double(change.Value),
+			min:       C.double(change.Min),
+			max:       C.double(change.Max),
+		}
+	}
+
+	var applied C.int
+	if !bool(C.plugin_bridge_batch_set_param_values(changeData, C.int(len(changes)), &applied)) {
+		return nil, fmt.Errorf("failed to set parameters")
+	}
+
+	if int(applied) < len(changes) {
+		for i, change := range changeSlice {
+			if !bool(change.ok) {
+				failed = append(failed, changes[i])
+			}
+		}
+	}
+	return failed, nil
+}
@@ -0,0 +1,201 @@
+package reaper
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../c -I${SRCDIR}/../../sdk
+#include "../c/bridge.h"
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// fxGUIDSize is the buffer size guidToString needs
+const fxGUIDSize = 64
+
+// paramMetadata is the part of a parameter that stays the same while the plugin is loaded
+type paramMetadata struct {
+	Name     string
+	Min, Max float64
+}
+
+// The parameter metadata of each FX read this session, by FX GUID. FX GUIDs are unique
+// per plugin instance and survive moves between slots and tracks, so an entry stays
+// right until the plugin itself changes, which a different parameter count reveals.
+var (
+	paramCacheMutex   sync.Mutex
+	paramCache        = make(map[string][]paramMetadata)
+	paramCacheHits    atomic.Int64
+	paramCacheMisses  atomic.Int64
+	paramCacheRefused atomic.Int64 // Hits whose parameter count no longer matched; the full read replaces them
+)
+
+// ParamCacheStats reports how often BatchGetFXParameters could skip names and ranges
+type ParamCacheStats struct {
+	FX      int   // FX with cached metadata
+	Hits    int64 // Reads of values only
+	Misses  int64 // Full reads
+	Refused int64 // Cached metadata dropped because the parameter count changed
+}
+
+// GetParamCacheStats returns the parameter metadata cache counters for this session
+func GetParamCacheStats() ParamCacheStats {
+	paramCacheMutex.Lock()
+	fxCount := len(paramCache)
+	paramCacheMutex.Unlock()
+
+	return ParamCacheStats{
+		FX:      fxCount,
+		Hits:    paramCacheHits.Load(),
+		Misses:  paramCacheMisses.Load(),
+		Refused: paramCacheRefused.Load(),
+	}
+}
+
+// ClearParamCache forgets all cached parameter metadata, e.g. after a plugin changed its
+// parameter names without changing their number
+func ClearParamCache() {
+	paramCacheMutex.Lock()
+	defer paramCacheMutex.Unlock()
+
+	paramCache = make(map[string][]paramMetadata)
+}
+
+// GetTrackFXGUID returns the GUID of an FX instance, e.g. "{8D3C1F2A-...}". It follows
+// the plugin when it is moved, unlike its index.
+func GetTrackFXGUID(track unsafe.Pointer, fxIndex int) (string, error) {
+	defer profileCall("TrackFX_GetFXGUID", false)()
+
+	getGUID, err := projectFunc("TrackFX_GetFXGUID")
+	if err != nil {
+		return "", err
+	}
+	guidToString, err := projectFunc("guidToString")
+	if err != nil {
+		return "", err
+	}
+
+	buf := getBuffer(fxGUIDSize)
+	defer buf.release()
+
+	if !C.plugin_bridge_call_track_fx_get_fx_guid(getGUID, guidToString, track, C.int(fxIndex), buf.ptr, buf.cSize()) {
+		return "", fmt.Errorf("FX %d has no GUID", fxIndex)
+	}
+	return buf.String(), nil
+}
+
+// FindTrackFXByGUID returns the index of the FX with a GUID from GetTrackFXGUID, wherever
+// it has been moved to on the track
+func FindTrackFXByGUID(track unsafe.Pointer, guid string) (int, error) {
+	count, err := GetTrackFXCount(track)
+	if err != nil {
+		return -1, err
+	}
+	for i := 0; i < count; i++ {
+		if fxGUID, err := GetTrackFXGUID(track, i); err == nil && strings.EqualFold(fxGUID, guid) {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("no FX with GUID %s on the track", guid)
+}
+
+// cachedParamMetadata returns the parameter metadata cached for an FX
+func cachedParamMetadata(guid string) ([]paramMetadata, bool) {
+	paramCacheMutex.Lock()
+	defer paramCacheMutex.Unlock()
+
+	metadata, ok := paramCache[guid]
+	return metadata, ok
+}
+
+// cacheParamMetadata stores the metadata of freshly read parameters
+func cacheParamMetadata(guid string, parameters []FXParameter) {
+	metadata := make([]paramMetadata, len(parameters))
+	for i, param := range parameters {
+		metadata[i] = paramMetadata{Name: param.Name, Min: param.Min, Max: param.Max}
+	}
+
+	paramCacheMisses.Add(1)
+
+	paramCacheMutex.Lock()
+	defer paramCacheMutex.Unlock()
+
+	paramCache[guid] = metadata
+}
+
+// readCachedParameters reads only the values of an FX's parameters and combines them
+// with cached metadata. ok is false if the FX no longer has the cached parameter count.
+func readCachedParameters(api ReaperAPI, track unsafe.Pointer, fxIndex int, metadata []paramMetadata) ([]FXParameter, bool) {
+	count := len(metadata)
+	if count == 0 {
+		return nil, false
+	}
+
+	values, total, err := api.ReadFXParameterValues(track, fxIndex, count)
+	if err != nil {
+		return nil, false
+	}
+	if total != count || len(values) != count {
+		paramCacheRefused.Add(1)
+		return nil, false
+	}
+
+	parameters := make([]FXParameter, count)
+	for i, value := range values {
+		parameters[i] = FXParameter{
+			Index:          i,
+			Name:           metadata[i].Name,
+			Value:          value.Value,
+			FormattedValue: value.FormattedValue,
+			Min:            metadata[i].Min,
+			Max:            metadata[i].Max,
+		}
+	}
+
+	paramCacheHits.Add(1)
+	return parameters, true
+}
+
+// readFXParameterValues reads the values of the first count parameters of an FX, with
+// only Index, Value and FormattedValue set, and returns how many parameters it has
+func readFXParameterValues(track unsafe.Pointer, fxIndex int, count int) ([]FXParameter, int, error) {
+	defer profileCall("batch: FX parameter values", true)()
+
+	if err := batchReady("BatchGetFXParameters"); err != nil {
+		return nil, 0, err
+	}
+	if count <= 0 {
+		return nil, 0, fmt.Errorf("no parameters to read")
+	}
+
+	valueBuf, valueData := getArray[C.fx_value_t](count)
+	defer valueBuf.release()
+
+	var paramCount C.int
+	if !bool(C.plugin_bridge_batch_get_fx_values(track, C.int(fxIndex), valueData, C.int(count), &paramCount)) {
+		return nil, 0, fmt.Errorf("failed to get FX parameter values")
+	}
+	total := int(paramCount)
+
+	values := arraySlice[C.fx_value_t](valueBuf, min(count, total))
+	parameters := make([]FXParameter, len(values))
+	for i := range values {
+		parameters[i] = FXParameter{
+			Index:          i,
+			Value:          float64(values[i].value),
+			FormattedValue: C.GoString(&values[i].formatted[0]),
+		}
+
+		if isTruncated(parameters[i].FormattedValue, len(values[i].formatted)) {
+			if formatted, err := GetTrackFXParamFormatted(track, fxIndex, i); err == nil {
+				parameters[i].FormattedValue = formatted
+			}
+		}
+	}
+
+	return parameters, total, nil
+}
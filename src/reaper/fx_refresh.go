@@ -0,0 +1,158 @@
+package reaper
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../c -I${SRCDIR}/../../sdk
+#include "../c/bridge.h"
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"fmt"
+	"unsafe"
+)
+
+// paramRef names a parameter to refresh
+type paramRef struct {
+	FXIndex    int
+	ParamIndex int
+}
+
+// paramValue is a refreshed parameter value
+type paramValue struct {
+	Value     float64
+	Formatted string
+}
+
+// readParamValues reads the values of any parameters of a track's FX in one call. It
+// also returns the track's FX count. Parameters that no longer exist are left out of the
+// values.
+func readParamValues(track unsafe.Pointer, refs []paramRef) (map[paramRef]paramValue, int, error) {
+	defer profileCall("batch: parameter values", true)()
+
+	if err := batchReady("readParamValues"); err != nil {
+		return nil, 0, err
+	}
+	if track == nil {
+		return nil, 0, fmt.Errorf("invalid track")
+	}
+
+	count := len(refs)
+	if count == 0 {
+		fxCount, err := GetTrackFXCount(track)
+		return map[paramRef]paramValue{}, fxCount, err
+	}
+
+	refBuf, refData := getArray[C.fx_param_ref_t](count)
+	defer refBuf.release()
+
+	valueBuf, valueData := getArray[C.fx_value_t](count)
+	defer valueBuf.release()
+
+	refSlice := arraySlice[C.fx_param_ref_t](refBuf, count)
+	for i, ref := range refs {
+		refSlice[i] = C.fx_param_ref_t{fx_idx: C.int(ref.FXIndex), param_idx: C.int(ref.ParamIndex)}
+	}
+
+	var fxCount C.int
+	if !bool(C.plugin_bridge_batch_get_param_values(track, refData, C.int(count), valueData, &fxCount)) {
+		return nil, 0, fmt.Errorf("failed to get parameter values")
+	}
+
+	values := make(map[paramRef]paramValue, count)
+	for i, value := range arraySlice[C.fx_value_t](valueBuf, count) {
+		if !bool(value.valid) {
+			continue
+		}
+
+		formatted := C.GoString(&value.formatted[0])
+		if isTruncated(formatted, len(value.formatted)) {
+			if full, err := GetTrackFXParamFormatted(track, refs[i].FXIndex, refs[i].ParamIndex); err == nil {
+				formatted = full
+			}
+		}
+		values[refs[i]] = paramValue{Value: float64(value.value), Formatted: formatted}
+	}
+	return values, int(fxCount), nil
+}
+
+// RefreshFXValues re-reads the Value and FormattedValue of every parameter of already
+// loaded FX, in one call for the whole list. Names, ranges and the FX themselves are left
+// as they are, so use it to poll FX loaded with GetFXParameters rather than loading them
+// again. It fails, changing nothing, if a parameter no longer exists; it can't tell a
+// plugin was swapped for one with as many parameters, so callers that keep FX across user
+// edits check that too.
+func RefreshFXValues(track unsafe.Pointer, fxList []FXInfo) error {
+	values, _, err := readFXListValues(track, fxList)
+	if err != nil {
+		return err
+	}
+	return applyFXValues(fxList, values)
+}
+
+// readFXListValues reads the values of every parameter in fxList and the track's FX count
+func readFXListValues(track unsafe.Pointer, fxList []FXInfo) (map[paramRef]paramValue, int, error) {
+	var refs []paramRef
+	for _, fx := range fxList {
+		for _, param := range fx.Parameters {
+			refs = append(refs, paramRef{FXIndex: fx.Index, ParamIndex: param.Index})
+		}
+	}
+	return readParamValues(track, refs)
+}
+
+// applyFXValues stores read values in fxList, checking first that every parameter was read
+func applyFXValues(fxList []FXInfo, values map[paramRef]paramValue) error {
+	for _, fx := range fxList {
+		for _, param := range fx.Parameters {
+			if _, ok := values[paramRef{FXIndex: fx.Index, ParamIndex: param.Index}]; !ok {
+				return fmt.Errorf("FX %d (%s) no longer has parameter %d", fx.Index, fx.Name, param.Index)
+			}
+		}
+	}
+
+	for i := range fxList {
+		fx := &fxList[i]
+		for j := range fx.Parameters {
+			param := &fx.Parameters[j]
+			value := values[paramRef{FXIndex: fx.Index, ParamIndex: param.Index}]
+
+			param.Value = value.Value
+			param.FormattedValue = value.Formatted
+			if param.Ident != "" {
+				param.FormattedValue = formatMixValue(param.Ident, value.Value)
+			}
+		}
+	}
+	return nil
+}
+
+// RefreshValues re-reads the parameter values of the FX loaded into a collection with
+// LoadFX, in one call per track. It stops at the first track that is gone or whose FX
+// chain changed size, leaving that track's values as they were; load the collection
+// again then.
+func RefreshValues(c *TrackCollection) error {
+	for i := range c.refs {
+		ref := &c.refs[i]
+		fxList, ok := c.fx[ref.GUID]
+		if !ok {
+			continue
+		}
+
+		track, err := ref.Resolve()
+		if err != nil {
+			return err
+		}
+
+		values, fxCount, err := readFXListValues(track, fxList)
+		if err != nil {
+			return err
+		}
+		if fxCount != len(fxList) {
+			return fmt.Errorf("the track had %d FX and now has %d", len(fxList), fxCount)
+		}
+		if err := applyFXValues(fxList, values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
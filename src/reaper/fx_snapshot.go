@@ -0,0 +1,118 @@
+package reaper
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// FXSnapshot holds the parameter values of every FX on some tracks, to put back later.
+// Tracks and FX are stored by GUID, so a snapshot still applies after they are moved.
+type FXSnapshot struct {
+	Tracks []TrackSnapshot `json:"tracks"`
+}
+
+// TrackSnapshot holds the FX of one track in a snapshot
+type TrackSnapshot struct {
+	GUID string     `json:"guid"`
+	Name string     `json:"name"` // Name when captured, for display
+	FX   []FXValues `json:"fx"`
+}
+
+// FXValues holds the normalized value of every parameter of an FX, by parameter index
+type FXValues struct {
+	GUID   string    `json:"guid"`
+	Name   string    `json:"name"`
+	Values []float64 `json:"values"`
+}
+
+// CaptureFXSnapshot reads the parameter values of every FX on the tracks
+func CaptureFXSnapshot(tracks []unsafe.Pointer) (FXSnapshot, error) {
+	var snapshot FXSnapshot
+	for _, track := range tracks {
+		guid, err := GetTrackGUID(track)
+		if err != nil {
+			return FXSnapshot{}, err
+		}
+		name, _ := GetTrackName(track)
+
+		count, err := GetTrackFXCount(track)
+		if err != nil {
+			return FXSnapshot{}, err
+		}
+
+		trackSnapshot := TrackSnapshot{GUID: guid, Name: name}
+		for i := 0; i < count; i++ {
+			fxGUID, err := GetTrackFXGUID(track, i)
+			if err != nil {
+				return FXSnapshot{}, err
+			}
+			fxName, _ := GetTrackFXName(track, i)
+
+			parameters, err := BatchGetFXParameters(track, i)
+			if err != nil {
+				return FXSnapshot{}, fmt.Errorf("failed to read FX %d on %s: %v", i, name, err)
+			}
+			values := make([]float64, len(parameters))
+			for j, param := range parameters {
+				values[j] = param.Value
+			}
+
+			trackSnapshot.FX = append(trackSnapshot.FX, FXValues{GUID: fxGUID, Name: fxName, Values: values})
+		}
+		snapshot.Tracks = append(snapshot.Tracks, trackSnapshot)
+	}
+	return snapshot, nil
+}
+
+// FXCount returns the number of FX in the snapshot
+func (s FXSnapshot) FXCount() int {
+	count := 0
+	for _, track := range s.Tracks {
+		count += len(track.FX)
+	}
+	return count
+}
+
+// Apply writes the snapshot's values back. Tracks and FX that are gone, and FX whose
+// parameter count changed, are skipped and described in skipped. Call it inside an undo
+// block.
+func (s FXSnapshot) Apply() (applied int, skipped []string) {
+	for _, trackSnapshot := range s.Tracks {
+		ref := TrackRef{GUID: trackSnapshot.GUID}
+		track, err := ref.Resolve()
+		if err != nil {
+			skipped = append(skipped, fmt.Sprintf("%s: the track is gone", trackSnapshot.Name))
+			continue
+		}
+
+		for _, fx := range trackSnapshot.FX {
+			fxIndex, err := FindTrackFXByGUID(track, fx.GUID)
+			if err != nil {
+				skipped = append(skipped, fmt.Sprintf("%s, %s: the FX is gone", trackSnapshot.Name, fx.Name))
+				continue
+			}
+			count, err := GetTrackFXParamCount(track, fxIndex)
+			if err != nil || count != len(fx.Values) {
+				skipped = append(skipped, fmt.Sprintf("%s, %s: the parameters changed", trackSnapshot.Name, fx.Name))
+				continue
+			}
+
+			if err := setFXValues(track, fxIndex, fx.Values); err != nil {
+				skipped = append(skipped, fmt.Sprintf("%s, %s: %v", trackSnapshot.Name, fx.Name, err))
+				continue
+			}
+			applied++
+		}
+	}
+	return applied, skipped
+}
+
+// setFXValues sets every parameter of an FX, by parameter index
+func setFXValues(track unsafe.Pointer, fxIndex int, values []float64) error {
+	for param, value := range values {
+		if err := SetTrackFXParamValue(track, fxIndex, param, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package reaper_test
+
+import (
+	"fmt"
+	"go-reaper/src/reaper"
+	"go-reaper/src/reaper/fake"
+	"math"
+	"strings"
+	"testing"
+)
+
+// msParam is a parameter displaying 10-1000 ms on a linear scale
+func msParam(value float64) *fake.Param {
+	param := fake.NewParam("Attack", value)
+	param.Format = func(v float64) string { return fmt.Sprintf("%.4f ms", 10+990*v) }
+	return param
+}
+
+func TestSetParamFromUnitValue(t *testing.T) {
+	param := msParam(0)
+	track := &fake.Track{Name: "Drums", FX: []*fake.FX{{Name: "Compressor", Params: []*fake.Param{param}}}}
+	api := fake.New(track)
+
+	normalized, err := reaper.SetParamFromUnitValue(api, fake.Handle(track), 0, 0, 250)
+	if err != nil {
+		t.Fatalf("SetParamFromUnitValue: %v", err)
+	}
+	want := 240.0 / 990
+	if math.Abs(normalized-want) > 1e-6 {
+		t.Errorf("normalized = %.8f, want %.8f", normalized, want)
+	}
+	if param.Value != normalized {
+		t.Errorf("parameter value = %.8f, want the returned %.8f", param.Value, normalized)
+	}
+}
+
+func TestSetParamFromUnitValueDescending(t *testing.T) {
+	param := fake.NewParam("Gain", 0)
+	param.Format = func(v float64) string { return fmt.Sprintf("%.4f dB", 12-24*v) }
+	track := &fake.Track{FX: []*fake.FX{{Name: "EQ", Params: []*fake.Param{param}}}}
+	api := fake.New(track)
+
+	normalized, err := reaper.SetParamFromUnitValue(api, fake.Handle(track), 0, 0, -6)
+	if err != nil {
+		t.Fatalf("SetParamFromUnitValue: %v", err)
+	}
+	if math.Abs(normalized-0.75) > 1e-5 {
+		t.Errorf("normalized = %.8f, want 0.75", normalized)
+	}
+}
+
+func TestSetParamFromUnitValueErrors(t *testing.T) {
+	text := fake.NewParam("Mode", 0)
+	text.Format = func(float64) string { return "Vintage" }
+	track := &fake.Track{FX: []*fake.FX{{Name: "Compressor", Params: []*fake.Param{msParam(0.5), text}}}}
+	api := fake.New(track)
+
+	if _, err := reaper.SetParamFromUnitValue(api, fake.Handle(track), 0, 0, 5000); err == nil || !strings.Contains(err.Error(), "outside") {
+		t.Errorf("out of range value: err = %v, want an outside the range error", err)
+	}
+	if _, err := reaper.SetParamFromUnitValue(api, fake.Handle(track), 0, 1, 1); err == nil || !strings.Contains(err.Error(), "not numeric") {
+		t.Errorf("text parameter: err = %v, want a not numeric error", err)
+	}
+	if _, err := reaper.SetParamFromUnitValue(api, fake.Handle(track), 1, 0, 100); err == nil {
+		t.Error("missing FX: err = nil, want an error")
+	}
+	if track.FX[0].Params[0].Value != 0.5 {
+		t.Errorf("a failed call changed the parameter to %.4f", track.FX[0].Params[0].Value)
+	}
+}
+
+func TestBatchGetParameters(t *testing.T) {
+	fx := &fake.FX{
+		Name:   "Delay",
+		Params: []*fake.Param{msParam(0), fake.NewParam("Feedback", 0.25)},
+	}
+	fx.Params[1].Min, fx.Params[1].Max = 0, 2
+	track := &fake.Track{Name: "Vocal", FX: []*fake.FX{fx}}
+	api := fake.New(track)
+	handle := fake.Handle(track)
+
+	// The first read lists names, ranges and values
+	before := reaper.GetParamCacheStats()
+	parameters, err := reaper.BatchGetParameters(api, handle, 0)
+	if err != nil {
+		t.Fatalf("BatchGetParameters: %v", err)
+	}
+	if len(parameters) != 2 {
+		t.Fatalf("got %d parameters, want 2", len(parameters))
+	}
+	if p := parameters[0]; p.Index != 0 || p.Name != "Attack" || p.FormattedValue != "10.0000 ms" || p.Min != 0 || p.Max != 1 {
+		t.Errorf("parameter 0 = %+v", p)
+	}
+	if p := parameters[1]; p.Name != "Feedback" || p.Value != 0.25 || p.Max != 2 {
+		t.Errorf("parameter 1 = %+v", p)
+	}
+
+	// The second read takes names and ranges from the cache, but reads fresh values
+	fx.Params[0].Value = 1
+	parameters, err = reaper.BatchGetParameters(api, handle, 0)
+	if err != nil {
+		t.Fatalf("BatchGetParameters (cached): %v", err)
+	}
+	if p := parameters[0]; p.Name != "Attack" || p.Value != 1 || p.FormattedValue != "1000.0000 ms" {
+		t.Errorf("cached parameter 0 = %+v", p)
+	}
+	if p := parameters[1]; p.Name != "Feedback" || p.Max != 2 {
+		t.Errorf("cached parameter 1 = %+v", p)
+	}
+	if hits := reaper.GetParamCacheStats().Hits - before.Hits; hits != 1 {
+		t.Errorf("cache hits = %d, want 1", hits)
+	}
+
+	// A plugin that changed its parameter count is read in full again
+	fx.Params = append(fx.Params, fake.NewParam("Mix", 0.5))
+	parameters, err = reaper.BatchGetParameters(api, handle, 0)
+	if err != nil {
+		t.Fatalf("BatchGetParameters (changed): %v", err)
+	}
+	if len(parameters) != 3 || parameters[2].Name != "Mix" {
+		t.Errorf("after the plugin changed got %+v, want 3 parameters ending with Mix", parameters)
+	}
+	if refused := reaper.GetParamCacheStats().Refused - before.Refused; refused != 1 {
+		t.Errorf("refused cache entries = %d, want 1", refused)
+	}
+}
+
+func TestGetFXInfo(t *testing.T) {
+	wet := fake.NewParam("Wet", 0.5)
+	wet.Ident = reaper.FXIdentWet
+	fx := &fake.FX{
+		Name:    "My Verb",
+		Enabled: true,
+		Params:  []*fake.Param{fake.NewParam("Size", 0.3), wet},
+		Config:  map[string]string{reaper.FXConfigType: "VST3", reaper.FXConfigName: "VST3: ValhallaRoom (Valhalla DSP, LLC)"},
+	}
+	track := &fake.Track{FX: []*fake.FX{fx}}
+	api := fake.New(track)
+
+	info, err := reaper.GetFXInfo(api, fake.Handle(track), 0)
+	if err != nil {
+		t.Fatalf("GetFXInfo: %v", err)
+	}
+	if info.Name != "My Verb" || !info.Enabled || info.Offline {
+		t.Errorf("info = %+v", info)
+	}
+	if info.Type != "VST3" || info.PluginName != "ValhallaRoom" || info.Vendor != "Valhalla DSP, LLC" {
+		t.Errorf("identity = %q %q %q", info.Type, info.PluginName, info.Vendor)
+	}
+	if len(info.Parameters) != 2 {
+		t.Fatalf("got %d parameters, want 2", len(info.Parameters))
+	}
+	if p := info.Parameters[1]; p.Ident != reaper.FXIdentWet || p.Name != "Wet (FX mix)" || p.FormattedValue != "50%" {
+		t.Errorf("wet parameter = %+v", p)
+	}
+}
+
+func TestGetSelectedTrackFXInfo(t *testing.T) {
+	first := &fake.Track{FX: []*fake.FX{{Name: "EQ"}}}
+	second := &fake.Track{FX: []*fake.FX{{Name: "Gate"}, {Name: "Comp", Offline: true}}}
+	api := fake.New(first, second)
+	api.Selected = second
+
+	infos, err := reaper.GetSelectedTrackFXInfo(api)
+	if err != nil {
+		t.Fatalf("GetSelectedTrackFXInfo: %v", err)
+	}
+	if len(infos) != 2 || infos[0].Name != "Gate" || infos[1].Name != "Comp" || !infos[1].Offline {
+		t.Errorf("infos = %+v", infos)
+	}
+
+	api.Selected = nil
+	if _, err := reaper.GetSelectedTrackFXInfo(api); err == nil {
+		t.Error("no selected track: err = nil, want an error")
+	}
+}
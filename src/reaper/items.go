@@ -0,0 +1,327 @@
+package reaper
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../c -I${SRCDIR}/../../sdk
+#include "../c/bridge.h"
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"fmt"
+	"unsafe"
+)
+
+// Numeric item and take properties, from GetMediaItemInfo_Value and GetMediaItemTakeInfo_Value
+const (
+	itemPropertyPosition    = "D_POSITION"  // Item start in project time, seconds
+	itemPropertyLength      = "D_LENGTH"    // Item length in project time, seconds
+	takePropertyStartOffset = "D_STARTOFFS" // Where the take starts in its source, seconds
+	takePropertyPlayRate    = "D_PLAYRATE"  // 1 for normal speed
+	takePropertyPitch       = "D_PITCH"     // Pitch shift in semitones
+	takePropertyPreserve    = "B_PPITCH"    // Nonzero to keep the pitch when the play rate changes
+)
+
+// TakeInfo is the active take of a media item, with where it sits in the project
+type TakeInfo struct {
+	Item        unsafe.Pointer // MediaItem*
+	Take        unsafe.Pointer // MediaItem_Take*
+	Track       unsafe.Pointer // MediaTrack* the item is on
+	Name        string
+	Position    float64 // Item start in project time, seconds
+	Length      float64 // Item length in project time, seconds
+	StartOffset float64 // Where the take starts in its source, seconds
+	PlayRate    float64 // 1 for normal speed
+	Pitch       float64 // Pitch shift in semitones, on top of any change from the play rate
+	// PreservePitch keeps the pitch when the play rate changes; without it the rate
+	// changes the pitch too, like varispeed
+	PreservePitch bool
+}
+
+// End returns the item's end in project time
+func (t TakeInfo) End() float64 {
+	return t.Position + t.Length
+}
+
+// SourcePosition converts a project time within the item to a position in the take's
+// source, which is where take markers are placed. Stretch markers are not taken into
+// account.
+func (t TakeInfo) SourcePosition(projectTime float64) float64 {
+	return t.StartOffset + (projectTime-t.Position)*t.PlayRate
+}
+
+// ProjectTime converts a position in the take's source to project time, the inverse of
+// SourcePosition
+func (t TakeInfo) ProjectTime(sourcePosition float64) float64 {
+	if t.PlayRate == 0 {
+		return t.Position
+	}
+	return t.Position + (sourcePosition-t.StartOffset)/t.PlayRate
+}
+
+// ItemPosition converts a project time to a position in the item at the take's play
+// rate, which is where stretch markers are placed
+func (t TakeInfo) ItemPosition(projectTime float64) float64 {
+	return (projectTime - t.Position) * t.PlayRate
+}
+
+// CountSelectedItems returns the number of selected media items in the active project
+func CountSelectedItems() (int, error) {
+	funcPtr, err := projectFunc("CountSelectedMediaItems")
+	if err != nil {
+		return 0, err
+	}
+
+	return int(C.plugin_bridge_call_count_selected_media_items(funcPtr, nil)), nil
+}
+
+// GetSelectedItems returns the selected media items in the active project
+func GetSelectedItems() ([]unsafe.Pointer, error) {
+	count, err := CountSelectedItems()
+	if err != nil {
+		return nil, err
+	}
+
+	funcPtr, err := projectFunc("GetSelectedMediaItem")
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]unsafe.Pointer, 0, count)
+	for i := 0; i < count; i++ {
+		item := C.plugin_bridge_call_get_selected_media_item(funcPtr, nil, C.int(i))
+		if item == nil {
+			return nil, fmt.Errorf("no selected item at index %d", i)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// GetActiveTake returns an item's active take. Empty items have none.
+func GetActiveTake(item unsafe.Pointer) (unsafe.Pointer, error) {
+	if item == nil {
+		return nil, fmt.Errorf("invalid item")
+	}
+
+	funcPtr, err := projectFunc("GetActiveTake")
+	if err != nil {
+		return nil, err
+	}
+
+	take := C.plugin_bridge_call_get_active_take(funcPtr, item)
+	if take == nil {
+		return nil, fmt.Errorf("the item has no takes")
+	}
+	return take, nil
+}
+
+// GetItemInfoValue reads a numeric item property such as "D_POSITION"
+func GetItemInfoValue(item unsafe.Pointer, param string) (float64, error) {
+	if item == nil {
+		return 0, fmt.Errorf("invalid item")
+	}
+
+	funcPtr, err := projectFunc("GetMediaItemInfo_Value")
+	if err != nil {
+		return 0, err
+	}
+
+	cParam := C.CString(param)
+	defer C.free(unsafe.Pointer(cParam))
+
+	return float64(C.plugin_bridge_call_get_media_item_info_value(funcPtr, item, cParam)), nil
+}
+
+// SetItemInfoValue sets a numeric item property. Call it inside an undo block, and
+// UpdateItem afterwards.
+func SetItemInfoValue(item unsafe.Pointer, param string, value float64) error {
+	if item == nil {
+		return fmt.Errorf("invalid item")
+	}
+
+	funcPtr, err := projectFunc("SetMediaItemInfo_Value")
+	if err != nil {
+		return err
+	}
+
+	cParam := C.CString(param)
+	defer C.free(unsafe.Pointer(cParam))
+
+	if !C.plugin_bridge_call_set_media_item_info_value(funcPtr, item, cParam, C.double(value)) {
+		return fmt.Errorf("failed to set item %s", param)
+	}
+	return nil
+}
+
+// GetTakeInfoValue reads a numeric take property such as "D_PLAYRATE"
+func GetTakeInfoValue(take unsafe.Pointer, param string) (float64, error) {
+	if take == nil {
+		return 0, fmt.Errorf("invalid take")
+	}
+
+	funcPtr, err := projectFunc("GetMediaItemTakeInfo_Value")
+	if err != nil {
+		return 0, err
+	}
+
+	cParam := C.CString(param)
+	defer C.free(unsafe.Pointer(cParam))
+
+	return float64(C.plugin_bridge_call_get_media_item_take_info_value(funcPtr, take, cParam)), nil
+}
+
+// SetTakeInfoValue sets a numeric take property. Call it inside an undo block, and
+// UpdateItem afterwards.
+func SetTakeInfoValue(take unsafe.Pointer, param string, value float64) error {
+	if take == nil {
+		return fmt.Errorf("invalid take")
+	}
+
+	funcPtr, err := projectFunc("SetMediaItemTakeInfo_Value")
+	if err != nil {
+		return err
+	}
+
+	cParam := C.CString(param)
+	defer C.free(unsafe.Pointer(cParam))
+
+	if !C.plugin_bridge_call_set_media_item_take_info_value(funcPtr, take, cParam, C.double(value)) {
+		return fmt.Errorf("failed to set take %s", param)
+	}
+	return nil
+}
+
+// GetTakeName returns a take's name, usually its source file's name
+func GetTakeName(take unsafe.Pointer) (string, error) {
+	if take == nil {
+		return "", fmt.Errorf("invalid take")
+	}
+
+	funcPtr, err := projectFunc("GetTakeName")
+	if err != nil {
+		return "", err
+	}
+
+	name := C.plugin_bridge_call_get_take_name(funcPtr, take)
+	if name == nil {
+		return "", nil
+	}
+	return C.GoString(name), nil
+}
+
+// IsTakeValid reports whether a take pointer still refers to a take in the current
+// project, e.g. before applying a change worked out earlier
+func IsTakeValid(take unsafe.Pointer) bool {
+	if take == nil {
+		return false
+	}
+
+	validateFuncPtr, err := projectFunc("ValidatePtr2")
+	if err != nil {
+		return false
+	}
+
+	cTypeName := C.CString("MediaItem_Take*")
+	defer C.free(unsafe.Pointer(cTypeName))
+
+	return bool(C.plugin_bridge_call_validate_ptr2(validateFuncPtr, nil, take, cTypeName))
+}
+
+// GetItemTrack returns the track an item is on
+func GetItemTrack(item unsafe.Pointer) (unsafe.Pointer, error) {
+	if item == nil {
+		return nil, fmt.Errorf("invalid item")
+	}
+
+	funcPtr, err := projectFunc("GetMediaItem_Track")
+	if err != nil {
+		return nil, err
+	}
+
+	track := C.plugin_bridge_call_get_media_item_track(funcPtr, item)
+	if track == nil {
+		return nil, fmt.Errorf("the item is not on a track")
+	}
+	return track, nil
+}
+
+// UpdateItem redraws an item after its takes or markers changed
+func UpdateItem(item unsafe.Pointer) error {
+	if item == nil {
+		return fmt.Errorf("invalid item")
+	}
+
+	funcPtr, err := projectFunc("UpdateItemInProject")
+	if err != nil {
+		return err
+	}
+
+	C.plugin_bridge_call_update_item_in_project(funcPtr, item)
+	return nil
+}
+
+// GetTakeInfo reads an item's active take and where it sits in the project
+func GetTakeInfo(item unsafe.Pointer) (TakeInfo, error) {
+	take, err := GetActiveTake(item)
+	if err != nil {
+		return TakeInfo{}, err
+	}
+	return takeInfo(item, take)
+}
+
+// takeInfo reads a take's details
+func takeInfo(item, take unsafe.Pointer) (TakeInfo, error) {
+	track, err := GetItemTrack(item)
+	if err != nil {
+		return TakeInfo{}, err
+	}
+
+	info := TakeInfo{Item: item, Take: take, Track: track}
+	if info.Name, err = GetTakeName(take); err != nil {
+		return TakeInfo{}, err
+	}
+	if info.Position, err = GetItemInfoValue(item, itemPropertyPosition); err != nil {
+		return TakeInfo{}, err
+	}
+	if info.Length, err = GetItemInfoValue(item, itemPropertyLength); err != nil {
+		return TakeInfo{}, err
+	}
+	if info.StartOffset, err = GetTakeInfoValue(take, takePropertyStartOffset); err != nil {
+		return TakeInfo{}, err
+	}
+	if info.PlayRate, err = GetTakeInfoValue(take, takePropertyPlayRate); err != nil {
+		return TakeInfo{}, err
+	}
+	if info.Pitch, err = GetTakeInfoValue(take, takePropertyPitch); err != nil {
+		return TakeInfo{}, err
+	}
+	preserve, err := GetTakeInfoValue(take, takePropertyPreserve)
+	if err != nil {
+		return TakeInfo{}, err
+	}
+	info.PreservePitch = preserve != 0
+	return info, nil
+}
+
+// GetSelectedTakes returns the active takes of the selected items. Empty items are left
+// out.
+func GetSelectedTakes() ([]TakeInfo, error) {
+	items, err := GetSelectedItems()
+	if err != nil {
+		return nil, err
+	}
+
+	takes := make([]TakeInfo, 0, len(items))
+	for _, item := range items {
+		take, err := GetActiveTake(item)
+		if err != nil {
+			continue
+		}
+		info, err := takeInfo(item, take)
+		if err != nil {
+			return nil, err
+		}
+		takes = append(takes, info)
+	}
+	return takes, nil
+}
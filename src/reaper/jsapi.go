@@ -0,0 +1,232 @@
+package reaper
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../c -I${SRCDIR}/../../sdk
+#include "../c/bridge.h"
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+)
+
+// Optional wrappers for js_ReaScriptAPI's window functions. Like the SWS wrappers, each
+// looks its function up on every call and returns ErrJSAPINotInstalled when the
+// extension is missing. HWNDs are native window handles: an HWND on Windows, an
+// NSView on macOS and a SWELL window on Linux.
+
+// ErrJSAPINotInstalled is returned by the js_ReaScriptAPI wrappers when it is not installed
+var ErrJSAPINotInstalled = errors.New("the js_ReaScriptAPI extension is not installed")
+
+// Window states for JSWindowShow
+const (
+	WindowShow   = "SHOW"   // Show and activate
+	WindowShowNA = "SHOWNA" // Show without activating
+	WindowHide   = "HIDE"
+)
+
+// jsFunc looks up one of the js_ReaScriptAPI functions
+func jsFunc(name string) (unsafe.Pointer, error) {
+	funcPtr, err := lookupFunc(name)
+	if err != nil {
+		return nil, err
+	}
+	if funcPtr == nil {
+		return nil, ErrJSAPINotInstalled
+	}
+	return funcPtr, nil
+}
+
+// IsJSAPIInstalled reports whether js_ReaScriptAPI is loaded
+func IsJSAPIInstalled() bool {
+	_, err := jsFunc("JS_ReaScriptAPI_Version")
+	return err == nil
+}
+
+// GetJSAPIVersion returns the installed js_ReaScriptAPI version, e.g. 1.301
+func GetJSAPIVersion() (float64, error) {
+	funcPtr, err := jsFunc("JS_ReaScriptAPI_Version")
+	if err != nil {
+		return 0, err
+	}
+
+	return float64(C.plugin_bridge_call_js_version(funcPtr)), nil
+}
+
+// GetMainHwnd returns REAPER's main window
+func GetMainHwnd() (unsafe.Pointer, error) {
+	funcPtr, err := projectFunc("GetMainHwnd")
+	if err != nil {
+		return nil, err
+	}
+
+	hwnd := C.plugin_bridge_call_get_main_hwnd(funcPtr)
+	if hwnd == nil {
+		return nil, fmt.Errorf("REAPER has no main window")
+	}
+	return hwnd, nil
+}
+
+// JSWindowCreate creates an empty top-level window owned by owner (nil for none).
+// className should be unique to the extension.
+func JSWindowCreate(title, className string, x, y, width, height int, owner unsafe.Pointer) (unsafe.Pointer, error) {
+	funcPtr, err := jsFunc("JS_Window_Create")
+	if err != nil {
+		return nil, err
+	}
+
+	cTitle := C.CString(title)
+	defer C.free(unsafe.Pointer(cTitle))
+	cClassName := C.CString(className)
+	defer C.free(unsafe.Pointer(cClassName))
+
+	hwnd := C.plugin_bridge_call_js_window_create(funcPtr, cTitle, cClassName,
+		C.int(x), C.int(y), C.int(width), C.int(height), nil, owner)
+	if hwnd == nil {
+		return nil, fmt.Errorf("failed to create window %q", title)
+	}
+	return hwnd, nil
+}
+
+// JSWindowDestroy destroys a window
+func JSWindowDestroy(hwnd unsafe.Pointer) error {
+	if hwnd == nil {
+		return fmt.Errorf("invalid window")
+	}
+
+	funcPtr, err := jsFunc("JS_Window_Destroy")
+	if err != nil {
+		return err
+	}
+
+	C.plugin_bridge_call_js_window_destroy(funcPtr, hwnd)
+	return nil
+}
+
+// JSWindowIsWindow reports whether hwnd is still a valid window
+func JSWindowIsWindow(hwnd unsafe.Pointer) bool {
+	if hwnd == nil {
+		return false
+	}
+
+	funcPtr, err := jsFunc("JS_Window_IsWindow")
+	if err != nil {
+		return false
+	}
+
+	return bool(C.plugin_bridge_call_js_window_is_window(funcPtr, hwnd))
+}
+
+// JSWindowFind returns the first top-level window whose title matches, exactly or as a
+// case-insensitive substring
+func JSWindowFind(title string, exact bool) (unsafe.Pointer, error) {
+	funcPtr, err := jsFunc("JS_Window_Find")
+	if err != nil {
+		return nil, err
+	}
+
+	cTitle := C.CString(title)
+	defer C.free(unsafe.Pointer(cTitle))
+
+	hwnd := C.plugin_bridge_call_js_window_find(funcPtr, cTitle, C.bool(exact))
+	if hwnd == nil {
+		return nil, fmt.Errorf("no window titled %q", title)
+	}
+	return hwnd, nil
+}
+
+// JSWindowSetParent makes child a child of parent (nil makes it top-level again)
+func JSWindowSetParent(child, parent unsafe.Pointer) error {
+	if child == nil {
+		return fmt.Errorf("invalid window")
+	}
+
+	funcPtr, err := jsFunc("JS_Window_SetParent")
+	if err != nil {
+		return err
+	}
+
+	C.plugin_bridge_call_js_window_set_parent(funcPtr, child, parent)
+	return nil
+}
+
+// JSWindowShow shows or hides a window; state is one of the Window constants
+func JSWindowShow(hwnd unsafe.Pointer, state string) error {
+	if hwnd == nil {
+		return fmt.Errorf("invalid window")
+	}
+
+	funcPtr, err := jsFunc("JS_Window_Show")
+	if err != nil {
+		return err
+	}
+
+	cState := C.CString(state)
+	defer C.free(unsafe.Pointer(cState))
+
+	C.plugin_bridge_call_js_window_show(funcPtr, hwnd, cState)
+	return nil
+}
+
+// JSWindowSetTitle changes a window's title
+func JSWindowSetTitle(hwnd unsafe.Pointer, title string) error {
+	if hwnd == nil {
+		return fmt.Errorf("invalid window")
+	}
+
+	funcPtr, err := jsFunc("JS_Window_SetTitle")
+	if err != nil {
+		return err
+	}
+
+	cTitle := C.CString(title)
+	defer C.free(unsafe.Pointer(cTitle))
+
+	if !bool(C.plugin_bridge_call_js_window_set_title(funcPtr, hwnd, cTitle)) {
+		return fmt.Errorf("failed to set window title")
+	}
+	return nil
+}
+
+// JSWindowSetPosition moves and resizes a window, in screen coordinates
+func JSWindowSetPosition(hwnd unsafe.Pointer, left, top, width, height int) error {
+	if hwnd == nil {
+		return fmt.Errorf("invalid window")
+	}
+
+	funcPtr, err := jsFunc("JS_Window_SetPosition")
+	if err != nil {
+		return err
+	}
+
+	if !bool(C.plugin_bridge_call_js_window_set_position(funcPtr, hwnd, C.int(left), C.int(top), C.int(width), C.int(height))) {
+		return fmt.Errorf("failed to position window")
+	}
+	return nil
+}
+
+// JSWindowGetRect returns a window's position and size in screen coordinates
+func JSWindowGetRect(hwnd unsafe.Pointer) (left, top, width, height int, err error) {
+	if hwnd == nil {
+		return 0, 0, 0, 0, fmt.Errorf("invalid window")
+	}
+
+	funcPtr, err := jsFunc("JS_Window_GetRect")
+	if err != nil {
+		return 0, 0, 0, 0, err
+	}
+
+	var cLeft, cTop, cRight, cBottom C.int
+	if !bool(C.plugin_bridge_call_js_window_get_rect(funcPtr, hwnd, &cLeft, &cTop, &cRight, &cBottom)) {
+		return 0, 0, 0, 0, fmt.Errorf("failed to get window position")
+	}
+
+	// Bottom is above top on macOS, where the y axis points up
+	height = int(cBottom - cTop)
+	if height < 0 {
+		height = -height
+	}
+	return int(cLeft), int(cTop), int(cRight - cLeft), height, nil
+}
@@ -0,0 +1,92 @@
+package reaper
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../c -I${SRCDIR}/../../sdk
+#include "../c/bridge.h"
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"fmt"
+	"go-reaper/src/pkg/logger"
+	"unsafe"
+)
+
+// Menu IDs REAPER passes to custom menu hooks
+const (
+	MenuExtensions   = "Main extensions"             // The Extensions menu in the main menu bar
+	MenuTrackContext = "Track control panel context" // The menu for right-clicking a track panel
+)
+
+// Flags REAPER passes to custom menu hooks
+const (
+	MenuFlagInit = 0 // The menu is being created; add items here
+	MenuFlagShow = 1 // The menu is about to be shown; update item state here
+)
+
+// MenuHook is called when REAPER creates or is about to show a menu. menu is the native
+// menu handle (an NSMenu on macOS).
+type MenuHook func(menu unsafe.Pointer, flag int)
+
+// menuHooks maps menu IDs to the hooks registered for them
+var menuHooks map[string][]MenuHook
+
+func init() {
+	menuHooks = make(map[string][]MenuHook)
+}
+
+// AddMenuHook registers hook to be called for the menu with ID menuID
+func AddMenuHook(menuID string, hook MenuHook) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	menuHooks[menuID] = append(menuHooks[menuID], hook)
+}
+
+// AddExtensionsMainMenu makes REAPER show the Extensions menu, which is hidden until an
+// extension asks for it
+func AddExtensionsMainMenu() error {
+	funcPtr, err := projectFunc("AddExtensionsMainMenu")
+	if err != nil {
+		return err
+	}
+
+	C.plugin_bridge_call_add_extensions_main_menu(funcPtr)
+	return nil
+}
+
+// RunAction runs the handler registered for one of our actions, as if it had been
+// triggered from the action list
+func RunAction(actionID string) error {
+	mutex.RLock()
+	handler, exists := actionHandlers[actionID]
+	mutex.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("no handler for action %s", actionID)
+	}
+
+	logger.Info("Running action %s", actionID)
+	callHandler(actionID, handler, ActionContext{ValueHW: -1})
+	return nil
+}
+
+// Custom menu callback handler
+//
+//export goHookCustomMenu
+func goHookCustomMenu(menuIDStr *C.char, menu unsafe.Pointer, flag C.int) {
+	defer Recover("goHookCustomMenu")
+
+	if menuIDStr == nil || menu == nil {
+		return
+	}
+	menuID := C.GoString(menuIDStr)
+
+	mutex.RLock()
+	hooks := menuHooks[menuID]
+	mutex.RUnlock()
+
+	for _, hook := range hooks {
+		hook(menu, int(flag))
+	}
+}
@@ -0,0 +1,87 @@
+package reaper
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../c -I${SRCDIR}/../../sdk
+#include "../c/bridge.h"
+#include <stdlib.h>
+*/
+import "C"
+import "fmt"
+
+// maxMIDIEvents limits how many events one ReadMIDIInput call returns. REAPER keeps a
+// short history, so a poller that runs every tick never gets near it.
+const maxMIDIEvents = 256
+
+// MIDIEvent is a short MIDI message received on one of REAPER's MIDI inputs
+type MIDIEvent struct {
+	Seq    int // Increases with each event REAPER receives
+	Status byte
+	Data1  byte
+	Data2  byte
+	Device int // Input device index, see GetMIDIInputName
+}
+
+// IsCC reports whether the event is a control change
+func (e MIDIEvent) IsCC() bool {
+	return e.Status&0xF0 == 0xB0
+}
+
+// Channel returns the event's MIDI channel, 1-16
+func (e MIDIEvent) Channel() int {
+	return int(e.Status&0x0F) + 1
+}
+
+// ReadMIDIInput returns the events REAPER received on its MIDI inputs after the event
+// numbered afterSeq, oldest first, and the newest event's number to pass next time.
+// Only inputs enabled in REAPER's preferences (for input or for control) are included,
+// and SysEx is left out. Pass 0 to read what is still in REAPER's short history.
+func ReadMIDIInput(afterSeq int) ([]MIDIEvent, int, error) {
+	funcPtr, err := projectFunc("MIDI_GetRecentInputEvent")
+	if err != nil {
+		return nil, afterSeq, err
+	}
+
+	buf, data := getArray[C.midi_input_event_t](maxMIDIEvents)
+	defer buf.release()
+
+	var latest C.int
+	count := int(C.plugin_bridge_call_get_recent_midi_input(funcPtr, C.int(afterSeq), data, C.int(maxMIDIEvents), &latest))
+	if latest == 0 {
+		// Nothing received since REAPER started
+		return nil, afterSeq, nil
+	}
+	if int(latest) < afterSeq {
+		// The numbering started over, e.g. after resetting MIDI devices
+		return nil, int(latest), nil
+	}
+
+	raw := arraySlice[C.midi_input_event_t](buf, count)
+	events := make([]MIDIEvent, len(raw))
+	for i, event := range raw {
+		// The bridge returns the newest first
+		events[len(raw)-1-i] = MIDIEvent{
+			Seq:    int(event.seq),
+			Status: byte(event.msg[0]),
+			Data1:  byte(event.msg[1]),
+			Data2:  byte(event.msg[2]),
+			Device: int(event.device),
+		}
+	}
+	return events, int(latest), nil
+}
+
+// GetMIDIInputName returns the name of a MIDI input device
+func GetMIDIInputName(device int) (string, error) {
+	funcPtr, err := projectFunc("GetMIDIInputName")
+	if err != nil {
+		return "", err
+	}
+
+	buf := getBuffer(256)
+	defer buf.release()
+
+	if !C.plugin_bridge_call_get_midi_input_name(funcPtr, C.int(device), buf.ptr, buf.cSize()) {
+		return "", fmt.Errorf("MIDI input %d is not present", device)
+	}
+	return buf.String(), nil
+}
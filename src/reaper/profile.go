@@ -0,0 +1,120 @@
+package reaper
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../c -I${SRCDIR}/../../sdk
+#include "../c/bridge.h"
+*/
+import "C"
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Profiling counts the calls made through the FX wrappers and the time spent in each
+// REAPER function, so a wrapper that crosses into C more often than it should shows up in
+// the diagnostics. It is off by default; the counters cost a lock per call when on.
+var (
+	profiling      atomic.Bool
+	profileMutex   sync.Mutex
+	profileStarted time.Time
+	profileLookups int64 // GetFunc lookups when profiling started
+	profileCalls   = make(map[string]*FunctionProfile)
+)
+
+// FunctionProfile is the profile of one REAPER function
+type FunctionProfile struct {
+	Function string
+	Batch    bool // One call covers many parameters or FX
+	Calls    int64
+	Total    time.Duration
+}
+
+// Average returns the mean time of a call
+func (f FunctionProfile) Average() time.Duration {
+	if f.Calls == 0 {
+		return 0
+	}
+	return f.Total / time.Duration(f.Calls)
+}
+
+// Profile is a snapshot of the counters since profiling started
+type Profile struct {
+	Enabled     bool
+	Duration    time.Duration     // Time since profiling started
+	Lookups     int64             // GetFunc lookups by any wrapper
+	BatchCalls  int64             // Calls to batch functions
+	SingleCalls int64             // Calls to per-parameter or per-FX functions
+	Functions   []FunctionProfile // By total time, longest first
+}
+
+// SetProfiling turns profiling on or off. Turning it on clears the counters.
+func SetProfiling(enabled bool) {
+	profileMutex.Lock()
+	defer profileMutex.Unlock()
+
+	if enabled && !profiling.Load() {
+		profileStarted = time.Now()
+		profileLookups = int64(C.plugin_bridge_get_func_call_count())
+		profileCalls = make(map[string]*FunctionProfile)
+	}
+	profiling.Store(enabled)
+}
+
+// IsProfiling reports whether profiling is on
+func IsProfiling() bool {
+	return profiling.Load()
+}
+
+// GetProfile returns the counters collected since profiling was last turned on
+func GetProfile() Profile {
+	profileMutex.Lock()
+	defer profileMutex.Unlock()
+
+	profile := Profile{Enabled: profiling.Load()}
+	if profileStarted.IsZero() {
+		return profile
+	}
+
+	profile.Duration = time.Since(profileStarted)
+	profile.Lookups = int64(C.plugin_bridge_get_func_call_count()) - profileLookups
+	for _, f := range profileCalls {
+		profile.Functions = append(profile.Functions, *f)
+		if f.Batch {
+			profile.BatchCalls += f.Calls
+		} else {
+			profile.SingleCalls += f.Calls
+		}
+	}
+	sort.Slice(profile.Functions, func(i, j int) bool {
+		return profile.Functions[i].Total > profile.Functions[j].Total
+	})
+	return profile
+}
+
+// profileCall starts timing a call to a REAPER function and returns the function that
+// records it. Defer it at the top of a wrapper:
+//
+//	defer profileCall("TrackFX_GetParam", false)()
+func profileCall(function string, batch bool) func() {
+	if !profiling.Load() {
+		return func() {}
+	}
+
+	start := time.Now()
+	return func() {
+		elapsed := time.Since(start)
+
+		profileMutex.Lock()
+		defer profileMutex.Unlock()
+
+		f, ok := profileCalls[function]
+		if !ok {
+			f = &FunctionProfile{Function: function, Batch: batch}
+			profileCalls[function] = f
+		}
+		f.Calls++
+		f.Total += elapsed
+	}
+}
@@ -0,0 +1,294 @@
+package reaper
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../c -I${SRCDIR}/../../sdk
+#include "../c/bridge.h"
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"fmt"
+	"go-reaper/src/pkg/logger"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// Built-in REAPER actions used by the extension
+const (
+	CommandQuit         = 40004 // File: Quit REAPER
+	CommandRenderToDisk = 40015 // File: Render project to disk...
+	CommandFreezeMono   = 40901 // Track: Freeze to mono (render pre-fader, save/remove items and online FX)
+	CommandFreezeStereo = 41223 // Track: Freeze to stereo (render pre-fader, save/remove items and online FX)
+	CommandUnfreeze     = 41644 // Track: Unfreeze tracks (restore previously saved items and FX)
+)
+
+// lookupFunc looks up a REAPER API function by name, or one another extension such as
+// SWS registered. Every wrapper looks up its function here, so none can call REAPER off
+// the main thread. A nil pointer without an error means there is no such function.
+func lookupFunc(name string) (unsafe.Pointer, error) {
+	if !initialized {
+		return nil, fmt.Errorf("REAPER functions not initialized")
+	}
+	if err := requireMainThread(name); err != nil {
+		return nil, err
+	}
+
+	cFuncName := C.CString(name)
+	defer C.free(unsafe.Pointer(cFuncName))
+
+	return C.plugin_bridge_call_get_func(C.plugin_bridge_get_get_func(), cFuncName), nil
+}
+
+// requireMainThread refuses, with a logged warning, calls into REAPER made off the main
+// thread. what names the call in the warning.
+func requireMainThread(what string) error {
+	if !IsMainThread() {
+		logger.Warning("%s called off the main thread", what)
+		return fmt.Errorf("%s can only be called on the main thread", what)
+	}
+	return nil
+}
+
+// batchReady checks that a batch call into the bridge can be made. The bridge's batch
+// functions call REAPER through pointers it looked up itself, bypassing lookupFunc, so
+// they check here instead.
+func batchReady(what string) error {
+	if !initialized {
+		return fmt.Errorf("REAPER functions not initialized")
+	}
+	return requireMainThread(what)
+}
+
+// projectFunc looks up one of REAPER's own functions, failing if REAPER has none by
+// that name
+func projectFunc(name string) (unsafe.Pointer, error) {
+	funcPtr, err := lookupFunc(name)
+	if err != nil {
+		return nil, err
+	}
+	if funcPtr == nil {
+		return nil, fmt.Errorf("could not get %s function pointer", name)
+	}
+	return funcPtr, nil
+}
+
+// CountTracks returns the number of tracks in the current project, not counting the master
+func CountTracks() (int, error) {
+	funcPtr, err := projectFunc("CountTracks")
+	if err != nil {
+		return 0, err
+	}
+
+	return int(C.plugin_bridge_call_count_tracks(funcPtr, nil)), nil
+}
+
+// InsertTrack inserts a track with the default settings at index (0-based) and returns it.
+// An index past the end appends the track.
+func InsertTrack(index int) (unsafe.Pointer, error) {
+	funcPtr, err := projectFunc("InsertTrackAtIndex")
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := CountTracks()
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 || index > count {
+		index = count
+	}
+
+	C.plugin_bridge_call_insert_track_at_index(funcPtr, C.int(index), C.bool(true))
+	return GetTrack(index)
+}
+
+// DeleteTrack removes a track from the current project
+func DeleteTrack(track unsafe.Pointer) error {
+	if track == nil {
+		return fmt.Errorf("cannot delete a nil track")
+	}
+
+	funcPtr, err := projectFunc("DeleteTrack")
+	if err != nil {
+		return err
+	}
+
+	C.plugin_bridge_call_delete_track(funcPtr, track)
+	return nil
+}
+
+// AddTrackFX adds an FX to the end of a track's chain and returns its index. name is
+// matched the way the FX browser does, e.g. "ReaEQ" or "VST3: Pro-Q 3 (FabFilter)".
+func AddTrackFX(track unsafe.Pointer, name string) (int, error) {
+	if track == nil {
+		return -1, fmt.Errorf("cannot add FX to a nil track")
+	}
+
+	funcPtr, err := projectFunc("TrackFX_AddByName")
+	if err != nil {
+		return -1, err
+	}
+
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+
+	index := int(C.plugin_bridge_call_track_fx_add_by_name(funcPtr, track, cName, C.bool(false), C.int(-1)))
+	if index < 0 {
+		return -1, fmt.Errorf("FX %q not found", name)
+	}
+	return index, nil
+}
+
+// RunCommand runs one of REAPER's built-in actions by command ID, e.g. CommandQuit
+func RunCommand(commandID int) error {
+	funcPtr, err := projectFunc("Main_OnCommandEx")
+	if err != nil {
+		return err
+	}
+
+	C.plugin_bridge_call_main_on_command_ex(funcPtr, C.int(commandID), 0, nil)
+	return nil
+}
+
+// NamedCommandLookup returns the command ID of an action registered by name, such as an
+// SWS or script action ("_SWS_ABOUT"), or 0 if there is no such action
+func NamedCommandLookup(name string) (int, error) {
+	funcPtr, err := projectFunc("NamedCommandLookup")
+	if err != nil {
+		return 0, err
+	}
+
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+
+	return int(C.plugin_bridge_call_named_command_lookup(funcPtr, cName)), nil
+}
+
+// RunNamedCommand runs an action registered by name, such as "_SWS_ABOUT". It accepts
+// anything LookupCommand does, so a command ID or one of our action IDs works too.
+func RunNamedCommand(name string) error {
+	commandID, err := LookupCommand(name)
+	if err != nil {
+		return err
+	}
+
+	return RunCommand(commandID)
+}
+
+// ReverseNamedCommandLookup returns the name an action was registered under, with its
+// leading underscore (e.g. "_SWS_ABOUT"), or "" for REAPER's built-in actions
+func ReverseNamedCommandLookup(commandID int) (string, error) {
+	funcPtr, err := projectFunc("ReverseNamedCommandLookup")
+	if err != nil {
+		return "", err
+	}
+
+	cName := C.plugin_bridge_call_reverse_named_command_lookup(funcPtr, C.int(commandID))
+	if cName == nil {
+		return "", nil
+	}
+	return "_" + C.GoString(cName), nil
+}
+
+// GetToggleCommandState returns the on/off state of an action in the main section:
+// 1 for on, 0 for off, or -1 for actions that aren't toggles
+func GetToggleCommandState(commandID int) (int, error) {
+	funcPtr, err := projectFunc("GetToggleCommandStateEx")
+	if err != nil {
+		return -1, err
+	}
+
+	return int(C.plugin_bridge_call_get_toggle_command_state_ex(funcPtr, C.int(SectionMain), C.int(commandID))), nil
+}
+
+// LookupCommand returns the command ID of an action given as a command ID ("40044"), a
+// named command ("_SWS_ABOUT") or one of our action IDs ("GO_EQ_CURVE")
+func LookupCommand(command string) (int, error) {
+	command = strings.TrimSpace(command)
+	if command == "" {
+		return 0, fmt.Errorf("empty command")
+	}
+
+	if commandID, err := strconv.Atoi(command); err == nil {
+		if commandID <= 0 {
+			return 0, fmt.Errorf("invalid command ID %d", commandID)
+		}
+		return commandID, nil
+	}
+
+	if commandID, ok := GetCommandID(command); ok {
+		return commandID, nil
+	}
+
+	// REAPER only finds named commands with their leading underscore
+	name := command
+	if !strings.HasPrefix(name, "_") {
+		name = "_" + name
+	}
+	commandID, err := NamedCommandLookup(name)
+	if err != nil {
+		return 0, err
+	}
+	if commandID == 0 {
+		return 0, fmt.Errorf("action %s not found", command)
+	}
+	return commandID, nil
+}
+
+// GetFXEnvelope returns the automation envelope of an FX parameter, creating it if
+// create is set
+func GetFXEnvelope(track unsafe.Pointer, fxIndex int, paramIndex int, create bool) (unsafe.Pointer, error) {
+	if track == nil {
+		return nil, fmt.Errorf("invalid track")
+	}
+
+	funcPtr, err := projectFunc("GetFXEnvelope")
+	if err != nil {
+		return nil, err
+	}
+
+	envelope := C.plugin_bridge_call_get_fx_envelope(funcPtr, track, C.int(fxIndex), C.int(paramIndex), C.bool(create))
+	if envelope == nil {
+		return nil, fmt.Errorf("FX %d parameter %d has no envelope", fxIndex, paramIndex)
+	}
+	return envelope, nil
+}
+
+// GetCursorPosition returns the edit cursor position in the current project, in seconds
+func GetCursorPosition() (float64, error) {
+	funcPtr, err := projectFunc("GetCursorPositionEx")
+	if err != nil {
+		return 0, err
+	}
+
+	return float64(C.plugin_bridge_call_get_cursor_position_ex(funcPtr, nil)), nil
+}
+
+// GetProjectInfo reads a numeric project setting such as "RENDER_SETTINGS"; see
+// GetSetProjectInfo in REAPER's API documentation for the names
+func GetProjectInfo(name string) (float64, error) {
+	funcPtr, err := projectFunc("GetSetProjectInfo")
+	if err != nil {
+		return 0, err
+	}
+
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+
+	return float64(C.plugin_bridge_call_get_set_project_info(funcPtr, nil, cName, 0, C.bool(false))), nil
+}
+
+// SetProjectInfo sets a numeric project setting such as "RENDER_BOUNDSFLAG"
+func SetProjectInfo(name string, value float64) error {
+	funcPtr, err := projectFunc("GetSetProjectInfo")
+	if err != nil {
+		return err
+	}
+
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+
+	C.plugin_bridge_call_get_set_project_info(funcPtr, nil, cName, C.double(value), C.bool(true))
+	return nil
+}
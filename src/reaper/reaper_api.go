@@ -0,0 +1,166 @@
+package reaper
+
+import "unsafe"
+
+// ReaperAPI is the subset of REAPER's track and FX API that the extension's logic is
+// written against. Native calls REAPER; the fake package provides an in-memory version
+// so the logic can run without REAPER. Tracks are opaque handles. The batch methods are
+// the bridge's single-call reads and writes; the helpers built on them, like
+// BatchGetParameters and GetFXInfo, take a ReaperAPI.
+type ReaperAPI interface {
+	GetSelectedTrack() (unsafe.Pointer, error)
+	GetTrackName(track unsafe.Pointer) (string, error)
+
+	GetTrackFXCount(track unsafe.Pointer) (int, error)
+	GetTrackFXName(track unsafe.Pointer, fxIndex int) (string, error)
+	GetTrackFXEnabled(track unsafe.Pointer, fxIndex int) (bool, error)
+	SetTrackFXEnabled(track unsafe.Pointer, fxIndex int, enabled bool) error
+	GetTrackFXOffline(track unsafe.Pointer, fxIndex int) (bool, error)
+	SetTrackFXOffline(track unsafe.Pointer, fxIndex int, offline bool) error
+	MoveTrackFX(track unsafe.Pointer, fromIndex int, toIndex int) error
+	GetTrackFXGUID(track unsafe.Pointer, fxIndex int) (string, error)
+	GetTrackFXNamedConfigParam(track unsafe.Pointer, fxIndex int, name string) (string, error)
+
+	GetTrackFXParamCount(track unsafe.Pointer, fxIndex int) (int, error)
+	GetTrackFXParamName(track unsafe.Pointer, fxIndex int, paramIndex int) (string, error)
+	GetTrackFXParamValue(track unsafe.Pointer, fxIndex int, paramIndex int) (float64, error)
+	GetTrackFXParamValueWithRange(track unsafe.Pointer, fxIndex int, paramIndex int) (value, min, max float64, err error)
+	GetTrackFXParamFormatted(track unsafe.Pointer, fxIndex int, paramIndex int) (string, error)
+	FormatTrackFXParamValue(track unsafe.Pointer, fxIndex int, paramIndex int, value float64) (string, error)
+	SetTrackFXParamValue(track unsafe.Pointer, fxIndex int, paramIndex int, value float64) error
+	GetTrackFXParamFromIdent(track unsafe.Pointer, fxIndex int, ident string) (int, error)
+
+	// ReadFXParameters reads the names, ranges and values of all parameters of an FX
+	ReadFXParameters(track unsafe.Pointer, fxIndex int) ([]FXParameter, error)
+	// ReadFXParameterValues reads the values of the first count parameters of an FX, with
+	// only Index, Value and FormattedValue set, and returns how many parameters it has
+	ReadFXParameterValues(track unsafe.Pointer, fxIndex int, count int) ([]FXParameter, int, error)
+	BatchGetTrackFXStates(track unsafe.Pointer) ([]FXState, error)
+	BatchSetTrackFXStates(track unsafe.Pointer, states []FXState) error
+	BatchSetParameters(changes []ParameterChange) (failed []ParameterChange, err error)
+}
+
+// Native implements ReaperAPI by calling REAPER through the package functions
+type Native struct{}
+
+var _ ReaperAPI = Native{}
+
+// GetSelectedTrack implements ReaperAPI
+func (Native) GetSelectedTrack() (unsafe.Pointer, error) {
+	return GetSelectedTrack()
+}
+
+// GetTrackName implements ReaperAPI
+func (Native) GetTrackName(track unsafe.Pointer) (string, error) {
+	return GetTrackName(track)
+}
+
+// GetTrackFXCount implements ReaperAPI
+func (Native) GetTrackFXCount(track unsafe.Pointer) (int, error) {
+	return GetTrackFXCount(track)
+}
+
+// GetTrackFXName implements ReaperAPI
+func (Native) GetTrackFXName(track unsafe.Pointer, fxIndex int) (string, error) {
+	return GetTrackFXName(track, fxIndex)
+}
+
+// GetTrackFXEnabled implements ReaperAPI
+func (Native) GetTrackFXEnabled(track unsafe.Pointer, fxIndex int) (bool, error) {
+	return GetTrackFXEnabled(track, fxIndex)
+}
+
+// SetTrackFXEnabled implements ReaperAPI
+func (Native) SetTrackFXEnabled(track unsafe.Pointer, fxIndex int, enabled bool) error {
+	return SetTrackFXEnabled(track, fxIndex, enabled)
+}
+
+// GetTrackFXOffline implements ReaperAPI
+func (Native) GetTrackFXOffline(track unsafe.Pointer, fxIndex int) (bool, error) {
+	return GetTrackFXOffline(track, fxIndex)
+}
+
+// SetTrackFXOffline implements ReaperAPI
+func (Native) SetTrackFXOffline(track unsafe.Pointer, fxIndex int, offline bool) error {
+	return SetTrackFXOffline(track, fxIndex, offline)
+}
+
+// MoveTrackFX implements ReaperAPI
+func (Native) MoveTrackFX(track unsafe.Pointer, fromIndex int, toIndex int) error {
+	return MoveTrackFX(track, fromIndex, toIndex)
+}
+
+// GetTrackFXGUID implements ReaperAPI
+func (Native) GetTrackFXGUID(track unsafe.Pointer, fxIndex int) (string, error) {
+	return GetTrackFXGUID(track, fxIndex)
+}
+
+// GetTrackFXNamedConfigParam implements ReaperAPI
+func (Native) GetTrackFXNamedConfigParam(track unsafe.Pointer, fxIndex int, name string) (string, error) {
+	return GetTrackFXNamedConfigParam(track, fxIndex, name)
+}
+
+// GetTrackFXParamCount implements ReaperAPI
+func (Native) GetTrackFXParamCount(track unsafe.Pointer, fxIndex int) (int, error) {
+	return GetTrackFXParamCount(track, fxIndex)
+}
+
+// GetTrackFXParamName implements ReaperAPI
+func (Native) GetTrackFXParamName(track unsafe.Pointer, fxIndex int, paramIndex int) (string, error) {
+	return GetTrackFXParamName(track, fxIndex, paramIndex)
+}
+
+// GetTrackFXParamValue implements ReaperAPI
+func (Native) GetTrackFXParamValue(track unsafe.Pointer, fxIndex int, paramIndex int) (float64, error) {
+	return GetTrackFXParamValue(track, fxIndex, paramIndex)
+}
+
+// GetTrackFXParamValueWithRange implements ReaperAPI
+func (Native) GetTrackFXParamValueWithRange(track unsafe.Pointer, fxIndex int, paramIndex int) (value, min, max float64, err error) {
+	return GetTrackFXParamValueWithRange(track, fxIndex, paramIndex)
+}
+
+// GetTrackFXParamFormatted implements ReaperAPI
+func (Native) GetTrackFXParamFormatted(track unsafe.Pointer, fxIndex int, paramIndex int) (string, error) {
+	return GetTrackFXParamFormatted(track, fxIndex, paramIndex)
+}
+
+// FormatTrackFXParamValue implements ReaperAPI
+func (Native) FormatTrackFXParamValue(track unsafe.Pointer, fxIndex int, paramIndex int, value float64) (string, error) {
+	return FormatTrackFXParamValue(track, fxIndex, paramIndex, value)
+}
+
+// SetTrackFXParamValue implements ReaperAPI
+func (Native) SetTrackFXParamValue(track unsafe.Pointer, fxIndex int, paramIndex int, value float64) error {
+	return SetTrackFXParamValue(track, fxIndex, paramIndex, value)
+}
+
+// GetTrackFXParamFromIdent implements ReaperAPI
+func (Native) GetTrackFXParamFromIdent(track unsafe.Pointer, fxIndex int, ident string) (int, error) {
+	return GetTrackFXParamFromIdent(track, fxIndex, ident)
+}
+
+// ReadFXParameters implements ReaperAPI
+func (Native) ReadFXParameters(track unsafe.Pointer, fxIndex int) ([]FXParameter, error) {
+	return readFXParameters(track, fxIndex)
+}
+
+// ReadFXParameterValues implements ReaperAPI
+func (Native) ReadFXParameterValues(track unsafe.Pointer, fxIndex int, count int) ([]FXParameter, int, error) {
+	return readFXParameterValues(track, fxIndex, count)
+}
+
+// BatchGetTrackFXStates implements ReaperAPI
+func (Native) BatchGetTrackFXStates(track unsafe.Pointer) ([]FXState, error) {
+	return BatchGetTrackFXStates(track)
+}
+
+// BatchSetTrackFXStates implements ReaperAPI
+func (Native) BatchSetTrackFXStates(track unsafe.Pointer, states []FXState) error {
+	return BatchSetTrackFXStates(track, states)
+}
+
+// BatchSetParameters implements ReaperAPI
+func (Native) BatchSetParameters(changes []ParameterChange) ([]ParameterChange, error) {
+	return BatchSetParameters(changes)
+}
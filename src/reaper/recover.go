@@ -0,0 +1,59 @@
+package reaper
+
+import (
+	"fmt"
+	"go-reaper/src/pkg/logger"
+	"runtime/debug"
+	"sync"
+)
+
+var (
+	crashReporterMutex sync.RWMutex
+	// crashReporter, if set, is told about recovered panics, e.g. to show a dialog
+	crashReporter func(where string, panicValue interface{})
+)
+
+// SetCrashReporter sets a function to call after a panic has been recovered and logged.
+// It may be called on any thread. Pass nil to only log panics.
+func SetCrashReporter(reporter func(where string, panicValue interface{})) {
+	crashReporterMutex.Lock()
+	defer crashReporterMutex.Unlock()
+
+	crashReporter = reporter
+}
+
+// Recover stops a panic in a callback from REAPER or the UI from unwinding into C, which
+// would take REAPER down with it. It must be deferred directly:
+//
+//	defer reaper.Recover("goHookCommandProc")
+func Recover(where string) {
+	if r := recover(); r != nil {
+		reportPanic(where, r)
+	}
+}
+
+// reportPanic logs a recovered panic with its stack and passes it to the crash reporter
+func reportPanic(where string, panicValue interface{}) {
+	logger.Error("Recovered panic in %s: %v\n%s", where, panicValue, debug.Stack())
+
+	crashReporterMutex.RLock()
+	reporter := crashReporter
+	crashReporterMutex.RUnlock()
+
+	if reporter == nil {
+		return
+	}
+
+	// A failing reporter must not cause the crash we just prevented
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("Crash reporter panicked: %v", r)
+		}
+	}()
+	reporter(where, panicValue)
+}
+
+// PanicMessage formats a recovered panic for a user-facing report
+func PanicMessage(where string, panicValue interface{}) string {
+	return fmt.Sprintf("Something went wrong in %s:\n\n%v\n\nREAPER can keep running. The details have been written to the log.", where, panicValue)
+}
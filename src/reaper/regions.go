@@ -0,0 +1,235 @@
+package reaper
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../c -I${SRCDIR}/../../sdk
+#include "../c/bridge.h"
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"fmt"
+	"unsafe"
+)
+
+// Render settings used with the region render matrix, from GetSetProjectInfo's
+// RENDER_SETTINGS and RENDER_BOUNDSFLAG
+const (
+	renderSourceMask    = 1 | 2 | 8 | 32 | 64 | 128 // Bits that choose what is rendered
+	renderSourceMatrix  = 8                         // Render the tracks in the region render matrix
+	renderBoundsRegions = 3                         // Render all project regions
+)
+
+// Region is a project region
+type Region struct {
+	Index  int // Enumeration index among markers and regions, for EnumProjectMarkers
+	Number int // Number shown in REAPER, used by the region render matrix
+	Name   string
+	Start  float64 // Start in seconds
+	End    float64 // End in seconds
+	Color  int     // Native color with REAPER's custom color flag, 0 for the default color
+}
+
+// Length returns the region's length in seconds
+func (r Region) Length() float64 {
+	return r.End - r.Start
+}
+
+// Marker is a project marker
+type Marker struct {
+	Index    int // Enumeration index among markers and regions, for EnumProjectMarkers
+	Number   int // Number shown in REAPER
+	Name     string
+	Position float64 // Position in seconds
+	Color    int     // Native color with REAPER's custom color flag, 0 for the default color
+}
+
+// GetRegions returns the regions in the current project, in time order. Markers are left out.
+func GetRegions() ([]Region, error) {
+	var regions []Region
+	err := enumProjectMarkers(func(region Region, isRegion bool) {
+		if isRegion {
+			regions = append(regions, region)
+		}
+	})
+	return regions, err
+}
+
+// GetMarkers returns the markers in the current project, in time order. Regions are left out.
+func GetMarkers() ([]Marker, error) {
+	var markers []Marker
+	err := enumProjectMarkers(func(region Region, isRegion bool) {
+		if !isRegion {
+			markers = append(markers, Marker{
+				Index:    region.Index,
+				Number:   region.Number,
+				Name:     region.Name,
+				Position: region.Start,
+				Color:    region.Color,
+			})
+		}
+	})
+	return markers, err
+}
+
+// enumProjectMarkers calls fn with each marker and region in the current project, in
+// time order. Markers are passed as regions that end where they start.
+func enumProjectMarkers(fn func(region Region, isRegion bool)) error {
+	funcPtr, err := projectFunc("EnumProjectMarkers3")
+	if err != nil {
+		return err
+	}
+
+	for index := 0; ; index++ {
+		var cIsRegion C.bool
+		var cPos, cEnd C.double
+		var cName *C.char
+		var cNumber, cColor C.int
+
+		next := C.plugin_bridge_call_enum_project_markers3(funcPtr, nil, C.int(index),
+			&cIsRegion, &cPos, &cEnd, &cName, &cNumber, &cColor)
+		if next == 0 {
+			return nil
+		}
+
+		name := ""
+		if cName != nil {
+			name = C.GoString(cName)
+		}
+		end := float64(cEnd)
+		if !cIsRegion {
+			end = float64(cPos)
+		}
+		fn(Region{
+			Index:  index,
+			Number: int(cNumber),
+			Name:   name,
+			Start:  float64(cPos),
+			End:    end,
+			Color:  int(cColor),
+		}, bool(cIsRegion))
+	}
+}
+
+// GetRegionAt returns the region containing a project time in seconds. When regions
+// overlap, REAPER picks the one that starts last.
+func GetRegionAt(time float64) (Region, error) {
+	funcPtr, err := projectFunc("GetLastMarkerAndCurRegion")
+	if err != nil {
+		return Region{}, err
+	}
+
+	var cMarker, cRegion C.int
+	C.plugin_bridge_call_get_last_marker_and_cur_region(funcPtr, nil, C.double(time), &cMarker, &cRegion)
+	if cRegion < 0 {
+		return Region{}, fmt.Errorf("no region at %.3fs", time)
+	}
+
+	regions, err := GetRegions()
+	if err != nil {
+		return Region{}, err
+	}
+	for _, region := range regions {
+		if region.Index == int(cRegion) {
+			return region, nil
+		}
+	}
+	return Region{}, fmt.Errorf("region %d not found", int(cRegion))
+}
+
+// GetCurrentRegion returns the region under the edit cursor
+func GetCurrentRegion() (Region, error) {
+	position, err := GetCursorPosition()
+	if err != nil {
+		return Region{}, err
+	}
+	return GetRegionAt(position)
+}
+
+// GetRegionRenderTracks returns the tracks the region render matrix renders for a region.
+// The master track is included when the region renders a master mix.
+func GetRegionRenderTracks(region Region) ([]unsafe.Pointer, error) {
+	funcPtr, err := projectFunc("EnumRegionRenderMatrix")
+	if err != nil {
+		return nil, err
+	}
+
+	var tracks []unsafe.Pointer
+	for i := 0; ; i++ {
+		track := C.plugin_bridge_call_enum_region_render_matrix(funcPtr, nil, C.int(region.Number), C.int(i))
+		if track == nil {
+			break
+		}
+		tracks = append(tracks, track)
+	}
+	return tracks, nil
+}
+
+// SetRegionRenderTrack adds a track to, or removes it from, a region's renders in the
+// region render matrix. Pass the master track to render a master mix of the region.
+func SetRegionRenderTrack(region Region, track unsafe.Pointer, render bool) error {
+	if track == nil {
+		return fmt.Errorf("invalid track")
+	}
+
+	funcPtr, err := projectFunc("SetRegionRenderMatrix")
+	if err != nil {
+		return err
+	}
+
+	flag := -1
+	if render {
+		flag = 1
+	}
+	C.plugin_bridge_call_set_region_render_matrix(funcPtr, nil, C.int(region.Number), track, C.int(flag))
+	return nil
+}
+
+// ClearRegionRenderMatrix removes every track from a region's renders
+func ClearRegionRenderMatrix(region Region) error {
+	tracks, err := GetRegionRenderTracks(region)
+	if err != nil {
+		return err
+	}
+
+	for _, track := range tracks {
+		if err := SetRegionRenderTrack(region, track, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UseRegionRenderMatrix switches the project's render settings to render every region
+// through the region render matrix. Format and output path settings are kept.
+func UseRegionRenderMatrix() error {
+	settings, err := GetProjectInfo("RENDER_SETTINGS")
+	if err != nil {
+		return err
+	}
+
+	updated := int(settings)&^renderSourceMask | renderSourceMatrix
+	if err := SetProjectInfo("RENDER_SETTINGS", float64(updated)); err != nil {
+		return err
+	}
+	return SetProjectInfo("RENDER_BOUNDSFLAG", renderBoundsRegions)
+}
+
+// SetupRegionStemRender sets a region to render exactly the given tracks as stems and
+// switches rendering to the region render matrix. Other regions keep their matrix entries
+// and render too when they have any. Call it inside an undo block.
+func SetupRegionStemRender(region Region, tracks []unsafe.Pointer) error {
+	if len(tracks) == 0 {
+		return fmt.Errorf("no tracks to render")
+	}
+
+	if err := ClearRegionRenderMatrix(region); err != nil {
+		return fmt.Errorf("failed to clear region %d: %v", region.Number, err)
+	}
+	for _, track := range tracks {
+		if err := SetRegionRenderTrack(region, track, true); err != nil {
+			return fmt.Errorf("failed to add track to region %d: %v", region.Number, err)
+		}
+	}
+
+	return UseRegionRenderMatrix()
+}
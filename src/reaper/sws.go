@@ -0,0 +1,278 @@
+package reaper
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../c -I${SRCDIR}/../../sdk
+#include "../c/bridge.h"
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+)
+
+// Optional wrappers for the SWS extension (https://www.sws-extension.org). SWS registers
+// its functions with REAPER when installed, so each wrapper looks its function up on
+// every call and returns ErrSWSNotInstalled when it is missing. Callers should treat
+// that error as "feature unavailable" and carry on.
+
+// ErrSWSNotInstalled is returned by the SWS wrappers when SWS is not installed
+var ErrSWSNotInstalled = errors.New("the SWS extension is not installed")
+
+// Envelope point shapes
+const (
+	EnvelopeShapeLinear    = 0
+	EnvelopeShapeSquare    = 1
+	EnvelopeShapeSlowStart = 2
+	EnvelopeShapeFastStart = 3
+	EnvelopeShapeFastEnd   = 4
+	EnvelopeShapeBezier    = 5
+)
+
+// EnvelopePoint is a point of an automation envelope
+type EnvelopePoint struct {
+	Position      float64 // Seconds
+	Value         float64 // In the envelope's raw range, e.g. 0-1 for FX parameters
+	Shape         int     // One of the EnvelopeShape constants
+	Selected      bool
+	BezierTension float64 // -1 to 1, used by EnvelopeShapeBezier
+}
+
+// swsFunc looks up one of the SWS functions
+func swsFunc(name string) (unsafe.Pointer, error) {
+	funcPtr, err := lookupFunc(name)
+	if err != nil {
+		return nil, err
+	}
+	if funcPtr == nil {
+		return nil, ErrSWSNotInstalled
+	}
+	return funcPtr, nil
+}
+
+// IsSWSInstalled reports whether the SWS extension is loaded
+func IsSWSInstalled() bool {
+	_, err := swsFunc("CF_GetSWSVersion")
+	return err == nil
+}
+
+// GetSWSVersion returns the installed SWS version, e.g. "2.14.0.3"
+func GetSWSVersion() (string, error) {
+	funcPtr, err := swsFunc("CF_GetSWSVersion")
+	if err != nil {
+		return "", err
+	}
+
+	buf := getBuffer(64)
+	defer buf.release()
+
+	C.plugin_bridge_call_cf_get_sws_version(funcPtr, buf.ptr, buf.cSize())
+	return buf.String(), nil
+}
+
+// GetTrackNotes returns a track's SWS notes
+func GetTrackNotes(track unsafe.Pointer) (string, error) {
+	if track == nil {
+		return "", fmt.Errorf("invalid track")
+	}
+
+	funcPtr, err := swsFunc("NF_GetSWSTrackNotes")
+	if err != nil {
+		return "", err
+	}
+
+	notes := C.plugin_bridge_call_nf_get_sws_track_notes(funcPtr, track)
+	if notes == nil {
+		return "", nil
+	}
+	return C.GoString(notes), nil
+}
+
+// SetTrackNotes replaces a track's SWS notes
+func SetTrackNotes(track unsafe.Pointer, notes string) error {
+	if track == nil {
+		return fmt.Errorf("invalid track")
+	}
+
+	funcPtr, err := swsFunc("NF_SetSWSTrackNotes")
+	if err != nil {
+		return err
+	}
+
+	cNotes := C.CString(notes)
+	defer C.free(unsafe.Pointer(cNotes))
+
+	C.plugin_bridge_call_nf_set_sws_track_notes(funcPtr, track, cNotes)
+	return nil
+}
+
+// SetClipboard replaces the system clipboard's contents with text
+func SetClipboard(text string) error {
+	funcPtr, err := swsFunc("CF_SetClipboard")
+	if err != nil {
+		return err
+	}
+
+	cText := C.CString(text)
+	defer C.free(unsafe.Pointer(cText))
+
+	C.plugin_bridge_call_cf_set_clipboard(funcPtr, cText)
+	return nil
+}
+
+// clipboardBufferSize is the most clipboard text GetClipboard returns, in bytes
+const clipboardBufferSize = 64 * 1024
+
+// GetClipboard returns the system clipboard's text, cut at 64 KB
+func GetClipboard() (string, error) {
+	funcPtr, err := swsFunc("CF_GetClipboard")
+	if err != nil {
+		return "", err
+	}
+
+	buf := getBuffer(clipboardBufferSize)
+	defer buf.release()
+
+	C.plugin_bridge_call_cf_get_clipboard(funcPtr, buf.ptr, buf.cSize())
+	return buf.String(), nil
+}
+
+// GetMouseCursorContext describes what is under the mouse, e.g. window "tcp", segment
+// "track" and details "empty". See BR_GetMouseCursorContext in the SWS documentation.
+func GetMouseCursorContext() (window, segment, details string, err error) {
+	funcPtr, err := swsFunc("BR_GetMouseCursorContext")
+	if err != nil {
+		return "", "", "", err
+	}
+
+	windowBuf := getBuffer(64)
+	defer windowBuf.release()
+	segmentBuf := getBuffer(64)
+	defer segmentBuf.release()
+	detailsBuf := getBuffer(64)
+	defer detailsBuf.release()
+
+	C.plugin_bridge_call_br_get_mouse_cursor_context(funcPtr,
+		windowBuf.ptr, windowBuf.cSize(), segmentBuf.ptr, segmentBuf.cSize(), detailsBuf.ptr, detailsBuf.cSize())
+	return windowBuf.String(), segmentBuf.String(), detailsBuf.String(), nil
+}
+
+// GetEnvelopePoints reads all points of an envelope, e.g. one from GetFXEnvelope
+func GetEnvelopePoints(envelope unsafe.Pointer) ([]EnvelopePoint, error) {
+	var points []EnvelopePoint
+	err := editEnvelope(envelope, false, func(brEnvelope unsafe.Pointer, funcs envelopeFuncs) error {
+		count := int(C.plugin_bridge_call_br_env_count_points(funcs.count, brEnvelope))
+		points = make([]EnvelopePoint, 0, count)
+
+		for i := 0; i < count; i++ {
+			var position, value, bezier C.double
+			var shape C.int
+			var selected C.bool
+			if !bool(C.plugin_bridge_call_br_env_get_point(funcs.get, brEnvelope, C.int(i), &position, &value, &shape, &selected, &bezier)) {
+				return fmt.Errorf("failed to read envelope point %d", i)
+			}
+			points = append(points, EnvelopePoint{
+				Position:      float64(position),
+				Value:         float64(value),
+				Shape:         int(shape),
+				Selected:      bool(selected),
+				BezierTension: float64(bezier),
+			})
+		}
+		return nil
+	})
+	return points, err
+}
+
+// SetEnvelopePoints updates points by index and adds any beyond the envelope's current
+// points, then writes the envelope back
+func SetEnvelopePoints(envelope unsafe.Pointer, points []EnvelopePoint) error {
+	return editEnvelope(envelope, true, func(brEnvelope unsafe.Pointer, funcs envelopeFuncs) error {
+		count := int(C.plugin_bridge_call_br_env_count_points(funcs.count, brEnvelope))
+
+		for i, point := range points {
+			id := i
+			if i >= count {
+				id = -1 // Add a new point
+			}
+			if !bool(C.plugin_bridge_call_br_env_set_point(funcs.set, brEnvelope, C.int(id),
+				C.double(point.Position), C.double(point.Value), C.int(point.Shape),
+				C.bool(point.Selected), C.double(point.BezierTension))) {
+				return fmt.Errorf("failed to set envelope point %d", i)
+			}
+		}
+		return nil
+	})
+}
+
+// envelopeFuncs holds the BR_Env function pointers used while editing
+type envelopeFuncs struct {
+	count, get, set unsafe.Pointer
+}
+
+// editEnvelope allocates an SWS envelope object, runs edit on it and frees it, writing
+// the changes back if commit is set and edit succeeded
+func editEnvelope(envelope unsafe.Pointer, commit bool, edit func(brEnvelope unsafe.Pointer, funcs envelopeFuncs) error) error {
+	if envelope == nil {
+		return fmt.Errorf("invalid envelope")
+	}
+
+	var funcs envelopeFuncs
+	names := map[string]*unsafe.Pointer{
+		"BR_EnvCountPoints": &funcs.count,
+		"BR_EnvGetPoint":    &funcs.get,
+		"BR_EnvSetPoint":    &funcs.set,
+	}
+	for name, ptr := range names {
+		funcPtr, err := swsFunc(name)
+		if err != nil {
+			return err
+		}
+		*ptr = funcPtr
+	}
+
+	allocPtr, err := swsFunc("BR_EnvAlloc")
+	if err != nil {
+		return err
+	}
+	freePtr, err := swsFunc("BR_EnvFree")
+	if err != nil {
+		return err
+	}
+
+	brEnvelope := C.plugin_bridge_call_br_env_alloc(allocPtr, envelope, C.bool(false))
+	if brEnvelope == nil {
+		return fmt.Errorf("failed to read the envelope")
+	}
+
+	err = edit(brEnvelope, funcs)
+	C.plugin_bridge_call_br_env_free(freePtr, brEnvelope, C.bool(commit && err == nil))
+	return err
+}
+
+// SWS snapshot actions. Snapshots store track mix settings (volume, pan, mute, FX and
+// more) and are recalled by slot.
+const (
+	swsSnapshotAddAction    = "_SWSSNAPSHOT_NEWALL"
+	swsSnapshotRecallAction = "_SWSSNAPSHOT_GET%d"
+)
+
+// AddSnapshot saves a new SWS snapshot of all tracks
+func AddSnapshot() error {
+	if !IsSWSInstalled() {
+		return ErrSWSNotInstalled
+	}
+	return RunNamedCommand(swsSnapshotAddAction)
+}
+
+// RecallSnapshot restores the SWS snapshot in a slot (1-based)
+func RecallSnapshot(slot int) error {
+	if !IsSWSInstalled() {
+		return ErrSWSNotInstalled
+	}
+	if slot < 1 {
+		return fmt.Errorf("invalid snapshot slot %d", slot)
+	}
+	return RunNamedCommand(fmt.Sprintf(swsSnapshotRecallAction, slot))
+}
@@ -0,0 +1,238 @@
+package reaper
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../c -I${SRCDIR}/../../sdk
+#include "../c/bridge.h"
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"fmt"
+	"unsafe"
+)
+
+// Item FX are the FX chains of takes. They are read and set like track FX, through
+// REAPER's TakeFX_ functions, which the bridge's track FX calls serve too. Video
+// processors, common as item FX in video projects, are handled like any other FX; their
+// parameters are the ones their code declares.
+
+// GetTakeFXCount returns the number of FX on a take
+func GetTakeFXCount(take unsafe.Pointer) (int, error) {
+	funcPtr, err := takeFunc(take, "TakeFX_GetCount")
+	if err != nil {
+		return 0, err
+	}
+	return int(C.plugin_bridge_call_track_fx_get_count(funcPtr, take)), nil
+}
+
+// GetTakeFXName returns the name of a take FX
+func GetTakeFXName(take unsafe.Pointer, fxIndex int) (string, error) {
+	funcPtr, err := takeFunc(take, "TakeFX_GetFXName")
+	if err != nil {
+		return "", err
+	}
+
+	name, _ := readString(GetStringBufferSize(), func(buf *cBuffer) bool {
+		C.plugin_bridge_call_track_fx_get_name(funcPtr, take, C.int(fxIndex), buf.ptr, buf.cSize())
+		return true
+	})
+	return name, nil
+}
+
+// GetTakeFXParamCount returns the number of parameters of a take FX
+func GetTakeFXParamCount(take unsafe.Pointer, fxIndex int) (int, error) {
+	funcPtr, err := takeFunc(take, "TakeFX_GetNumParams")
+	if err != nil {
+		return 0, err
+	}
+	return int(C.plugin_bridge_call_track_fx_get_param_count(funcPtr, take, C.int(fxIndex))), nil
+}
+
+// GetTakeFXParamName returns the name of a take FX parameter
+func GetTakeFXParamName(take unsafe.Pointer, fxIndex int, paramIndex int) (string, error) {
+	funcPtr, err := takeFunc(take, "TakeFX_GetParamName")
+	if err != nil {
+		return "", err
+	}
+
+	name, _ := readString(GetStringBufferSize(), func(buf *cBuffer) bool {
+		C.plugin_bridge_call_track_fx_get_param_name(funcPtr, take, C.int(fxIndex), C.int(paramIndex), buf.ptr, buf.cSize())
+		return true
+	})
+	return name, nil
+}
+
+// GetTakeFXParamValueWithRange returns a take FX parameter's value in its own range,
+// and the range. Video processor parameters use the ranges their code declares.
+func GetTakeFXParamValueWithRange(take unsafe.Pointer, fxIndex int, paramIndex int) (value, min, max float64, err error) {
+	funcPtr, err := takeFunc(take, "TakeFX_GetParam")
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	var cMin, cMax C.double
+	value = float64(C.plugin_bridge_call_track_fx_get_param(funcPtr, take, C.int(fxIndex), C.int(paramIndex), &cMin, &cMax))
+	return value, float64(cMin), float64(cMax), nil
+}
+
+// GetTakeFXParamValue returns a take FX parameter's normalized value, 0 to 1
+func GetTakeFXParamValue(take unsafe.Pointer, fxIndex int, paramIndex int) (float64, error) {
+	value, min, max, err := GetTakeFXParamValueWithRange(take, fxIndex, paramIndex)
+	if err != nil {
+		return 0, err
+	}
+	return normalizeParam(value, min, max), nil
+}
+
+// SetTakeFXParamValue sets a take FX parameter from a normalized value, 0 to 1. Call it
+// inside an undo block.
+func SetTakeFXParamValue(take unsafe.Pointer, fxIndex int, paramIndex int, value float64) error {
+	if value < 0 || value > 1 {
+		return fmt.Errorf("invalid normalized value: %f", value)
+	}
+
+	funcPtr, err := takeFunc(take, "TakeFX_SetParamNormalized")
+	if err != nil {
+		return err
+	}
+
+	if !C.plugin_bridge_call_track_fx_set_param(funcPtr, take, C.int(fxIndex), C.int(paramIndex), C.double(value)) {
+		return fmt.Errorf("failed to set parameter %d of take FX %d", paramIndex, fxIndex)
+	}
+	return nil
+}
+
+// GetTakeFXParamFormatted returns a take FX parameter's value as the FX displays it
+func GetTakeFXParamFormatted(take unsafe.Pointer, fxIndex int, paramIndex int) (string, error) {
+	funcPtr, err := takeFunc(take, "TakeFX_GetFormattedParamValue")
+	if err != nil {
+		return "", err
+	}
+
+	formatted, _ := readString(GetStringBufferSize(), func(buf *cBuffer) bool {
+		C.plugin_bridge_call_track_fx_get_param_formatted(funcPtr, take, C.int(fxIndex), C.int(paramIndex), buf.ptr, buf.cSize())
+		return true
+	})
+	return formatted, nil
+}
+
+// GetTakeFXEnabled reports whether a take FX is active rather than bypassed
+func GetTakeFXEnabled(take unsafe.Pointer, fxIndex int) (bool, error) {
+	funcPtr, err := takeFunc(take, "TakeFX_GetEnabled")
+	if err != nil {
+		return false, err
+	}
+	return bool(C.plugin_bridge_call_track_fx_get_enabled(funcPtr, take, C.int(fxIndex))), nil
+}
+
+// SetTakeFXEnabled bypasses or enables a take FX. Call it inside an undo block.
+func SetTakeFXEnabled(take unsafe.Pointer, fxIndex int, enabled bool) error {
+	funcPtr, err := takeFunc(take, "TakeFX_SetEnabled")
+	if err != nil {
+		return err
+	}
+	C.plugin_bridge_call_track_fx_set_enabled(funcPtr, take, C.int(fxIndex), C.bool(enabled))
+	return nil
+}
+
+// GetTakeFXOffline reports whether a take FX is offline
+func GetTakeFXOffline(take unsafe.Pointer, fxIndex int) (bool, error) {
+	funcPtr, err := takeFunc(take, "TakeFX_GetOffline")
+	if err != nil {
+		return false, err
+	}
+	return bool(C.plugin_bridge_call_track_fx_get_offline(funcPtr, take, C.int(fxIndex))), nil
+}
+
+// GetTakeFXNamedConfigParam reads a named take FX property via TakeFX_GetNamedConfigParm
+func GetTakeFXNamedConfigParam(take unsafe.Pointer, fxIndex int, name string) (string, error) {
+	funcPtr, err := takeFunc(take, "TakeFX_GetNamedConfigParm")
+	if err != nil {
+		return "", err
+	}
+
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+
+	value, ok := readString(defaultConfigBufferSize, func(buf *cBuffer) bool {
+		return bool(C.plugin_bridge_call_track_fx_get_named_config_parm(funcPtr, take, C.int(fxIndex), cName, buf.ptr, buf.cSize()))
+	})
+	if !ok {
+		return "", fmt.Errorf("take FX %d does not provide %s", fxIndex, name)
+	}
+	return value, nil
+}
+
+// GetTakeFXParameters reads a take FX with its identity, state and parameters, like
+// GetFXParameters does for track FX. Values are normalized, 0 to 1.
+func GetTakeFXParameters(take unsafe.Pointer, fxIndex int) (FXInfo, error) {
+	result := FXInfo{Index: fxIndex, Parameters: []FXParameter{}}
+
+	var err error
+	if result.Name, err = GetTakeFXName(take, fxIndex); err != nil {
+		return result, fmt.Errorf("failed to get FX name: %v", err)
+	}
+
+	fxType, _ := GetTakeFXNamedConfigParam(take, fxIndex, FXConfigType)
+	pluginID, _ := GetTakeFXNamedConfigParam(take, fxIndex, FXConfigIdent)
+	originalName, _ := GetTakeFXNamedConfigParam(take, fxIndex, FXConfigName)
+	setFXIdentity(&result, fxType, pluginID, originalName)
+
+	if result.Enabled, err = GetTakeFXEnabled(take, fxIndex); err != nil {
+		return result, fmt.Errorf("failed to get FX enabled state: %v", err)
+	}
+	if result.Offline, err = GetTakeFXOffline(take, fxIndex); err != nil {
+		return result, fmt.Errorf("failed to get FX offline state: %v", err)
+	}
+
+	count, err := GetTakeFXParamCount(take, fxIndex)
+	if err != nil {
+		return result, err
+	}
+	for i := 0; i < count; i++ {
+		name, err := GetTakeFXParamName(take, fxIndex, i)
+		if err != nil {
+			return result, err
+		}
+		value, min, max, err := GetTakeFXParamValueWithRange(take, fxIndex, i)
+		if err != nil {
+			return result, err
+		}
+		formatted, _ := GetTakeFXParamFormatted(take, fxIndex, i)
+		result.Parameters = append(result.Parameters, FXParameter{
+			Index:          i,
+			Name:           name,
+			Value:          normalizeParam(value, min, max),
+			FormattedValue: formatted,
+			Min:            min,
+			Max:            max,
+		})
+	}
+	return result, nil
+}
+
+// GetTakeFXList reads every FX on a take with its parameters
+func GetTakeFXList(take unsafe.Pointer) ([]FXInfo, error) {
+	count, err := GetTakeFXCount(take)
+	if err != nil {
+		return nil, err
+	}
+
+	fxList := make([]FXInfo, 0, count)
+	for i := 0; i < count; i++ {
+		fx, err := GetTakeFXParameters(take, i)
+		if err != nil {
+			return nil, fmt.Errorf("take FX %d: %v", i, err)
+		}
+		fxList = append(fxList, fx)
+	}
+	return fxList, nil
+}
+
+// normalizeParam maps a value in min to max to 0 to 1
+func normalizeParam(value, min, max float64) float64 {
+	if max <= min {
+		return value
+	}
+	return (value - min) / (max - min)
+}
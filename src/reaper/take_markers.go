@@ -0,0 +1,269 @@
+package reaper
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../c -I${SRCDIR}/../../sdk
+#include "../c/bridge.h"
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"fmt"
+	"unsafe"
+)
+
+// Take markers label positions in a take's source media and move with it when the item
+// is trimmed or moved, so analysis results (clipping, onsets, loud passages) can be
+// pinned to the audio they were found in. Stretch markers mark positions in the item
+// that time stretching is anchored to.
+
+// takeMarkerNameSize is the buffer size for take marker names
+const takeMarkerNameSize = 512
+
+// TakeMarker is a marker on a take
+type TakeMarker struct {
+	Index          int // Position among the take's markers; -1 when adding a marker
+	Name           string
+	SourcePosition float64 // Position in the take's source, seconds
+	Color          Color
+	HasColor       bool // False for the default color; when updating, the color is left as it is
+}
+
+// StretchMarker is a stretch marker on a take
+type StretchMarker struct {
+	Index          int     // Position among the take's stretch markers; -1 when adding a marker
+	Position       float64 // Position in the item at the take's play rate, seconds
+	SourcePosition float64 // Position in the take's source the marker is anchored to, seconds
+	Slope          float64 // Rate change across the segment after the marker, -1 to 1
+}
+
+// takeFunc looks up a take function and checks the take
+func takeFunc(take unsafe.Pointer, name string) (unsafe.Pointer, error) {
+	if take == nil {
+		return nil, fmt.Errorf("invalid take")
+	}
+	return projectFunc(name)
+}
+
+// GetTakeMarkers returns a take's markers, in source order
+func GetTakeMarkers(take unsafe.Pointer) ([]TakeMarker, error) {
+	countFunc, err := takeFunc(take, "GetNumTakeMarkers")
+	if err != nil {
+		return nil, err
+	}
+	getFunc, err := projectFunc("GetTakeMarker")
+	if err != nil {
+		return nil, err
+	}
+
+	count := int(C.plugin_bridge_call_get_num_take_markers(countFunc, take))
+	buf := getBuffer(takeMarkerNameSize)
+	defer buf.release()
+
+	markers := make([]TakeMarker, 0, count)
+	for i := 0; i < count; i++ {
+		var cColor C.int
+		position := float64(C.plugin_bridge_call_get_take_marker(getFunc, take, C.int(i), buf.ptr, buf.cSize(), &cColor))
+		if position < 0 {
+			return nil, fmt.Errorf("take marker %d not found", i)
+		}
+
+		color, hasColor, err := colorFromNative(int(cColor))
+		if err != nil {
+			return nil, err
+		}
+		markers = append(markers, TakeMarker{
+			Index:          i,
+			Name:           buf.String(),
+			SourcePosition: position,
+			Color:          color,
+			HasColor:       hasColor,
+		})
+	}
+	return markers, nil
+}
+
+// SetTakeMarker updates the marker at marker.Index, or adds one if Index is -1, and
+// returns its index. Markers are kept in source order, so the index can change. Call it
+// inside an undo block, and UpdateItem afterwards.
+func SetTakeMarker(take unsafe.Pointer, marker TakeMarker) (int, error) {
+	if marker.SourcePosition < 0 {
+		return -1, fmt.Errorf("invalid take marker position: %f", marker.SourcePosition)
+	}
+
+	funcPtr, err := takeFunc(take, "SetTakeMarker")
+	if err != nil {
+		return -1, err
+	}
+
+	index := marker.Index
+	if index < 0 {
+		index = -1
+	}
+
+	var colorPtr *C.int
+	if marker.HasColor {
+		native, err := nativeColor(marker.Color)
+		if err != nil {
+			return -1, err
+		}
+		cColor := C.int(native)
+		colorPtr = &cColor
+	}
+
+	cName := C.CString(marker.Name)
+	defer C.free(unsafe.Pointer(cName))
+	cPosition := C.double(marker.SourcePosition)
+
+	result := int(C.plugin_bridge_call_set_take_marker(funcPtr, take, C.int(index), cName, &cPosition, colorPtr))
+	if result < 0 {
+		if index < 0 {
+			return -1, fmt.Errorf("failed to add take marker at %.3fs", marker.SourcePosition)
+		}
+		return -1, fmt.Errorf("failed to update take marker %d", index)
+	}
+	return result, nil
+}
+
+// DeleteTakeMarker removes the take marker at index. Call it inside an undo block, and
+// UpdateItem afterwards.
+func DeleteTakeMarker(take unsafe.Pointer, index int) error {
+	funcPtr, err := takeFunc(take, "DeleteTakeMarker")
+	if err != nil {
+		return err
+	}
+
+	if !C.plugin_bridge_call_delete_take_marker(funcPtr, take, C.int(index)) {
+		return fmt.Errorf("take marker %d not found", index)
+	}
+	return nil
+}
+
+// MarkTakeAt adds a take marker at each project time that falls within the take's item,
+// converting it to a source position, and redraws the item. It returns how many were
+// added. Times outside the item are skipped. Call it inside an undo block.
+func MarkTakeAt(take TakeInfo, name string, color *Color, projectTimes []float64) (int, error) {
+	added := 0
+	for _, time := range projectTimes {
+		if time < take.Position || time > take.End() {
+			continue
+		}
+
+		marker := TakeMarker{Index: -1, Name: name, SourcePosition: take.SourcePosition(time)}
+		if color != nil {
+			marker.Color, marker.HasColor = *color, true
+		}
+		if _, err := SetTakeMarker(take.Take, marker); err != nil {
+			return added, err
+		}
+		added++
+	}
+
+	if added > 0 {
+		if err := UpdateItem(take.Item); err != nil {
+			return added, err
+		}
+	}
+	return added, nil
+}
+
+// GetStretchMarkers returns a take's stretch markers, in order
+func GetStretchMarkers(take unsafe.Pointer) ([]StretchMarker, error) {
+	countFunc, err := takeFunc(take, "GetTakeNumStretchMarkers")
+	if err != nil {
+		return nil, err
+	}
+	getFunc, err := projectFunc("GetTakeStretchMarker")
+	if err != nil {
+		return nil, err
+	}
+	slopeFunc, err := projectFunc("GetTakeStretchMarkerSlope")
+	if err != nil {
+		return nil, err
+	}
+
+	count := int(C.plugin_bridge_call_get_take_num_stretch_markers(countFunc, take))
+	markers := make([]StretchMarker, 0, count)
+	for i := 0; i < count; i++ {
+		var cPosition, cSource C.double
+		if C.plugin_bridge_call_get_take_stretch_marker(getFunc, take, C.int(i), &cPosition, &cSource) < 0 {
+			return nil, fmt.Errorf("stretch marker %d not found", i)
+		}
+		markers = append(markers, StretchMarker{
+			Index:          i,
+			Position:       float64(cPosition),
+			SourcePosition: float64(cSource),
+			Slope:          float64(C.plugin_bridge_call_get_take_stretch_marker_slope(slopeFunc, take, C.int(i))),
+		})
+	}
+	return markers, nil
+}
+
+// AddStretchMarker adds a stretch marker at a position in the item, anchored to the
+// source position currently playing there, and returns its index. Call it inside an
+// undo block, and UpdateItem afterwards.
+func AddStretchMarker(take unsafe.Pointer, position float64) (int, error) {
+	funcPtr, err := takeFunc(take, "SetTakeStretchMarker")
+	if err != nil {
+		return -1, err
+	}
+
+	index := int(C.plugin_bridge_call_set_take_stretch_marker(funcPtr, take, -1, C.double(position), nil))
+	if index < 0 {
+		return -1, fmt.Errorf("failed to add a stretch marker at %.3fs; there may already be one there", position)
+	}
+	return index, nil
+}
+
+// SetStretchMarker updates the stretch marker at marker.Index, or adds one if Index is
+// -1, with both its positions, and sets its slope. REAPER keeps markers between their
+// neighbours, so positions may be adjusted. It returns the marker's index. Call it inside
+// an undo block, and UpdateItem afterwards.
+func SetStretchMarker(take unsafe.Pointer, marker StretchMarker) (int, error) {
+	if marker.Slope < -1 || marker.Slope > 1 {
+		return -1, fmt.Errorf("invalid stretch marker slope: %f", marker.Slope)
+	}
+
+	funcPtr, err := takeFunc(take, "SetTakeStretchMarker")
+	if err != nil {
+		return -1, err
+	}
+	slopeFunc, err := projectFunc("SetTakeStretchMarkerSlope")
+	if err != nil {
+		return -1, err
+	}
+
+	index := marker.Index
+	if index < 0 {
+		index = -1
+	}
+
+	cSource := C.double(marker.SourcePosition)
+	result := int(C.plugin_bridge_call_set_take_stretch_marker(funcPtr, take, C.int(index), C.double(marker.Position), &cSource))
+	if result < 0 {
+		if index < 0 {
+			return -1, fmt.Errorf("failed to add a stretch marker at %.3fs", marker.Position)
+		}
+		return -1, fmt.Errorf("failed to update stretch marker %d", index)
+	}
+
+	if !C.plugin_bridge_call_set_take_stretch_marker_slope(slopeFunc, take, C.int(result), C.double(marker.Slope)) {
+		return result, fmt.Errorf("failed to set the slope of stretch marker %d", result)
+	}
+	return result, nil
+}
+
+// DeleteStretchMarkers removes count stretch markers starting at index, and returns how
+// many were removed. Call it inside an undo block, and UpdateItem afterwards.
+func DeleteStretchMarkers(take unsafe.Pointer, index, count int) (int, error) {
+	if count < 1 {
+		return 0, nil
+	}
+
+	funcPtr, err := takeFunc(take, "DeleteTakeStretchMarkers")
+	if err != nil {
+		return 0, err
+	}
+
+	cCount := C.int(count)
+	return int(C.plugin_bridge_call_delete_take_stretch_markers(funcPtr, take, C.int(index), &cCount)), nil
+}
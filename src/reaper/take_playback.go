@@ -0,0 +1,96 @@
+package reaper
+
+import (
+	"fmt"
+	"math"
+)
+
+// A take's play rate speeds it up or slows it down. Without preserve pitch the pitch
+// follows the rate, like varispeed on tape; with it, only the timing changes. The
+// take's pitch shift is applied on top either way.
+
+// Limits for take playback changes
+const (
+	MinPlayRate   = 0.1 // A tenth of normal speed
+	MaxPlayRate   = 10  // Ten times normal speed
+	MaxPitchShift = 48  // Semitones either way
+)
+
+// TakePlayback is how a take plays back. Nil fields are left as they are.
+type TakePlayback struct {
+	PlayRate      *float64
+	Pitch         *float64 // Semitones
+	PreservePitch *bool
+}
+
+// SemitonesToRate returns the play rate that shifts the pitch by semitones when pitch
+// isn't preserved, e.g. 2 for 12
+func SemitonesToRate(semitones float64) float64 {
+	return math.Pow(2, semitones/12)
+}
+
+// RateToSemitones returns how many semitones a play rate shifts the pitch by when pitch
+// isn't preserved, the inverse of SemitonesToRate
+func RateToSemitones(rate float64) float64 {
+	if rate <= 0 {
+		return 0
+	}
+	return 12 * math.Log2(rate)
+}
+
+// SetTakePlayback changes how a take plays back. When the play rate changes, the item's
+// length is scaled so it still covers the same part of the source, as REAPER does when
+// the rate is changed from the item properties. It returns the take's details after the
+// change. Call it inside an undo block.
+func SetTakePlayback(take TakeInfo, playback TakePlayback) (TakeInfo, error) {
+	if playback.PlayRate != nil {
+		rate := *playback.PlayRate
+		if math.IsNaN(rate) || rate < MinPlayRate || rate > MaxPlayRate {
+			return take, fmt.Errorf("play rate %g is out of range (%g to %g)", rate, float64(MinPlayRate), float64(MaxPlayRate))
+		}
+	}
+	if playback.Pitch != nil {
+		pitch := *playback.Pitch
+		if math.IsNaN(pitch) || math.Abs(pitch) > MaxPitchShift {
+			return take, fmt.Errorf("pitch shift %g is out of range (±%d semitones)", pitch, MaxPitchShift)
+		}
+	}
+
+	if playback.PreservePitch != nil {
+		value := 0.0
+		if *playback.PreservePitch {
+			value = 1
+		}
+		if err := SetTakeInfoValue(take.Take, takePropertyPreserve, value); err != nil {
+			return take, err
+		}
+		take.PreservePitch = *playback.PreservePitch
+	}
+
+	if playback.Pitch != nil {
+		if err := SetTakeInfoValue(take.Take, takePropertyPitch, *playback.Pitch); err != nil {
+			return take, err
+		}
+		take.Pitch = *playback.Pitch
+	}
+
+	if playback.PlayRate != nil && *playback.PlayRate != take.PlayRate {
+		rate := *playback.PlayRate
+		if err := SetTakeInfoValue(take.Take, takePropertyPlayRate, rate); err != nil {
+			return take, err
+		}
+		if take.PlayRate > 0 {
+			length := take.Length * take.PlayRate / rate
+			if err := SetItemInfoValue(take.Item, itemPropertyLength, length); err != nil {
+				return take, err
+			}
+			take.Length = length
+		}
+		take.PlayRate = rate
+	}
+
+	if err := UpdateItem(take.Item); err != nil {
+		return take, err
+	}
+	return take, nil
+}
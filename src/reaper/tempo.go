@@ -0,0 +1,342 @@
+package reaper
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../c -I${SRCDIR}/../../sdk
+#include "../c/bridge.h"
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// NoteLength represents a musical note duration such as "1/8 dotted"
+type NoteLength struct {
+	Division int     // Denominator of the note value (4 = quarter note, 8 = eighth note, ...)
+	Modifier float64 // Duration multiplier (1.0 straight, 1.5 dotted, 2/3 triplet)
+}
+
+// Note length modifiers
+const (
+	NoteStraight = 1.0
+	NoteDotted   = 1.5
+	NoteTriplet  = 2.0 / 3.0
+)
+
+// GetProjectTempo returns the tempo (BPM) and beats per measure of the active project
+func GetProjectTempo() (bpm float64, beatsPerMeasure float64, err error) {
+	getFuncPtr, err := projectFunc("GetProjectTimeSignature2")
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var cBPM, cBPI C.double
+	C.plugin_bridge_call_get_project_time_signature2(getFuncPtr, nil, &cBPM, &cBPI)
+
+	bpm = float64(cBPM)
+	if bpm <= 0 {
+		return 0, 0, fmt.Errorf("invalid project tempo: %f", bpm)
+	}
+
+	return bpm, float64(cBPI), nil
+}
+
+// TempoMarker is a point on the project's tempo map
+type TempoMarker struct {
+	Index        int     // Position in the tempo map; -1 when adding a marker
+	Time         float64 // Project time in seconds
+	Measure      int     // Measure the marker falls in (0-based)
+	Beat         float64 // Beat position within the measure
+	BPM          float64
+	TimeSigNum   int  // Time signature numerator, 0 if the marker keeps the previous one
+	TimeSigDenom int  // Time signature denominator, 0 if the marker keeps the previous one
+	Linear       bool // Tempo ramps linearly to the next marker instead of jumping
+}
+
+// CountTempoTimeSigMarkers returns the number of tempo/time signature markers in the active project
+func CountTempoTimeSigMarkers() (int, error) {
+	funcPtr, err := projectFunc("CountTempoTimeSigMarkers")
+	if err != nil {
+		return 0, err
+	}
+
+	return int(C.plugin_bridge_call_count_tempo_time_sig_markers(funcPtr, nil)), nil
+}
+
+// GetTempoTimeSigMarker returns the tempo/time signature marker at index (0-based)
+func GetTempoTimeSigMarker(index int) (TempoMarker, error) {
+	funcPtr, err := projectFunc("GetTempoTimeSigMarker")
+	if err != nil {
+		return TempoMarker{}, err
+	}
+
+	var cTime, cBeat, cBPM C.double
+	var cMeasure, cNum, cDenom C.int
+	var cLinear C.bool
+	ok := C.plugin_bridge_call_get_tempo_time_sig_marker(funcPtr, nil, C.int(index),
+		&cTime, &cMeasure, &cBeat, &cBPM, &cNum, &cDenom, &cLinear)
+	if !ok {
+		return TempoMarker{}, fmt.Errorf("tempo marker %d not found", index)
+	}
+
+	return TempoMarker{
+		Index:        index,
+		Time:         float64(cTime),
+		Measure:      int(cMeasure),
+		Beat:         float64(cBeat),
+		BPM:          float64(cBPM),
+		TimeSigNum:   int(cNum),
+		TimeSigDenom: int(cDenom),
+		Linear:       bool(cLinear),
+	}, nil
+}
+
+// GetTempoTimeSigMarkers returns every tempo/time signature marker in the active project, in time order
+func GetTempoTimeSigMarkers() ([]TempoMarker, error) {
+	count, err := CountTempoTimeSigMarkers()
+	if err != nil {
+		return nil, err
+	}
+
+	markers := make([]TempoMarker, 0, count)
+	for i := 0; i < count; i++ {
+		marker, err := GetTempoTimeSigMarker(i)
+		if err != nil {
+			return nil, err
+		}
+		markers = append(markers, marker)
+	}
+	return markers, nil
+}
+
+// SetTempoTimeSigMarker updates the marker at marker.Index, or adds one if Index is -1.
+// The marker is placed by Time; Measure and Beat are ignored. The timeline is redrawn
+// afterwards, but callers should wrap changes in an undo block themselves.
+func SetTempoTimeSigMarker(marker TempoMarker) error {
+	if marker.BPM <= 0 {
+		return fmt.Errorf("invalid tempo: %f", marker.BPM)
+	}
+	if marker.Time < 0 {
+		return fmt.Errorf("invalid marker time: %f", marker.Time)
+	}
+	if (marker.TimeSigNum == 0) != (marker.TimeSigDenom == 0) || marker.TimeSigNum < 0 || marker.TimeSigDenom < 0 {
+		return fmt.Errorf("invalid time signature: %d/%d", marker.TimeSigNum, marker.TimeSigDenom)
+	}
+
+	funcPtr, err := projectFunc("SetTempoTimeSigMarker")
+	if err != nil {
+		return err
+	}
+
+	index := marker.Index
+	if index < 0 {
+		index = -1
+	}
+
+	ok := C.plugin_bridge_call_set_tempo_time_sig_marker(funcPtr, nil, C.int(index),
+		C.double(marker.Time), -1, -1, C.double(marker.BPM),
+		C.int(marker.TimeSigNum), C.int(marker.TimeSigDenom), C.bool(marker.Linear))
+	if !ok {
+		if index < 0 {
+			return fmt.Errorf("failed to add tempo marker at %.3fs", marker.Time)
+		}
+		return fmt.Errorf("failed to set tempo marker %d", index)
+	}
+
+	return updateTimeline()
+}
+
+// AddTempoTimeSigMarker adds a tempo change at a project time in seconds. Pass 0 for
+// num and denom to keep the time signature already in effect.
+func AddTempoTimeSigMarker(time float64, bpm float64, num int, denom int) error {
+	return SetTempoTimeSigMarker(TempoMarker{
+		Index:        -1,
+		Time:         time,
+		BPM:          bpm,
+		TimeSigNum:   num,
+		TimeSigDenom: denom,
+	})
+}
+
+// DeleteTempoTimeSigMarker removes the tempo/time signature marker at index (0-based)
+func DeleteTempoTimeSigMarker(index int) error {
+	funcPtr, err := projectFunc("DeleteTempoTimeSigMarker")
+	if err != nil {
+		return err
+	}
+
+	if !C.plugin_bridge_call_delete_tempo_time_sig_marker(funcPtr, nil, C.int(index)) {
+		return fmt.Errorf("failed to delete tempo marker %d", index)
+	}
+
+	return updateTimeline()
+}
+
+// GetTempoAtTime returns the tempo and time signature in effect at a project time in seconds,
+// taking tempo markers into account
+func GetTempoAtTime(time float64) (bpm float64, num int, denom int, err error) {
+	funcPtr, err := projectFunc("TimeMap_GetTimeSigAtTime")
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	var cNum, cDenom C.int
+	var cTempo C.double
+	C.plugin_bridge_call_time_map_get_time_sig_at_time(funcPtr, nil, C.double(time), &cNum, &cDenom, &cTempo)
+
+	bpm = float64(cTempo)
+	if bpm <= 0 {
+		return 0, 0, 0, fmt.Errorf("invalid tempo at %.3fs: %f", time, bpm)
+	}
+
+	return bpm, int(cNum), int(cDenom), nil
+}
+
+// updateTimeline redraws the ruler and arrange view after the tempo map changes
+func updateTimeline() error {
+	funcPtr, err := projectFunc("UpdateTimeline")
+	if err != nil {
+		return err
+	}
+
+	C.plugin_bridge_call_update_timeline(funcPtr)
+	return nil
+}
+
+// ParseNoteLength parses note lengths like "1/4", "1/8 dotted", "1/8d", "1/8.", "1/16 triplet" or "1/16T"
+func ParseNoteLength(input string) (NoteLength, error) {
+	s := strings.ToLower(strings.TrimSpace(input))
+	if s == "" {
+		return NoteLength{}, fmt.Errorf("empty note length")
+	}
+
+	result := NoteLength{Modifier: NoteStraight}
+
+	// Strip the modifier suffix, if any
+	switch {
+	case strings.HasSuffix(s, "dotted"):
+		result.Modifier = NoteDotted
+		s = strings.TrimSuffix(s, "dotted")
+	case strings.HasSuffix(s, "triplet"):
+		result.Modifier = NoteTriplet
+		s = strings.TrimSuffix(s, "triplet")
+	case strings.HasSuffix(s, "d"), strings.HasSuffix(s, "."):
+		result.Modifier = NoteDotted
+		s = s[:len(s)-1]
+	case strings.HasSuffix(s, "t"):
+		result.Modifier = NoteTriplet
+		s = s[:len(s)-1]
+	}
+	s = strings.TrimSpace(s)
+
+	// Accept both "1/8" and a bare "8"
+	s = strings.TrimPrefix(s, "1/")
+
+	division, err := strconv.Atoi(s)
+	if err != nil || division <= 0 {
+		return NoteLength{}, fmt.Errorf("invalid note length: %s", input)
+	}
+	result.Division = division
+
+	return result, nil
+}
+
+// Milliseconds returns the duration of the note at the given tempo, where a beat is a quarter note
+func (n NoteLength) Milliseconds(bpm float64) float64 {
+	if bpm <= 0 || n.Division <= 0 {
+		return 0
+	}
+
+	quarterMs := 60000.0 / bpm
+	return quarterMs * 4.0 / float64(n.Division) * n.Modifier
+}
+
+// Hertz returns the frequency corresponding to one note per cycle at the given tempo
+func (n NoteLength) Hertz(bpm float64) float64 {
+	ms := n.Milliseconds(bpm)
+	if ms <= 0 {
+		return 0
+	}
+	return 1000.0 / ms
+}
+
+// NoteLengthToMs converts a note length string to milliseconds at the current project tempo
+func NoteLengthToMs(note string) (float64, error) {
+	length, err := ParseNoteLength(note)
+	if err != nil {
+		return 0, err
+	}
+
+	bpm, _, err := GetProjectTempo()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get project tempo: %v", err)
+	}
+
+	return length.Milliseconds(bpm), nil
+}
+
+// NoteLengthToHz converts a note length string to a rate in Hz at the current project tempo
+func NoteLengthToHz(note string) (float64, error) {
+	length, err := ParseNoteLength(note)
+	if err != nil {
+		return 0, err
+	}
+
+	bpm, _, err := GetProjectTempo()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get project tempo: %v", err)
+	}
+
+	return length.Hertz(bpm), nil
+}
+
+// SetTrackFXParamNoteLength sets a time-based parameter to a tempo-synced note length.
+// The parameter's formatted unit decides whether the note is applied as milliseconds,
+// seconds or Hz (e.g. delay times vs. LFO rates).
+func SetTrackFXParamNoteLength(track unsafe.Pointer, fxIndex int, paramIndex int, note string) (float64, error) {
+	length, err := ParseNoteLength(note)
+	if err != nil {
+		return 0, err
+	}
+
+	bpm, _, err := GetProjectTempo()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get project tempo: %v", err)
+	}
+
+	// Inspect the current formatted value to work out which unit the plugin displays
+	formatted, err := GetTrackFXParamFormatted(track, fxIndex, paramIndex)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get formatted parameter value: %v", err)
+	}
+
+	var target float64
+	switch unit := formattedUnit(formatted); unit {
+	case "hz", "khz":
+		// Formatted values are parsed in Hz, including "kHz" displays
+		target = length.Hertz(bpm)
+	case "s", "sec":
+		target = length.Milliseconds(bpm) / 1000.0
+	default:
+		target = length.Milliseconds(bpm)
+	}
+
+	return SetTrackFXParamFromUnitValue(track, fxIndex, paramIndex, target)
+}
+
+// formattedUnit extracts the lower-cased unit suffix from a formatted value like "250.0 ms"
+func formattedUnit(formatted string) string {
+	s := strings.TrimSpace(formatted)
+	i := len(s)
+	for i > 0 {
+		c := s[i-1]
+		if (c >= '0' && c <= '9') || c == '.' || c == ' ' {
+			break
+		}
+		i--
+	}
+	return strings.ToLower(strings.TrimSpace(s[i:]))
+}
@@ -0,0 +1,66 @@
+package reaper
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../c -I${SRCDIR}/../../sdk
+#include "../c/bridge.h"
+*/
+import "C"
+import (
+	"sync"
+	"time"
+)
+
+// Functions waiting for the next timer tick. They have their own lock because Defer is
+// called while logging, which can happen with mutex held.
+var (
+	deferredMutex sync.Mutex
+	deferred      []func()
+)
+
+// Defer runs fn on the main thread at REAPER's next timer tick, once the current
+// callback (or, during loading, startup) has finished
+func Defer(fn func()) {
+	deferredMutex.Lock()
+	defer deferredMutex.Unlock()
+
+	deferred = append(deferred, fn)
+}
+
+// After runs fn on the main thread at the first timer tick once delay has passed.
+// Ticks come about every 33ms, so that is the resolution of the delay.
+func After(delay time.Duration, fn func()) {
+	deadline := time.Now().Add(delay)
+
+	var wait func()
+	wait = func() {
+		if time.Now().Before(deadline) {
+			Defer(wait)
+			return
+		}
+		fn()
+	}
+	Defer(wait)
+}
+
+// IsMainThread reports whether the caller is on REAPER's main thread, the only thread
+// REAPER's API may be called from. Goroutines started by the extension never are; they
+// hand their REAPER work to the main thread with core.MainThread.
+func IsMainThread() bool {
+	return bool(C.plugin_bridge_is_main_thread())
+}
+
+// Timer callback handler
+//
+//export goTimerProc
+func goTimerProc() {
+	defer Recover("goTimerProc")
+
+	deferredMutex.Lock()
+	pending := deferred
+	deferred = nil
+	deferredMutex.Unlock()
+
+	for _, fn := range pending {
+		fn()
+	}
+}
@@ -0,0 +1,94 @@
+package reaper
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// TrackCollectionSchema is the version of TrackCollection's JSON form. Raise it when a
+// change would make older readers misread a collection; adding optional fields doesn't.
+//
+// Version 1:
+//
+//	{
+//	  "schema": 1,
+//	  "tracks": [
+//	    {
+//	      "guid": "{2B6E8C7A-...}",
+//	      "fx": [                       // null when LoadFX wasn't called
+//	        {
+//	          "index": 0, "name": "VST: ReaEQ (Cockos)", "enabled": true, "offline": false,
+//	          "type": "VST", "plugin_name": "ReaEQ", "vendor": "Cockos", "plugin_id": "reaeq.dll",
+//	          "parameters": [
+//	            {"index": 0, "name": "Freq-Low Shelf", "value": 0.25, "formattedValue": "100.0",
+//	             "min": 0, "max": 1},
+//	            {"index": 24, "name": "Wet (FX mix)", "value": 1, "formattedValue": "100%",
+//	             "min": 0, "max": 1, "ident": ":wet"}
+//	          ]
+//	        }
+//	      ]
+//	    }
+//	  ]
+//	}
+//
+// FX and parameters use the JSON tags of FXInfo and FXParameter.
+const TrackCollectionSchema = 1
+
+// trackCollectionJSON is the JSON form of a TrackCollection
+type trackCollectionJSON struct {
+	Schema int              `json:"schema"`
+	Tracks []trackEntryJSON `json:"tracks"`
+}
+
+// trackEntryJSON is a track in a collection's JSON form
+type trackEntryJSON struct {
+	GUID string   `json:"guid"`
+	FX   []FXInfo `json:"fx"`
+}
+
+// MarshalJSON encodes the collection's tracks and any loaded FX. Track pointers aren't
+// stored; a decoded collection finds its tracks by GUID when resolved.
+func (c *TrackCollection) MarshalJSON() ([]byte, error) {
+	out := trackCollectionJSON{Schema: TrackCollectionSchema, Tracks: make([]trackEntryJSON, len(c.refs))}
+	for i, ref := range c.refs {
+		out.Tracks[i] = trackEntryJSON{GUID: ref.GUID, FX: c.fx[ref.GUID]}
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON decodes a collection written by MarshalJSON. Collections from a newer
+// schema are refused rather than partly read.
+func (c *TrackCollection) UnmarshalJSON(data []byte) error {
+	var in trackCollectionJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	if in.Schema < 1 || in.Schema > TrackCollectionSchema {
+		return fmt.Errorf("unsupported track collection schema %d (this version reads up to %d)", in.Schema, TrackCollectionSchema)
+	}
+
+	decoded := TrackCollection{}
+	seen := make(map[string]bool)
+	for i, entry := range in.Tracks {
+		guid := strings.TrimSpace(entry.GUID)
+		if guid == "" {
+			return fmt.Errorf("track %d has no GUID", i)
+		}
+		if seen[strings.ToUpper(guid)] {
+			continue
+		}
+		seen[strings.ToUpper(guid)] = true
+
+		decoded.refs = append(decoded.refs, TrackRef{GUID: guid})
+		if entry.FX != nil {
+			if decoded.fx == nil {
+				decoded.fx = make(map[string][]FXInfo)
+			}
+			decoded.fx[guid] = entry.FX
+		}
+	}
+
+	*c = decoded
+	return nil
+}
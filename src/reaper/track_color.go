@@ -0,0 +1,164 @@
+package reaper
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../c -I${SRCDIR}/../../sdk
+#include "../c/bridge.h"
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// customColorFlag is set in native track colors that are in use; without it the track
+// has the theme's default color
+const customColorFlag = 0x1000000
+
+// Color is an RGB color
+type Color struct {
+	R, G, B uint8
+}
+
+// ParseColor parses a color written as "#RRGGBB"
+func ParseColor(s string) (Color, error) {
+	hex := strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(hex) != 6 {
+		return Color{}, fmt.Errorf("invalid color %q, expected #RRGGBB", s)
+	}
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return Color{}, fmt.Errorf("invalid color %q, expected #RRGGBB", s)
+	}
+	return Color{R: uint8(value >> 16), G: uint8(value >> 8), B: uint8(value)}, nil
+}
+
+// String returns the color as "#RRGGBB"
+func (c Color) String() string {
+	return fmt.Sprintf("#%02X%02X%02X", c.R, c.G, c.B)
+}
+
+// GetTrackColor returns a track's custom color. ok is false if it has the theme's
+// default color.
+func GetTrackColor(track unsafe.Pointer) (color Color, ok bool, err error) {
+	if track == nil {
+		return Color{}, false, fmt.Errorf("invalid track")
+	}
+
+	getColor, err := projectFunc("GetTrackColor")
+	if err != nil {
+		return Color{}, false, err
+	}
+
+	native := int(C.plugin_bridge_call_get_track_color(getColor, track))
+	if native&customColorFlag == 0 {
+		return Color{}, false, nil
+	}
+
+	fromNative, err := projectFunc("ColorFromNative")
+	if err != nil {
+		return Color{}, false, err
+	}
+
+	var r, g, b C.int
+	C.plugin_bridge_call_color_from_native(fromNative, C.int(native&^customColorFlag), &r, &g, &b)
+	return Color{R: uint8(r), G: uint8(g), B: uint8(b)}, true, nil
+}
+
+// SetTrackColor gives a track a custom color. Call it inside an undo block.
+func SetTrackColor(track unsafe.Pointer, color Color) error {
+	if track == nil {
+		return fmt.Errorf("invalid track")
+	}
+
+	toNative, err := projectFunc("ColorToNative")
+	if err != nil {
+		return err
+	}
+	setColor, err := projectFunc("SetTrackColor")
+	if err != nil {
+		return err
+	}
+
+	native := C.plugin_bridge_call_color_to_native(toNative, C.int(color.R), C.int(color.G), C.int(color.B))
+	C.plugin_bridge_call_set_track_color(setColor, track, native)
+	return nil
+}
+
+// ClearTrackColor gives a track the theme's default color again. Call it inside an undo
+// block.
+func ClearTrackColor(track unsafe.Pointer) error {
+	if track == nil {
+		return fmt.Errorf("invalid track")
+	}
+
+	funcPtr, err := projectFunc("SetMediaTrackInfo_Value")
+	if err != nil {
+		return err
+	}
+
+	cParam := C.CString("I_CUSTOMCOLOR")
+	defer C.free(unsafe.Pointer(cParam))
+
+	if !C.plugin_bridge_call_set_media_track_info_value(funcPtr, track, cParam, 0) {
+		return fmt.Errorf("failed to clear track color")
+	}
+	return nil
+}
+
+// GetThemeColor returns a color of the current REAPER theme by its key in the theme
+// file, e.g. "col_main_bg2" for the main window background. ok is false if the theme
+// has no such color.
+func GetThemeColor(key string) (color Color, ok bool, err error) {
+	getThemeColor, err := projectFunc("GetThemeColor")
+	if err != nil {
+		return Color{}, false, err
+	}
+
+	cKey := C.CString(key)
+	defer C.free(unsafe.Pointer(cKey))
+
+	native := int(C.plugin_bridge_call_get_theme_color(getThemeColor, cKey, 0))
+	if native < 0 {
+		return Color{}, false, nil
+	}
+
+	fromNative, err := projectFunc("ColorFromNative")
+	if err != nil {
+		return Color{}, false, err
+	}
+
+	var r, g, b C.int
+	C.plugin_bridge_call_color_from_native(fromNative, C.int(native), &r, &g, &b)
+	return Color{R: uint8(r), G: uint8(g), B: uint8(b)}, true, nil
+}
+
+// nativeColor converts a color to REAPER's native form, with the custom color flag set as
+// markers and items expect
+func nativeColor(color Color) (int, error) {
+	toNative, err := projectFunc("ColorToNative")
+	if err != nil {
+		return 0, err
+	}
+	native := C.plugin_bridge_call_color_to_native(toNative, C.int(color.R), C.int(color.G), C.int(color.B))
+	return int(native) | customColorFlag, nil
+}
+
+// colorFromNative converts a native color with the custom color flag. ok is false if the
+// flag isn't set, i.e. the default color is used.
+func colorFromNative(native int) (color Color, ok bool, err error) {
+	if native&customColorFlag == 0 {
+		return Color{}, false, nil
+	}
+
+	fromNative, err := projectFunc("ColorFromNative")
+	if err != nil {
+		return Color{}, false, err
+	}
+
+	var r, g, b C.int
+	C.plugin_bridge_call_color_from_native(fromNative, C.int(native&^customColorFlag), &r, &g, &b)
+	return Color{R: uint8(r), G: uint8(g), B: uint8(b)}, true, nil
+}
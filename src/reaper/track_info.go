@@ -14,9 +14,33 @@ import (
 // TrackInfo represents information about a REAPER track
 type TrackInfo struct {
 	MediaTrack unsafe.Pointer
-	Index      int
+	Index      int // 1-based track number, -1 for the master track
 	Name       string
+	GUID       string
 	NumFX      int
+
+	Mute   bool
+	Solo   int // 0 when not soloed; 1 soloed, 2 soloed in place, higher for safe modes
+	RecArm bool
+	Volume float64 // As a gain, 1 for 0dB
+	Pan    float64 // -1 (left) to 1 (right)
+
+	Color    Color // The custom color, if HasColor
+	HasColor bool  // False when the track has the theme's default color
+
+	FolderDepth int            // 1 if the track starts a folder, negative if it closes folders, else 0
+	Depth       int            // Nesting level, 0 for top-level tracks
+	Parent      unsafe.Pointer // Folder track the track is in, or nil
+}
+
+// IsSoloed reports whether the track is soloed in any mode
+func (t *TrackInfo) IsSoloed() bool {
+	return t.Solo != 0
+}
+
+// IsFolder reports whether the track starts a folder
+func (t *TrackInfo) IsFolder() bool {
+	return t.FolderDepth == 1
 }
 
 // GetSelectedTrackInfo gets detailed information about the selected track
@@ -27,41 +51,51 @@ func GetSelectedTrackInfo() (*TrackInfo, error) {
 		return nil, err
 	}
 
-	// Create track info object
-	trackInfo := &TrackInfo{
-		MediaTrack: track,
-	}
-
-	// Get track index
-	cFuncName := C.CString("GetMediaTrackInfo_Value")
-	defer C.free(unsafe.Pointer(cFuncName))
+	return GetTrackInfo(track)
+}
 
-	getTrackInfoPtr := C.plugin_bridge_call_get_func(C.plugin_bridge_get_get_func(), cFuncName)
-	if getTrackInfoPtr == nil {
-		return nil, fmt.Errorf("could not get GetMediaTrackInfo_Value function pointer")
+// GetTrackInfo gets detailed information about a track in a single bridge call
+func GetTrackInfo(track unsafe.Pointer) (*TrackInfo, error) {
+	if err := batchReady("GetTrackInfo"); err != nil {
+		return nil, err
+	}
+	if track == nil {
+		return nil, fmt.Errorf("invalid track")
 	}
 
-	// Get track index (IP_TRACKNUMBER)
-	cParam := C.CString("IP_TRACKNUMBER")
-	defer C.free(unsafe.Pointer(cParam))
-
-	// Define function pointer type for GetMediaTrackInfo_Value
-	getTrackInfoFunc := unsafe.Pointer(getTrackInfoPtr)
-	index := C.plugin_bridge_call_track_get_info_value(getTrackInfoFunc, track, cParam)
-	trackInfo.Index = int(index)
+	var info C.track_info_t
+	if !C.plugin_bridge_batch_get_track_info(track, &info) {
+		return nil, fmt.Errorf("failed to get track info")
+	}
 
-	// Get track name
-	trackName, err := GetTrackName(track)
-	if err == nil {
-		trackInfo.Name = trackName
-	} else {
-		trackInfo.Name = fmt.Sprintf("Track %d", trackInfo.Index)
+	trackInfo := &TrackInfo{
+		MediaTrack:  track,
+		Index:       int(info.number),
+		Name:        C.GoString(&info.name[0]),
+		GUID:        C.GoString(&info.guid[0]),
+		NumFX:       int(info.fx_count),
+		Mute:        bool(info.mute),
+		Solo:        int(info.solo),
+		RecArm:      bool(info.rec_arm),
+		Volume:      float64(info.volume),
+		Pan:         float64(info.pan),
+		HasColor:    int(info.color)&customColorFlag != 0,
+		FolderDepth: int(info.folder_depth),
+		Depth:       int(info.depth),
+		Parent:      info.parent,
+	}
+	if trackInfo.HasColor {
+		trackInfo.Color = Color{R: uint8(info.r), G: uint8(info.g), B: uint8(info.b)}
 	}
 
-	// Get FX count
-	fxCount, err := GetTrackFXCount(track)
-	if err == nil {
-		trackInfo.NumFX = fxCount
+	// The name field is fixed size; read long names again on their own
+	if isTruncated(trackInfo.Name, len(info.name)) {
+		if name, err := GetTrackName(track); err == nil {
+			trackInfo.Name = name
+		}
+	}
+	if trackInfo.Name == "" {
+		trackInfo.Name = fmt.Sprintf("Track %d", trackInfo.Index)
 	}
 
 	return trackInfo, nil
@@ -69,56 +103,73 @@ func GetSelectedTrackInfo() (*TrackInfo, error) {
 
 // GetTrackName gets the name of a track
 func GetTrackName(track unsafe.Pointer) (string, error) {
-	if !initialized {
-		return "", fmt.Errorf("REAPER functions not initialized")
-	}
-
-	cFuncName := C.CString("GetTrackName")
-	defer C.free(unsafe.Pointer(cFuncName))
-
-	getTrackNamePtr := C.plugin_bridge_call_get_func(C.plugin_bridge_get_get_func(), cFuncName)
-	if getTrackNamePtr == nil {
-		return "", fmt.Errorf("could not get GetTrackName function pointer")
+	getTrackNamePtr, err := projectFunc("GetTrackName")
+	if err != nil {
+		return "", err
 	}
 
-	// Allocate buffers for track name and flags
-	nameBuf := (*C.char)(C.malloc(C.size_t(256)))
-	defer C.free(unsafe.Pointer(nameBuf))
-
-	flagsBuf := (*C.int)(C.malloc(C.size_t(unsafe.Sizeof(C.int(0)))))
-	defer C.free(unsafe.Pointer(flagsBuf))
-
 	// Call GetTrackName
-	result := C.plugin_bridge_call_get_track_name(getTrackNamePtr, track, nameBuf, 256, flagsBuf)
-	if !bool(result) {
+	var flags C.int
+	name, ok := readString(GetStringBufferSize(), func(buf *cBuffer) bool {
+		return bool(C.plugin_bridge_call_get_track_name(getTrackNamePtr, track, buf.ptr, buf.cSize(), &flags))
+	})
+	if !ok {
 		return "", fmt.Errorf("failed to get track name")
 	}
 
-	return C.GoString(nameBuf), nil
+	return name, nil
 }
 
-// GetTrackFXList gets a list of all FX on a track
-func GetTrackFXList(track unsafe.Pointer) ([]FXInfo, error) {
-	// Get FX count
-	fxCount, err := GetTrackFXCount(track)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get FX count: %v", err)
+// GetTrackFXSummaries returns the name, bypass/offline state and plugin identity of
+// every FX on a track, without parameters, in a single bridge call. Use it for FX lists
+// and pickers; load parameters for the chosen FX with GetFXParameters.
+func GetTrackFXSummaries(track unsafe.Pointer) ([]FXInfo, error) {
+	if err := batchReady("GetTrackFXSummaries"); err != nil {
+		return nil, err
+	}
+	if track == nil {
+		return nil, fmt.Errorf("invalid track")
 	}
 
-	// Gather info for all FX
-	result := make([]FXInfo, 0, fxCount)
-	for i := 0; i < fxCount; i++ {
-		// Get just FX name for the list (optimization)
-		fxName, err := GetTrackFXName(track, i)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get FX name: %v", err)
+	var fxCount C.int
+	summaryData := C.plugin_bridge_batch_get_fx_summaries(track, &fxCount)
+	if summaryData == nil {
+		if fxCount == 0 {
+			return []FXInfo{}, nil
 		}
+		return nil, fmt.Errorf("failed to get FX summaries")
+	}
+	defer C.free(unsafe.Pointer(summaryData))
 
-		// Create minimal FX info (don't load all parameters yet for performance)
+	summaries := cArray(summaryData, int(fxCount))
+	result := make([]FXInfo, 0, len(summaries))
+	for _, summary := range summaries {
 		fxInfo := FXInfo{
-			Index: i,
-			Name:  fxName,
+			Index:   int(summary.index),
+			Name:    C.GoString(&summary.name[0]),
+			Enabled: bool(summary.enabled),
+			Offline: bool(summary.offline),
+		}
+		pluginID := C.GoString(&summary.ident[0])
+		originalName := C.GoString(&summary.original_name[0])
+
+		// The summary fields are fixed size; read long values again on their own
+		if isTruncated(fxInfo.Name, len(summary.name)) {
+			if name, err := GetTrackFXName(track, fxInfo.Index); err == nil {
+				fxInfo.Name = name
+			}
+		}
+		if isTruncated(pluginID, len(summary.ident)) {
+			if value, err := GetTrackFXNamedConfigParam(track, fxInfo.Index, FXConfigIdent); err == nil {
+				pluginID = value
+			}
+		}
+		if isTruncated(originalName, len(summary.original_name)) {
+			if value, err := GetTrackFXNamedConfigParam(track, fxInfo.Index, FXConfigName); err == nil {
+				originalName = value
+			}
 		}
+		setFXIdentity(&fxInfo, C.GoString(&summary._type[0]), pluginID, originalName)
 
 		result = append(result, fxInfo)
 	}
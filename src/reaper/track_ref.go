@@ -0,0 +1,245 @@
+package reaper
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../c -I${SRCDIR}/../../sdk
+#include "../c/bridge.h"
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"fmt"
+	"strings"
+	"unsafe"
+)
+
+// trackInfoStringSize is the buffer size for GetSetMediaTrackInfo_String, which doesn't
+// take a size and expects a large buffer
+const trackInfoStringSize = 4096
+
+// getTrackInfoString reads a string property of a track, e.g. "GUID" or "P_NAME"
+func getTrackInfoString(track unsafe.Pointer, param string) (string, error) {
+	if track == nil {
+		return "", fmt.Errorf("invalid track")
+	}
+
+	funcPtr, err := projectFunc("GetSetMediaTrackInfo_String")
+	if err != nil {
+		return "", err
+	}
+
+	cParam := C.CString(param)
+	defer C.free(unsafe.Pointer(cParam))
+
+	buf := getBuffer(trackInfoStringSize)
+	defer buf.release()
+
+	if !C.plugin_bridge_call_get_set_media_track_info_string(funcPtr, track, cParam, buf.ptr, buf.cSize(), C.bool(false)) {
+		return "", fmt.Errorf("failed to get track %s", param)
+	}
+	return buf.String(), nil
+}
+
+// GetTrackGUID returns a track's GUID, e.g. "{2B6E8C7A-...}". Unlike the track pointer,
+// it stays the same across undo, reordering and reopening the project.
+func GetTrackGUID(track unsafe.Pointer) (string, error) {
+	guid, err := getTrackInfoString(track, "GUID")
+	if err != nil {
+		return "", err
+	}
+	if guid == "" {
+		return "", fmt.Errorf("track has no GUID")
+	}
+	return guid, nil
+}
+
+// GuidToTrack returns the track with the given GUID in the current project, including
+// the master track
+func GuidToTrack(guid string) (unsafe.Pointer, error) {
+	if guid == "" {
+		return nil, fmt.Errorf("empty track GUID")
+	}
+
+	if master, err := GetMasterTrack(); err == nil {
+		if masterGUID, err := GetTrackGUID(master); err == nil && strings.EqualFold(masterGUID, guid) {
+			return master, nil
+		}
+	}
+
+	count, err := CountTracks()
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < count; i++ {
+		track, err := GetTrack(i)
+		if err != nil {
+			continue
+		}
+		if trackGUID, err := GetTrackGUID(track); err == nil && strings.EqualFold(trackGUID, guid) {
+			return track, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no track with GUID %s", guid)
+}
+
+// TrackRef refers to a track by its GUID. Hold a TrackRef instead of a track pointer
+// across actions, timer ticks or background work: the pointer dangles once the track is
+// deleted, and undoing the delete brings the track back under a new pointer.
+type TrackRef struct {
+	GUID  string
+	track unsafe.Pointer // Last resolved pointer, checked before it is reused
+}
+
+// NewTrackRef returns a reference to a track
+func NewTrackRef(track unsafe.Pointer) (TrackRef, error) {
+	guid, err := GetTrackGUID(track)
+	if err != nil {
+		return TrackRef{}, err
+	}
+	return TrackRef{GUID: guid, track: track}, nil
+}
+
+// IsZero reports whether the reference doesn't refer to any track
+func (r TrackRef) IsZero() bool {
+	return r.GUID == ""
+}
+
+// Resolve returns the current pointer of the referenced track, or an error if the track
+// is no longer in the project. Call it on the main thread right before using the track.
+func (r *TrackRef) Resolve() (unsafe.Pointer, error) {
+	if r.IsZero() {
+		return nil, fmt.Errorf("no track")
+	}
+
+	// The last pointer is usually still right; check it is the same track, since a
+	// deleted track's memory can be reused for another
+	if r.track != nil && IsTrackValid(r.track) {
+		if guid, err := GetTrackGUID(r.track); err == nil && strings.EqualFold(guid, r.GUID) {
+			return r.track, nil
+		}
+	}
+
+	track, err := GuidToTrack(r.GUID)
+	if err != nil {
+		r.track = nil
+		return nil, err
+	}
+	r.track = track
+	return track, nil
+}
+
+// TrackCollection is an ordered set of tracks held by GUID, for working on several
+// tracks over time
+type TrackCollection struct {
+	refs []TrackRef
+	fx   map[string][]FXInfo // FX and parameters loaded by LoadFX, by track GUID
+}
+
+// NewTrackCollection returns a collection of the given tracks
+func NewTrackCollection(tracks ...unsafe.Pointer) (*TrackCollection, error) {
+	c := &TrackCollection{}
+	for _, track := range tracks {
+		if err := c.Add(track); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// Add adds a track to the collection, unless it is already in it
+func (c *TrackCollection) Add(track unsafe.Pointer) error {
+	ref, err := NewTrackRef(track)
+	if err != nil {
+		return err
+	}
+	for _, existing := range c.refs {
+		if strings.EqualFold(existing.GUID, ref.GUID) {
+			return nil
+		}
+	}
+	c.refs = append(c.refs, ref)
+	return nil
+}
+
+// Len returns the number of tracks in the collection
+func (c *TrackCollection) Len() int {
+	return len(c.refs)
+}
+
+// GUIDs returns the GUIDs of the tracks in the collection, in order
+func (c *TrackCollection) GUIDs() []string {
+	guids := make([]string, len(c.refs))
+	for i, ref := range c.refs {
+		guids[i] = ref.GUID
+	}
+	return guids
+}
+
+// Resolve returns the current pointers of the tracks still in the project, in order,
+// and the GUIDs of those that are gone
+func (c *TrackCollection) Resolve() (tracks []unsafe.Pointer, missing []string) {
+	for i := range c.refs {
+		track, err := c.refs[i].Resolve()
+		if err != nil {
+			missing = append(missing, c.refs[i].GUID)
+			continue
+		}
+		tracks = append(tracks, track)
+	}
+	return tracks, missing
+}
+
+// ForEach resolves every track first, then calls fn for each. Nothing is run if any
+// track is gone, so a batch operation is never applied to part of the collection.
+func (c *TrackCollection) ForEach(fn func(track unsafe.Pointer) error) error {
+	tracks, missing := c.Resolve()
+	if len(missing) > 0 {
+		return fmt.Errorf("%d of %d tracks are no longer in the project", len(missing), len(c.refs))
+	}
+
+	for _, track := range tracks {
+		if err := fn(track); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadFX reads the FX chain and parameters of every track in the collection. Keep them
+// current with RefreshValues, which only reads the values again.
+func (c *TrackCollection) LoadFX() error {
+	loaded := make(map[string][]FXInfo, len(c.refs))
+	for i := range c.refs {
+		track, err := c.refs[i].Resolve()
+		if err != nil {
+			return err
+		}
+
+		summaries, err := GetTrackFXSummaries(track)
+		if err != nil {
+			return err
+		}
+		fxList := make([]FXInfo, 0, len(summaries))
+		for _, summary := range summaries {
+			fx, err := GetFXParameters(track, summary.Index)
+			if err != nil {
+				return fmt.Errorf("failed to load FX %d: %v", summary.Index, err)
+			}
+			fxList = append(fxList, fx)
+		}
+		loaded[c.refs[i].GUID] = fxList
+	}
+
+	c.fx = loaded
+	return nil
+}
+
+// FX returns the FX loaded for a track in the collection, or nil if none were loaded
+func (c *TrackCollection) FX(guid string) []FXInfo {
+	for _, ref := range c.refs {
+		if strings.EqualFold(ref.GUID, guid) {
+			return c.fx[ref.GUID]
+		}
+	}
+	return nil
+}
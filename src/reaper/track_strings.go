@@ -0,0 +1,140 @@
+package reaper
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../c -I${SRCDIR}/../../sdk
+#include "../c/bridge.h"
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"fmt"
+	"strings"
+	"unsafe"
+)
+
+// Track string properties, from GetSetMediaTrackInfo_String
+const (
+	trackPropertyName      = "P_NAME"       // Track name, empty for an unnamed track
+	trackPropertyIcon      = "P_ICON"       // Icon image path, relative to the track icon folder or absolute
+	trackPropertyTCPLayout = "P_TCP_LAYOUT" // Track control panel layout name, empty for the theme default
+	trackPropertyMCPLayout = "P_MCP_LAYOUT" // Mixer control panel layout name, empty for the theme default
+	trackPropertyExtPrefix = "P_EXT:"       // Extension data stored in the project, by key
+)
+
+// setTrackInfoString sets a string property of a track. Call it inside an undo block.
+func setTrackInfoString(track unsafe.Pointer, param string, value string) error {
+	if track == nil {
+		return fmt.Errorf("invalid track")
+	}
+	if len(value) >= trackInfoStringSize {
+		return fmt.Errorf("track %s is too long (%d bytes, at most %d)", param, len(value), trackInfoStringSize-1)
+	}
+
+	funcPtr, err := projectFunc("GetSetMediaTrackInfo_String")
+	if err != nil {
+		return err
+	}
+
+	cParam := C.CString(param)
+	defer C.free(unsafe.Pointer(cParam))
+	cValue := C.CString(value)
+	defer C.free(unsafe.Pointer(cValue))
+
+	if !C.plugin_bridge_call_get_set_media_track_info_string(funcPtr, track, cParam, cValue, C.int(len(value)+1), C.bool(true)) {
+		return fmt.Errorf("failed to set track %s", param)
+	}
+	return nil
+}
+
+// adjustTrackWindows redraws the track panels, and the mixer unless minor is set
+func adjustTrackWindows(minor bool) error {
+	funcPtr, err := projectFunc("TrackList_AdjustWindows")
+	if err != nil {
+		return err
+	}
+
+	C.plugin_bridge_call_track_list_adjust_windows(funcPtr, C.bool(minor))
+	return nil
+}
+
+// GetTrackCustomName returns the name the user gave a track, or "" if it has none.
+// GetTrackName returns "Track N" for those instead.
+func GetTrackCustomName(track unsafe.Pointer) (string, error) {
+	return getTrackInfoString(track, trackPropertyName)
+}
+
+// SetTrackName renames a track. An empty name clears it, so REAPER shows "Track N".
+func SetTrackName(track unsafe.Pointer, name string) error {
+	return setTrackInfoString(track, trackPropertyName, name)
+}
+
+// GetTrackIcon returns the path of a track's icon, or "" if it has none
+func GetTrackIcon(track unsafe.Pointer) (string, error) {
+	return getTrackInfoString(track, trackPropertyIcon)
+}
+
+// SetTrackIcon sets a track's icon to an image path, either relative to REAPER's track
+// icon folder (e.g. "guitar.png") or absolute. An empty path removes the icon.
+func SetTrackIcon(track unsafe.Pointer, path string) error {
+	return setTrackInfoString(track, trackPropertyIcon, path)
+}
+
+// TrackLayouts are the theme layouts of a track's control panel and mixer strip. An
+// empty name means the theme's default layout.
+type TrackLayouts struct {
+	TCP string
+	MCP string
+}
+
+// GetTrackLayouts returns a track's panel layouts
+func GetTrackLayouts(track unsafe.Pointer) (TrackLayouts, error) {
+	tcp, err := getTrackInfoString(track, trackPropertyTCPLayout)
+	if err != nil {
+		return TrackLayouts{}, err
+	}
+	mcp, err := getTrackInfoString(track, trackPropertyMCPLayout)
+	if err != nil {
+		return TrackLayouts{}, err
+	}
+	return TrackLayouts{TCP: tcp, MCP: mcp}, nil
+}
+
+// SetTrackLayouts switches a track's panel layouts, using names from the current theme
+// such as "A" or "B", and redraws the track list and mixer. REAPER accepts any name;
+// unknown layouts display as the default.
+func SetTrackLayouts(track unsafe.Pointer, layouts TrackLayouts) error {
+	if err := setTrackInfoString(track, trackPropertyTCPLayout, layouts.TCP); err != nil {
+		return err
+	}
+	if err := setTrackInfoString(track, trackPropertyMCPLayout, layouts.MCP); err != nil {
+		return err
+	}
+	return adjustTrackWindows(false)
+}
+
+// GetTrackExtData returns a value the extension stored on a track with SetTrackExtData,
+// or "" if there is none
+func GetTrackExtData(track unsafe.Pointer, key string) (string, error) {
+	if err := checkTrackExtKey(key); err != nil {
+		return "", err
+	}
+	return getTrackInfoString(track, trackPropertyExtPrefix+key)
+}
+
+// SetTrackExtData stores a value on a track. It is saved with the project and follows
+// the track through undo, e.g. to tag tracks the assistant changed. Keys should start
+// with the extension's prefix, like "GoReaper_".
+func SetTrackExtData(track unsafe.Pointer, key string, value string) error {
+	if err := checkTrackExtKey(key); err != nil {
+		return err
+	}
+	return setTrackInfoString(track, trackPropertyExtPrefix+key, value)
+}
+
+// checkTrackExtKey rejects keys REAPER couldn't store
+func checkTrackExtKey(key string) error {
+	if key == "" || strings.ContainsAny(key, " \t\r\n") {
+		return fmt.Errorf("invalid track data key %q", key)
+	}
+	return nil
+}
@@ -0,0 +1,276 @@
+package reaper
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unsafe"
+)
+
+// Track templates (.RTrackTemplate files) hold the state chunks of one or more tracks,
+// as REAPER's "Save tracks as track template" writes them. Inserting one makes a new
+// track for each chunk; overrides then set chosen parameters of the new tracks' FX, so
+// one template serves several setups, e.g. a vocal chain tuned for each singer.
+
+// TrackTemplateExtension is the extension REAPER uses for track template files
+const TrackTemplateExtension = "RTrackTemplate"
+
+// templateIDKeys are chunk lines holding GUIDs. They are dropped when inserting, so
+// REAPER gives the new track and its FX their own instead of copies of the template's.
+var templateIDKeys = map[string]bool{
+	"TRACKID": true,
+	"FXID":    true,
+}
+
+// ParamOverride sets a parameter of an FX on a track made from a template. FX and
+// parameters are found by name, ignoring case.
+type ParamOverride struct {
+	Track      int     `json:"track,omitempty"`      // Track in the template, from 1; 0 for the first
+	FX         string  `json:"fx"`                   // Part of the FX name, e.g. "ReaComp"
+	Occurrence int     `json:"occurrence,omitempty"` // Which FX matching FX, from 1; 0 for the first
+	Param      string  `json:"param"`                // Parameter name; an exact match wins over one containing it
+	Value      float64 `json:"value"`                // Normalized, 0 to 1
+}
+
+// String describes the override for messages, e.g. "ReaComp: Thresh = 0.25"
+func (o ParamOverride) String() string {
+	fx := o.FX
+	if o.Occurrence > 1 {
+		fx = fmt.Sprintf("%s #%d", fx, o.Occurrence)
+	}
+	return fmt.Sprintf("%s: %s = %g", fx, o.Param, o.Value)
+}
+
+// ReadTrackTemplate reads a track template file and returns its tracks' chunks
+func ReadTrackTemplate(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseTrackTemplate(string(data))
+}
+
+// ParseTrackTemplate splits a track template into the chunks of its tracks, in order.
+// Folder templates hold their child tracks one after another, not nested.
+func ParseTrackTemplate(data string) ([]string, error) {
+	var chunks []string
+	var current []string
+	depth := 0
+
+	for _, line := range chunkLines(data) {
+		trimmed := strings.TrimSpace(line)
+		if depth == 0 {
+			if !strings.HasPrefix(trimmed, "<TRACK") {
+				continue
+			}
+			current = nil
+		}
+
+		current = append(current, line)
+		switch {
+		case strings.HasPrefix(trimmed, "<"):
+			depth++
+		case trimmed == ">":
+			depth--
+			if depth == 0 {
+				chunks = append(chunks, strings.Join(current, "\n")+"\n")
+			}
+		}
+	}
+
+	if depth != 0 {
+		return nil, fmt.Errorf("the template ends inside a track")
+	}
+	if len(chunks) == 0 {
+		return nil, fmt.Errorf("the template has no tracks")
+	}
+	return chunks, nil
+}
+
+// withoutTemplateIDs drops the GUID lines of a chunk
+func withoutTemplateIDs(chunk string) string {
+	lines := chunkLines(chunk)
+	kept := lines[:0]
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) > 0 && templateIDKeys[fields[0]] {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return strings.Join(kept, "\n") + "\n"
+}
+
+// InsertTrackTemplate inserts a track for each of a template's chunks, starting at index
+// (0-based; past the end appends), and returns them in order. Call it inside an undo
+// block. Tracks inserted before an error are left in place for the undo block to hold.
+func InsertTrackTemplate(index int, chunks []string) ([]unsafe.Pointer, error) {
+	count, err := CountTracks()
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 || index > count {
+		index = count
+	}
+
+	tracks := make([]unsafe.Pointer, 0, len(chunks))
+	for i, chunk := range chunks {
+		track, err := InsertTrack(index + i)
+		if err != nil {
+			return tracks, err
+		}
+		tracks = append(tracks, track)
+
+		if err := SetTrackStateChunk(track, withoutTemplateIDs(chunk)); err != nil {
+			return tracks, fmt.Errorf("failed to set up template track %d: %v", i+1, err)
+		}
+	}
+	return tracks, nil
+}
+
+// ApplyParamOverrides sets the overridden parameters of tracks made from a template, in
+// one batch. Overrides whose track, FX or parameter can't be found are described in
+// skipped; the rest are still applied. Call it inside an undo block.
+func ApplyParamOverrides(tracks []unsafe.Pointer, overrides []ParamOverride) (applied int, skipped []string, err error) {
+	var changes []ParameterChange
+	var described []ParamOverride
+	fxNames := make(map[unsafe.Pointer][]string)
+	params := make(map[[2]int][]FXParameter)
+
+	for _, override := range overrides {
+		trackIndex := max(override.Track, 1) - 1
+		if trackIndex >= len(tracks) {
+			skipped = append(skipped, fmt.Sprintf("%s: the template has no track %d", override, trackIndex+1))
+			continue
+		}
+		track := tracks[trackIndex]
+
+		names, ok := fxNames[track]
+		if !ok {
+			names, err = trackFXNames(track)
+			if err != nil {
+				return 0, nil, err
+			}
+			fxNames[track] = names
+		}
+		fxIndex, ok := findFXByName(names, override.FX, max(override.Occurrence, 1))
+		if !ok {
+			skipped = append(skipped, fmt.Sprintf("%s: no such FX", override))
+			continue
+		}
+
+		key := [2]int{trackIndex, fxIndex}
+		parameters, ok := params[key]
+		if !ok {
+			parameters, err = BatchGetFXParameters(track, fxIndex)
+			if err != nil {
+				return 0, nil, err
+			}
+			params[key] = parameters
+		}
+		paramIndex, ok := findParamByName(parameters, override.Param)
+		if !ok {
+			skipped = append(skipped, fmt.Sprintf("%s: no such parameter", override))
+			continue
+		}
+
+		changes = append(changes, ParameterChange{Track: track, FXIndex: fxIndex, ParamIndex: paramIndex, Value: override.Value})
+		described = append(described, override)
+	}
+
+	failed, err := BatchSetParameters(changes)
+	if err != nil {
+		return 0, nil, err
+	}
+	for _, change := range failed {
+		for i, candidate := range changes {
+			if candidate == change {
+				skipped = append(skipped, fmt.Sprintf("%s: REAPER refused the value", described[i]))
+				break
+			}
+		}
+	}
+	return len(changes) - len(failed), skipped, nil
+}
+
+// ApplyFXValues sets the parameters of a track's FX from a snapshot's values, matching
+// each snapshot FX to the track's FX of the same name in order. FX that aren't found or
+// whose parameter count differs are described in skipped. Call it inside an undo block.
+func ApplyFXValues(track unsafe.Pointer, fx []FXValues) (applied int, skipped []string, err error) {
+	names, err := trackFXNames(track)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	seen := make(map[string]int)
+	for _, values := range fx {
+		seen[values.Name]++
+		fxIndex, ok := findFXByName(names, values.Name, seen[values.Name])
+		if !ok {
+			skipped = append(skipped, fmt.Sprintf("%s: not on the track", values.Name))
+			continue
+		}
+		count, err := GetTrackFXParamCount(track, fxIndex)
+		if err != nil || count != len(values.Values) {
+			skipped = append(skipped, fmt.Sprintf("%s: the parameters differ", values.Name))
+			continue
+		}
+		if err := setFXValues(track, fxIndex, values.Values); err != nil {
+			skipped = append(skipped, fmt.Sprintf("%s: %v", values.Name, err))
+			continue
+		}
+		applied++
+	}
+	return applied, skipped, nil
+}
+
+// trackFXNames returns the names of a track's FX, by index
+func trackFXNames(track unsafe.Pointer) ([]string, error) {
+	count, err := GetTrackFXCount(track)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, count)
+	for i := range names {
+		names[i], _ = GetTrackFXName(track, i)
+	}
+	return names, nil
+}
+
+// findFXByName returns the index of the occurrence-th FX whose name contains name,
+// ignoring case; an exact name counts as containing itself
+func findFXByName(names []string, name string, occurrence int) (int, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return 0, false
+	}
+	for i, fxName := range names {
+		if strings.Contains(strings.ToLower(fxName), name) {
+			occurrence--
+			if occurrence == 0 {
+				return i, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// findParamByName returns the index of the parameter named name, ignoring case, or else
+// of the first whose name contains it
+func findParamByName(parameters []FXParameter, name string) (int, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return 0, false
+	}
+	contains := -1
+	for _, param := range parameters {
+		paramName := strings.ToLower(strings.TrimSpace(param.Name))
+		if paramName == name {
+			return param.Index, true
+		}
+		if contains < 0 && strings.Contains(paramName, name) {
+			contains = param.Index
+		}
+	}
+	return contains, contains >= 0
+}
@@ -0,0 +1,120 @@
+package reaper
+
+import (
+	"strings"
+	"unsafe"
+)
+
+// TrackPathSeparator joins track names in TrackNode.PathString
+const TrackPathSeparator = " > "
+
+// TrackNode is a track in the project's folder hierarchy
+type TrackNode struct {
+	*TrackInfo
+	Parent   *TrackNode   // Folder the track is in, or nil for top-level tracks
+	Children []*TrackNode // Tracks in the folder, in project order; empty unless IsFolder
+}
+
+// GetTrackTree returns the current project's tracks as a tree built from their folder
+// depths, as the top-level tracks in project order. The master track is not included.
+func GetTrackTree() ([]*TrackNode, error) {
+	count, err := CountTracks()
+	if err != nil {
+		return nil, err
+	}
+
+	var roots []*TrackNode
+	var open []*TrackNode // Folders the next track is in, innermost last
+	for index := 0; index < count; index++ {
+		track, err := GetTrack(index)
+		if err != nil {
+			return nil, err
+		}
+		info, err := GetTrackInfo(track)
+		if err != nil {
+			return nil, err
+		}
+
+		node := &TrackNode{TrackInfo: info}
+		if len(open) > 0 {
+			node.Parent = open[len(open)-1]
+			node.Parent.Children = append(node.Parent.Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+
+		switch {
+		case info.FolderDepth > 0:
+			open = append(open, node)
+		case info.FolderDepth < 0:
+			// The last track in one or more folders closes them
+			closed := -info.FolderDepth
+			if closed > len(open) {
+				closed = len(open)
+			}
+			open = open[:len(open)-closed]
+		}
+	}
+
+	return roots, nil
+}
+
+// Path returns the names of the folders the track is in, outermost first, followed by
+// its own name
+func (n *TrackNode) Path() []string {
+	var path []string
+	for node := n; node != nil; node = node.Parent {
+		path = append([]string{node.Name}, path...)
+	}
+	return path
+}
+
+// PathString returns the track's path for display, e.g. "Bus > Drums > Kick"
+func (n *TrackNode) PathString() string {
+	return strings.Join(n.Path(), TrackPathSeparator)
+}
+
+// Walk calls fn for the track and then every track under it, in project order
+func (n *TrackNode) Walk(fn func(*TrackNode)) {
+	fn(n)
+	for _, child := range n.Children {
+		child.Walk(fn)
+	}
+}
+
+// Tracks returns the track and every track under it, in project order, e.g. to work on
+// all FX in a folder
+func (n *TrackNode) Tracks() []unsafe.Pointer {
+	var tracks []unsafe.Pointer
+	n.Walk(func(node *TrackNode) {
+		tracks = append(tracks, node.MediaTrack)
+	})
+	return tracks
+}
+
+// FXCount returns the number of FX on the track and every track under it
+func (n *TrackNode) FXCount() int {
+	count := 0
+	n.Walk(func(node *TrackNode) {
+		count += node.NumFX
+	})
+	return count
+}
+
+// WalkTrackTree calls fn for every track in a tree, in project order
+func WalkTrackTree(roots []*TrackNode, fn func(*TrackNode)) {
+	for _, root := range roots {
+		root.Walk(fn)
+	}
+}
+
+// FindTrackNode returns the node of a track in a tree, or nil
+func FindTrackNode(roots []*TrackNode, track unsafe.Pointer) *TrackNode {
+	var found *TrackNode
+	WalkTrackTree(roots, func(node *TrackNode) {
+		if found == nil && node.MediaTrack == track {
+			found = node
+		}
+	})
+	return found
+}
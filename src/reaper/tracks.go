@@ -13,27 +13,145 @@ import (
 
 // GetSelectedTrack returns the first selected track in the current project
 func GetSelectedTrack() (unsafe.Pointer, error) {
-	if !initialized {
-		return nil, fmt.Errorf("REAPER functions not initialized")
+	trackFuncPtr, err := projectFunc("GetSelectedTrack")
+	if err != nil {
+		return nil, err
 	}
 
-	// Get the GetFunc pointer using our bridge function
-	getFuncPtr := C.plugin_bridge_get_get_func()
+	// Call GetSelectedTrack(0, 0) - first project, first selected track
+	track := C.plugin_bridge_call_get_selected_track(trackFuncPtr, 0, 0)
+	if track == nil {
+		return nil, fmt.Errorf("no track selected")
+	}
 
-	// Get the GetSetObjectState function pointer
-	cFuncName := C.CString("GetSelectedTrack")
-	defer C.free(unsafe.Pointer(cFuncName))
+	return track, nil
+}
 
-	trackFuncPtr := C.plugin_bridge_call_get_func(getFuncPtr, cFuncName)
-	if trackFuncPtr == nil {
-		return nil, fmt.Errorf("could not get GetSelectedTrack function pointer")
+// IsTrackValid reports whether a track pointer still refers to a track in the current project,
+// e.g. before reusing a pointer held across actions or timer ticks
+func IsTrackValid(track unsafe.Pointer) bool {
+	if track == nil {
+		return false
 	}
 
-	// Call GetSelectedTrack(0, 0) - first project, first selected track
-	track := C.plugin_bridge_call_get_selected_track(trackFuncPtr, 0, 0)
+	validateFuncPtr, err := projectFunc("ValidatePtr2")
+	if err != nil {
+		return false
+	}
+
+	cTypeName := C.CString("MediaTrack*")
+	defer C.free(unsafe.Pointer(cTypeName))
+
+	return bool(C.plugin_bridge_call_validate_ptr2(validateFuncPtr, nil, track, cTypeName))
+}
+
+// GetTrack returns the track at index (0-based, not counting the master) in the current project
+func GetTrack(index int) (unsafe.Pointer, error) {
+	funcPtr, err := projectFunc("GetTrack")
+	if err != nil {
+		return nil, err
+	}
+
+	track := C.plugin_bridge_call_get_track(funcPtr, nil, C.int(index))
 	if track == nil {
-		return nil, fmt.Errorf("no track selected")
+		return nil, fmt.Errorf("no track at index %d", index)
+	}
+
+	return track, nil
+}
+
+// GetMasterTrack returns the master track of the current project
+func GetMasterTrack() (unsafe.Pointer, error) {
+	funcPtr, err := projectFunc("GetMasterTrack")
+	if err != nil {
+		return nil, err
+	}
+
+	track := C.plugin_bridge_call_get_master_track(funcPtr, nil)
+	if track == nil {
+		return nil, fmt.Errorf("no master track")
+	}
+
+	return track, nil
+}
+
+// GetTrackUnderMouse returns the track whose panel or lane is under the mouse pointer,
+// e.g. the track a context menu was opened on
+func GetTrackUnderMouse() (unsafe.Pointer, error) {
+	mouseFuncPtr, err := projectFunc("GetMousePosition")
+	if err != nil {
+		return nil, err
+	}
+
+	pointFuncPtr, err := projectFunc("GetTrackFromPoint")
+	if err != nil {
+		return nil, err
+	}
+
+	var x, y C.int
+	C.plugin_bridge_call_get_mouse_position(mouseFuncPtr, &x, &y)
+
+	track := C.plugin_bridge_call_get_track_from_point(pointFuncPtr, x, y, nil)
+	if track == nil {
+		return nil, fmt.Errorf("no track under the mouse")
 	}
 
 	return track, nil
 }
+
+// CountSelectedTracks returns the number of selected tracks in the current project, not counting the master
+func CountSelectedTracks() (int, error) {
+	funcPtr, err := projectFunc("CountSelectedTracks2")
+	if err != nil {
+		return 0, err
+	}
+
+	return int(C.plugin_bridge_call_count_selected_tracks2(funcPtr, nil, C.bool(false))), nil
+}
+
+// GetSelectedTracks returns every selected track in the current project, in track order
+func GetSelectedTracks() ([]unsafe.Pointer, error) {
+	count, err := CountSelectedTracks()
+	if err != nil {
+		return nil, err
+	}
+
+	funcPtr, err := projectFunc("GetSelectedTrack")
+	if err != nil {
+		return nil, err
+	}
+
+	tracks := make([]unsafe.Pointer, 0, count)
+	for i := 0; i < count; i++ {
+		track := C.plugin_bridge_call_get_selected_track(funcPtr, 0, C.int(i))
+		if track == nil {
+			return nil, fmt.Errorf("no selected track at index %d", i)
+		}
+		tracks = append(tracks, track)
+	}
+	return tracks, nil
+}
+
+// SetTrackSelected selects or deselects a track, leaving other tracks' selection alone
+func SetTrackSelected(track unsafe.Pointer, selected bool) error {
+	if track == nil {
+		return fmt.Errorf("invalid track")
+	}
+
+	funcPtr, err := projectFunc("SetMediaTrackInfo_Value")
+	if err != nil {
+		return err
+	}
+
+	cParam := C.CString("I_SELECTED")
+	defer C.free(unsafe.Pointer(cParam))
+
+	value := 0.0
+	if selected {
+		value = 1
+	}
+	if !C.plugin_bridge_call_set_media_track_info_value(funcPtr, track, cParam, C.double(value)) {
+		return fmt.Errorf("failed to set track selection")
+	}
+	return nil
+}
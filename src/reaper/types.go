@@ -1,25 +1,94 @@
 package reaper
 
+import "unsafe"
+
 // FXParameter represents a single parameter of an FX
 type FXParameter struct {
 	Index          int     `json:"index"`
 	Name           string  `json:"name"`
-	Value          float64 `json:"value"`          // Normalized value (0.0-1.0)
-	FormattedValue string  `json:"formattedValue"` // Human-readable value
-	Min            float64 `json:"min"`            // Minimum value
-	Max            float64 `json:"max"`            // Maximum value
+	Value          float64 `json:"value"`           // Normalized value (0.0-1.0)
+	FormattedValue string  `json:"formattedValue"`  // Human-readable value
+	Min            float64 `json:"min"`             // Minimum value
+	Max            float64 `json:"max"`             // Maximum value
+	Ident          string  `json:"ident,omitempty"` // Built-in parameter identifier, e.g. ":wet"
 }
 
 // FXInfo represents an FX and its parameters
 type FXInfo struct {
 	Index      int           `json:"index"`
 	Name       string        `json:"name"`
+	Enabled    bool          `json:"enabled"`               // False when bypassed
+	Offline    bool          `json:"offline"`               // Offline FX are unloaded
+	Type       string        `json:"type,omitempty"`        // Plugin format, e.g. "VST3"
+	PluginName string        `json:"plugin_name,omitempty"` // Original plugin name, even if the instance was renamed
+	Vendor     string        `json:"vendor,omitempty"`
+	PluginID   string        `json:"plugin_id,omitempty"` // Plugin file and/or unique ID
 	Parameters []FXParameter `json:"parameters"`
 }
 
+// FXState represents the bypass and offline state of an FX
+type FXState struct {
+	Index   int  `json:"index"`
+	Enabled bool `json:"enabled"`
+	Offline bool `json:"offline"`
+}
+
 // ActionHandler defines a function type for handling actions
 type ActionHandler func()
 
+// ContextActionHandler defines a function type for handling actions that use the
+// context they were triggered in, e.g. a MIDI CC value
+type ContextActionHandler func(ctx ActionContext)
+
+// ActionContext describes how an action was triggered. The values come from REAPER's
+// hookcommand2; actions run another way (menus, RunAction) get the zero value with
+// ValueHW -1.
+type ActionContext struct {
+	SectionID int            // Action list section, e.g. SectionMain
+	CommandID int            // Command ID REAPER assigned to the action
+	Value     int            // MIDI CC value (0-127), or the high 7 bits of a 14-bit value
+	ValueHW   int            // Low 7 bits of a 14-bit value (pitch bend), or -1 for 7-bit CCs
+	RelMode   int            // 0 for absolute values, 1-3 for REAPER's relative CC modes
+	HWND      unsafe.Pointer // Window the action was triggered from, if any
+	Project   unsafe.Pointer // Project the action applies to, nil for the active project
+}
+
+// IsRelative reports whether the action was triggered by a relative control, such as an
+// endless encoder
+func (c ActionContext) IsRelative() bool {
+	return c.RelMode != 0
+}
+
+// RelativeDelta decodes a relative control's value into steps, negative for decrements.
+// It returns 0 for absolute controls.
+func (c ActionContext) RelativeDelta() int {
+	switch c.RelMode {
+	case 1: // Two's complement: 1 = +1, 127 = -1
+		if c.Value >= 64 {
+			return c.Value - 128
+		}
+		return c.Value
+	case 2: // Offset binary: 65 = +1, 63 = -1
+		return c.Value - 64
+	case 3: // Sign-magnitude: 1 = +1, 65 = -1
+		if c.Value&0x40 != 0 {
+			return -(c.Value & 0x3f)
+		}
+		return c.Value
+	default:
+		return 0
+	}
+}
+
+// AbsoluteValue returns an absolute control's position from 0 to 1, using 14-bit
+// resolution when available
+func (c ActionContext) AbsoluteValue() float64 {
+	if c.ValueHW >= 0 {
+		return float64(c.ValueHW|c.Value<<7) / 16383
+	}
+	return float64(c.Value) / 127
+}
+
 // Section ID constants
 const (
 	SectionMain          = 0
@@ -0,0 +1,49 @@
+package reaper
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../c -I${SRCDIR}/../../sdk
+#include "../c/bridge.h"
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"unsafe"
+)
+
+// Undo state flags for UndoEndBlock, from reaper_plugin.h
+const (
+	UndoStateAll      = -1
+	UndoStateTrackCfg = 1  // Track/master volume, pan and routing
+	UndoStateFX       = 2  // Track/master FX
+	UndoStateItems    = 4  // Track items
+	UndoStateMiscCfg  = 8  // Loop selection, markers, regions and extensions
+	UndoStateTrackEnv = 32 // Non-FX envelopes
+	UndoStateFXEnv    = 64 // FX envelopes, implied by UndoStateFX
+)
+
+// UndoBeginBlock starts collecting changes in the active project into one undo point.
+// Every call must be matched by UndoEndBlock.
+func UndoBeginBlock() error {
+	funcPtr, err := projectFunc("Undo_BeginBlock2")
+	if err != nil {
+		return err
+	}
+
+	C.plugin_bridge_call_undo_begin_block2(funcPtr, nil)
+	return nil
+}
+
+// UndoEndBlock ends the undo block started by UndoBeginBlock, naming the undo point.
+// flags is a combination of the UndoState constants describing what changed.
+func UndoEndBlock(description string, flags int) error {
+	funcPtr, err := projectFunc("Undo_EndBlock2")
+	if err != nil {
+		return err
+	}
+
+	cDesc := C.CString(description)
+	defer C.free(unsafe.Pointer(cDesc))
+
+	C.plugin_bridge_call_undo_end_block2(funcPtr, nil, cDesc, C.int(flags))
+	return nil
+}
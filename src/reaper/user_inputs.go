@@ -9,7 +9,6 @@ import "C"
 import (
 	"fmt"
 	"go-reaper/src/pkg/logger"
-	"runtime"
 	"strings"
 	"sync"
 	"unsafe"
@@ -39,79 +38,155 @@ const (
 	IDNO     = 7
 )
 
+// MaxInputFields is the most fields GetUserInputs can show
+const MaxInputFields = 16
+
+// inputSeparator separates values instead of REAPER's default comma. A single-line
+// field can't contain a newline, so values round-trip unchanged.
+const inputSeparator = "\n"
+
+// InputField is one field of an InputForm
+type InputField struct {
+	Label    string
+	Default  string
+	Password bool // Hide the typed text
+}
+
+// InputForm describes a GetUserInputs dialog. Unlike the plain comma-separated call,
+// values may contain commas and always come back one per field.
+type InputForm struct {
+	Title      string
+	Fields     []InputField
+	ExtraWidth int // Extra width for the text fields, in pixels
+}
+
 // GetUserInputs shows a dialog with fields for user input
 func GetUserInputs(title string, fields []string, defaults []string) ([]string, error) {
+	form := InputForm{Title: title, Fields: make([]InputField, len(fields))}
+	for i, label := range fields {
+		form.Fields[i].Label = label
+		if i < len(defaults) {
+			form.Fields[i].Default = defaults[i]
+		}
+	}
+	return form.Show()
+}
+
+// Show shows the form and returns the values in field order
+func (f InputForm) Show() ([]string, error) {
+	if len(f.Fields) == 0 {
+		return nil, fmt.Errorf("an input form needs at least one field")
+	}
+	if len(f.Fields) > MaxInputFields {
+		return nil, fmt.Errorf("an input form can have at most %d fields, got %d", MaxInputFields, len(f.Fields))
+	}
+
+	captions, defaults := f.encode()
+	goValues, err := getUserInputs(f.Title, len(f.Fields), captions, defaults)
+	if err != nil {
+		return nil, err
+	}
+
+	values := strings.Split(goValues, inputSeparator)
+	if len(values) != len(f.Fields) {
+		return nil, fmt.Errorf("expected %d values from the dialog, got %d", len(f.Fields), len(values))
+	}
+	logger.Info("Dialog completed with result: %q", f.redact(values))
+	return values, nil
+}
+
+// redact returns the values with password fields masked, for logging
+func (f InputForm) redact(values []string) []string {
+	redacted := make([]string, len(values))
+	for i, value := range values {
+		if f.Fields[i].Password && value != "" {
+			value = "[REDACTED]"
+		}
+		redacted[i] = value
+	}
+	return redacted
+}
+
+// encode builds the captions and default values. Captions are always comma-separated,
+// so commas in labels are replaced; the trailing extrawidth= and separator= entries are
+// REAPER's extensions to the caption list.
+func (f InputForm) encode() (string, string) {
+	captions := make([]string, 0, len(f.Fields)+2)
+	defaults := make([]string, len(f.Fields))
+
+	for i, field := range f.Fields {
+		label := strings.ReplaceAll(field.Label, ",", ";")
+		// A leading * marks a password field
+		label = strings.TrimLeft(label, "*")
+		if field.Password {
+			label = "*" + label
+		}
+		captions = append(captions, label)
+
+		// Newlines can't be typed into a field and would split the value
+		defaults[i] = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(field.Default)
+	}
+
+	if f.ExtraWidth > 0 {
+		captions = append(captions, fmt.Sprintf("extrawidth=%d", f.ExtraWidth))
+	}
+	captions = append(captions, "separator="+inputSeparator)
+
+	return strings.Join(captions, ","), strings.Join(defaults, inputSeparator)
+}
+
+// getUserInputs calls REAPER's GetUserInputs and returns the raw result
+func getUserInputs(title string, numInputs int, captions string, defaults string) (string, error) {
 	// Use a global mutex to ensure only one dialog can be shown at a time
 	uiMutex.Lock()
 	defer uiMutex.Unlock()
 
-	// Lock the OS thread to ensure UI operations happen on the same thread
-	runtime.LockOSThread()
-	defer runtime.UnlockOSThread()
-
 	if !initialized {
-		return nil, fmt.Errorf("REAPER functions not initialized")
-	}
-
-	// Ensure defaults has the same length as fields
-	if len(defaults) != len(fields) {
-		defaults = make([]string, len(fields))
+		return "", fmt.Errorf("REAPER functions not initialized")
 	}
 
 	// Get the GetUserInputs function
-	cFuncName := C.CString("GetUserInputs")
-	defer C.free(unsafe.Pointer(cFuncName))
-
-	getUserInputsPtr := C.plugin_bridge_call_get_func(C.plugin_bridge_get_get_func(), cFuncName)
-	if getUserInputsPtr == nil {
-		return nil, fmt.Errorf("could not get GetUserInputs function pointer")
+	getUserInputsPtr, err := projectFunc("GetUserInputs")
+	if err != nil {
+		return "", err
 	}
 
 	// Prepare parameters
 	cTitle := C.CString(title)
 	defer C.free(unsafe.Pointer(cTitle))
 
-	// Join field names with commas
-	captions := strings.Join(fields, ",")
 	cCaptions := C.CString(captions)
 	defer C.free(unsafe.Pointer(cCaptions))
 
-	// Join default values with commas
-	defaultValues := strings.Join(defaults, ",")
-
 	// Very important: Use a proper buffer with plenty of space
 	bufferSize := 8192 // Much larger buffer to handle any clipboard content
+	if len(defaults) >= bufferSize {
+		return "", fmt.Errorf("default values are too long (%d bytes)", len(defaults))
+	}
 
 	// Allocate buffer using malloc to ensure it's modifiable
 	cValuesBuf := (*C.char)(C.malloc(C.size_t(bufferSize)))
 	if cValuesBuf == nil {
-		return nil, fmt.Errorf("failed to allocate memory for input buffer")
+		return "", fmt.Errorf("failed to allocate memory for input buffer")
 	}
 	defer C.free(unsafe.Pointer(cValuesBuf))
 
-	// Zero the entire buffer first
+	// Zero the entire buffer, then copy in the default values
 	C.memset(unsafe.Pointer(cValuesBuf), 0, C.size_t(bufferSize))
-
-	// Copy default values into the buffer
-	if len(defaultValues) > 0 {
-		C.strncpy(cValuesBuf, C.CString(defaultValues), C.size_t(bufferSize-1))
+	if len(defaults) > 0 {
+		cDefaults := C.CString(defaults)
+		defer C.free(unsafe.Pointer(cDefaults))
+		C.strncpy(cValuesBuf, cDefaults, C.size_t(bufferSize-1))
 	}
 
-	// Ensure null termination
-	*(*C.char)(unsafe.Pointer(uintptr(unsafe.Pointer(cValuesBuf)) + uintptr(bufferSize-1))) = 0
-
 	// Log what we're about to do
 	logger.Debug("Showing GetUserInputs dialog: %s", title)
 
-	// Ensure we're on the main thread
-	mainThread := runtime.NumGoroutine() // Just a debug helper to verify thread
-	logger.Debug("Running on goroutine #%d", mainThread)
-
 	// Call GetUserInputs
 	result := C.plugin_bridge_call_get_user_inputs(
 		getUserInputsPtr,
 		cTitle,
-		C.int(len(fields)),
+		C.int(numInputs),
 		cCaptions,
 		cValuesBuf,
 		C.int(bufferSize),
@@ -120,17 +195,11 @@ func GetUserInputs(title string, fields []string, defaults []string) ([]string,
 	// Check result
 	if !bool(result) {
 		logger.Info("User cancelled the dialog")
-		return nil, fmt.Errorf("user cancelled the dialog")
+		return "", fmt.Errorf("user cancelled the dialog")
 	}
 
-	// Safely convert the buffer to a Go string
-	goValues := C.GoString(cValuesBuf)
-	logger.Info("Dialog completed with result: %s", goValues)
-
-	// Split by comma and return
-	values := strings.Split(goValues, ",")
-
-	return values, nil
+	// The values aren't logged here: password fields are only known to the form
+	return C.GoString(cValuesBuf), nil
 }
 
 // MessageBox is a simplified function that shows a message box with OK button
@@ -147,12 +216,9 @@ func MessageBox(text string, title string) error {
 	}
 
 	// Get the function pointer
-	cFuncName := C.CString("ShowMessageBox")
-	defer C.free(unsafe.Pointer(cFuncName))
-
-	showMessageBoxPtr := C.plugin_bridge_call_get_func(C.plugin_bridge_get_get_func(), cFuncName)
-	if showMessageBoxPtr == nil {
-		return fmt.Errorf("could not get ShowMessageBox function pointer")
+	showMessageBoxPtr, err := projectFunc("ShowMessageBox")
+	if err != nil {
+		return err
 	}
 
 	// Prepare the parameters
@@ -189,12 +255,9 @@ func YesNoBox(text string, title string) (bool, error) {
 	}
 
 	// Get the function pointer
-	cFuncName := C.CString("ShowMessageBox")
-	defer C.free(unsafe.Pointer(cFuncName))
-
-	showMessageBoxPtr := C.plugin_bridge_call_get_func(C.plugin_bridge_get_get_func(), cFuncName)
-	if showMessageBoxPtr == nil {
-		return false, fmt.Errorf("could not get ShowMessageBox function pointer")
+	showMessageBoxPtr, err := projectFunc("ShowMessageBox")
+	if err != nil {
+		return false, err
 	}
 
 	// Prepare the parameters
@@ -215,3 +278,82 @@ func YesNoBox(text string, title string) (bool, error) {
 	logger.Debug("Yes/No box %s completed with result %d", title, int(result))
 	return int(result) == IDYES, nil
 }
+
+// YesNoCancelBox shows a Yes/No/Cancel dialog
+// Returns IDYES, IDNO or IDCANCEL
+func YesNoCancelBox(text string, title string) (int, error) {
+	// Lock the UI mutex to prevent concurrent UI operations
+	uiMutex.Lock()
+	defer uiMutex.Unlock()
+
+	// Always log the question
+	logger.Info("[QUESTION] %s: %s", title, text)
+
+	if !initialized {
+		return IDCANCEL, fmt.Errorf("REAPER functions not initialized")
+	}
+
+	// Get the function pointer
+	showMessageBoxPtr, err := projectFunc("ShowMessageBox")
+	if err != nil {
+		return IDCANCEL, err
+	}
+
+	// Prepare the parameters
+	cText := C.CString(text)
+	defer C.free(unsafe.Pointer(cText))
+
+	cTitle := C.CString(title)
+	defer C.free(unsafe.Pointer(cTitle))
+
+	// Call ShowMessageBox
+	result := int(C.plugin_bridge_call_show_message_box(
+		showMessageBoxPtr,
+		cText,
+		cTitle,
+		C.int(MB_YESNOCANCEL),
+	))
+
+	logger.Debug("Yes/No/Cancel box %s completed with result %d", title, result)
+	if result != IDYES && result != IDNO {
+		return IDCANCEL, nil
+	}
+	return result, nil
+}
+
+// BrowseForFile shows REAPER's open file dialog, starting at initialPath, and returns the
+// chosen file. ok is false if the user cancelled.
+func BrowseForFile(title string, initialPath string, extension string) (path string, ok bool, err error) {
+	uiMutex.Lock()
+	defer uiMutex.Unlock()
+
+	funcPtr, err := projectFunc("GetUserFileNameForRead")
+	if err != nil {
+		return "", false, err
+	}
+
+	// REAPER requires a 4096-byte file name buffer
+	const fileNameSize = 4096
+	if len(initialPath) >= fileNameSize {
+		initialPath = ""
+	}
+	buf := getBuffer(fileNameSize)
+	defer buf.release()
+
+	if initialPath != "" {
+		cInitialPath := C.CString(initialPath)
+		defer C.free(unsafe.Pointer(cInitialPath))
+		C.strncpy(buf.ptr, cInitialPath, C.size_t(fileNameSize-1))
+	}
+
+	cTitle := C.CString(title)
+	defer C.free(unsafe.Pointer(cTitle))
+
+	cExtension := C.CString(extension)
+	defer C.free(unsafe.Pointer(cExtension))
+
+	if !bool(C.plugin_bridge_call_get_user_file_name_for_read(funcPtr, buf.ptr, cTitle, cExtension)) {
+		return "", false, nil
+	}
+	return buf.String(), true, nil
+}
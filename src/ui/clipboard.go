@@ -0,0 +1,74 @@
+package ui
+
+// This file reads and writes the system clipboard's text. On macOS it uses the general
+// pasteboard; elsewhere it goes through the SWS extension, so there it fails with
+// reaper.ErrSWSNotInstalled when SWS isn't installed.
+
+/*
+#cgo darwin CFLAGS: -I${SRCDIR}
+#cgo darwin LDFLAGS: -framework Cocoa
+#include <stdlib.h>
+#include "clipboard.h"
+*/
+import "C"
+import (
+	"fmt"
+	"go-reaper/src/reaper"
+	"runtime"
+	"strings"
+	"unsafe"
+)
+
+// SetClipboardText replaces the clipboard's contents with text
+func SetClipboardText(text string) error {
+	if runtime.GOOS != "darwin" {
+		return reaper.SetClipboard(text)
+	}
+
+	cText := C.CString(text)
+	defer C.free(unsafe.Pointer(cText))
+
+	if !bool(C.ui_clipboard_set_text(cText)) {
+		return fmt.Errorf("failed to copy to the clipboard")
+	}
+	return nil
+}
+
+// ClipboardText returns the clipboard's text, or "" if it holds none
+func ClipboardText() (string, error) {
+	if runtime.GOOS != "darwin" {
+		return reaper.GetClipboard()
+	}
+
+	cText := C.ui_clipboard_copy_text()
+	if cText == nil {
+		return "", nil
+	}
+	defer C.free(unsafe.Pointer(cText))
+
+	return C.GoString(cText), nil
+}
+
+// ClipboardLine returns the clipboard's text as one line of at most maxLength bytes,
+// for single-line fields: line breaks and runs of spaces become single spaces, and
+// longer text is cut at a word.
+func ClipboardLine(maxLength int) (string, error) {
+	text, err := ClipboardText()
+	if err != nil {
+		return "", err
+	}
+
+	line := strings.Join(strings.Fields(text), " ")
+	if maxLength > 0 && len(line) > maxLength {
+		cut := strings.LastIndex(line[:maxLength+1], " ")
+		if cut <= 0 {
+			// One long word; back up to a character boundary
+			cut = maxLength
+			for cut > 0 && line[cut]&0xC0 == 0x80 {
+				cut--
+			}
+		}
+		line = line[:cut]
+	}
+	return line, nil
+}
@@ -0,0 +1,10 @@
+#ifndef UI_CLIPBOARD_H
+#define UI_CLIPBOARD_H
+
+#include <stdbool.h>
+
+// Function declarations that will be called from Go
+bool ui_clipboard_set_text(const char* text);
+char* ui_clipboard_copy_text(void); // Caller frees; NULL when the clipboard holds no text
+
+#endif /* UI_CLIPBOARD_H */
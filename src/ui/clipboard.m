@@ -0,0 +1,36 @@
+#include <stdlib.h>
+#include <stdbool.h>
+#include <string.h>
+#import <Cocoa/Cocoa.h>
+#include "clipboard.h"
+
+// Replace the general pasteboard's contents with text - PUBLIC FUNCTION
+bool ui_clipboard_set_text(const char* text) {
+    if (!text) {
+        return false;
+    }
+
+    @autoreleasepool {
+        NSString* string = [NSString stringWithUTF8String:text];
+        if (!string) {
+            return false;
+        }
+
+        NSPasteboard* pasteboard = [NSPasteboard generalPasteboard];
+        [pasteboard clearContents];
+        return [pasteboard setString:string forType:NSPasteboardTypeString];
+    }
+}
+
+// Copy the general pasteboard's text - PUBLIC FUNCTION
+char* ui_clipboard_copy_text(void) {
+    @autoreleasepool {
+        NSString* string = [[NSPasteboard generalPasteboard] stringForType:NSPasteboardTypeString];
+        if (!string) {
+            return NULL;
+        }
+
+        const char* utf8 = [string UTF8String];
+        return utf8 ? strdup(utf8) : NULL;
+    }
+}
@@ -0,0 +1,91 @@
+package ui
+
+// This file lets Windows dock into REAPER's docker. Docking hands the window's content
+// view to REAPER (on macOS an HWND is an NSView) and hides the now-empty NSWindow,
+// which takes the content back when undocked.
+
+/*
+#cgo darwin CFLAGS: -I${SRCDIR}
+#include "window.h"
+*/
+import "C"
+import (
+	"fmt"
+	"go-reaper/src/pkg/config"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+)
+
+// dockIdentPrefix namespaces our windows in REAPER's dock preferences
+const dockIdentPrefix = "go_reaper_"
+
+// Dock moves the window into REAPER's docker. REAPER remembers which docker each
+// window ID was last placed in. allowShow lets REAPER open the docker if it is hidden.
+func (w *Window) Dock(allowShow bool) error {
+	if w.IsDocked() {
+		return reaper.DockWindowActivate(w.dockedView)
+	}
+
+	view := C.ui_window_detach_content(C.int(w.id))
+	if view == nil {
+		return fmt.Errorf("failed to detach window %q for docking", w.title)
+	}
+
+	if err := reaper.DockWindowAdd(view, w.title, dockIdentPrefix+w.windowID, allowShow); err != nil {
+		C.ui_window_reattach_content(C.int(w.id))
+		return fmt.Errorf("failed to dock window %q: %v", w.title, err)
+	}
+
+	w.dockedView = view
+	logger.Debug("Docked window %s", w.windowID)
+	return nil
+}
+
+// Undock takes the window out of REAPER's docker and shows it as a floating window
+func (w *Window) Undock() error {
+	if !w.IsDocked() {
+		return nil
+	}
+
+	if err := reaper.DockWindowRemove(w.dockedView); err != nil {
+		return fmt.Errorf("failed to undock window %q: %v", w.title, err)
+	}
+	w.dockedView = nil
+
+	if !bool(C.ui_window_reattach_content(C.int(w.id))) {
+		return fmt.Errorf("failed to restore window %q after undocking", w.title)
+	}
+
+	logger.Debug("Undocked window %s", w.windowID)
+	return w.Show()
+}
+
+// IsDocked reports whether the window is currently in REAPER's docker
+func (w *Window) IsDocked() bool {
+	return w.dockedView != nil
+}
+
+// ToggleDock docks a floating window or undocks a docked one, and remembers the choice
+// for the next time the window opens
+func (w *Window) ToggleDock() error {
+	var err error
+	if w.IsDocked() {
+		err = w.Undock()
+	} else {
+		err = w.Dock(true)
+	}
+	if err != nil {
+		return err
+	}
+	rememberDocked(w.windowID, w.IsDocked())
+	return nil
+}
+
+// rememberDocked stores whether a window opens docked in its preferences
+func rememberDocked(windowID string, docked bool) {
+	preferences := config.GetWindowPreferences(windowID)
+	preferences.Docked = docked
+	if err := config.SetWindowPreferences(windowID, preferences); err != nil {
+		logger.Warning("Failed to remember docking of window %s: %v", windowID, err)
+	}
+}
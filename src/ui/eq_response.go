@@ -0,0 +1,164 @@
+package ui
+
+import (
+	"math"
+	"strings"
+)
+
+// EQFilterType is the shape of an EQ band
+type EQFilterType int
+
+// EQ filter types
+const (
+	EQPeak EQFilterType = iota
+	EQLowShelf
+	EQHighShelf
+	EQLowPass
+	EQHighPass
+	EQNotch
+	EQBandPass
+)
+
+// EQBand describes one EQ band in real units
+type EQBand struct {
+	Type      EQFilterType
+	Frequency float64 // Hz
+	Gain      float64 // dB, ignored by pass/notch filters
+	Q         float64
+}
+
+// CurvePoint is one point of a frequency response curve
+type CurvePoint struct {
+	Frequency float64 // Hz
+	Gain      float64 // dB
+}
+
+// Frequency range drawn by the EQ curve view
+const (
+	CurveMinFrequency = 20.0
+	CurveMaxFrequency = 20000.0
+)
+
+// curveSampleRate is the sample rate the response is evaluated at; it only affects
+// the shape close to Nyquist, which is above the drawn range
+const curveSampleRate = 48000.0
+
+// EQFilterTypeFromName guesses a band's type from its label, e.g. "Low Shelf" or "Band 2"
+func EQFilterTypeFromName(name string) EQFilterType {
+	name = strings.ToLower(name)
+	switch {
+	case strings.Contains(name, "low shelf"):
+		return EQLowShelf
+	case strings.Contains(name, "high shelf"):
+		return EQHighShelf
+	case strings.Contains(name, "low pass") || strings.Contains(name, "lowpass"):
+		return EQLowPass
+	case strings.Contains(name, "high pass") || strings.Contains(name, "highpass"):
+		return EQHighPass
+	case strings.Contains(name, "notch"):
+		return EQNotch
+	case strings.Contains(name, "band pass") || strings.Contains(name, "bandpass"):
+		return EQBandPass
+	default:
+		return EQPeak
+	}
+}
+
+// ResponseCurve evaluates the combined response of the bands at count log-spaced frequencies
+func ResponseCurve(bands []EQBand, count int) []CurvePoint {
+	if count < 2 {
+		count = 2
+	}
+
+	points := make([]CurvePoint, count)
+	ratio := math.Log(CurveMaxFrequency / CurveMinFrequency)
+	for i := range points {
+		freq := CurveMinFrequency * math.Exp(ratio*float64(i)/float64(count-1))
+		points[i] = CurvePoint{Frequency: freq, Gain: Response(bands, freq)}
+	}
+
+	return points
+}
+
+// Response returns the combined gain in dB of the bands at a frequency
+func Response(bands []EQBand, freq float64) float64 {
+	total := 0.0
+	for _, band := range bands {
+		total += band.response(freq)
+	}
+	return total
+}
+
+// response evaluates a single band using the RBJ audio EQ cookbook biquads
+func (b EQBand) response(freq float64) float64 {
+	if b.Frequency <= 0 || b.Frequency >= curveSampleRate/2 {
+		return 0
+	}
+
+	q := b.Q
+	if q <= 0 {
+		q = math.Sqrt2 / 2
+	}
+
+	a := math.Pow(10, b.Gain/40)
+	w0 := 2 * math.Pi * b.Frequency / curveSampleRate
+	cosW0, sinW0 := math.Cos(w0), math.Sin(w0)
+	alpha := sinW0 / (2 * q)
+
+	var b0, b1, b2, a0, a1, a2 float64
+	switch b.Type {
+	case EQLowShelf:
+		sqrtA := 2 * math.Sqrt(a) * alpha
+		b0 = a * ((a + 1) - (a-1)*cosW0 + sqrtA)
+		b1 = 2 * a * ((a - 1) - (a+1)*cosW0)
+		b2 = a * ((a + 1) - (a-1)*cosW0 - sqrtA)
+		a0 = (a + 1) + (a-1)*cosW0 + sqrtA
+		a1 = -2 * ((a - 1) + (a+1)*cosW0)
+		a2 = (a + 1) + (a-1)*cosW0 - sqrtA
+	case EQHighShelf:
+		sqrtA := 2 * math.Sqrt(a) * alpha
+		b0 = a * ((a + 1) + (a-1)*cosW0 + sqrtA)
+		b1 = -2 * a * ((a - 1) + (a+1)*cosW0)
+		b2 = a * ((a + 1) + (a-1)*cosW0 - sqrtA)
+		a0 = (a + 1) - (a-1)*cosW0 + sqrtA
+		a1 = 2 * ((a - 1) - (a+1)*cosW0)
+		a2 = (a + 1) - (a-1)*cosW0 - sqrtA
+	case EQLowPass:
+		b0 = (1 - cosW0) / 2
+		b1 = 1 - cosW0
+		b2 = (1 - cosW0) / 2
+		a0, a1, a2 = 1+alpha, -2*cosW0, 1-alpha
+	case EQHighPass:
+		b0 = (1 + cosW0) / 2
+		b1 = -(1 + cosW0)
+		b2 = (1 + cosW0) / 2
+		a0, a1, a2 = 1+alpha, -2*cosW0, 1-alpha
+	case EQNotch:
+		b0, b1, b2 = 1, -2*cosW0, 1
+		a0, a1, a2 = 1+alpha, -2*cosW0, 1-alpha
+	case EQBandPass:
+		b0, b1, b2 = alpha, 0, -alpha
+		a0, a1, a2 = 1+alpha, -2*cosW0, 1-alpha
+	default: // EQPeak
+		b0, b1, b2 = 1+alpha*a, -2*cosW0, 1-alpha*a
+		a0, a1, a2 = 1+alpha/a, -2*cosW0, 1-alpha/a
+	}
+
+	// |H(e^jw)| at the evaluation frequency
+	w := 2 * math.Pi * freq / curveSampleRate
+	cos1, sin1 := math.Cos(w), math.Sin(w)
+	cos2, sin2 := math.Cos(2*w), math.Sin(2*w)
+
+	numRe := b0 + b1*cos1 + b2*cos2
+	numIm := -(b1*sin1 + b2*sin2)
+	denRe := a0 + a1*cos1 + a2*cos2
+	denIm := -(a1*sin1 + a2*sin2)
+
+	num := numRe*numRe + numIm*numIm
+	den := denRe*denRe + denIm*denIm
+	if den == 0 || num == 0 {
+		return -120
+	}
+
+	return 10 * math.Log10(num/den)
+}
@@ -0,0 +1,194 @@
+package ui
+
+// This file implements a native macOS window that draws an EQ response curve
+// and keeps it in sync with the plugin while it is open
+
+/*
+#cgo darwin CFLAGS: -I${SRCDIR}
+#cgo darwin LDFLAGS: -framework Cocoa
+#include <stdlib.h>
+#include "eqcurve.h"
+*/
+import "C"
+import (
+	"fmt"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"reflect"
+	"runtime"
+	"sync"
+	"unsafe"
+)
+
+// EQCurveSource returns the bands to draw. It is polled on the main thread while the
+// window is open; returning false (e.g. the FX was removed) closes the window.
+type EQCurveSource func() ([]EQBand, bool)
+
+// EQCurveWindowID is the ID the EQ curve window is registered under
+const EQCurveWindowID = "eq-curve"
+
+const (
+	eqCurvePoints          = 256 // Points evaluated across 20 Hz - 20 kHz
+	eqCurveRefreshInterval = 0.1 // Seconds between polls of the source
+)
+
+var (
+	eqCurveMutex     sync.Mutex
+	eqCurveSource    EQCurveSource
+	eqCurveLastBands []EQBand
+)
+
+// ShowEQCurve opens (or retargets) the EQ curve window and draws the source's bands in
+// the current theme's colors
+func ShowEQCurve(title string, source EQCurveSource) error {
+	if runtime.GOOS != "darwin" {
+		return fmt.Errorf("the EQ curve window is currently only implemented for macOS")
+	}
+
+	eqCurveMutex.Lock()
+	eqCurveSource = source
+	eqCurveLastBands = nil
+	eqCurveMutex.Unlock()
+
+	cTitle := C.CString(title)
+	defer C.free(unsafe.Pointer(cTitle))
+
+	theme := eqCurveTheme(CurrentTheme())
+	if !bool(C.eq_curve_show_window(cTitle, C.double(eqCurveRefreshInterval), &theme)) {
+		return fmt.Errorf("failed to create EQ curve window")
+	}
+
+	if _, open := Windows.Lookup(EQCurveWindowID); !open {
+		if err := Windows.Register(EQCurveWindowID, WindowFuncs{CloseFunc: CloseEQCurve, IsOpenFunc: IsEQCurveOpen}); err != nil {
+			logger.Warning("Failed to register EQ curve window: %v", err)
+		}
+	}
+
+	// Draw immediately rather than waiting for the first poll
+	refreshEQCurve()
+	return nil
+}
+
+// eqCurveTheme converts a theme to the colors the curve view draws with
+func eqCurveTheme(theme Theme) C.EQCurveTheme {
+	color := func(c Color) C.EQColor {
+		return C.EQColor{r: C.double(c.R), g: C.double(c.G), b: C.double(c.B), a: C.double(c.A)}
+	}
+	return C.EQCurveTheme{
+		dark:       C.bool(theme.Dark),
+		background: color(theme.Background),
+		label:      color(theme.SecondaryText),
+		grid:       color(theme.Grid),
+		axis:       color(theme.Axis),
+		curve:      color(theme.Accent),
+	}
+}
+
+// CloseEQCurve closes the EQ curve window if it exists
+func CloseEQCurve() {
+	if runtime.GOOS == "darwin" {
+		C.eq_curve_close_window()
+	}
+}
+
+// IsEQCurveOpen checks if the EQ curve window is currently open
+func IsEQCurveOpen() bool {
+	if runtime.GOOS == "darwin" {
+		return bool(C.eq_curve_window_exists())
+	}
+	return false
+}
+
+// Called by the window's refresh timer
+//
+//export go_eq_curve_refresh
+func go_eq_curve_refresh() {
+	defer reaper.Recover("go_eq_curve_refresh")
+	refreshEQCurve()
+}
+
+// Called when the window closes, however it was closed
+//
+//export go_eq_curve_closed
+func go_eq_curve_closed() {
+	defer reaper.Recover("go_eq_curve_closed")
+
+	eqCurveMutex.Lock()
+	eqCurveSource = nil
+	eqCurveLastBands = nil
+	eqCurveMutex.Unlock()
+
+	Windows.Closed(EQCurveWindowID)
+}
+
+// refreshEQCurve polls the source and redraws the curve if the bands changed
+func refreshEQCurve() {
+	eqCurveMutex.Lock()
+	source := eqCurveSource
+	eqCurveMutex.Unlock()
+
+	if source == nil {
+		return
+	}
+
+	bands, ok := source()
+	if !ok {
+		logger.Info("EQ curve source is gone, closing window")
+		CloseEQCurve()
+		return
+	}
+
+	eqCurveMutex.Lock()
+	unchanged := eqCurveLastBands != nil && reflect.DeepEqual(bands, eqCurveLastBands)
+	if !unchanged {
+		eqCurveLastBands = append([]EQBand{}, bands...)
+	}
+	eqCurveMutex.Unlock()
+
+	if unchanged {
+		return
+	}
+
+	setEQCurveData(bands)
+}
+
+// setEQCurveData evaluates the response and hands it to the view
+func setEQCurveData(bands []EQBand) {
+	points := ResponseCurve(bands, eqCurvePoints)
+
+	// Allocate in C memory, since the view keeps its own copy
+	freqs := allocDoubles(len(points))
+	defer C.free(unsafe.Pointer(&freqs[0]))
+	gains := allocDoubles(len(points))
+	defer C.free(unsafe.Pointer(&gains[0]))
+
+	for i, point := range points {
+		freqs[i] = C.double(point.Frequency)
+		gains[i] = C.double(point.Gain)
+	}
+
+	// Markers sit on the combined curve at each band's frequency
+	var markerFreqs, markerGains *C.double
+	if len(bands) > 0 {
+		mf := allocDoubles(len(bands))
+		defer C.free(unsafe.Pointer(&mf[0]))
+		mg := allocDoubles(len(bands))
+		defer C.free(unsafe.Pointer(&mg[0]))
+
+		for i, band := range bands {
+			mf[i] = C.double(band.Frequency)
+			mg[i] = C.double(Response(bands, band.Frequency))
+		}
+		markerFreqs, markerGains = &mf[0], &mg[0]
+	}
+
+	if !bool(C.eq_curve_set_data(&freqs[0], &gains[0], C.int(len(points)), markerFreqs, markerGains, C.int(len(bands)))) {
+		logger.Warning("Failed to update EQ curve")
+	}
+}
+
+// allocDoubles allocates a C array of n doubles and returns it as a slice
+func allocDoubles(n int) []C.double {
+	ptr := (*C.double)(C.malloc(C.size_t(n) * C.size_t(unsafe.Sizeof(C.double(0)))))
+	return unsafe.Slice(ptr, n)
+}
@@ -0,0 +1,46 @@
+#ifndef EQCURVE_H
+#define EQCURVE_H
+
+#include <stdbool.h>
+
+// An RGBA color with components from 0 to 1
+typedef struct {
+    double r, g, b, a;
+} EQColor;
+
+// Colors the curve view draws with, from the Go theme
+typedef struct {
+    bool dark;          // Window appearance
+    EQColor background;
+    EQColor label;
+    EQColor grid;
+    EQColor axis;       // 0 dB line
+    EQColor curve;
+} EQCurveTheme;
+
+// Context structure for passing data between Go and Objective-C
+typedef struct {
+    const char* title;
+    double refresh_interval; // Seconds between parameter polls
+    const EQCurveTheme* theme;
+    const double* freqs;   // Curve points, Hz
+    const double* gains;   // Curve points, dB
+    int count;
+    const double* marker_freqs; // Band markers, Hz
+    const double* marker_gains; // Band markers, dB
+    int marker_count;
+    bool success;
+} EQCurveContext;
+
+// Function declarations that will be called from Go
+bool eq_curve_show_window(const char* title, double refresh_interval, const EQCurveTheme* theme);
+bool eq_curve_set_data(const double* freqs, const double* gains, int count,
+                       const double* marker_freqs, const double* marker_gains, int marker_count);
+void eq_curve_close_window(void);
+bool eq_curve_window_exists(void);
+
+// Callbacks from Objective-C to Go
+extern void go_eq_curve_refresh(void);
+extern void go_eq_curve_closed(void);
+
+#endif /* EQCURVE_H */
@@ -0,0 +1,436 @@
+#include <stdio.h>
+#include <stdlib.h>
+#include <stdbool.h>
+#include <string.h>
+#include "../c/logging.h"
+#import <Cocoa/Cocoa.h>
+#include "eqcurve.h"
+
+// Use our core logging system
+static void eq_log_to_reaper(LogLevel level, const char* message) {
+    log_message_v(level, "eqCurveUI", message);
+}
+
+// Display range of the curve view
+#define EQ_MIN_FREQ 20.0
+#define EQ_MAX_FREQ 20000.0
+#define EQ_DB_RANGE 24.0
+
+// Forward declarations of our view and controller classes
+@interface RPREQCurveView : NSView
+@end
+
+@interface RPREQCurveController : NSObject <NSWindowDelegate>
+- (void)refreshTimerFired:(NSTimer*)timer;
+@end
+
+// Global references for window, view and refresh timer
+static NSWindow* eq_window = nil;
+static RPREQCurveView* eq_view = nil;
+static RPREQCurveController* eq_controller = nil;
+static NSTimer* eq_timer = nil;
+
+// Curve data, owned by the main thread
+static double* eq_freqs = NULL;
+static double* eq_gains = NULL;
+static int eq_count = 0;
+static double* eq_marker_freqs = NULL;
+static double* eq_marker_gains = NULL;
+static int eq_marker_count = 0;
+
+// Colors of the view, replaced each time the window is shown
+static EQCurveTheme eq_theme = {
+    true,
+    {0.12, 0.12, 0.12, 1.0},
+    {0.6, 0.6, 0.6, 1.0},
+    {0.25, 0.25, 0.25, 1.0},
+    {0.45, 0.45, 0.45, 1.0},
+    {0.3, 0.7, 1.0, 1.0}
+};
+
+// Convert a theme color to an NSColor
+static NSColor* eq_color(EQColor color) {
+    return [NSColor colorWithCalibratedRed:color.r green:color.g blue:color.b alpha:color.a];
+}
+
+// Give the window the theme's appearance, so its title bar matches the view
+static void eq_apply_appearance(NSWindow* window) {
+    if (@available(macOS 10.14, *)) {
+        [window setAppearance:[NSAppearance appearanceNamed:eq_theme.dark ? NSAppearanceNameDarkAqua : NSAppearanceNameAqua]];
+    }
+}
+
+// Copy a double array, returning NULL for empty input
+static double* eq_copy_array(const double* values, int count) {
+    if (values == NULL || count <= 0) {
+        return NULL;
+    }
+    double* copy = (double*)malloc(sizeof(double) * count);
+    if (copy != NULL) {
+        memcpy(copy, values, sizeof(double) * count);
+    }
+    return copy;
+}
+
+// Release the stored curve data
+static void eq_free_data(void) {
+    free(eq_freqs);
+    free(eq_gains);
+    free(eq_marker_freqs);
+    free(eq_marker_gains);
+    eq_freqs = eq_gains = eq_marker_freqs = eq_marker_gains = NULL;
+    eq_count = eq_marker_count = 0;
+}
+
+// Stop the refresh timer and forget the window
+static void eq_release_window(void) {
+    if (eq_timer != nil) {
+        [eq_timer invalidate];
+        eq_timer = nil;
+    }
+    eq_window = nil;
+    eq_view = nil;
+    eq_free_data();
+}
+
+@implementation RPREQCurveView
+
+- (BOOL)isFlipped {
+    return NO;
+}
+
+// Map a frequency in Hz to a horizontal position
+- (CGFloat)xForFrequency:(double)freq inRect:(NSRect)rect {
+    if (freq < EQ_MIN_FREQ) freq = EQ_MIN_FREQ;
+    if (freq > EQ_MAX_FREQ) freq = EQ_MAX_FREQ;
+    double position = log(freq / EQ_MIN_FREQ) / log(EQ_MAX_FREQ / EQ_MIN_FREQ);
+    return rect.origin.x + position * rect.size.width;
+}
+
+// Map a gain in dB to a vertical position, clamped to the display range
+- (CGFloat)yForGain:(double)gain inRect:(NSRect)rect {
+    if (gain < -EQ_DB_RANGE) gain = -EQ_DB_RANGE;
+    if (gain > EQ_DB_RANGE) gain = EQ_DB_RANGE;
+    double position = (gain + EQ_DB_RANGE) / (2 * EQ_DB_RANGE);
+    return rect.origin.y + position * rect.size.height;
+}
+
+- (void)drawRect:(NSRect)dirtyRect {
+    NSRect bounds = [self bounds];
+    NSRect plot = NSInsetRect(bounds, 36, 20);
+
+    // Background
+    [eq_color(eq_theme.background) setFill];
+    NSRectFill(bounds);
+
+    NSDictionary* labelAttributes = @{
+        NSFontAttributeName: [NSFont systemFontOfSize:9],
+        NSForegroundColorAttributeName: eq_color(eq_theme.label)
+    };
+
+    // Frequency grid
+    static const double gridFreqs[] = {50, 100, 200, 500, 1000, 2000, 5000, 10000};
+    static const char* gridLabels[] = {"50", "100", "200", "500", "1k", "2k", "5k", "10k"};
+    NSBezierPath* grid = [NSBezierPath bezierPath];
+    for (int i = 0; i < 8; i++) {
+        CGFloat x = [self xForFrequency:gridFreqs[i] inRect:plot];
+        [grid moveToPoint:NSMakePoint(x, NSMinY(plot))];
+        [grid lineToPoint:NSMakePoint(x, NSMaxY(plot))];
+        NSString* label = [NSString stringWithUTF8String:gridLabels[i]];
+        [label drawAtPoint:NSMakePoint(x - 8, NSMinY(plot) - 14) withAttributes:labelAttributes];
+    }
+
+    // Gain grid
+    for (int db = -18; db <= 18; db += 6) {
+        CGFloat y = [self yForGain:db inRect:plot];
+        if (db != 0) {
+            [grid moveToPoint:NSMakePoint(NSMinX(plot), y)];
+            [grid lineToPoint:NSMakePoint(NSMaxX(plot), y)];
+        }
+        NSString* label = [NSString stringWithFormat:@"%+d", db];
+        [label drawAtPoint:NSMakePoint(NSMinX(plot) - 30, y - 6) withAttributes:labelAttributes];
+    }
+    // Hairlines one device pixel wide at any scale factor
+    CGFloat scale = [[self window] backingScaleFactor];
+    [eq_color(eq_theme.grid) setStroke];
+    [grid setLineWidth:scale > 0 ? 1.0 / scale : 0.5];
+    [grid stroke];
+
+    // 0 dB line
+    NSBezierPath* zeroLine = [NSBezierPath bezierPath];
+    CGFloat zeroY = [self yForGain:0 inRect:plot];
+    [zeroLine moveToPoint:NSMakePoint(NSMinX(plot), zeroY)];
+    [zeroLine lineToPoint:NSMakePoint(NSMaxX(plot), zeroY)];
+    [eq_color(eq_theme.axis) setStroke];
+    [zeroLine setLineWidth:1.0];
+    [zeroLine stroke];
+
+    if (eq_count < 2 || eq_freqs == NULL || eq_gains == NULL) {
+        NSString* empty = @"No EQ bands";
+        [empty drawAtPoint:NSMakePoint(NSMidX(plot) - 30, NSMidY(plot) + 6) withAttributes:labelAttributes];
+        return;
+    }
+
+    // Response curve, clipped to the plot area
+    [NSGraphicsContext saveGraphicsState];
+    NSRectClip(plot);
+
+    NSBezierPath* curve = [NSBezierPath bezierPath];
+    for (int i = 0; i < eq_count; i++) {
+        NSPoint point = NSMakePoint([self xForFrequency:eq_freqs[i] inRect:plot],
+                                    [self yForGain:eq_gains[i] inRect:plot]);
+        if (i == 0) {
+            [curve moveToPoint:point];
+        } else {
+            [curve lineToPoint:point];
+        }
+    }
+
+    // Shade the area between the curve and 0 dB
+    NSBezierPath* fill = [curve copy];
+    [fill lineToPoint:NSMakePoint([self xForFrequency:eq_freqs[eq_count - 1] inRect:plot], zeroY)];
+    [fill lineToPoint:NSMakePoint([self xForFrequency:eq_freqs[0] inRect:plot], zeroY)];
+    [fill closePath];
+    [[eq_color(eq_theme.curve) colorWithAlphaComponent:0.15 * eq_theme.curve.a] setFill];
+    [fill fill];
+
+    [eq_color(eq_theme.curve) setStroke];
+    [curve setLineWidth:2.0];
+    [curve stroke];
+
+    // Band markers
+    [[NSColor colorWithCalibratedRed:1.0 green:0.75 blue:0.3 alpha:1.0] setFill];
+    for (int i = 0; i < eq_marker_count; i++) {
+        CGFloat x = [self xForFrequency:eq_marker_freqs[i] inRect:plot];
+        CGFloat y = [self yForGain:eq_marker_gains[i] inRect:plot];
+        NSBezierPath* marker = [NSBezierPath bezierPathWithOvalInRect:NSMakeRect(x - 4, y - 4, 8, 8)];
+        [marker fill];
+
+        NSString* label = [NSString stringWithFormat:@"%d", i + 1];
+        [label drawAtPoint:NSMakePoint(x + 5, y + 3) withAttributes:labelAttributes];
+    }
+
+    [NSGraphicsContext restoreGraphicsState];
+}
+
+@end
+
+@implementation RPREQCurveController
+
+- (void)refreshTimerFired:(NSTimer*)timer {
+    // Go reads the current band settings and pushes new data if anything changed
+    go_eq_curve_refresh();
+}
+
+- (void)windowWillClose:(NSNotification*)notification {
+    eq_log_to_reaper(LOG_DEBUG, "EQ curve window closing");
+    eq_release_window();
+    go_eq_curve_closed();
+}
+
+@end
+
+// Function to create and show the curve window - internal
+static void eq_show_window_on_main_thread(void* context) {
+    eq_log_to_reaper(LOG_DEBUG, "Entering eq_show_window_on_main_thread");
+
+    EQCurveContext* ctx = (EQCurveContext*)context;
+    if (!ctx) {
+        eq_log_to_reaper(LOG_ERROR, "Null context in eq_show_window_on_main_thread");
+        return;
+    }
+
+    @autoreleasepool {
+        @try {
+            NSString* title = [NSString stringWithUTF8String:ctx->title];
+            if (ctx->theme != NULL) {
+                eq_theme = *ctx->theme;
+            }
+
+            // Reuse the existing window, only updating its title and colors
+            if (eq_window != nil) {
+                eq_log_to_reaper(LOG_DEBUG, "Window already exists, bringing to front");
+                [eq_window setTitle:title];
+                eq_apply_appearance(eq_window);
+                [eq_view setNeedsDisplay:YES];
+                [eq_window makeKeyAndOrderFront:nil];
+                ctx->success = true;
+                return;
+            }
+
+            if (eq_controller == nil) {
+                eq_controller = [[RPREQCurveController alloc] init];
+                if (!eq_controller) {
+                    eq_log_to_reaper(LOG_ERROR, "Failed to create controller");
+                    ctx->success = false;
+                    return;
+                }
+            }
+
+            NSRect frame = NSMakeRect(100, 100, 640, 320);
+            NSWindow* window = [[NSWindow alloc]
+                initWithContentRect:frame
+                styleMask:NSWindowStyleMaskTitled|NSWindowStyleMaskClosable|NSWindowStyleMaskResizable
+                backing:NSBackingStoreBuffered
+                defer:NO];
+
+            if (window == nil) {
+                eq_log_to_reaper(LOG_ERROR, "Failed to create window");
+                ctx->success = false;
+                return;
+            }
+
+            [window setTitle:title];
+            [window setReleasedWhenClosed:NO]; // Important: Don't release on close
+            [window setDelegate:eq_controller];
+            [window setMinSize:NSMakeSize(320, 180)];
+            eq_apply_appearance(window);
+
+            RPREQCurveView* view = [[RPREQCurveView alloc] initWithFrame:[[window contentView] bounds]];
+            [view setAutoresizingMask:NSViewWidthSizable|NSViewHeightSizable];
+            [[window contentView] addSubview:view];
+
+            eq_window = window;
+            eq_view = view;
+
+            // Poll for parameter changes; common modes keep it running while menus are open
+            eq_timer = [NSTimer timerWithTimeInterval:ctx->refresh_interval
+                                               target:eq_controller
+                                             selector:@selector(refreshTimerFired:)
+                                             userInfo:nil
+                                              repeats:YES];
+            [[NSRunLoop mainRunLoop] addTimer:eq_timer forMode:NSRunLoopCommonModes];
+
+            [window center];
+            [window makeKeyAndOrderFront:nil];
+
+            eq_log_to_reaper(LOG_INFO, "EQ curve window created and shown successfully");
+            ctx->success = true;
+        }
+        @catch (NSException *exception) {
+            eq_log_to_reaper(LOG_ERROR, "EXCEPTION creating EQ curve window");
+            NSLog(@"Exception: %@", exception);
+            ctx->success = false;
+        }
+    }
+}
+
+// Function to replace the curve data - internal
+static void eq_set_data_on_main_thread(void* context) {
+    EQCurveContext* ctx = (EQCurveContext*)context;
+    if (!ctx) {
+        eq_log_to_reaper(LOG_ERROR, "Null context in eq_set_data_on_main_thread");
+        return;
+    }
+
+    if (eq_window == nil || eq_view == nil) {
+        ctx->success = false;
+        return;
+    }
+
+    eq_free_data();
+    eq_freqs = eq_copy_array(ctx->freqs, ctx->count);
+    eq_gains = eq_copy_array(ctx->gains, ctx->count);
+    eq_count = (eq_freqs != NULL && eq_gains != NULL) ? ctx->count : 0;
+    eq_marker_freqs = eq_copy_array(ctx->marker_freqs, ctx->marker_count);
+    eq_marker_gains = eq_copy_array(ctx->marker_gains, ctx->marker_count);
+    eq_marker_count = (eq_marker_freqs != NULL && eq_marker_gains != NULL) ? ctx->marker_count : 0;
+
+    [eq_view setNeedsDisplay:YES];
+    ctx->success = true;
+}
+
+// Function to close window on main thread - internal
+static void eq_close_window_on_main_thread(void* context) {
+    eq_log_to_reaper(LOG_DEBUG, "Entering eq_close_window_on_main_thread");
+
+    @try {
+        if (eq_window != nil) {
+            // windowWillClose: releases the globals and notifies Go
+            [eq_window close];
+            eq_log_to_reaper(LOG_INFO, "EQ curve window closed successfully");
+        } else {
+            eq_log_to_reaper(LOG_DEBUG, "EQ curve window already nil when trying to close");
+        }
+    }
+    @catch (NSException *exception) {
+        eq_log_to_reaper(LOG_ERROR, "EXCEPTION closing EQ curve window");
+        NSLog(@"Exception: %@", exception);
+    }
+}
+
+// Execute function on main thread - internal
+static bool eq_execute_on_main_thread(void (*func)(void*), void* context) {
+    // Check if already on main thread (e.g. called from the refresh timer)
+    if ([NSThread isMainThread]) {
+        func(context);
+        return true;
+    }
+
+    __block bool completed = false;
+
+    dispatch_sync(dispatch_get_main_queue(), ^{
+        func(context);
+        completed = true;
+    });
+
+    return completed;
+}
+
+// Show the EQ curve window, handling thread requirements - PUBLIC FUNCTION
+bool eq_curve_show_window(const char* title, double refresh_interval, const EQCurveTheme* theme) {
+    eq_log_to_reaper(LOG_INFO, "Entering eq_curve_show_window");
+
+    EQCurveContext ctx;
+    memset(&ctx, 0, sizeof(ctx));
+    ctx.title = title;
+    ctx.refresh_interval = refresh_interval;
+    ctx.theme = theme;
+
+    bool executed = eq_execute_on_main_thread(eq_show_window_on_main_thread, &ctx);
+
+    if (!executed) {
+        eq_log_to_reaper(LOG_ERROR, "Failed to execute on main thread");
+        return false;
+    }
+
+    return ctx.success;
+}
+
+// Replace the drawn curve and band markers - PUBLIC FUNCTION
+bool eq_curve_set_data(const double* freqs, const double* gains, int count,
+                       const double* marker_freqs, const double* marker_gains, int marker_count) {
+    EQCurveContext ctx;
+    memset(&ctx, 0, sizeof(ctx));
+    ctx.freqs = freqs;
+    ctx.gains = gains;
+    ctx.count = count;
+    ctx.marker_freqs = marker_freqs;
+    ctx.marker_gains = marker_gains;
+    ctx.marker_count = marker_count;
+
+    if (!eq_execute_on_main_thread(eq_set_data_on_main_thread, &ctx)) {
+        eq_log_to_reaper(LOG_ERROR, "Failed to execute on main thread");
+        return false;
+    }
+
+    return ctx.success;
+}
+
+// Close the EQ curve window if it exists - PUBLIC FUNCTION
+void eq_curve_close_window(void) {
+    eq_log_to_reaper(LOG_DEBUG, "Entering eq_curve_close_window");
+
+    if (eq_window == nil) {
+        eq_log_to_reaper(LOG_DEBUG, "No EQ curve window to close");
+        return;
+    }
+
+    // Execute on main thread using a function pointer (not a block)
+    eq_execute_on_main_thread(eq_close_window_on_main_thread, NULL);
+}
+
+// Check if the EQ curve window exists - PUBLIC FUNCTION
+bool eq_curve_window_exists(void) {
+    return (eq_window != nil);
+}
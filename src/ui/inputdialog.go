@@ -0,0 +1,88 @@
+package ui
+
+// This file implements a native multi-field input dialog. Unlike REAPER's GetUserInputs,
+// each field is returned separately, so values may contain commas.
+
+/*
+#cgo darwin CFLAGS: -I${SRCDIR}
+#cgo darwin LDFLAGS: -framework Cocoa
+#include <stdlib.h>
+#include "inputdialog.h"
+*/
+import "C"
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"unsafe"
+)
+
+// ErrInputCancelled is returned when the user cancels an input dialog
+var ErrInputCancelled = errors.New("user cancelled the dialog")
+
+// ShowInputDialog shows a modal dialog with a text field for each label, pre-filled with
+// defaults, and returns the values in the same order. message, if not empty, is shown
+// above the fields. Only macOS is implemented.
+func ShowInputDialog(title, message string, labels []string, defaults []string) ([]string, error) {
+	if runtime.GOOS != "darwin" {
+		return nil, fmt.Errorf("the native input dialog is currently only implemented for macOS")
+	}
+	if len(labels) == 0 {
+		return nil, fmt.Errorf("an input dialog needs at least one field")
+	}
+
+	cTitle := C.CString(title)
+	defer C.free(unsafe.Pointer(cTitle))
+
+	var cMessage *C.char
+	if message != "" {
+		cMessage = C.CString(message)
+		defer C.free(unsafe.Pointer(cMessage))
+	}
+
+	count := len(labels)
+	cLabels := allocStrings(labels)
+	defer freeStrings(cLabels)
+
+	values := make([]string, count)
+	copy(values, defaults)
+	cDefaults := allocStrings(values)
+	defer freeStrings(cDefaults)
+
+	// Filled in by the dialog with strings we free
+	cResults := unsafe.Slice((**C.char)(C.calloc(C.size_t(count), C.size_t(unsafe.Sizeof((*C.char)(nil))))), count)
+	defer func() {
+		for _, result := range cResults {
+			if result != nil {
+				C.free(unsafe.Pointer(result))
+			}
+		}
+		C.free(unsafe.Pointer(&cResults[0]))
+	}()
+
+	if !bool(C.ui_input_dialog(cTitle, cMessage, &cLabels[0], &cDefaults[0], C.int(count), &cResults[0])) {
+		return nil, ErrInputCancelled
+	}
+
+	for i, result := range cResults {
+		values[i] = C.GoString(result)
+	}
+	return values, nil
+}
+
+// allocStrings copies strings into a C array; release it with freeStrings
+func allocStrings(values []string) []*C.char {
+	array := unsafe.Slice((**C.char)(C.malloc(C.size_t(len(values))*C.size_t(unsafe.Sizeof((*C.char)(nil))))), len(values))
+	for i, value := range values {
+		array[i] = C.CString(value)
+	}
+	return array
+}
+
+// freeStrings frees an array made by allocStrings
+func freeStrings(array []*C.char) {
+	for _, value := range array {
+		C.free(unsafe.Pointer(value))
+	}
+	C.free(unsafe.Pointer(&array[0]))
+}
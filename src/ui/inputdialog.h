@@ -0,0 +1,21 @@
+#ifndef UI_INPUTDIALOG_H
+#define UI_INPUTDIALOG_H
+
+#include <stdbool.h>
+
+// Context structure for passing data between Go and Objective-C
+typedef struct {
+    const char* title;
+    const char* message;     // Optional text above the fields
+    const char** labels;
+    const char** defaults;
+    int count;
+    char** results;          // count entries allocated with malloc, freed by the caller
+    bool success;            // false when cancelled
+} InputDialogContext;
+
+// Function declarations that will be called from Go
+bool ui_input_dialog(const char* title, const char* message, const char** labels, const char** defaults,
+                     int count, char** results);
+
+#endif /* UI_INPUTDIALOG_H */
@@ -0,0 +1,124 @@
+#include <stdio.h>
+#include <stdlib.h>
+#include <stdbool.h>
+#include <string.h>
+#include "../c/logging.h"
+#import <Cocoa/Cocoa.h>
+#include "inputdialog.h"
+
+// Use our core logging system
+static void input_log_to_reaper(LogLevel level, const char* message) {
+    log_message_v(level, "inputDialog", message);
+}
+
+// Layout of the accessory view
+static const CGFloat kLabelWidth = 140;
+static const CGFloat kFieldWidth = 260;
+static const CGFloat kRowHeight = 24;
+static const CGFloat kRowSpacing = 8;
+
+// Show the dialog modally - internal
+static void input_dialog_on_main_thread(void* context) {
+    InputDialogContext* ctx = (InputDialogContext*)context;
+
+    @autoreleasepool {
+        @try {
+            NSAlert* alert = [[NSAlert alloc] init];
+            [alert setMessageText:[NSString stringWithUTF8String:ctx->title ? ctx->title : ""]];
+            if (ctx->message != NULL) {
+                [alert setInformativeText:[NSString stringWithUTF8String:ctx->message]];
+            }
+            [alert addButtonWithTitle:@"OK"];
+            [alert addButtonWithTitle:@"Cancel"];
+
+            // One label/field row per input, first row at the top
+            CGFloat height = ctx->count * kRowHeight + (ctx->count > 0 ? (ctx->count - 1) * kRowSpacing : 0);
+            NSView* accessory = [[NSView alloc] initWithFrame:NSMakeRect(0, 0, kLabelWidth + kFieldWidth, height)];
+            NSMutableArray* fields = [NSMutableArray arrayWithCapacity:ctx->count];
+
+            for (int i = 0; i < ctx->count; i++) {
+                CGFloat y = height - (i + 1) * kRowHeight - i * kRowSpacing;
+
+                NSTextField* label = [[NSTextField alloc] initWithFrame:NSMakeRect(0, y, kLabelWidth - 8, kRowHeight - 4)];
+                [label setStringValue:[NSString stringWithUTF8String:ctx->labels[i] ? ctx->labels[i] : ""]];
+                [label setBezeled:NO];
+                [label setDrawsBackground:NO];
+                [label setEditable:NO];
+                [label setSelectable:NO];
+                [label setAlignment:NSTextAlignmentRight];
+                [accessory addSubview:label];
+
+                NSTextField* field = [[NSTextField alloc] initWithFrame:NSMakeRect(kLabelWidth, y, kFieldWidth, kRowHeight)];
+                [field setStringValue:[NSString stringWithUTF8String:ctx->defaults[i] ? ctx->defaults[i] : ""]];
+                [accessory addSubview:field];
+                [fields addObject:field];
+            }
+
+            // Tab moves between the fields in order
+            for (NSUInteger i = 0; i + 1 < [fields count]; i++) {
+                [[fields objectAtIndex:i] setNextKeyView:[fields objectAtIndex:i + 1]];
+            }
+
+            [alert setAccessoryView:accessory];
+            [alert layout];
+            if ([fields count] > 0) {
+                [[alert window] setInitialFirstResponder:[fields objectAtIndex:0]];
+            }
+
+            NSModalResponse response = [alert runModal];
+            if (response != NSAlertFirstButtonReturn) {
+                input_log_to_reaper(LOG_DEBUG, "Input dialog cancelled");
+                ctx->success = false;
+                return;
+            }
+
+            for (int i = 0; i < ctx->count; i++) {
+                const char* utf8 = [[[fields objectAtIndex:i] stringValue] UTF8String];
+                ctx->results[i] = strdup(utf8 ? utf8 : "");
+            }
+            ctx->success = true;
+        }
+        @catch (NSException *exception) {
+            input_log_to_reaper(LOG_ERROR, "EXCEPTION showing input dialog");
+            NSLog(@"Exception: %@", exception);
+            ctx->success = false;
+        }
+    }
+}
+
+// Execute function on main thread - internal
+static bool input_execute_on_main_thread(void (*func)(void*), void* context) {
+    // Check if already on main thread (e.g. called from an action handler)
+    if ([NSThread isMainThread]) {
+        func(context);
+        return true;
+    }
+
+    __block bool completed = false;
+
+    dispatch_sync(dispatch_get_main_queue(), ^{
+        func(context);
+        completed = true;
+    });
+
+    return completed;
+}
+
+// Show a modal dialog with one text field per label - PUBLIC FUNCTION
+bool ui_input_dialog(const char* title, const char* message, const char** labels, const char** defaults,
+                     int count, char** results) {
+    InputDialogContext ctx;
+    memset(&ctx, 0, sizeof(ctx));
+    ctx.title = title;
+    ctx.message = message;
+    ctx.labels = labels;
+    ctx.defaults = defaults;
+    ctx.count = count;
+    ctx.results = results;
+
+    if (!input_execute_on_main_thread(input_dialog_on_main_thread, &ctx)) {
+        input_log_to_reaper(LOG_ERROR, "Failed to execute on main thread");
+        return false;
+    }
+    return ctx.success;
+}
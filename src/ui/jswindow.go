@@ -0,0 +1,240 @@
+package ui
+
+import (
+	"fmt"
+	"go-reaper/src/pkg/config"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"sync"
+	"unsafe"
+)
+
+// This file is an alternative window backend built on js_ReaScriptAPI. js_ReaScriptAPI
+// creates windows through REAPER's own toolkit (Win32, or SWELL on macOS and Linux), so
+// JSWindows work on every platform without native code of our own, are owned by REAPER's
+// main window and dock like any other REAPER window. js_ReaScriptAPI has no controls,
+// so a JSWindow is a container: parent other native views to its HWND or draw into it.
+
+// jsWindowClassName is the window class our JSWindows are created with
+const jsWindowClassName = "GoReaperJSWindow"
+
+// JSWindow is a window created through js_ReaScriptAPI. It is registered with Windows
+// under its ID while open.
+type JSWindow struct {
+	mutex    sync.Mutex
+	windowID string
+	title    string
+	hwnd     unsafe.Pointer
+	docked   bool
+	shown    bool // Whether Show was called, to apply the docking preference once
+}
+
+// IsJSWindowAvailable reports whether js_ReaScriptAPI is installed, so NewJSWindow can work
+func IsJSWindowAvailable() bool {
+	return reaper.IsJSAPIInstalled()
+}
+
+// NewJSWindow creates a hidden window owned by REAPER's main window and registers it
+// with Windows under windowID; call Show or Dock to display it. A default size in the
+// window's preferences replaces width and height.
+func NewJSWindow(windowID, title string, width, height int) (*JSWindow, error) {
+	if !IsJSWindowAvailable() {
+		return nil, reaper.ErrJSAPINotInstalled
+	}
+	if _, open := Windows.Lookup(windowID); open {
+		return nil, fmt.Errorf("a window with ID %q is already open", windowID)
+	}
+
+	owner, err := reaper.GetMainHwnd()
+	if err != nil {
+		return nil, err
+	}
+
+	preferences := config.GetWindowPreferences(windowID)
+	if preferences.Width > 0 {
+		width = preferences.Width
+	}
+	if preferences.Height > 0 {
+		height = preferences.Height
+	}
+
+	// Start centred over REAPER when its position is known
+	x, y := 100, 100
+	if left, top, ownerWidth, ownerHeight, err := reaper.JSWindowGetRect(owner); err == nil {
+		x = left + (ownerWidth-width)/2
+		y = top + (ownerHeight-height)/2
+	}
+
+	hwnd, err := reaper.JSWindowCreate(title, jsWindowClassName, x, y, width, height, owner)
+	if err != nil {
+		return nil, err
+	}
+	reaper.JSWindowShow(hwnd, reaper.WindowHide)
+
+	w := &JSWindow{windowID: windowID, title: title, hwnd: hwnd}
+	if err := Windows.Register(windowID, w); err != nil {
+		reaper.JSWindowDestroy(hwnd)
+		return nil, err
+	}
+
+	logger.Debug("Created js_ReaScriptAPI window %s (%s)", windowID, title)
+	return w, nil
+}
+
+// ID returns the ID the window is registered under
+func (w *JSWindow) ID() string {
+	return w.windowID
+}
+
+// Title returns the window's title
+func (w *JSWindow) Title() string {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.title
+}
+
+// HWND returns the native window handle, for parenting views to it
+func (w *JSWindow) HWND() unsafe.Pointer {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.hwnd
+}
+
+// SetTitle changes the window's title
+func (w *JSWindow) SetTitle(title string) error {
+	if err := reaper.JSWindowSetTitle(w.HWND(), title); err != nil {
+		return err
+	}
+
+	w.mutex.Lock()
+	w.title = title
+	w.mutex.Unlock()
+	return nil
+}
+
+// SetFrame moves and resizes a floating window, in screen coordinates
+func (w *JSWindow) SetFrame(x, y, width, height int) error {
+	if w.IsDocked() {
+		return fmt.Errorf("window %q is docked", w.windowID)
+	}
+	return reaper.JSWindowSetPosition(w.HWND(), x, y, width, height)
+}
+
+// Show shows the window and brings it to the front. The first time, a window whose
+// preferences say so opens in REAPER's docker.
+func (w *JSWindow) Show() error {
+	w.mutex.Lock()
+	first := !w.shown
+	w.shown = true
+	w.mutex.Unlock()
+
+	if first && config.GetWindowPreferences(w.windowID).Docked {
+		err := w.Dock(false)
+		if err == nil {
+			return nil
+		}
+		logger.Warning("Failed to open window %s docked: %v", w.windowID, err)
+	}
+	if w.IsDocked() {
+		return reaper.DockWindowActivate(w.HWND())
+	}
+	return reaper.JSWindowShow(w.HWND(), reaper.WindowShow)
+}
+
+// Hide hides a floating window without closing it
+func (w *JSWindow) Hide() error {
+	if w.IsDocked() {
+		return fmt.Errorf("window %q is docked", w.windowID)
+	}
+	return reaper.JSWindowShow(w.HWND(), reaper.WindowHide)
+}
+
+// Dock moves the window into REAPER's docker. allowShow lets REAPER open the docker if
+// it is hidden.
+func (w *JSWindow) Dock(allowShow bool) error {
+	if w.IsDocked() {
+		return reaper.DockWindowActivate(w.HWND())
+	}
+
+	if err := reaper.DockWindowAdd(w.HWND(), w.Title(), dockIdentPrefix+w.windowID, allowShow); err != nil {
+		return fmt.Errorf("failed to dock window %q: %v", w.windowID, err)
+	}
+
+	w.mutex.Lock()
+	w.docked = true
+	w.mutex.Unlock()
+
+	logger.Debug("Docked js_ReaScriptAPI window %s", w.windowID)
+	return nil
+}
+
+// Undock takes the window out of REAPER's docker and shows it floating
+func (w *JSWindow) Undock() error {
+	if !w.IsDocked() {
+		return nil
+	}
+
+	if err := reaper.DockWindowRemove(w.HWND()); err != nil {
+		return fmt.Errorf("failed to undock window %q: %v", w.windowID, err)
+	}
+
+	w.mutex.Lock()
+	w.docked = false
+	w.mutex.Unlock()
+
+	logger.Debug("Undocked js_ReaScriptAPI window %s", w.windowID)
+	return w.Show()
+}
+
+// IsDocked reports whether the window is in REAPER's docker
+func (w *JSWindow) IsDocked() bool {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.docked
+}
+
+// ToggleDock docks a floating window or undocks a docked one, and remembers the choice
+// for the next time the window opens
+func (w *JSWindow) ToggleDock() error {
+	var err error
+	if w.IsDocked() {
+		err = w.Undock()
+	} else {
+		err = w.Dock(true)
+	}
+	if err != nil {
+		return err
+	}
+	rememberDocked(w.windowID, w.IsDocked())
+	return nil
+}
+
+// Close destroys the window if it is open
+func (w *JSWindow) Close() {
+	w.mutex.Lock()
+	hwnd, docked := w.hwnd, w.docked
+	w.hwnd, w.docked = nil, false
+	w.mutex.Unlock()
+
+	if hwnd == nil {
+		return
+	}
+
+	if docked {
+		if err := reaper.DockWindowRemove(hwnd); err != nil {
+			logger.Warning("Failed to remove %s from the docker: %v", w.windowID, err)
+		}
+	}
+	if reaper.JSWindowIsWindow(hwnd) {
+		if err := reaper.JSWindowDestroy(hwnd); err != nil {
+			logger.Warning("Failed to destroy window %s: %v", w.windowID, err)
+		}
+	}
+
+	Windows.Closed(w.windowID)
+}
+
+// IsOpen reports whether the window still exists; it is false once the user closes it
+func (w *JSWindow) IsOpen() bool {
+	return reaper.JSWindowIsWindow(w.HWND())
+}
@@ -0,0 +1,191 @@
+package ui
+
+// This file implements a small layout system: vertical and horizontal stacks size
+// widgets to fit their text and place them with consistent spacing, so windows don't
+// break when a label is longer than expected. Widgets laid out by a stack can be added
+// with a frame holding just their minimum width and height.
+
+/*
+#cgo darwin CFLAGS: -I${SRCDIR}
+#include "window.h"
+*/
+import "C"
+import "fmt"
+
+// Spacing in points, from the macOS human interface guidelines
+const (
+	LayoutMargin  = 12 // Between a window's edges and its contents
+	LayoutSpacing = 8  // Between neighbouring widgets
+)
+
+// Layout is something a stack arranges: a widget, a spacer or another stack
+type Layout interface {
+	// measure returns the smallest size the layout fits in
+	measure() (width, height float64)
+	// place positions the layout within frame
+	place(frame Rect) error
+}
+
+// measure implements Layout for widgets
+func (wd *Widget) measure() (width, height float64) {
+	return wd.FittingSize()
+}
+
+// place implements Layout for widgets
+func (wd *Widget) place(frame Rect) error {
+	return wd.SetFrame(frame)
+}
+
+// spacer is an empty layout
+type spacer struct{}
+
+func (spacer) measure() (width, height float64) { return 0, 0 }
+func (spacer) place(frame Rect) error           { return nil }
+
+// Spacer returns an empty layout; add it to a stack with Fill to push the items after
+// it to the far end, e.g. to right-align buttons
+func Spacer() Layout {
+	return spacer{}
+}
+
+// Stack arranges layouts in a column or a row. Items in a column are stretched to its
+// width; items in a row keep their height and are centered, except nested stacks,
+// which are stretched to the row's height. Space left over along the stack goes to
+// the items added with Fill.
+type Stack struct {
+	vertical bool
+	padding  float64
+	spacing  float64
+	items    []stackItem
+}
+
+// stackItem is a layout in a stack
+type stackItem struct {
+	layout Layout
+	fill   bool // Shares the stack's spare space
+}
+
+// NewVStack creates a column with padding around its items and spacing between them
+func NewVStack(padding, spacing float64) *Stack {
+	return &Stack{vertical: true, padding: padding, spacing: spacing}
+}
+
+// NewHStack creates a row with padding around its items and spacing between them
+func NewHStack(padding, spacing float64) *Stack {
+	return &Stack{padding: padding, spacing: spacing}
+}
+
+// Add appends items at their fitting size
+func (s *Stack) Add(items ...Layout) *Stack {
+	for _, item := range items {
+		s.items = append(s.items, stackItem{layout: item})
+	}
+	return s
+}
+
+// Fill appends an item that grows to take the stack's spare space
+func (s *Stack) Fill(item Layout) *Stack {
+	s.items = append(s.items, stackItem{layout: item, fill: true})
+	return s
+}
+
+// axes orders a width and height as the stack's main and cross axis sizes
+func (s *Stack) axes(width, height float64) (along, across float64) {
+	if s.vertical {
+		return height, width
+	}
+	return width, height
+}
+
+// measure implements Layout
+func (s *Stack) measure() (width, height float64) {
+	var along, across float64
+	for i, item := range s.items {
+		itemAlong, itemAcross := s.axes(item.layout.measure())
+		along += itemAlong
+		if i > 0 {
+			along += s.spacing
+		}
+		across = max(across, itemAcross)
+	}
+
+	along += 2 * s.padding
+	across += 2 * s.padding
+	if s.vertical {
+		return across, along
+	}
+	return along, across
+}
+
+// place implements Layout
+func (s *Stack) place(frame Rect) error {
+	inner := Rect{
+		X:      frame.X + s.padding,
+		Y:      frame.Y + s.padding,
+		Width:  max(frame.Width-2*s.padding, 0),
+		Height: max(frame.Height-2*s.padding, 0),
+	}
+	innerAlong, innerAcross := s.axes(inner.Width, inner.Height)
+
+	// Measure once, and share what's left among the fill items
+	sizes := make([][2]float64, len(s.items))
+	used, fills := 0.0, 0
+	for i, item := range s.items {
+		along, across := s.axes(item.layout.measure())
+		sizes[i] = [2]float64{along, across}
+		used += along
+		if i > 0 {
+			used += s.spacing
+		}
+		if item.fill {
+			fills++
+		}
+	}
+	extra := 0.0
+	if fills > 0 && innerAlong > used {
+		extra = (innerAlong - used) / float64(fills)
+	}
+
+	offset := 0.0
+	for i, item := range s.items {
+		along, across := sizes[i][0], sizes[i][1]
+		if item.fill {
+			along += extra
+		}
+
+		// Columns stretch their items; rows center them unless they're stacks
+		crossOffset := 0.0
+		if _, nested := item.layout.(*Stack); s.vertical || nested {
+			across = innerAcross
+		} else {
+			across = min(across, innerAcross)
+			crossOffset = (innerAcross - across) / 2
+		}
+
+		itemFrame := Rect{X: inner.X + offset, Y: inner.Y + crossOffset, Width: along, Height: across}
+		if s.vertical {
+			itemFrame = Rect{X: inner.X + crossOffset, Y: inner.Y + offset, Width: across, Height: along}
+		}
+		if err := item.layout.place(itemFrame); err != nil {
+			return err
+		}
+		offset += along + s.spacing
+	}
+	return nil
+}
+
+// SetLayout sizes and places the window's widgets with layout, which fills the window's
+// content area. The window grows if the layout doesn't fit, and any spare space goes
+// to the items added with Fill. Call it after adding the widgets, before Show.
+func (w *Window) SetLayout(layout Layout) error {
+	width, height := layout.measure()
+	width, height = max(width, w.width), max(height, w.height)
+
+	if width != w.width || height != w.height {
+		if !bool(C.ui_window_set_content_size(C.int(w.id), C.double(width), C.double(height))) {
+			return fmt.Errorf("failed to resize window %q", w.title)
+		}
+		w.width, w.height = width, height
+	}
+	return layout.place(Rect{Width: width, Height: height})
+}
@@ -0,0 +1,78 @@
+package ui
+
+// This file runs Go closures on the main (UI) thread. Closures are registered under a
+// handle, the handle is queued on the main dispatch queue, and the exported trampoline
+// looks the closure up and runs it there. Only macOS is implemented; elsewhere the
+// closure runs on the calling goroutine.
+
+/*
+#cgo darwin CFLAGS: -I${SRCDIR}
+#cgo darwin LDFLAGS: -framework Foundation
+#include "mainthread.h"
+*/
+import "C"
+import (
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"runtime"
+	"sync"
+)
+
+// Closures waiting to run, by handle
+var (
+	mainThreadMutex  sync.Mutex
+	mainThreadFuncs          = make(map[uintptr]func())
+	nextMainThreadID uintptr = 1
+)
+
+// RunOnMainThread runs fn on the main thread and waits for it to finish. Called from the
+// main thread it runs fn directly. The caller must not hold locks the main thread may be
+// waiting for.
+func RunOnMainThread(fn func()) {
+	if runtime.GOOS != "darwin" {
+		fn()
+		return
+	}
+	C.ui_dispatch_sync(C.uintptr_t(registerMainThreadFunc(fn)))
+}
+
+// RunOnMainThreadAsync queues fn to run on the main thread and returns immediately.
+// Use it to put off UI work until the current event is handled, e.g. closing a window
+// from its own button. Goroutines hand REAPER work back with core.MainThread instead.
+func RunOnMainThreadAsync(fn func()) {
+	if runtime.GOOS != "darwin" {
+		fn()
+		return
+	}
+	C.ui_dispatch_async(C.uintptr_t(registerMainThreadFunc(fn)))
+}
+
+// registerMainThreadFunc stores fn until the trampoline runs it
+func registerMainThreadFunc(fn func()) uintptr {
+	mainThreadMutex.Lock()
+	defer mainThreadMutex.Unlock()
+
+	handle := nextMainThreadID
+	nextMainThreadID++
+	mainThreadFuncs[handle] = fn
+	return handle
+}
+
+// Called on the main thread for each queued closure
+//
+//export go_ui_run_on_main
+func go_ui_run_on_main(handle C.uintptr_t) {
+	mainThreadMutex.Lock()
+	fn, ok := mainThreadFuncs[uintptr(handle)]
+	delete(mainThreadFuncs, uintptr(handle))
+	mainThreadMutex.Unlock()
+
+	if !ok {
+		logger.Warning("Main thread dispatch for unknown handle %d", uintptr(handle))
+		return
+	}
+
+	// A panic must not unwind into the dispatch queue
+	defer reaper.Recover("main thread closure")
+	fn()
+}
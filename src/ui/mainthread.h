@@ -0,0 +1,13 @@
+#ifndef UI_MAINTHREAD_H
+#define UI_MAINTHREAD_H
+
+#include <stdint.h>
+
+// Function declarations that will be called from Go
+void ui_dispatch_async(uintptr_t handle);
+void ui_dispatch_sync(uintptr_t handle);
+
+// Callback from Objective-C to Go, run on the main thread
+extern void go_ui_run_on_main(uintptr_t handle);
+
+#endif /* UI_MAINTHREAD_H */
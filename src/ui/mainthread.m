@@ -0,0 +1,23 @@
+#include <stdint.h>
+#import <Foundation/Foundation.h>
+#include "mainthread.h"
+
+// Queue a Go closure on the main thread and return immediately - PUBLIC FUNCTION
+void ui_dispatch_async(uintptr_t handle) {
+    dispatch_async(dispatch_get_main_queue(), ^{
+        go_ui_run_on_main(handle);
+    });
+}
+
+// Run a Go closure on the main thread and wait for it - PUBLIC FUNCTION
+void ui_dispatch_sync(uintptr_t handle) {
+    // dispatch_sync to the main queue from the main thread would deadlock
+    if ([NSThread isMainThread]) {
+        go_ui_run_on_main(handle);
+        return;
+    }
+
+    dispatch_sync(dispatch_get_main_queue(), ^{
+        go_ui_run_on_main(handle);
+    });
+}
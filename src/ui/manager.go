@@ -0,0 +1,178 @@
+package ui
+
+import (
+	"fmt"
+	"go-reaper/src/pkg/logger"
+	"sort"
+	"sync"
+)
+
+// ManagedWindow is any window the WindowManager can track and close
+type ManagedWindow interface {
+	Close()
+	IsOpen() bool
+}
+
+// WindowFuncs adapts a pair of functions to ManagedWindow, for windows implemented
+// outside this package with their own globals
+type WindowFuncs struct {
+	CloseFunc  func()
+	IsOpenFunc func() bool
+}
+
+// Close calls CloseFunc
+func (f WindowFuncs) Close() {
+	if f.CloseFunc != nil {
+		f.CloseFunc()
+	}
+}
+
+// IsOpen calls IsOpenFunc
+func (f WindowFuncs) IsOpen() bool {
+	return f.IsOpenFunc != nil && f.IsOpenFunc()
+}
+
+// managedEntry is a registered window and the callbacks to run when it closes
+type managedEntry struct {
+	window  ManagedWindow
+	onClose []func()
+}
+
+// WindowManager tracks open windows by ID so they can be found, closed together on
+// unload, and observed for closing
+type WindowManager struct {
+	mutex   sync.Mutex
+	entries map[string]*managedEntry
+}
+
+// Windows is the window manager used by the extension
+var Windows = NewWindowManager()
+
+// NewWindowManager creates an empty window manager
+func NewWindowManager() *WindowManager {
+	return &WindowManager{entries: make(map[string]*managedEntry)}
+}
+
+// Register adds a window under an ID. An ID can be reused once its window has closed.
+func (m *WindowManager) Register(id string, window ManagedWindow) error {
+	m.prune()
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if _, ok := m.entries[id]; ok {
+		return fmt.Errorf("a window with ID %q is already open", id)
+	}
+
+	m.entries[id] = &managedEntry{window: window}
+	logger.Debug("Registered window %q", id)
+	return nil
+}
+
+// Lookup returns the open window registered under an ID
+func (m *WindowManager) Lookup(id string) (ManagedWindow, bool) {
+	m.prune()
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	entry, ok := m.entries[id]
+	if !ok {
+		return nil, false
+	}
+	return entry.window, true
+}
+
+// IDs returns the IDs of all open windows, sorted
+func (m *WindowManager) IDs() []string {
+	m.prune()
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	ids := make([]string, 0, len(m.entries))
+	for id := range m.entries {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// OnClose adds a callback to run when the window registered under id closes
+func (m *WindowManager) OnClose(id string, callback func()) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	entry, ok := m.entries[id]
+	if !ok {
+		return fmt.Errorf("no window with ID %q", id)
+	}
+	entry.onClose = append(entry.onClose, callback)
+	return nil
+}
+
+// Closed unregisters a window and runs its close callbacks. Windows call this when they
+// close, however they were closed; calling it for an unknown ID does nothing.
+func (m *WindowManager) Closed(id string) {
+	m.mutex.Lock()
+	entry, ok := m.entries[id]
+	delete(m.entries, id)
+	m.mutex.Unlock()
+
+	if !ok {
+		return
+	}
+
+	logger.Debug("Window %q closed", id)
+	for _, callback := range entry.onClose {
+		callback()
+	}
+}
+
+// Close closes the window registered under an ID
+func (m *WindowManager) Close(id string) {
+	m.mutex.Lock()
+	entry, ok := m.entries[id]
+	m.mutex.Unlock()
+
+	if !ok {
+		return
+	}
+
+	entry.window.Close()
+
+	// Not every window reports back, so make sure the callbacks run
+	m.Closed(id)
+}
+
+// CloseAll closes every registered window, e.g. when the plugin unloads
+func (m *WindowManager) CloseAll() {
+	m.mutex.Lock()
+	ids := make([]string, 0, len(m.entries))
+	for id := range m.entries {
+		ids = append(ids, id)
+	}
+	m.mutex.Unlock()
+
+	logger.Info("Closing %d open window(s)", len(ids))
+	for _, id := range ids {
+		m.Close(id)
+	}
+}
+
+// prune forgets windows that closed without reporting it
+func (m *WindowManager) prune() {
+	m.mutex.Lock()
+	entries := make(map[string]ManagedWindow, len(m.entries))
+	for id, entry := range m.entries {
+		entries[id] = entry.window
+	}
+	m.mutex.Unlock()
+
+	// IsOpen may wait for the main thread, which may be waiting for the lock
+	for id, window := range entries {
+		if !window.IsOpen() {
+			m.Closed(id)
+		}
+	}
+}
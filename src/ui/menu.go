@@ -0,0 +1,92 @@
+package ui
+
+// This file adds submenus of our actions to REAPER's menus. On macOS REAPER's menus are
+// NSMenus, so items are added natively and chosen items run the action's handler or the
+// item's own callback.
+
+/*
+#cgo darwin CFLAGS: -I${SRCDIR}
+#cgo darwin LDFLAGS: -framework Cocoa
+#include <stdlib.h>
+#include "menu.h"
+*/
+import "C"
+import (
+	"fmt"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"runtime"
+	"sync"
+	"unsafe"
+)
+
+// MenuItem is an entry in a submenu. An item with an empty Title is a separator.
+type MenuItem struct {
+	Title    string
+	ActionID string // The action run when the item is chosen
+	OnSelect func() // Run instead of an action, e.g. to act on what the menu was opened on
+}
+
+var (
+	menuMutex sync.Mutex
+	// menuItems holds each item added, indexed by the item's tag
+	menuItems []MenuItem
+)
+
+// AddSubmenu appends a submenu titled title to menu, the native menu handle REAPER
+// passes to menu hooks. Only macOS is implemented.
+func AddSubmenu(menu unsafe.Pointer, title string, items []MenuItem) error {
+	if runtime.GOOS != "darwin" {
+		return fmt.Errorf("menu items are currently only implemented for macOS")
+	}
+	if len(items) == 0 {
+		return fmt.Errorf("a submenu needs at least one item")
+	}
+
+	cTitle := C.CString(title)
+	defer C.free(unsafe.Pointer(cTitle))
+
+	titles := make([]string, len(items))
+	tags := make([]C.int, len(items))
+
+	menuMutex.Lock()
+	for i, item := range items {
+		titles[i] = item.Title
+		tags[i] = C.int(len(menuItems))
+		menuItems = append(menuItems, item)
+	}
+	menuMutex.Unlock()
+
+	cTitles := allocStrings(titles)
+	defer freeStrings(cTitles)
+
+	if !bool(C.ui_menu_add_submenu(menu, cTitle, &cTitles[0], &tags[0], C.int(len(items)))) {
+		return fmt.Errorf("failed to add submenu %q", title)
+	}
+	return nil
+}
+
+// Callback when a menu item is chosen
+//
+//export go_ui_menu_item_selected
+func go_ui_menu_item_selected(tag C.int) {
+	defer reaper.Recover("go_ui_menu_item_selected")
+
+	menuMutex.Lock()
+	var item MenuItem
+	if int(tag) >= 0 && int(tag) < len(menuItems) {
+		item = menuItems[tag]
+	}
+	menuMutex.Unlock()
+
+	switch {
+	case item.OnSelect != nil:
+		item.OnSelect()
+	case item.ActionID != "":
+		if err := reaper.RunAction(item.ActionID); err != nil {
+			logger.Error("Failed to run %s from the menu: %v", item.ActionID, err)
+		}
+	default:
+		logger.Warning("Menu item %d has no action", int(tag))
+	}
+}
@@ -0,0 +1,22 @@
+#ifndef UI_MENU_H
+#define UI_MENU_H
+
+#include <stdbool.h>
+
+// Context structure for passing data between Go and Objective-C
+typedef struct {
+    void* menu;              // NSMenu to add the submenu to
+    const char* title;
+    const char** titles;     // Item titles; NULL or empty for a separator
+    const int* tags;         // Tag passed back to Go when each item is chosen
+    int count;
+    bool success;
+} MenuContext;
+
+// Function declarations that will be called from Go
+bool ui_menu_add_submenu(void* menu, const char* title, const char** titles, const int* tags, int count);
+
+// Callback from Objective-C to Go when an item is chosen
+extern void go_ui_menu_item_selected(int tag);
+
+#endif /* UI_MENU_H */
@@ -0,0 +1,108 @@
+#include <stdio.h>
+#include <stdlib.h>
+#include <stdbool.h>
+#include <string.h>
+#include "../c/logging.h"
+#import <Cocoa/Cocoa.h>
+#include "menu.h"
+
+// Use our core logging system
+static void menu_log_to_reaper(LogLevel level, const char* message) {
+    log_message_v(level, "menu", message);
+}
+
+// Target for our menu items, forwarding the chosen item's tag to Go
+@interface RPRMenuTarget : NSObject
+- (void)itemSelected:(id)sender;
+@end
+
+@implementation RPRMenuTarget
+- (void)itemSelected:(id)sender {
+    go_ui_menu_item_selected((int)[sender tag]);
+}
+@end
+
+// Menu items hold their target weakly, so keep one alive for the process
+static RPRMenuTarget* g_menuTarget = nil;
+
+// Add the submenu - internal
+static void menu_add_submenu_on_main_thread(void* context) {
+    MenuContext* ctx = (MenuContext*)context;
+
+    @autoreleasepool {
+        @try {
+            NSMenu* menu = (__bridge NSMenu*)ctx->menu;
+            if (g_menuTarget == nil) {
+                g_menuTarget = [[RPRMenuTarget alloc] init];
+            }
+
+            NSString* title = [NSString stringWithUTF8String:ctx->title ? ctx->title : ""];
+            NSMenu* submenu = [[NSMenu alloc] initWithTitle:title];
+
+            for (int i = 0; i < ctx->count; i++) {
+                const char* itemTitle = ctx->titles[i];
+                if (itemTitle == NULL || itemTitle[0] == '\0') {
+                    [submenu addItem:[NSMenuItem separatorItem]];
+                    continue;
+                }
+
+                NSMenuItem* item = [[NSMenuItem alloc] initWithTitle:[NSString stringWithUTF8String:itemTitle]
+                                                              action:@selector(itemSelected:)
+                                                       keyEquivalent:@""];
+                [item setTarget:g_menuTarget];
+                [item setTag:ctx->tags[i]];
+                [submenu addItem:item];
+            }
+
+            NSMenuItem* parent = [[NSMenuItem alloc] initWithTitle:title action:nil keyEquivalent:@""];
+            [parent setSubmenu:submenu];
+            [menu addItem:parent];
+            ctx->success = true;
+        }
+        @catch (NSException *exception) {
+            menu_log_to_reaper(LOG_ERROR, "EXCEPTION adding submenu");
+            NSLog(@"Exception: %@", exception);
+            ctx->success = false;
+        }
+    }
+}
+
+// Execute function on main thread - internal
+static bool menu_execute_on_main_thread(void (*func)(void*), void* context) {
+    // Check if already on main thread (menu hooks are called there)
+    if ([NSThread isMainThread]) {
+        func(context);
+        return true;
+    }
+
+    __block bool completed = false;
+
+    dispatch_sync(dispatch_get_main_queue(), ^{
+        func(context);
+        completed = true;
+    });
+
+    return completed;
+}
+
+// Append a submenu of items to a menu - PUBLIC FUNCTION
+bool ui_menu_add_submenu(void* menu, const char* title, const char** titles, const int* tags, int count) {
+    if (menu == NULL) {
+        menu_log_to_reaper(LOG_ERROR, "No menu to add a submenu to");
+        return false;
+    }
+
+    MenuContext ctx;
+    memset(&ctx, 0, sizeof(ctx));
+    ctx.menu = menu;
+    ctx.title = title;
+    ctx.titles = titles;
+    ctx.tags = tags;
+    ctx.count = count;
+
+    if (!menu_execute_on_main_thread(menu_add_submenu_on_main_thread, &ctx)) {
+        menu_log_to_reaper(LOG_ERROR, "Failed to execute on main thread");
+        return false;
+    }
+    return ctx.success;
+}
@@ -0,0 +1,128 @@
+package ui
+
+// This file implements a progress dialog for long operations: a message, a progress bar
+// and a Cancel button, built on Window.
+
+import (
+	"fmt"
+	"go-reaper/src/pkg/logger"
+	"sync"
+	"sync/atomic"
+)
+
+// progressCount numbers progress dialogs so several can be open at once
+var progressCount int64
+
+// Progress is an open progress dialog. Its methods may be called from any goroutine, so a
+// long operation should run on a goroutine and report back; while the main thread is
+// blocked the dialog cannot redraw.
+type Progress struct {
+	window  *Window
+	message *Widget
+	bar     *Widget
+	cancel  *Widget
+
+	cancelled chan struct{}
+	once      sync.Once
+}
+
+// ShowProgress opens a progress dialog titled title at 0%. Call Close when the operation
+// finishes, including after it has been cancelled.
+func ShowProgress(title string) (*Progress, error) {
+	windowID := fmt.Sprintf("progress-%d", atomic.AddInt64(&progressCount, 1))
+	window, err := NewWindow(windowID, title, 420, 130)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Progress{window: window, cancelled: make(chan struct{})}
+
+	// Close the half-built window if any widget fails
+	ok := false
+	defer func() {
+		if !ok {
+			p.once.Do(func() {})
+			window.Close()
+		}
+	}()
+
+	if p.message, err = window.AddLabel(Rect{X: 20, Y: 20, Width: 380, Height: 20}, "Working..."); err != nil {
+		return nil, err
+	}
+	if p.bar, err = window.AddProgressBar(Rect{X: 20, Y: 50, Width: 380, Height: 20}); err != nil {
+		return nil, err
+	}
+	if p.cancel, err = window.AddButton(Rect{X: 310, Y: 84, Width: 90, Height: 30}, "Cancel", p.requestCancel); err != nil {
+		return nil, err
+	}
+
+	// Closing the window from its title bar counts as cancelling
+	if err := Windows.OnClose(windowID, p.requestCancel); err != nil {
+		return nil, err
+	}
+
+	if err := window.Show(); err != nil {
+		return nil, err
+	}
+
+	ok = true
+	return p, nil
+}
+
+// SetProgress moves the bar to pct (0-100) and, unless msg is empty, replaces the message
+func (p *Progress) SetProgress(pct float64, msg string) error {
+	if pct < 0 {
+		pct = 0
+	} else if pct > 100 {
+		pct = 100
+	}
+	if err := p.bar.SetValue(pct); err != nil {
+		return err
+	}
+	if msg != "" {
+		return p.message.SetText(msg)
+	}
+	return nil
+}
+
+// Cancelled returns a channel that is closed when the user cancels, either with the Cancel
+// button or by closing the dialog. It is never closed by Close.
+func (p *Progress) Cancelled() <-chan struct{} {
+	return p.cancelled
+}
+
+// IsCancelled reports whether the user has cancelled
+func (p *Progress) IsCancelled() bool {
+	select {
+	case <-p.cancelled:
+		return true
+	default:
+		return false
+	}
+}
+
+// Close closes the dialog
+func (p *Progress) Close() {
+	// Once the owner closes the dialog, the window closing is not a cancellation
+	p.once.Do(func() {})
+	p.window.Close()
+}
+
+// requestCancel signals cancellation once; the dialog stays open until its owner calls
+// Close, so the operation can report that it is stopping
+func (p *Progress) requestCancel() {
+	p.once.Do(func() {
+		logger.Debug("Progress %q cancelled", p.window.Title())
+		close(p.cancelled)
+
+		if !p.window.IsOpen() {
+			return
+		}
+		if err := p.message.SetText("Cancelling..."); err != nil {
+			logger.Warning("Failed to update progress message: %v", err)
+		}
+		if err := p.cancel.SetEnabled(false); err != nil {
+			logger.Warning("Failed to disable Cancel button: %v", err)
+		}
+	})
+}
@@ -0,0 +1,103 @@
+package ui
+
+// This file picks the colors the extension's windows draw with, so they match a dark
+// or light REAPER theme, and reports the display's scale factor for drawing hairlines
+// and images at the screen's resolution.
+
+/*
+#cgo darwin CFLAGS: -I${SRCDIR}
+#cgo darwin LDFLAGS: -framework Cocoa
+#include "theme.h"
+*/
+import "C"
+import (
+	"go-reaper/src/pkg/config"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"runtime"
+)
+
+// themeBackgroundKey is the REAPER theme color whose brightness decides dark or light
+const themeBackgroundKey = "col_main_bg2"
+
+// Color is an RGBA color with components from 0 to 1
+type Color struct {
+	R, G, B, A float64
+}
+
+// Theme is the appearance windows draw with
+type Theme struct {
+	Dark  bool
+	Scale float64 // Backing scale factor: 2 on Retina displays, 1 otherwise
+
+	Background    Color // Behind custom drawing
+	Text          Color
+	SecondaryText Color // Labels and captions
+	Grid          Color
+	Axis          Color // Reference lines, e.g. 0 dB
+	Accent        Color // Curves and highlights
+}
+
+// Palettes for each appearance; the dark one matches REAPER's default theme
+var (
+	darkTheme = Theme{
+		Dark:          true,
+		Background:    Color{0.12, 0.12, 0.12, 1},
+		Text:          Color{0.9, 0.9, 0.9, 1},
+		SecondaryText: Color{0.6, 0.6, 0.6, 1},
+		Grid:          Color{0.25, 0.25, 0.25, 1},
+		Axis:          Color{0.45, 0.45, 0.45, 1},
+		Accent:        Color{0.3, 0.7, 1.0, 1},
+	}
+	lightTheme = Theme{
+		Background:    Color{0.96, 0.96, 0.96, 1},
+		Text:          Color{0.1, 0.1, 0.1, 1},
+		SecondaryText: Color{0.4, 0.4, 0.4, 1},
+		Grid:          Color{0.82, 0.82, 0.82, 1},
+		Axis:          Color{0.6, 0.6, 0.6, 1},
+		Accent:        Color{0.1, 0.45, 0.85, 1},
+	}
+)
+
+// CurrentTheme returns the theme windows should use now. The appearance setting wins;
+// on auto, a dark REAPER theme means dark, and if the theme can't be read the system
+// appearance decides. Call it on the main thread.
+func CurrentTheme() Theme {
+	theme := lightTheme
+	if isDarkAppearance() {
+		theme = darkTheme
+	}
+	theme.Scale = 1
+	if runtime.GOOS == "darwin" {
+		theme.Scale = float64(C.ui_theme_scale_factor())
+	}
+	return theme
+}
+
+// isDarkAppearance decides between the dark and light palettes
+func isDarkAppearance() bool {
+	switch config.GetAppearance() {
+	case config.AppearanceDark:
+		return true
+	case config.AppearanceLight:
+		return false
+	}
+
+	background, ok, err := reaper.GetThemeColor(themeBackgroundKey)
+	if err != nil {
+		logger.Debug("Could not read REAPER's theme colors: %v", err)
+	}
+	if err == nil && ok {
+		return luminance(background) < 0.5
+	}
+
+	if runtime.GOOS == "darwin" {
+		return bool(C.ui_theme_system_dark())
+	}
+	return false
+}
+
+// luminance is a color's relative brightness from 0 to 1
+func luminance(color reaper.Color) float64 {
+	return (0.2126*float64(color.R) + 0.7152*float64(color.G) + 0.0722*float64(color.B)) / 255
+}
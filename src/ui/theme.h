@@ -0,0 +1,10 @@
+#ifndef UI_THEME_H
+#define UI_THEME_H
+
+#include <stdbool.h>
+
+// Function declarations that will be called from Go
+bool ui_theme_system_dark(void);     // Whether the system appearance is dark
+double ui_theme_scale_factor(void);  // Backing scale factor of the main screen, e.g. 2 on Retina displays
+
+#endif /* UI_THEME_H */
@@ -0,0 +1,39 @@
+#include <stdbool.h>
+#import <Cocoa/Cocoa.h>
+#include "theme.h"
+
+// Run a block on the main thread, where AppKit state may be read
+static void theme_run_on_main_thread(void (^block)(void)) {
+    if ([NSThread isMainThread]) {
+        block();
+    } else {
+        dispatch_sync(dispatch_get_main_queue(), block);
+    }
+}
+
+// Check whether the application's effective appearance is dark - PUBLIC FUNCTION
+bool ui_theme_system_dark(void) {
+    __block bool dark = false;
+    theme_run_on_main_thread(^{
+        @autoreleasepool {
+            if (@available(macOS 10.14, *)) {
+                NSAppearance* appearance = [NSApp effectiveAppearance];
+                NSAppearanceName match = [appearance bestMatchFromAppearancesWithNames:@[NSAppearanceNameAqua, NSAppearanceNameDarkAqua]];
+                dark = [match isEqualToString:NSAppearanceNameDarkAqua];
+            }
+        }
+    });
+    return dark;
+}
+
+// Get the main screen's backing scale factor - PUBLIC FUNCTION
+double ui_theme_scale_factor(void) {
+    __block double scale = 1.0;
+    theme_run_on_main_thread(^{
+        NSScreen* screen = [NSScreen mainScreen];
+        if (screen != nil && [screen backingScaleFactor] > 0) {
+            scale = [screen backingScaleFactor];
+        }
+    });
+    return scale;
+}
@@ -0,0 +1,580 @@
+package ui
+
+// This file implements a small native window abstraction: a window holds widgets laid out
+// with a top-left origin, and widget events are routed back to Go callbacks.
+// Only macOS is implemented; other platforms get an error from NewWindow.
+
+/*
+#cgo darwin CFLAGS: -I${SRCDIR}
+#cgo darwin LDFLAGS: -framework Cocoa
+#include <stdlib.h>
+#include "window.h"
+*/
+import "C"
+import (
+	"fmt"
+	"go-reaper/src/pkg/config"
+	"go-reaper/src/pkg/logger"
+	"go-reaper/src/reaper"
+	"runtime"
+	"sync"
+	"unsafe"
+)
+
+// WidgetKind identifies the type of a widget; values match UIWidgetKind in window.h
+type WidgetKind int
+
+// Widget kinds
+const (
+	WidgetLabel WidgetKind = iota
+	WidgetButton
+	WidgetTextField
+	WidgetSlider
+	WidgetCheckbox
+	WidgetDropdown
+	WidgetCombo
+	WidgetMultiline
+	WidgetChecklist
+	WidgetProgress
+)
+
+// Key equivalents of the default and cancel buttons
+const (
+	keyReturn = "\r"
+	keyEscape = "\x1b"
+)
+
+// Rect is a widget frame in points, relative to the window's top-left corner
+type Rect struct {
+	X, Y, Width, Height float64
+}
+
+// Window is a native window containing widgets. It is registered with Windows under
+// its ID while open; use Windows.OnClose to observe it closing.
+type Window struct {
+	id       int
+	windowID string
+	title    string
+	widgets  map[int]*Widget
+	nextID   int
+	width    float64 // Content size, grown by SetLayout
+	height   float64
+
+	dockedView unsafe.Pointer // Content view handed to REAPER's docker, nil when floating
+	shown      bool           // Whether Show was called, to apply the docking preference once
+}
+
+// Widget is a control inside a Window. Callbacks run on the main thread.
+type Widget struct {
+	window    *Window
+	id        int
+	kind      WidgetKind
+	minWidth  float64 // Size of the frame it was added with, kept when laid out
+	minHeight float64
+
+	onClick  func()
+	onText   func(string)
+	onValue  func(float64)
+	onToggle func(bool)
+	onSelect func(int)
+	onItem   func(int, bool)
+}
+
+// Native windows by their numeric ID, for routing events
+var (
+	windowsMutex  sync.Mutex
+	nativeWindows = make(map[int]*Window)
+	nextNativeID  = 1
+)
+
+// NewWindow creates a hidden window and registers it with Windows under windowID;
+// add widgets, then call Show. A default size in the window's preferences replaces
+// width and height, but never makes the window smaller, since widgets are placed at
+// fixed positions.
+func NewWindow(windowID, title string, width, height float64) (*Window, error) {
+	if runtime.GOOS != "darwin" {
+		return nil, fmt.Errorf("native windows are currently only implemented for macOS")
+	}
+	if _, open := Windows.Lookup(windowID); open {
+		return nil, fmt.Errorf("a window with ID %q is already open", windowID)
+	}
+
+	preferences := config.GetWindowPreferences(windowID)
+	width = max(width, float64(preferences.Width))
+	height = max(height, float64(preferences.Height))
+
+	windowsMutex.Lock()
+	id := nextNativeID
+	nextNativeID++
+	windowsMutex.Unlock()
+
+	cTitle := C.CString(title)
+	defer C.free(unsafe.Pointer(cTitle))
+
+	if !bool(C.ui_window_create(C.int(id), cTitle, C.double(width), C.double(height))) {
+		return nil, fmt.Errorf("failed to create window %q", title)
+	}
+
+	w := &Window{id: id, windowID: windowID, title: title, widgets: make(map[int]*Widget), width: width, height: height}
+
+	windowsMutex.Lock()
+	nativeWindows[id] = w
+	windowsMutex.Unlock()
+
+	if err := Windows.Register(windowID, w); err != nil {
+		// Forget the native window first so closing it doesn't report the other window closed
+		windowsMutex.Lock()
+		delete(nativeWindows, id)
+		windowsMutex.Unlock()
+		w.Close()
+		return nil, err
+	}
+
+	logger.Debug("Created window %d (%s)", id, title)
+	return w, nil
+}
+
+// ID returns the ID the window is registered under
+func (w *Window) ID() string {
+	return w.windowID
+}
+
+// Title returns the title the window was created with
+func (w *Window) Title() string {
+	return w.title
+}
+
+// Show shows the window and brings it to the front, in the current theme's appearance.
+// The first time, a window whose preferences say so opens in REAPER's docker.
+func (w *Window) Show() error {
+	if !bool(C.ui_window_set_appearance(C.int(w.id), C.bool(CurrentTheme().Dark))) {
+		logger.Warning("Failed to set the appearance of window %s", w.windowID)
+	}
+	if !w.shown {
+		w.shown = true
+		if config.GetWindowPreferences(w.windowID).Docked {
+			err := w.Dock(false)
+			if err == nil {
+				return nil
+			}
+			logger.Warning("Failed to open window %s docked: %v", w.windowID, err)
+		}
+	}
+	if w.IsDocked() {
+		return reaper.DockWindowActivate(w.dockedView)
+	}
+	if !bool(C.ui_window_show(C.int(w.id))) {
+		return fmt.Errorf("failed to show window %q", w.title)
+	}
+	return nil
+}
+
+// ScaleFactor returns the backing scale factor of the screen the window is on: 2 on
+// Retina displays, 1 otherwise
+func (w *Window) ScaleFactor() float64 {
+	return float64(C.ui_window_scale_factor(C.int(w.id)))
+}
+
+// SetDefaultButton makes Return click button. Don't use it in windows with editable
+// text areas, where Return should start a new line.
+func (w *Window) SetDefaultButton(button *Widget) error {
+	return w.setKeyEquivalent(button, keyReturn)
+}
+
+// SetCancelButton makes Escape click button
+func (w *Window) SetCancelButton(button *Widget) error {
+	return w.setKeyEquivalent(button, keyEscape)
+}
+
+// setKeyEquivalent makes a key click one of the window's buttons
+func (w *Window) setKeyEquivalent(button *Widget, key string) error {
+	if button.window != w || button.kind != WidgetButton {
+		return fmt.Errorf("widget is not a button in window %q", w.title)
+	}
+
+	cKey := C.CString(key)
+	defer C.free(unsafe.Pointer(cKey))
+
+	if !bool(C.ui_window_set_key_equivalent(C.int(w.id), C.int(button.id), cKey)) {
+		return fmt.Errorf("failed to set key equivalent")
+	}
+	return nil
+}
+
+// SetTabOrder sets the order Tab moves the keyboard focus through widgets, starting with
+// the first when the window opens. Without it, Tab follows the widgets' positions.
+// Labels and progress bars are skipped; a checklist contributes the entries it has now.
+func (w *Window) SetTabOrder(widgets ...*Widget) error {
+	if len(widgets) == 0 {
+		return nil
+	}
+
+	ids := make([]C.int, len(widgets))
+	for i, widget := range widgets {
+		if widget.window != w {
+			return fmt.Errorf("widget is not in window %q", w.title)
+		}
+		ids[i] = C.int(widget.id)
+	}
+
+	if !bool(C.ui_window_set_tab_order(C.int(w.id), &ids[0], C.int(len(ids)))) {
+		return fmt.Errorf("failed to set tab order")
+	}
+	return nil
+}
+
+// Close closes the window if it is open
+func (w *Window) Close() {
+	if w.IsDocked() {
+		if err := reaper.DockWindowRemove(w.dockedView); err != nil {
+			logger.Warning("Failed to remove %s from the docker: %v", w.windowID, err)
+		}
+		w.dockedView = nil
+	}
+	C.ui_window_close(C.int(w.id))
+}
+
+// IsOpen reports whether the window still exists
+func (w *Window) IsOpen() bool {
+	return bool(C.ui_window_exists(C.int(w.id)))
+}
+
+// addWidget creates a native widget and registers it for events
+func (w *Window) addWidget(kind WidgetKind, frame Rect, text string, editable bool) (*Widget, error) {
+	windowsMutex.Lock()
+	w.nextID++
+	widget := &Widget{window: w, id: w.nextID, kind: kind, minWidth: frame.Width, minHeight: frame.Height}
+	w.widgets[widget.id] = widget
+	windowsMutex.Unlock()
+
+	cText := C.CString(text)
+	defer C.free(unsafe.Pointer(cText))
+
+	if !bool(C.ui_window_add_widget(C.int(w.id), C.int(widget.id), C.int(kind),
+		C.double(frame.X), C.double(frame.Y), C.double(frame.Width), C.double(frame.Height),
+		cText, C.bool(editable))) {
+		windowsMutex.Lock()
+		delete(w.widgets, widget.id)
+		windowsMutex.Unlock()
+		return nil, fmt.Errorf("failed to add widget to window %q", w.title)
+	}
+
+	return widget, nil
+}
+
+// AddLabel adds static text
+func (w *Window) AddLabel(frame Rect, text string) (*Widget, error) {
+	return w.addWidget(WidgetLabel, frame, text, false)
+}
+
+// AddButton adds a push button
+func (w *Window) AddButton(frame Rect, title string, onClick func()) (*Widget, error) {
+	widget, err := w.addWidget(WidgetButton, frame, title, false)
+	if err != nil {
+		return nil, err
+	}
+	widget.onClick = onClick
+	return widget, nil
+}
+
+// AddTextField adds a single-line text field; onChange is called on every edit
+func (w *Window) AddTextField(frame Rect, text string, onChange func(string)) (*Widget, error) {
+	widget, err := w.addWidget(WidgetTextField, frame, text, true)
+	if err != nil {
+		return nil, err
+	}
+	widget.onText = onChange
+	return widget, nil
+}
+
+// AddSlider adds a horizontal slider; onChange is called continuously while dragging
+func (w *Window) AddSlider(frame Rect, min, max, value float64, onChange func(float64)) (*Widget, error) {
+	if min >= max {
+		return nil, fmt.Errorf("invalid slider range %g-%g", min, max)
+	}
+
+	widget, err := w.addWidget(WidgetSlider, frame, "", false)
+	if err != nil {
+		return nil, err
+	}
+	if !bool(C.ui_window_set_range(C.int(w.id), C.int(widget.id), C.double(min), C.double(max))) {
+		return nil, fmt.Errorf("failed to set slider range")
+	}
+	if err := widget.SetValue(value); err != nil {
+		return nil, err
+	}
+
+	widget.onValue = onChange
+	return widget, nil
+}
+
+// AddCheckbox adds a checkbox with a title
+func (w *Window) AddCheckbox(frame Rect, title string, checked bool, onChange func(bool)) (*Widget, error) {
+	widget, err := w.addWidget(WidgetCheckbox, frame, title, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := widget.SetChecked(checked); err != nil {
+		return nil, err
+	}
+
+	widget.onToggle = onChange
+	return widget, nil
+}
+
+// AddDropdown adds a pop-up list; onChange receives the selected index
+func (w *Window) AddDropdown(frame Rect, items []string, selected int, onChange func(int)) (*Widget, error) {
+	widget, err := w.addWidget(WidgetDropdown, frame, "", false)
+	if err != nil {
+		return nil, err
+	}
+	if err := widget.SetItems(items); err != nil {
+		return nil, err
+	}
+	if selected >= 0 && selected < len(items) {
+		if err := widget.SetValue(float64(selected)); err != nil {
+			return nil, err
+		}
+	}
+
+	widget.onSelect = onChange
+	return widget, nil
+}
+
+// AddCombo adds an editable text field with a list of suggestions; onChange receives the text
+func (w *Window) AddCombo(frame Rect, items []string, text string, onChange func(string)) (*Widget, error) {
+	widget, err := w.addWidget(WidgetCombo, frame, text, true)
+	if err != nil {
+		return nil, err
+	}
+	if err := widget.SetItems(items); err != nil {
+		return nil, err
+	}
+
+	widget.onText = onChange
+	return widget, nil
+}
+
+// AddMultilineText adds a scrolling text area; onChange is only called when editable
+func (w *Window) AddMultilineText(frame Rect, text string, editable bool, onChange func(string)) (*Widget, error) {
+	widget, err := w.addWidget(WidgetMultiline, frame, text, editable)
+	if err != nil {
+		return nil, err
+	}
+
+	widget.onText = onChange
+	return widget, nil
+}
+
+// AddChecklist adds a scrolling list of checkboxes, all unchecked; onToggle receives the
+// entry's index and new state
+func (w *Window) AddChecklist(frame Rect, items []string, onToggle func(index int, checked bool)) (*Widget, error) {
+	widget, err := w.addWidget(WidgetChecklist, frame, "", false)
+	if err != nil {
+		return nil, err
+	}
+	if err := widget.SetItems(items); err != nil {
+		return nil, err
+	}
+
+	widget.onItem = onToggle
+	return widget, nil
+}
+
+// AddProgressBar adds a determinate progress bar running from 0 to 100
+func (w *Window) AddProgressBar(frame Rect) (*Widget, error) {
+	return w.addWidget(WidgetProgress, frame, "", false)
+}
+
+// SetFrame moves and resizes the widget
+func (wd *Widget) SetFrame(frame Rect) error {
+	if !bool(C.ui_window_set_frame(C.int(wd.window.id), C.int(wd.id),
+		C.double(frame.X), C.double(frame.Y), C.double(frame.Width), C.double(frame.Height))) {
+		return fmt.Errorf("failed to move widget")
+	}
+	return nil
+}
+
+// FittingSize returns the size the widget needs to show its text, and at least the
+// size it was added with. Text areas, checklists, sliders and progress bars have no
+// natural size, so give them one when adding them.
+func (wd *Widget) FittingSize() (width, height float64) {
+	var cWidth, cHeight C.double
+	if bool(C.ui_window_fitting_size(C.int(wd.window.id), C.int(wd.id), &cWidth, &cHeight)) {
+		width, height = float64(cWidth), float64(cHeight)
+	}
+	return max(width, wd.minWidth), max(height, wd.minHeight)
+}
+
+// Focus moves the keyboard focus to the widget
+func (wd *Widget) Focus() error {
+	if !bool(C.ui_window_focus(C.int(wd.window.id), C.int(wd.id))) {
+		return fmt.Errorf("failed to focus widget")
+	}
+	return nil
+}
+
+// SetAccessibilityLabel sets what VoiceOver calls the widget. Give one to widgets whose
+// purpose is only shown by a nearby label, such as text fields and lists.
+func (wd *Widget) SetAccessibilityLabel(label string) error {
+	cLabel := C.CString(label)
+	defer C.free(unsafe.Pointer(cLabel))
+
+	if !bool(C.ui_window_set_accessibility_label(C.int(wd.window.id), C.int(wd.id), cLabel)) {
+		return fmt.Errorf("failed to set accessibility label")
+	}
+	return nil
+}
+
+// Kind returns the widget's kind
+func (wd *Widget) Kind() WidgetKind {
+	return wd.kind
+}
+
+// SetText sets the text of a label, field, combo or text area, or the title of a button or checkbox
+func (wd *Widget) SetText(text string) error {
+	cText := C.CString(text)
+	defer C.free(unsafe.Pointer(cText))
+
+	if !bool(C.ui_window_set_text(C.int(wd.window.id), C.int(wd.id), cText)) {
+		return fmt.Errorf("failed to set widget text")
+	}
+	return nil
+}
+
+// Text returns the widget's text; for a dropdown, the selected item
+func (wd *Widget) Text() string {
+	cText := C.ui_window_get_text(C.int(wd.window.id), C.int(wd.id))
+	if cText == nil {
+		return ""
+	}
+	defer C.free(unsafe.Pointer(cText))
+	return C.GoString(cText)
+}
+
+// SetValue sets a slider's or progress bar's value or a dropdown's selected index. It does not fire callbacks.
+func (wd *Widget) SetValue(value float64) error {
+	if !bool(C.ui_window_set_value(C.int(wd.window.id), C.int(wd.id), C.double(value))) {
+		return fmt.Errorf("failed to set widget value")
+	}
+	return nil
+}
+
+// Value returns a slider's or progress bar's value or a dropdown's selected index (-1 when nothing is selected)
+func (wd *Widget) Value() float64 {
+	return float64(C.ui_window_get_value(C.int(wd.window.id), C.int(wd.id)))
+}
+
+// SetChecked sets a checkbox's state. It does not fire callbacks.
+func (wd *Widget) SetChecked(checked bool) error {
+	value := 0.0
+	if checked {
+		value = 1.0
+	}
+	return wd.SetValue(value)
+}
+
+// Checked returns a checkbox's state
+func (wd *Widget) Checked() bool {
+	return wd.Value() != 0
+}
+
+// SetItemChecked checks or unchecks a checklist entry. It does not fire callbacks.
+func (wd *Widget) SetItemChecked(index int, checked bool) error {
+	if !bool(C.ui_window_set_item_checked(C.int(wd.window.id), C.int(wd.id), C.int(index), C.bool(checked))) {
+		return fmt.Errorf("failed to set checklist entry %d", index)
+	}
+	return nil
+}
+
+// ItemChecked returns the state of a checklist entry
+func (wd *Widget) ItemChecked(index int) bool {
+	return bool(C.ui_window_get_item_checked(C.int(wd.window.id), C.int(wd.id), C.int(index)))
+}
+
+// SetItemText changes the title of a checklist entry, keeping its state
+func (wd *Widget) SetItemText(index int, text string) error {
+	cText := C.CString(text)
+	defer C.free(unsafe.Pointer(cText))
+
+	if !bool(C.ui_window_set_item_text(C.int(wd.window.id), C.int(wd.id), C.int(index), cText)) {
+		return fmt.Errorf("failed to set checklist entry %d", index)
+	}
+	return nil
+}
+
+// SetItems replaces the entries of a dropdown, combo box or checklist
+func (wd *Widget) SetItems(items []string) error {
+	var cItems **C.char
+	if len(items) > 0 {
+		array := allocStrings(items)
+		defer freeStrings(array)
+		cItems = &array[0]
+	}
+
+	if !bool(C.ui_window_set_items(C.int(wd.window.id), C.int(wd.id), cItems, C.int(len(items)))) {
+		return fmt.Errorf("failed to set widget items")
+	}
+	return nil
+}
+
+// SetEnabled enables or disables the widget; a disabled text area becomes read-only
+func (wd *Widget) SetEnabled(enabled bool) error {
+	if !bool(C.ui_window_set_enabled(C.int(wd.window.id), C.int(wd.id), C.bool(enabled))) {
+		return fmt.Errorf("failed to enable widget")
+	}
+	return nil
+}
+
+// Called when the user interacts with a widget
+//
+//export go_ui_widget_event
+func go_ui_widget_event(windowID C.int, widgetID C.int, value C.double, text *C.char) {
+	defer reaper.Recover("go_ui_widget_event")
+
+	windowsMutex.Lock()
+	var widget *Widget
+	if w, ok := nativeWindows[int(windowID)]; ok {
+		widget = w.widgets[int(widgetID)]
+	}
+	windowsMutex.Unlock()
+
+	if widget == nil {
+		logger.Warning("Event for unknown widget %d in window %d", int(widgetID), int(windowID))
+		return
+	}
+
+	// Call outside the lock so callbacks can use the window freely
+	switch {
+	case widget.onClick != nil:
+		widget.onClick()
+	case widget.onText != nil:
+		if text != nil {
+			widget.onText(C.GoString(text))
+		}
+	case widget.onValue != nil:
+		widget.onValue(float64(value))
+	case widget.onToggle != nil:
+		widget.onToggle(value != 0)
+	case widget.onSelect != nil:
+		widget.onSelect(int(value))
+	case widget.onItem != nil:
+		widget.onItem(int(value), widget.ItemChecked(int(value)))
+	}
+}
+
+// Called when a window closes, however it was closed
+//
+//export go_ui_window_closed
+func go_ui_window_closed(windowID C.int) {
+	defer reaper.Recover("go_ui_window_closed")
+
+	windowsMutex.Lock()
+	w, ok := nativeWindows[int(windowID)]
+	delete(nativeWindows, int(windowID))
+	windowsMutex.Unlock()
+
+	if ok {
+		Windows.Closed(w.windowID)
+	}
+}
@@ -0,0 +1,71 @@
+#ifndef UI_WINDOW_H
+#define UI_WINDOW_H
+
+#include <stdbool.h>
+
+// Widget kinds, kept in sync with WidgetKind in window.go
+typedef enum {
+    UI_WIDGET_LABEL = 0,
+    UI_WIDGET_BUTTON,
+    UI_WIDGET_TEXT_FIELD,
+    UI_WIDGET_SLIDER,
+    UI_WIDGET_CHECKBOX,
+    UI_WIDGET_DROPDOWN,
+    UI_WIDGET_COMBO,
+    UI_WIDGET_MULTILINE,
+    UI_WIDGET_CHECKLIST,
+    UI_WIDGET_PROGRESS
+} UIWidgetKind;
+
+// Context structure for passing data between Go and Objective-C
+typedef struct {
+    int window_id;
+    int widget_id;
+    int kind;
+    double x, y, width, height; // Widget frame, top-left origin
+    const char* text;
+    const char** items;         // Dropdown/combo/checklist entries
+    const int* widget_ids;      // Tab order
+    int item_count;
+    int index;                  // Checklist entry
+    double min, max, value;     // Slider/progress range and value, checkbox state or dropdown index
+    bool editable;              // Multi-line text only
+    char* result_text;          // Allocated with malloc, freed by the caller
+    void* result_view;          // NSView handed to REAPER's docker
+    bool success;
+} UIContext;
+
+// Function declarations that will be called from Go
+bool ui_window_create(int window_id, const char* title, double width, double height);
+bool ui_window_add_widget(int window_id, int widget_id, int kind, double x, double y, double width, double height,
+                          const char* text, bool editable);
+bool ui_window_set_text(int window_id, int widget_id, const char* text);
+char* ui_window_get_text(int window_id, int widget_id);
+bool ui_window_set_value(int window_id, int widget_id, double value);
+double ui_window_get_value(int window_id, int widget_id);
+bool ui_window_set_range(int window_id, int widget_id, double min, double max);
+bool ui_window_set_items(int window_id, int widget_id, const char** items, int item_count);
+bool ui_window_set_item_checked(int window_id, int widget_id, int index, bool checked);
+bool ui_window_get_item_checked(int window_id, int widget_id, int index);
+bool ui_window_set_item_text(int window_id, int widget_id, int index, const char* text);
+bool ui_window_set_enabled(int window_id, int widget_id, bool enabled);
+bool ui_window_set_frame(int window_id, int widget_id, double x, double y, double width, double height);
+bool ui_window_fitting_size(int window_id, int widget_id, double* width, double* height);
+bool ui_window_set_content_size(int window_id, double width, double height);
+bool ui_window_set_key_equivalent(int window_id, int widget_id, const char* key);
+bool ui_window_set_tab_order(int window_id, const int* widget_ids, int count);
+bool ui_window_focus(int window_id, int widget_id);
+bool ui_window_set_accessibility_label(int window_id, int widget_id, const char* label);
+bool ui_window_show(int window_id);
+bool ui_window_set_appearance(int window_id, bool dark);
+double ui_window_scale_factor(int window_id);
+void* ui_window_detach_content(int window_id);
+bool ui_window_reattach_content(int window_id);
+void ui_window_close(int window_id);
+bool ui_window_exists(int window_id);
+
+// Callbacks from Objective-C to Go
+extern void go_ui_widget_event(int window_id, int widget_id, double value, char* text);
+extern void go_ui_window_closed(int window_id);
+
+#endif /* UI_WINDOW_H */
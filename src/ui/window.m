@@ -0,0 +1,1237 @@
+#include <stdio.h>
+#include <stdlib.h>
+#include <stdbool.h>
+#include <string.h>
+#include "../c/logging.h"
+#import <Cocoa/Cocoa.h>
+#include "window.h"
+
+// Use our core logging system
+static void ui_log_to_reaper(LogLevel level, const char* message) {
+    log_message_v(level, "windowUI", message);
+}
+
+// Content view with a top-left origin, so Go lays widgets out like text
+@interface RPRFlippedView : NSView
+@end
+
+@implementation RPRFlippedView
+- (BOOL)isFlipped {
+    return YES;
+}
+@end
+
+// A window and its widgets; also the target of every control in the window
+@interface RPRUIWindow : NSObject <NSWindowDelegate, NSTextFieldDelegate, NSTextViewDelegate, NSComboBoxDelegate>
+@property (nonatomic, assign) int windowId;
+@property (nonatomic, strong) NSWindow* window;
+@property (nonatomic, strong) NSView* content;              // Content view, also while docked
+@property (nonatomic, strong) NSMutableDictionary* widgets; // widget id -> NSView
+@property (nonatomic, strong) NSMutableDictionary* kinds;   // widget id -> UIWidgetKind
+- (void)controlActivated:(id)sender;
+- (void)checklistToggled:(id)sender;
+- (void)closed;
+@end
+
+// Open windows by id
+static NSMutableDictionary* ui_windows = nil;
+
+// Look up a window by id - main thread only
+static RPRUIWindow* ui_lookup_window(int window_id) {
+    if (ui_windows == nil) {
+        return nil;
+    }
+    return [ui_windows objectForKey:@(window_id)];
+}
+
+// Send a widget event to Go
+static void ui_send_event(int window_id, int widget_id, double value, NSString* text) {
+    if (text == nil) {
+        go_ui_widget_event(window_id, widget_id, value, NULL);
+        return;
+    }
+
+    // Go copies the text before returning
+    const char* utf8 = [text UTF8String];
+    char* copy = strdup(utf8 ? utf8 : "");
+    go_ui_widget_event(window_id, widget_id, value, copy);
+    free(copy);
+}
+
+@implementation RPRUIWindow
+
+// Find the widget id of a view
+- (int)widgetIdForView:(id)view {
+    for (NSNumber* key in self.widgets) {
+        id widget = [self.widgets objectForKey:key];
+        if (widget == view) {
+            return [key intValue];
+        }
+        // Multi-line text and checklists live inside a scroll view
+        if ([widget isKindOfClass:[NSScrollView class]] && [(NSScrollView*)widget documentView] == view) {
+            return [key intValue];
+        }
+    }
+    return -1;
+}
+
+- (void)controlActivated:(id)sender {
+    int widgetId = [self widgetIdForView:sender];
+    if (widgetId < 0) {
+        return;
+    }
+
+    switch ([[self.kinds objectForKey:@(widgetId)] intValue]) {
+    case UI_WIDGET_BUTTON:
+        ui_send_event(self.windowId, widgetId, 0, nil);
+        break;
+    case UI_WIDGET_SLIDER:
+        ui_send_event(self.windowId, widgetId, [(NSSlider*)sender doubleValue], nil);
+        break;
+    case UI_WIDGET_CHECKBOX:
+        ui_send_event(self.windowId, widgetId, [(NSButton*)sender state] == NSControlStateValueOn ? 1 : 0, nil);
+        break;
+    case UI_WIDGET_DROPDOWN:
+        ui_send_event(self.windowId, widgetId, [(NSPopUpButton*)sender indexOfSelectedItem], nil);
+        break;
+    case UI_WIDGET_COMBO:
+    case UI_WIDGET_TEXT_FIELD:
+        ui_send_event(self.windowId, widgetId, 0, [(NSTextField*)sender stringValue]);
+        break;
+    default:
+        break;
+    }
+}
+
+// Checklist entries report their index; Go reads the new state back
+- (void)checklistToggled:(id)sender {
+    int widgetId = [self widgetIdForView:[sender superview]];
+    if (widgetId >= 0) {
+        ui_send_event(self.windowId, widgetId, [(NSButton*)sender tag], nil);
+    }
+}
+
+// Text fields and combo boxes report every edit
+- (void)controlTextDidChange:(NSNotification*)notification {
+    id field = [notification object];
+    int widgetId = [self widgetIdForView:field];
+    if (widgetId >= 0) {
+        ui_send_event(self.windowId, widgetId, 0, [(NSTextField*)field stringValue]);
+    }
+}
+
+// Picking a combo box entry doesn't change the text until after this notification
+- (void)comboBoxSelectionDidChange:(NSNotification*)notification {
+    NSComboBox* combo = [notification object];
+    int widgetId = [self widgetIdForView:combo];
+    NSInteger index = [combo indexOfSelectedItem];
+    if (widgetId >= 0 && index >= 0) {
+        ui_send_event(self.windowId, widgetId, index, [combo itemObjectValueAtIndex:index]);
+    }
+}
+
+// Tab moves to the next control rather than typing a tab, so the keyboard can leave text areas
+- (BOOL)textView:(NSTextView*)textView doCommandBySelector:(SEL)selector {
+    if (selector == @selector(insertTab:)) {
+        [[textView window] selectNextKeyView:nil];
+        return YES;
+    }
+    if (selector == @selector(insertBacktab:)) {
+        [[textView window] selectPreviousKeyView:nil];
+        return YES;
+    }
+    return NO;
+}
+
+// Multi-line text reports every edit
+- (void)textDidChange:(NSNotification*)notification {
+    NSTextView* textView = [notification object];
+    int widgetId = [self widgetIdForView:textView];
+    if (widgetId >= 0) {
+        ui_send_event(self.windowId, widgetId, 0, [textView string]);
+    }
+}
+
+- (void)windowWillClose:(NSNotification*)notification {
+    ui_log_to_reaper(LOG_DEBUG, "Window closing");
+    [self closed];
+}
+
+// Forget the window and tell Go; runs once however the window was closed
+- (void)closed {
+    // Keep ourselves alive until the method returns; the registry holds the last reference
+    RPRUIWindow* keepAlive = self;
+    int windowId = keepAlive.windowId;
+    if (ui_lookup_window(windowId) != keepAlive) {
+        return;
+    }
+
+    [keepAlive.window setDelegate:nil];
+    [ui_windows removeObjectForKey:@(windowId)];
+    go_ui_window_closed(windowId);
+}
+
+@end
+
+// Create a window - internal
+static void ui_create_window_on_main_thread(void* context) {
+    UIContext* ctx = (UIContext*)context;
+
+    @autoreleasepool {
+        @try {
+            if (ui_windows == nil) {
+                ui_windows = [[NSMutableDictionary alloc] init];
+            }
+            if (ui_lookup_window(ctx->window_id) != nil) {
+                ui_log_to_reaper(LOG_ERROR, "Window id already in use");
+                ctx->success = false;
+                return;
+            }
+
+            NSRect frame = NSMakeRect(100, 100, ctx->width, ctx->height);
+            NSWindow* window = [[NSWindow alloc]
+                initWithContentRect:frame
+                styleMask:NSWindowStyleMaskTitled|NSWindowStyleMaskClosable|NSWindowStyleMaskMiniaturizable
+                backing:NSBackingStoreBuffered
+                defer:NO];
+
+            if (window == nil) {
+                ui_log_to_reaper(LOG_ERROR, "Failed to create window");
+                ctx->success = false;
+                return;
+            }
+
+            [window setTitle:[NSString stringWithUTF8String:ctx->text]];
+            [window setReleasedWhenClosed:NO]; // Important: Don't release on close
+            NSView* content = [[RPRFlippedView alloc] initWithFrame:frame];
+            [window setContentView:content];
+
+            RPRUIWindow* state = [[RPRUIWindow alloc] init];
+            state.windowId = ctx->window_id;
+            state.window = window;
+            state.content = content;
+            state.widgets = [NSMutableDictionary dictionary];
+            state.kinds = [NSMutableDictionary dictionary];
+            [window setDelegate:state];
+            // Tab follows the widgets' positions until Go sets an order
+            [window setAutorecalculatesKeyViewLoop:YES];
+
+            [ui_windows setObject:state forKey:@(ctx->window_id)];
+            ctx->success = true;
+        }
+        @catch (NSException *exception) {
+            ui_log_to_reaper(LOG_ERROR, "EXCEPTION creating window");
+            NSLog(@"Exception: %@", exception);
+            ctx->success = false;
+        }
+    }
+}
+
+// Create a label-style text field
+static NSTextField* ui_make_label(NSRect frame, NSString* text) {
+    NSTextField* label = [[NSTextField alloc] initWithFrame:frame];
+    [label setStringValue:text];
+    [label setBezeled:NO];
+    [label setDrawsBackground:NO];
+    [label setEditable:NO];
+    [label setSelectable:NO];
+    return label;
+}
+
+// Add a widget to a window - internal
+static void ui_add_widget_on_main_thread(void* context) {
+    UIContext* ctx = (UIContext*)context;
+
+    @autoreleasepool {
+        @try {
+            RPRUIWindow* state = ui_lookup_window(ctx->window_id);
+            if (state == nil) {
+                ui_log_to_reaper(LOG_ERROR, "Window not found when adding widget");
+                ctx->success = false;
+                return;
+            }
+
+            NSRect frame = NSMakeRect(ctx->x, ctx->y, ctx->width, ctx->height);
+            NSString* text = [NSString stringWithUTF8String:ctx->text ? ctx->text : ""];
+            NSView* view = nil;
+
+            switch (ctx->kind) {
+            case UI_WIDGET_LABEL:
+                view = ui_make_label(frame, text);
+                break;
+            case UI_WIDGET_BUTTON: {
+                NSButton* button = [[NSButton alloc] initWithFrame:frame];
+                [button setTitle:text];
+                [button setBezelStyle:NSBezelStyleRounded];
+                [button setTarget:state];
+                [button setAction:@selector(controlActivated:)];
+                view = button;
+                break;
+            }
+            case UI_WIDGET_TEXT_FIELD: {
+                NSTextField* field = [[NSTextField alloc] initWithFrame:frame];
+                [field setStringValue:text];
+                [field setDelegate:state];
+                [field setTarget:state];
+                [field setAction:@selector(controlActivated:)];
+                view = field;
+                break;
+            }
+            case UI_WIDGET_SLIDER: {
+                NSSlider* slider = [[NSSlider alloc] initWithFrame:frame];
+                [slider setMinValue:0];
+                [slider setMaxValue:1];
+                [slider setContinuous:YES];
+                [slider setTarget:state];
+                [slider setAction:@selector(controlActivated:)];
+                view = slider;
+                break;
+            }
+            case UI_WIDGET_CHECKBOX: {
+                NSButton* checkbox = [[NSButton alloc] initWithFrame:frame];
+                [checkbox setButtonType:NSButtonTypeSwitch];
+                [checkbox setTitle:text];
+                [checkbox setTarget:state];
+                [checkbox setAction:@selector(controlActivated:)];
+                view = checkbox;
+                break;
+            }
+            case UI_WIDGET_DROPDOWN: {
+                NSPopUpButton* popup = [[NSPopUpButton alloc] initWithFrame:frame pullsDown:NO];
+                [popup setTarget:state];
+                [popup setAction:@selector(controlActivated:)];
+                view = popup;
+                break;
+            }
+            case UI_WIDGET_COMBO: {
+                NSComboBox* combo = [[NSComboBox alloc] initWithFrame:frame];
+                [combo setStringValue:text];
+                [combo setDelegate:state];
+                [combo setTarget:state];
+                [combo setAction:@selector(controlActivated:)];
+                view = combo;
+                break;
+            }
+            case UI_WIDGET_MULTILINE: {
+                NSScrollView* scroll = [[NSScrollView alloc] initWithFrame:frame];
+                [scroll setHasVerticalScroller:YES];
+                [scroll setBorderType:NSBezelBorder];
+
+                NSSize contentSize = [scroll contentSize];
+                NSTextView* textView = [[NSTextView alloc] initWithFrame:NSMakeRect(0, 0, contentSize.width, contentSize.height)];
+                [textView setString:text];
+                [textView setEditable:ctx->editable ? YES : NO];
+                [textView setRichText:NO];
+                [textView setVerticallyResizable:YES];
+                [textView setAutoresizingMask:NSViewWidthSizable];
+                [[textView textContainer] setWidthTracksTextView:YES];
+                [textView setDelegate:state];
+
+                [scroll setDocumentView:textView];
+                view = scroll;
+                break;
+            }
+            case UI_WIDGET_PROGRESS: {
+                NSProgressIndicator* progress = [[NSProgressIndicator alloc] initWithFrame:frame];
+                [progress setStyle:NSProgressIndicatorStyleBar];
+                [progress setIndeterminate:NO];
+                [progress setMinValue:0];
+                [progress setMaxValue:100];
+                view = progress;
+                break;
+            }
+            case UI_WIDGET_CHECKLIST: {
+                NSScrollView* scroll = [[NSScrollView alloc] initWithFrame:frame];
+                [scroll setHasVerticalScroller:YES];
+                [scroll setBorderType:NSBezelBorder];
+
+                // Entries are added by ui_window_set_items
+                NSSize contentSize = [scroll contentSize];
+                NSView* list = [[RPRFlippedView alloc] initWithFrame:NSMakeRect(0, 0, contentSize.width, contentSize.height)];
+                [scroll setDocumentView:list];
+                view = scroll;
+                break;
+            }
+            default:
+                ui_log_to_reaper(LOG_ERROR, "Unknown widget kind");
+                ctx->success = false;
+                return;
+            }
+
+            [state.content addSubview:view];
+            [state.widgets setObject:view forKey:@(ctx->widget_id)];
+            [state.kinds setObject:@(ctx->kind) forKey:@(ctx->widget_id)];
+            ctx->success = true;
+        }
+        @catch (NSException *exception) {
+            ui_log_to_reaper(LOG_ERROR, "EXCEPTION adding widget");
+            NSLog(@"Exception: %@", exception);
+            ctx->success = false;
+        }
+    }
+}
+
+// Look up a widget and its kind - main thread only
+static NSView* ui_lookup_widget(UIContext* ctx, int* kind) {
+    RPRUIWindow* state = ui_lookup_window(ctx->window_id);
+    if (state == nil) {
+        return nil;
+    }
+    NSView* view = [state.widgets objectForKey:@(ctx->widget_id)];
+    if (view != nil && kind != NULL) {
+        *kind = [[state.kinds objectForKey:@(ctx->widget_id)] intValue];
+    }
+    return view;
+}
+
+// Set a widget's text - internal
+static void ui_set_text_on_main_thread(void* context) {
+    UIContext* ctx = (UIContext*)context;
+    int kind = 0;
+    NSView* view = ui_lookup_widget(ctx, &kind);
+    if (view == nil) {
+        ctx->success = false;
+        return;
+    }
+
+    NSString* text = [NSString stringWithUTF8String:ctx->text ? ctx->text : ""];
+    switch (kind) {
+    case UI_WIDGET_BUTTON:
+    case UI_WIDGET_CHECKBOX:
+        [(NSButton*)view setTitle:text];
+        break;
+    case UI_WIDGET_LABEL:
+    case UI_WIDGET_TEXT_FIELD:
+    case UI_WIDGET_COMBO:
+        [(NSTextField*)view setStringValue:text];
+        break;
+    case UI_WIDGET_MULTILINE:
+        [(NSTextView*)[(NSScrollView*)view documentView] setString:text];
+        break;
+    default:
+        ctx->success = false;
+        return;
+    }
+    ctx->success = true;
+}
+
+// Get a widget's text - internal
+static void ui_get_text_on_main_thread(void* context) {
+    UIContext* ctx = (UIContext*)context;
+    int kind = 0;
+    NSView* view = ui_lookup_widget(ctx, &kind);
+    if (view == nil) {
+        ctx->success = false;
+        return;
+    }
+
+    NSString* text = nil;
+    switch (kind) {
+    case UI_WIDGET_BUTTON:
+    case UI_WIDGET_CHECKBOX:
+        text = [(NSButton*)view title];
+        break;
+    case UI_WIDGET_LABEL:
+    case UI_WIDGET_TEXT_FIELD:
+    case UI_WIDGET_COMBO:
+        text = [(NSTextField*)view stringValue];
+        break;
+    case UI_WIDGET_DROPDOWN:
+        text = [(NSPopUpButton*)view titleOfSelectedItem];
+        break;
+    case UI_WIDGET_MULTILINE:
+        text = [(NSTextView*)[(NSScrollView*)view documentView] string];
+        break;
+    default:
+        break;
+    }
+
+    const char* utf8 = text ? [text UTF8String] : "";
+    ctx->result_text = strdup(utf8 ? utf8 : "");
+    ctx->success = true;
+}
+
+// Set a widget's value - internal
+static void ui_set_value_on_main_thread(void* context) {
+    UIContext* ctx = (UIContext*)context;
+    int kind = 0;
+    NSView* view = ui_lookup_widget(ctx, &kind);
+    if (view == nil) {
+        ctx->success = false;
+        return;
+    }
+
+    switch (kind) {
+    case UI_WIDGET_SLIDER:
+        [(NSSlider*)view setDoubleValue:ctx->value];
+        break;
+    case UI_WIDGET_PROGRESS:
+        [(NSProgressIndicator*)view setDoubleValue:ctx->value];
+        break;
+    case UI_WIDGET_CHECKBOX:
+        [(NSButton*)view setState:ctx->value != 0 ? NSControlStateValueOn : NSControlStateValueOff];
+        break;
+    case UI_WIDGET_DROPDOWN:
+        [(NSPopUpButton*)view selectItemAtIndex:(NSInteger)ctx->value];
+        break;
+    case UI_WIDGET_COMBO:
+        if (ctx->value >= 0 && ctx->value < [(NSComboBox*)view numberOfItems]) {
+            [(NSComboBox*)view selectItemAtIndex:(NSInteger)ctx->value];
+        }
+        break;
+    default:
+        ctx->success = false;
+        return;
+    }
+    ctx->success = true;
+}
+
+// Get a widget's value - internal
+static void ui_get_value_on_main_thread(void* context) {
+    UIContext* ctx = (UIContext*)context;
+    int kind = 0;
+    NSView* view = ui_lookup_widget(ctx, &kind);
+    if (view == nil) {
+        ctx->success = false;
+        return;
+    }
+
+    switch (kind) {
+    case UI_WIDGET_SLIDER:
+        ctx->value = [(NSSlider*)view doubleValue];
+        break;
+    case UI_WIDGET_PROGRESS:
+        ctx->value = [(NSProgressIndicator*)view doubleValue];
+        break;
+    case UI_WIDGET_CHECKBOX:
+        ctx->value = [(NSButton*)view state] == NSControlStateValueOn ? 1 : 0;
+        break;
+    case UI_WIDGET_DROPDOWN:
+        ctx->value = [(NSPopUpButton*)view indexOfSelectedItem];
+        break;
+    case UI_WIDGET_COMBO:
+        ctx->value = [(NSComboBox*)view indexOfSelectedItem];
+        break;
+    default:
+        ctx->success = false;
+        return;
+    }
+    ctx->success = true;
+}
+
+// Set a slider's or progress bar's range - internal
+static void ui_set_range_on_main_thread(void* context) {
+    UIContext* ctx = (UIContext*)context;
+    int kind = 0;
+    NSView* view = ui_lookup_widget(ctx, &kind);
+    if (view == nil) {
+        ctx->success = false;
+        return;
+    }
+
+    if (kind == UI_WIDGET_SLIDER) {
+        [(NSSlider*)view setMinValue:ctx->min];
+        [(NSSlider*)view setMaxValue:ctx->max];
+    } else if (kind == UI_WIDGET_PROGRESS) {
+        [(NSProgressIndicator*)view setMinValue:ctx->min];
+        [(NSProgressIndicator*)view setMaxValue:ctx->max];
+    } else {
+        ctx->success = false;
+        return;
+    }
+    ctx->success = true;
+}
+
+// Replace the entries of a dropdown, combo box or checklist - internal
+static void ui_set_items_on_main_thread(void* context) {
+    UIContext* ctx = (UIContext*)context;
+    int kind = 0;
+    NSView* view = ui_lookup_widget(ctx, &kind);
+    if (view == nil) {
+        ctx->success = false;
+        return;
+    }
+
+    if (kind == UI_WIDGET_DROPDOWN) {
+        NSPopUpButton* popup = (NSPopUpButton*)view;
+        [popup removeAllItems];
+        for (int i = 0; i < ctx->item_count; i++) {
+            // addItemWithTitle: ignores duplicates, so add through the menu
+            NSString* title = [NSString stringWithUTF8String:ctx->items[i]];
+            [[popup menu] addItemWithTitle:title action:nil keyEquivalent:@""];
+        }
+    } else if (kind == UI_WIDGET_COMBO) {
+        NSComboBox* combo = (NSComboBox*)view;
+        [combo removeAllItems];
+        for (int i = 0; i < ctx->item_count; i++) {
+            [combo addItemWithObjectValue:[NSString stringWithUTF8String:ctx->items[i]]];
+        }
+    } else if (kind == UI_WIDGET_CHECKLIST) {
+        RPRUIWindow* state = ui_lookup_window(ctx->window_id);
+        NSScrollView* scroll = (NSScrollView*)view;
+        NSView* list = [scroll documentView];
+        for (NSView* entry in [[list subviews] copy]) {
+            [entry removeFromSuperview];
+        }
+
+        const CGFloat rowHeight = 22;
+        NSSize contentSize = [scroll contentSize];
+        [list setFrame:NSMakeRect(0, 0, contentSize.width, MAX(contentSize.height, rowHeight * ctx->item_count + 4))];
+        for (int i = 0; i < ctx->item_count; i++) {
+            NSButton* entry = [[NSButton alloc] initWithFrame:NSMakeRect(6, 2 + rowHeight * i, contentSize.width - 12, rowHeight)];
+            [entry setButtonType:NSButtonTypeSwitch];
+            [entry setTitle:[NSString stringWithUTF8String:ctx->items[i]]];
+            [entry setTag:i];
+            [entry setTarget:state];
+            [entry setAction:@selector(checklistToggled:)];
+            [list addSubview:entry];
+        }
+    } else {
+        ctx->success = false;
+        return;
+    }
+    ctx->success = true;
+}
+
+// Find a checklist entry - main thread only
+static NSButton* ui_lookup_checklist_entry(UIContext* ctx) {
+    int kind = 0;
+    NSView* view = ui_lookup_widget(ctx, &kind);
+    if (view == nil || kind != UI_WIDGET_CHECKLIST) {
+        return nil;
+    }
+    NSView* entry = [[(NSScrollView*)view documentView] viewWithTag:ctx->index];
+    return [entry isKindOfClass:[NSButton class]] ? (NSButton*)entry : nil;
+}
+
+// Check or uncheck a checklist entry - internal
+static void ui_set_item_checked_on_main_thread(void* context) {
+    UIContext* ctx = (UIContext*)context;
+    NSButton* entry = ui_lookup_checklist_entry(ctx);
+    if (entry == nil) {
+        ctx->success = false;
+        return;
+    }
+
+    [entry setState:ctx->value != 0 ? NSControlStateValueOn : NSControlStateValueOff];
+    ctx->success = true;
+}
+
+// Get a checklist entry's state - internal
+static void ui_get_item_checked_on_main_thread(void* context) {
+    UIContext* ctx = (UIContext*)context;
+    NSButton* entry = ui_lookup_checklist_entry(ctx);
+    ctx->success = entry != nil && [entry state] == NSControlStateValueOn;
+}
+
+// Retitle a checklist entry - internal
+static void ui_set_item_text_on_main_thread(void* context) {
+    UIContext* ctx = (UIContext*)context;
+    NSButton* entry = ui_lookup_checklist_entry(ctx);
+    if (entry == nil) {
+        ctx->success = false;
+        return;
+    }
+
+    [entry setTitle:[NSString stringWithUTF8String:ctx->text ? ctx->text : ""]];
+    ctx->success = true;
+}
+
+// Enable or disable a widget - internal
+static void ui_set_enabled_on_main_thread(void* context) {
+    UIContext* ctx = (UIContext*)context;
+    int kind = 0;
+    NSView* view = ui_lookup_widget(ctx, &kind);
+    if (view == nil) {
+        ctx->success = false;
+        return;
+    }
+
+    if (kind == UI_WIDGET_MULTILINE) {
+        [(NSTextView*)[(NSScrollView*)view documentView] setEditable:ctx->value != 0];
+    } else if (kind == UI_WIDGET_CHECKLIST) {
+        for (NSView* entry in [[(NSScrollView*)view documentView] subviews]) {
+            if ([entry isKindOfClass:[NSControl class]]) {
+                [(NSControl*)entry setEnabled:ctx->value != 0];
+            }
+        }
+    } else if ([view isKindOfClass:[NSControl class]]) {
+        [(NSControl*)view setEnabled:ctx->value != 0];
+    }
+    ctx->success = true;
+}
+
+// Move and resize a widget - internal
+static void ui_set_frame_on_main_thread(void* context) {
+    UIContext* ctx = (UIContext*)context;
+    int kind = 0;
+    NSView* view = ui_lookup_widget(ctx, &kind);
+    if (view == nil) {
+        ctx->success = false;
+        return;
+    }
+
+    [view setFrame:NSMakeRect(ctx->x, ctx->y, ctx->width, ctx->height)];
+
+    // Checklist entries were laid out for the old width
+    if (kind == UI_WIDGET_CHECKLIST) {
+        NSScrollView* scroll = (NSScrollView*)view;
+        NSView* list = [scroll documentView];
+        NSSize contentSize = [scroll contentSize];
+        [list setFrameSize:NSMakeSize(contentSize.width, MAX([list frame].size.height, contentSize.height))];
+        for (NSView* entry in [list subviews]) {
+            [entry setFrameSize:NSMakeSize(contentSize.width - 12, [entry frame].size.height)];
+        }
+    }
+    ctx->success = true;
+}
+
+// Measure the size a widget needs to show its contents - internal
+static void ui_fitting_size_on_main_thread(void* context) {
+    UIContext* ctx = (UIContext*)context;
+    int kind = 0;
+    NSView* view = ui_lookup_widget(ctx, &kind);
+    if (view == nil) {
+        ctx->success = false;
+        return;
+    }
+
+    // Scrolling views, sliders and progress bars have no natural size; keep their frame
+    NSSize size = [view frame].size;
+    switch (kind) {
+    case UI_WIDGET_LABEL:
+    case UI_WIDGET_BUTTON:
+    case UI_WIDGET_TEXT_FIELD:
+    case UI_WIDGET_CHECKBOX:
+    case UI_WIDGET_DROPDOWN:
+    case UI_WIDGET_COMBO:
+        size = [[(NSControl*)view cell] cellSize];
+        break;
+    default:
+        break;
+    }
+    ctx->width = ceil(size.width);
+    ctx->height = ceil(size.height);
+    ctx->success = true;
+}
+
+// Resize a window's content area, keeping its top-left corner in place - internal
+static void ui_set_content_size_on_main_thread(void* context) {
+    UIContext* ctx = (UIContext*)context;
+    RPRUIWindow* state = ui_lookup_window(ctx->window_id);
+    if (state == nil) {
+        ctx->success = false;
+        return;
+    }
+
+    NSRect frame = [state.window frame];
+    NSRect content = [state.window frameRectForContentRect:NSMakeRect(0, 0, ctx->width, ctx->height)];
+    content.origin = NSMakePoint(frame.origin.x, NSMaxY(frame) - content.size.height);
+    [state.window setFrame:content display:YES];
+    [state.content setFrameSize:NSMakeSize(ctx->width, ctx->height)];
+    ctx->success = true;
+}
+
+// The views a widget adds to the key view loop, in order - main thread only
+static NSArray* ui_key_views(NSView* view, int kind) {
+    switch (kind) {
+    case UI_WIDGET_LABEL:
+    case UI_WIDGET_PROGRESS:
+        return @[];
+    case UI_WIDGET_MULTILINE:
+        return @[[(NSScrollView*)view documentView]];
+    case UI_WIDGET_CHECKLIST:
+        return [[(NSScrollView*)view documentView] subviews];
+    default:
+        return @[view];
+    }
+}
+
+// Give a button a key equivalent, e.g. Return for the default button - internal
+static void ui_set_key_equivalent_on_main_thread(void* context) {
+    UIContext* ctx = (UIContext*)context;
+    int kind = 0;
+    NSView* view = ui_lookup_widget(ctx, &kind);
+    if (view == nil || kind != UI_WIDGET_BUTTON) {
+        ctx->success = false;
+        return;
+    }
+
+    [(NSButton*)view setKeyEquivalent:[NSString stringWithUTF8String:ctx->text]];
+    ctx->success = true;
+}
+
+// Chain widgets into the window's key view loop - internal
+static void ui_set_tab_order_on_main_thread(void* context) {
+    UIContext* ctx = (UIContext*)context;
+    RPRUIWindow* state = ui_lookup_window(ctx->window_id);
+    if (state == nil) {
+        ctx->success = false;
+        return;
+    }
+
+    NSMutableArray* views = [NSMutableArray array];
+    for (int i = 0; i < ctx->item_count; i++) {
+        ctx->widget_id = ctx->widget_ids[i];
+        int kind = 0;
+        NSView* view = ui_lookup_widget(ctx, &kind);
+        if (view == nil) {
+            ctx->success = false;
+            return;
+        }
+        [views addObjectsFromArray:ui_key_views(view, kind)];
+    }
+    if ([views count] == 0) {
+        ctx->success = false;
+        return;
+    }
+
+    [state.window setAutorecalculatesKeyViewLoop:NO];
+    for (NSUInteger i = 0; i < [views count]; i++) {
+        [[views objectAtIndex:i] setNextKeyView:[views objectAtIndex:(i + 1) % [views count]]];
+    }
+    [state.window setInitialFirstResponder:[views firstObject]];
+    ctx->success = true;
+}
+
+// Move the keyboard focus to a widget - internal
+static void ui_focus_on_main_thread(void* context) {
+    UIContext* ctx = (UIContext*)context;
+    int kind = 0;
+    NSView* view = ui_lookup_widget(ctx, &kind);
+    NSArray* views = view != nil ? ui_key_views(view, kind) : @[];
+    if ([views count] == 0) {
+        ctx->success = false;
+        return;
+    }
+
+    NSView* target = [views firstObject];
+    ctx->success = [target window] != nil && [[target window] makeFirstResponder:target];
+}
+
+// Name a widget for VoiceOver - internal
+static void ui_set_accessibility_label_on_main_thread(void* context) {
+    UIContext* ctx = (UIContext*)context;
+    int kind = 0;
+    NSView* view = ui_lookup_widget(ctx, &kind);
+    if (view == nil) {
+        ctx->success = false;
+        return;
+    }
+
+    NSString* label = [NSString stringWithUTF8String:ctx->text];
+    [view setAccessibilityLabel:label];
+    // VoiceOver reads the text view or list inside a scroll view
+    if ([view isKindOfClass:[NSScrollView class]]) {
+        [[(NSScrollView*)view documentView] setAccessibilityLabel:label];
+    }
+    ctx->success = true;
+}
+
+// Show a window - internal
+static void ui_show_window_on_main_thread(void* context) {
+    UIContext* ctx = (UIContext*)context;
+    RPRUIWindow* state = ui_lookup_window(ctx->window_id);
+    if (state == nil) {
+        ctx->success = false;
+        return;
+    }
+
+    if (![state.window isVisible]) {
+        [state.window center];
+    }
+    [state.window makeKeyAndOrderFront:nil];
+    ctx->success = true;
+}
+
+// Give a window and its content view a dark or light appearance - internal
+static void ui_set_appearance_on_main_thread(void* context) {
+    UIContext* ctx = (UIContext*)context;
+    RPRUIWindow* state = ui_lookup_window(ctx->window_id);
+    if (state == nil) {
+        ctx->success = false;
+        return;
+    }
+
+    if (@available(macOS 10.14, *)) {
+        NSAppearance* appearance = [NSAppearance appearanceNamed:ctx->value != 0 ? NSAppearanceNameDarkAqua : NSAppearanceNameAqua];
+        [state.window setAppearance:appearance];
+        // The content view leaves the window while docked
+        [state.content setAppearance:appearance];
+    }
+    ctx->success = true;
+}
+
+// Read the backing scale factor of the screen a window is on - internal
+static void ui_scale_factor_on_main_thread(void* context) {
+    UIContext* ctx = (UIContext*)context;
+    RPRUIWindow* state = ui_lookup_window(ctx->window_id);
+    if (state == nil) {
+        ctx->success = false;
+        return;
+    }
+
+    // A docked view's window is REAPER's, which knows the screen it is on
+    NSWindow* window = [state.content window] != nil ? [state.content window] : state.window;
+    ctx->value = [window backingScaleFactor];
+    ctx->success = ctx->value > 0;
+}
+
+// Hide the window and hand out its content view for docking - internal
+static void ui_detach_content_on_main_thread(void* context) {
+    UIContext* ctx = (UIContext*)context;
+    RPRUIWindow* state = ui_lookup_window(ctx->window_id);
+    if (state == nil) {
+        ctx->success = false;
+        return;
+    }
+
+    // The docker reparents the view; the empty window stays around, hidden, for undocking
+    [state.window orderOut:nil];
+    ctx->result_view = (__bridge void*)state.content;
+    ctx->success = true;
+}
+
+// Put the content view back into the window after undocking - internal
+static void ui_reattach_content_on_main_thread(void* context) {
+    UIContext* ctx = (UIContext*)context;
+    RPRUIWindow* state = ui_lookup_window(ctx->window_id);
+    if (state == nil) {
+        ctx->success = false;
+        return;
+    }
+
+    [state.content removeFromSuperview];
+    [state.content setFrame:[[state.window contentView] bounds]];
+    [state.window setContentView:state.content];
+    ctx->success = true;
+}
+
+// Close a window - internal
+static void ui_close_window_on_main_thread(void* context) {
+    UIContext* ctx = (UIContext*)context;
+
+    @try {
+        RPRUIWindow* state = ui_lookup_window(ctx->window_id);
+        if (state != nil) {
+            // A docked window is hidden and may not post windowWillClose:, so report it directly
+            [state.window setDelegate:nil];
+            [state.window close];
+            [state closed];
+        }
+    }
+    @catch (NSException *exception) {
+        ui_log_to_reaper(LOG_ERROR, "EXCEPTION closing window");
+        NSLog(@"Exception: %@", exception);
+    }
+}
+
+// Check whether a window exists - internal
+static void ui_window_exists_on_main_thread(void* context) {
+    UIContext* ctx = (UIContext*)context;
+    ctx->success = ui_lookup_window(ctx->window_id) != nil;
+}
+
+// Execute function on main thread - internal
+static bool ui_execute_on_main_thread(void (*func)(void*), void* context) {
+    // Check if already on main thread (e.g. called from a widget callback)
+    if ([NSThread isMainThread]) {
+        func(context);
+        return true;
+    }
+
+    __block bool completed = false;
+
+    dispatch_sync(dispatch_get_main_queue(), ^{
+        func(context);
+        completed = true;
+    });
+
+    return completed;
+}
+
+// Run a function for a widget on the main thread and report its success
+static bool ui_run(void (*func)(void*), UIContext* ctx) {
+    if (!ui_execute_on_main_thread(func, ctx)) {
+        ui_log_to_reaper(LOG_ERROR, "Failed to execute on main thread");
+        return false;
+    }
+    return ctx->success;
+}
+
+// Create a hidden window - PUBLIC FUNCTION
+bool ui_window_create(int window_id, const char* title, double width, double height) {
+    UIContext ctx;
+    memset(&ctx, 0, sizeof(ctx));
+    ctx.window_id = window_id;
+    ctx.text = title;
+    ctx.width = width;
+    ctx.height = height;
+    return ui_run(ui_create_window_on_main_thread, &ctx);
+}
+
+// Add a widget to a window - PUBLIC FUNCTION
+bool ui_window_add_widget(int window_id, int widget_id, int kind, double x, double y, double width, double height,
+                          const char* text, bool editable) {
+    UIContext ctx;
+    memset(&ctx, 0, sizeof(ctx));
+    ctx.window_id = window_id;
+    ctx.widget_id = widget_id;
+    ctx.kind = kind;
+    ctx.x = x;
+    ctx.y = y;
+    ctx.width = width;
+    ctx.height = height;
+    ctx.text = text;
+    ctx.editable = editable;
+    return ui_run(ui_add_widget_on_main_thread, &ctx);
+}
+
+// Set a widget's text - PUBLIC FUNCTION
+bool ui_window_set_text(int window_id, int widget_id, const char* text) {
+    UIContext ctx;
+    memset(&ctx, 0, sizeof(ctx));
+    ctx.window_id = window_id;
+    ctx.widget_id = widget_id;
+    ctx.text = text;
+    return ui_run(ui_set_text_on_main_thread, &ctx);
+}
+
+// Get a widget's text; the caller frees the result - PUBLIC FUNCTION
+char* ui_window_get_text(int window_id, int widget_id) {
+    UIContext ctx;
+    memset(&ctx, 0, sizeof(ctx));
+    ctx.window_id = window_id;
+    ctx.widget_id = widget_id;
+    if (!ui_run(ui_get_text_on_main_thread, &ctx)) {
+        return NULL;
+    }
+    return ctx.result_text;
+}
+
+// Set a slider value, checkbox state or dropdown index - PUBLIC FUNCTION
+bool ui_window_set_value(int window_id, int widget_id, double value) {
+    UIContext ctx;
+    memset(&ctx, 0, sizeof(ctx));
+    ctx.window_id = window_id;
+    ctx.widget_id = widget_id;
+    ctx.value = value;
+    return ui_run(ui_set_value_on_main_thread, &ctx);
+}
+
+// Get a slider value, checkbox state or dropdown index - PUBLIC FUNCTION
+double ui_window_get_value(int window_id, int widget_id) {
+    UIContext ctx;
+    memset(&ctx, 0, sizeof(ctx));
+    ctx.window_id = window_id;
+    ctx.widget_id = widget_id;
+    if (!ui_run(ui_get_value_on_main_thread, &ctx)) {
+        return 0;
+    }
+    return ctx.value;
+}
+
+// Set a slider's or progress bar's range - PUBLIC FUNCTION
+bool ui_window_set_range(int window_id, int widget_id, double min, double max) {
+    UIContext ctx;
+    memset(&ctx, 0, sizeof(ctx));
+    ctx.window_id = window_id;
+    ctx.widget_id = widget_id;
+    ctx.min = min;
+    ctx.max = max;
+    return ui_run(ui_set_range_on_main_thread, &ctx);
+}
+
+// Replace the entries of a dropdown, combo box or checklist - PUBLIC FUNCTION
+bool ui_window_set_items(int window_id, int widget_id, const char** items, int item_count) {
+    UIContext ctx;
+    memset(&ctx, 0, sizeof(ctx));
+    ctx.window_id = window_id;
+    ctx.widget_id = widget_id;
+    ctx.items = items;
+    ctx.item_count = item_count;
+    return ui_run(ui_set_items_on_main_thread, &ctx);
+}
+
+// Check or uncheck a checklist entry - PUBLIC FUNCTION
+bool ui_window_set_item_checked(int window_id, int widget_id, int index, bool checked) {
+    UIContext ctx;
+    memset(&ctx, 0, sizeof(ctx));
+    ctx.window_id = window_id;
+    ctx.widget_id = widget_id;
+    ctx.index = index;
+    ctx.value = checked ? 1 : 0;
+    return ui_run(ui_set_item_checked_on_main_thread, &ctx);
+}
+
+// Check whether a checklist entry is checked - PUBLIC FUNCTION
+bool ui_window_get_item_checked(int window_id, int widget_id, int index) {
+    UIContext ctx;
+    memset(&ctx, 0, sizeof(ctx));
+    ctx.window_id = window_id;
+    ctx.widget_id = widget_id;
+    ctx.index = index;
+    return ui_run(ui_get_item_checked_on_main_thread, &ctx);
+}
+
+// Retitle a checklist entry - PUBLIC FUNCTION
+bool ui_window_set_item_text(int window_id, int widget_id, int index, const char* text) {
+    UIContext ctx;
+    memset(&ctx, 0, sizeof(ctx));
+    ctx.window_id = window_id;
+    ctx.widget_id = widget_id;
+    ctx.index = index;
+    ctx.text = text;
+    return ui_run(ui_set_item_text_on_main_thread, &ctx);
+}
+
+// Enable or disable a widget - PUBLIC FUNCTION
+bool ui_window_set_enabled(int window_id, int widget_id, bool enabled) {
+    UIContext ctx;
+    memset(&ctx, 0, sizeof(ctx));
+    ctx.window_id = window_id;
+    ctx.widget_id = widget_id;
+    ctx.value = enabled ? 1 : 0;
+    return ui_run(ui_set_enabled_on_main_thread, &ctx);
+}
+
+// Move and resize a widget - PUBLIC FUNCTION
+bool ui_window_set_frame(int window_id, int widget_id, double x, double y, double width, double height) {
+    UIContext ctx;
+    memset(&ctx, 0, sizeof(ctx));
+    ctx.window_id = window_id;
+    ctx.widget_id = widget_id;
+    ctx.x = x;
+    ctx.y = y;
+    ctx.width = width;
+    ctx.height = height;
+    return ui_run(ui_set_frame_on_main_thread, &ctx);
+}
+
+// Get the size a widget needs to show its contents - PUBLIC FUNCTION
+bool ui_window_fitting_size(int window_id, int widget_id, double* width, double* height) {
+    UIContext ctx;
+    memset(&ctx, 0, sizeof(ctx));
+    ctx.window_id = window_id;
+    ctx.widget_id = widget_id;
+    if (!ui_run(ui_fitting_size_on_main_thread, &ctx)) {
+        return false;
+    }
+    *width = ctx.width;
+    *height = ctx.height;
+    return true;
+}
+
+// Resize a window's content area - PUBLIC FUNCTION
+bool ui_window_set_content_size(int window_id, double width, double height) {
+    UIContext ctx;
+    memset(&ctx, 0, sizeof(ctx));
+    ctx.window_id = window_id;
+    ctx.width = width;
+    ctx.height = height;
+    return ui_run(ui_set_content_size_on_main_thread, &ctx);
+}
+
+// Give a button a key equivalent - PUBLIC FUNCTION
+bool ui_window_set_key_equivalent(int window_id, int widget_id, const char* key) {
+    UIContext ctx;
+    memset(&ctx, 0, sizeof(ctx));
+    ctx.window_id = window_id;
+    ctx.widget_id = widget_id;
+    ctx.text = key;
+    return ui_run(ui_set_key_equivalent_on_main_thread, &ctx);
+}
+
+// Set the order Tab moves through widgets - PUBLIC FUNCTION
+bool ui_window_set_tab_order(int window_id, const int* widget_ids, int count) {
+    UIContext ctx;
+    memset(&ctx, 0, sizeof(ctx));
+    ctx.window_id = window_id;
+    ctx.widget_ids = widget_ids;
+    ctx.item_count = count;
+    return ui_run(ui_set_tab_order_on_main_thread, &ctx);
+}
+
+// Move the keyboard focus to a widget - PUBLIC FUNCTION
+bool ui_window_focus(int window_id, int widget_id) {
+    UIContext ctx;
+    memset(&ctx, 0, sizeof(ctx));
+    ctx.window_id = window_id;
+    ctx.widget_id = widget_id;
+    return ui_run(ui_focus_on_main_thread, &ctx);
+}
+
+// Set the label VoiceOver reads for a widget - PUBLIC FUNCTION
+bool ui_window_set_accessibility_label(int window_id, int widget_id, const char* label) {
+    UIContext ctx;
+    memset(&ctx, 0, sizeof(ctx));
+    ctx.window_id = window_id;
+    ctx.widget_id = widget_id;
+    ctx.text = label;
+    return ui_run(ui_set_accessibility_label_on_main_thread, &ctx);
+}
+
+// Show a window and bring it to the front - PUBLIC FUNCTION
+bool ui_window_show(int window_id) {
+    UIContext ctx;
+    memset(&ctx, 0, sizeof(ctx));
+    ctx.window_id = window_id;
+    return ui_run(ui_show_window_on_main_thread, &ctx);
+}
+
+// Set a window's appearance to dark or light - PUBLIC FUNCTION
+bool ui_window_set_appearance(int window_id, bool dark) {
+    UIContext ctx;
+    memset(&ctx, 0, sizeof(ctx));
+    ctx.window_id = window_id;
+    ctx.value = dark ? 1 : 0;
+    return ui_run(ui_set_appearance_on_main_thread, &ctx);
+}
+
+// Get the backing scale factor of a window's screen, or 1 if unknown - PUBLIC FUNCTION
+double ui_window_scale_factor(int window_id) {
+    UIContext ctx;
+    memset(&ctx, 0, sizeof(ctx));
+    ctx.window_id = window_id;
+    if (!ui_run(ui_scale_factor_on_main_thread, &ctx)) {
+        return 1.0;
+    }
+    return ctx.value;
+}
+
+// Hide a window and return its content view for docking - PUBLIC FUNCTION
+void* ui_window_detach_content(int window_id) {
+    UIContext ctx;
+    memset(&ctx, 0, sizeof(ctx));
+    ctx.window_id = window_id;
+    if (!ui_run(ui_detach_content_on_main_thread, &ctx)) {
+        return NULL;
+    }
+    return ctx.result_view;
+}
+
+// Move a docked window's content view back into its window - PUBLIC FUNCTION
+bool ui_window_reattach_content(int window_id) {
+    UIContext ctx;
+    memset(&ctx, 0, sizeof(ctx));
+    ctx.window_id = window_id;
+    return ui_run(ui_reattach_content_on_main_thread, &ctx);
+}
+
+// Close a window if it exists - PUBLIC FUNCTION
+void ui_window_close(int window_id) {
+    UIContext ctx;
+    memset(&ctx, 0, sizeof(ctx));
+    ctx.window_id = window_id;
+
+    // Execute on main thread using a function pointer (not a block)
+    ui_execute_on_main_thread(ui_close_window_on_main_thread, &ctx);
+}
+
+// Check if a window exists - PUBLIC FUNCTION
+bool ui_window_exists(int window_id) {
+    UIContext ctx;
+    memset(&ctx, 0, sizeof(ctx));
+    ctx.window_id = window_id;
+    return ui_run(ui_window_exists_on_main_thread, &ctx);
+}